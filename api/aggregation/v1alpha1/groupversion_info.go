@@ -1,11 +1,17 @@
 package v1alpha1
 
 import (
+	"fmt"
+
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 )
 
+// CoderWorkspaceOwnerNameField is the field selector key that scopes CoderWorkspace
+// lists to a single Coder owner, for example `status.ownerName=alice`.
+const CoderWorkspaceOwnerNameField = "status.ownerName"
+
 var (
 	// SchemeGroupVersion is group version used to register these objects.
 	SchemeGroupVersion = schema.GroupVersion{Group: "aggregation.coder.com", Version: "v1alpha1"}
@@ -23,9 +29,22 @@ func addKnownTypes(scheme *runtime.Scheme) error {
 		&CoderWorkspaceList{},
 		&CoderTemplate{},
 		&CoderTemplateList{},
+		&CoderTemplateVersion{},
+		&CoderTemplateVersionList{},
 	)
 	metav1.AddToGroupVersion(scheme, SchemeGroupVersion)
-	return nil
+
+	return scheme.AddFieldLabelConversionFunc(
+		SchemeGroupVersion.WithKind("CoderWorkspace"),
+		func(label, value string) (string, string, error) {
+			switch label {
+			case "metadata.name", "metadata.namespace", CoderWorkspaceOwnerNameField:
+				return label, value, nil
+			default:
+				return "", "", fmt.Errorf("field label not supported: %s", label)
+			}
+		},
+	)
 }
 
 // Resource takes an unqualified resource and returns a Group-qualified GroupResource.
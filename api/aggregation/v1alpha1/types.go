@@ -13,6 +13,17 @@ type CoderWorkspaceSpec struct {
 	// TemplateVersionID optionally pins to a specific template version.
 	TemplateVersionID string `json:"templateVersionID,omitempty"`
 
+	// UseActiveTemplateVersion pins the workspace to the template's active
+	// version, resolved when the workspace is created, so later promotions do
+	// not change the version its builds use. It cannot be combined with
+	// TemplateVersionID and is ignored on update.
+	UseActiveTemplateVersion bool `json:"useActiveTemplateVersion,omitempty"`
+
+	// PresetName selects a preset of the template version by name when the
+	// workspace is created; the preset's parameter values are applied to the
+	// first build. It is ignored on update.
+	PresetName string `json:"presetName,omitempty"`
+
 	// Running drives start/stop via CreateWorkspaceBuild.
 	Running bool `json:"running"`
 
@@ -26,14 +37,54 @@ type CoderWorkspaceStatus struct {
 	OwnerName        string `json:"ownerName,omitempty"`
 	OrganizationName string `json:"organizationName,omitempty"`
 	TemplateName     string `json:"templateName,omitempty"`
+	// TemplateVersionID is the template version the latest build uses. For a
+	// workspace created with UseActiveTemplateVersion it is the pinned version.
+	TemplateVersionID string `json:"templateVersionID,omitempty"`
 
 	LatestBuildID     string `json:"latestBuildID,omitempty"`
 	LatestBuildStatus string `json:"latestBuildStatus,omitempty"`
 
+	// LastBuildStartedAt is when the provisioner started the latest build. It
+	// is empty while the build is still queued.
+	LastBuildStartedAt *metav1.Time `json:"lastBuildStartedAt,omitempty"`
+	// LastBuildCompletedAt is when the latest build finished, successfully or
+	// not. It is empty while the build is queued or running.
+	LastBuildCompletedAt *metav1.Time `json:"lastBuildCompletedAt,omitempty"`
+	// LastBuildDurationSeconds is the latest build's run time, from start to
+	// completion, rounded to whole seconds. It is set once the build completed.
+	LastBuildDurationSeconds *int64 `json:"lastBuildDurationSeconds,omitempty"`
+	// DailyCost is the quota cost Coder charges per day for the latest build's
+	// resources. It is zero when the template assigns no cost.
+	DailyCost int32 `json:"dailyCost,omitempty"`
+
+	// Health is healthy unless Coder reports a failing agent in the latest
+	// build, in which case it is unhealthy.
+	Health CoderWorkspaceHealth `json:"health,omitempty"`
+	// AgentStatus is the least connected status among the latest build's
+	// agents: timeout, disconnected, connecting, or connected. It is empty when
+	// the build has no agents.
+	AgentStatus string `json:"agentStatus,omitempty"`
+
+	// PresetName is the template version preset the latest build used. It is
+	// resolved on get and create; list and watch leave it empty to avoid a
+	// preset lookup per workspace.
+	PresetName string `json:"presetName,omitempty"`
+
 	AutoShutdown *metav1.Time `json:"autoShutdown,omitempty"`
 	LastUsedAt   *metav1.Time `json:"lastUsedAt,omitempty"`
 }
 
+// CoderWorkspaceHealth reports whether a workspace's agents are healthy.
+type CoderWorkspaceHealth string
+
+const (
+	// CoderWorkspaceHealthHealthy means no agent of the latest build is failing.
+	CoderWorkspaceHealthHealthy CoderWorkspaceHealth = "healthy"
+	// CoderWorkspaceHealthUnhealthy means at least one agent of the latest build
+	// is failing.
+	CoderWorkspaceHealthUnhealthy CoderWorkspaceHealth = "unhealthy"
+)
+
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
@@ -64,6 +115,8 @@ type CoderTemplateSpec struct {
 	Organization string `json:"organization"`
 
 	// VersionID is the Coder template version UUID used on creation (required for CREATE).
+	// On GET it reports the active version. Changing it on UPDATE promotes that
+	// existing version of the template without uploading files.
 	VersionID string `json:"versionID"`
 
 	DisplayName string `json:"displayName,omitempty"`
@@ -79,17 +132,127 @@ type CoderTemplateSpec struct {
 	// On CREATE/UPDATE with files, the server uploads source and creates a new template version.
 	Files map[string]string `json:"files,omitempty"`
 
+	// GitSource imports the template source from a Git repository instead of
+	// Files. CREATE/UPDATE fetch the ref and upload the subdirectory as a new
+	// template version, skipping the upload when the resolved tree hash
+	// matches the active version. Requires the aggregated API server to run
+	// with --enable-template-git-sources. Not returned on GET; Files reports
+	// the imported contents instead.
+	// +optional
+	GitSource *CoderTemplateGitSource `json:"gitSource,omitempty"`
+
+	// VersionName names the template version created from Files, for example
+	// a Git commit SHA. Empty lets Coder generate a name. Ignored when the
+	// request does not create a new template version.
+	// +optional
+	VersionName string `json:"versionName,omitempty"`
+
+	// DormancyThresholdMillis marks workspaces dormant after this many
+	// milliseconds of inactivity. Zero disables dormancy. Requires the
+	// advanced_template_scheduling entitlement when non-zero.
+	// +optional
+	DormancyThresholdMillis *int64 `json:"dormancyThresholdMillis,omitempty"`
+	// AutoDeleteThresholdMillis deletes dormant workspaces after they have been
+	// dormant for this many milliseconds. Zero disables auto-deletion. A
+	// non-zero value requires a non-zero DormancyThresholdMillis and the
+	// advanced_template_scheduling entitlement.
+	// +optional
+	AutoDeleteThresholdMillis *int64 `json:"autoDeleteThresholdMillis,omitempty"`
+
+	// DeprecationMessage marks the template deprecated and blocks new
+	// workspaces from using it. An empty string removes the deprecation.
+	// +optional
+	DeprecationMessage *string `json:"deprecationMessage,omitempty"`
+	// DefaultTTLMillis stops workspaces this many milliseconds after they
+	// start unless activity extends the deadline. Zero disables the default.
+	// +optional
+	DefaultTTLMillis *int64 `json:"defaultTTLMillis,omitempty"`
+	// ActivityBumpMillis extends a running workspace's deadline by this many
+	// milliseconds on activity. Zero disables activity bumping.
+	// +optional
+	ActivityBumpMillis *int64 `json:"activityBumpMillis,omitempty"`
+	// AutostopRequirement forces workspaces to stop during their owner's quiet
+	// hours on the listed days. Requires the advanced_template_scheduling
+	// entitlement; Coder ignores it otherwise.
+	// +optional
+	AutostopRequirement *CoderTemplateAutostopRequirement `json:"autostopRequirement,omitempty"`
+
+	// ACL grants template roles to Coder users and groups. When set on
+	// CREATE/UPDATE it replaces the template's ACL; omit it to leave the ACL
+	// unchanged. Populated on GET; requires the Coder template_rbac entitlement.
+	// +optional
+	ACL *CoderTemplateACL `json:"acl,omitempty"`
+
 	// Running is a legacy flag retained temporarily for in-repo callers that still read template run-state directly.
 	Running bool `json:"running,omitempty"`
 }
 
+// CoderTemplateAutostopRequirement defines when workspaces must be stopped.
+type CoderTemplateAutostopRequirement struct {
+	// DaysOfWeek lists the lowercase weekdays (e.g. "saturday") on which
+	// workspaces must stop.
+	DaysOfWeek []string `json:"daysOfWeek,omitempty"`
+	// Weeks is the number of weeks between required stops. Zero and one
+	// both mean weekly.
+	Weeks int64 `json:"weeks,omitempty"`
+}
+
+// CoderTemplateRole is a template role granted through a CoderTemplate ACL.
+type CoderTemplateRole string
+
+const (
+	// CoderTemplateRoleAdmin allows editing the template and its ACL.
+	CoderTemplateRoleAdmin CoderTemplateRole = "admin"
+	// CoderTemplateRoleUse allows creating workspaces from the template.
+	CoderTemplateRoleUse CoderTemplateRole = "use"
+)
+
+// CoderTemplateACL lists the users and groups granted access to a template.
+type CoderTemplateACL struct {
+	// Users grants template roles to Coder users by username.
+	Users []CoderTemplateACLEntry `json:"users,omitempty"`
+	// Groups grants template roles to Coder groups in the template's
+	// organization by group name.
+	Groups []CoderTemplateACLEntry `json:"groups,omitempty"`
+}
+
+// CoderTemplateACLEntry grants a template role to a single user or group.
+type CoderTemplateACLEntry struct {
+	// Name is the Coder username or group name.
+	Name string `json:"name"`
+	// Role is the granted template role: admin or use.
+	Role CoderTemplateRole `json:"role"`
+}
+
+// CoderTemplateGitSource references a template source tree in a Git repository.
+type CoderTemplateGitSource struct {
+	// URL is the repository to fetch, for example
+	// https://github.com/acme/templates.git.
+	URL string `json:"url"`
+
+	// Ref is the branch, tag, or commit SHA to import. Empty uses the
+	// repository's default branch.
+	// +optional
+	Ref string `json:"ref,omitempty"`
+
+	// Subdir is the slash-delimited directory within the repository that holds
+	// the template. Empty uses the repository root.
+	// +optional
+	Subdir string `json:"subdir,omitempty"`
+}
+
 // CoderTemplateStatus defines the observed state of a CoderTemplate.
 type CoderTemplateStatus struct {
-	ID               string       `json:"id,omitempty"`
-	OrganizationName string       `json:"organizationName,omitempty"`
-	ActiveVersionID  string       `json:"activeVersionID,omitempty"`
-	Deprecated       bool         `json:"deprecated,omitempty"`
-	UpdatedAt        *metav1.Time `json:"updatedAt,omitempty"`
+	ID               string `json:"id,omitempty"`
+	OrganizationName string `json:"organizationName,omitempty"`
+	ActiveVersionID  string `json:"activeVersionID,omitempty"`
+	// ActiveVersionName is the name of the active template version.
+	ActiveVersionName string `json:"activeVersionName,omitempty"`
+	// SourceTreeHash is the Git tree hash the active version was imported
+	// from via spec.gitSource. Empty for versions uploaded from spec.files.
+	SourceTreeHash string       `json:"sourceTreeHash,omitempty"`
+	Deprecated     bool         `json:"deprecated,omitempty"`
+	UpdatedAt      *metav1.Time `json:"updatedAt,omitempty"`
 
 	// AutoShutdown is a legacy timestamp retained temporarily for in-repo callers that still surface template shutdown timestamps.
 	AutoShutdown *metav1.Time `json:"autoShutdown,omitempty"`
@@ -101,6 +264,9 @@ type CoderTemplateStatus struct {
 
 // CoderTemplate is the schema for Coder template resources.
 // metadata.name is <organization>.<template-name>.
+//
+// POST to the lint subresource builds spec.files or spec.gitSource as a new,
+// inactive template version and returns the resulting CoderTemplateVersion.
 type CoderTemplate struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
@@ -118,3 +284,58 @@ type CoderTemplateList struct {
 	metav1.ListMeta `json:"metadata,omitempty"`
 	Items           []CoderTemplate `json:"items"`
 }
+
+// CoderTemplateVersionSpec defines the desired state of a CoderTemplateVersion.
+type CoderTemplateVersionSpec struct {
+	// Organization is the Coder organization name.
+	Organization string `json:"organization,omitempty"`
+
+	// TemplateName is the Coder template this version belongs to.
+	TemplateName string `json:"templateName,omitempty"`
+
+	// Message is the message attached to the template version.
+	Message string `json:"message,omitempty"`
+}
+
+// CoderTemplateVersionStatus defines the observed state of a CoderTemplateVersion.
+type CoderTemplateVersionStatus struct {
+	ID          string `json:"id,omitempty"`
+	VersionName string `json:"versionName,omitempty"`
+	TemplateID  string `json:"templateID,omitempty"`
+
+	// Active reports whether this version is the template's active version.
+	Active bool `json:"active,omitempty"`
+
+	// JobStatus is the status of the version's provisioner import job.
+	JobStatus string `json:"jobStatus,omitempty"`
+
+	// JobError is the error reported by a failed import job, such as
+	// Terraform parse or plan diagnostics.
+	JobError string `json:"jobError,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:object:root=true
+
+// CoderTemplateVersion is the schema for Coder template version resources.
+// metadata.name is <organization>.<template-name>.<version-id>.
+//
+// POST to the promote subresource sets the version as the template's active
+// version and returns the updated CoderTemplate.
+type CoderTemplateVersion struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   CoderTemplateVersionSpec   `json:"spec,omitempty"`
+	Status CoderTemplateVersionStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:object:root=true
+
+// CoderTemplateVersionList contains a list of CoderTemplateVersion objects.
+type CoderTemplateVersionList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []CoderTemplateVersion `json:"items"`
+}
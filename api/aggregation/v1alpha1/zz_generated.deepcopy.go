@@ -37,6 +37,85 @@ func (in *CoderTemplate) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CoderTemplateACL) DeepCopyInto(out *CoderTemplateACL) {
+	*out = *in
+	if in.Users != nil {
+		in, out := &in.Users, &out.Users
+		*out = make([]CoderTemplateACLEntry, len(*in))
+		copy(*out, *in)
+	}
+	if in.Groups != nil {
+		in, out := &in.Groups, &out.Groups
+		*out = make([]CoderTemplateACLEntry, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CoderTemplateACL.
+func (in *CoderTemplateACL) DeepCopy() *CoderTemplateACL {
+	if in == nil {
+		return nil
+	}
+	out := new(CoderTemplateACL)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CoderTemplateACLEntry) DeepCopyInto(out *CoderTemplateACLEntry) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CoderTemplateACLEntry.
+func (in *CoderTemplateACLEntry) DeepCopy() *CoderTemplateACLEntry {
+	if in == nil {
+		return nil
+	}
+	out := new(CoderTemplateACLEntry)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CoderTemplateAutostopRequirement) DeepCopyInto(out *CoderTemplateAutostopRequirement) {
+	*out = *in
+	if in.DaysOfWeek != nil {
+		in, out := &in.DaysOfWeek, &out.DaysOfWeek
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CoderTemplateAutostopRequirement.
+func (in *CoderTemplateAutostopRequirement) DeepCopy() *CoderTemplateAutostopRequirement {
+	if in == nil {
+		return nil
+	}
+	out := new(CoderTemplateAutostopRequirement)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CoderTemplateGitSource) DeepCopyInto(out *CoderTemplateGitSource) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CoderTemplateGitSource.
+func (in *CoderTemplateGitSource) DeepCopy() *CoderTemplateGitSource {
+	if in == nil {
+		return nil
+	}
+	out := new(CoderTemplateGitSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CoderTemplateList) DeepCopyInto(out *CoderTemplateList) {
 	*out = *in
@@ -80,6 +159,46 @@ func (in *CoderTemplateSpec) DeepCopyInto(out *CoderTemplateSpec) {
 			(*out)[key] = val
 		}
 	}
+	if in.GitSource != nil {
+		in, out := &in.GitSource, &out.GitSource
+		*out = new(CoderTemplateGitSource)
+		**out = **in
+	}
+	if in.DormancyThresholdMillis != nil {
+		in, out := &in.DormancyThresholdMillis, &out.DormancyThresholdMillis
+		*out = new(int64)
+		**out = **in
+	}
+	if in.AutoDeleteThresholdMillis != nil {
+		in, out := &in.AutoDeleteThresholdMillis, &out.AutoDeleteThresholdMillis
+		*out = new(int64)
+		**out = **in
+	}
+	if in.DeprecationMessage != nil {
+		in, out := &in.DeprecationMessage, &out.DeprecationMessage
+		*out = new(string)
+		**out = **in
+	}
+	if in.DefaultTTLMillis != nil {
+		in, out := &in.DefaultTTLMillis, &out.DefaultTTLMillis
+		*out = new(int64)
+		**out = **in
+	}
+	if in.ActivityBumpMillis != nil {
+		in, out := &in.ActivityBumpMillis, &out.ActivityBumpMillis
+		*out = new(int64)
+		**out = **in
+	}
+	if in.AutostopRequirement != nil {
+		in, out := &in.AutostopRequirement, &out.AutostopRequirement
+		*out = new(CoderTemplateAutostopRequirement)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ACL != nil {
+		in, out := &in.ACL, &out.ACL
+		*out = new(CoderTemplateACL)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -117,6 +236,99 @@ func (in *CoderTemplateStatus) DeepCopy() *CoderTemplateStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CoderTemplateVersion) DeepCopyInto(out *CoderTemplateVersion) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = in.Spec
+	out.Status = in.Status
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CoderTemplateVersion.
+func (in *CoderTemplateVersion) DeepCopy() *CoderTemplateVersion {
+	if in == nil {
+		return nil
+	}
+	out := new(CoderTemplateVersion)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *CoderTemplateVersion) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CoderTemplateVersionList) DeepCopyInto(out *CoderTemplateVersionList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]CoderTemplateVersion, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CoderTemplateVersionList.
+func (in *CoderTemplateVersionList) DeepCopy() *CoderTemplateVersionList {
+	if in == nil {
+		return nil
+	}
+	out := new(CoderTemplateVersionList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *CoderTemplateVersionList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CoderTemplateVersionSpec) DeepCopyInto(out *CoderTemplateVersionSpec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CoderTemplateVersionSpec.
+func (in *CoderTemplateVersionSpec) DeepCopy() *CoderTemplateVersionSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CoderTemplateVersionSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CoderTemplateVersionStatus) DeepCopyInto(out *CoderTemplateVersionStatus) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CoderTemplateVersionStatus.
+func (in *CoderTemplateVersionStatus) DeepCopy() *CoderTemplateVersionStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CoderTemplateVersionStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CoderWorkspace) DeepCopyInto(out *CoderWorkspace) {
 	*out = *in
@@ -207,6 +419,19 @@ func (in *CoderWorkspaceSpec) DeepCopy() *CoderWorkspaceSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CoderWorkspaceStatus) DeepCopyInto(out *CoderWorkspaceStatus) {
 	*out = *in
+	if in.LastBuildStartedAt != nil {
+		in, out := &in.LastBuildStartedAt, &out.LastBuildStartedAt
+		*out = (*in).DeepCopy()
+	}
+	if in.LastBuildCompletedAt != nil {
+		in, out := &in.LastBuildCompletedAt, &out.LastBuildCompletedAt
+		*out = (*in).DeepCopy()
+	}
+	if in.LastBuildDurationSeconds != nil {
+		in, out := &in.LastBuildDurationSeconds, &out.LastBuildDurationSeconds
+		*out = new(int64)
+		**out = **in
+	}
 	if in.AutoShutdown != nil {
 		in, out := &in.AutoShutdown, &out.AutoShutdown
 		*out = (*in).DeepCopy()
@@ -2,6 +2,7 @@ package v1alpha1
 
 import (
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -10,8 +11,86 @@ const (
 	CoderControlPlanePhasePending = "Pending"
 	// CoderControlPlanePhaseReady indicates at least one control plane pod is ready.
 	CoderControlPlanePhaseReady = "Ready"
+	// CoderControlPlanePhaseSuspended indicates spec.replicas is explicitly 0,
+	// so the control plane is intentionally scaled down.
+	CoderControlPlanePhaseSuspended = "Suspended"
 	// CoderControlPlaneConditionLicenseApplied indicates whether the operator uploaded the configured license.
 	CoderControlPlaneConditionLicenseApplied = "LicenseApplied"
+	// CoderControlPlaneConditionOperatorAccessReady reports whether the operator
+	// bootstrapped API access to coderd, and why not when it is False.
+	CoderControlPlaneConditionOperatorAccessReady = "OperatorAccessReady"
+	// CoderControlPlaneConditionManagedEnvOverridden is set while spec.extraEnv
+	// overrides operator-managed environment variables.
+	CoderControlPlaneConditionManagedEnvOverridden = "ManagedEnvOverridden"
+	// CoderControlPlaneConditionManagedArgsOverridden is set while
+	// spec.extraArgs overrides operator-managed server flags.
+	CoderControlPlaneConditionManagedArgsOverridden = "ManagedArgsOverridden"
+	// CoderControlPlaneConditionExtraEnvRejected is set while spec.extraEnv,
+	// spec.config, or spec.envFrom sets environment variables the operator's
+	// env policy does not allow, or spec.extraArgs sets a flag whose CODER_*
+	// variable it does not allow. The controller leaves managed resources
+	// unchanged until the spec complies.
+	CoderControlPlaneConditionExtraEnvRejected = "ExtraEnvRejected"
+	// CoderControlPlaneConditionEnvFromSourceMissing is set while the operator
+	// has an env policy and a non-optional spec.envFrom source does not exist.
+	// The policy expands envFrom into single env vars, so coderd starts without
+	// the missing source's variables instead of failing to start.
+	CoderControlPlaneConditionEnvFromSourceMissing = "EnvFromSourceMissing"
+	// CoderControlPlaneConditionConfigMapConflict is set while a ConfigMap
+	// named `<name>-config` that the control plane does not own blocks
+	// spec.config. The controller leaves managed resources unchanged until the
+	// ConfigMap is renamed or deleted.
+	CoderControlPlaneConditionConfigMapConflict = "ConfigMapConflict"
+	// CoderControlPlaneConditionBuiltinPostgresSecretConflict is set while a
+	// Secret named `<name>-postgres` that the operator did not create for the
+	// control plane blocks spec.database.builtin. The controller leaves managed
+	// resources unchanged until the Secret is renamed or deleted.
+	CoderControlPlaneConditionBuiltinPostgresSecretConflict = "BuiltinPostgresSecretConflict"
+	// CoderControlPlaneConditionMigrationComplete reports whether the database
+	// migration Job for the current image has succeeded.
+	CoderControlPlaneConditionMigrationComplete = "MigrationComplete"
+	// CoderControlPlaneConditionDeploymentAvailable mirrors the control plane
+	// Deployment's Available condition.
+	CoderControlPlaneConditionDeploymentAvailable = "DeploymentAvailable"
+	// CoderControlPlaneConditionDeploymentProgressing mirrors the control plane
+	// Deployment's Progressing condition; it is False when the rollout exceeded
+	// its progress deadline.
+	CoderControlPlaneConditionDeploymentProgressing = "DeploymentProgressing"
+	// CoderControlPlaneConditionGatewayControllerMissing is set while the managed
+	// HTTPRoute stays un-accepted, which usually means no Gateway API controller
+	// is installed for the referenced Gateway.
+	CoderControlPlaneConditionGatewayControllerMissing = "GatewayControllerMissing"
+	// CoderControlPlaneConditionImagePinned is set to False while the coder
+	// container image uses the mutable latest tag or no tag at all. It is
+	// informational and does not affect reconciliation.
+	CoderControlPlaneConditionImagePinned = "ImagePinned"
+	// CoderControlPlaneConditionServicePortPlaintext is set while
+	// spec.service.port is 443 but forwards to Coder's plain HTTP listener
+	// because TLS is disabled. It is informational and does not affect
+	// reconciliation.
+	CoderControlPlaneConditionServicePortPlaintext = "ServicePortPlaintext"
+	// CoderControlPlaneConditionServiceTargetPortValid reports whether
+	// spec.service.targetPort matches a port the coder container exposes. While
+	// it is False the controller leaves the Service unchanged.
+	CoderControlPlaneConditionServiceTargetPortValid = "ServiceTargetPortValid"
+	// CoderControlPlaneConditionEntitlementsUnknown is set while the operator
+	// cannot query entitlements from the control plane. The last known license
+	// tier and entitlement status fields are kept until a query succeeds.
+	CoderControlPlaneConditionEntitlementsUnknown = "EntitlementsUnknown"
+	// CoderControlPlaneConditionVersionDetected reports whether the operator
+	// read the running Coder version from the control plane's build info. It is
+	// False while the query fails; status.coderVersion keeps its last value.
+	CoderControlPlaneConditionVersionDetected = "VersionDetected"
+	// CoderControlPlaneConditionExternalURLReachable reports whether coderd
+	// answered a build info query at spec.externalURL. It is only set when
+	// spec.manageDeployment is false; the control plane stays Pending while it
+	// is not True.
+	CoderControlPlaneConditionExternalURLReachable = "ExternalURLReachable"
+	// CoderControlPlaneConditionBackupConfigured is set to False while
+	// spec.backup is enabled but cannot be applied, for example because the
+	// database URL comes from envFrom or a ConfigMap. The rest of the control
+	// plane is still reconciled.
+	CoderControlPlaneConditionBackupConfigured = "BackupConfigured"
 
 	// CoderControlPlaneLicenseTierNone indicates no license is currently installed.
 	CoderControlPlaneLicenseTierNone = "none"
@@ -26,50 +105,164 @@ const (
 
 	// CoderControlPlaneEntitlementUnknown indicates the controller could not determine a feature entitlement.
 	CoderControlPlaneEntitlementUnknown = "unknown"
+
+	// RotateOperatorTokenAnnotation requests an immediate operator token
+	// rotation. Set it to a new value (for example a timestamp) to mint a fresh
+	// token and revoke the previous one; the controller records the handled
+	// value in status.operatorTokenRotationRequest.
+	RotateOperatorTokenAnnotation = "coder.com/rotate-operator-token"
+
+	// RestartedAtAnnotation requests a rolling restart of the Coder
+	// Deployment, like kubectl rollout restart. Set it to a new value (for
+	// example a timestamp); the controller copies it onto the pod template.
+	RestartedAtAnnotation = "coder.com/restartedAt"
+
+	// AggregatedOrganizationsAnnotation limits aggregated API LIST requests in
+	// the control plane's namespace to a comma-separated list of Coder
+	// organization names. Unset lists every organization.
+	AggregatedOrganizationsAnnotation = "coder.com/aggregated-organizations"
 )
 
 // CoderControlPlaneSpec defines the desired state of a CoderControlPlane.
+// +kubebuilder:validation:XValidation:rule="!has(self.dnsPolicy) || self.dnsPolicy != 'None' || has(self.dnsConfig)",message="dnsConfig is required when dnsPolicy is None"
+// +kubebuilder:validation:XValidation:rule="!has(self.manageDeployment) || self.manageDeployment || (has(self.externalURL) && size(self.externalURL) > 0)",message="externalURL is required when manageDeployment is false"
+// +kubebuilder:validation:XValidation:rule="!has(self.manageDeployment) || self.manageDeployment || !has(self.expose)",message="expose is not supported when manageDeployment is false"
+// +kubebuilder:validation:XValidation:rule="!has(self.expose) || !has(self.expose.gateway) || !has(self.expose.gateway.backendTLS) || (has(self.tls) && has(self.tls.secretNames) && size(self.tls.secretNames) > 0)",message="expose.gateway.backendTLS requires tls.secretNames"
+// +kubebuilder:validation:XValidation:rule="!has(self.derp) || !has(self.derp.relayURL) || self.derp.relayURL.contains('$(KUBE_POD_IP)') || !has(self.replicas) || self.replicas <= 1",message="derp.relayURL must reference $(KUBE_POD_IP) when replicas is greater than 1"
+// +kubebuilder:validation:XValidation:rule="!has(self.requireLicense) || !self.requireLicense || has(self.licenseSecretRef) || (has(self.licenses) && size(self.licenses) > 0)",message="requireLicense requires licenseSecretRef or licenses"
 type CoderControlPlaneSpec struct {
 	// Image is the container image used for the Coder control plane pod.
-	// +kubebuilder:default="ghcr.io/coder/coder:latest"
+	// When omitted, the operator's --default-coder-image is used
+	// (ghcr.io/coder/coder:latest unless overridden).
+	// +optional
 	Image string `json:"image,omitempty"`
-	// Replicas is the desired number of control plane pods.
+	// ImagePullPolicy is the pull policy of the coder container. When omitted,
+	// it follows the Kubernetes default: Always for a `:latest` or untagged
+	// image, IfNotPresent otherwise.
+	// +kubebuilder:validation:Enum=Always;IfNotPresent;Never
+	// +optional
+	ImagePullPolicy corev1.PullPolicy `json:"imagePullPolicy,omitempty"`
+	// Replicas is the desired number of control plane pods. Set it to 0 to
+	// scale coderd down, for example during maintenance; the control plane
+	// then reports the Suspended phase.
 	// +kubebuilder:default=1
+	// +kubebuilder:validation:Minimum=0
 	Replicas *int32 `json:"replicas,omitempty"`
+	// ManageDeployment controls whether the operator creates and reconciles the
+	// control plane Deployment. Set it to false when coderd is deployed by other
+	// means (for example, the Helm chart); operator access, licenses, and
+	// entitlements are still managed against ExternalURL. The operator then
+	// creates no Service or exposure resources, since their selectors would not
+	// match the externally managed pods, and reports Ready only once coderd
+	// answers at ExternalURL.
+	// +kubebuilder:default=true
+	// +optional
+	ManageDeployment *bool `json:"manageDeployment,omitempty"`
+	// ExternalURL is the in-cluster URL of an externally managed coderd, used
+	// for operator API calls and status.url when ManageDeployment is false.
+	// +kubebuilder:validation:Pattern=`^https?://`
+	// +optional
+	ExternalURL string `json:"externalURL,omitempty"`
 	// Service controls the service created in front of the control plane.
 	// +kubebuilder:default={}
 	Service ServiceSpec `json:"service,omitempty"`
 	// ExtraArgs are appended to the default Coder server arguments.
+	// A flag that the operator already sets (for example --http-address)
+	// replaces the managed flag in place, and the ManagedArgsOverridden
+	// condition lists the overridden flags. Repeated single-value flags such as
+	// --access-url or --prometheus-enable are deduplicated, with the last entry
+	// winning; flags that may be repeated, such as --oidc-group-mapping or
+	// --external-auth-*, are passed through as given.
 	ExtraArgs []string `json:"extraArgs,omitempty"`
 	// ExtraEnv are injected into the Coder control plane container.
+	// Entries that share a name with an operator-managed variable (for example
+	// KUBE_POD_IP or CODER_DERP_SERVER_RELAY_URL) replace the managed value in
+	// place, and the ManagedEnvOverridden condition lists the overridden names.
+	// Managed entries that reference an overridden variable expand to the user's
+	// value; for example, overriding KUBE_POD_IP changes the host in the managed
+	// CODER_DERP_SERVER_RELAY_URL. CODER_ACCESS_URL is not injected at all when
+	// set here, so it is never reported as overridden. Repeated names are
+	// deduplicated, with the last entry winning.
 	ExtraEnv []corev1.EnvVar `json:"extraEnv,omitempty"`
 	// ImagePullSecrets are used by the pod to pull private images.
 	ImagePullSecrets []corev1.LocalObjectReference `json:"imagePullSecrets,omitempty"`
 	// OperatorAccess configures bootstrap API access to the coderd instance.
+	// Unless disabled, the controller creates a `coder-k8s-operator` user once
+	// the control plane is reachable and stores its API token in a Secret in
+	// this namespace. The operator uses that token for license uploads and
+	// entitlements checks, and status.operatorTokenSecretRef points at it.
 	// +kubebuilder:default={}
 	OperatorAccess OperatorAccessSpec `json:"operatorAccess,omitempty"`
 	// LicenseSecretRef references a Secret key containing a Coder Enterprise
-	// license JWT. When set, the controller uploads the license after the
-	// control plane is ready and re-uploads when the Secret value changes.
+	// license JWT, or several JWTs separated by newlines. When set, the
+	// controller uploads the licenses after the control plane is ready and
+	// re-uploads when the Secret value changes.
 	// +optional
 	LicenseSecretRef *SecretKeySelector `json:"licenseSecretRef,omitempty"`
+	// Licenses references additional Secret keys containing Coder license JWTs
+	// to stack on top of LicenseSecretRef. A key may hold several JWTs separated
+	// by newlines. Each license is uploaded and tracked independently, and
+	// re-uploaded if it goes missing from coderd.
+	// +optional
+	Licenses []SecretKeySelector `json:"licenses,omitempty"`
+	// RequireLicense keeps the control plane in the Pending phase until every
+	// configured license is applied, as reported by the LicenseApplied
+	// condition. When false, licenses are applied best-effort and do not affect
+	// the phase.
+	// +optional
+	RequireLicense bool `json:"requireLicense,omitempty"`
+
+	// CommonLabels are added to every child object the operator manages for
+	// this control plane, for example for cost allocation. The operator's own
+	// labels, such as app.kubernetes.io/*, take precedence, and selectors never
+	// include common labels.
+	// +optional
+	CommonLabels map[string]string `json:"commonLabels,omitempty"`
+	// CommonAnnotations are added to every child object the operator manages
+	// for this control plane. Annotations configured for a specific object, such
+	// as spec.service.annotations, take precedence.
+	// +optional
+	CommonAnnotations map[string]string `json:"commonAnnotations,omitempty"`
 
 	// ServiceAccount configures the ServiceAccount for the control plane pod.
 	// +kubebuilder:default={}
 	ServiceAccount ServiceAccountSpec `json:"serviceAccount,omitempty"`
 	// RBAC configures namespace-scoped RBAC for workspace provisioning.
+	// The controller grants the control plane ServiceAccount a Role and
+	// RoleBinding in this namespace and in each of rbac.workspaceNamespaces so
+	// Coder can create workspace pods, PVCs, and (optionally) Deployments.
 	// +kubebuilder:default={}
 	RBAC RBACSpec `json:"rbac,omitempty"`
 
 	// Resources sets resource requests/limits for the control plane container.
+	// When set, Resources takes precedence over ResourceProfile. Extended
+	// resources such as nvidia.com/gpu must set a whole-number limit, and a
+	// request, when set, must equal it.
 	// +optional
 	Resources *corev1.ResourceRequirements `json:"resources,omitempty"`
+	// ResourceProfile selects a named resource profile (for example, "small",
+	// "medium", or "large") configured on the operator. The profile's
+	// requests/limits are applied only when Resources is unset.
+	// +optional
+	ResourceProfile string `json:"resourceProfile,omitempty"`
+	// Provisioner configures coderd's built-in provisioner daemons.
+	// +optional
+	Provisioner *BuiltinProvisionerSpec `json:"provisioner,omitempty"`
 	// SecurityContext sets the container security context.
 	// +optional
 	SecurityContext *corev1.SecurityContext `json:"securityContext,omitempty"`
 	// PodSecurityContext sets the pod-level security context.
 	// +optional
 	PodSecurityContext *corev1.PodSecurityContext `json:"podSecurityContext,omitempty"`
+	// Hardened applies a restricted default security context to the coder
+	// container: it runs as non-root user 1000, drops all capabilities,
+	// disallows privilege escalation, uses the RuntimeDefault seccomp profile,
+	// and mounts a read-only root filesystem with emptyDir volumes for /tmp and
+	// /home/coder. Fields set in SecurityContext take precedence over these
+	// defaults, and a VolumeMounts entry at /tmp or /home/coder replaces that
+	// emptyDir.
+	// +optional
+	Hardened bool `json:"hardened,omitempty"`
 
 	// TLS configures Coder built-in TLS.
 	// +kubebuilder:default={}
@@ -82,21 +275,52 @@ type CoderControlPlaneSpec struct {
 	// +kubebuilder:default={enabled:false}
 	LivenessProbe ProbeSpec `json:"livenessProbe,omitempty"`
 
-	// EnvUseClusterAccessURL injects a default CODER_ACCESS_URL when not explicitly set.
+	// EnvUseClusterAccessURL injects a default CODER_ACCESS_URL, derived from the
+	// in-cluster Service URL, when neither spec.extraEnv nor spec.envFrom sets
+	// one. Set it to false to omit the derived value entirely, for example when
+	// Coder is only reachable through external DNS, and let Coder apply its own
+	// default. An explicitly configured CODER_ACCESS_URL always wins.
 	// +kubebuilder:default=true
 	EnvUseClusterAccessURL *bool `json:"envUseClusterAccessURL,omitempty"`
 
 	// Expose configures external exposure via Ingress or Gateway API.
+	// The controller manages an Ingress or HTTPRoute named after the control
+	// plane that routes the configured hosts to the Coder Service, and
+	// status.publicURL reports the primary host's URL.
 	// +optional
 	Expose *ExposeSpec `json:"expose,omitempty"`
 
 	// +kubebuilder:validation:XValidation:rule="self.all(e, !(has(e.configMapRef) && has(e.secretRef)))",message="each envFrom entry may specify at most one of configMapRef or secretRef"
 	// EnvFrom injects environment variables from ConfigMaps/Secrets.
 	EnvFrom []corev1.EnvFromSource `json:"envFrom,omitempty"`
+	// Config sets non-secret Coder environment variables, for example
+	// CODER_TELEMETRY_ENABLE. The operator writes them to the managed
+	// <name>-config ConfigMap, loads it into the coder container ahead of
+	// spec.envFrom, and rolls the Deployment when the values change. Operator
+	// managed variables, spec.extraEnv, and spec.envFrom take precedence for the
+	// same name. Removing every entry deletes the ConfigMap.
+	// +kubebuilder:validation:XValidation:rule="self.all(k, k.matches('^[-._a-zA-Z][-._a-zA-Z0-9]*$'))",message="config keys must be valid environment variable names"
+	// +optional
+	Config map[string]string `json:"config,omitempty"`
 	// Volumes are additional volumes to add to the pod.
 	Volumes []corev1.Volume `json:"volumes,omitempty"`
 	// VolumeMounts are additional volume mounts for the control plane container.
 	VolumeMounts []corev1.VolumeMount `json:"volumeMounts,omitempty"`
+	// ProjectedTokens mounts projected ServiceAccount tokens into the control
+	// plane container, for example to authenticate to a cloud provider through
+	// workload identity federation. Each token gets its own volume and mount.
+	// +listType=map
+	// +listMapKey=name
+	// +optional
+	ProjectedTokens []ProjectedTokenSpec `json:"projectedTokens,omitempty"`
+	// ExtraPorts are additional ports exposed by the control plane container,
+	// for example pprof or a separate DERP listener. Named ports are also added
+	// to the control plane Service, using the container port as the Service
+	// port. Names must not collide with the managed `http`, `https`, or
+	// `https-<port>` ports.
+	// +kubebuilder:validation:XValidation:rule="self.all(p, !has(p.name) || (p.name != 'http' && p.name != 'https' && !p.name.startsWith('https-')))",message="extraPorts names must not collide with the managed http, https, or https-<port> ports"
+	// +optional
+	ExtraPorts []corev1.ContainerPort `json:"extraPorts,omitempty"`
 	// Certs configures additional CA certificate mounts.
 	// +kubebuilder:default={}
 	Certs CertsSpec `json:"certs,omitempty"`
@@ -110,16 +334,297 @@ type CoderControlPlaneSpec struct {
 	Affinity *corev1.Affinity `json:"affinity,omitempty"`
 	// TopologySpreadConstraints control pod topology spread.
 	TopologySpreadConstraints []corev1.TopologySpreadConstraint `json:"topologySpreadConstraints,omitempty"`
+	// HostAliases add entries to the control plane pod's /etc/hosts, for
+	// example to reach Postgres or an OIDC provider without cluster DNS.
+	// +optional
+	HostAliases []corev1.HostAlias `json:"hostAliases,omitempty"`
+	// DNSPolicy sets the control plane pod's DNS policy. When None, DNSConfig
+	// must provide the resolver configuration.
+	// +kubebuilder:validation:Enum=ClusterFirst;ClusterFirstWithHostNet;Default;None
+	// +optional
+	DNSPolicy corev1.DNSPolicy `json:"dnsPolicy,omitempty"`
+	// DNSConfig sets custom resolvers, search domains, and options for the
+	// control plane pod.
+	// +optional
+	DNSConfig *corev1.PodDNSConfig `json:"dnsConfig,omitempty"`
+	// Lifecycle sets lifecycle hooks on the coder container, for example a
+	// preStop hook that drains workspace connections before SIGTERM.
+	// +optional
+	Lifecycle *corev1.Lifecycle `json:"lifecycle,omitempty"`
+	// TerminationGracePeriodSeconds bounds how long the control plane pod may
+	// take to shut down, including the preStop hook. When omitted, the
+	// Kubernetes default of 30 seconds applies.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	TerminationGracePeriodSeconds *int64 `json:"terminationGracePeriodSeconds,omitempty"`
+
+	// HighAvailability configures multi-replica control plane networking.
+	// +optional
+	HighAvailability *HighAvailabilitySpec `json:"highAvailability,omitempty"`
+	// DERP configures the control plane's embedded DERP relay.
+	// +optional
+	DERP *DERPSpec `json:"derp,omitempty"`
+	// Security configures Coder's cookie and reverse-proxy trust settings.
+	// +optional
+	Security *SecuritySpec `json:"security,omitempty"`
+	// Migration runs database migrations in a one-shot Job before the
+	// Deployment is scaled up.
+	// +optional
+	Migration *MigrationSpec `json:"migration,omitempty"`
+	// Database configures the PostgreSQL database used by coderd.
+	// +optional
+	Database *DatabaseSpec `json:"database,omitempty"`
+	// Backup schedules pg_dump backups of the Coder database.
+	// +optional
+	Backup *BackupSpec `json:"backup,omitempty"`
+}
+
+// DatabaseSpec configures the PostgreSQL database used by coderd.
+type DatabaseSpec struct {
+	// Builtin provisions a single-instance PostgreSQL managed by the operator.
+	// It is intended for evaluation and quick-start installs only; it has no
+	// replication or upgrade handling. Use an external database in production.
+	// +optional
+	Builtin *BuiltinDatabaseSpec `json:"builtin,omitempty"`
+}
+
+// BuiltinDatabaseSpec configures the operator-managed PostgreSQL instance.
+type BuiltinDatabaseSpec struct {
+	// Enabled provisions the PostgreSQL StatefulSet, Service, and credentials
+	// Secret, and points CODER_PG_CONNECTION_URL at it unless spec.extraEnv
+	// sets that variable.
+	// +kubebuilder:default=false
+	Enabled bool `json:"enabled,omitempty"`
+	// Image is the PostgreSQL container image.
+	// +kubebuilder:default="postgres:17"
+	// +optional
+	Image string `json:"image,omitempty"`
+	// StorageSize is the requested size of the data volume.
+	// +kubebuilder:default="10Gi"
+	// +optional
+	StorageSize *resource.Quantity `json:"storageSize,omitempty"`
+	// StorageClassName selects the StorageClass for the data volume. When
+	// omitted, the cluster default StorageClass is used.
+	// +optional
+	StorageClassName *string `json:"storageClassName,omitempty"`
+}
+
+// ProjectedTokenSpec configures a projected ServiceAccount token mounted into
+// the control plane container.
+type ProjectedTokenSpec struct {
+	// Name identifies the token. The volume is named `token-{name}`.
+	// +kubebuilder:validation:MaxLength=57
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	Name string `json:"name"`
+	// Audience is the intended audience of the token, for example
+	// `sts.amazonaws.com`.
+	// +kubebuilder:validation:MinLength=1
+	Audience string `json:"audience"`
+	// ExpirationSeconds is the requested token lifetime. The kubelet refreshes
+	// the token before it expires. Defaults to one hour.
+	// +kubebuilder:validation:Minimum=600
+	// +optional
+	ExpirationSeconds *int64 `json:"expirationSeconds,omitempty"`
+	// MountPath is the directory the token is mounted in. Defaults to
+	// `/var/run/secrets/tokens/{name}`.
+	// +kubebuilder:validation:Pattern=`^/`
+	// +optional
+	MountPath string `json:"mountPath,omitempty"`
+	// Path is the token file name within MountPath. Defaults to `token`. It
+	// must be relative, must not start with `..`, and must not contain a `..`
+	// segment, matching the rules Kubernetes applies to projected volume paths.
+	// +kubebuilder:validation:Pattern=`^([^/.][^/]*|\.[^/.][^/]*|\.)(/([^/.][^/]*|\.[^/.][^/]*|\.\.[^/]+|\.))*$`
+	// +optional
+	Path string `json:"path,omitempty"`
+}
+
+// BackupSpec configures scheduled pg_dump backups of the Coder database.
+// +kubebuilder:validation:XValidation:rule="!self.enabled || has(self.destination)",message="destination is required when backup is enabled"
+type BackupSpec struct {
+	// Enabled creates a CronJob that dumps the database resolved from
+	// CODER_PG_CONNECTION_URL.
+	// +kubebuilder:default=false
+	Enabled bool `json:"enabled,omitempty"`
+	// Schedule is the CronJob schedule in cron format.
+	// +kubebuilder:default="0 3 * * *"
+	// +kubebuilder:validation:MinLength=1
+	// +optional
+	Schedule string `json:"schedule,omitempty"`
+	// Image runs pg_dump. S3 destinations also require the aws CLI in this
+	// image.
+	// +kubebuilder:default="postgres:17"
+	// +optional
+	Image string `json:"image,omitempty"`
+	// Retention is the number of most recent dumps kept at the destination.
+	// +kubebuilder:default=7
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	Retention *int32 `json:"retention,omitempty"`
+	// Destination is where dumps are written.
+	// +optional
+	Destination *BackupDestination `json:"destination,omitempty"`
+}
+
+// BackupDestination selects where database dumps are stored.
+// +kubebuilder:validation:XValidation:rule="has(self.persistentVolumeClaim) != has(self.s3)",message="exactly one of persistentVolumeClaim or s3 must be set"
+type BackupDestination struct {
+	// PersistentVolumeClaim writes dumps to an existing PVC.
+	// +optional
+	PersistentVolumeClaim *PVCBackupDestination `json:"persistentVolumeClaim,omitempty"`
+	// S3 uploads dumps with the aws CLI.
+	// +optional
+	S3 *S3BackupDestination `json:"s3,omitempty"`
+}
+
+// PVCBackupDestination writes dumps to an existing PersistentVolumeClaim.
+type PVCBackupDestination struct {
+	// ClaimName is the PVC in the control plane namespace.
+	// +kubebuilder:validation:MinLength=1
+	ClaimName string `json:"claimName"`
+}
+
+// S3BackupDestination uploads dumps to an S3-compatible bucket.
+type S3BackupDestination struct {
+	// URL is the s3:// bucket and optional prefix that dumps are uploaded to.
+	// +kubebuilder:validation:Pattern=`^s3://`
+	URL string `json:"url"`
+	// Env configures the aws CLI, for example AWS_REGION, AWS_ENDPOINT_URL,
+	// and credentials from a Secret.
+	// +optional
+	Env []corev1.EnvVar `json:"env,omitempty"`
+	// EnvFrom loads aws CLI configuration from Secrets or ConfigMaps.
+	// +optional
+	EnvFrom []corev1.EnvFromSource `json:"envFrom,omitempty"`
+}
+
+// MigrationSpec configures the database migration Job.
+// +kubebuilder:validation:XValidation:rule="!has(self.enabled) || !self.enabled || (has(self.args) && size(self.args) > 0)",message="args is required when enabled is true"
+type MigrationSpec struct {
+	// Enabled runs a migration Job for each control plane image. The Deployment
+	// stays scaled to zero until the Job for the current image succeeds.
+	// +kubebuilder:default=false
+	Enabled bool `json:"enabled,omitempty"`
+	// Args are the container arguments for the migration Job and are required
+	// when Enabled is true. The Job uses the control plane image, env, envFrom,
+	// and volumes. Coder has no documented migrate-only subcommand, so Args must
+	// run a command in the image that migrates the database and exits.
+	// +optional
+	Args []string `json:"args,omitempty"`
+	// TTLSecondsAfterFinished is how long a finished migration Job is kept
+	// before Kubernetes deletes it.
+	// +kubebuilder:default=3600
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	TTLSecondsAfterFinished *int32 `json:"ttlSecondsAfterFinished,omitempty"`
+}
+
+// BuiltinProvisionerSpec configures the provisioner daemons embedded in coderd.
+type BuiltinProvisionerSpec struct {
+	// Daemons is the number of built-in provisioner daemons
+	// (CODER_PROVISIONER_DAEMONS). When omitted, Coder's default of 3 applies.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	Daemons *int32 `json:"daemons,omitempty"`
+	// ResourceFactorPercent is the share of the base container resources added
+	// for each daemon beyond Coder's default of 3. It applies only when
+	// Resources is unset; the base is ResourceProfile, or the "small" profile
+	// when no profile is selected.
+	// +kubebuilder:default=25
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	ResourceFactorPercent *int32 `json:"resourceFactorPercent,omitempty"`
+}
+
+// HighAvailabilitySpec configures networking for multi-replica control planes.
+type HighAvailabilitySpec struct {
+	// Enabled creates a headless Service (`<name>-mesh`) selecting the control
+	// plane pods while spec.replicas is greater than 1. Peers relay DERP traffic
+	// to each other over CODER_DERP_SERVER_RELAY_URL; the headless Service gives
+	// each pod stable DNS discovery alongside that pod-IP based relay address.
+	// +kubebuilder:default=false
+	Enabled bool `json:"enabled,omitempty"`
+}
+
+// DERPSpec configures the embedded DERP relay used between control plane
+// replicas. There is no mesh key field: coderd has no setting for one and
+// generates and stores the DERP mesh key in its database, shared by every
+// replica.
+type DERPSpec struct {
+	// RelayURL sets CODER_DERP_SERVER_RELAY_URL, the address other replicas
+	// use to reach this one. Each replica needs its own address, so the value
+	// may reference $(KUBE_POD_IP), which is expanded per pod, and must do so
+	// when spec.replicas is greater than 1. When omitted, the operator derives
+	// http://$(KUBE_POD_IP):8080 from the pod IP.
+	// +kubebuilder:validation:Pattern=`^https?://`
+	// +optional
+	RelayURL string `json:"relayURL,omitempty"`
+}
+
+// SecuritySpec configures Coder's auth cookie and reverse-proxy trust settings.
+type SecuritySpec struct {
+	// SecureAuthCookie maps to CODER_SECURE_AUTH_COOKIE. When omitted, it
+	// defaults to true when built-in TLS or Ingress TLS is configured, or when
+	// ProxyTrustedHeaders forwards X-Forwarded-Proto from a TLS-terminating proxy.
+	// Otherwise the operator leaves the variable unset and Coder's default of
+	// false applies.
+	// +optional
+	SecureAuthCookie *bool `json:"secureAuthCookie,omitempty"`
+	// ProxyTrustedHeaders maps to CODER_PROXY_TRUSTED_HEADERS.
+	// Coder only honors these headers from ProxyTrustedOrigins, so both must be set together.
+	// +optional
+	ProxyTrustedHeaders []string `json:"proxyTrustedHeaders,omitempty"`
+	// ProxyTrustedOrigins maps to CODER_PROXY_TRUSTED_ORIGINS (CIDR ranges).
+	// +optional
+	ProxyTrustedOrigins []string `json:"proxyTrustedOrigins,omitempty"`
 }
 
 // OperatorAccessSpec configures the controller-managed coderd operator user.
 type OperatorAccessSpec struct {
 	// Disabled turns off creation and management of the `coder-k8s-operator`
-	// user and API token.
+	// user and API token. Features that call the Coder API, such as license
+	// uploads and entitlements status, are skipped while disabled.
 	// +kubebuilder:default=false
 	Disabled bool `json:"disabled,omitempty"`
-	// GeneratedTokenSecretName stores the generated operator API token.
+	// GeneratedTokenSecretName names the Secret that stores the generated
+	// operator API token under the `token` key. When omitted, the controller
+	// uses `<name>-operator-token`, shortened with a hash suffix when the
+	// result would exceed 63 characters.
 	GeneratedTokenSecretName string `json:"generatedTokenSecretName,omitempty"`
+	// Username is the Coder user the operator token belongs to. When omitted,
+	// the operator manages a `coder-k8s-operator` system user. Naming an
+	// existing non-system user reuses that account as is: its email,
+	// password, login type, and site and organization roles are left
+	// unchanged. Changing the username revokes the token of the previous user
+	// and provisions a new one.
+	// +kubebuilder:validation:MaxLength=32
+	// +kubebuilder:validation:Pattern=`^[a-zA-Z0-9]+(-[a-zA-Z0-9]+)*$`
+	// +optional
+	Username string `json:"username,omitempty"`
+	// Roles are the site-wide Coder roles granted to the operator-managed
+	// system user. When omitted, it is granted `owner`. It is always an
+	// organization admin in every organization. Roles are not applied to an
+	// existing non-system user named by Username.
+	// +listType=set
+	// +kubebuilder:validation:items:MinLength=1
+	// +optional
+	Roles []string `json:"roles,omitempty"`
+}
+
+// AppliedLicenseStatus records an operator-managed license upload from spec.licenses.
+type AppliedLicenseStatus struct {
+	// SecretName is the name of the Secret the license was read from.
+	SecretName string `json:"secretName"`
+	// Key is the Secret data key the license was read from.
+	Key string `json:"key"`
+	// Hash is the SHA-256 hex hash of the trimmed license JWT.
+	Hash string `json:"hash"`
+	// UUID is the license ID from the JWT `jti` claim, used to detect whether
+	// coderd still has the license installed. Empty when the JWT has no ID.
+	// +optional
+	UUID string `json:"uuid,omitempty"`
+	// LastApplied is the timestamp of the most recent successful upload.
+	// +optional
+	LastApplied *metav1.Time `json:"lastApplied,omitempty"`
 }
 
 // CoderControlPlaneStatus defines the observed state of a CoderControlPlane.
@@ -130,18 +635,45 @@ type CoderControlPlaneStatus struct {
 	ReadyReplicas int32 `json:"readyReplicas,omitempty"`
 	// URL is the in-cluster URL for the control plane service.
 	URL string `json:"url,omitempty"`
+	// PublicURL is the public URL of the control plane, derived from the
+	// Ingress or Gateway host in spec.expose. Empty when exposure is not
+	// configured. Unlike spec.externalURL, which addresses an externally
+	// managed coderd, it is never used by the operator to reach Coder.
+	// +optional
+	PublicURL string `json:"publicURL,omitempty"`
 	// OperatorTokenSecretRef points to the Secret key containing the `coder-k8s-operator` API token.
 	OperatorTokenSecretRef *SecretKeySelector `json:"operatorTokenSecretRef,omitempty"`
 	// OperatorAccessReady reports whether operator API access bootstrap succeeded.
 	OperatorAccessReady bool `json:"operatorAccessReady,omitempty"`
+	// OperatorUsername is the Coder user that owns the current operator token.
+	// The controller uses it to revoke that token when spec.operatorAccess.username
+	// changes.
+	// +optional
+	OperatorUsername string `json:"operatorUsername,omitempty"`
+	// MigrationJobName is the name of the migration Job for the current image.
+	// +optional
+	MigrationJobName string `json:"migrationJobName,omitempty"`
+	// OperatorTokenRotatedAt is the timestamp of the most recent on-demand
+	// operator token rotation.
+	// +optional
+	OperatorTokenRotatedAt *metav1.Time `json:"operatorTokenRotatedAt,omitempty"`
+	// OperatorTokenRotationRequest is the RotateOperatorTokenAnnotation value
+	// that OperatorTokenRotatedAt refers to.
+	// +optional
+	OperatorTokenRotationRequest string `json:"operatorTokenRotationRequest,omitempty"`
 	// LicenseLastApplied is the timestamp of the most recent successful
 	// operator-managed license upload.
 	// +optional
 	LicenseLastApplied *metav1.Time `json:"licenseLastApplied,omitempty"`
-	// LicenseLastAppliedHash is the SHA-256 hex hash of the trimmed license JWT
-	// that LicenseLastApplied refers to.
+	// LicenseLastAppliedHash is the SHA-256 hex hash of the trimmed
+	// LicenseSecretRef value that LicenseLastApplied refers to.
 	// +optional
 	LicenseLastAppliedHash string `json:"licenseLastAppliedHash,omitempty"`
+	// Licenses tracks each license from spec.licenses that the operator has
+	// uploaded, with one entry per JWT. Entries are removed when their Secret
+	// key leaves spec.licenses or the JWT is removed from the Secret value.
+	// +optional
+	Licenses []AppliedLicenseStatus `json:"licenses,omitempty"`
 	// LicenseTier is a best-effort classification of the currently applied license.
 	// Values: none, trial, enterprise, premium, unknown.
 	// +optional
@@ -154,7 +686,18 @@ type CoderControlPlaneStatus struct {
 	// Values: entitled, grace_period, not_entitled, unknown.
 	// +optional
 	ExternalProvisionerDaemonsEntitlement string `json:"externalProvisionerDaemonsEntitlement,omitempty"`
-	// Phase is a high-level readiness indicator.
+	// ConnectedProvisionerDaemons is the number of external provisioner daemons
+	// connected to coderd, refreshed with the entitlements check. It stays zero
+	// when external provisioner daemons are not entitled or the control plane
+	// does not report them.
+	// +optional
+	ConnectedProvisionerDaemons int32 `json:"connectedProvisionerDaemons,omitempty"`
+	// CoderVersion is the Coder version the control plane reports in its build
+	// info, for example v2.20.0. It is unset until the control plane is ready
+	// and answers the query.
+	// +optional
+	CoderVersion string `json:"coderVersion,omitempty"`
+	// Phase is a high-level readiness indicator: Pending, Ready, or Suspended.
 	Phase string `json:"phase,omitempty"`
 	// Conditions are Kubernetes-standard conditions for this resource.
 	Conditions []metav1.Condition `json:"conditions,omitempty"`
@@ -162,8 +705,12 @@ type CoderControlPlaneStatus struct {
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 // +kubebuilder:object:root=true
-// +kubebuilder:resource:scope=Namespaced
+// +kubebuilder:resource:scope=Namespaced,shortName=ccp,categories=coder
 // +kubebuilder:subresource:status
+// +kubebuilder:storageversion
+// +kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
+// +kubebuilder:printcolumn:name="Public URL",type=string,JSONPath=`.status.publicURL`
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 
 // CoderControlPlane is the schema for Coder control plane resources.
 type CoderControlPlane struct {
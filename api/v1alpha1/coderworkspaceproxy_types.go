@@ -71,7 +71,7 @@ type WorkspaceProxyStatus struct {
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 // +kubebuilder:object:root=true
-// +kubebuilder:resource:path=coderworkspaceproxies,scope=Namespaced
+// +kubebuilder:resource:path=coderworkspaceproxies,scope=Namespaced,shortName=cwp,categories=coder
 // +kubebuilder:subresource:status
 
 // CoderWorkspaceProxy is the schema for Coder workspace proxy resources.
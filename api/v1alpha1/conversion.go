@@ -0,0 +1,5 @@
+package v1alpha1
+
+// Hub marks v1alpha1 as the conversion hub and storage version for
+// CoderControlPlane; other versions convert to and from it.
+func (*CoderControlPlane) Hub() {}
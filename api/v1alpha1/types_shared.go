@@ -3,6 +3,7 @@ package v1alpha1
 import (
 	corev1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 const (
@@ -20,6 +21,23 @@ type ServiceSpec struct {
 	// Port controls the exposed service port.
 	// +kubebuilder:default=80
 	Port int32 `json:"port,omitempty"`
+	// TargetPort overrides the container port, by name or number, that the
+	// primary service port forwards to. It must match a port the container
+	// exposes. For a control plane that is `http`, `https`, or one of
+	// spec.extraPorts, and the controller leaves the Service unchanged with
+	// ServiceTargetPortValid=False until it does; a workspace proxy only
+	// exposes `http`. When omitted, the primary port targets `http`, or
+	// `https` for a control plane with TLS enabled and Port 443.
+	// +optional
+	TargetPort *intstr.IntOrString `json:"targetPort,omitempty"`
+	// AppProtocol sets appProtocol on the service ports so service meshes
+	// route them correctly. "auto" sets "https" on ports that forward to a TLS
+	// listener and "http" on the others. "http" or "https" forces that value on
+	// the primary port and sets the remaining ports like "auto". Ports from
+	// spec.extraPorts are left unset. When omitted, no appProtocol is set.
+	// +kubebuilder:validation:Enum=auto;http;https
+	// +optional
+	AppProtocol string `json:"appProtocol,omitempty"`
 	// Annotations are applied to the reconciled service object.
 	Annotations map[string]string `json:"annotations,omitempty"`
 }
@@ -55,20 +73,62 @@ type RBACSpec struct {
 	// When omitted, the default is true.
 	// +kubebuilder:default=true
 	EnableDeployments *bool `json:"enableDeployments,omitempty"`
-	// ExtraRules are appended to the managed Role rules.
+	// ExtraRules are appended to the managed Role rules in every workspace
+	// namespace.
 	ExtraRules []rbacv1.PolicyRule `json:"extraRules,omitempty"`
-	// WorkspaceNamespaces lists additional namespaces for Role/RoleBinding creation.
+	// WorkspaceNamespaces lists additional namespaces, besides the control
+	// plane's own, where Coder may provision workspaces. The controller creates
+	// the same Role and RoleBinding in each namespace, binding the control
+	// plane ServiceAccount, and removes them when a namespace is dropped from
+	// this list or the control plane is deleted.
 	WorkspaceNamespaces []string `json:"workspaceNamespaces,omitempty"`
 }
 
 // TLSSpec configures Coder built-in TLS.
+// +kubebuilder:validation:XValidation:rule="!has(self.clientAuth) || self.clientAuth == 'none' || (has(self.clientCASecretName) && size(self.clientCASecretName) > 0)",message="clientCASecretName is required when clientAuth verifies client certificates"
 type TLSSpec struct {
 	// SecretNames lists TLS secrets to mount for built-in TLS.
 	// When non-empty, TLS is enabled on the Coder control plane.
+	// Each entry is "<secret-name>" or "<secret-name>:<port>". Coder serves every
+	// certificate on a single 8443 listener (exposed as Service port 443); an
+	// entry with a port adds a Service port of that number, named
+	// `https-<port>`, that also forwards to the 8443 listener.
 	SecretNames []string `json:"secretNames,omitempty"`
+	// RedirectHTTP controls whether plain HTTP requests are redirected to the
+	// HTTPS access URL (CODER_TLS_REDIRECT_HTTP_TO_HTTPS). Only valid when
+	// SecretNames is non-empty. When omitted, Coder's default (true) applies.
+	RedirectHTTP *bool `json:"redirectHTTP,omitempty"`
+	// HSTS configures the Strict-Transport-Security response header.
+	// Only valid when SecretNames is non-empty.
+	HSTS *HSTSSpec `json:"hsts,omitempty"`
+	// ClientAuth sets how Coder requests and verifies TLS client certificates
+	// (CODER_TLS_CLIENT_AUTH). Any mode other than "none" requires
+	// ClientCASecretName. Only valid when SecretNames is non-empty.
+	// +kubebuilder:validation:Enum=none;request;require-any;verify-if-given;require-and-verify
+	// +optional
+	ClientAuth string `json:"clientAuth,omitempty"`
+	// ClientCASecretName names a Secret whose "ca.crt" key holds the CA bundle
+	// used to verify client certificates (CODER_TLS_CLIENT_CA_FILE). Only
+	// valid when SecretNames is non-empty.
+	// +optional
+	ClientCASecretName string `json:"clientCASecretName,omitempty"`
+}
+
+// HSTSSpec configures the Strict-Transport-Security header served by Coder.
+type HSTSSpec struct {
+	// MaxAge is the header max-age in seconds (CODER_STRICT_TRANSPORT_SECURITY).
+	// +kubebuilder:validation:Minimum=1
+	MaxAge int64 `json:"maxAge"`
+	// IncludeSubdomains adds the includeSubDomains directive.
+	IncludeSubdomains bool `json:"includeSubdomains,omitempty"`
+	// Preload adds the preload directive.
+	Preload bool `json:"preload,omitempty"`
 }
 
 // ProbeSpec configures a Kubernetes probe with an enable toggle.
+// +kubebuilder:validation:XValidation:rule="(has(self.type) && self.type == 'exec') == (has(self.command) && size(self.command) > 0)",message="command is required for exec probes and only allowed for them"
+// +kubebuilder:validation:XValidation:rule="!has(self.healthPath) || !has(self.type) || self.type == 'httpGet'",message="healthPath is only allowed for httpGet probes"
+// +kubebuilder:validation:XValidation:rule="!has(self.port) || !has(self.type) || self.type != 'exec'",message="port is not allowed for exec probes"
 type ProbeSpec struct {
 	// Enabled toggles the probe on or off.
 	// When omitted, readiness defaults to enabled while liveness defaults to disabled.
@@ -84,16 +144,38 @@ type ProbeSpec struct {
 	SuccessThreshold *int32 `json:"successThreshold,omitempty"`
 	// FailureThreshold is the minimum consecutive failures for the probe to be considered failed.
 	FailureThreshold *int32 `json:"failureThreshold,omitempty"`
+	// Type selects the probe handler: httpGet requests HealthPath, tcpSocket
+	// opens a TCP connection to Port, and exec runs Command in the container.
+	// When omitted, the probe uses httpGet.
+	// +kubebuilder:validation:Enum=httpGet;tcpSocket;exec
+	// +optional
+	Type string `json:"type,omitempty"`
+	// HealthPath is the HTTP path an httpGet probe requests.
+	// When omitted, the probe uses /healthz.
+	// +kubebuilder:validation:Pattern=`^/`
+	HealthPath string `json:"healthPath,omitempty"`
+	// Port overrides the container port an httpGet or tcpSocket probe targets,
+	// by name or number. An httpGet probe's port must serve plain HTTP; use a
+	// tcpSocket probe to check the "https" port. When omitted, the probe uses
+	// the "http" port.
+	Port *intstr.IntOrString `json:"port,omitempty"`
+	// Command is the command an exec probe runs in the container. It is
+	// required for exec probes and not allowed for other types.
+	// +optional
+	Command []string `json:"command,omitempty"`
 }
 
 // ExposeSpec configures external exposure for the control plane.
-// At most one of Ingress or Gateway may be set.
+// At most one of Ingress or Gateway may be set. Removing the field deletes the
+// managed Ingress or HTTPRoute.
 // +kubebuilder:validation:XValidation:rule="!(has(self.ingress) && has(self.gateway))",message="only one of ingress or gateway may be set"
 type ExposeSpec struct {
 	// Ingress configures a networking.k8s.io/v1 Ingress.
 	// +optional
 	Ingress *IngressExposeSpec `json:"ingress,omitempty"`
-	// Gateway configures a gateway.networking.k8s.io/v1 HTTPRoute.
+	// Gateway configures a gateway.networking.k8s.io/v1 HTTPRoute. If no Gateway
+	// controller accepts the route after repeated reconciles, the controller backs
+	// off and sets the GatewayControllerMissing condition.
 	// +optional
 	Gateway *GatewayExposeSpec `json:"gateway,omitempty"`
 }
@@ -104,8 +186,20 @@ type IngressExposeSpec struct {
 	ClassName *string `json:"className,omitempty"`
 	// Host is the primary hostname for the Ingress rule.
 	Host string `json:"host"`
-	// WildcardHost is an optional wildcard hostname (e.g., for workspace apps).
+	// WildcardHost is an optional wildcard hostname (e.g., for workspace apps),
+	// such as `*.coder.example.com`. It gets its own Ingress rule routing to the
+	// Coder Service.
 	WildcardHost string `json:"wildcardHost,omitempty"`
+	// AdditionalHosts are extra hostnames, such as vanity domains, that each get
+	// an Ingress rule routing to the Coder Service. The primary Host remains the
+	// access URL.
+	// +optional
+	// +listType=set
+	// +kubebuilder:validation:items:MinLength=1
+	AdditionalHosts []string `json:"additionalHosts,omitempty"`
+	// DefaultBackend routes requests that match no rule to the Coder Service.
+	// +optional
+	DefaultBackend bool `json:"defaultBackend,omitempty"`
 	// Annotations are applied to the managed Ingress.
 	Annotations map[string]string `json:"annotations,omitempty"`
 	// TLS configures TLS termination at the Ingress.
@@ -119,18 +213,44 @@ type IngressTLSExposeSpec struct {
 	SecretName string `json:"secretName,omitempty"`
 	// WildcardSecretName is the TLS Secret for the wildcard host.
 	WildcardSecretName string `json:"wildcardSecretName,omitempty"`
+	// AdditionalHostsSecretName is the TLS Secret for the additional hosts. It
+	// must hold a certificate valid for every entry in additionalHosts.
+	AdditionalHostsSecretName string `json:"additionalHostsSecretName,omitempty"`
 }
 
 // GatewayExposeSpec defines Gateway API (HTTPRoute) exposure configuration.
 type GatewayExposeSpec struct {
 	// Host is the primary hostname for the HTTPRoute.
 	Host string `json:"host"`
-	// WildcardHost is an optional wildcard hostname.
+	// WildcardHost is an optional wildcard hostname, such as
+	// `*.coder.example.com`, for workspace apps.
 	WildcardHost string `json:"wildcardHost,omitempty"`
 	// ParentRefs are Gateways that the HTTPRoute attaches to.
 	// At least one parentRef is required when gateway exposure is configured.
 	// +kubebuilder:validation:MinItems=1
 	ParentRefs []GatewayParentRef `json:"parentRefs"`
+	// BackendTLS routes the HTTPRoute to the Coder Service https port and
+	// creates a BackendTLSPolicy so the Gateway connects to Coder over TLS.
+	// Requires spec.tls.secretNames. When unset, the HTTPRoute uses the http
+	// port.
+	// +optional
+	BackendTLS *GatewayBackendTLSSpec `json:"backendTLS,omitempty"`
+}
+
+// GatewayBackendTLSSpec configures TLS between the Gateway and Coder.
+type GatewayBackendTLSSpec struct {
+	// Hostname is the SNI name the Gateway sends, which Coder's serving
+	// certificate must match. Defaults to the Service DNS name
+	// `{name}.{namespace}.svc.cluster.local`.
+	// +optional
+	Hostname string `json:"hostname,omitempty"`
+	// CACertificateRefs name ConfigMaps in this namespace whose `ca.crt` key
+	// holds the CA that signed Coder's serving certificate. When empty, the
+	// Gateway validates the certificate against its system CAs.
+	// +optional
+	// +kubebuilder:validation:MaxItems=8
+	// +kubebuilder:validation:items:MinLength=1
+	CACertificateRefs []string `json:"caCertificateRefs,omitempty"`
 }
 
 // GatewayParentRef identifies a Gateway for HTTPRoute attachment.
@@ -150,6 +270,11 @@ type CertsSpec struct {
 	// Secrets lists Secret key selectors for CA certificates.
 	// Each is mounted at `/etc/ssl/certs/{name}.crt`.
 	Secrets []CertSecretSelector `json:"secrets,omitempty"`
+	// Projected consolidates all certificate secrets into a single projected
+	// volume mounted at `/etc/ssl/coder-ca`, with one `{name}-{key}.crt` file
+	// per selector, and points SSL_CERT_DIR at it.
+	// +optional
+	Projected bool `json:"projected,omitempty"`
 }
 
 // CertSecretSelector identifies a key within a Secret for CA cert mounting.
@@ -10,8 +10,133 @@ import (
 	rbacv1 "k8s.io/api/rbac/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
+	intstr "k8s.io/apimachinery/pkg/util/intstr"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AppliedLicenseStatus) DeepCopyInto(out *AppliedLicenseStatus) {
+	*out = *in
+	if in.LastApplied != nil {
+		in, out := &in.LastApplied, &out.LastApplied
+		*out = (*in).DeepCopy()
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AppliedLicenseStatus.
+func (in *AppliedLicenseStatus) DeepCopy() *AppliedLicenseStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(AppliedLicenseStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackupDestination) DeepCopyInto(out *BackupDestination) {
+	*out = *in
+	if in.PersistentVolumeClaim != nil {
+		in, out := &in.PersistentVolumeClaim, &out.PersistentVolumeClaim
+		*out = new(PVCBackupDestination)
+		**out = **in
+	}
+	if in.S3 != nil {
+		in, out := &in.S3, &out.S3
+		*out = new(S3BackupDestination)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupDestination.
+func (in *BackupDestination) DeepCopy() *BackupDestination {
+	if in == nil {
+		return nil
+	}
+	out := new(BackupDestination)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackupSpec) DeepCopyInto(out *BackupSpec) {
+	*out = *in
+	if in.Retention != nil {
+		in, out := &in.Retention, &out.Retention
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Destination != nil {
+		in, out := &in.Destination, &out.Destination
+		*out = new(BackupDestination)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupSpec.
+func (in *BackupSpec) DeepCopy() *BackupSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(BackupSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BuiltinDatabaseSpec) DeepCopyInto(out *BuiltinDatabaseSpec) {
+	*out = *in
+	if in.StorageSize != nil {
+		in, out := &in.StorageSize, &out.StorageSize
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.StorageClassName != nil {
+		in, out := &in.StorageClassName, &out.StorageClassName
+		*out = new(string)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BuiltinDatabaseSpec.
+func (in *BuiltinDatabaseSpec) DeepCopy() *BuiltinDatabaseSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(BuiltinDatabaseSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BuiltinProvisionerSpec) DeepCopyInto(out *BuiltinProvisionerSpec) {
+	*out = *in
+	if in.Daemons != nil {
+		in, out := &in.Daemons, &out.Daemons
+		*out = new(int32)
+		**out = **in
+	}
+	if in.ResourceFactorPercent != nil {
+		in, out := &in.ResourceFactorPercent, &out.ResourceFactorPercent
+		*out = new(int32)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BuiltinProvisionerSpec.
+func (in *BuiltinProvisionerSpec) DeepCopy() *BuiltinProvisionerSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(BuiltinProvisionerSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CertSecretSelector) DeepCopyInto(out *CertSecretSelector) {
 	*out = *in
@@ -118,6 +243,11 @@ func (in *CoderControlPlaneSpec) DeepCopyInto(out *CoderControlPlaneSpec) {
 		*out = new(int32)
 		**out = **in
 	}
+	if in.ManageDeployment != nil {
+		in, out := &in.ManageDeployment, &out.ManageDeployment
+		*out = new(bool)
+		**out = **in
+	}
 	in.Service.DeepCopyInto(&out.Service)
 	if in.ExtraArgs != nil {
 		in, out := &in.ExtraArgs, &out.ExtraArgs
@@ -136,12 +266,31 @@ func (in *CoderControlPlaneSpec) DeepCopyInto(out *CoderControlPlaneSpec) {
 		*out = make([]v1.LocalObjectReference, len(*in))
 		copy(*out, *in)
 	}
-	out.OperatorAccess = in.OperatorAccess
+	in.OperatorAccess.DeepCopyInto(&out.OperatorAccess)
 	if in.LicenseSecretRef != nil {
 		in, out := &in.LicenseSecretRef, &out.LicenseSecretRef
 		*out = new(SecretKeySelector)
 		**out = **in
 	}
+	if in.Licenses != nil {
+		in, out := &in.Licenses, &out.Licenses
+		*out = make([]SecretKeySelector, len(*in))
+		copy(*out, *in)
+	}
+	if in.CommonLabels != nil {
+		in, out := &in.CommonLabels, &out.CommonLabels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.CommonAnnotations != nil {
+		in, out := &in.CommonAnnotations, &out.CommonAnnotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 	in.ServiceAccount.DeepCopyInto(&out.ServiceAccount)
 	in.RBAC.DeepCopyInto(&out.RBAC)
 	if in.Resources != nil {
@@ -149,6 +298,11 @@ func (in *CoderControlPlaneSpec) DeepCopyInto(out *CoderControlPlaneSpec) {
 		*out = new(v1.ResourceRequirements)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Provisioner != nil {
+		in, out := &in.Provisioner, &out.Provisioner
+		*out = new(BuiltinProvisionerSpec)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.SecurityContext != nil {
 		in, out := &in.SecurityContext, &out.SecurityContext
 		*out = new(v1.SecurityContext)
@@ -179,6 +333,13 @@ func (in *CoderControlPlaneSpec) DeepCopyInto(out *CoderControlPlaneSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Config != nil {
+		in, out := &in.Config, &out.Config
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 	if in.Volumes != nil {
 		in, out := &in.Volumes, &out.Volumes
 		*out = make([]v1.Volume, len(*in))
@@ -193,6 +354,18 @@ func (in *CoderControlPlaneSpec) DeepCopyInto(out *CoderControlPlaneSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.ProjectedTokens != nil {
+		in, out := &in.ProjectedTokens, &out.ProjectedTokens
+		*out = make([]ProjectedTokenSpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ExtraPorts != nil {
+		in, out := &in.ExtraPorts, &out.ExtraPorts
+		*out = make([]v1.ContainerPort, len(*in))
+		copy(*out, *in)
+	}
 	in.Certs.DeepCopyInto(&out.Certs)
 	if in.NodeSelector != nil {
 		in, out := &in.NodeSelector, &out.NodeSelector
@@ -220,6 +393,58 @@ func (in *CoderControlPlaneSpec) DeepCopyInto(out *CoderControlPlaneSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.HostAliases != nil {
+		in, out := &in.HostAliases, &out.HostAliases
+		*out = make([]v1.HostAlias, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.DNSConfig != nil {
+		in, out := &in.DNSConfig, &out.DNSConfig
+		*out = new(v1.PodDNSConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Lifecycle != nil {
+		in, out := &in.Lifecycle, &out.Lifecycle
+		*out = new(v1.Lifecycle)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TerminationGracePeriodSeconds != nil {
+		in, out := &in.TerminationGracePeriodSeconds, &out.TerminationGracePeriodSeconds
+		*out = new(int64)
+		**out = **in
+	}
+	if in.HighAvailability != nil {
+		in, out := &in.HighAvailability, &out.HighAvailability
+		*out = new(HighAvailabilitySpec)
+		**out = **in
+	}
+	if in.DERP != nil {
+		in, out := &in.DERP, &out.DERP
+		*out = new(DERPSpec)
+		**out = **in
+	}
+	if in.Security != nil {
+		in, out := &in.Security, &out.Security
+		*out = new(SecuritySpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Migration != nil {
+		in, out := &in.Migration, &out.Migration
+		*out = new(MigrationSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Database != nil {
+		in, out := &in.Database, &out.Database
+		*out = new(DatabaseSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Backup != nil {
+		in, out := &in.Backup, &out.Backup
+		*out = new(BackupSpec)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -241,10 +466,21 @@ func (in *CoderControlPlaneStatus) DeepCopyInto(out *CoderControlPlaneStatus) {
 		*out = new(SecretKeySelector)
 		**out = **in
 	}
+	if in.OperatorTokenRotatedAt != nil {
+		in, out := &in.OperatorTokenRotatedAt, &out.OperatorTokenRotatedAt
+		*out = (*in).DeepCopy()
+	}
 	if in.LicenseLastApplied != nil {
 		in, out := &in.LicenseLastApplied, &out.LicenseLastApplied
 		*out = (*in).DeepCopy()
 	}
+	if in.Licenses != nil {
+		in, out := &in.Licenses, &out.Licenses
+		*out = make([]AppliedLicenseStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	if in.EntitlementsLastChecked != nil {
 		in, out := &in.EntitlementsLastChecked, &out.EntitlementsLastChecked
 		*out = (*in).DeepCopy()
@@ -488,6 +724,43 @@ func (in *CoderWorkspaceProxyList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DERPSpec) DeepCopyInto(out *DERPSpec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DERPSpec.
+func (in *DERPSpec) DeepCopy() *DERPSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DERPSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DatabaseSpec) DeepCopyInto(out *DatabaseSpec) {
+	*out = *in
+	if in.Builtin != nil {
+		in, out := &in.Builtin, &out.Builtin
+		*out = new(BuiltinDatabaseSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DatabaseSpec.
+func (in *DatabaseSpec) DeepCopy() *DatabaseSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DatabaseSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ExposeSpec) DeepCopyInto(out *ExposeSpec) {
 	*out = *in
@@ -514,6 +787,27 @@ func (in *ExposeSpec) DeepCopy() *ExposeSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GatewayBackendTLSSpec) DeepCopyInto(out *GatewayBackendTLSSpec) {
+	*out = *in
+	if in.CACertificateRefs != nil {
+		in, out := &in.CACertificateRefs, &out.CACertificateRefs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GatewayBackendTLSSpec.
+func (in *GatewayBackendTLSSpec) DeepCopy() *GatewayBackendTLSSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(GatewayBackendTLSSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *GatewayExposeSpec) DeepCopyInto(out *GatewayExposeSpec) {
 	*out = *in
@@ -524,6 +818,11 @@ func (in *GatewayExposeSpec) DeepCopyInto(out *GatewayExposeSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.BackendTLS != nil {
+		in, out := &in.BackendTLS, &out.BackendTLS
+		*out = new(GatewayBackendTLSSpec)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -563,6 +862,38 @@ func (in *GatewayParentRef) DeepCopy() *GatewayParentRef {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HSTSSpec) DeepCopyInto(out *HSTSSpec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HSTSSpec.
+func (in *HSTSSpec) DeepCopy() *HSTSSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(HSTSSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HighAvailabilitySpec) DeepCopyInto(out *HighAvailabilitySpec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HighAvailabilitySpec.
+func (in *HighAvailabilitySpec) DeepCopy() *HighAvailabilitySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(HighAvailabilitySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *IngressExposeSpec) DeepCopyInto(out *IngressExposeSpec) {
 	*out = *in
@@ -571,6 +902,11 @@ func (in *IngressExposeSpec) DeepCopyInto(out *IngressExposeSpec) {
 		*out = new(string)
 		**out = **in
 	}
+	if in.AdditionalHosts != nil {
+		in, out := &in.AdditionalHosts, &out.AdditionalHosts
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	if in.Annotations != nil {
 		in, out := &in.Annotations, &out.Annotations
 		*out = make(map[string]string, len(*in))
@@ -612,9 +948,40 @@ func (in *IngressTLSExposeSpec) DeepCopy() *IngressTLSExposeSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MigrationSpec) DeepCopyInto(out *MigrationSpec) {
+	*out = *in
+	if in.Args != nil {
+		in, out := &in.Args, &out.Args
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.TTLSecondsAfterFinished != nil {
+		in, out := &in.TTLSecondsAfterFinished, &out.TTLSecondsAfterFinished
+		*out = new(int32)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MigrationSpec.
+func (in *MigrationSpec) DeepCopy() *MigrationSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(MigrationSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *OperatorAccessSpec) DeepCopyInto(out *OperatorAccessSpec) {
 	*out = *in
+	if in.Roles != nil {
+		in, out := &in.Roles, &out.Roles
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
@@ -628,6 +995,22 @@ func (in *OperatorAccessSpec) DeepCopy() *OperatorAccessSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PVCBackupDestination) DeepCopyInto(out *PVCBackupDestination) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PVCBackupDestination.
+func (in *PVCBackupDestination) DeepCopy() *PVCBackupDestination {
+	if in == nil {
+		return nil
+	}
+	out := new(PVCBackupDestination)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ProbeSpec) DeepCopyInto(out *ProbeSpec) {
 	*out = *in
@@ -656,6 +1039,16 @@ func (in *ProbeSpec) DeepCopyInto(out *ProbeSpec) {
 		*out = new(int32)
 		**out = **in
 	}
+	if in.Port != nil {
+		in, out := &in.Port, &out.Port
+		*out = new(intstr.IntOrString)
+		**out = **in
+	}
+	if in.Command != nil {
+		in, out := &in.Command, &out.Command
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
@@ -669,6 +1062,27 @@ func (in *ProbeSpec) DeepCopy() *ProbeSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProjectedTokenSpec) DeepCopyInto(out *ProjectedTokenSpec) {
+	*out = *in
+	if in.ExpirationSeconds != nil {
+		in, out := &in.ExpirationSeconds, &out.ExpirationSeconds
+		*out = new(int64)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProjectedTokenSpec.
+func (in *ProjectedTokenSpec) DeepCopy() *ProjectedTokenSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ProjectedTokenSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ProxyBootstrapSpec) DeepCopyInto(out *ProxyBootstrapSpec) {
 	*out = *in
@@ -724,6 +1138,36 @@ func (in *RBACSpec) DeepCopy() *RBACSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *S3BackupDestination) DeepCopyInto(out *S3BackupDestination) {
+	*out = *in
+	if in.Env != nil {
+		in, out := &in.Env, &out.Env
+		*out = make([]v1.EnvVar, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.EnvFrom != nil {
+		in, out := &in.EnvFrom, &out.EnvFrom
+		*out = make([]v1.EnvFromSource, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new S3BackupDestination.
+func (in *S3BackupDestination) DeepCopy() *S3BackupDestination {
+	if in == nil {
+		return nil
+	}
+	out := new(S3BackupDestination)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SecretKeySelector) DeepCopyInto(out *SecretKeySelector) {
 	*out = *in
@@ -740,6 +1184,37 @@ func (in *SecretKeySelector) DeepCopy() *SecretKeySelector {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SecuritySpec) DeepCopyInto(out *SecuritySpec) {
+	*out = *in
+	if in.SecureAuthCookie != nil {
+		in, out := &in.SecureAuthCookie, &out.SecureAuthCookie
+		*out = new(bool)
+		**out = **in
+	}
+	if in.ProxyTrustedHeaders != nil {
+		in, out := &in.ProxyTrustedHeaders, &out.ProxyTrustedHeaders
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ProxyTrustedOrigins != nil {
+		in, out := &in.ProxyTrustedOrigins, &out.ProxyTrustedOrigins
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecuritySpec.
+func (in *SecuritySpec) DeepCopy() *SecuritySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SecuritySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ServiceAccountSpec) DeepCopyInto(out *ServiceAccountSpec) {
 	*out = *in
@@ -773,6 +1248,11 @@ func (in *ServiceAccountSpec) DeepCopy() *ServiceAccountSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ServiceSpec) DeepCopyInto(out *ServiceSpec) {
 	*out = *in
+	if in.TargetPort != nil {
+		in, out := &in.TargetPort, &out.TargetPort
+		*out = new(intstr.IntOrString)
+		**out = **in
+	}
 	if in.Annotations != nil {
 		in, out := &in.Annotations, &out.Annotations
 		*out = make(map[string]string, len(*in))
@@ -801,6 +1281,16 @@ func (in *TLSSpec) DeepCopyInto(out *TLSSpec) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.RedirectHTTP != nil {
+		in, out := &in.RedirectHTTP, &out.RedirectHTTP
+		*out = new(bool)
+		**out = **in
+	}
+	if in.HSTS != nil {
+		in, out := &in.HSTS, &out.HSTS
+		*out = new(HSTSSpec)
+		**out = **in
+	}
 	return
 }
 
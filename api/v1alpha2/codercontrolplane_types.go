@@ -0,0 +1,42 @@
+package v1alpha2
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	coderv1alpha1 "github.com/coder/coder-k8s/api/v1alpha1"
+)
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Namespaced,shortName=ccp,categories=coder
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
+// +kubebuilder:printcolumn:name="Public URL",type=string,JSONPath=`.status.publicURL`
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// CoderControlPlane is the schema for Coder control plane resources.
+//
+// The v1alpha2 schema currently matches v1alpha1. Fields that change shape are
+// copied into this package as they diverge, with the mapping kept in
+// conversion.go.
+type CoderControlPlane struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   coderv1alpha1.CoderControlPlaneSpec   `json:"spec,omitempty"`
+	Status coderv1alpha1.CoderControlPlaneStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:object:root=true
+
+// CoderControlPlaneList contains a list of CoderControlPlane objects.
+type CoderControlPlaneList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []CoderControlPlane `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&CoderControlPlane{}, &CoderControlPlaneList{})
+}
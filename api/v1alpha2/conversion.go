@@ -0,0 +1,39 @@
+package v1alpha2
+
+import (
+	"fmt"
+
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+
+	coderv1alpha1 "github.com/coder/coder-k8s/api/v1alpha1"
+)
+
+var _ conversion.Convertible = (*CoderControlPlane)(nil)
+
+// ConvertTo converts this CoderControlPlane to the v1alpha1 hub version.
+func (cp *CoderControlPlane) ConvertTo(dstRaw conversion.Hub) error {
+	dst, ok := dstRaw.(*coderv1alpha1.CoderControlPlane)
+	if !ok {
+		return fmt.Errorf("assertion failed: expected *v1alpha1.CoderControlPlane hub, got %T", dstRaw)
+	}
+
+	dst.ObjectMeta = *cp.ObjectMeta.DeepCopy()
+	dst.Spec = *cp.Spec.DeepCopy()
+	dst.Status = *cp.Status.DeepCopy()
+
+	return nil
+}
+
+// ConvertFrom converts the v1alpha1 hub version to this CoderControlPlane.
+func (cp *CoderControlPlane) ConvertFrom(srcRaw conversion.Hub) error {
+	src, ok := srcRaw.(*coderv1alpha1.CoderControlPlane)
+	if !ok {
+		return fmt.Errorf("assertion failed: expected *v1alpha1.CoderControlPlane hub, got %T", srcRaw)
+	}
+
+	cp.ObjectMeta = *src.ObjectMeta.DeepCopy()
+	cp.Spec = *src.Spec.DeepCopy()
+	cp.Status = *src.Status.DeepCopy()
+
+	return nil
+}
@@ -0,0 +1,62 @@
+package v1alpha2_test
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	coderv1alpha1 "github.com/coder/coder-k8s/api/v1alpha1"
+	coderv1alpha2 "github.com/coder/coder-k8s/api/v1alpha2"
+)
+
+func TestCoderControlPlaneConversionRoundTrip(t *testing.T) {
+	hub := &coderv1alpha1.CoderControlPlane{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "roundtrip",
+			Namespace:   "coder",
+			Labels:      map[string]string{"team": "platform"},
+			Annotations: map[string]string{coderv1alpha1.RotateOperatorTokenAnnotation: "1"},
+		},
+		Spec: coderv1alpha1.CoderControlPlaneSpec{
+			Image:       "ghcr.io/coder/coder:v2.20.0",
+			ExternalURL: "https://coder.example.com",
+			ExtraArgs:   []string{"--verbose"},
+			Migration:   &coderv1alpha1.MigrationSpec{Enabled: true},
+		},
+		Status: coderv1alpha1.CoderControlPlaneStatus{
+			Phase: coderv1alpha1.CoderControlPlanePhaseReady,
+			URL:   "http://roundtrip.coder.svc.cluster.local:80",
+			Conditions: []metav1.Condition{{
+				Type:   coderv1alpha1.CoderControlPlaneConditionMigrationComplete,
+				Status: metav1.ConditionTrue,
+				Reason: "Succeeded",
+			}},
+		},
+	}
+
+	spoke := &coderv1alpha2.CoderControlPlane{}
+	if err := spoke.ConvertFrom(hub); err != nil {
+		t.Fatalf("convert from hub: %v", err)
+	}
+	if spoke.Spec.Image != hub.Spec.Image {
+		t.Fatalf("expected image %q after conversion, got %q", hub.Spec.Image, spoke.Spec.Image)
+	}
+
+	// The spoke must not alias the hub's slices and maps.
+	spoke.Spec.ExtraArgs[0] = "--mutated"
+	if hub.Spec.ExtraArgs[0] != "--verbose" {
+		t.Fatal("expected conversion to deep-copy spec fields")
+	}
+	spoke.Spec.ExtraArgs[0] = "--verbose"
+
+	roundTripped := &coderv1alpha1.CoderControlPlane{}
+	if err := spoke.ConvertTo(roundTripped); err != nil {
+		t.Fatalf("convert to hub: %v", err)
+	}
+	if !equality.Semantic.DeepEqual(hub.ObjectMeta, roundTripped.ObjectMeta) ||
+		!equality.Semantic.DeepEqual(hub.Spec, roundTripped.Spec) ||
+		!equality.Semantic.DeepEqual(hub.Status, roundTripped.Status) {
+		t.Fatalf("expected lossless round trip, got %+v", roundTripped)
+	}
+}
@@ -0,0 +1,6 @@
+// Package v1alpha2 contains the next API schema definitions for the coder.com
+// API group. v1alpha1 remains the storage version; objects convert through it.
+//
+// +k8s:deepcopy-gen=package
+// +groupName=coder.com
+package v1alpha2
@@ -10,18 +10,20 @@ import (
 
 	ctrl "sigs.k8s.io/controller-runtime"
 
+	"github.com/coder/coder-k8s/internal/aggregated/coder"
 	"github.com/coder/coder-k8s/internal/app/allapp"
 	"github.com/coder/coder-k8s/internal/app/apiserverapp"
 	"github.com/coder/coder-k8s/internal/app/controllerapp"
 	"github.com/coder/coder-k8s/internal/app/mcpapp"
+	"github.com/coder/coder-k8s/internal/controller"
 )
 
 const supportedAppModes = "all, controller, aggregated-apiserver, mcp-http"
 
 var (
-	runAllApp                 func(context.Context, time.Duration) error = allapp.Run
-	runControllerApp                                                     = controllerapp.Run
-	runAggregatedAPIServerApp                                            = func(ctx context.Context, opts apiserverapp.Options) error {
+	runAllApp                 func(context.Context, time.Duration, controllerapp.Options) error = allapp.RunWithOptions
+	runControllerApp                                                                            = controllerapp.RunWithOptions
+	runAggregatedAPIServerApp                                                                   = func(ctx context.Context, opts apiserverapp.Options) error {
 		return apiserverapp.RunWithOptions(ctx, opts)
 	}
 	runMCPHTTPApp      = mcpapp.RunHTTP
@@ -34,8 +36,25 @@ func run(args []string) error {
 		appMode             string
 		coderURL            string
 		coderSessionToken   string
+		sessionTokenFile    string
+		tokenReloadInterval time.Duration
 		coderNamespace      string
+		coderOrganizations  string
+		namespaceSelector   string
+		adminTokenSecret    string
 		coderRequestTimeout time.Duration
+		defaultCoderImage   string
+		defaultResources    string
+		extraEnvAllow       string
+		extraEnvDeny        string
+		resyncPeriod        time.Duration
+		disableDriftRequeue bool
+		watchNamespaces     string
+		templateGitSources  bool
+
+		licenseDuplicateUploadMaxAttempts int
+		licenseDuplicateUploadBackoff     time.Duration
+		enableConversionWebhook           bool
 	)
 	fs.StringVar(&appMode, "app", "all", "Application mode (all, controller, aggregated-apiserver, mcp-http)")
 	fs.StringVar(
@@ -44,6 +63,18 @@ func run(args []string) error {
 		"",
 		"Admin session token for the backing Coder deployment",
 	)
+	fs.StringVar(
+		&sessionTokenFile,
+		"coder-session-token-file",
+		"",
+		"File holding the admin session token, for example a mounted secret; re-read periodically and after Coder rejects the token",
+	)
+	fs.DurationVar(
+		&tokenReloadInterval,
+		"coder-session-token-reload-interval",
+		coder.DefaultSessionTokenReloadInterval,
+		"How often --coder-session-token-file is re-read",
+	)
 	fs.StringVar(
 		&coderURL,
 		"coder-url",
@@ -56,15 +87,139 @@ func run(args []string) error {
 		"",
 		"Restrict the aggregated API server to serve only this Kubernetes namespace",
 	)
+	fs.StringVar(
+		&coderOrganizations,
+		"coder-organizations",
+		"",
+		"Comma-separated Coder organization names the aggregated API server lists (default all organizations)",
+	)
+	fs.StringVar(
+		&namespaceSelector,
+		"coder-namespace-selector",
+		"",
+		"Serve every namespace matching this label selector (for example, "+coder.DefaultControlPlaneNamespaceSelector+
+			"), each backed by the Coder deployment in its admin token secret",
+	)
+	fs.StringVar(
+		&adminTokenSecret,
+		"coder-admin-token-secret",
+		coder.DefaultAdminTokenSecretName,
+		"Name of the per-namespace secret holding the Coder url and token keys, used with --coder-namespace-selector",
+	)
 	fs.DurationVar(
 		&coderRequestTimeout,
 		"coder-request-timeout",
 		30*time.Second,
 		"Timeout for Coder SDK API requests",
 	)
+	fs.StringVar(
+		&defaultCoderImage,
+		"default-coder-image",
+		"",
+		"Coder image used when a resource does not set spec.image (default ghcr.io/coder/coder:latest)",
+	)
+	fs.StringVar(
+		&defaultResources,
+		"default-resources",
+		"",
+		"JSON ResourceRequirements for the CoderControlPlane coder container when spec.resources and spec.resourceProfile are unset",
+	)
+	fs.StringVar(
+		&extraEnvAllow,
+		"extra-env-allow",
+		"",
+		"Comma-separated env var name patterns (for example, CODER_TELEMETRY_*) that are the only names CoderControlPlane spec.extraEnv, spec.envFrom, and spec.extraArgs flags may set (default all names)",
+	)
+	fs.StringVar(
+		&extraEnvDeny,
+		"extra-env-deny",
+		"",
+		"Comma-separated env var name patterns (for example, CODER_OIDC_*) that CoderControlPlane spec.extraEnv, spec.envFrom, and spec.extraArgs flags may not set; takes precedence over --extra-env-allow",
+	)
+	fs.DurationVar(
+		&resyncPeriod,
+		"resync-period",
+		0,
+		"How often the controller re-reconciles every resource to correct drift (default about 10h); each reconcile queries Coder",
+	)
+	fs.BoolVar(
+		&disableDriftRequeue,
+		"disable-drift-requeue",
+		false,
+		"Rely on watches instead of periodic requeues to correct gateway and cross-namespace workspace RBAC drift",
+	)
+	fs.BoolVar(
+		&enableConversionWebhook,
+		"enable-conversion-webhook",
+		false,
+		"Serve the CoderControlPlane conversion webhook; required when the CRD uses the Webhook conversion strategy from config/crd-conversion-webhook",
+	)
+	fs.StringVar(
+		&watchNamespaces,
+		"watch-namespaces",
+		"",
+		"Comma-separated namespaces whose CoderControlPlane resources the controller reconciles (default all namespaces)",
+	)
+	fs.IntVar(
+		&licenseDuplicateUploadMaxAttempts,
+		"license-duplicate-upload-max-attempts",
+		0,
+		"Re-uploads of a license coderd rejects as a duplicate without reporting it installed, before giving up until the Secret changes (default 5)",
+	)
+	fs.DurationVar(
+		&licenseDuplicateUploadBackoff,
+		"license-duplicate-upload-backoff",
+		0,
+		"First delay between those license re-uploads, doubling per attempt up to 5m (default 10s)",
+	)
+	fs.BoolVar(
+		&templateGitSources,
+		"enable-template-git-sources",
+		false,
+		"Let CoderTemplate spec.gitSource fetch template source from Git repositories (requires git on PATH)",
+	)
 	if err := fs.Parse(args); err != nil {
 		return err
 	}
+	if resyncPeriod < 0 {
+		return fmt.Errorf("assertion failed: invalid --resync-period %s: must not be negative", resyncPeriod)
+	}
+	if licenseDuplicateUploadMaxAttempts < 0 {
+		return fmt.Errorf(
+			"assertion failed: invalid --license-duplicate-upload-max-attempts %d: must not be negative",
+			licenseDuplicateUploadMaxAttempts,
+		)
+	}
+	if licenseDuplicateUploadBackoff < 0 {
+		return fmt.Errorf(
+			"assertion failed: invalid --license-duplicate-upload-backoff %s: must not be negative",
+			licenseDuplicateUploadBackoff,
+		)
+	}
+	parsedDefaultResources, err := controller.ParseDefaultResources(defaultResources)
+	if err != nil {
+		return fmt.Errorf("assertion failed: invalid --default-resources: %w", err)
+	}
+	allowedEnvNames, err := controller.ParseEnvNamePatterns(extraEnvAllow)
+	if err != nil {
+		return fmt.Errorf("assertion failed: invalid --extra-env-allow: %w", err)
+	}
+	deniedEnvNames, err := controller.ParseEnvNamePatterns(extraEnvDeny)
+	if err != nil {
+		return fmt.Errorf("assertion failed: invalid --extra-env-deny: %w", err)
+	}
+	controllerOpts := controllerapp.Options{
+		DefaultCoderImage:   strings.TrimSpace(defaultCoderImage),
+		DefaultResources:    parsedDefaultResources,
+		EnvPolicy:           controller.EnvPolicy{Allowed: allowedEnvNames, Denied: deniedEnvNames},
+		ResyncPeriod:        resyncPeriod,
+		DisableDriftRequeue: disableDriftRequeue,
+		WatchNamespaces:     controllerapp.ParseNamespaceList(watchNamespaces),
+
+		LicenseDuplicateUploadMaxAttempts: licenseDuplicateUploadMaxAttempts,
+		LicenseDuplicateUploadBackoff:     licenseDuplicateUploadBackoff,
+		EnableConversionWebhook:           enableConversionWebhook,
+	}
 
 	if coderURL != "" {
 		parsedCoderURL, err := url.Parse(coderURL)
@@ -85,15 +240,21 @@ func run(args []string) error {
 
 	switch appMode {
 	case "all":
-		return runAllApp(setupSignalHandler(), coderRequestTimeout)
+		return runAllApp(setupSignalHandler(), coderRequestTimeout, controllerOpts)
 	case "controller":
-		return runControllerApp(setupSignalHandler())
+		return runControllerApp(setupSignalHandler(), controllerOpts)
 	case "aggregated-apiserver":
 		opts := apiserverapp.Options{
-			CoderURL:            coderURL,
-			CoderSessionToken:   coderSessionToken,
-			CoderNamespace:      coderNamespace,
-			CoderRequestTimeout: coderRequestTimeout,
+			CoderURL:                        coderURL,
+			CoderSessionToken:               coderSessionToken,
+			CoderSessionTokenFile:           sessionTokenFile,
+			CoderSessionTokenReloadInterval: tokenReloadInterval,
+			CoderNamespace:                  coderNamespace,
+			CoderOrganizations:              coder.ParseOrganizationList(coderOrganizations),
+			CoderNamespaceSelector:          namespaceSelector,
+			CoderAdminTokenSecret:           adminTokenSecret,
+			CoderRequestTimeout:             coderRequestTimeout,
+			EnableTemplateGitSources:        templateGitSources,
 		}
 		return runAggregatedAPIServerApp(setupSignalHandler(), opts)
 	case "mcp-http":
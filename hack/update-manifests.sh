@@ -18,7 +18,7 @@ cd "${SCRIPT_ROOT}"
 # Generate CRDs for operator-owned coder.com APIs only.
 GOFLAGS=-mod=vendor go run ./vendor/sigs.k8s.io/controller-tools/cmd/controller-gen \
 	crd:crdVersions=v1 \
-	paths=./api/v1alpha1 \
+	paths="{./api/v1alpha1,./api/v1alpha2}" \
 	output:crd:artifacts:config=config/crd/bases
 
 # Generate RBAC across the repo.
@@ -0,0 +1,143 @@
+package coder
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/coder/coder/v2/codersdk"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// CachingClientProvider reuses one Coder SDK client per namespace on top of
+// another ClientProvider. Every call still asks the wrapped provider for the
+// current URL and session token, so a rotated operator token or a moved
+// control plane replaces the cached client on the next request. Cached clients
+// share one HTTP transport, so requests to the same coderd reuse keep-alive
+// connections.
+type CachingClientProvider struct {
+	inner     ClientProvider
+	transport http.RoundTripper
+
+	mu      sync.Mutex
+	clients map[string]cachedClient
+}
+
+type cachedClient struct {
+	url          string
+	sessionToken string
+	client       *codersdk.Client
+}
+
+var (
+	_ ClientProvider     = (*CachingClientProvider)(nil)
+	_ NamespaceResolver  = (*CachingClientProvider)(nil)
+	_ NamespaceLister    = (*CachingClientProvider)(nil)
+	_ OrganizationFilter = (*CachingClientProvider)(nil)
+)
+
+// NewCachingClientProvider wraps inner with a per-namespace client cache whose
+// clients use transport.
+func NewCachingClientProvider(inner ClientProvider, transport http.RoundTripper) (*CachingClientProvider, error) {
+	if inner == nil {
+		return nil, fmt.Errorf("assertion failed: inner client provider must not be nil")
+	}
+	if transport == nil {
+		return nil, fmt.Errorf("assertion failed: transport must not be nil")
+	}
+
+	return &CachingClientProvider{
+		inner:     inner,
+		transport: transport,
+		clients:   make(map[string]cachedClient),
+	}, nil
+}
+
+// ClientForNamespace returns the cached client for namespace while the wrapped
+// provider keeps resolving the same URL and session token.
+func (p *CachingClientProvider) ClientForNamespace(ctx context.Context, namespace string) (*codersdk.Client, error) {
+	if p == nil {
+		return nil, fmt.Errorf("assertion failed: caching client provider must not be nil")
+	}
+	if ctx == nil {
+		return nil, fmt.Errorf("assertion failed: context must not be nil")
+	}
+
+	resolved, err := p.inner.ClientForNamespace(ctx, namespace)
+	if err != nil {
+		p.mu.Lock()
+		delete(p.clients, namespace)
+		p.mu.Unlock()
+		return nil, err
+	}
+	if resolved == nil || resolved.URL == nil || resolved.HTTPClient == nil {
+		return nil, fmt.Errorf("assertion failed: inner client provider returned an incomplete client")
+	}
+
+	resolvedURL := resolved.URL.String()
+	sessionToken := resolved.SessionToken()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if cached, ok := p.clients[namespace]; ok && cached.url == resolvedURL && cached.sessionToken == sessionToken {
+		return cached.client, nil
+	}
+
+	sdkClient, err := NewSDKClient(Config{
+		CoderURL:       resolved.URL,
+		SessionToken:   sessionToken,
+		RequestTimeout: resolved.HTTPClient.Timeout,
+		Transport:      p.transport,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("construct cached Coder SDK client for namespace %q: %w", namespace, err)
+	}
+	p.clients[namespace] = cachedClient{url: resolvedURL, sessionToken: sessionToken, client: sdkClient}
+
+	return sdkClient, nil
+}
+
+// DefaultNamespace delegates to the wrapped provider.
+func (p *CachingClientProvider) DefaultNamespace(ctx context.Context) (string, error) {
+	if p == nil {
+		return "", fmt.Errorf("assertion failed: caching client provider must not be nil")
+	}
+
+	resolver, ok := p.inner.(NamespaceResolver)
+	if !ok {
+		return "", apierrors.NewServiceUnavailable("wrapped client provider does not implement namespace resolution")
+	}
+
+	return resolver.DefaultNamespace(ctx)
+}
+
+// EligibleNamespaces delegates to the wrapped provider.
+func (p *CachingClientProvider) EligibleNamespaces(ctx context.Context) ([]string, error) {
+	if p == nil {
+		return nil, fmt.Errorf("assertion failed: caching client provider must not be nil")
+	}
+
+	lister, ok := p.inner.(NamespaceLister)
+	if !ok {
+		return nil, apierrors.NewServiceUnavailable("wrapped client provider does not implement namespace listing")
+	}
+
+	return lister.EligibleNamespaces(ctx)
+}
+
+// AllowedOrganizations delegates to the wrapped provider. Providers without an
+// organization filter allow every organization.
+func (p *CachingClientProvider) AllowedOrganizations(ctx context.Context, namespace string) ([]string, error) {
+	if p == nil {
+		return nil, fmt.Errorf("assertion failed: caching client provider must not be nil")
+	}
+
+	filter, ok := p.inner.(OrganizationFilter)
+	if !ok {
+		return nil, nil
+	}
+
+	return filter.AllowedOrganizations(ctx, namespace)
+}
@@ -0,0 +1,105 @@
+package coder
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	coderv1alpha1 "github.com/coder/coder-k8s/api/v1alpha1"
+)
+
+func TestCachingClientProviderReusesClientUntilTokenChanges(t *testing.T) {
+	t.Parallel()
+
+	provider, secretReader := newControlPlaneProviderForTest(
+		t,
+		[]coderv1alpha1.CoderControlPlane{eligibleControlPlane("team-a", "coder")},
+		[]corev1.Secret{
+			secretWithStringData("team-a", "operator-token", map[string]string{"token": "first-token"}),
+		},
+	)
+	transport := &http.Transport{}
+	cachingProvider, err := NewCachingClientProvider(provider, transport)
+	if err != nil {
+		t.Fatalf("new caching client provider: %v", err)
+	}
+
+	first, err := cachingProvider.ClientForNamespace(context.Background(), "team-a")
+	if err != nil {
+		t.Fatalf("resolve first client: %v", err)
+	}
+	second, err := cachingProvider.ClientForNamespace(context.Background(), "team-a")
+	if err != nil {
+		t.Fatalf("resolve second client: %v", err)
+	}
+	if first != second {
+		t.Fatalf("expected repeated lookups to reuse client %p, got %p", first, second)
+	}
+	if first.HTTPClient.Transport != transport {
+		t.Fatalf("expected cached client to use the shared transport, got %T", first.HTTPClient.Transport)
+	}
+	if got, want := secretReader.getCalls, 2; got != want {
+		t.Fatalf("expected every lookup to re-read the token secret, got %d reads", got)
+	}
+
+	secret := &corev1.Secret{}
+	if err := secretReader.Get(context.Background(), client.ObjectKey{Namespace: "team-a", Name: "operator-token"}, secret); err != nil {
+		t.Fatalf("get token secret: %v", err)
+	}
+	secret.Data["token"] = []byte("rotated-token")
+	writer, ok := secretReader.Reader.(client.Client)
+	if !ok {
+		t.Fatalf("expected secret reader to be a client, got %T", secretReader.Reader)
+	}
+	if err := writer.Update(context.Background(), secret); err != nil {
+		t.Fatalf("rotate token secret: %v", err)
+	}
+
+	rotated, err := cachingProvider.ClientForNamespace(context.Background(), "team-a")
+	if err != nil {
+		t.Fatalf("resolve client after rotation: %v", err)
+	}
+	if rotated == first {
+		t.Fatal("expected token rotation to replace the cached client")
+	}
+	if got, want := rotated.SessionToken(), "rotated-token"; got != want {
+		t.Fatalf("expected session token %q, got %q", want, got)
+	}
+}
+
+func TestCachingClientProviderDropsClientOnResolutionError(t *testing.T) {
+	t.Parallel()
+
+	provider, _ := newControlPlaneProviderForTest(
+		t,
+		[]coderv1alpha1.CoderControlPlane{eligibleControlPlane("team-a", "coder")},
+		[]corev1.Secret{
+			secretWithStringData("team-a", "operator-token", map[string]string{"token": "token"}),
+		},
+	)
+	cachingProvider, err := NewCachingClientProvider(provider, &http.Transport{})
+	if err != nil {
+		t.Fatalf("new caching client provider: %v", err)
+	}
+
+	if _, err := cachingProvider.ClientForNamespace(context.Background(), "team-a"); err != nil {
+		t.Fatalf("resolve client: %v", err)
+	}
+	if _, err := cachingProvider.ClientForNamespace(context.Background(), "team-b"); err == nil {
+		t.Fatal("expected error for namespace without a control plane")
+	}
+
+	namespaces, err := cachingProvider.EligibleNamespaces(context.Background())
+	if err != nil {
+		t.Fatalf("eligible namespaces: %v", err)
+	}
+	if len(namespaces) != 1 || namespaces[0] != "team-a" {
+		t.Fatalf("expected delegated eligible namespaces [team-a], got %v", namespaces)
+	}
+	if len(cachingProvider.clients) != 1 {
+		t.Fatalf("expected only team-a to stay cached, got %d entries", len(cachingProvider.clients))
+	}
+}
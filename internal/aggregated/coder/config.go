@@ -3,19 +3,77 @@ package coder
 
 import (
 	"fmt"
+	"net"
+	"net/http"
 	"net/url"
 	"time"
 
 	"github.com/coder/coder/v2/codersdk"
 )
 
-const defaultRequestTimeout = 30 * time.Second
+const (
+	defaultRequestTimeout = 30 * time.Second
+
+	defaultDialTimeout         = 10 * time.Second
+	defaultDialKeepAlive       = 30 * time.Second
+	defaultTLSHandshakeTimeout = 10 * time.Second
+	defaultIdleConnTimeout     = 90 * time.Second
+	defaultMaxIdleConnsPerHost = 16
+)
 
 // Config describes how to construct a Coder SDK client.
 type Config struct {
 	CoderURL       *url.URL
 	SessionToken   string
 	RequestTimeout time.Duration
+	// Transport, when set, replaces the SDK's default HTTP transport so
+	// several clients can share one keep-alive connection pool.
+	Transport http.RoundTripper
+}
+
+// TransportConfig tunes the HTTP transport shared by cached Coder SDK clients.
+// Zero values select the defaults.
+type TransportConfig struct {
+	DialTimeout         time.Duration
+	TLSHandshakeTimeout time.Duration
+	IdleConnTimeout     time.Duration
+	MaxIdleConnsPerHost int
+}
+
+// NewTransport builds a keep-alive HTTP transport from cfg, based on
+// http.DefaultTransport so proxy environment variables are still honored.
+func NewTransport(cfg TransportConfig) (*http.Transport, error) {
+	if cfg.DialTimeout < 0 || cfg.TLSHandshakeTimeout < 0 || cfg.IdleConnTimeout < 0 {
+		return nil, fmt.Errorf("assertion failed: transport timeouts must not be negative")
+	}
+	if cfg.MaxIdleConnsPerHost < 0 {
+		return nil, fmt.Errorf("assertion failed: max idle connections per host must not be negative")
+	}
+
+	orDefault := func(value, fallback time.Duration) time.Duration {
+		if value == 0 {
+			return fallback
+		}
+		return value
+	}
+
+	defaultTransport, ok := http.DefaultTransport.(*http.Transport)
+	if !ok {
+		return nil, fmt.Errorf("assertion failed: http.DefaultTransport is %T, not *http.Transport", http.DefaultTransport)
+	}
+	transport := defaultTransport.Clone()
+	transport.DialContext = (&net.Dialer{
+		Timeout:   orDefault(cfg.DialTimeout, defaultDialTimeout),
+		KeepAlive: defaultDialKeepAlive,
+	}).DialContext
+	transport.TLSHandshakeTimeout = orDefault(cfg.TLSHandshakeTimeout, defaultTLSHandshakeTimeout)
+	transport.IdleConnTimeout = orDefault(cfg.IdleConnTimeout, defaultIdleConnTimeout)
+	transport.MaxIdleConnsPerHost = defaultMaxIdleConnsPerHost
+	if cfg.MaxIdleConnsPerHost > 0 {
+		transport.MaxIdleConnsPerHost = cfg.MaxIdleConnsPerHost
+	}
+
+	return transport, nil
 }
 
 // NewSDKClient creates a configured Coder SDK client from cfg.
@@ -45,6 +103,9 @@ func NewSDKClient(cfg Config) (*codersdk.Client, error) {
 	}
 
 	client.HTTPClient.Timeout = requestTimeout
+	if cfg.Transport != nil {
+		client.HTTPClient.Transport = cfg.Transport
+	}
 	client.SetSessionToken(cfg.SessionToken)
 	if client.SessionToken() == "" {
 		return nil, fmt.Errorf("assertion failed: coder SDK session token is empty after successful configuration")
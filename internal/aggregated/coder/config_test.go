@@ -95,6 +95,28 @@ func TestNewSDKClient(t *testing.T) {
 	}
 }
 
+func TestNewTransport(t *testing.T) {
+	t.Parallel()
+
+	transport, err := NewTransport(TransportConfig{IdleConnTimeout: time.Minute})
+	if err != nil {
+		t.Fatalf("new transport: %v", err)
+	}
+	if got, want := transport.IdleConnTimeout, time.Minute; got != want {
+		t.Fatalf("expected idle conn timeout %v, got %v", want, got)
+	}
+	if got, want := transport.TLSHandshakeTimeout, defaultTLSHandshakeTimeout; got != want {
+		t.Fatalf("expected default TLS handshake timeout %v, got %v", want, got)
+	}
+	if got, want := transport.MaxIdleConnsPerHost, defaultMaxIdleConnsPerHost; got != want {
+		t.Fatalf("expected default max idle conns per host %d, got %d", want, got)
+	}
+
+	if _, err := NewTransport(TransportConfig{DialTimeout: -time.Second}); err == nil {
+		t.Fatal("expected negative dial timeout to fail")
+	}
+}
+
 func mustParseURL(t *testing.T, rawURL string) *url.URL {
 	t.Helper()
 
@@ -25,9 +25,10 @@ type ControlPlaneClientProvider struct {
 }
 
 var (
-	_ ClientProvider    = (*ControlPlaneClientProvider)(nil)
-	_ NamespaceResolver = (*ControlPlaneClientProvider)(nil)
-	_ NamespaceLister   = (*ControlPlaneClientProvider)(nil)
+	_ ClientProvider     = (*ControlPlaneClientProvider)(nil)
+	_ NamespaceResolver  = (*ControlPlaneClientProvider)(nil)
+	_ NamespaceLister    = (*ControlPlaneClientProvider)(nil)
+	_ OrganizationFilter = (*ControlPlaneClientProvider)(nil)
 )
 
 // NewControlPlaneClientProvider constructs a dynamic ClientProvider backed by CoderControlPlane resources.
@@ -247,6 +248,32 @@ func (p *ControlPlaneClientProvider) EligibleNamespaces(ctx context.Context) ([]
 	return namespaces, nil
 }
 
+// AllowedOrganizations reads the organization allow-list from the
+// AggregatedOrganizationsAnnotation on the namespace's eligible
+// CoderControlPlane. A missing or empty annotation allows every organization.
+func (p *ControlPlaneClientProvider) AllowedOrganizations(ctx context.Context, namespace string) ([]string, error) {
+	if p == nil {
+		return nil, fmt.Errorf("assertion failed: control plane client provider must not be nil")
+	}
+	if ctx == nil {
+		return nil, fmt.Errorf("assertion failed: context must not be nil")
+	}
+
+	eligible, err := p.findEligibleControlPlanes(ctx, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	switch len(eligible) {
+	case 0:
+		return nil, apierrors.NewServiceUnavailable(noEligibleControlPlaneMessage(namespace))
+	case 1:
+		return ParseOrganizationList(eligible[0].Annotations[coderv1alpha1.AggregatedOrganizationsAnnotation]), nil
+	default:
+		return nil, apierrors.NewBadRequest(multipleEligibleControlPlaneMessage(namespace))
+	}
+}
+
 func (p *ControlPlaneClientProvider) findEligibleControlPlanes(
 	ctx context.Context,
 	namespace string,
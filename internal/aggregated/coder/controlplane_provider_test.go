@@ -225,6 +225,36 @@ func TestControlPlaneClientProviderDefaultNamespaceHappyPath(t *testing.T) {
 	}
 }
 
+func TestControlPlaneClientProviderAllowedOrganizationsReadsAnnotation(t *testing.T) {
+	t.Parallel()
+
+	annotated := eligibleControlPlane("team-a", "coder")
+	annotated.Annotations = map[string]string{
+		coderv1alpha1.AggregatedOrganizationsAnnotation: " acme, globex ,,acme",
+	}
+	provider, _ := newControlPlaneProviderForTest(
+		t,
+		[]coderv1alpha1.CoderControlPlane{annotated, eligibleControlPlane("team-b", "coder")},
+		nil,
+	)
+
+	organizations, err := provider.AllowedOrganizations(context.Background(), "team-a")
+	if err != nil {
+		t.Fatalf("resolve allowed organizations: %v", err)
+	}
+	if got, want := strings.Join(organizations, ","), "acme,globex"; got != want {
+		t.Fatalf("expected allowed organizations %q, got %q", want, got)
+	}
+
+	organizations, err = provider.AllowedOrganizations(context.Background(), "team-b")
+	if err != nil {
+		t.Fatalf("resolve allowed organizations without annotation: %v", err)
+	}
+	if organizations != nil {
+		t.Fatalf("expected nil allow-list without annotation, got %v", organizations)
+	}
+}
+
 func TestControlPlaneClientProviderDefaultNamespaceReturnsServiceUnavailableWhenNoEligibleControlPlane(t *testing.T) {
 	t.Parallel()
 
@@ -1,6 +1,7 @@
 package coder
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net/http"
@@ -8,9 +9,48 @@ import (
 
 	"github.com/coder/coder/v2/codersdk"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 )
 
+// coderValidationFieldPaths maps the request fields Coder names in validation
+// errors to the Kubernetes field paths they come from, per resource. Fields
+// without a mapping keep Coder's name.
+var coderValidationFieldPaths = map[string]map[string]string{
+	"codertemplates": {
+		"name":                           "metadata.name",
+		"display_name":                   "spec.displayName",
+		"description":                    "spec.description",
+		"icon":                           "spec.icon",
+		"template_version_id":            "spec.versionID",
+		"default_ttl_ms":                 "spec.defaultTTLMillis",
+		"activity_bump_ms":               "spec.activityBumpMillis",
+		"autostop_requirement":           "spec.autostopRequirement",
+		"dormant_ttl_ms":                 "spec.dormancyThresholdMillis",
+		"time_til_dormant_ms":            "spec.dormancyThresholdMillis",
+		"delete_ttl_ms":                  "spec.autoDeleteThresholdMillis",
+		"time_til_dormant_autodelete_ms": "spec.autoDeleteThresholdMillis",
+		"deprecation_message":            "spec.deprecationMessage",
+	},
+	"coderworkspaces": {
+		"name":                       "metadata.name",
+		"template_id":                "spec.templateName",
+		"template_version_id":        "spec.templateVersionID",
+		"template_version_preset_id": "spec.presetName",
+		"ttl_ms":                     "spec.ttlMillis",
+		"autostart_schedule":         "spec.autostartSchedule",
+		"schedule":                   "spec.autostartSchedule",
+	},
+}
+
+// coderResourceKinds maps aggregated resources to their kinds for status
+// details, which name the kind rather than the resource.
+var coderResourceKinds = map[string]string{
+	"codertemplates":        "CoderTemplate",
+	"codertemplateversions": "CoderTemplateVersion",
+	"coderworkspaces":       "CoderWorkspace",
+}
+
 // MapCoderError converts Coder SDK errors to Kubernetes API errors.
 func MapCoderError(err error, resource schema.GroupResource, name string) error {
 	if err == nil {
@@ -23,6 +63,18 @@ func MapCoderError(err error, resource schema.GroupResource, name string) error
 		return fmt.Errorf("assertion failed: resource name must not be empty")
 	}
 
+	if errors.Is(err, context.DeadlineExceeded) {
+		return apierrors.NewTimeoutError(
+			fmt.Sprintf("timed out waiting for the Coder API to serve %s %q: %v", resource.String(), name, err),
+			0,
+		)
+	}
+
+	var rateLimitErr *RateLimitedError
+	if errors.As(err, &rateLimitErr) {
+		return rateLimitErr.StatusError()
+	}
+
 	var coderErr *codersdk.Error
 	if !errors.As(err, &coderErr) {
 		return apierrors.NewInternalError(err)
@@ -42,11 +94,18 @@ func MapCoderError(err error, resource schema.GroupResource, name string) error
 		}
 		return apierrors.NewConflict(resource, name, err)
 	case http.StatusBadRequest, http.StatusUnprocessableEntity:
+		if len(coderErr.Validations) > 0 {
+			return newValidationStatusError(resource, name, message, coderErr.Validations)
+		}
 		return apierrors.NewBadRequest(message)
 	case http.StatusUnauthorized:
 		return apierrors.NewUnauthorized(message)
 	case http.StatusTooManyRequests:
 		return apierrors.NewTooManyRequests(message, 0)
+	case http.StatusBadGateway, http.StatusServiceUnavailable:
+		return apierrors.NewServiceUnavailable(message)
+	case http.StatusGatewayTimeout:
+		return apierrors.NewTimeoutError(message, 0)
 	default:
 		if statusCode >= http.StatusBadRequest && statusCode < http.StatusInternalServerError {
 			return apierrors.NewBadRequest(message)
@@ -56,6 +115,44 @@ func MapCoderError(err error, resource schema.GroupResource, name string) error
 	}
 }
 
+// newValidationStatusError reports Coder's field-level validation errors as an
+// Invalid status whose causes carry Kubernetes field paths, which kubectl
+// prints one per line.
+func newValidationStatusError(
+	resource schema.GroupResource,
+	name string,
+	message string,
+	validations []codersdk.ValidationError,
+) *apierrors.StatusError {
+	causes := make([]metav1.StatusCause, 0, len(validations))
+	details := make([]string, 0, len(validations))
+	for _, validation := range validations {
+		fieldPath := validation.Field
+		if mapped, ok := coderValidationFieldPaths[resource.Resource][validation.Field]; ok {
+			fieldPath = mapped
+		}
+		causes = append(causes, metav1.StatusCause{
+			Type:    metav1.CauseTypeFieldValueInvalid,
+			Message: validation.Detail,
+			Field:   fieldPath,
+		})
+		details = append(details, fmt.Sprintf("%s: %s", fieldPath, validation.Detail))
+	}
+
+	return &apierrors.StatusError{ErrStatus: metav1.Status{
+		Status:  metav1.StatusFailure,
+		Code:    http.StatusUnprocessableEntity,
+		Reason:  metav1.StatusReasonInvalid,
+		Message: fmt.Sprintf("%s %q is invalid: %s: %s", resource.String(), name, message, strings.Join(details, ", ")),
+		Details: &metav1.StatusDetails{
+			Group:  resource.Group,
+			Kind:   coderResourceKinds[resource.Resource],
+			Name:   name,
+			Causes: causes,
+		},
+	}}
+}
+
 func coderErrorMessage(coderErr *codersdk.Error, fallback error) string {
 	if coderErr == nil {
 		panic("assertion failed: coder error must not be nil")
@@ -1,14 +1,19 @@
 package coder
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"net/http"
+	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	aggregationv1alpha1 "github.com/coder/coder-k8s/api/aggregation/v1alpha1"
 	"github.com/coder/coder/v2/codersdk"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 )
 
@@ -33,6 +38,16 @@ func TestMapCoderError(t *testing.T) {
 				}
 			},
 		},
+		{
+			name: "maps deadline exceeded to timeout",
+			err:  fmt.Errorf("do: %w", context.DeadlineExceeded),
+			assertMapping: func(t *testing.T, err error) {
+				t.Helper()
+				if !apierrors.IsTimeout(err) {
+					t.Fatalf("expected Timeout, got %v", err)
+				}
+			},
+		},
 		{
 			name: "maps forbidden",
 			err:  codersdk.NewTestError(http.StatusForbidden, http.MethodGet, "https://coder.example.com"),
@@ -69,6 +84,40 @@ func TestMapCoderError(t *testing.T) {
 				}
 			},
 		},
+		{
+			name: "maps validation errors to invalid with field causes",
+			err: withCoderValidations(
+				withCoderMessage(
+					codersdk.NewTestError(http.StatusBadRequest, http.MethodPost, "https://coder.example.com"),
+					"Invalid create workspace request.",
+				),
+				codersdk.ValidationError{Field: "ttl_ms", Detail: "ttl must be at least one minute"},
+				codersdk.ValidationError{Field: "rich_parameter_values", Detail: "missing parameter"},
+			),
+			assertMapping: func(t *testing.T, err error) {
+				t.Helper()
+				if !apierrors.IsInvalid(err) {
+					t.Fatalf("expected Invalid, got %v", err)
+				}
+				statusErr, ok := err.(*apierrors.StatusError)
+				if !ok || statusErr.ErrStatus.Details == nil {
+					t.Fatalf("expected StatusError with details, got %#v", err)
+				}
+				if statusErr.ErrStatus.Details.Kind != "CoderWorkspace" {
+					t.Fatalf("expected status details kind %q, got %q", "CoderWorkspace", statusErr.ErrStatus.Details.Kind)
+				}
+				want := []metav1.StatusCause{
+					{Type: metav1.CauseTypeFieldValueInvalid, Field: "spec.ttlMillis", Message: "ttl must be at least one minute"},
+					{Type: metav1.CauseTypeFieldValueInvalid, Field: "rich_parameter_values", Message: "missing parameter"},
+				}
+				if !reflect.DeepEqual(statusErr.ErrStatus.Details.Causes, want) {
+					t.Fatalf("expected causes %#v, got %#v", want, statusErr.ErrStatus.Details.Causes)
+				}
+				if !strings.Contains(err.Error(), "spec.ttlMillis: ttl must be at least one minute") {
+					t.Fatalf("expected message to list field errors, got %v", err)
+				}
+			},
+		},
 		{
 			name: "maps unauthorized",
 			err: withCoderMessage(
@@ -95,6 +144,19 @@ func TestMapCoderError(t *testing.T) {
 				}
 			},
 		},
+		{
+			name: "maps rate limit with retry-after",
+			err:  fmt.Errorf("do: %w", &RateLimitedError{Message: "rate limited", RetryAfter: 1500 * time.Millisecond}),
+			assertMapping: func(t *testing.T, err error) {
+				t.Helper()
+				if !apierrors.IsTooManyRequests(err) {
+					t.Fatalf("expected TooManyRequests, got %v", err)
+				}
+				if delay, ok := apierrors.SuggestsClientDelay(err); !ok || delay != 2 {
+					t.Fatalf("expected retry-after of 2 seconds, got %d (ok=%t)", delay, ok)
+				}
+			},
+		},
 		{
 			name: "maps create conflict to already exists",
 			err: withCoderMessage(
@@ -131,6 +193,26 @@ func TestMapCoderError(t *testing.T) {
 				}
 			},
 		},
+		{
+			name: "maps coder unavailable to service unavailable",
+			err:  codersdk.NewTestError(http.StatusServiceUnavailable, http.MethodGet, "https://coder.example.com"),
+			assertMapping: func(t *testing.T, err error) {
+				t.Helper()
+				if !apierrors.IsServiceUnavailable(err) {
+					t.Fatalf("expected ServiceUnavailable, got %v", err)
+				}
+			},
+		},
+		{
+			name: "maps coder gateway timeout to timeout",
+			err:  codersdk.NewTestError(http.StatusGatewayTimeout, http.MethodGet, "https://coder.example.com"),
+			assertMapping: func(t *testing.T, err error) {
+				t.Helper()
+				if !apierrors.IsTimeout(err) {
+					t.Fatalf("expected Timeout, got %v", err)
+				}
+			},
+		},
 		{
 			name: "maps generic errors to internal",
 			err:  errors.New("boom"),
@@ -213,3 +295,35 @@ func withCoderMessage(err *codersdk.Error, message string) *codersdk.Error {
 
 	return err
 }
+
+func withCoderValidations(err *codersdk.Error, validations ...codersdk.ValidationError) *codersdk.Error {
+	if err == nil {
+		panic("assertion failed: coder error must not be nil")
+	}
+
+	err.Validations = validations
+
+	return err
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, time.January, 2, 3, 4, 5, 0, time.UTC)
+	tests := []struct {
+		value string
+		want  time.Duration
+	}{
+		{value: "", want: 0},
+		{value: "7", want: 7 * time.Second},
+		{value: "-1", want: 0},
+		{value: "soon", want: 0},
+		{value: now.Add(30 * time.Second).Format(http.TimeFormat), want: 30 * time.Second},
+		{value: now.Add(-30 * time.Second).Format(http.TimeFormat), want: 0},
+	}
+	for _, tt := range tests {
+		if got := parseRetryAfter(tt.value, now); got != tt.want {
+			t.Fatalf("parseRetryAfter(%q) = %s, want %s", tt.value, got, tt.want)
+		}
+	}
+}
@@ -27,6 +27,16 @@ func ParseWorkspaceName(name string) (org, user, workspace string, err error) {
 	return segments[0], segments[1], segments[2], nil
 }
 
+// ParseTemplateVersionName splits "<org>.<template-name>.<version-id>" into organization, template, and version ID.
+func ParseTemplateVersionName(name string) (org, template, versionID string, err error) {
+	segments, err := parseNameSegments(name, 3, "template version")
+	if err != nil {
+		return "", "", "", err
+	}
+
+	return segments[0], segments[1], segments[2], nil
+}
+
 // BuildTemplateName constructs "<org>.<template-name>".
 func BuildTemplateName(org, template string) string {
 	assertNameSegment("organization", org)
@@ -44,6 +54,15 @@ func BuildWorkspaceName(org, user, workspace string) string {
 	return org + nameSeparator + user + nameSeparator + workspace
 }
 
+// BuildTemplateVersionName constructs "<org>.<template-name>.<version-id>".
+func BuildTemplateVersionName(org, template, versionID string) string {
+	assertNameSegment("organization", org)
+	assertNameSegment("template", template)
+	assertNameSegment("template version ID", versionID)
+
+	return org + nameSeparator + template + nameSeparator + versionID
+}
+
 func parseNameSegments(name string, expectedSegments int, objectType string) ([]string, error) {
 	if name == "" {
 		return nil, fmt.Errorf("invalid %s name: name must not be empty", objectType)
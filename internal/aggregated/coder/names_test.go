@@ -95,6 +95,26 @@ func TestParseWorkspaceName(t *testing.T) {
 	}
 }
 
+func TestParseTemplateVersionName(t *testing.T) {
+	t.Parallel()
+
+	versionID := "0d4a7f8e-4b7e-4a51-9f2c-3f0d3f1f9a10"
+	org, template, gotVersionID, err := ParseTemplateVersionName("acme.starter." + versionID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if org != "acme" || template != "starter" || gotVersionID != versionID {
+		t.Fatalf("expected (acme, starter, %s), got (%s, %s, %s)", versionID, org, template, gotVersionID)
+	}
+
+	if _, _, _, err := ParseTemplateVersionName("acme.starter"); err == nil {
+		t.Fatal("expected error for template version name without version segment")
+	}
+	if got, want := BuildTemplateVersionName("acme", "starter", versionID), "acme.starter."+versionID; got != want {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+}
+
 func TestBuildTemplateName(t *testing.T) {
 	t.Parallel()
 
@@ -0,0 +1,259 @@
+package coder
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/coder/coder/v2/codersdk"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+
+	coderv1alpha1 "github.com/coder/coder-k8s/api/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// DefaultControlPlaneNamespaceSelector selects the namespaces served by a
+	// NamespaceLabelClientProvider when no selector is configured.
+	DefaultControlPlaneNamespaceSelector = "coder.com/control-plane=true"
+	// DefaultAdminTokenSecretName is the Secret, in each selected namespace,
+	// that holds the Coder URL and admin session token for that namespace.
+	DefaultAdminTokenSecretName = "coder-admin-token"
+	// AdminTokenSecretURLKey is the admin token Secret key holding the Coder URL.
+	AdminTokenSecretURLKey = "url"
+	// AdminTokenSecretTokenKey is the admin token Secret key holding the session token.
+	AdminTokenSecretTokenKey = coderv1alpha1.DefaultTokenSecretKey
+)
+
+// NamespaceLabelClientProvider serves every namespace matching a label
+// selector. Each namespace names its own Coder deployment through an admin
+// token Secret, so one aggregated API server can front many control planes
+// without CoderControlPlane resources.
+type NamespaceLabelClientProvider struct {
+	namespaceReader client.Reader
+	secretReader    client.Reader
+	selector        labels.Selector
+	secretName      string
+	requestTimeout  time.Duration
+}
+
+var (
+	_ ClientProvider     = (*NamespaceLabelClientProvider)(nil)
+	_ NamespaceResolver  = (*NamespaceLabelClientProvider)(nil)
+	_ NamespaceLister    = (*NamespaceLabelClientProvider)(nil)
+	_ OrganizationFilter = (*NamespaceLabelClientProvider)(nil)
+)
+
+// NewNamespaceLabelClientProvider constructs a ClientProvider for namespaces
+// matching namespaceSelector. Empty selector and secret name values fall back to
+// DefaultControlPlaneNamespaceSelector and DefaultAdminTokenSecretName.
+func NewNamespaceLabelClientProvider(
+	namespaceReader client.Reader,
+	secretReader client.Reader,
+	namespaceSelector string,
+	secretName string,
+	requestTimeout time.Duration,
+) (*NamespaceLabelClientProvider, error) {
+	if namespaceReader == nil {
+		return nil, fmt.Errorf("assertion failed: namespace reader must not be nil")
+	}
+	if secretReader == nil {
+		return nil, fmt.Errorf("assertion failed: secret reader must not be nil")
+	}
+	if requestTimeout < 0 {
+		return nil, fmt.Errorf("assertion failed: request timeout must not be negative")
+	}
+
+	namespaceSelector = strings.TrimSpace(namespaceSelector)
+	if namespaceSelector == "" {
+		namespaceSelector = DefaultControlPlaneNamespaceSelector
+	}
+	selector, err := labels.Parse(namespaceSelector)
+	if err != nil {
+		return nil, fmt.Errorf("parse namespace selector %q: %w", namespaceSelector, err)
+	}
+	if selector.Empty() {
+		return nil, fmt.Errorf("namespace selector %q must not select every namespace", namespaceSelector)
+	}
+
+	secretName = strings.TrimSpace(secretName)
+	if secretName == "" {
+		secretName = DefaultAdminTokenSecretName
+	}
+
+	return &NamespaceLabelClientProvider{
+		namespaceReader: namespaceReader,
+		secretReader:    secretReader,
+		selector:        selector,
+		secretName:      secretName,
+		requestTimeout:  requestTimeout,
+	}, nil
+}
+
+// ClientForNamespace resolves an SDK client from the admin token Secret of a
+// selected namespace.
+func (p *NamespaceLabelClientProvider) ClientForNamespace(ctx context.Context, namespace string) (*codersdk.Client, error) {
+	if p == nil {
+		return nil, fmt.Errorf("assertion failed: namespace label client provider must not be nil")
+	}
+	if ctx == nil {
+		return nil, fmt.Errorf("assertion failed: context must not be nil")
+	}
+	if p.secretReader == nil {
+		return nil, fmt.Errorf("assertion failed: secret reader must not be nil")
+	}
+
+	if _, err := p.selectedNamespace(ctx, namespace); err != nil {
+		return nil, err
+	}
+
+	tokenSecret := &corev1.Secret{}
+	if err := p.secretReader.Get(ctx, client.ObjectKey{Namespace: namespace, Name: p.secretName}, tokenSecret); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, apierrors.NewServiceUnavailable(
+				fmt.Sprintf("admin token secret %s/%s not found", namespace, p.secretName),
+			)
+		}
+		return nil, fmt.Errorf("read admin token secret %s/%s: %w", namespace, p.secretName, err)
+	}
+
+	values := make(map[string]string, 2)
+	for _, key := range []string{AdminTokenSecretURLKey, AdminTokenSecretTokenKey} {
+		value := strings.TrimSpace(string(tokenSecret.Data[key]))
+		if value == "" {
+			return nil, apierrors.NewServiceUnavailable(
+				fmt.Sprintf("admin token secret %s/%s is missing a value for key %q", namespace, p.secretName, key),
+			)
+		}
+		values[key] = value
+	}
+
+	coderURL := values[AdminTokenSecretURLKey]
+	parsedCoderURL, err := url.Parse(coderURL)
+	if err != nil || parsedCoderURL.Scheme == "" || parsedCoderURL.Host == "" {
+		return nil, apierrors.NewServiceUnavailable(
+			fmt.Sprintf(
+				"admin token secret %s/%s key %q holds invalid Coder URL %q",
+				namespace,
+				p.secretName,
+				AdminTokenSecretURLKey,
+				coderURL,
+			),
+		)
+	}
+
+	sdkClient, err := NewSDKClient(Config{
+		CoderURL:       parsedCoderURL,
+		SessionToken:   values[AdminTokenSecretTokenKey],
+		RequestTimeout: p.requestTimeout,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("construct Coder SDK client for namespace %q: %w", namespace, err)
+	}
+	if sdkClient == nil {
+		return nil, fmt.Errorf("assertion failed: Coder SDK client is nil after successful construction")
+	}
+
+	return sdkClient, nil
+}
+
+// DefaultNamespace resolves the namespace for all-namespaces LIST requests when
+// exactly one namespace is selected.
+func (p *NamespaceLabelClientProvider) DefaultNamespace(ctx context.Context) (string, error) {
+	namespaces, err := p.EligibleNamespaces(ctx)
+	if err != nil {
+		return "", err
+	}
+	if len(namespaces) != 1 {
+		return "", apierrors.NewBadRequest(
+			fmt.Sprintf("multiple namespaces match selector %q; specify a namespace", p.selector.String()),
+		)
+	}
+
+	return namespaces[0], nil
+}
+
+// EligibleNamespaces returns the sorted names of namespaces matching the
+// selector.
+func (p *NamespaceLabelClientProvider) EligibleNamespaces(ctx context.Context) ([]string, error) {
+	if p == nil {
+		return nil, fmt.Errorf("assertion failed: namespace label client provider must not be nil")
+	}
+	if ctx == nil {
+		return nil, fmt.Errorf("assertion failed: context must not be nil")
+	}
+	if p.namespaceReader == nil {
+		return nil, fmt.Errorf("assertion failed: namespace reader must not be nil")
+	}
+
+	namespaceList := &corev1.NamespaceList{}
+	if err := p.namespaceReader.List(ctx, namespaceList, client.MatchingLabelsSelector{Selector: p.selector}); err != nil {
+		return nil, fmt.Errorf("list namespaces matching selector %q: %w", p.selector.String(), err)
+	}
+	if len(namespaceList.Items) == 0 {
+		return nil, apierrors.NewServiceUnavailable(noSelectedNamespaceMessage(p.selector))
+	}
+
+	namespaces := make([]string, 0, len(namespaceList.Items))
+	for i := range namespaceList.Items {
+		namespaces = append(namespaces, namespaceList.Items[i].Name)
+	}
+	sort.Strings(namespaces)
+
+	return namespaces, nil
+}
+
+// AllowedOrganizations reads the organization allow-list from the
+// AggregatedOrganizationsAnnotation on the selected namespace. A missing or
+// empty annotation allows every organization.
+func (p *NamespaceLabelClientProvider) AllowedOrganizations(ctx context.Context, namespace string) ([]string, error) {
+	selected, err := p.selectedNamespace(ctx, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseOrganizationList(selected.Annotations[coderv1alpha1.AggregatedOrganizationsAnnotation]), nil
+}
+
+// selectedNamespace reads namespace and rejects it unless it matches the
+// selector.
+func (p *NamespaceLabelClientProvider) selectedNamespace(ctx context.Context, namespace string) (*corev1.Namespace, error) {
+	if p == nil {
+		return nil, fmt.Errorf("assertion failed: namespace label client provider must not be nil")
+	}
+	if ctx == nil {
+		return nil, fmt.Errorf("assertion failed: context must not be nil")
+	}
+	if p.namespaceReader == nil {
+		return nil, fmt.Errorf("assertion failed: namespace reader must not be nil")
+	}
+	if namespace == "" {
+		return nil, apierrors.NewBadRequest("namespace is required")
+	}
+
+	selected := &corev1.Namespace{}
+	if err := p.namespaceReader.Get(ctx, client.ObjectKey{Name: namespace}, selected); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, apierrors.NewBadRequest(unselectedNamespaceMessage(namespace, p.selector))
+		}
+		return nil, fmt.Errorf("get namespace %q: %w", namespace, err)
+	}
+	if !p.selector.Matches(labels.Set(selected.Labels)) {
+		return nil, apierrors.NewBadRequest(unselectedNamespaceMessage(namespace, p.selector))
+	}
+
+	return selected, nil
+}
+
+func noSelectedNamespaceMessage(selector labels.Selector) string {
+	return fmt.Sprintf("no namespaces match selector %q", selector.String())
+}
+
+func unselectedNamespaceMessage(namespace string, selector labels.Selector) string {
+	return fmt.Sprintf("namespace %q does not match selector %q and is not served", namespace, selector.String())
+}
@@ -0,0 +1,281 @@
+package coder
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	coderv1alpha1 "github.com/coder/coder-k8s/api/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestNamespaceLabelClientProviderEligibleNamespacesFiltersByLabel(t *testing.T) {
+	t.Parallel()
+
+	provider, secretReader := newNamespaceLabelProviderForTest(
+		t,
+		"",
+		[]corev1.Namespace{
+			labeledNamespace("team-b", map[string]string{"coder.com/control-plane": "true"}),
+			labeledNamespace("team-a", map[string]string{"coder.com/control-plane": "true"}),
+			labeledNamespace("team-c", map[string]string{"coder.com/control-plane": "false"}),
+			labeledNamespace("default", nil),
+		},
+		nil,
+	)
+
+	namespaces, err := provider.EligibleNamespaces(context.Background())
+	if err != nil {
+		t.Fatalf("eligible namespaces: %v", err)
+	}
+	if want := []string{"team-a", "team-b"}; !reflect.DeepEqual(namespaces, want) {
+		t.Fatalf("expected namespaces %v, got %v", want, namespaces)
+	}
+	if got, want := secretReader.getCalls, 0; got != want {
+		t.Fatalf("expected %d secret reads, got %d", want, got)
+	}
+
+	_, err = provider.DefaultNamespace(context.Background())
+	if !apierrors.IsBadRequest(err) {
+		t.Fatalf("expected BadRequest for multiple selected namespaces, got %v", err)
+	}
+}
+
+func TestNamespaceLabelClientProviderCustomSelector(t *testing.T) {
+	t.Parallel()
+
+	provider, _ := newNamespaceLabelProviderForTest(
+		t,
+		"example.com/coder in (prod)",
+		[]corev1.Namespace{
+			labeledNamespace("prod", map[string]string{"example.com/coder": "prod"}),
+			labeledNamespace("team-a", map[string]string{"coder.com/control-plane": "true"}),
+		},
+		nil,
+	)
+
+	namespace, err := provider.DefaultNamespace(context.Background())
+	if err != nil {
+		t.Fatalf("default namespace: %v", err)
+	}
+	if got, want := namespace, "prod"; got != want {
+		t.Fatalf("expected default namespace %q, got %q", want, got)
+	}
+}
+
+func TestNamespaceLabelClientProviderEligibleNamespacesNoneSelected(t *testing.T) {
+	t.Parallel()
+
+	provider, _ := newNamespaceLabelProviderForTest(
+		t,
+		"",
+		[]corev1.Namespace{labeledNamespace("default", nil)},
+		nil,
+	)
+
+	_, err := provider.EligibleNamespaces(context.Background())
+	if !apierrors.IsServiceUnavailable(err) {
+		t.Fatalf("expected ServiceUnavailable, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "coder.com/control-plane=true") {
+		t.Fatalf("expected error to name the selector, got %v", err)
+	}
+}
+
+func TestNamespaceLabelClientProviderClientForNamespaceResolvesPerNamespace(t *testing.T) {
+	t.Parallel()
+
+	provider, _ := newNamespaceLabelProviderForTest(
+		t,
+		"",
+		[]corev1.Namespace{
+			labeledNamespace("team-a", map[string]string{"coder.com/control-plane": "true"}),
+			labeledNamespace("team-b", map[string]string{"coder.com/control-plane": "true"}),
+		},
+		[]corev1.Secret{
+			secretWithStringData("team-a", DefaultAdminTokenSecretName, map[string]string{
+				AdminTokenSecretURLKey:   "https://coder.team-a.example.com",
+				AdminTokenSecretTokenKey: "token-a",
+			}),
+			secretWithStringData("team-b", DefaultAdminTokenSecretName, map[string]string{
+				AdminTokenSecretURLKey:   "https://coder.team-b.example.com",
+				AdminTokenSecretTokenKey: "token-b",
+			}),
+		},
+	)
+
+	for _, testCase := range []struct {
+		namespace string
+		url       string
+		token     string
+	}{
+		{namespace: "team-a", url: "https://coder.team-a.example.com", token: "token-a"},
+		{namespace: "team-b", url: "https://coder.team-b.example.com", token: "token-b"},
+	} {
+		resolvedClient, err := provider.ClientForNamespace(context.Background(), testCase.namespace)
+		if err != nil {
+			t.Fatalf("resolve client for %q: %v", testCase.namespace, err)
+		}
+		if got := resolvedClient.URL.String(); got != testCase.url {
+			t.Fatalf("expected URL %q for %q, got %q", testCase.url, testCase.namespace, got)
+		}
+		if got := resolvedClient.SessionToken(); got != testCase.token {
+			t.Fatalf("expected session token %q for %q, got %q", testCase.token, testCase.namespace, got)
+		}
+	}
+}
+
+func TestNamespaceLabelClientProviderClientForNamespaceRejectsUnselectedNamespace(t *testing.T) {
+	t.Parallel()
+
+	provider, secretReader := newNamespaceLabelProviderForTest(
+		t,
+		"",
+		[]corev1.Namespace{labeledNamespace("default", nil)},
+		[]corev1.Secret{
+			secretWithStringData("default", DefaultAdminTokenSecretName, map[string]string{
+				AdminTokenSecretURLKey:   "https://coder.example.com",
+				AdminTokenSecretTokenKey: "token",
+			}),
+		},
+	)
+
+	for _, namespace := range []string{"default", "missing"} {
+		_, err := provider.ClientForNamespace(context.Background(), namespace)
+		if !apierrors.IsBadRequest(err) {
+			t.Fatalf("expected BadRequest for namespace %q, got %v", namespace, err)
+		}
+	}
+	if got, want := secretReader.getCalls, 0; got != want {
+		t.Fatalf("expected %d secret reads, got %d", want, got)
+	}
+}
+
+func TestNamespaceLabelClientProviderClientForNamespaceInvalidSecret(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		secrets []corev1.Secret
+		message string
+	}{
+		{
+			name:    "missing secret",
+			message: "not found",
+		},
+		{
+			name: "missing token",
+			secrets: []corev1.Secret{
+				secretWithStringData("team-a", DefaultAdminTokenSecretName, map[string]string{
+					AdminTokenSecretURLKey: "https://coder.example.com",
+				}),
+			},
+			message: `key "token"`,
+		},
+		{
+			name: "invalid URL",
+			secrets: []corev1.Secret{
+				secretWithStringData("team-a", DefaultAdminTokenSecretName, map[string]string{
+					AdminTokenSecretURLKey:   "coder.example.com",
+					AdminTokenSecretTokenKey: "token",
+				}),
+			},
+			message: "invalid Coder URL",
+		},
+	}
+
+	for _, testCase := range tests {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			provider, _ := newNamespaceLabelProviderForTest(
+				t,
+				"",
+				[]corev1.Namespace{labeledNamespace("team-a", map[string]string{"coder.com/control-plane": "true"})},
+				testCase.secrets,
+			)
+
+			_, err := provider.ClientForNamespace(context.Background(), "team-a")
+			if !apierrors.IsServiceUnavailable(err) {
+				t.Fatalf("expected ServiceUnavailable, got %v", err)
+			}
+			if !strings.Contains(err.Error(), testCase.message) {
+				t.Fatalf("expected error containing %q, got %v", testCase.message, err)
+			}
+		})
+	}
+}
+
+func TestNamespaceLabelClientProviderAllowedOrganizationsReadsNamespaceAnnotation(t *testing.T) {
+	t.Parallel()
+
+	namespace := labeledNamespace("team-a", map[string]string{"coder.com/control-plane": "true"})
+	namespace.Annotations = map[string]string{
+		coderv1alpha1.AggregatedOrganizationsAnnotation: "acme, globex",
+	}
+	provider, _ := newNamespaceLabelProviderForTest(t, "", []corev1.Namespace{namespace}, nil)
+
+	organizations, err := provider.AllowedOrganizations(context.Background(), "team-a")
+	if err != nil {
+		t.Fatalf("allowed organizations: %v", err)
+	}
+	if want := []string{"acme", "globex"}; !reflect.DeepEqual(organizations, want) {
+		t.Fatalf("expected organizations %v, got %v", want, organizations)
+	}
+}
+
+func TestNewNamespaceLabelClientProviderRejectsInvalidSelector(t *testing.T) {
+	t.Parallel()
+
+	reader := fake.NewClientBuilder().WithScheme(newControlPlaneProviderTestScheme(t)).Build()
+	for _, selector := range []string{"not a selector!", "!"} {
+		if _, err := NewNamespaceLabelClientProvider(reader, reader, selector, "", time.Second); err == nil {
+			t.Fatalf("expected selector %q to be rejected", selector)
+		}
+	}
+}
+
+func newNamespaceLabelProviderForTest(
+	t *testing.T,
+	selector string,
+	namespaces []corev1.Namespace,
+	secrets []corev1.Secret,
+) (*NamespaceLabelClientProvider, *countingReader) {
+	t.Helper()
+
+	scheme := newControlPlaneProviderTestScheme(t)
+
+	namespaceReader := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithLists(&corev1.NamespaceList{Items: namespaces}).
+		Build()
+
+	baseSecretReader := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithLists(&corev1.SecretList{Items: secrets}).
+		Build()
+	secretReader := &countingReader{Reader: baseSecretReader}
+
+	provider, err := NewNamespaceLabelClientProvider(namespaceReader, secretReader, selector, "", 10*time.Second)
+	if err != nil {
+		t.Fatalf("new namespace label client provider: %v", err)
+	}
+
+	return provider, secretReader
+}
+
+func labeledNamespace(name string, labels map[string]string) corev1.Namespace {
+	return corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: labels,
+		},
+	}
+}
@@ -3,6 +3,7 @@ package coder
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 
@@ -28,16 +29,27 @@ type NamespaceLister interface {
 	EligibleNamespaces(ctx context.Context) ([]string, error)
 }
 
+// OrganizationFilter can be implemented by ClientProvider implementations that
+// restrict which Coder organizations are listed for a namespace.
+type OrganizationFilter interface {
+	// AllowedOrganizations returns the Coder organization names LIST may return
+	// for namespace. A nil slice means every organization is allowed.
+	AllowedOrganizations(ctx context.Context, namespace string) ([]string, error)
+}
+
 // StaticClientProvider returns one static client, optionally restricted to one namespace.
 type StaticClientProvider struct {
 	Client    *codersdk.Client
 	Namespace string // If non-empty, only this namespace is allowed.
+	// Organizations limits LIST to these Coder organizations. Empty allows all.
+	Organizations []string
 }
 
 var (
-	_ ClientProvider    = (*StaticClientProvider)(nil)
-	_ NamespaceResolver = (*StaticClientProvider)(nil)
-	_ NamespaceLister   = (*StaticClientProvider)(nil)
+	_ ClientProvider     = (*StaticClientProvider)(nil)
+	_ NamespaceResolver  = (*StaticClientProvider)(nil)
+	_ NamespaceLister    = (*StaticClientProvider)(nil)
+	_ OrganizationFilter = (*StaticClientProvider)(nil)
 )
 
 // ClientForNamespace returns the static client.
@@ -96,6 +108,38 @@ func (p *StaticClientProvider) EligibleNamespaces(_ context.Context) ([]string,
 	return []string{p.Namespace}, nil
 }
 
+// AllowedOrganizations returns the configured organization allow-list.
+func (p *StaticClientProvider) AllowedOrganizations(_ context.Context, _ string) ([]string, error) {
+	if p == nil {
+		return nil, fmt.Errorf("assertion failed: static client provider must not be nil")
+	}
+	if len(p.Organizations) == 0 {
+		return nil, nil
+	}
+
+	return append([]string(nil), p.Organizations...), nil
+}
+
+// ParseOrganizationList splits a comma-separated list of Coder organization
+// names, dropping blanks and duplicates. It returns nil when no names remain.
+func ParseOrganizationList(value string) []string {
+	var organizations []string
+	seen := make(map[string]struct{})
+	for _, name := range strings.Split(value, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if _, ok := seen[name]; ok {
+			continue
+		}
+		seen[name] = struct{}{}
+		organizations = append(organizations, name)
+	}
+
+	return organizations
+}
+
 // NewStaticClientProvider creates a StaticClientProvider from cfg and optional namespace restriction.
 func NewStaticClientProvider(cfg Config, namespace string) (*StaticClientProvider, error) {
 	client, err := NewSDKClient(cfg)
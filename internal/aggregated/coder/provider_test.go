@@ -2,6 +2,7 @@ package coder
 
 import (
 	"context"
+	"reflect"
 	"strings"
 	"testing"
 
@@ -261,6 +262,39 @@ func TestStaticClientProviderEligibleNamespacesNilReceiver(t *testing.T) {
 	}
 }
 
+func TestStaticClientProviderAllowedOrganizations(t *testing.T) {
+	t.Parallel()
+
+	provider := &StaticClientProvider{Namespace: "control-plane"}
+	organizations, err := provider.AllowedOrganizations(context.Background(), "control-plane")
+	if err != nil {
+		t.Fatalf("resolve allowed organizations: %v", err)
+	}
+	if organizations != nil {
+		t.Fatalf("expected nil allow-list by default, got %v", organizations)
+	}
+
+	provider.Organizations = []string{"acme", "globex"}
+	organizations, err = provider.AllowedOrganizations(context.Background(), "control-plane")
+	if err != nil {
+		t.Fatalf("resolve allowed organizations: %v", err)
+	}
+	if !reflect.DeepEqual(organizations, []string{"acme", "globex"}) {
+		t.Fatalf("expected configured allow-list, got %v", organizations)
+	}
+}
+
+func TestParseOrganizationList(t *testing.T) {
+	t.Parallel()
+
+	if got := ParseOrganizationList(" , "); got != nil {
+		t.Fatalf("expected nil for blank list, got %v", got)
+	}
+	if got, want := ParseOrganizationList("acme, globex,acme"), []string{"acme", "globex"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+}
+
 func TestNewStaticClientProvider(t *testing.T) {
 	t.Parallel()
 
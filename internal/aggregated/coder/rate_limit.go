@@ -0,0 +1,93 @@
+package coder
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/coder/coder/v2/codersdk"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// maxRateLimitBodyBytes bounds how much of a 429 response body is read for
+// the error message.
+const maxRateLimitBodyBytes = 64 << 10
+
+// RateLimitedError reports that Coder answered 429 Too Many Requests. It keeps
+// the Retry-After delay, which codersdk drops when it decodes error responses.
+type RateLimitedError struct {
+	Message    string
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitedError) Error() string {
+	if e.RetryAfter > 0 {
+		return fmt.Sprintf("coder rate limit exceeded, retry after %s: %s", e.RetryAfter, e.Message)
+	}
+
+	return fmt.Sprintf("coder rate limit exceeded: %s", e.Message)
+}
+
+// StatusError converts the rate limit into a TooManyRequests status error whose
+// Retry-After is rounded up to whole seconds.
+func (e *RateLimitedError) StatusError() *apierrors.StatusError {
+	retryAfterSeconds := 0
+	if e.RetryAfter > 0 {
+		retryAfterSeconds = int(math.Ceil(e.RetryAfter.Seconds()))
+	}
+
+	return apierrors.NewTooManyRequests(e.Message, retryAfterSeconds)
+}
+
+// rateLimitTransport turns 429 responses into *RateLimitedError so the
+// Retry-After header survives codersdk's error decoding.
+type rateLimitTransport struct {
+	base http.RoundTripper
+	now  func() time.Time
+}
+
+func (t *rateLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+	if err != nil || resp.StatusCode != http.StatusTooManyRequests {
+		return resp, err
+	}
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxRateLimitBodyBytes))
+	_ = resp.Body.Close()
+
+	message := http.StatusText(http.StatusTooManyRequests)
+	var coderResponse codersdk.Response
+	if json.Unmarshal(body, &coderResponse) == nil && strings.TrimSpace(coderResponse.Message) != "" {
+		message = strings.TrimSpace(coderResponse.Message)
+	}
+
+	return nil, &RateLimitedError{
+		Message:    message,
+		RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After"), t.now()),
+	}
+}
+
+// parseRetryAfter reads a Retry-After header in either delay-seconds or
+// HTTP-date form. Missing or invalid values yield zero.
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	if retryAt, err := http.ParseTime(value); err == nil && retryAt.After(now) {
+		return retryAt.Sub(now)
+	}
+
+	return 0
+}
@@ -0,0 +1,155 @@
+package coder
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"time"
+
+	"github.com/coder/coder/v2/codersdk"
+)
+
+const (
+	// readRetryAttempts bounds how often an idempotent read is sent when Coder
+	// answers with a transient error.
+	readRetryAttempts = 3
+	// readRetryBaseDelay is the backoff before the first retry; it doubles for
+	// each further retry and is jittered down by up to half.
+	readRetryBaseDelay = 100 * time.Millisecond
+)
+
+// WithRequestPolicy returns a copy of client whose HTTP calls each run under
+// context.WithTimeout derived from the caller's context, using the client's
+// request timeout (or the default when unset). Unlike http.Client.Timeout, the
+// resulting context.DeadlineExceeded errors are mapped to 504 Gateway Timeout by
+// MapCoderError. GET and HEAD requests that fail with a connection error or a
+// 502, 503, or 504 response are retried with jittered backoff, so a restarting
+// coderd does not fail aggregated reads outright; other methods are never
+// retried. A 429 response surfaces as *RateLimitedError so MapCoderError can
+// pass Coder's Retry-After on to Kubernetes clients. The copy shares the
+// client's transport and connection pool.
+func WithRequestPolicy(client *codersdk.Client) (*codersdk.Client, error) {
+	if client == nil {
+		return nil, fmt.Errorf("assertion failed: coder SDK client must not be nil")
+	}
+	if client.URL == nil || client.HTTPClient == nil {
+		return nil, fmt.Errorf("assertion failed: coder SDK client must have a URL and HTTP client")
+	}
+
+	timeout := client.HTTPClient.Timeout
+	if timeout <= 0 {
+		timeout = defaultRequestTimeout
+	}
+	base := client.HTTPClient.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	coderURL := *client.URL
+	bounded := codersdk.New(&coderURL)
+	if bounded == nil {
+		return nil, fmt.Errorf("assertion failed: coder SDK client is nil after successful construction")
+	}
+	bounded.HTTPClient = &http.Client{
+		Transport: &rateLimitTransport{
+			base: &readRetryTransport{
+				base:      &callDeadlineTransport{base: base, timeout: timeout},
+				attempts:  readRetryAttempts,
+				baseDelay: readRetryBaseDelay,
+			},
+			now: time.Now,
+		},
+		CheckRedirect: client.HTTPClient.CheckRedirect,
+		Jar:           client.HTTPClient.Jar,
+	}
+	bounded.SetSessionToken(client.SessionToken())
+
+	return bounded, nil
+}
+
+// callDeadlineTransport bounds each round trip, including reading the
+// response body, by a per-call context deadline.
+type callDeadlineTransport struct {
+	base    http.RoundTripper
+	timeout time.Duration
+}
+
+func (t *callDeadlineTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx, cancel := context.WithTimeout(req.Context(), t.timeout)
+	resp, err := t.base.RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	resp.Body = &cancelOnCloseBody{ReadCloser: resp.Body, cancel: cancel}
+
+	return resp, nil
+}
+
+type cancelOnCloseBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelOnCloseBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
+// readRetryTransport retries idempotent reads on transient failures.
+type readRetryTransport struct {
+	base      http.RoundTripper
+	attempts  int
+	baseDelay time.Duration
+}
+
+func (t *readRetryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		return t.base.RoundTrip(req)
+	}
+
+	delay := t.baseDelay
+	for attempt := 1; ; attempt++ {
+		resp, err := t.base.RoundTrip(req)
+		if attempt >= t.attempts || !retryableRead(req.Context(), resp, err) {
+			return resp, err
+		}
+		if resp != nil {
+			_, _ = io.Copy(io.Discard, resp.Body)
+			_ = resp.Body.Close()
+		}
+
+		jittered := delay/2 + rand.N(delay/2+1)
+		timer := time.NewTimer(jittered)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+		delay *= 2
+	}
+}
+
+// retryableRead reports whether a read failed transiently: the caller is still
+// waiting, and Coder either could not be reached or answered 502, 503, or 504.
+// Per-call deadline expiries are not retried, so a hung backend fails fast.
+func retryableRead(ctx context.Context, resp *http.Response, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	if err != nil {
+		return !errors.Is(err, context.DeadlineExceeded) && !errors.Is(err, context.Canceled)
+	}
+
+	switch resp.StatusCode {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,232 @@
+package coder
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/coder/coder/v2/codersdk"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// DefaultSessionTokenReloadInterval is how often a TokenFileClientProvider
+// re-reads its token file when no reload interval is configured.
+const DefaultSessionTokenReloadInterval = time.Minute
+
+// TokenFileClientProvider serves one namespace like StaticClientProvider, but
+// reads the admin session token from a file, typically a mounted Secret. The
+// file is re-read every reload interval and on the next request after Coder
+// answers 401 Unauthorized, so a rotated token takes effect without a restart.
+// The cached client is replaced whenever the token changes.
+type TokenFileClientProvider struct {
+	// Organizations limits LIST to these Coder organizations. Empty allows all.
+	Organizations []string
+
+	coderURL       *url.URL
+	tokenPath      string
+	namespace      string
+	requestTimeout time.Duration
+	reloadInterval time.Duration
+	transport      http.RoundTripper
+	now            func() time.Time
+
+	mu       sync.Mutex
+	client   *codersdk.Client
+	token    string
+	loadedAt time.Time
+	stale    bool
+}
+
+var (
+	_ ClientProvider     = (*TokenFileClientProvider)(nil)
+	_ NamespaceResolver  = (*TokenFileClientProvider)(nil)
+	_ NamespaceLister    = (*TokenFileClientProvider)(nil)
+	_ OrganizationFilter = (*TokenFileClientProvider)(nil)
+)
+
+// NewTokenFileClientProvider creates a TokenFileClientProvider for namespace
+// that reads the session token from tokenPath instead of cfg.SessionToken. A
+// zero reloadInterval selects DefaultSessionTokenReloadInterval. The token file
+// is read once up front so a missing or empty file fails startup.
+func NewTokenFileClientProvider(
+	cfg Config,
+	tokenPath string,
+	namespace string,
+	reloadInterval time.Duration,
+) (*TokenFileClientProvider, error) {
+	if cfg.CoderURL == nil {
+		return nil, fmt.Errorf("assertion failed: coder URL must not be nil")
+	}
+	if cfg.SessionToken != "" {
+		return nil, fmt.Errorf("assertion failed: session token must be empty when reading it from a file")
+	}
+	if cfg.RequestTimeout < 0 {
+		return nil, fmt.Errorf("assertion failed: request timeout must not be negative")
+	}
+	tokenPath = strings.TrimSpace(tokenPath)
+	if tokenPath == "" {
+		return nil, fmt.Errorf("assertion failed: session token file path must not be empty")
+	}
+	switch {
+	case reloadInterval < 0:
+		return nil, fmt.Errorf("assertion failed: session token reload interval must not be negative")
+	case reloadInterval == 0:
+		reloadInterval = DefaultSessionTokenReloadInterval
+	}
+
+	baseTransport := cfg.Transport
+	if baseTransport == nil {
+		baseTransport = http.DefaultTransport
+	}
+
+	provider := &TokenFileClientProvider{
+		coderURL:       cfg.CoderURL,
+		tokenPath:      tokenPath,
+		namespace:      namespace,
+		requestTimeout: cfg.RequestTimeout,
+		reloadInterval: reloadInterval,
+		now:            time.Now,
+	}
+	provider.transport = &reloadOnUnauthorizedTransport{
+		base:           baseTransport,
+		onUnauthorized: provider.markStale,
+	}
+	if err := provider.reloadLocked(); err != nil {
+		return nil, err
+	}
+
+	return provider, nil
+}
+
+// ClientForNamespace returns the client for the current token, re-reading the
+// token file first when it is due for a reload.
+func (p *TokenFileClientProvider) ClientForNamespace(ctx context.Context, namespace string) (*codersdk.Client, error) {
+	if p == nil {
+		return nil, fmt.Errorf("assertion failed: token file client provider must not be nil")
+	}
+	if ctx == nil {
+		return nil, fmt.Errorf("assertion failed: context must not be nil")
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.stale || !p.now().Before(p.loadedAt.Add(p.reloadInterval)) {
+		if err := p.reloadLocked(); err != nil {
+			return nil, err
+		}
+	}
+
+	return p.staticLocked().ClientForNamespace(ctx, namespace)
+}
+
+// DefaultNamespace resolves the pinned namespace for all-namespaces LIST requests.
+func (p *TokenFileClientProvider) DefaultNamespace(ctx context.Context) (string, error) {
+	if p == nil {
+		return "", fmt.Errorf("assertion failed: token file client provider must not be nil")
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.staticLocked().DefaultNamespace(ctx)
+}
+
+// EligibleNamespaces returns namespaces served by the provider.
+func (p *TokenFileClientProvider) EligibleNamespaces(ctx context.Context) ([]string, error) {
+	if p == nil {
+		return nil, fmt.Errorf("assertion failed: token file client provider must not be nil")
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.staticLocked().EligibleNamespaces(ctx)
+}
+
+// AllowedOrganizations returns the configured organization allow-list.
+func (p *TokenFileClientProvider) AllowedOrganizations(ctx context.Context, namespace string) ([]string, error) {
+	if p == nil {
+		return nil, fmt.Errorf("assertion failed: token file client provider must not be nil")
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.staticLocked().AllowedOrganizations(ctx, namespace)
+}
+
+// staticLocked returns a StaticClientProvider view of the current client. The
+// caller must hold p.mu.
+func (p *TokenFileClientProvider) staticLocked() *StaticClientProvider {
+	return &StaticClientProvider{
+		Client:        p.client,
+		Namespace:     p.namespace,
+		Organizations: p.Organizations,
+	}
+}
+
+// reloadLocked re-reads the token file and replaces the client when the token
+// changed. A failed read keeps the provider stale so the next request retries.
+// The caller must hold p.mu.
+func (p *TokenFileClientProvider) reloadLocked() error {
+	contents, err := os.ReadFile(p.tokenPath)
+	if err != nil {
+		return apierrors.NewServiceUnavailable(
+			fmt.Sprintf("read Coder session token file %q: %v", p.tokenPath, err),
+		)
+	}
+	token := strings.TrimSpace(string(contents))
+	if token == "" {
+		return apierrors.NewServiceUnavailable(
+			fmt.Sprintf("Coder session token file %q is empty", p.tokenPath),
+		)
+	}
+
+	if p.client == nil || token != p.token {
+		sdkClient, err := NewSDKClient(Config{
+			CoderURL:       p.coderURL,
+			SessionToken:   token,
+			RequestTimeout: p.requestTimeout,
+			Transport:      p.transport,
+		})
+		if err != nil {
+			return fmt.Errorf("construct Coder SDK client from session token file %q: %w", p.tokenPath, err)
+		}
+		p.client = sdkClient
+		p.token = token
+	}
+	p.loadedAt = p.now()
+	p.stale = false
+
+	return nil
+}
+
+// markStale forces the next ClientForNamespace call to re-read the token file.
+func (p *TokenFileClientProvider) markStale() {
+	p.mu.Lock()
+	p.stale = true
+	p.mu.Unlock()
+}
+
+// reloadOnUnauthorizedTransport reports 401 Unauthorized responses so a
+// rejected session token is re-read before the next request. The response
+// itself is returned unchanged.
+type reloadOnUnauthorizedTransport struct {
+	base           http.RoundTripper
+	onUnauthorized func()
+}
+
+func (t *reloadOnUnauthorizedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+	if err == nil && resp.StatusCode == http.StatusUnauthorized {
+		t.onUnauthorized()
+	}
+
+	return resp, err
+}
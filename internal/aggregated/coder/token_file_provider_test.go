@@ -0,0 +1,156 @@
+package coder
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/coder/coder/v2/codersdk"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+func TestTokenFileClientProviderReloadsTokenAfterUnauthorized(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Header.Get(codersdk.SessionTokenHeader) != "rotated-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_ = json.NewEncoder(w).Encode(codersdk.Response{Message: "Invalid session token."})
+			return
+		}
+		_ = json.NewEncoder(w).Encode([]codersdk.Organization{})
+	}))
+	t.Cleanup(server.Close)
+
+	tokenPath := writeTokenFile(t, "revoked-token")
+	provider, err := NewTokenFileClientProvider(
+		Config{CoderURL: mustParseURL(t, server.URL)},
+		tokenPath,
+		"control-plane",
+		time.Hour,
+	)
+	if err != nil {
+		t.Fatalf("new token file client provider: %v", err)
+	}
+
+	first, err := provider.ClientForNamespace(context.Background(), "control-plane")
+	if err != nil {
+		t.Fatalf("resolve first client: %v", err)
+	}
+	// Rotating the file alone does not reload before the interval elapses.
+	if err := os.WriteFile(tokenPath, []byte("rotated-token\n"), 0o600); err != nil {
+		t.Fatalf("rotate token file: %v", err)
+	}
+	unchanged, err := provider.ClientForNamespace(context.Background(), "control-plane")
+	if err != nil {
+		t.Fatalf("resolve client before reload: %v", err)
+	}
+	if unchanged != first {
+		t.Fatalf("expected client %p to be reused before reload, got %p", first, unchanged)
+	}
+
+	_, err = first.Organizations(context.Background())
+	if err == nil {
+		t.Fatal("expected the revoked token to be rejected")
+	}
+	var sdkErr *codersdk.Error
+	if !errors.As(err, &sdkErr) || sdkErr.StatusCode() != http.StatusUnauthorized {
+		t.Fatalf("expected a %d Coder error, got %v", http.StatusUnauthorized, err)
+	}
+
+	second, err := provider.ClientForNamespace(context.Background(), "control-plane")
+	if err != nil {
+		t.Fatalf("resolve client after unauthorized response: %v", err)
+	}
+	if second == first {
+		t.Fatal("expected the cached client to be replaced after reload")
+	}
+	if got, want := second.SessionToken(), "rotated-token"; got != want {
+		t.Fatalf("expected session token %q, got %q", want, got)
+	}
+	if _, err := second.Organizations(context.Background()); err != nil {
+		t.Fatalf("expected the rotated token to be accepted: %v", err)
+	}
+}
+
+func TestTokenFileClientProviderReloadsTokenOnInterval(t *testing.T) {
+	t.Parallel()
+
+	tokenPath := writeTokenFile(t, "first-token")
+	provider, err := NewTokenFileClientProvider(
+		Config{CoderURL: mustParseURL(t, "https://coder.example.com")},
+		tokenPath,
+		"control-plane",
+		time.Minute,
+	)
+	if err != nil {
+		t.Fatalf("new token file client provider: %v", err)
+	}
+	now := time.Now()
+	provider.now = func() time.Time { return now }
+
+	if err := os.WriteFile(tokenPath, []byte("second-token"), 0o600); err != nil {
+		t.Fatalf("rotate token file: %v", err)
+	}
+	now = now.Add(time.Minute)
+
+	sdkClient, err := provider.ClientForNamespace(context.Background(), "control-plane")
+	if err != nil {
+		t.Fatalf("resolve client: %v", err)
+	}
+	if got, want := sdkClient.SessionToken(), "second-token"; got != want {
+		t.Fatalf("expected session token %q, got %q", want, got)
+	}
+
+	_, err = provider.ClientForNamespace(context.Background(), "default")
+	if !apierrors.IsBadRequest(err) {
+		t.Fatalf("expected BadRequest for namespace outside provider scope, got %v", err)
+	}
+}
+
+func TestTokenFileClientProviderRejectsUnreadableTokenFile(t *testing.T) {
+	t.Parallel()
+
+	coderURL := mustParseURL(t, "https://coder.example.com")
+	if _, err := NewTokenFileClientProvider(
+		Config{CoderURL: coderURL},
+		filepath.Join(t.TempDir(), "missing"),
+		"control-plane",
+		0,
+	); err == nil {
+		t.Fatal("expected a missing token file to be rejected")
+	}
+
+	tokenPath := writeTokenFile(t, "token")
+	provider, err := NewTokenFileClientProvider(Config{CoderURL: coderURL}, tokenPath, "control-plane", time.Hour)
+	if err != nil {
+		t.Fatalf("new token file client provider: %v", err)
+	}
+	if err := os.WriteFile(tokenPath, []byte("  \n"), 0o600); err != nil {
+		t.Fatalf("empty token file: %v", err)
+	}
+	provider.markStale()
+
+	_, err = provider.ClientForNamespace(context.Background(), "control-plane")
+	if !apierrors.IsServiceUnavailable(err) {
+		t.Fatalf("expected ServiceUnavailable for an empty token file, got %v", err)
+	}
+}
+
+func writeTokenFile(t *testing.T, token string) string {
+	t.Helper()
+
+	tokenPath := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(tokenPath, []byte(token), 0o600); err != nil {
+		t.Fatalf("write token file: %v", err)
+	}
+
+	return tokenPath
+}
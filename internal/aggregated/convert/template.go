@@ -3,6 +3,8 @@ package convert
 
 import (
 	"fmt"
+	"maps"
+	"sort"
 	"strconv"
 
 	aggregationv1alpha1 "github.com/coder/coder-k8s/api/aggregation/v1alpha1"
@@ -21,6 +23,33 @@ func TemplateToK8s(namespace string, t codersdk.Template) *aggregationv1alpha1.C
 
 	updatedAt := metav1.NewTime(t.UpdatedAt)
 
+	var dormancyThresholdMillis, autoDeleteThresholdMillis *int64
+	if t.TimeTilDormantMillis != 0 {
+		dormancyThresholdMillis = &t.TimeTilDormantMillis
+	}
+	if t.TimeTilDormantAutoDeleteMillis != 0 {
+		autoDeleteThresholdMillis = &t.TimeTilDormantAutoDeleteMillis
+	}
+
+	var deprecationMessage *string
+	if t.DeprecationMessage != "" {
+		deprecationMessage = &t.DeprecationMessage
+	}
+	var defaultTTLMillis, activityBumpMillis *int64
+	if t.DefaultTTLMillis != 0 {
+		defaultTTLMillis = &t.DefaultTTLMillis
+	}
+	if t.ActivityBumpMillis != 0 {
+		activityBumpMillis = &t.ActivityBumpMillis
+	}
+	var autostopRequirement *aggregationv1alpha1.CoderTemplateAutostopRequirement
+	if len(t.AutostopRequirement.DaysOfWeek) > 0 {
+		autostopRequirement = &aggregationv1alpha1.CoderTemplateAutostopRequirement{
+			DaysOfWeek: append([]string(nil), t.AutostopRequirement.DaysOfWeek...),
+			Weeks:      t.AutostopRequirement.Weeks,
+		}
+	}
+
 	return &aggregationv1alpha1.CoderTemplate{
 		TypeMeta: metav1.TypeMeta{
 			Kind:       "CoderTemplate",
@@ -39,6 +68,14 @@ func TemplateToK8s(namespace string, t codersdk.Template) *aggregationv1alpha1.C
 			DisplayName:  t.DisplayName,
 			Description:  t.Description,
 			Icon:         t.Icon,
+
+			DormancyThresholdMillis:   dormancyThresholdMillis,
+			AutoDeleteThresholdMillis: autoDeleteThresholdMillis,
+
+			DeprecationMessage:  deprecationMessage,
+			DefaultTTLMillis:    defaultTTLMillis,
+			ActivityBumpMillis:  activityBumpMillis,
+			AutostopRequirement: autostopRequirement,
 		},
 		Status: aggregationv1alpha1.CoderTemplateStatus{
 			ID:               t.ID.String(),
@@ -70,9 +107,29 @@ func TemplateCreateRequestFromK8s(obj *aggregationv1alpha1.CoderTemplate, templa
 		DisplayName: obj.Spec.DisplayName,
 		Description: obj.Spec.Description,
 		Icon:        obj.Spec.Icon,
+
+		TimeTilDormantMillis:           obj.Spec.DormancyThresholdMillis,
+		TimeTilDormantAutoDeleteMillis: obj.Spec.AutoDeleteThresholdMillis,
+
+		DefaultTTLMillis:    obj.Spec.DefaultTTLMillis,
+		ActivityBumpMillis:  obj.Spec.ActivityBumpMillis,
+		AutostopRequirement: TemplateAutostopRequirementFromK8s(obj.Spec.AutostopRequirement),
 	}, nil
 }
 
+// TemplateAutostopRequirementFromK8s converts a CoderTemplate autostop
+// requirement to its codersdk form. A nil requirement converts to nil.
+func TemplateAutostopRequirementFromK8s(requirement *aggregationv1alpha1.CoderTemplateAutostopRequirement) *codersdk.TemplateAutostopRequirement {
+	if requirement == nil {
+		return nil
+	}
+
+	return &codersdk.TemplateAutostopRequirement{
+		DaysOfWeek: append([]string{}, requirement.DaysOfWeek...),
+		Weeks:      requirement.Weeks,
+	}
+}
+
 // TemplateUpdateMetaRequestFromK8s builds a codersdk.UpdateTemplateMeta request.
 func TemplateUpdateMetaRequestFromK8s(obj *aggregationv1alpha1.CoderTemplate) codersdk.UpdateTemplateMeta {
 	if obj == nil {
@@ -83,9 +140,213 @@ func TemplateUpdateMetaRequestFromK8s(obj *aggregationv1alpha1.CoderTemplate) co
 	description := obj.Spec.Description
 	icon := obj.Spec.Icon
 
+	// Coder treats omitted cleanup thresholds as zero, so always send the
+	// desired values to avoid clearing them on unrelated metadata updates.
+	// The same applies to the default TTL and activity bump.
+	var dormancyThresholdMillis, autoDeleteThresholdMillis, defaultTTLMillis, activityBumpMillis int64
+	if obj.Spec.DormancyThresholdMillis != nil {
+		dormancyThresholdMillis = *obj.Spec.DormancyThresholdMillis
+	}
+	if obj.Spec.AutoDeleteThresholdMillis != nil {
+		autoDeleteThresholdMillis = *obj.Spec.AutoDeleteThresholdMillis
+	}
+	if obj.Spec.DefaultTTLMillis != nil {
+		defaultTTLMillis = *obj.Spec.DefaultTTLMillis
+	}
+	if obj.Spec.ActivityBumpMillis != nil {
+		activityBumpMillis = *obj.Spec.ActivityBumpMillis
+	}
+
 	return codersdk.UpdateTemplateMeta{
 		DisplayName: &displayName,
 		Description: &description,
 		Icon:        &icon,
+
+		TimeTilDormantMillis:           dormancyThresholdMillis,
+		TimeTilDormantAutoDeleteMillis: autoDeleteThresholdMillis,
+
+		DeprecationMessage:  obj.Spec.DeprecationMessage,
+		DefaultTTLMillis:    defaultTTLMillis,
+		ActivityBumpMillis:  activityBumpMillis,
+		AutostopRequirement: TemplateAutostopRequirementFromK8s(obj.Spec.AutostopRequirement),
+	}
+}
+
+// ValidateTemplateCleanupThresholds checks the dormant/auto-delete thresholds on a CoderTemplate spec.
+func ValidateTemplateCleanupThresholds(spec aggregationv1alpha1.CoderTemplateSpec) error {
+	if spec.DormancyThresholdMillis != nil && *spec.DormancyThresholdMillis < 0 {
+		return fmt.Errorf("spec.dormancyThresholdMillis must not be negative, got %d", *spec.DormancyThresholdMillis)
+	}
+	if spec.AutoDeleteThresholdMillis != nil && *spec.AutoDeleteThresholdMillis < 0 {
+		return fmt.Errorf("spec.autoDeleteThresholdMillis must not be negative, got %d", *spec.AutoDeleteThresholdMillis)
+	}
+	if spec.AutoDeleteThresholdMillis != nil && *spec.AutoDeleteThresholdMillis > 0 &&
+		(spec.DormancyThresholdMillis == nil || *spec.DormancyThresholdMillis == 0) {
+		return fmt.Errorf("spec.autoDeleteThresholdMillis requires spec.dormancyThresholdMillis: workspaces must become dormant before they can be auto-deleted")
+	}
+
+	return nil
+}
+
+// ValidateTemplateSchedule checks the default TTL, activity bump, and autostop
+// requirement on a CoderTemplate spec.
+func ValidateTemplateSchedule(spec aggregationv1alpha1.CoderTemplateSpec) error {
+	if spec.DefaultTTLMillis != nil && *spec.DefaultTTLMillis < 0 {
+		return fmt.Errorf("spec.defaultTTLMillis must not be negative, got %d", *spec.DefaultTTLMillis)
+	}
+	if spec.ActivityBumpMillis != nil && *spec.ActivityBumpMillis < 0 {
+		return fmt.Errorf("spec.activityBumpMillis must not be negative, got %d", *spec.ActivityBumpMillis)
+	}
+	if spec.AutostopRequirement == nil {
+		return nil
+	}
+
+	if spec.AutostopRequirement.Weeks < 0 {
+		return fmt.Errorf("spec.autostopRequirement.weeks must not be negative, got %d", spec.AutostopRequirement.Weeks)
+	}
+	seen := make(map[string]struct{}, len(spec.AutostopRequirement.DaysOfWeek))
+	for i, day := range spec.AutostopRequirement.DaysOfWeek {
+		if _, ok := weekdays[day]; !ok {
+			return fmt.Errorf("spec.autostopRequirement.daysOfWeek[%d] %q must be a lowercase weekday", i, day)
+		}
+		if _, ok := seen[day]; ok {
+			return fmt.Errorf("spec.autostopRequirement.daysOfWeek[%d] %q is duplicated", i, day)
+		}
+		seen[day] = struct{}{}
+	}
+
+	return nil
+}
+
+var weekdays = map[string]struct{}{
+	"monday": {}, "tuesday": {}, "wednesday": {}, "thursday": {}, "friday": {}, "saturday": {}, "sunday": {},
+}
+
+// TemplateCleanupThresholdsSet reports whether a CoderTemplate spec enables dormancy or auto-deletion.
+func TemplateCleanupThresholdsSet(spec aggregationv1alpha1.CoderTemplateSpec) bool {
+	return (spec.DormancyThresholdMillis != nil && *spec.DormancyThresholdMillis > 0) ||
+		(spec.AutoDeleteThresholdMillis != nil && *spec.AutoDeleteThresholdMillis > 0)
+}
+
+// TemplateACLToK8s converts a codersdk.TemplateACL to a CoderTemplateACL with
+// entries sorted by name.
+func TemplateACLToK8s(acl codersdk.TemplateACL) *aggregationv1alpha1.CoderTemplateACL {
+	result := &aggregationv1alpha1.CoderTemplateACL{}
+	for _, user := range acl.Users {
+		result.Users = append(result.Users, aggregationv1alpha1.CoderTemplateACLEntry{
+			Name: user.Username,
+			Role: aggregationv1alpha1.CoderTemplateRole(user.Role),
+		})
+	}
+	for _, group := range acl.Groups {
+		result.Groups = append(result.Groups, aggregationv1alpha1.CoderTemplateACLEntry{
+			Name: group.Name,
+			Role: aggregationv1alpha1.CoderTemplateRole(group.Role),
+		})
+	}
+	sortTemplateACLEntries(result.Users)
+	sortTemplateACLEntries(result.Groups)
+
+	return result
+}
+
+// ValidateTemplateACL checks the user and group entries of a CoderTemplate ACL.
+func ValidateTemplateACL(acl *aggregationv1alpha1.CoderTemplateACL) error {
+	if acl == nil {
+		return nil
+	}
+	if err := validateTemplateACLEntries("spec.acl.users", acl.Users); err != nil {
+		return err
+	}
+
+	return validateTemplateACLEntries("spec.acl.groups", acl.Groups)
+}
+
+// TemplateACLEqual reports whether two ACLs grant the same roles, ignoring
+// entry order. A nil ACL only equals another nil ACL.
+func TemplateACLEqual(a, b *aggregationv1alpha1.CoderTemplateACL) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+
+	return maps.Equal(templateACLEntryRoles(a.Users), templateACLEntryRoles(b.Users)) &&
+		maps.Equal(templateACLEntryRoles(a.Groups), templateACLEntryRoles(b.Groups))
+}
+
+func validateTemplateACLEntries(path string, entries []aggregationv1alpha1.CoderTemplateACLEntry) error {
+	seen := make(map[string]struct{}, len(entries))
+	for i, entry := range entries {
+		if entry.Name == "" {
+			return fmt.Errorf("%s[%d].name must not be empty", path, i)
+		}
+		if _, ok := seen[entry.Name]; ok {
+			return fmt.Errorf("%s[%d].name %q is duplicated", path, i, entry.Name)
+		}
+		seen[entry.Name] = struct{}{}
+
+		switch entry.Role {
+		case aggregationv1alpha1.CoderTemplateRoleAdmin, aggregationv1alpha1.CoderTemplateRoleUse:
+		default:
+			return fmt.Errorf("%s[%d].role must be %q or %q, got %q",
+				path, i, aggregationv1alpha1.CoderTemplateRoleAdmin, aggregationv1alpha1.CoderTemplateRoleUse, entry.Role)
+		}
+	}
+
+	return nil
+}
+
+func templateACLEntryRoles(entries []aggregationv1alpha1.CoderTemplateACLEntry) map[string]aggregationv1alpha1.CoderTemplateRole {
+	roles := make(map[string]aggregationv1alpha1.CoderTemplateRole, len(entries))
+	for _, entry := range entries {
+		roles[entry.Name] = entry.Role
+	}
+
+	return roles
+}
+
+func sortTemplateACLEntries(entries []aggregationv1alpha1.CoderTemplateACLEntry) {
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Name < entries[j].Name
+	})
+}
+
+// TemplateVersionToK8s converts a codersdk.TemplateVersion of template t to an aggregated API CoderTemplateVersion.
+func TemplateVersionToK8s(
+	namespace string,
+	t codersdk.Template,
+	v codersdk.TemplateVersion,
+) *aggregationv1alpha1.CoderTemplateVersion {
+	if namespace == "" {
+		panic("assertion failed: namespace must not be empty")
+	}
+	if v.TemplateID == nil || *v.TemplateID != t.ID {
+		panic("assertion failed: template version must belong to template")
+	}
+
+	return &aggregationv1alpha1.CoderTemplateVersion{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "CoderTemplateVersion",
+			APIVersion: aggregationv1alpha1.SchemeGroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              coder.BuildTemplateVersionName(t.OrganizationName, t.Name, v.ID.String()),
+			Namespace:         namespace,
+			UID:               types.UID(v.ID.String()),
+			ResourceVersion:   strconv.FormatInt(v.UpdatedAt.UnixNano(), 10),
+			CreationTimestamp: metav1.NewTime(v.CreatedAt),
+		},
+		Spec: aggregationv1alpha1.CoderTemplateVersionSpec{
+			Organization: t.OrganizationName,
+			TemplateName: t.Name,
+			Message:      v.Message,
+		},
+		Status: aggregationv1alpha1.CoderTemplateVersionStatus{
+			ID:          v.ID.String(),
+			VersionName: v.Name,
+			TemplateID:  t.ID.String(),
+			Active:      t.ActiveVersionID == v.ID,
+			JobStatus:   string(v.Job.Status),
+			JobError:    v.Job.Error,
+		},
 	}
 }
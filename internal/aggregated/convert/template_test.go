@@ -134,3 +134,58 @@ func TestTemplateCreateRequestFromK8sRejectsInvalidVersionID(t *testing.T) {
 		t.Fatalf("expected parse error, got %v", err)
 	}
 }
+
+func TestValidateTemplateACL(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		acl     *aggregationv1alpha1.CoderTemplateACL
+		wantErr string
+	}{
+		{name: "nil ACL", acl: nil},
+		{name: "empty ACL", acl: &aggregationv1alpha1.CoderTemplateACL{}},
+		{
+			name: "valid entries",
+			acl: &aggregationv1alpha1.CoderTemplateACL{
+				Users:  []aggregationv1alpha1.CoderTemplateACLEntry{{Name: "alice", Role: aggregationv1alpha1.CoderTemplateRoleAdmin}},
+				Groups: []aggregationv1alpha1.CoderTemplateACLEntry{{Name: "Everyone", Role: aggregationv1alpha1.CoderTemplateRoleUse}},
+			},
+		},
+		{
+			name:    "empty name",
+			acl:     &aggregationv1alpha1.CoderTemplateACL{Users: []aggregationv1alpha1.CoderTemplateACLEntry{{Role: aggregationv1alpha1.CoderTemplateRoleUse}}},
+			wantErr: "spec.acl.users[0].name must not be empty",
+		},
+		{
+			name: "duplicate group",
+			acl: &aggregationv1alpha1.CoderTemplateACL{Groups: []aggregationv1alpha1.CoderTemplateACLEntry{
+				{Name: "platform", Role: aggregationv1alpha1.CoderTemplateRoleUse},
+				{Name: "platform", Role: aggregationv1alpha1.CoderTemplateRoleAdmin},
+			}},
+			wantErr: `spec.acl.groups[1].name "platform" is duplicated`,
+		},
+		{
+			name:    "unknown role",
+			acl:     &aggregationv1alpha1.CoderTemplateACL{Users: []aggregationv1alpha1.CoderTemplateACLEntry{{Name: "alice", Role: "owner"}}},
+			wantErr: "spec.acl.users[0].role must be",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := ValidateTemplateACL(tt.acl)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
@@ -3,6 +3,7 @@ package convert
 import (
 	"fmt"
 	"strconv"
+	"time"
 
 	aggregationv1alpha1 "github.com/coder/coder-k8s/api/aggregation/v1alpha1"
 	"github.com/coder/coder-k8s/internal/aggregated/coder"
@@ -24,6 +25,7 @@ func WorkspaceToK8s(namespace string, w codersdk.Workspace) *aggregationv1alpha1
 		autoShutdown = &autoShutdownTime
 	}
 	lastUsedAt := metav1.NewTime(w.LastUsedAt)
+	buildStartedAt, buildCompletedAt, buildDurationSeconds := workspaceBuildTiming(w.LatestBuild.Job)
 
 	return &aggregationv1alpha1.CoderWorkspace{
 		TypeMeta: metav1.TypeMeta{
@@ -46,14 +48,21 @@ func WorkspaceToK8s(namespace string, w codersdk.Workspace) *aggregationv1alpha1
 			AutostartSchedule: w.AutostartSchedule,
 		},
 		Status: aggregationv1alpha1.CoderWorkspaceStatus{
-			ID:                w.ID.String(),
-			OwnerName:         w.OwnerName,
-			OrganizationName:  w.OrganizationName,
-			TemplateName:      w.TemplateName,
-			LatestBuildID:     w.LatestBuild.ID.String(),
-			LatestBuildStatus: string(w.LatestBuild.Status),
-			AutoShutdown:      autoShutdown,
-			LastUsedAt:        &lastUsedAt,
+			ID:                       w.ID.String(),
+			OwnerName:                w.OwnerName,
+			OrganizationName:         w.OrganizationName,
+			TemplateName:             w.TemplateName,
+			TemplateVersionID:        w.LatestBuild.TemplateVersionID.String(),
+			LatestBuildID:            w.LatestBuild.ID.String(),
+			LatestBuildStatus:        string(w.LatestBuild.Status),
+			LastBuildStartedAt:       buildStartedAt,
+			LastBuildCompletedAt:     buildCompletedAt,
+			LastBuildDurationSeconds: buildDurationSeconds,
+			DailyCost:                w.LatestBuild.DailyCost,
+			Health:                   workspaceHealth(w),
+			AgentStatus:              workspaceAgentStatus(w),
+			AutoShutdown:             autoShutdown,
+			LastUsedAt:               &lastUsedAt,
 		},
 	}
 }
@@ -71,6 +80,61 @@ func workspaceRunning(workspace codersdk.Workspace) bool {
 	}
 }
 
+// workspaceHealth treats a workspace without failing agents as healthy, which
+// also covers responses that omit the health field.
+func workspaceHealth(workspace codersdk.Workspace) aggregationv1alpha1.CoderWorkspaceHealth {
+	if workspace.Health.Healthy || len(workspace.Health.FailingAgents) == 0 {
+		return aggregationv1alpha1.CoderWorkspaceHealthHealthy
+	}
+
+	return aggregationv1alpha1.CoderWorkspaceHealthUnhealthy
+}
+
+// agentStatusRank orders agent statuses from most to least connected.
+var agentStatusRank = map[codersdk.WorkspaceAgentStatus]int{
+	codersdk.WorkspaceAgentConnected:    0,
+	codersdk.WorkspaceAgentConnecting:   1,
+	codersdk.WorkspaceAgentDisconnected: 2,
+	codersdk.WorkspaceAgentTimeout:      3,
+}
+
+// workspaceAgentStatus returns the least connected status among the agents of
+// the latest build. The workspace list response already carries the build's
+// resources and agents, so this needs no extra Coder API call.
+func workspaceAgentStatus(workspace codersdk.Workspace) string {
+	var status codersdk.WorkspaceAgentStatus
+	for _, resource := range workspace.LatestBuild.Resources {
+		for _, agent := range resource.Agents {
+			if status == "" || agentStatusRank[agent.Status] > agentStatusRank[status] {
+				status = agent.Status
+			}
+		}
+	}
+
+	return string(status)
+}
+
+// workspaceBuildTiming reads the latest build's start and completion times from
+// its provisioner job, which the workspace response already embeds. The
+// duration is only reported once both times are known.
+func workspaceBuildTiming(job codersdk.ProvisionerJob) (*metav1.Time, *metav1.Time, *int64) {
+	var startedAt, completedAt *metav1.Time
+	if job.StartedAt != nil && !job.StartedAt.IsZero() {
+		started := metav1.NewTime(*job.StartedAt)
+		startedAt = &started
+	}
+	if job.CompletedAt != nil && !job.CompletedAt.IsZero() {
+		completed := metav1.NewTime(*job.CompletedAt)
+		completedAt = &completed
+	}
+	if startedAt == nil || completedAt == nil || job.CompletedAt.Before(*job.StartedAt) {
+		return startedAt, completedAt, nil
+	}
+
+	durationSeconds := int64(job.CompletedAt.Sub(*job.StartedAt).Round(time.Second) / time.Second)
+	return startedAt, completedAt, &durationSeconds
+}
+
 // WorkspaceCreateRequestFromK8s builds a codersdk.CreateWorkspaceRequest.
 func WorkspaceCreateRequestFromK8s(
 	obj *aggregationv1alpha1.CoderWorkspace,
@@ -7,6 +7,7 @@ import (
 	aggregationv1alpha1 "github.com/coder/coder-k8s/api/aggregation/v1alpha1"
 	"github.com/coder/coder/v2/codersdk"
 	"github.com/google/uuid"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 func TestWorkspaceToK8s(t *testing.T) {
@@ -180,6 +181,167 @@ func TestWorkspaceToK8sRunningStateFromTransitionAndStatus(t *testing.T) {
 	}
 }
 
+func TestWorkspaceToK8sHealthAndAgentStatus(t *testing.T) {
+	t.Parallel()
+
+	agents := func(statuses ...codersdk.WorkspaceAgentStatus) []codersdk.WorkspaceResource {
+		resource := codersdk.WorkspaceResource{ID: uuid.New(), Name: "dev"}
+		for _, status := range statuses {
+			resource.Agents = append(resource.Agents, codersdk.WorkspaceAgent{ID: uuid.New(), Status: status})
+		}
+		return []codersdk.WorkspaceResource{resource}
+	}
+
+	testCases := []struct {
+		name        string
+		health      codersdk.WorkspaceHealth
+		resources   []codersdk.WorkspaceResource
+		wantHealth  aggregationv1alpha1.CoderWorkspaceHealth
+		agentStatus string
+	}{
+		{
+			name:       "no agents",
+			health:     codersdk.WorkspaceHealth{Healthy: true},
+			wantHealth: aggregationv1alpha1.CoderWorkspaceHealthHealthy,
+		},
+		{
+			name:       "health omitted",
+			wantHealth: aggregationv1alpha1.CoderWorkspaceHealthHealthy,
+		},
+		{
+			name:        "all connected",
+			health:      codersdk.WorkspaceHealth{Healthy: true},
+			resources:   agents(codersdk.WorkspaceAgentConnected, codersdk.WorkspaceAgentConnected),
+			wantHealth:  aggregationv1alpha1.CoderWorkspaceHealthHealthy,
+			agentStatus: string(codersdk.WorkspaceAgentConnected),
+		},
+		{
+			name:        "one connecting",
+			health:      codersdk.WorkspaceHealth{Healthy: true},
+			resources:   agents(codersdk.WorkspaceAgentConnected, codersdk.WorkspaceAgentConnecting),
+			wantHealth:  aggregationv1alpha1.CoderWorkspaceHealthHealthy,
+			agentStatus: string(codersdk.WorkspaceAgentConnecting),
+		},
+		{
+			name:        "one timed out",
+			health:      codersdk.WorkspaceHealth{FailingAgents: []uuid.UUID{uuid.New()}},
+			resources:   agents(codersdk.WorkspaceAgentTimeout, codersdk.WorkspaceAgentDisconnected),
+			wantHealth:  aggregationv1alpha1.CoderWorkspaceHealthUnhealthy,
+			agentStatus: string(codersdk.WorkspaceAgentTimeout),
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			workspace := codersdk.Workspace{
+				ID:               uuid.New(),
+				OwnerName:        "alice",
+				OrganizationName: "acme",
+				Name:             "dev-workspace",
+				Health:           testCase.health,
+				LatestBuild: codersdk.WorkspaceBuild{
+					ID:        uuid.New(),
+					Resources: testCase.resources,
+				},
+			}
+
+			converted := WorkspaceToK8s("control-plane", workspace)
+			if converted.Status.Health != testCase.wantHealth {
+				t.Fatalf("expected health %q, got %q", testCase.wantHealth, converted.Status.Health)
+			}
+			if converted.Status.AgentStatus != testCase.agentStatus {
+				t.Fatalf("expected agent status %q, got %q", testCase.agentStatus, converted.Status.AgentStatus)
+			}
+		})
+	}
+}
+
+func TestWorkspaceToK8sLastBuildTiming(t *testing.T) {
+	t.Parallel()
+
+	startedAt := time.Date(2025, time.February, 2, 3, 4, 5, 0, time.UTC)
+	completedAt := startedAt.Add(92*time.Second + 600*time.Millisecond)
+	roundedDurationSeconds := int64(93)
+
+	testCases := []struct {
+		name          string
+		job           codersdk.ProvisionerJob
+		wantStarted   *time.Time
+		wantCompleted *time.Time
+		wantDuration  *int64
+	}{
+		{
+			name: "queued",
+			job:  codersdk.ProvisionerJob{Status: codersdk.ProvisionerJobPending},
+		},
+		{
+			name:        "running",
+			job:         codersdk.ProvisionerJob{Status: codersdk.ProvisionerJobRunning, StartedAt: &startedAt},
+			wantStarted: &startedAt,
+		},
+		{
+			name: "completed",
+			job: codersdk.ProvisionerJob{
+				Status:      codersdk.ProvisionerJobSucceeded,
+				StartedAt:   &startedAt,
+				CompletedAt: &completedAt,
+			},
+			wantStarted:   &startedAt,
+			wantCompleted: &completedAt,
+			wantDuration:  &roundedDurationSeconds,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			workspace := codersdk.Workspace{
+				ID:               uuid.New(),
+				OwnerName:        "alice",
+				OrganizationName: "acme",
+				Name:             "dev-workspace",
+				LatestBuild: codersdk.WorkspaceBuild{
+					ID:        uuid.New(),
+					Job:       testCase.job,
+					DailyCost: 7,
+				},
+			}
+
+			status := WorkspaceToK8s("control-plane", workspace).Status
+			assertOptionalTime(t, "lastBuildStartedAt", status.LastBuildStartedAt, testCase.wantStarted)
+			assertOptionalTime(t, "lastBuildCompletedAt", status.LastBuildCompletedAt, testCase.wantCompleted)
+			switch {
+			case testCase.wantDuration == nil && status.LastBuildDurationSeconds != nil:
+				t.Fatalf("expected no lastBuildDurationSeconds, got %d", *status.LastBuildDurationSeconds)
+			case testCase.wantDuration != nil &&
+				(status.LastBuildDurationSeconds == nil || *status.LastBuildDurationSeconds != *testCase.wantDuration):
+				t.Fatalf("expected lastBuildDurationSeconds %d, got %v", *testCase.wantDuration, status.LastBuildDurationSeconds)
+			}
+			if got, want := status.DailyCost, int32(7); got != want {
+				t.Fatalf("expected dailyCost %d, got %d", want, got)
+			}
+		})
+	}
+}
+
+func assertOptionalTime(t *testing.T, field string, got *metav1.Time, want *time.Time) {
+	t.Helper()
+
+	switch {
+	case want == nil && got != nil:
+		t.Fatalf("expected no %s, got %s", field, got.Time)
+	case want != nil && got == nil:
+		t.Fatalf("expected %s %s, got none", field, *want)
+	case want != nil && !got.Time.Equal(*want):
+		t.Fatalf("expected %s %s, got %s", field, *want, got.Time)
+	}
+}
+
 func TestWorkspaceCreateRequestFromK8s(t *testing.T) {
 	t.Parallel()
 
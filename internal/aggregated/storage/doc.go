@@ -10,5 +10,9 @@
 //   - A single admin session token is used for all API calls (no per-request impersonation in v1).
 //   - Storage resolves the backing codersdk.Client via a ClientProvider interface.
 //   - All-namespaces LIST aggregates results across eligible CoderControlPlane namespaces
-//     when the provider implements NamespaceLister.
+//     when the provider implements NamespaceLister. Namespaces are listed concurrently;
+//     by default any failing namespace fails the LIST, and ListFanOutModeBestEffort
+//     skips failing namespaces with a warning instead.
+//   - metadata.managedFields for server-side apply are kept in memory per API server
+//     process; applies that own status fields are rejected.
 package storage
@@ -2,8 +2,13 @@ package storage
 
 import (
 	"errors"
+	"net/http"
+	"strings"
 
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+
+	"github.com/coder/coder-k8s/internal/aggregated/coder"
+	"github.com/coder/coder/v2/codersdk"
 )
 
 func wrapClientError(err error) error {
@@ -16,5 +21,20 @@ func wrapClientError(err error) error {
 		return statusErr
 	}
 
+	// Resolving a client can call Coder; pass its rate limits on so clients
+	// back off instead of seeing an opaque internal error.
+	var rateLimitErr *coder.RateLimitedError
+	if errors.As(err, &rateLimitErr) {
+		return rateLimitErr.StatusError()
+	}
+	var coderErr *codersdk.Error
+	if errors.As(err, &coderErr) && coderErr.StatusCode() == http.StatusTooManyRequests {
+		message := strings.TrimSpace(coderErr.Message)
+		if message == "" {
+			message = err.Error()
+		}
+		return apierrors.NewTooManyRequests(message, 0)
+	}
+
 	return apierrors.NewInternalError(err)
 }
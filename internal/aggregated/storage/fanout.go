@@ -0,0 +1,109 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"k8s.io/apiserver/pkg/warning"
+)
+
+// ListFanOutMode selects how an all-namespaces LIST handles a namespace whose
+// Coder backend fails.
+type ListFanOutMode string
+
+const (
+	// ListFanOutModeStrict fails the whole LIST when any namespace fails. It
+	// is the default, so a LIST never silently omits objects.
+	ListFanOutModeStrict ListFanOutMode = "strict"
+	// ListFanOutModeBestEffort returns the healthy namespaces and reports the
+	// failing ones as a warning.
+	ListFanOutModeBestEffort ListFanOutMode = "best-effort"
+
+	// listFanOutConcurrency bounds how many namespaces are listed at once.
+	listFanOutConcurrency = 4
+)
+
+// ParseListFanOutMode parses a list fan-out mode. An empty value selects
+// ListFanOutModeStrict.
+func ParseListFanOutMode(value string) (ListFanOutMode, error) {
+	switch mode := ListFanOutMode(strings.TrimSpace(value)); mode {
+	case "", ListFanOutModeStrict:
+		return ListFanOutModeStrict, nil
+	case ListFanOutModeBestEffort:
+		return ListFanOutModeBestEffort, nil
+	default:
+		return "", fmt.Errorf(
+			"invalid list fan-out mode %q: must be %q or %q",
+			value,
+			ListFanOutModeStrict,
+			ListFanOutModeBestEffort,
+		)
+	}
+}
+
+// fanOutList lists every namespace concurrently, at most
+// listFanOutConcurrency at a time, and concatenates the results in namespace
+// order. In best-effort mode failing namespaces are skipped and reported as a
+// warning on the request, and the LIST only fails when every namespace fails.
+// Otherwise the first failing namespace's error is returned.
+func fanOutList[T any](
+	ctx context.Context,
+	mode ListFanOutMode,
+	namespaces []string,
+	list func(ctx context.Context, namespace string) ([]T, error),
+) ([]T, error) {
+	if ctx == nil {
+		return nil, fmt.Errorf("assertion failed: context must not be nil")
+	}
+	if list == nil {
+		return nil, fmt.Errorf("assertion failed: list function must not be nil")
+	}
+
+	strict := mode != ListFanOutModeBestEffort
+	results := make([][]T, len(namespaces))
+	errs := make([]error, len(namespaces))
+	slots := make(chan struct{}, listFanOutConcurrency)
+	var wg sync.WaitGroup
+	for i, namespace := range namespaces {
+		wg.Add(1)
+		slots <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-slots }()
+			results[i], errs[i] = list(ctx, namespace)
+		}()
+	}
+	wg.Wait()
+
+	items := make([]T, 0)
+	var (
+		failed   []string
+		firstErr error
+	)
+	for i, namespace := range namespaces {
+		if errs[i] != nil {
+			if strict {
+				return nil, errs[i]
+			}
+			if firstErr == nil {
+				firstErr = errs[i]
+			}
+			failed = append(failed, fmt.Sprintf("%s (%v)", namespace, errs[i]))
+			continue
+		}
+		items = append(items, results[i]...)
+	}
+	if len(failed) > 0 {
+		if len(failed) == len(namespaces) {
+			return nil, firstErr
+		}
+		warning.AddWarning(ctx, "", fmt.Sprintf(
+			"partial list: skipped namespaces whose Coder backend failed: %s",
+			strings.Join(failed, "; "),
+		))
+	}
+
+	return items, nil
+}
@@ -0,0 +1,115 @@
+package storage
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	metainternalversion "k8s.io/apimachinery/pkg/apis/meta/internalversion"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// listCacheTTL bounds how long a List response may be served again to
+// resourceVersion=0 requests. Such requests accept any recent state, which
+// lets informer relists and resyncs skip the Coder API.
+const listCacheTTL = 5 * time.Second
+
+type listCacheEntry struct {
+	list     runtime.Object
+	storedAt time.Time
+}
+
+// listCache holds the most recent List response for each namespace and
+// selector combination for listCacheTTL.
+type listCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	now     func() time.Time
+	entries map[string]listCacheEntry
+}
+
+func newListCache(ttl time.Duration) *listCache {
+	return &listCache{
+		ttl:     ttl,
+		now:     time.Now,
+		entries: make(map[string]listCacheEntry),
+	}
+}
+
+// listCacheRequested reports whether a List may be served from the cache.
+// Only resourceVersion=0 opts in; an empty resourceVersion asks for the most
+// recent state and always goes to Coder.
+func listCacheRequested(opts *metainternalversion.ListOptions) bool {
+	return opts != nil && opts.ResourceVersion == "0"
+}
+
+// listCacheKey identifies a List request by namespace and selectors. The
+// codersdk client is chosen per namespace, not per caller, so callers allowed
+// to list a namespace all see the same response.
+func listCacheKey(namespace string, opts *metainternalversion.ListOptions) string {
+	var key strings.Builder
+	key.WriteString(namespace)
+	key.WriteByte(0)
+	if opts != nil && opts.FieldSelector != nil {
+		key.WriteString(opts.FieldSelector.String())
+	}
+	key.WriteByte(0)
+	if opts != nil && opts.LabelSelector != nil {
+		key.WriteString(opts.LabelSelector.String())
+	}
+
+	return key.String()
+}
+
+// get returns a copy of the cached list for key when it is younger than the
+// TTL.
+func (c *listCache) get(key string) (runtime.Object, bool) {
+	if c == nil {
+		return nil, false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if c.now().Sub(entry.storedAt) >= c.ttl {
+		delete(c.entries, key)
+		return nil, false
+	}
+
+	return entry.list.DeepCopyObject(), true
+}
+
+// put stores a copy of list for key and drops expired entries.
+func (c *listCache) put(key string, list runtime.Object) {
+	if c == nil || list == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	for existingKey, entry := range c.entries {
+		if now.Sub(entry.storedAt) >= c.ttl {
+			delete(c.entries, existingKey)
+		}
+	}
+	c.entries[key] = listCacheEntry{list: list.DeepCopyObject(), storedAt: now}
+}
+
+// reset drops every cached list, for example after a write through this API
+// server.
+func (c *listCache) reset() {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	clear(c.entries)
+}
@@ -0,0 +1,111 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// managedFieldsStore remembers metadata.managedFields written through this API
+// server so server-side apply can track field owners across requests. Coder has
+// no place to persist Kubernetes metadata, so entries live in memory only and
+// are lost when the aggregated API server restarts.
+type managedFieldsStore struct {
+	mu      sync.Mutex
+	entries map[string]managedFieldsEntry
+}
+
+// managedFieldsEntry ties managed fields to the Coder object they were written
+// for, so a resource recreated outside Kubernetes does not inherit them.
+type managedFieldsEntry struct {
+	uid           types.UID
+	managedFields []metav1.ManagedFieldsEntry
+}
+
+func newManagedFieldsStore() *managedFieldsStore {
+	return &managedFieldsStore{entries: make(map[string]managedFieldsEntry)}
+}
+
+func managedFieldsKey(namespace, name string) string {
+	return namespace + "/" + name
+}
+
+// get returns a copy of the managed fields recorded for obj, or nil when none
+// were recorded for its current UID.
+func (s *managedFieldsStore) get(obj metav1.Object) []metav1.ManagedFieldsEntry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := managedFieldsKey(obj.GetNamespace(), obj.GetName())
+	entry, ok := s.entries[key]
+	if !ok {
+		return nil
+	}
+	if entry.uid != obj.GetUID() {
+		delete(s.entries, key)
+		return nil
+	}
+
+	return copyManagedFields(entry.managedFields)
+}
+
+// record stores managedFields for obj and sets them on it.
+func (s *managedFieldsStore) record(obj metav1.Object, managedFields []metav1.ManagedFieldsEntry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := managedFieldsKey(obj.GetNamespace(), obj.GetName())
+	if len(managedFields) == 0 {
+		delete(s.entries, key)
+	} else {
+		s.entries[key] = managedFieldsEntry{uid: obj.GetUID(), managedFields: copyManagedFields(managedFields)}
+	}
+	obj.SetManagedFields(copyManagedFields(managedFields))
+}
+
+func (s *managedFieldsStore) forget(namespace, name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.entries, managedFieldsKey(namespace, name))
+}
+
+func copyManagedFields(managedFields []metav1.ManagedFieldsEntry) []metav1.ManagedFieldsEntry {
+	if len(managedFields) == 0 {
+		return nil
+	}
+
+	copied := make([]metav1.ManagedFieldsEntry, len(managedFields))
+	for i := range managedFields {
+		managedFields[i].DeepCopyInto(&copied[i])
+	}
+
+	return copied
+}
+
+// rejectAppliedStatus rejects server-side apply requests that try to own status
+// fields. Status is computed from Coder and is never written back.
+func rejectAppliedStatus(managedFields []metav1.ManagedFieldsEntry) error {
+	for _, entry := range managedFields {
+		if entry.Operation != metav1.ManagedFieldsOperationApply || entry.Subresource != "" || entry.FieldsV1 == nil {
+			continue
+		}
+
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(entry.FieldsV1.Raw, &fields); err != nil {
+			return fmt.Errorf("decode managed fields for manager %q: %w", entry.Manager, err)
+		}
+		if _, ok := fields["f:status"]; ok {
+			return apierrors.NewBadRequest(fmt.Sprintf(
+				"field manager %q cannot apply status fields; status is computed from Coder",
+				entry.Manager,
+			))
+		}
+	}
+
+	return nil
+}
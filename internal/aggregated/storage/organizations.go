@@ -0,0 +1,81 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/coder/coder/v2/codersdk"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/coder/coder-k8s/internal/aggregated/coder"
+)
+
+// organizationAllowList is the set of Coder organization names LIST may
+// return. A nil set allows every organization.
+type organizationAllowList map[string]struct{}
+
+// allowedOrganizations asks provider for the organizations served in
+// namespace. Providers that do not filter organizations allow all of them.
+func allowedOrganizations(ctx context.Context, provider coder.ClientProvider, namespace string) (organizationAllowList, error) {
+	if ctx == nil {
+		return nil, fmt.Errorf("assertion failed: context must not be nil")
+	}
+
+	filter, ok := provider.(coder.OrganizationFilter)
+	if !ok {
+		return nil, nil
+	}
+
+	organizations, err := filter.AllowedOrganizations(ctx, namespace)
+	if err != nil {
+		return nil, wrapClientError(err)
+	}
+	if len(organizations) == 0 {
+		return nil, nil
+	}
+
+	allowed := make(organizationAllowList, len(organizations))
+	for _, organization := range organizations {
+		allowed[organization] = struct{}{}
+	}
+
+	return allowed, nil
+}
+
+func (l organizationAllowList) allows(organization string) bool {
+	if l == nil {
+		return true
+	}
+	_, ok := l[organization]
+
+	return ok
+}
+
+// organizationForCreate resolves the organization parsed from the metadata.name
+// of an object being created. A missing organization is a BadRequest naming it,
+// rather than a NotFound for the object itself.
+func organizationForCreate(
+	ctx context.Context,
+	sdk *codersdk.Client,
+	orgName string,
+	resource schema.GroupResource,
+	name string,
+) (codersdk.Organization, error) {
+	if sdk == nil {
+		return codersdk.Organization{}, fmt.Errorf("assertion failed: codersdk client must not be nil")
+	}
+
+	org, err := sdk.OrganizationByName(ctx, orgName)
+	if err != nil {
+		if coderStatusCode(err) == http.StatusNotFound {
+			return codersdk.Organization{}, apierrors.NewBadRequest(
+				fmt.Sprintf("Coder organization %q parsed from metadata.name not found", orgName),
+			)
+		}
+		return codersdk.Organization{}, coder.MapCoderError(err, resource, name)
+	}
+
+	return org, nil
+}
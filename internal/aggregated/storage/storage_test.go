@@ -1,6 +1,7 @@
 package storage
 
 import (
+	"archive/tar"
 	"archive/zip"
 	"bytes"
 	"context"
@@ -12,8 +13,11 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"os"
+	"path/filepath"
 	"reflect"
 	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"testing"
@@ -21,10 +25,13 @@ import (
 
 	"github.com/google/uuid"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metainternalversion "k8s.io/apimachinery/pkg/apis/meta/internalversion"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/runtime"
 	genericapirequest "k8s.io/apiserver/pkg/endpoints/request"
 	"k8s.io/apiserver/pkg/registry/rest"
+	"k8s.io/apiserver/pkg/warning"
 
 	aggregationv1alpha1 "github.com/coder/coder-k8s/api/aggregation/v1alpha1"
 	"github.com/coder/coder-k8s/internal/aggregated/coder"
@@ -71,7 +78,7 @@ func TestTemplateStorageCRUDWithCoderSDK(t *testing.T) {
 		t.Fatalf("expected organization acme, got %q", template.Spec.Organization)
 	}
 
-	versionID := uuid.New()
+	versionID := state.addDetachedTemplateVersion()
 	createObj := &aggregationv1alpha1.CoderTemplate{
 		ObjectMeta: metav1.ObjectMeta{Name: "acme.ops-template"},
 		Spec: aggregationv1alpha1.CoderTemplateSpec{
@@ -142,6 +149,74 @@ func TestTemplateStorageGetPopulatesSpecFiles(t *testing.T) {
 	}
 }
 
+func TestTemplateStorageGetCachesSpecFilesUntilTemplateChanges(t *testing.T) {
+	t.Parallel()
+
+	server, state := newMockCoderServer(t)
+	defer server.Close()
+
+	templateStorage := NewTemplateStorage(newTestClientProvider(t, server.URL))
+	ctx := namespacedContext("control-plane")
+	expectedFiles := map[string]string{"main.tf": seededTemplateMainTF}
+
+	getTemplate := func(t *testing.T) *aggregationv1alpha1.CoderTemplate {
+		t.Helper()
+
+		obj, err := templateStorage.Get(ctx, "acme.starter-template", nil)
+		if err != nil {
+			t.Fatalf("expected template get to succeed: %v", err)
+		}
+		template, ok := obj.(*aggregationv1alpha1.CoderTemplate)
+		if !ok {
+			t.Fatalf("expected *CoderTemplate from get, got %T", obj)
+		}
+		if !reflect.DeepEqual(template.Spec.Files, expectedFiles) {
+			t.Fatalf("expected get to populate spec.files %v, got %v", expectedFiles, template.Spec.Files)
+		}
+		return template
+	}
+
+	first := getTemplate(t)
+	downloadsAfterFirst := state.fileDownloadCount()
+	if downloadsAfterFirst == 0 {
+		t.Fatal("expected the first get to download the template source")
+	}
+
+	// Callers own the returned map; mutating it must not poison the cache.
+	first.Spec.Files["main.tf"] = "mutated"
+	getTemplate(t)
+	if got := state.fileDownloadCount(); got != downloadsAfterFirst {
+		t.Fatalf("expected the second get of an unchanged template not to re-download files, downloads %d -> %d", downloadsAfterFirst, got)
+	}
+
+	desired := getTemplate(t)
+	desired.Spec.Files = nil
+	desired.Spec.DisplayName = "Starter (renamed)"
+	if _, _, err := templateStorage.Update(
+		ctx,
+		desired.Name,
+		testUpdatedObjectInfo{obj: desired},
+		nil,
+		rest.ValidateAllObjectUpdateFunc,
+		false,
+		nil,
+	); err != nil {
+		t.Fatalf("expected template metadata update to succeed: %v", err)
+	}
+
+	// The update bumped the template's updatedAt, so its refreshing get
+	// downloaded the files again; later gets reuse that entry.
+	downloadsAfterUpdate := state.fileDownloadCount()
+	getTemplate(t)
+	getTemplate(t)
+	if got := state.fileDownloadCount(); got != downloadsAfterUpdate {
+		t.Fatalf("expected gets after the update refresh to reuse the cache, downloads %d -> %d", downloadsAfterUpdate, got)
+	}
+	if downloadsAfterUpdate <= downloadsAfterFirst {
+		t.Fatalf("expected a changed updatedAt to invalidate the cached files, downloads stayed %d", downloadsAfterUpdate)
+	}
+}
+
 func TestTemplateStorageListOmitsSpecFiles(t *testing.T) {
 	t.Parallel()
 
@@ -171,6 +246,43 @@ func TestTemplateStorageListOmitsSpecFiles(t *testing.T) {
 	}
 }
 
+func TestTemplateStorageListPopulatesActiveVersionNames(t *testing.T) {
+	t.Parallel()
+
+	server, state := newMockCoderServer(t)
+	defer server.Close()
+
+	const extraTemplates = 3 * templateListVersionConcurrency
+	for i := range extraTemplates {
+		state.addTemplateWithActiveVersion(fmt.Sprintf("bulk-%02d", i), fmt.Sprintf("bulk-%02d-v1", i))
+	}
+
+	listObj, err := NewTemplateStorage(newTestClientProvider(t, server.URL)).List(namespacedContext("control-plane"), nil)
+	if err != nil {
+		t.Fatalf("expected template list to succeed: %v", err)
+	}
+	list, ok := listObj.(*aggregationv1alpha1.CoderTemplateList)
+	if !ok {
+		t.Fatalf("expected *CoderTemplateList, got %T", listObj)
+	}
+	if got, want := len(list.Items), extraTemplates+1; got != want {
+		t.Fatalf("expected %d templates, got %d", want, got)
+	}
+	if !sort.SliceIsSorted(list.Items, func(i, j int) bool { return list.Items[i].Name < list.Items[j].Name }) {
+		t.Fatal("expected template list items to be sorted by name")
+	}
+
+	for _, template := range list.Items {
+		want := "starter-template-v1"
+		if bulkName, ok := strings.CutPrefix(template.Name, "acme.bulk-"); ok {
+			want = "bulk-" + bulkName + "-v1"
+		}
+		if template.Status.ActiveVersionName != want {
+			t.Fatalf("expected %q active version name %q, got %q", template.Name, want, template.Status.ActiveVersionName)
+		}
+	}
+}
+
 func TestTemplateStorageCreateWithFiles(t *testing.T) {
 	t.Parallel()
 
@@ -236,6 +348,154 @@ func TestTemplateStorageCreateWithFiles(t *testing.T) {
 	}
 }
 
+func TestTemplateStorageCreatePreservesNestedFiles(t *testing.T) {
+	t.Parallel()
+
+	server, state := newMockCoderServer(t)
+	defer server.Close()
+
+	templateStorage := NewTemplateStorage(newTestClientProvider(t, server.URL))
+	ctx := namespacedContext("control-plane")
+
+	mainTF := "module \"foo\" { source = \"./modules/foo\" }"
+	createObj := &aggregationv1alpha1.CoderTemplate{
+		ObjectMeta: metav1.ObjectMeta{Name: "acme.nested-files-template"},
+		Spec: aggregationv1alpha1.CoderTemplateSpec{
+			Organization: "acme",
+			Files: map[string]string{
+				"main.tf":                    mainTF,
+				"./main.tf":                  mainTF,
+				"./modules/foo/main.tf":      "resource \"null_resource\" \"foo\" {}",
+				"modules/foo/variables.tf":   "variable \"name\" {}",
+				"modules/bar/nested/main.tf": "resource \"null_resource\" \"bar\" {}",
+			},
+		},
+	}
+	wantFiles := map[string]string{
+		"main.tf":                    mainTF,
+		"modules/foo/main.tf":        "resource \"null_resource\" \"foo\" {}",
+		"modules/foo/variables.tf":   "variable \"name\" {}",
+		"modules/bar/nested/main.tf": "resource \"null_resource\" \"bar\" {}",
+	}
+
+	createdObj, err := templateStorage.Create(ctx, createObj, rest.ValidateAllObjectFunc, nil)
+	if err != nil {
+		t.Fatalf("expected template create with nested files to succeed: %v", err)
+	}
+	if _, ok := createdObj.(*aggregationv1alpha1.CoderTemplate); !ok {
+		t.Fatalf("expected *CoderTemplate from create, got %T", createdObj)
+	}
+	if _, ok := createObj.Spec.Files["./main.tf"]; !ok {
+		t.Fatal("expected create to leave the request object's spec.files unmodified")
+	}
+
+	fetchedObj, err := templateStorage.Get(ctx, createObj.Name, nil)
+	if err != nil {
+		t.Fatalf("expected get for created template to succeed: %v", err)
+	}
+	fetchedTemplate, ok := fetchedObj.(*aggregationv1alpha1.CoderTemplate)
+	if !ok {
+		t.Fatalf("expected *CoderTemplate from get, got %T", fetchedObj)
+	}
+	if !reflect.DeepEqual(fetchedTemplate.Spec.Files, wantFiles) {
+		t.Fatalf("expected nested template files %v, got %v", wantFiles, fetchedTemplate.Spec.Files)
+	}
+
+	fileCountBefore := state.fileCount()
+	conflictObj := &aggregationv1alpha1.CoderTemplate{
+		ObjectMeta: metav1.ObjectMeta{Name: "acme.conflicting-files-template"},
+		Spec: aggregationv1alpha1.CoderTemplateSpec{
+			Organization: "acme",
+			Files: map[string]string{
+				"modules/foo/main.tf":   "resource \"null_resource\" \"one\" {}",
+				"./modules/foo/main.tf": "resource \"null_resource\" \"two\" {}",
+			},
+		},
+	}
+	_, err = templateStorage.Create(ctx, conflictObj, rest.ValidateAllObjectFunc, nil)
+	if !apierrors.IsBadRequest(err) {
+		t.Fatalf("expected conflicting normalized paths to return BadRequest, got %v", err)
+	}
+	if state.fileCount() != fileCountBefore {
+		t.Fatalf("expected conflicting paths to avoid file uploads, before=%d after=%d", fileCountBefore, state.fileCount())
+	}
+}
+
+func TestTemplateStorageVersionNameReachesCoderAndStatus(t *testing.T) {
+	t.Parallel()
+
+	server, state := newMockCoderServer(t)
+	defer server.Close()
+
+	templateStorage := NewTemplateStorage(newTestClientProvider(t, server.URL))
+	ctx := namespacedContext("control-plane")
+
+	createObj := &aggregationv1alpha1.CoderTemplate{
+		ObjectMeta: metav1.ObjectMeta{Name: "acme.named-version-template"},
+		Spec: aggregationv1alpha1.CoderTemplateSpec{
+			Organization: "acme",
+			Files:        map[string]string{"main.tf": "resource \"null_resource\" \"v1\" {}"},
+			VersionName:  "git-1a2b3c4",
+		},
+	}
+
+	createdObj, err := templateStorage.Create(ctx, createObj, rest.ValidateAllObjectFunc, nil)
+	if err != nil {
+		t.Fatalf("expected template create with versionName to succeed: %v", err)
+	}
+	createdTemplate, ok := createdObj.(*aggregationv1alpha1.CoderTemplate)
+	if !ok {
+		t.Fatalf("expected *CoderTemplate from create, got %T", createdObj)
+	}
+	if createdTemplate.Status.ActiveVersionName != "git-1a2b3c4" {
+		t.Fatalf("expected created status.activeVersionName %q, got %q", "git-1a2b3c4", createdTemplate.Status.ActiveVersionName)
+	}
+
+	activeVersionID, ok := state.templateActiveVersionID("acme", "named-version-template")
+	if !ok {
+		t.Fatal("expected created template active version in mock state")
+	}
+	if versionName, _ := state.templateVersionName(activeVersionID); versionName != "git-1a2b3c4" {
+		t.Fatalf("expected backend template version name %q, got %q", "git-1a2b3c4", versionName)
+	}
+
+	currentObj, err := templateStorage.Get(ctx, createObj.Name, nil)
+	if err != nil {
+		t.Fatalf("expected template get to succeed: %v", err)
+	}
+	currentTemplate, ok := currentObj.(*aggregationv1alpha1.CoderTemplate)
+	if !ok {
+		t.Fatalf("expected *CoderTemplate from get, got %T", currentObj)
+	}
+	if currentTemplate.Status.ActiveVersionName != "git-1a2b3c4" {
+		t.Fatalf("expected status.activeVersionName %q on get, got %q", "git-1a2b3c4", currentTemplate.Status.ActiveVersionName)
+	}
+
+	desiredTemplate := currentTemplate.DeepCopy()
+	desiredTemplate.Spec.Files = map[string]string{"main.tf": "resource \"null_resource\" \"v2\" {}"}
+	desiredTemplate.Spec.VersionName = "git-5d6e7f8"
+
+	updatedObj, _, err := templateStorage.Update(
+		ctx,
+		desiredTemplate.Name,
+		testUpdatedObjectInfo{obj: desiredTemplate},
+		nil,
+		rest.ValidateAllObjectUpdateFunc,
+		false,
+		nil,
+	)
+	if err != nil {
+		t.Fatalf("expected template update with versionName to succeed: %v", err)
+	}
+	updatedTemplate, ok := updatedObj.(*aggregationv1alpha1.CoderTemplate)
+	if !ok {
+		t.Fatalf("expected *CoderTemplate from update, got %T", updatedObj)
+	}
+	if updatedTemplate.Status.ActiveVersionName != "git-5d6e7f8" {
+		t.Fatalf("expected updated status.activeVersionName %q, got %q", "git-5d6e7f8", updatedTemplate.Status.ActiveVersionName)
+	}
+}
+
 func TestTemplateStorageUpdateWithChangedFiles(t *testing.T) {
 	t.Parallel()
 
@@ -569,6 +829,69 @@ func TestTemplateStorageUpdateReturnsTimeoutWhenRequestContextTimesOut(t *testin
 	assertTopLevelStatusError(t, updateErr)
 }
 
+func TestStoragesReturnTimeoutWhenCoderCallExceedsDeadline(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+		writeCoderError(w, http.StatusServiceUnavailable, "slow backend")
+	}))
+	defer server.Close()
+
+	serverURL, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatalf("parse server URL: %v", err)
+	}
+	sdk, err := coder.NewSDKClient(coder.Config{
+		CoderURL:       serverURL,
+		SessionToken:   "test-session-token",
+		RequestTimeout: 100 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("create SDK client: %v", err)
+	}
+	provider := &coder.StaticClientProvider{Client: sdk, Namespace: "control-plane"}
+	ctx := namespacedContext("control-plane")
+
+	for _, tc := range []struct {
+		name string
+		get  func() error
+	}{
+		{
+			name: "workspace",
+			get: func() error {
+				workspaceStorage := NewWorkspaceStorage(provider)
+				defer workspaceStorage.Destroy()
+				_, err := workspaceStorage.Get(ctx, "acme.alice.dev", nil)
+				return err
+			},
+		},
+		{
+			name: "template",
+			get: func() error {
+				_, err := NewTemplateStorage(provider).Get(ctx, "acme.starter", nil)
+				return err
+			},
+		},
+	} {
+		start := time.Now()
+		err := tc.get()
+		if err == nil {
+			t.Fatalf("%s: expected error from slow backend", tc.name)
+		}
+		if !apierrors.IsTimeout(err) {
+			t.Fatalf("%s: expected timeout error, got %v", tc.name, err)
+		}
+		if elapsed := time.Since(start); elapsed > 2*time.Second {
+			t.Fatalf("%s: expected per-call deadline to fire quickly, took %v", tc.name, elapsed)
+		}
+		assertTopLevelStatusError(t, err)
+	}
+}
+
 func TestMapTemplateVersionBuildWaitErrorMapsRequestContextFailuresToTimeout(t *testing.T) {
 	tests := []struct {
 		name  string
@@ -675,6 +998,124 @@ func TestLoadTemplateVersionBuildWaitConfigFromEnvInvalid(t *testing.T) {
 	}
 }
 
+func TestTemplateStorageRejectsFilesOverLimits(t *testing.T) {
+	t.Setenv(templateFilesMaxCountEnv, "2")
+	t.Setenv(templateFilesMaxBytesEnv, "16")
+
+	server, state := newMockCoderServer(t)
+	defer server.Close()
+
+	templateStorage := NewTemplateStorage(newTestClientProvider(t, server.URL))
+	ctx := namespacedContext("control-plane")
+
+	fileCountBefore := state.fileCount()
+
+	for _, testCase := range []struct {
+		name  string
+		files map[string]string
+	}{
+		{
+			name:  "too many files",
+			files: map[string]string{"a.tf": "a", "b.tf": "b", "c.tf": "c"},
+		},
+		{
+			name:  "too many bytes",
+			files: map[string]string{"main.tf": strings.Repeat("x", 17)},
+		},
+	} {
+		createObj := &aggregationv1alpha1.CoderTemplate{
+			ObjectMeta: metav1.ObjectMeta{Name: "acme.over-limit-template"},
+			Spec: aggregationv1alpha1.CoderTemplateSpec{
+				Organization: "acme",
+				Files:        testCase.files,
+			},
+		}
+		_, err := templateStorage.Create(ctx, createObj, rest.ValidateAllObjectFunc, nil)
+		if !apierrors.IsBadRequest(err) {
+			t.Fatalf("%s: expected create to return BadRequest, got %v", testCase.name, err)
+		}
+
+		currentObj, err := templateStorage.Get(ctx, "acme.starter-template", nil)
+		if err != nil {
+			t.Fatalf("expected template get to succeed: %v", err)
+		}
+		currentTemplate, ok := currentObj.(*aggregationv1alpha1.CoderTemplate)
+		if !ok {
+			t.Fatalf("expected *CoderTemplate from get, got %T", currentObj)
+		}
+		desiredTemplate := currentTemplate.DeepCopy()
+		desiredTemplate.Spec.Files = testCase.files
+
+		_, _, err = templateStorage.Update(
+			ctx,
+			desiredTemplate.Name,
+			testUpdatedObjectInfo{obj: desiredTemplate},
+			nil,
+			rest.ValidateAllObjectUpdateFunc,
+			false,
+			nil,
+		)
+		if !apierrors.IsBadRequest(err) {
+			t.Fatalf("%s: expected update to return BadRequest, got %v", testCase.name, err)
+		}
+	}
+
+	if state.fileCount() != fileCountBefore {
+		t.Fatalf("expected over-limit requests to avoid file uploads, before=%d after=%d", fileCountBefore, state.fileCount())
+	}
+	if state.hasTemplate("acme", "over-limit-template") {
+		t.Fatal("expected over-limit create to leave the template absent in Coder")
+	}
+}
+
+func TestTemplateStorageRejectsFilePathTraversal(t *testing.T) {
+	t.Parallel()
+
+	server, state := newMockCoderServer(t)
+	defer server.Close()
+
+	templateStorage := NewTemplateStorage(newTestClientProvider(t, server.URL))
+	ctx := namespacedContext("control-plane")
+
+	fileCountBefore := state.fileCount()
+
+	for _, filePath := range []string{"../escape.tf", "/etc/main.tf", "modules/../main.tf", "modules/../../escape.tf"} {
+		createObj := &aggregationv1alpha1.CoderTemplate{
+			ObjectMeta: metav1.ObjectMeta{Name: "acme.traversal-template"},
+			Spec: aggregationv1alpha1.CoderTemplateSpec{
+				Organization: "acme",
+				Files:        map[string]string{filePath: "resource \"null_resource\" \"escape\" {}"},
+			},
+		}
+		_, err := templateStorage.Create(ctx, createObj, rest.ValidateAllObjectFunc, nil)
+		if !apierrors.IsBadRequest(err) {
+			t.Fatalf("expected create with file path %q to return BadRequest, got %v", filePath, err)
+		}
+	}
+
+	if state.fileCount() != fileCountBefore {
+		t.Fatalf("expected rejected paths to avoid file uploads, before=%d after=%d", fileCountBefore, state.fileCount())
+	}
+}
+
+func TestLoadTemplateFilesLimitsFromEnvInvalid(t *testing.T) {
+	t.Setenv(templateFilesMaxCountEnv, "0")
+	t.Setenv(templateFilesMaxBytesEnv, "")
+
+	_, err := loadTemplateFilesLimitsFromEnv()
+	if err == nil || !strings.Contains(err.Error(), templateFilesMaxCountEnv) {
+		t.Fatalf("expected error to mention %s, got %v", templateFilesMaxCountEnv, err)
+	}
+
+	t.Setenv(templateFilesMaxCountEnv, "")
+	t.Setenv(templateFilesMaxBytesEnv, strconv.Itoa(maxTemplateSourceTotalUncompressedBytes+1))
+
+	_, err = loadTemplateFilesLimitsFromEnv()
+	if err == nil || !strings.Contains(err.Error(), templateFilesMaxBytesEnv) {
+		t.Fatalf("expected error to mention %s, got %v", templateFilesMaxBytesEnv, err)
+	}
+}
+
 func TestTemplateStorageUpdateVerifiesActiveVersionPromotion(t *testing.T) {
 	t.Parallel()
 
@@ -830,7 +1271,20 @@ func TestTemplateStorageUpdateWithIdenticalFilesIsNoOp(t *testing.T) {
 	}
 }
 
-func TestTemplateStorageUpdatePreservesNonUTF8Files(t *testing.T) {
+type fakeTemplateSourceFetcher struct {
+	source  TemplateSource
+	fetched []aggregationv1alpha1.CoderTemplateGitSource
+}
+
+func (f *fakeTemplateSourceFetcher) Fetch(
+	_ context.Context,
+	source aggregationv1alpha1.CoderTemplateGitSource,
+) (TemplateSource, error) {
+	f.fetched = append(f.fetched, source)
+	return TemplateSource{TreeHash: f.source.TreeHash, Files: cloneStringMap(f.source.Files)}, nil
+}
+
+func TestTemplateStorageGitSourceSkipsUnchangedTree(t *testing.T) {
 	t.Parallel()
 
 	server, state := newMockCoderServer(t)
@@ -839,16 +1293,201 @@ func TestTemplateStorageUpdatePreservesNonUTF8Files(t *testing.T) {
 	templateStorage := NewTemplateStorage(newTestClientProvider(t, server.URL))
 	ctx := namespacedContext("control-plane")
 
-	currentObj, err := templateStorage.Get(ctx, "acme.starter-template", nil)
-	if err != nil {
-		t.Fatalf("expected template get to succeed: %v", err)
-	}
-	currentTemplate, ok := currentObj.(*aggregationv1alpha1.CoderTemplate)
-	if !ok {
-		t.Fatalf("expected *CoderTemplate from get, got %T", currentObj)
+	gitSource := &aggregationv1alpha1.CoderTemplateGitSource{
+		URL:    "https://git.example.com/acme/templates.git",
+		Ref:    "main",
+		Subdir: "docker",
 	}
-	if _, hasBinary := currentTemplate.Spec.Files["binary.dat"]; hasBinary {
-		t.Fatal("expected non-UTF8 binary.dat to be omitted from spec.files")
+	createObj := &aggregationv1alpha1.CoderTemplate{
+		ObjectMeta: metav1.ObjectMeta{Name: "acme.git-template"},
+		Spec: aggregationv1alpha1.CoderTemplateSpec{
+			Organization: "acme",
+			GitSource:    gitSource,
+		},
+	}
+
+	_, err := templateStorage.Create(ctx, createObj, rest.ValidateAllObjectFunc, nil)
+	if !apierrors.IsBadRequest(err) || !strings.Contains(err.Error(), "--enable-template-git-sources") {
+		t.Fatalf("expected BadRequest while git sources are disabled, got %v", err)
+	}
+
+	fetcher := &fakeTemplateSourceFetcher{source: TemplateSource{
+		TreeHash: "4b825dc642cb6eb9a060e54bf8d69288fbee4904",
+		Files: map[string]string{
+			"main.tf":             "resource \"null_resource\" \"v1\" {}",
+			"modules/foo/main.tf": "variable \"name\" {}",
+		},
+	}}
+	templateStorage.SetGitSourceFetcher(fetcher)
+
+	invalidRefObj := createObj.DeepCopy()
+	invalidRefObj.Spec.GitSource.Ref = "--upload-pack=touch /tmp/pwned"
+	_, err = templateStorage.Create(ctx, invalidRefObj, rest.ValidateAllObjectFunc, nil)
+	if !apierrors.IsBadRequest(err) || !strings.Contains(err.Error(), "spec.gitSource.ref") {
+		t.Fatalf("expected BadRequest for an invalid ref, got %v", err)
+	}
+	withFilesObj := createObj.DeepCopy()
+	withFilesObj.Spec.Files = map[string]string{"main.tf": ""}
+	_, err = templateStorage.Create(ctx, withFilesObj, rest.ValidateAllObjectFunc, nil)
+	if !apierrors.IsBadRequest(err) || !strings.Contains(err.Error(), "mutually exclusive") {
+		t.Fatalf("expected BadRequest for spec.files with spec.gitSource, got %v", err)
+	}
+	if len(fetcher.fetched) != 0 {
+		t.Fatalf("expected rejected requests not to fetch, got %d fetches", len(fetcher.fetched))
+	}
+
+	createdObj, err := templateStorage.Create(ctx, createObj, rest.ValidateAllObjectFunc, nil)
+	if err != nil {
+		t.Fatalf("expected template create from git source to succeed: %v", err)
+	}
+	createdTemplate, ok := createdObj.(*aggregationv1alpha1.CoderTemplate)
+	if !ok {
+		t.Fatalf("expected *CoderTemplate from create, got %T", createdObj)
+	}
+	if got, want := createdTemplate.Status.SourceTreeHash, fetcher.source.TreeHash; got != want {
+		t.Fatalf("expected created status.sourceTreeHash %q, got %q", want, got)
+	}
+	if len(fetcher.fetched) != 1 || fetcher.fetched[0] != *gitSource {
+		t.Fatalf("expected one fetch of %+v, got %+v", *gitSource, fetcher.fetched)
+	}
+
+	currentObj, err := templateStorage.Get(ctx, createObj.Name, nil)
+	if err != nil {
+		t.Fatalf("expected get for git template to succeed: %v", err)
+	}
+	currentTemplate, ok := currentObj.(*aggregationv1alpha1.CoderTemplate)
+	if !ok {
+		t.Fatalf("expected *CoderTemplate from get, got %T", currentObj)
+	}
+	if !reflect.DeepEqual(currentTemplate.Spec.Files, fetcher.source.Files) {
+		t.Fatalf("expected imported files %v, got %v", fetcher.source.Files, currentTemplate.Spec.Files)
+	}
+
+	fileCountBefore := state.fileCount()
+	templateVersionCountBefore := state.templateVersionCount()
+	activeVersionBefore, ok := state.templateActiveVersionID("acme", "git-template")
+	if !ok {
+		t.Fatal("expected active version for created template")
+	}
+
+	unchangedTemplate := currentTemplate.DeepCopy()
+	unchangedTemplate.Spec.GitSource = gitSource.DeepCopy()
+	_, _, err = templateStorage.Update(
+		ctx,
+		unchangedTemplate.Name,
+		testUpdatedObjectInfo{obj: unchangedTemplate},
+		nil,
+		rest.ValidateAllObjectUpdateFunc,
+		false,
+		nil,
+	)
+	if err != nil {
+		t.Fatalf("expected update with an unchanged git tree to succeed: %v", err)
+	}
+	if state.fileCount() != fileCountBefore || state.templateVersionCount() != templateVersionCountBefore {
+		t.Fatalf(
+			"expected no upload or version for an unchanged tree, files %d->%d versions %d->%d",
+			fileCountBefore,
+			state.fileCount(),
+			templateVersionCountBefore,
+			state.templateVersionCount(),
+		)
+	}
+	if activeVersionAfter, _ := state.templateActiveVersionID("acme", "git-template"); activeVersionAfter != activeVersionBefore {
+		t.Fatalf("expected active version to remain %q for an unchanged tree, got %q", activeVersionBefore, activeVersionAfter)
+	}
+
+	fetcher.source = TemplateSource{
+		TreeHash: "9bd1cf6c0bd4d8dd3f1f4c4de6bb2d2b8a7f0f0e",
+		Files:    map[string]string{"main.tf": "resource \"null_resource\" \"v2\" {}"},
+	}
+	changedTemplate := currentTemplate.DeepCopy()
+	changedTemplate.Spec.GitSource = gitSource.DeepCopy()
+	updatedObj, _, err := templateStorage.Update(
+		ctx,
+		changedTemplate.Name,
+		testUpdatedObjectInfo{obj: changedTemplate},
+		nil,
+		rest.ValidateAllObjectUpdateFunc,
+		false,
+		nil,
+	)
+	if err != nil {
+		t.Fatalf("expected update with a changed git tree to succeed: %v", err)
+	}
+	if got, want := state.templateVersionCount(), templateVersionCountBefore+1; got != want {
+		t.Fatalf("expected one new template version for a changed tree, got %d want %d", got, want)
+	}
+	updatedTemplate, ok := updatedObj.(*aggregationv1alpha1.CoderTemplate)
+	if !ok {
+		t.Fatalf("expected *CoderTemplate from update, got %T", updatedObj)
+	}
+	if got, want := updatedTemplate.Status.SourceTreeHash, fetcher.source.TreeHash; got != want {
+		t.Fatalf("expected updated status.sourceTreeHash %q, got %q", want, got)
+	}
+	// The Git tree replaces the source instead of merging with the previous version.
+	if !reflect.DeepEqual(updatedTemplate.Spec.Files, fetcher.source.Files) {
+		t.Fatalf("expected files %v after git update, got %v", fetcher.source.Files, updatedTemplate.Spec.Files)
+	}
+}
+
+func TestGitTemplateSourceFetcherStopsOversizedArchive(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	var header bytes.Buffer
+	tarWriter := tar.NewWriter(&header)
+	if err := tarWriter.WriteHeader(&tar.Header{
+		Name:     "main.tf",
+		Mode:     0o644,
+		Size:     maxTemplateSourceFileBytes + 1,
+		Typeflag: tar.TypeReg,
+	}); err != nil {
+		t.Fatalf("write tar header: %v", err)
+	}
+	headerPath := filepath.Join(dir, "header.tar")
+	if err := os.WriteFile(headerPath, header.Bytes(), 0o600); err != nil {
+		t.Fatalf("write tar header file: %v", err)
+	}
+	// The fake git writes an oversized tar entry followed by an endless
+	// stream, so archive only returns if it stops git.
+	gitPath := filepath.Join(dir, "git")
+	script := fmt.Sprintf("#!/bin/sh\nexec cat %q /dev/zero\n", headerPath)
+	if err := os.WriteFile(gitPath, []byte(script), 0o700); err != nil {
+		t.Fatalf("write fake git: %v", err)
+	}
+
+	fetcher := &GitTemplateSourceFetcher{gitPath: gitPath}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	_, err := fetcher.archive(ctx, dir, "tree")
+	if err == nil || !strings.Contains(err.Error(), "exceeds max file size") {
+		t.Fatalf("expected oversized file error, got %v", err)
+	}
+	if ctx.Err() != nil {
+		t.Fatalf("expected archive to stop git before the test deadline, got %v", ctx.Err())
+	}
+}
+
+func TestTemplateStorageUpdatePreservesNonUTF8Files(t *testing.T) {
+	t.Parallel()
+
+	server, state := newMockCoderServer(t)
+	defer server.Close()
+
+	templateStorage := NewTemplateStorage(newTestClientProvider(t, server.URL))
+	ctx := namespacedContext("control-plane")
+
+	currentObj, err := templateStorage.Get(ctx, "acme.starter-template", nil)
+	if err != nil {
+		t.Fatalf("expected template get to succeed: %v", err)
+	}
+	currentTemplate, ok := currentObj.(*aggregationv1alpha1.CoderTemplate)
+	if !ok {
+		t.Fatalf("expected *CoderTemplate from get, got %T", currentObj)
+	}
+	if _, hasBinary := currentTemplate.Spec.Files["binary.dat"]; hasBinary {
+		t.Fatal("expected non-UTF8 binary.dat to be omitted from spec.files")
 	}
 
 	originalSourceZip, ok := state.templateActiveSourceZip("acme", "starter-template")
@@ -1094,160 +1733,104 @@ func TestTemplateStorageUpdateMetadata(t *testing.T) {
 	}
 }
 
-func TestTemplateStorageListAllowsAllNamespacesRequest(t *testing.T) {
+func TestTemplateStorageCreateRejectsUnknownOrganization(t *testing.T) {
 	t.Parallel()
 
-	server, _ := newMockCoderServer(t)
+	server, state := newMockCoderServer(t)
 	defer server.Close()
 
 	templateStorage := NewTemplateStorage(newTestClientProvider(t, server.URL))
+	ctx := namespacedContext("control-plane")
 
-	listObj, err := templateStorage.List(context.Background(), nil)
-	if err != nil {
-		t.Fatalf("expected all-namespaces list to succeed, got %v", err)
-	}
-	list, ok := listObj.(*aggregationv1alpha1.CoderTemplateList)
-	if !ok {
-		t.Fatalf("expected *CoderTemplateList, got %T", listObj)
-	}
-	if len(list.Items) == 0 {
-		t.Fatal("expected at least one template in list")
-	}
-}
-
-func TestTemplateStorageListAggregatesAcrossNamespaces(t *testing.T) {
-	t.Parallel()
-
-	serverA, _ := newMockCoderServer(t)
-	defer serverA.Close()
-	serverB, _ := newMockCoderServer(t)
-	defer serverB.Close()
+	fileCountBefore := state.fileCount()
+	templateVersionCountBefore := state.templateVersionCount()
 
-	provider := &multiNamespaceTestProvider{
-		clients: map[string]*codersdk.Client{
-			"ns-a": newTestSDKClient(t, serverA.URL),
-			"ns-b": newTestSDKClient(t, serverB.URL),
+	_, err := templateStorage.Create(ctx, &aggregationv1alpha1.CoderTemplate{
+		ObjectMeta: metav1.ObjectMeta{Name: "ghost-org.template"},
+		Spec: aggregationv1alpha1.CoderTemplateSpec{
+			Organization: "ghost-org",
+			Files:        map[string]string{"main.tf": "resource \"null_resource\" \"ghost\" {}"},
 		},
-		namespaces: []string{"ns-a", "ns-b"},
-	}
-
-	templateStorage := NewTemplateStorage(provider)
-
-	listObj, err := templateStorage.List(namespacedContext(""), nil)
-	if err != nil {
-		t.Fatalf("expected all-namespaces list to aggregate successfully, got %v", err)
-	}
-
-	list, ok := listObj.(*aggregationv1alpha1.CoderTemplateList)
-	if !ok {
-		t.Fatalf("expected *CoderTemplateList, got %T", listObj)
+	}, rest.ValidateAllObjectFunc, nil)
+	if !apierrors.IsBadRequest(err) {
+		t.Fatalf("expected BadRequest for unknown organization, got %v", err)
 	}
-	if len(list.Items) != 2 {
-		t.Fatalf("expected two templates in aggregated list, got %d", len(list.Items))
+	assertTopLevelStatusError(t, err)
+	if !strings.Contains(err.Error(), `organization "ghost-org"`) {
+		t.Fatalf("expected error to name organization ghost-org, got %v", err)
 	}
-	if list.Items[0].Namespace != "ns-a" || list.Items[1].Namespace != "ns-b" {
-		t.Fatalf(
-			"expected namespaces [ns-a ns-b], got [%s %s]",
-			list.Items[0].Namespace,
-			list.Items[1].Namespace,
-		)
+	if state.fileCount() != fileCountBefore {
+		t.Fatalf("expected no file upload for unknown organization, before=%d after=%d", fileCountBefore, state.fileCount())
 	}
-	if !sort.SliceIsSorted(list.Items, func(i, j int) bool {
-		if list.Items[i].Namespace != list.Items[j].Namespace {
-			return list.Items[i].Namespace < list.Items[j].Namespace
-		}
-		return list.Items[i].Name < list.Items[j].Name
-	}) {
-		t.Fatal("expected template list items to be sorted by namespace then name")
+	if state.templateVersionCount() != templateVersionCountBefore {
+		t.Fatalf("expected no template version for unknown organization, before=%d after=%d", templateVersionCountBefore, state.templateVersionCount())
 	}
 }
 
-func TestTemplateStorageListNamespacedRequestBypassesFanOut(t *testing.T) {
+func TestTemplateStorageCreateDryRunDoesNotMutateCoder(t *testing.T) {
 	t.Parallel()
 
-	serverA, _ := newMockCoderServer(t)
-	defer serverA.Close()
-	serverB, _ := newMockCoderServer(t)
-	defer serverB.Close()
+	server, state := newMockCoderServer(t)
+	defer server.Close()
 
-	provider := &multiNamespaceTestProvider{
-		clients: map[string]*codersdk.Client{
-			"ns-a": newTestSDKClient(t, serverA.URL),
-			"ns-b": newTestSDKClient(t, serverB.URL),
+	templateStorage := NewTemplateStorage(newTestClientProvider(t, server.URL))
+	ctx := namespacedContext("control-plane")
+
+	fileCountBefore := state.fileCount()
+	templateVersionCountBefore := state.templateVersionCount()
+
+	createObj := &aggregationv1alpha1.CoderTemplate{
+		ObjectMeta: metav1.ObjectMeta{Name: "acme.dry-run-template"},
+		Spec: aggregationv1alpha1.CoderTemplateSpec{
+			Organization: "acme",
+			DisplayName:  "Dry Run Template",
+			Files:        map[string]string{"main.tf": "resource \"null_resource\" \"dry\" {}"},
 		},
-		namespaces: []string{"ns-a", "ns-b"},
 	}
 
-	templateStorage := NewTemplateStorage(provider)
-
-	listObj, err := templateStorage.List(namespacedContext("ns-a"), nil)
+	createdObj, err := templateStorage.Create(
+		ctx,
+		createObj,
+		rest.ValidateAllObjectFunc,
+		&metav1.CreateOptions{DryRun: []string{metav1.DryRunAll}},
+	)
 	if err != nil {
-		t.Fatalf("expected namespaced list to succeed, got %v", err)
+		t.Fatalf("expected dry-run template create to succeed: %v", err)
 	}
 
-	list, ok := listObj.(*aggregationv1alpha1.CoderTemplateList)
+	createdTemplate, ok := createdObj.(*aggregationv1alpha1.CoderTemplate)
 	if !ok {
-		t.Fatalf("expected *CoderTemplateList, got %T", listObj)
-	}
-	if len(list.Items) != 1 {
-		t.Fatalf("expected one template from namespaced list, got %d", len(list.Items))
+		t.Fatalf("expected *CoderTemplate from create, got %T", createdObj)
 	}
-	if list.Items[0].Namespace != "ns-a" {
-		t.Fatalf("expected namespaced list item to use ns-a, got %q", list.Items[0].Namespace)
+	if createdTemplate.Namespace != "control-plane" {
+		t.Fatalf("expected dry-run namespace %q, got %q", "control-plane", createdTemplate.Namespace)
 	}
-}
-
-func TestTemplateStorageListPreservesProviderStatusErrors(t *testing.T) {
-	t.Parallel()
-
-	server, _ := newMockCoderServer(t)
-	defer server.Close()
-
-	parsedURL, err := url.Parse(server.URL)
-	if err != nil {
-		t.Fatalf("parse mock server URL %q: %v", server.URL, err)
+	if state.hasTemplate("acme", "dry-run-template") {
+		t.Fatal("expected dry-run create to leave the template absent in Coder")
 	}
-	client := codersdk.New(parsedURL)
-	client.SetSessionToken("test-session-token")
-
-	templateStorage := NewTemplateStorage(&coder.StaticClientProvider{
-		Client:    client,
-		Namespace: "control-plane",
-	})
-
-	_, err = templateStorage.List(namespacedContext("other-namespace"), nil)
-	if !apierrors.IsBadRequest(err) {
-		t.Fatalf("expected BadRequest from provider namespace restriction, got %v", err)
+	if state.fileCount() != fileCountBefore {
+		t.Fatalf("expected dry-run create to avoid file uploads, before=%d after=%d", fileCountBefore, state.fileCount())
 	}
-	assertTopLevelStatusError(t, err)
-}
-
-func TestWrapClientErrorReturnsTopLevelStatusError(t *testing.T) {
-	t.Parallel()
-
-	statusErr := apierrors.NewBadRequest("provider namespace mismatch")
-	wrappedErr := fmt.Errorf("resolve codersdk client for namespace %q: %w", "control-plane", statusErr)
-
-	wrappedClientErr := wrapClientError(wrappedErr)
-	if !apierrors.IsBadRequest(wrappedClientErr) {
-		t.Fatalf("expected BadRequest from wrapped status error, got %v", wrappedClientErr)
+	if state.templateVersionCount() != templateVersionCountBefore {
+		t.Fatalf("expected dry-run create to avoid template version creation, before=%d after=%d", templateVersionCountBefore, state.templateVersionCount())
 	}
 
-	assertTopLevelStatusError(t, wrappedClientErr)
-
-	var unwrappedStatusErr *apierrors.StatusError
-	if !errors.As(wrappedClientErr, &unwrappedStatusErr) {
-		t.Fatalf("expected *apierrors.StatusError in wrapped client error chain, got %T", wrappedClientErr)
-	}
-	if unwrappedStatusErr != statusErr {
-		t.Fatalf("expected wrapClientError to return original status error pointer")
+	createObj.Name = "acme.starter-template"
+	_, err = templateStorage.Create(
+		ctx,
+		createObj,
+		rest.ValidateAllObjectFunc,
+		&metav1.CreateOptions{DryRun: []string{metav1.DryRunAll}},
+	)
+	if !apierrors.IsAlreadyExists(err) {
+		t.Fatalf("expected dry-run create of an existing template to return AlreadyExists, got %v", err)
 	}
 }
 
-func TestTemplateStorageUpdateReturnsCurrentBackendObjectForLegacyRunningField(t *testing.T) {
+func TestTemplateStorageUpdateDryRunDoesNotMutateCoder(t *testing.T) {
 	t.Parallel()
 
-	server, _ := newMockCoderServer(t)
+	server, state := newMockCoderServer(t)
 	defer server.Close()
 
 	templateStorage := NewTemplateStorage(newTestClientProvider(t, server.URL))
@@ -1257,17 +1840,18 @@ func TestTemplateStorageUpdateReturnsCurrentBackendObjectForLegacyRunningField(t
 	if err != nil {
 		t.Fatalf("expected template get to succeed: %v", err)
 	}
-
 	currentTemplate, ok := currentObj.(*aggregationv1alpha1.CoderTemplate)
 	if !ok {
 		t.Fatalf("expected *CoderTemplate from get, got %T", currentObj)
 	}
-	if currentTemplate.ResourceVersion == "" {
-		t.Fatal("expected current template resourceVersion to be populated")
-	}
+
+	metaUpdateCountBefore := state.templateMetaUpdateCount()
+	fileCountBefore := state.fileCount()
+	templateVersionCountBefore := state.templateVersionCount()
 
 	desiredTemplate := currentTemplate.DeepCopy()
-	desiredTemplate.Spec.Running = !currentTemplate.Spec.Running
+	desiredTemplate.Spec.DisplayName = "Dry Run Display Name"
+	desiredTemplate.Spec.Files = map[string]string{"main.tf": "resource \"null_resource\" \"dry\" {}"}
 
 	updatedObj, created, err := templateStorage.Update(
 		ctx,
@@ -1276,31 +1860,37 @@ func TestTemplateStorageUpdateReturnsCurrentBackendObjectForLegacyRunningField(t
 		nil,
 		rest.ValidateAllObjectUpdateFunc,
 		false,
-		nil,
+		&metav1.UpdateOptions{DryRun: []string{metav1.DryRunAll}},
 	)
 	if err != nil {
-		t.Fatalf("expected template update to succeed: %v", err)
+		t.Fatalf("expected dry-run template update to succeed: %v", err)
 	}
 	if created {
 		t.Fatal("expected update created=false")
 	}
+	if state.templateMetaUpdateCount() != metaUpdateCountBefore {
+		t.Fatalf("expected dry-run update to avoid metadata updates, before=%d after=%d", metaUpdateCountBefore, state.templateMetaUpdateCount())
+	}
+	if state.fileCount() != fileCountBefore {
+		t.Fatalf("expected dry-run update to avoid file uploads, before=%d after=%d", fileCountBefore, state.fileCount())
+	}
+	if state.templateVersionCount() != templateVersionCountBefore {
+		t.Fatalf("expected dry-run update to avoid template version creation, before=%d after=%d", templateVersionCountBefore, state.templateVersionCount())
+	}
 
 	updatedTemplate, ok := updatedObj.(*aggregationv1alpha1.CoderTemplate)
 	if !ok {
 		t.Fatalf("expected *CoderTemplate from update, got %T", updatedObj)
 	}
-	if updatedTemplate.Spec.Running != currentTemplate.Spec.Running {
-		t.Fatalf("expected update response running=%t from current backend object, got %t", currentTemplate.Spec.Running, updatedTemplate.Spec.Running)
-	}
-	if updatedTemplate.Name != currentTemplate.Name {
-		t.Fatalf("expected updated name %q, got %q", currentTemplate.Name, updatedTemplate.Name)
+	if updatedTemplate.Spec.DisplayName != desiredTemplate.Spec.DisplayName {
+		t.Fatalf("expected dry-run displayName %q, got %q", desiredTemplate.Spec.DisplayName, updatedTemplate.Spec.DisplayName)
 	}
 }
 
-func TestTemplateStorageUpdateAllowsUnchangedVersionIDWhenTogglingRunning(t *testing.T) {
+func TestTemplateStorageUpdatePolicy(t *testing.T) {
 	t.Parallel()
 
-	server, _ := newMockCoderServer(t)
+	server, state := newMockCoderServer(t)
 	defer server.Close()
 
 	templateStorage := NewTemplateStorage(newTestClientProvider(t, server.URL))
@@ -1310,19 +1900,25 @@ func TestTemplateStorageUpdateAllowsUnchangedVersionIDWhenTogglingRunning(t *tes
 	if err != nil {
 		t.Fatalf("expected template get to succeed: %v", err)
 	}
-
 	currentTemplate, ok := currentObj.(*aggregationv1alpha1.CoderTemplate)
 	if !ok {
 		t.Fatalf("expected *CoderTemplate from get, got %T", currentObj)
 	}
-	if currentTemplate.Spec.VersionID == "" {
-		t.Fatal("expected current template spec.versionID to be populated")
-	}
 
-	desiredTemplate := currentTemplate.DeepCopy()
-	desiredTemplate.Spec.Running = !currentTemplate.Spec.Running
+	metaUpdateCountBefore := state.templateMetaUpdateCount()
+	templateVersionCountBefore := state.templateVersionCount()
 
-	updatedObj, created, err := templateStorage.Update(
+	desiredTemplate := currentTemplate.DeepCopy()
+	deprecationMessage := "Use acme.next-template instead."
+	defaultTTLMillis := int64(8 * time.Hour / time.Millisecond)
+	activityBumpMillis := int64(2 * time.Hour / time.Millisecond)
+	autostopRequirement := &aggregationv1alpha1.CoderTemplateAutostopRequirement{DaysOfWeek: []string{"saturday", "sunday"}, Weeks: 2}
+	desiredTemplate.Spec.DeprecationMessage = &deprecationMessage
+	desiredTemplate.Spec.DefaultTTLMillis = &defaultTTLMillis
+	desiredTemplate.Spec.ActivityBumpMillis = &activityBumpMillis
+	desiredTemplate.Spec.AutostopRequirement = autostopRequirement
+
+	updatedObj, _, err := templateStorage.Update(
 		ctx,
 		desiredTemplate.Name,
 		testUpdatedObjectInfo{obj: desiredTemplate},
@@ -1332,28 +1928,77 @@ func TestTemplateStorageUpdateAllowsUnchangedVersionIDWhenTogglingRunning(t *tes
 		nil,
 	)
 	if err != nil {
-		t.Fatalf("expected template update to succeed when spec.versionID is unchanged: %v", err)
+		t.Fatalf("expected policy update to succeed: %v", err)
 	}
-	if created {
-		t.Fatal("expected update created=false")
+	if state.templateMetaUpdateCount() != metaUpdateCountBefore+1 {
+		t.Fatalf("expected one metadata update call, before=%d after=%d", metaUpdateCountBefore, state.templateMetaUpdateCount())
+	}
+	if state.templateVersionCount() != templateVersionCountBefore {
+		t.Fatalf("expected policy update to avoid template version creation, before=%d after=%d", templateVersionCountBefore, state.templateVersionCount())
 	}
 
 	updatedTemplate, ok := updatedObj.(*aggregationv1alpha1.CoderTemplate)
 	if !ok {
 		t.Fatalf("expected *CoderTemplate from update, got %T", updatedObj)
 	}
-	if updatedTemplate.Spec.Running != currentTemplate.Spec.Running {
-		t.Fatalf("expected update response running=%t from current backend object, got %t", currentTemplate.Spec.Running, updatedTemplate.Spec.Running)
+	if updatedTemplate.Spec.DeprecationMessage == nil || *updatedTemplate.Spec.DeprecationMessage != deprecationMessage {
+		t.Fatalf("expected deprecationMessage %q, got %v", deprecationMessage, updatedTemplate.Spec.DeprecationMessage)
 	}
-	if updatedTemplate.Spec.VersionID != currentTemplate.Spec.VersionID {
-		t.Fatalf("expected update response spec.versionID %q from current backend object, got %q", currentTemplate.Spec.VersionID, updatedTemplate.Spec.VersionID)
+	if !updatedTemplate.Status.Deprecated {
+		t.Fatal("expected status.deprecated to be true")
+	}
+	if updatedTemplate.Spec.DefaultTTLMillis == nil || *updatedTemplate.Spec.DefaultTTLMillis != defaultTTLMillis {
+		t.Fatalf("expected defaultTTLMillis %d, got %v", defaultTTLMillis, updatedTemplate.Spec.DefaultTTLMillis)
+	}
+	if updatedTemplate.Spec.ActivityBumpMillis == nil || *updatedTemplate.Spec.ActivityBumpMillis != activityBumpMillis {
+		t.Fatalf("expected activityBumpMillis %d, got %v", activityBumpMillis, updatedTemplate.Spec.ActivityBumpMillis)
+	}
+	if !reflect.DeepEqual(updatedTemplate.Spec.AutostopRequirement, autostopRequirement) {
+		t.Fatalf("expected autostopRequirement %+v, got %+v", autostopRequirement, updatedTemplate.Spec.AutostopRequirement)
+	}
+
+	// Omitting the policy fields keeps them; an empty deprecation message
+	// un-deprecates the template.
+	undeprecated := updatedTemplate.DeepCopy()
+	emptyMessage := ""
+	undeprecated.Spec.DeprecationMessage = &emptyMessage
+	undeprecated.Spec.DefaultTTLMillis = nil
+	undeprecated.Spec.ActivityBumpMillis = nil
+	undeprecated.Spec.AutostopRequirement = nil
+	undeprecatedObj, _, err := templateStorage.Update(
+		ctx,
+		undeprecated.Name,
+		testUpdatedObjectInfo{obj: undeprecated},
+		nil,
+		rest.ValidateAllObjectUpdateFunc,
+		false,
+		nil,
+	)
+	if err != nil {
+		t.Fatalf("expected un-deprecation update to succeed: %v", err)
+	}
+	result, ok := undeprecatedObj.(*aggregationv1alpha1.CoderTemplate)
+	if !ok {
+		t.Fatalf("expected *CoderTemplate from update, got %T", undeprecatedObj)
+	}
+	if result.Spec.DeprecationMessage != nil || result.Status.Deprecated {
+		t.Fatalf("expected template to be un-deprecated, got message %v deprecated=%t", result.Spec.DeprecationMessage, result.Status.Deprecated)
+	}
+	if result.Spec.DefaultTTLMillis == nil || *result.Spec.DefaultTTLMillis != defaultTTLMillis {
+		t.Fatalf("expected defaultTTLMillis to be preserved as %d, got %v", defaultTTLMillis, result.Spec.DefaultTTLMillis)
+	}
+	if result.Spec.ActivityBumpMillis == nil || *result.Spec.ActivityBumpMillis != activityBumpMillis {
+		t.Fatalf("expected activityBumpMillis to be preserved as %d, got %v", activityBumpMillis, result.Spec.ActivityBumpMillis)
+	}
+	if !reflect.DeepEqual(result.Spec.AutostopRequirement, autostopRequirement) {
+		t.Fatalf("expected autostopRequirement to be preserved as %+v, got %+v", autostopRequirement, result.Spec.AutostopRequirement)
 	}
 }
 
-func TestTemplateStorageUpdateAllowsEmptyVersionIDWhenTogglingRunning(t *testing.T) {
+func TestTemplateStorageUpdateRejectsNegativePolicyDurations(t *testing.T) {
 	t.Parallel()
 
-	server, _ := newMockCoderServer(t)
+	server, state := newMockCoderServer(t)
 	defer server.Close()
 
 	templateStorage := NewTemplateStorage(newTestClientProvider(t, server.URL))
@@ -1363,52 +2008,49 @@ func TestTemplateStorageUpdateAllowsEmptyVersionIDWhenTogglingRunning(t *testing
 	if err != nil {
 		t.Fatalf("expected template get to succeed: %v", err)
 	}
-
 	currentTemplate, ok := currentObj.(*aggregationv1alpha1.CoderTemplate)
 	if !ok {
 		t.Fatalf("expected *CoderTemplate from get, got %T", currentObj)
 	}
-	if currentTemplate.Spec.VersionID == "" {
-		t.Fatal("expected current template spec.versionID to be populated")
-	}
-
-	desiredTemplate := currentTemplate.DeepCopy()
-	desiredTemplate.Spec.VersionID = ""
-	desiredTemplate.Spec.Running = !currentTemplate.Spec.Running
-
-	updatedObj, created, err := templateStorage.Update(
-		ctx,
-		desiredTemplate.Name,
-		testUpdatedObjectInfo{obj: desiredTemplate},
-		nil,
-		rest.ValidateAllObjectUpdateFunc,
-		false,
-		nil,
-	)
-	if err != nil {
-		t.Fatalf("expected template update to succeed when desired spec.versionID is empty: %v", err)
-	}
-	if created {
-		t.Fatal("expected update created=false")
-	}
 
-	updatedTemplate, ok := updatedObj.(*aggregationv1alpha1.CoderTemplate)
-	if !ok {
-		t.Fatalf("expected *CoderTemplate from update, got %T", updatedObj)
-	}
-	if updatedTemplate.Spec.Running != currentTemplate.Spec.Running {
-		t.Fatalf("expected update response running=%t from current backend object, got %t", currentTemplate.Spec.Running, updatedTemplate.Spec.Running)
+	negative := int64(-1)
+	for _, tc := range []struct {
+		field  string
+		mutate func(*aggregationv1alpha1.CoderTemplate)
+	}{
+		{field: "spec.defaultTTLMillis", mutate: func(tpl *aggregationv1alpha1.CoderTemplate) { tpl.Spec.DefaultTTLMillis = &negative }},
+		{field: "spec.activityBumpMillis", mutate: func(tpl *aggregationv1alpha1.CoderTemplate) { tpl.Spec.ActivityBumpMillis = &negative }},
+		{field: "spec.autostopRequirement.weeks", mutate: func(tpl *aggregationv1alpha1.CoderTemplate) {
+			tpl.Spec.AutostopRequirement = &aggregationv1alpha1.CoderTemplateAutostopRequirement{DaysOfWeek: []string{"sunday"}, Weeks: -1}
+		}},
+	} {
+		desiredTemplate := currentTemplate.DeepCopy()
+		tc.mutate(desiredTemplate)
+
+		_, _, err := templateStorage.Update(
+			ctx,
+			desiredTemplate.Name,
+			testUpdatedObjectInfo{obj: desiredTemplate},
+			nil,
+			rest.ValidateAllObjectUpdateFunc,
+			false,
+			nil,
+		)
+		if !apierrors.IsBadRequest(err) || !strings.Contains(err.Error(), tc.field) {
+			t.Fatalf("expected bad request naming %s, got %v", tc.field, err)
+		}
 	}
-	if updatedTemplate.Spec.VersionID != currentTemplate.Spec.VersionID {
-		t.Fatalf("expected update response spec.versionID %q from current backend object, got %q", currentTemplate.Spec.VersionID, updatedTemplate.Spec.VersionID)
+	if state.templateMetaUpdateCount() != 0 {
+		t.Fatalf("expected no metadata update calls, got %d", state.templateMetaUpdateCount())
 	}
 }
 
-func TestTemplateStorageUpdateAllowsEmptyOptionalFieldsWhenTogglingRunning(t *testing.T) {
+func TestTemplateStorageUpdateCleanupThresholds(t *testing.T) {
 	t.Parallel()
 
-	server, _ := newMockCoderServer(t)
+	server, state := newMockCoderServer(t)
 	defer server.Close()
+	state.setAdvancedSchedulingEntitled(true)
 
 	templateStorage := NewTemplateStorage(newTestClientProvider(t, server.URL))
 	ctx := namespacedContext("control-plane")
@@ -1417,22 +2059,20 @@ func TestTemplateStorageUpdateAllowsEmptyOptionalFieldsWhenTogglingRunning(t *te
 	if err != nil {
 		t.Fatalf("expected template get to succeed: %v", err)
 	}
-
 	currentTemplate, ok := currentObj.(*aggregationv1alpha1.CoderTemplate)
 	if !ok {
 		t.Fatalf("expected *CoderTemplate from get, got %T", currentObj)
 	}
-	if currentTemplate.Spec.DisplayName == "" || currentTemplate.Spec.Description == "" || currentTemplate.Spec.Icon == "" {
-		t.Fatal("expected current template optional fields to be populated")
-	}
+
+	metaUpdateCountBefore := state.templateMetaUpdateCount()
 
 	desiredTemplate := currentTemplate.DeepCopy()
-	desiredTemplate.Spec.Running = !currentTemplate.Spec.Running
-	desiredTemplate.Spec.DisplayName = ""
-	desiredTemplate.Spec.Description = ""
-	desiredTemplate.Spec.Icon = ""
+	dormancyThresholdMillis := int64(7 * 24 * time.Hour / time.Millisecond)
+	autoDeleteThresholdMillis := int64(30 * 24 * time.Hour / time.Millisecond)
+	desiredTemplate.Spec.DormancyThresholdMillis = &dormancyThresholdMillis
+	desiredTemplate.Spec.AutoDeleteThresholdMillis = &autoDeleteThresholdMillis
 
-	updatedObj, created, err := templateStorage.Update(
+	updatedObj, _, err := templateStorage.Update(
 		ctx,
 		desiredTemplate.Name,
 		testUpdatedObjectInfo{obj: desiredTemplate},
@@ -1442,34 +2082,56 @@ func TestTemplateStorageUpdateAllowsEmptyOptionalFieldsWhenTogglingRunning(t *te
 		nil,
 	)
 	if err != nil {
-		t.Fatalf("expected template update to succeed when optional fields are empty: %v", err)
+		t.Fatalf("expected cleanup threshold update to succeed: %v", err)
 	}
-	if created {
-		t.Fatal("expected update created=false")
+	if state.templateMetaUpdateCount() != metaUpdateCountBefore+1 {
+		t.Fatalf("expected one metadata update call, before=%d after=%d", metaUpdateCountBefore, state.templateMetaUpdateCount())
 	}
 
 	updatedTemplate, ok := updatedObj.(*aggregationv1alpha1.CoderTemplate)
 	if !ok {
 		t.Fatalf("expected *CoderTemplate from update, got %T", updatedObj)
 	}
-	if updatedTemplate.Spec.Running != currentTemplate.Spec.Running {
-		t.Fatalf("expected update response running=%t from current backend object, got %t", currentTemplate.Spec.Running, updatedTemplate.Spec.Running)
+	if updatedTemplate.Spec.DormancyThresholdMillis == nil || *updatedTemplate.Spec.DormancyThresholdMillis != dormancyThresholdMillis {
+		t.Fatalf("expected dormancyThresholdMillis %d, got %v", dormancyThresholdMillis, updatedTemplate.Spec.DormancyThresholdMillis)
 	}
-	if updatedTemplate.Spec.DisplayName != desiredTemplate.Spec.DisplayName {
-		t.Fatalf("expected updated spec.displayName %q, got %q", desiredTemplate.Spec.DisplayName, updatedTemplate.Spec.DisplayName)
+	if updatedTemplate.Spec.AutoDeleteThresholdMillis == nil || *updatedTemplate.Spec.AutoDeleteThresholdMillis != autoDeleteThresholdMillis {
+		t.Fatalf("expected autoDeleteThresholdMillis %d, got %v", autoDeleteThresholdMillis, updatedTemplate.Spec.AutoDeleteThresholdMillis)
 	}
-	if updatedTemplate.Spec.Description != desiredTemplate.Spec.Description {
-		t.Fatalf("expected updated spec.description %q, got %q", desiredTemplate.Spec.Description, updatedTemplate.Spec.Description)
+
+	// Omitting the thresholds on a later metadata-only update keeps them.
+	renamedTemplate := updatedTemplate.DeepCopy()
+	renamedTemplate.Spec.DormancyThresholdMillis = nil
+	renamedTemplate.Spec.AutoDeleteThresholdMillis = nil
+	renamedTemplate.Spec.DisplayName = "Renamed Starter Template"
+	renamedObj, _, err := templateStorage.Update(
+		ctx,
+		renamedTemplate.Name,
+		testUpdatedObjectInfo{obj: renamedTemplate},
+		nil,
+		rest.ValidateAllObjectUpdateFunc,
+		false,
+		nil,
+	)
+	if err != nil {
+		t.Fatalf("expected metadata update to succeed: %v", err)
 	}
-	if updatedTemplate.Spec.Icon != desiredTemplate.Spec.Icon {
-		t.Fatalf("expected updated spec.icon %q, got %q", desiredTemplate.Spec.Icon, updatedTemplate.Spec.Icon)
+	renamed, ok := renamedObj.(*aggregationv1alpha1.CoderTemplate)
+	if !ok {
+		t.Fatalf("expected *CoderTemplate from update, got %T", renamedObj)
+	}
+	if renamed.Spec.DormancyThresholdMillis == nil || *renamed.Spec.DormancyThresholdMillis != dormancyThresholdMillis {
+		t.Fatalf("expected dormancyThresholdMillis to be preserved as %d, got %v", dormancyThresholdMillis, renamed.Spec.DormancyThresholdMillis)
+	}
+	if renamed.Spec.AutoDeleteThresholdMillis == nil || *renamed.Spec.AutoDeleteThresholdMillis != autoDeleteThresholdMillis {
+		t.Fatalf("expected autoDeleteThresholdMillis to be preserved as %d, got %v", autoDeleteThresholdMillis, renamed.Spec.AutoDeleteThresholdMillis)
 	}
 }
 
-func TestTemplateStorageUpdateRejectsVersionIDChange(t *testing.T) {
+func TestTemplateStorageUpdateCleanupThresholdsRequiresEntitlement(t *testing.T) {
 	t.Parallel()
 
-	server, _ := newMockCoderServer(t)
+	server, state := newMockCoderServer(t)
 	defer server.Close()
 
 	templateStorage := NewTemplateStorage(newTestClientProvider(t, server.URL))
@@ -1479,20 +2141,18 @@ func TestTemplateStorageUpdateRejectsVersionIDChange(t *testing.T) {
 	if err != nil {
 		t.Fatalf("expected template get to succeed: %v", err)
 	}
-
 	currentTemplate, ok := currentObj.(*aggregationv1alpha1.CoderTemplate)
 	if !ok {
 		t.Fatalf("expected *CoderTemplate from get, got %T", currentObj)
 	}
 
+	metaUpdateCountBefore := state.templateMetaUpdateCount()
+
 	desiredTemplate := currentTemplate.DeepCopy()
-	desiredTemplate.Spec.Running = !currentTemplate.Spec.Running
-	desiredTemplate.Spec.VersionID = uuid.New().String()
-	if desiredTemplate.Spec.VersionID == currentTemplate.Spec.VersionID {
-		t.Fatal("expected test fixture to use a different spec.versionID")
-	}
+	dormancyThresholdMillis := int64(24 * time.Hour / time.Millisecond)
+	desiredTemplate.Spec.DormancyThresholdMillis = &dormancyThresholdMillis
 
-	_, created, err := templateStorage.Update(
+	_, _, err = templateStorage.Update(
 		ctx,
 		desiredTemplate.Name,
 		testUpdatedObjectInfo{obj: desiredTemplate},
@@ -1501,22 +2161,26 @@ func TestTemplateStorageUpdateRejectsVersionIDChange(t *testing.T) {
 		false,
 		nil,
 	)
-	if !apierrors.IsBadRequest(err) {
-		t.Fatalf("expected BadRequest when changing spec.versionID, got %v", err)
+	if err == nil {
+		t.Fatal("expected cleanup threshold update to fail without entitlement")
 	}
-	if err == nil || !strings.Contains(err.Error(), "spec.versionID is read-only") {
-		t.Fatalf("expected read-only spec.versionID error, got %v", err)
+	if !apierrors.IsForbidden(err) {
+		t.Fatalf("expected forbidden error, got %v", err)
 	}
-	if created {
-		t.Fatal("expected update created=false")
+	if !strings.Contains(err.Error(), string(codersdk.FeatureAdvancedTemplateScheduling)) {
+		t.Fatalf("expected error to name the missing entitlement, got %v", err)
+	}
+	if state.templateMetaUpdateCount() != metaUpdateCountBefore {
+		t.Fatalf("expected no metadata update calls, before=%d after=%d", metaUpdateCountBefore, state.templateMetaUpdateCount())
 	}
 }
 
-func TestTemplateStorageUpdateAllowsMetadataSpecChanges(t *testing.T) {
+func TestTemplateStorageUpdateRejectsAutoDeleteWithoutDormancy(t *testing.T) {
 	t.Parallel()
 
-	server, _ := newMockCoderServer(t)
+	server, state := newMockCoderServer(t)
 	defer server.Close()
+	state.setAdvancedSchedulingEntitled(true)
 
 	templateStorage := NewTemplateStorage(newTestClientProvider(t, server.URL))
 	ctx := namespacedContext("control-plane")
@@ -1525,16 +2189,16 @@ func TestTemplateStorageUpdateAllowsMetadataSpecChanges(t *testing.T) {
 	if err != nil {
 		t.Fatalf("expected template get to succeed: %v", err)
 	}
-
 	currentTemplate, ok := currentObj.(*aggregationv1alpha1.CoderTemplate)
 	if !ok {
 		t.Fatalf("expected *CoderTemplate from get, got %T", currentObj)
 	}
 
 	desiredTemplate := currentTemplate.DeepCopy()
-	desiredTemplate.Spec.DisplayName = "Renamed Template"
+	autoDeleteThresholdMillis := int64(24 * time.Hour / time.Millisecond)
+	desiredTemplate.Spec.AutoDeleteThresholdMillis = &autoDeleteThresholdMillis
 
-	updatedObj, created, err := templateStorage.Update(
+	_, _, err = templateStorage.Update(
 		ctx,
 		desiredTemplate.Name,
 		testUpdatedObjectInfo{obj: desiredTemplate},
@@ -1543,78 +2207,120 @@ func TestTemplateStorageUpdateAllowsMetadataSpecChanges(t *testing.T) {
 		false,
 		nil,
 	)
-	if err != nil {
-		t.Fatalf("expected update to allow metadata spec changes: %v", err)
-	}
-	if created {
-		t.Fatal("expected update created=false")
-	}
-
-	updatedTemplate, ok := updatedObj.(*aggregationv1alpha1.CoderTemplate)
-	if !ok {
-		t.Fatalf("expected *CoderTemplate from update, got %T", updatedObj)
+	if err == nil {
+		t.Fatal("expected auto-delete without dormancy to fail")
 	}
-	if updatedTemplate.Spec.DisplayName != desiredTemplate.Spec.DisplayName {
-		t.Fatalf("expected updated displayName %q, got %q", desiredTemplate.Spec.DisplayName, updatedTemplate.Spec.DisplayName)
+	if !apierrors.IsBadRequest(err) {
+		t.Fatalf("expected bad request error, got %v", err)
 	}
 }
 
-func TestTemplateStorageUpdateForceAllowCreateCreatesWhenMissing(t *testing.T) {
+func TestTemplateStorageACLRoundTrip(t *testing.T) {
 	t.Parallel()
 
 	server, state := newMockCoderServer(t)
 	defer server.Close()
+	state.setTemplateRBACEntitled(true)
 
 	templateStorage := NewTemplateStorage(newTestClientProvider(t, server.URL))
 	ctx := namespacedContext("control-plane")
 
-	desiredTemplate := &aggregationv1alpha1.CoderTemplate{
-		Spec: aggregationv1alpha1.CoderTemplateSpec{
-			Organization: "acme",
-			DisplayName:  "SSA-created template",
-			Description:  "Created via forceAllowCreate update hack",
-			Icon:         "/icons/ssa-template.png",
-			Files: map[string]string{
-				"main.tf": "resource \"null_resource\" \"ssa_create\" {}",
-			},
+	currentObj, err := templateStorage.Get(ctx, "acme.starter-template", nil)
+	if err != nil {
+		t.Fatalf("expected template get to succeed: %v", err)
+	}
+	currentTemplate, ok := currentObj.(*aggregationv1alpha1.CoderTemplate)
+	if !ok {
+		t.Fatalf("expected *CoderTemplate from get, got %T", currentObj)
+	}
+	expectedSeededACL := &aggregationv1alpha1.CoderTemplateACL{
+		Groups: []aggregationv1alpha1.CoderTemplateACLEntry{{Name: "Everyone", Role: aggregationv1alpha1.CoderTemplateRoleUse}},
+	}
+	if !reflect.DeepEqual(currentTemplate.Spec.ACL, expectedSeededACL) {
+		t.Fatalf("expected seeded ACL %+v, got %+v", expectedSeededACL, currentTemplate.Spec.ACL)
+	}
+
+	desiredTemplate := currentTemplate.DeepCopy()
+	desiredTemplate.Spec.ACL = &aggregationv1alpha1.CoderTemplateACL{
+		Users: []aggregationv1alpha1.CoderTemplateACLEntry{
+			{Name: "bob", Role: aggregationv1alpha1.CoderTemplateRoleUse},
+			{Name: "alice", Role: aggregationv1alpha1.CoderTemplateRoleAdmin},
 		},
+		Groups: []aggregationv1alpha1.CoderTemplateACLEntry{{Name: "platform", Role: aggregationv1alpha1.CoderTemplateRoleUse}},
 	}
 
-	updatedObj, created, err := templateStorage.Update(
+	updatedObj, _, err := templateStorage.Update(
 		ctx,
-		"acme.ssa-template",
+		desiredTemplate.Name,
 		testUpdatedObjectInfo{obj: desiredTemplate},
-		rest.ValidateAllObjectFunc,
+		nil,
 		rest.ValidateAllObjectUpdateFunc,
-		true,
+		false,
 		nil,
 	)
 	if err != nil {
-		t.Fatalf("expected create-on-update to succeed for missing template: %v", err)
-	}
-	if !created {
-		t.Fatal("expected update created=true when forceAllowCreate creates a template")
+		t.Fatalf("expected ACL update to succeed: %v", err)
 	}
-
-	createdTemplate, ok := updatedObj.(*aggregationv1alpha1.CoderTemplate)
+	updatedTemplate, ok := updatedObj.(*aggregationv1alpha1.CoderTemplate)
 	if !ok {
 		t.Fatalf("expected *CoderTemplate from update, got %T", updatedObj)
 	}
-	if createdTemplate.Name != "acme.ssa-template" {
-		t.Fatalf("expected created template name acme.ssa-template, got %q", createdTemplate.Name)
+	expectedACL := &aggregationv1alpha1.CoderTemplateACL{
+		Users: []aggregationv1alpha1.CoderTemplateACLEntry{
+			{Name: "alice", Role: aggregationv1alpha1.CoderTemplateRoleAdmin},
+			{Name: "bob", Role: aggregationv1alpha1.CoderTemplateRoleUse},
+		},
+		Groups: []aggregationv1alpha1.CoderTemplateACLEntry{{Name: "platform", Role: aggregationv1alpha1.CoderTemplateRoleUse}},
 	}
-	if createdTemplate.Spec.Organization != "acme" {
-		t.Fatalf("expected created template organization acme, got %q", createdTemplate.Spec.Organization)
+	if !reflect.DeepEqual(updatedTemplate.Spec.ACL, expectedACL) {
+		t.Fatalf("expected ACL %+v with the Everyone group removed, got %+v", expectedACL, updatedTemplate.Spec.ACL)
 	}
-	if !state.hasTemplate("acme", "ssa-template") {
-		t.Fatal("expected create-on-update template to be persisted in mock server state")
+
+	// Re-applying the same ACL in a different order is a no-op.
+	patchCountBefore := state.templateACLUpdateCount()
+	reordered := updatedTemplate.DeepCopy()
+	reordered.Spec.ACL.Users[0], reordered.Spec.ACL.Users[1] = reordered.Spec.ACL.Users[1], reordered.Spec.ACL.Users[0]
+	if _, _, err := templateStorage.Update(
+		ctx,
+		reordered.Name,
+		testUpdatedObjectInfo{obj: reordered},
+		nil,
+		rest.ValidateAllObjectUpdateFunc,
+		false,
+		nil,
+	); err != nil {
+		t.Fatalf("expected unchanged ACL update to succeed: %v", err)
+	}
+	if state.templateACLUpdateCount() != patchCountBefore {
+		t.Fatalf("expected no ACL update calls for an unchanged ACL, before=%d after=%d", patchCountBefore, state.templateACLUpdateCount())
+	}
+
+	unknownUser := updatedTemplate.DeepCopy()
+	unknownUser.Spec.ACL.Users = append(unknownUser.Spec.ACL.Users, aggregationv1alpha1.CoderTemplateACLEntry{
+		Name: "mallory",
+		Role: aggregationv1alpha1.CoderTemplateRoleUse,
+	})
+	_, _, err = templateStorage.Update(
+		ctx,
+		unknownUser.Name,
+		testUpdatedObjectInfo{obj: unknownUser},
+		nil,
+		rest.ValidateAllObjectUpdateFunc,
+		false,
+		nil,
+	)
+	if !apierrors.IsBadRequest(err) || !strings.Contains(err.Error(), "mallory") {
+		t.Fatalf("expected bad request naming the unknown user, got %v", err)
+	}
+	if state.templateACLUpdateCount() != patchCountBefore {
+		t.Fatalf("expected no ACL update calls for an unknown user, before=%d after=%d", patchCountBefore, state.templateACLUpdateCount())
 	}
 }
 
-func TestTemplateStorageUpdateRejectsMissingResourceVersion(t *testing.T) {
+func TestTemplateStorageACLSkippedWithoutEntitlement(t *testing.T) {
 	t.Parallel()
 
-	server, _ := newMockCoderServer(t)
+	server, state := newMockCoderServer(t)
 	defer server.Close()
 
 	templateStorage := NewTemplateStorage(newTestClientProvider(t, server.URL))
@@ -1622,23 +2328,25 @@ func TestTemplateStorageUpdateRejectsMissingResourceVersion(t *testing.T) {
 
 	currentObj, err := templateStorage.Get(ctx, "acme.starter-template", nil)
 	if err != nil {
-		t.Fatalf("expected template get to succeed: %v", err)
+		t.Fatalf("expected template get to succeed without template ACL support: %v", err)
 	}
-
 	currentTemplate, ok := currentObj.(*aggregationv1alpha1.CoderTemplate)
 	if !ok {
 		t.Fatalf("expected *CoderTemplate from get, got %T", currentObj)
 	}
-	if currentTemplate.ResourceVersion == "" {
-		t.Fatal("expected current template resourceVersion to be populated")
+	if currentTemplate.Spec.ACL != nil {
+		t.Fatalf("expected no ACL without the template_rbac entitlement, got %+v", currentTemplate.Spec.ACL)
 	}
 
 	desiredTemplate := currentTemplate.DeepCopy()
-	desiredTemplate.Spec.Running = !currentTemplate.Spec.Running
-	desiredTemplate.ResourceVersion = ""
+	desiredTemplate.Spec.DisplayName = "Renamed Starter Template"
+	desiredTemplate.Spec.ACL = &aggregationv1alpha1.CoderTemplateACL{
+		Users: []aggregationv1alpha1.CoderTemplateACLEntry{{Name: "alice", Role: aggregationv1alpha1.CoderTemplateRoleAdmin}},
+	}
 
-	_, _, err = templateStorage.Update(
-		ctx,
+	recorder := &recordingWarningRecorder{}
+	updatedObj, _, err := templateStorage.Update(
+		warning.WithWarningRecorder(ctx, recorder),
 		desiredTemplate.Name,
 		testUpdatedObjectInfo{obj: desiredTemplate},
 		nil,
@@ -1646,135 +2354,2323 @@ func TestTemplateStorageUpdateRejectsMissingResourceVersion(t *testing.T) {
 		false,
 		nil,
 	)
-	if !apierrors.IsBadRequest(err) {
-		t.Fatalf("expected BadRequest for missing resourceVersion, got %v", err)
+	if err != nil {
+		t.Fatalf("expected update to succeed while skipping spec.acl: %v", err)
 	}
-	if err == nil || !strings.Contains(err.Error(), "metadata.resourceVersion is required for update") {
-		t.Fatalf("expected missing resourceVersion error message, got %v", err)
+	updatedTemplate, ok := updatedObj.(*aggregationv1alpha1.CoderTemplate)
+	if !ok {
+		t.Fatalf("expected *CoderTemplate from update, got %T", updatedObj)
+	}
+	if updatedTemplate.Spec.DisplayName != "Renamed Starter Template" {
+		t.Fatalf("expected display name update to apply, got %q", updatedTemplate.Spec.DisplayName)
+	}
+	if state.templateACLUpdateCount() != 0 {
+		t.Fatalf("expected no ACL update calls, got %d", state.templateACLUpdateCount())
+	}
+	warnings := recorder.snapshot()
+	if len(warnings) != 1 || !strings.Contains(warnings[0], string(codersdk.FeatureTemplateRBAC)) {
+		t.Fatalf("expected one warning naming the template_rbac entitlement, got %v", warnings)
 	}
 }
 
-func TestTemplateStorageUpdateRejectsStaleResourceVersion(t *testing.T) {
+func TestTemplateStorageListAllowsAllNamespacesRequest(t *testing.T) {
 	t.Parallel()
 
 	server, _ := newMockCoderServer(t)
 	defer server.Close()
 
 	templateStorage := NewTemplateStorage(newTestClientProvider(t, server.URL))
+
+	listObj, err := templateStorage.List(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("expected all-namespaces list to succeed, got %v", err)
+	}
+	list, ok := listObj.(*aggregationv1alpha1.CoderTemplateList)
+	if !ok {
+		t.Fatalf("expected *CoderTemplateList, got %T", listObj)
+	}
+	if len(list.Items) == 0 {
+		t.Fatal("expected at least one template in list")
+	}
+}
+
+func TestTemplateStorageListAggregatesAcrossNamespaces(t *testing.T) {
+	t.Parallel()
+
+	serverA, _ := newMockCoderServer(t)
+	defer serverA.Close()
+	serverB, _ := newMockCoderServer(t)
+	defer serverB.Close()
+
+	provider := &multiNamespaceTestProvider{
+		clients: map[string]*codersdk.Client{
+			"ns-a": newTestSDKClient(t, serverA.URL),
+			"ns-b": newTestSDKClient(t, serverB.URL),
+		},
+		namespaces: []string{"ns-a", "ns-b"},
+	}
+
+	templateStorage := NewTemplateStorage(provider)
+
+	listObj, err := templateStorage.List(namespacedContext(""), nil)
+	if err != nil {
+		t.Fatalf("expected all-namespaces list to aggregate successfully, got %v", err)
+	}
+
+	list, ok := listObj.(*aggregationv1alpha1.CoderTemplateList)
+	if !ok {
+		t.Fatalf("expected *CoderTemplateList, got %T", listObj)
+	}
+	if len(list.Items) != 2 {
+		t.Fatalf("expected two templates in aggregated list, got %d", len(list.Items))
+	}
+	if list.Items[0].Namespace != "ns-a" || list.Items[1].Namespace != "ns-b" {
+		t.Fatalf(
+			"expected namespaces [ns-a ns-b], got [%s %s]",
+			list.Items[0].Namespace,
+			list.Items[1].Namespace,
+		)
+	}
+	if !sort.SliceIsSorted(list.Items, func(i, j int) bool {
+		if list.Items[i].Namespace != list.Items[j].Namespace {
+			return list.Items[i].Namespace < list.Items[j].Namespace
+		}
+		return list.Items[i].Name < list.Items[j].Name
+	}) {
+		t.Fatal("expected template list items to be sorted by namespace then name")
+	}
+}
+
+func TestTemplateStorageListBestEffortSkipsFailingNamespace(t *testing.T) {
+	t.Parallel()
+
+	healthy, _ := newMockCoderServer(t)
+	defer healthy.Close()
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		writeCoderError(w, http.StatusInternalServerError, "database is down")
+	}))
+	defer failing.Close()
+
+	provider := &multiNamespaceTestProvider{
+		clients: map[string]*codersdk.Client{
+			"ns-a": newTestSDKClient(t, healthy.URL),
+			"ns-b": newTestSDKClient(t, failing.URL),
+		},
+		namespaces: []string{"ns-a", "ns-b"},
+	}
+
+	templateStorage := NewTemplateStorage(provider)
+	templateStorage.SetListFanOutMode(ListFanOutModeBestEffort)
+	recorder := &recordingWarningRecorder{}
+	listObj, err := templateStorage.List(warning.WithWarningRecorder(namespacedContext(""), recorder), nil)
+	if err != nil {
+		t.Fatalf("expected best-effort list to succeed, got %v", err)
+	}
+	list, ok := listObj.(*aggregationv1alpha1.CoderTemplateList)
+	if !ok {
+		t.Fatalf("expected *CoderTemplateList, got %T", listObj)
+	}
+	if len(list.Items) != 1 || list.Items[0].Namespace != "ns-a" {
+		t.Fatalf("expected only the healthy ns-a template, got %+v", list.Items)
+	}
+	warnings := recorder.snapshot()
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "ns-b") {
+		t.Fatalf("expected one warning naming ns-b, got %v", warnings)
+	}
+}
+
+func TestTemplateStorageListStrictByDefaultFailsOnFailingNamespace(t *testing.T) {
+	t.Parallel()
+
+	healthy, _ := newMockCoderServer(t)
+	defer healthy.Close()
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		writeCoderError(w, http.StatusInternalServerError, "database is down")
+	}))
+	defer failing.Close()
+
+	provider := &multiNamespaceTestProvider{
+		clients: map[string]*codersdk.Client{
+			"ns-a": newTestSDKClient(t, healthy.URL),
+			"ns-b": newTestSDKClient(t, failing.URL),
+		},
+		namespaces: []string{"ns-a", "ns-b"},
+	}
+
+	_, err := NewTemplateStorage(provider).List(namespacedContext(""), nil)
+	if !apierrors.IsInternalError(err) {
+		t.Fatalf("expected strict list to fail with InternalError, got %v", err)
+	}
+	assertTopLevelStatusError(t, err)
+}
+
+func TestParseListFanOutMode(t *testing.T) {
+	t.Parallel()
+
+	for value, want := range map[string]ListFanOutMode{
+		"":              ListFanOutModeStrict,
+		"strict":        ListFanOutModeStrict,
+		" best-effort ": ListFanOutModeBestEffort,
+	} {
+		got, err := ParseListFanOutMode(value)
+		if err != nil || got != want {
+			t.Fatalf("ParseListFanOutMode(%q) = %q, %v; want %q", value, got, err, want)
+		}
+	}
+	if _, err := ParseListFanOutMode("lenient"); err == nil {
+		t.Fatal("expected an unknown list fan-out mode to be rejected")
+	}
+}
+
+func TestTemplateStorageListNamespacedRequestBypassesFanOut(t *testing.T) {
+	t.Parallel()
+
+	serverA, _ := newMockCoderServer(t)
+	defer serverA.Close()
+	serverB, _ := newMockCoderServer(t)
+	defer serverB.Close()
+
+	provider := &multiNamespaceTestProvider{
+		clients: map[string]*codersdk.Client{
+			"ns-a": newTestSDKClient(t, serverA.URL),
+			"ns-b": newTestSDKClient(t, serverB.URL),
+		},
+		namespaces: []string{"ns-a", "ns-b"},
+	}
+
+	templateStorage := NewTemplateStorage(provider)
+
+	listObj, err := templateStorage.List(namespacedContext("ns-a"), nil)
+	if err != nil {
+		t.Fatalf("expected namespaced list to succeed, got %v", err)
+	}
+
+	list, ok := listObj.(*aggregationv1alpha1.CoderTemplateList)
+	if !ok {
+		t.Fatalf("expected *CoderTemplateList, got %T", listObj)
+	}
+	if len(list.Items) != 1 {
+		t.Fatalf("expected one template from namespaced list, got %d", len(list.Items))
+	}
+	if list.Items[0].Namespace != "ns-a" {
+		t.Fatalf("expected namespaced list item to use ns-a, got %q", list.Items[0].Namespace)
+	}
+}
+
+func TestStoragesListFilterOrganizations(t *testing.T) {
+	t.Parallel()
+
+	server, state := newMockCoderServer(t)
+	defer server.Close()
+	state.addOrganizationResources("globex")
+
+	listNames := func(provider coder.ClientProvider, ctx context.Context) ([]string, []string) {
+		t.Helper()
+
+		templatesObj, err := NewTemplateStorage(provider).List(ctx, nil)
+		if err != nil {
+			t.Fatalf("list templates: %v", err)
+		}
+		workspacesObj, err := NewWorkspaceStorage(provider).List(ctx, nil)
+		if err != nil {
+			t.Fatalf("list workspaces: %v", err)
+		}
+
+		var templateNames, workspaceNames []string
+		for _, item := range templatesObj.(*aggregationv1alpha1.CoderTemplateList).Items {
+			templateNames = append(templateNames, item.Name)
+		}
+		for _, item := range workspacesObj.(*aggregationv1alpha1.CoderWorkspaceList).Items {
+			workspaceNames = append(workspaceNames, item.Name)
+		}
+		sort.Strings(templateNames)
+		sort.Strings(workspaceNames)
+
+		return templateNames, workspaceNames
+	}
+
+	unfiltered := &coder.StaticClientProvider{Client: newTestSDKClient(t, server.URL), Namespace: "control-plane"}
+	templateNames, workspaceNames := listNames(unfiltered, namespacedContext("control-plane"))
+	if got, want := strings.Join(templateNames, ","), "acme.starter-template,globex.other-template"; got != want {
+		t.Fatalf("expected unfiltered templates %q, got %q", want, got)
+	}
+	if got, want := strings.Join(workspaceNames, ","), "acme.alice.dev-workspace,globex.alice.other-workspace"; got != want {
+		t.Fatalf("expected unfiltered workspaces %q, got %q", want, got)
+	}
+
+	filtered := &coder.StaticClientProvider{
+		Client:        newTestSDKClient(t, server.URL),
+		Namespace:     "control-plane",
+		Organizations: []string{"globex"},
+	}
+	for _, ctx := range []context.Context{namespacedContext("control-plane"), namespacedContext("")} {
+		templateNames, workspaceNames = listNames(filtered, ctx)
+		if got, want := strings.Join(templateNames, ","), "globex.other-template"; got != want {
+			t.Fatalf("expected filtered templates %q, got %q", want, got)
+		}
+		if got, want := strings.Join(workspaceNames, ","), "globex.alice.other-workspace"; got != want {
+			t.Fatalf("expected filtered workspaces %q, got %q", want, got)
+		}
+	}
+}
+
+func TestTemplateStorageListPreservesProviderStatusErrors(t *testing.T) {
+	t.Parallel()
+
+	server, _ := newMockCoderServer(t)
+	defer server.Close()
+
+	parsedURL, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatalf("parse mock server URL %q: %v", server.URL, err)
+	}
+	client := codersdk.New(parsedURL)
+	client.SetSessionToken("test-session-token")
+
+	templateStorage := NewTemplateStorage(&coder.StaticClientProvider{
+		Client:    client,
+		Namespace: "control-plane",
+	})
+
+	_, err = templateStorage.List(namespacedContext("other-namespace"), nil)
+	if !apierrors.IsBadRequest(err) {
+		t.Fatalf("expected BadRequest from provider namespace restriction, got %v", err)
+	}
+	assertTopLevelStatusError(t, err)
+}
+
+func TestWrapClientErrorReturnsTopLevelStatusError(t *testing.T) {
+	t.Parallel()
+
+	statusErr := apierrors.NewBadRequest("provider namespace mismatch")
+	wrappedErr := fmt.Errorf("resolve codersdk client for namespace %q: %w", "control-plane", statusErr)
+
+	wrappedClientErr := wrapClientError(wrappedErr)
+	if !apierrors.IsBadRequest(wrappedClientErr) {
+		t.Fatalf("expected BadRequest from wrapped status error, got %v", wrappedClientErr)
+	}
+
+	assertTopLevelStatusError(t, wrappedClientErr)
+
+	var unwrappedStatusErr *apierrors.StatusError
+	if !errors.As(wrappedClientErr, &unwrappedStatusErr) {
+		t.Fatalf("expected *apierrors.StatusError in wrapped client error chain, got %T", wrappedClientErr)
+	}
+	if unwrappedStatusErr != statusErr {
+		t.Fatalf("expected wrapClientError to return original status error pointer")
+	}
+}
+
+func TestStoragesMapCoderRateLimitToTooManyRequests(t *testing.T) {
+	t.Parallel()
+
+	server, _ := newMockCoderServer(t)
+	defer server.Close()
+
+	limited := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/api/v2/organizations/") {
+			w.Header().Set("Retry-After", "7")
+			writeCoderError(w, http.StatusTooManyRequests, "admin token rate limited")
+			return
+		}
+		server.Config.Handler.ServeHTTP(w, r)
+	}))
+	defer limited.Close()
+
+	templateStorage := NewTemplateStorage(newTestClientProvider(t, limited.URL))
+	defer templateStorage.Destroy()
+
+	_, err := templateStorage.Get(namespacedContext("control-plane"), "acme.starter-template", nil)
+	if !apierrors.IsTooManyRequests(err) {
+		t.Fatalf("expected TooManyRequests, got %v", err)
+	}
+	assertTopLevelStatusError(t, err)
+	if delay, ok := apierrors.SuggestsClientDelay(err); !ok || delay != 7 {
+		t.Fatalf("expected retry-after of 7 seconds, got %d (ok=%t)", delay, ok)
+	}
+	if !strings.Contains(err.Error(), "admin token rate limited") {
+		t.Fatalf("expected Coder message in error, got %v", err)
+	}
+}
+
+func TestWrapClientErrorMapsCoderRateLimit(t *testing.T) {
+	t.Parallel()
+
+	rateLimitErr := fmt.Errorf("resolve codersdk client: %w", &coder.RateLimitedError{Message: "slow down", RetryAfter: 3 * time.Second})
+	wrappedErr := wrapClientError(rateLimitErr)
+	if !apierrors.IsTooManyRequests(wrappedErr) {
+		t.Fatalf("expected TooManyRequests, got %v", wrappedErr)
+	}
+	if delay, ok := apierrors.SuggestsClientDelay(wrappedErr); !ok || delay != 3 {
+		t.Fatalf("expected retry-after of 3 seconds, got %d (ok=%t)", delay, ok)
+	}
+
+	sdkErr := codersdk.NewTestError(http.StatusTooManyRequests, http.MethodGet, "https://coder.example.com")
+	if wrappedErr := wrapClientError(fmt.Errorf("resolve codersdk client: %w", sdkErr)); !apierrors.IsTooManyRequests(wrappedErr) {
+		t.Fatalf("expected TooManyRequests for codersdk 429, got %v", wrappedErr)
+	}
+}
+
+func TestTemplateStorageUpdateReturnsCurrentBackendObjectForLegacyRunningField(t *testing.T) {
+	t.Parallel()
+
+	server, _ := newMockCoderServer(t)
+	defer server.Close()
+
+	templateStorage := NewTemplateStorage(newTestClientProvider(t, server.URL))
+	ctx := namespacedContext("control-plane")
+
+	currentObj, err := templateStorage.Get(ctx, "acme.starter-template", nil)
+	if err != nil {
+		t.Fatalf("expected template get to succeed: %v", err)
+	}
+
+	currentTemplate, ok := currentObj.(*aggregationv1alpha1.CoderTemplate)
+	if !ok {
+		t.Fatalf("expected *CoderTemplate from get, got %T", currentObj)
+	}
+	if currentTemplate.ResourceVersion == "" {
+		t.Fatal("expected current template resourceVersion to be populated")
+	}
+
+	desiredTemplate := currentTemplate.DeepCopy()
+	desiredTemplate.Spec.Running = !currentTemplate.Spec.Running
+
+	updatedObj, created, err := templateStorage.Update(
+		ctx,
+		desiredTemplate.Name,
+		testUpdatedObjectInfo{obj: desiredTemplate},
+		nil,
+		rest.ValidateAllObjectUpdateFunc,
+		false,
+		nil,
+	)
+	if err != nil {
+		t.Fatalf("expected template update to succeed: %v", err)
+	}
+	if created {
+		t.Fatal("expected update created=false")
+	}
+
+	updatedTemplate, ok := updatedObj.(*aggregationv1alpha1.CoderTemplate)
+	if !ok {
+		t.Fatalf("expected *CoderTemplate from update, got %T", updatedObj)
+	}
+	if updatedTemplate.Spec.Running != currentTemplate.Spec.Running {
+		t.Fatalf("expected update response running=%t from current backend object, got %t", currentTemplate.Spec.Running, updatedTemplate.Spec.Running)
+	}
+	if updatedTemplate.Name != currentTemplate.Name {
+		t.Fatalf("expected updated name %q, got %q", currentTemplate.Name, updatedTemplate.Name)
+	}
+}
+
+func TestTemplateStorageUpdateAllowsUnchangedVersionIDWhenTogglingRunning(t *testing.T) {
+	t.Parallel()
+
+	server, _ := newMockCoderServer(t)
+	defer server.Close()
+
+	templateStorage := NewTemplateStorage(newTestClientProvider(t, server.URL))
+	ctx := namespacedContext("control-plane")
+
+	currentObj, err := templateStorage.Get(ctx, "acme.starter-template", nil)
+	if err != nil {
+		t.Fatalf("expected template get to succeed: %v", err)
+	}
+
+	currentTemplate, ok := currentObj.(*aggregationv1alpha1.CoderTemplate)
+	if !ok {
+		t.Fatalf("expected *CoderTemplate from get, got %T", currentObj)
+	}
+	if currentTemplate.Spec.VersionID == "" {
+		t.Fatal("expected current template spec.versionID to be populated")
+	}
+
+	desiredTemplate := currentTemplate.DeepCopy()
+	desiredTemplate.Spec.Running = !currentTemplate.Spec.Running
+
+	updatedObj, created, err := templateStorage.Update(
+		ctx,
+		desiredTemplate.Name,
+		testUpdatedObjectInfo{obj: desiredTemplate},
+		nil,
+		rest.ValidateAllObjectUpdateFunc,
+		false,
+		nil,
+	)
+	if err != nil {
+		t.Fatalf("expected template update to succeed when spec.versionID is unchanged: %v", err)
+	}
+	if created {
+		t.Fatal("expected update created=false")
+	}
+
+	updatedTemplate, ok := updatedObj.(*aggregationv1alpha1.CoderTemplate)
+	if !ok {
+		t.Fatalf("expected *CoderTemplate from update, got %T", updatedObj)
+	}
+	if updatedTemplate.Spec.Running != currentTemplate.Spec.Running {
+		t.Fatalf("expected update response running=%t from current backend object, got %t", currentTemplate.Spec.Running, updatedTemplate.Spec.Running)
+	}
+	if updatedTemplate.Spec.VersionID != currentTemplate.Spec.VersionID {
+		t.Fatalf("expected update response spec.versionID %q from current backend object, got %q", currentTemplate.Spec.VersionID, updatedTemplate.Spec.VersionID)
+	}
+}
+
+func TestTemplateStorageUpdateAllowsEmptyVersionIDWhenTogglingRunning(t *testing.T) {
+	t.Parallel()
+
+	server, _ := newMockCoderServer(t)
+	defer server.Close()
+
+	templateStorage := NewTemplateStorage(newTestClientProvider(t, server.URL))
+	ctx := namespacedContext("control-plane")
+
+	currentObj, err := templateStorage.Get(ctx, "acme.starter-template", nil)
+	if err != nil {
+		t.Fatalf("expected template get to succeed: %v", err)
+	}
+
+	currentTemplate, ok := currentObj.(*aggregationv1alpha1.CoderTemplate)
+	if !ok {
+		t.Fatalf("expected *CoderTemplate from get, got %T", currentObj)
+	}
+	if currentTemplate.Spec.VersionID == "" {
+		t.Fatal("expected current template spec.versionID to be populated")
+	}
+
+	desiredTemplate := currentTemplate.DeepCopy()
+	desiredTemplate.Spec.VersionID = ""
+	desiredTemplate.Spec.Running = !currentTemplate.Spec.Running
+
+	updatedObj, created, err := templateStorage.Update(
+		ctx,
+		desiredTemplate.Name,
+		testUpdatedObjectInfo{obj: desiredTemplate},
+		nil,
+		rest.ValidateAllObjectUpdateFunc,
+		false,
+		nil,
+	)
+	if err != nil {
+		t.Fatalf("expected template update to succeed when desired spec.versionID is empty: %v", err)
+	}
+	if created {
+		t.Fatal("expected update created=false")
+	}
+
+	updatedTemplate, ok := updatedObj.(*aggregationv1alpha1.CoderTemplate)
+	if !ok {
+		t.Fatalf("expected *CoderTemplate from update, got %T", updatedObj)
+	}
+	if updatedTemplate.Spec.Running != currentTemplate.Spec.Running {
+		t.Fatalf("expected update response running=%t from current backend object, got %t", currentTemplate.Spec.Running, updatedTemplate.Spec.Running)
+	}
+	if updatedTemplate.Spec.VersionID != currentTemplate.Spec.VersionID {
+		t.Fatalf("expected update response spec.versionID %q from current backend object, got %q", currentTemplate.Spec.VersionID, updatedTemplate.Spec.VersionID)
+	}
+}
+
+func TestTemplateStorageUpdateAllowsEmptyOptionalFieldsWhenTogglingRunning(t *testing.T) {
+	t.Parallel()
+
+	server, _ := newMockCoderServer(t)
+	defer server.Close()
+
+	templateStorage := NewTemplateStorage(newTestClientProvider(t, server.URL))
+	ctx := namespacedContext("control-plane")
+
+	currentObj, err := templateStorage.Get(ctx, "acme.starter-template", nil)
+	if err != nil {
+		t.Fatalf("expected template get to succeed: %v", err)
+	}
+
+	currentTemplate, ok := currentObj.(*aggregationv1alpha1.CoderTemplate)
+	if !ok {
+		t.Fatalf("expected *CoderTemplate from get, got %T", currentObj)
+	}
+	if currentTemplate.Spec.DisplayName == "" || currentTemplate.Spec.Description == "" || currentTemplate.Spec.Icon == "" {
+		t.Fatal("expected current template optional fields to be populated")
+	}
+
+	desiredTemplate := currentTemplate.DeepCopy()
+	desiredTemplate.Spec.Running = !currentTemplate.Spec.Running
+	desiredTemplate.Spec.DisplayName = ""
+	desiredTemplate.Spec.Description = ""
+	desiredTemplate.Spec.Icon = ""
+
+	updatedObj, created, err := templateStorage.Update(
+		ctx,
+		desiredTemplate.Name,
+		testUpdatedObjectInfo{obj: desiredTemplate},
+		nil,
+		rest.ValidateAllObjectUpdateFunc,
+		false,
+		nil,
+	)
+	if err != nil {
+		t.Fatalf("expected template update to succeed when optional fields are empty: %v", err)
+	}
+	if created {
+		t.Fatal("expected update created=false")
+	}
+
+	updatedTemplate, ok := updatedObj.(*aggregationv1alpha1.CoderTemplate)
+	if !ok {
+		t.Fatalf("expected *CoderTemplate from update, got %T", updatedObj)
+	}
+	if updatedTemplate.Spec.Running != currentTemplate.Spec.Running {
+		t.Fatalf("expected update response running=%t from current backend object, got %t", currentTemplate.Spec.Running, updatedTemplate.Spec.Running)
+	}
+	if updatedTemplate.Spec.DisplayName != desiredTemplate.Spec.DisplayName {
+		t.Fatalf("expected updated spec.displayName %q, got %q", desiredTemplate.Spec.DisplayName, updatedTemplate.Spec.DisplayName)
+	}
+	if updatedTemplate.Spec.Description != desiredTemplate.Spec.Description {
+		t.Fatalf("expected updated spec.description %q, got %q", desiredTemplate.Spec.Description, updatedTemplate.Spec.Description)
+	}
+	if updatedTemplate.Spec.Icon != desiredTemplate.Spec.Icon {
+		t.Fatalf("expected updated spec.icon %q, got %q", desiredTemplate.Spec.Icon, updatedTemplate.Spec.Icon)
+	}
+}
+
+func TestTemplateStorageUpdateRejectsUnknownVersionID(t *testing.T) {
+	t.Parallel()
+
+	server, _ := newMockCoderServer(t)
+	defer server.Close()
+
+	templateStorage := NewTemplateStorage(newTestClientProvider(t, server.URL))
+	ctx := namespacedContext("control-plane")
+
+	currentObj, err := templateStorage.Get(ctx, "acme.starter-template", nil)
+	if err != nil {
+		t.Fatalf("expected template get to succeed: %v", err)
+	}
+
+	currentTemplate, ok := currentObj.(*aggregationv1alpha1.CoderTemplate)
+	if !ok {
+		t.Fatalf("expected *CoderTemplate from get, got %T", currentObj)
+	}
+
+	desiredTemplate := currentTemplate.DeepCopy()
+	desiredTemplate.Spec.Running = !currentTemplate.Spec.Running
+	desiredTemplate.Spec.VersionID = uuid.New().String()
+	if desiredTemplate.Spec.VersionID == currentTemplate.Spec.VersionID {
+		t.Fatal("expected test fixture to use a different spec.versionID")
+	}
+
+	_, created, err := templateStorage.Update(
+		ctx,
+		desiredTemplate.Name,
+		testUpdatedObjectInfo{obj: desiredTemplate},
+		nil,
+		rest.ValidateAllObjectUpdateFunc,
+		false,
+		nil,
+	)
+	if !apierrors.IsBadRequest(err) {
+		t.Fatalf("expected BadRequest when changing spec.versionID to an unknown version, got %v", err)
+	}
+	if err == nil || !strings.Contains(err.Error(), "is not a version of template") {
+		t.Fatalf("expected unknown spec.versionID error, got %v", err)
+	}
+	if created {
+		t.Fatal("expected update created=false")
+	}
+}
+
+func TestTemplateStorageUpdatePromotesExistingVersionID(t *testing.T) {
+	t.Parallel()
+
+	server, state := newMockCoderServer(t)
+	defer server.Close()
+
+	templateStorage := NewTemplateStorage(newTestClientProvider(t, server.URL))
+	ctx := namespacedContext("control-plane")
+
+	initialFiles := map[string]string{"main.tf": "resource \"null_resource\" \"initial\" {}"}
+	createdObj, err := templateStorage.Create(ctx, &aggregationv1alpha1.CoderTemplate{
+		ObjectMeta: metav1.ObjectMeta{Name: "acme.promote-version-template"},
+		Spec: aggregationv1alpha1.CoderTemplateSpec{
+			Organization: "acme",
+			Files:        cloneStringMap(initialFiles),
+		},
+	}, rest.ValidateAllObjectFunc, nil)
+	if err != nil {
+		t.Fatalf("expected template create with files to succeed: %v", err)
+	}
+	createdTemplate, ok := createdObj.(*aggregationv1alpha1.CoderTemplate)
+	if !ok {
+		t.Fatalf("expected *CoderTemplate from create, got %T", createdObj)
+	}
+	initialVersionID := createdTemplate.Spec.VersionID
+
+	updatedFiles := map[string]string{"main.tf": "resource \"null_resource\" \"updated\" {}"}
+	desiredTemplate := createdTemplate.DeepCopy()
+	desiredTemplate.Spec.Files = cloneStringMap(updatedFiles)
+	updatedObj, _, err := templateStorage.Update(
+		ctx,
+		desiredTemplate.Name,
+		testUpdatedObjectInfo{obj: desiredTemplate},
+		nil,
+		rest.ValidateAllObjectUpdateFunc,
+		false,
+		nil,
+	)
+	if err != nil {
+		t.Fatalf("expected update with changed files to succeed: %v", err)
+	}
+	updatedTemplate, ok := updatedObj.(*aggregationv1alpha1.CoderTemplate)
+	if !ok {
+		t.Fatalf("expected *CoderTemplate from update, got %T", updatedObj)
+	}
+	if updatedTemplate.Spec.VersionID == initialVersionID {
+		t.Fatalf("expected files update to create a new active version, still %q", initialVersionID)
+	}
+
+	fileCountBefore := state.fileCount()
+	templateVersionCountBefore := state.templateVersionCount()
+
+	// Files still match the current active version, so only the version changes.
+	promoteTemplate := updatedTemplate.DeepCopy()
+	promoteTemplate.Spec.VersionID = initialVersionID
+	promotedObj, created, err := templateStorage.Update(
+		ctx,
+		promoteTemplate.Name,
+		testUpdatedObjectInfo{obj: promoteTemplate},
+		nil,
+		rest.ValidateAllObjectUpdateFunc,
+		false,
+		nil,
+	)
+	if err != nil {
+		t.Fatalf("expected update promoting an existing version to succeed: %v", err)
+	}
+	if created {
+		t.Fatal("expected update created=false")
+	}
+
+	if state.fileCount() != fileCountBefore {
+		t.Fatalf("expected no file upload during version promotion, before=%d after=%d", fileCountBefore, state.fileCount())
+	}
+	if state.templateVersionCount() != templateVersionCountBefore {
+		t.Fatalf("expected no template version creation during version promotion, before=%d after=%d", templateVersionCountBefore, state.templateVersionCount())
+	}
+	activeVersionAfter, ok := state.templateActiveVersionID("acme", "promote-version-template")
+	if !ok {
+		t.Fatal("expected active version for promoted template")
+	}
+	if activeVersionAfter.String() != initialVersionID {
+		t.Fatalf("expected active version %q after promotion, got %q", initialVersionID, activeVersionAfter)
+	}
+
+	promotedTemplate, ok := promotedObj.(*aggregationv1alpha1.CoderTemplate)
+	if !ok {
+		t.Fatalf("expected *CoderTemplate from update, got %T", promotedObj)
+	}
+	if promotedTemplate.Spec.VersionID != initialVersionID {
+		t.Fatalf("expected spec.versionID %q after promotion, got %q", initialVersionID, promotedTemplate.Spec.VersionID)
+	}
+	if !reflect.DeepEqual(promotedTemplate.Spec.Files, initialFiles) {
+		t.Fatalf("expected promoted version files %v, got %v", initialFiles, promotedTemplate.Spec.Files)
+	}
+
+	t.Run("RejectsVersionOfAnotherTemplate", func(t *testing.T) {
+		starterObj, err := templateStorage.Get(ctx, "acme.starter-template", nil)
+		if err != nil {
+			t.Fatalf("expected starter template get to succeed: %v", err)
+		}
+		starterTemplate, ok := starterObj.(*aggregationv1alpha1.CoderTemplate)
+		if !ok {
+			t.Fatalf("expected *CoderTemplate from get, got %T", starterObj)
+		}
+
+		foreignTemplate := promotedTemplate.DeepCopy()
+		foreignTemplate.Spec.VersionID = starterTemplate.Spec.VersionID
+		_, _, err = templateStorage.Update(
+			ctx,
+			foreignTemplate.Name,
+			testUpdatedObjectInfo{obj: foreignTemplate},
+			nil,
+			rest.ValidateAllObjectUpdateFunc,
+			false,
+			nil,
+		)
+		if !apierrors.IsBadRequest(err) || !strings.Contains(err.Error(), "is not a version of template") {
+			t.Fatalf("expected BadRequest for a version of another template, got %v", err)
+		}
+	})
+
+	t.Run("RejectsChangedFiles", func(t *testing.T) {
+		changedTemplate := promotedTemplate.DeepCopy()
+		changedTemplate.Spec.VersionID = updatedTemplate.Spec.VersionID
+		changedTemplate.Spec.Files = map[string]string{"main.tf": "resource \"null_resource\" \"other\" {}"}
+		_, _, err := templateStorage.Update(
+			ctx,
+			changedTemplate.Name,
+			testUpdatedObjectInfo{obj: changedTemplate},
+			nil,
+			rest.ValidateAllObjectUpdateFunc,
+			false,
+			nil,
+		)
+		if !apierrors.IsBadRequest(err) || !strings.Contains(err.Error(), "cannot both change") {
+			t.Fatalf("expected BadRequest when changing spec.versionID and spec.files together, got %v", err)
+		}
+	})
+}
+
+func TestTemplateStorageUpdateAllowsMetadataSpecChanges(t *testing.T) {
+	t.Parallel()
+
+	server, _ := newMockCoderServer(t)
+	defer server.Close()
+
+	templateStorage := NewTemplateStorage(newTestClientProvider(t, server.URL))
+	ctx := namespacedContext("control-plane")
+
+	currentObj, err := templateStorage.Get(ctx, "acme.starter-template", nil)
+	if err != nil {
+		t.Fatalf("expected template get to succeed: %v", err)
+	}
+
+	currentTemplate, ok := currentObj.(*aggregationv1alpha1.CoderTemplate)
+	if !ok {
+		t.Fatalf("expected *CoderTemplate from get, got %T", currentObj)
+	}
+
+	desiredTemplate := currentTemplate.DeepCopy()
+	desiredTemplate.Spec.DisplayName = "Renamed Template"
+
+	updatedObj, created, err := templateStorage.Update(
+		ctx,
+		desiredTemplate.Name,
+		testUpdatedObjectInfo{obj: desiredTemplate},
+		nil,
+		rest.ValidateAllObjectUpdateFunc,
+		false,
+		nil,
+	)
+	if err != nil {
+		t.Fatalf("expected update to allow metadata spec changes: %v", err)
+	}
+	if created {
+		t.Fatal("expected update created=false")
+	}
+
+	updatedTemplate, ok := updatedObj.(*aggregationv1alpha1.CoderTemplate)
+	if !ok {
+		t.Fatalf("expected *CoderTemplate from update, got %T", updatedObj)
+	}
+	if updatedTemplate.Spec.DisplayName != desiredTemplate.Spec.DisplayName {
+		t.Fatalf("expected updated displayName %q, got %q", desiredTemplate.Spec.DisplayName, updatedTemplate.Spec.DisplayName)
+	}
+}
+
+func TestTemplateStorageUpdateForceAllowCreateCreatesWhenMissing(t *testing.T) {
+	t.Parallel()
+
+	server, state := newMockCoderServer(t)
+	defer server.Close()
+
+	templateStorage := NewTemplateStorage(newTestClientProvider(t, server.URL))
+	ctx := namespacedContext("control-plane")
+
+	desiredTemplate := &aggregationv1alpha1.CoderTemplate{
+		Spec: aggregationv1alpha1.CoderTemplateSpec{
+			Organization: "acme",
+			DisplayName:  "SSA-created template",
+			Description:  "Created via forceAllowCreate update hack",
+			Icon:         "/icons/ssa-template.png",
+			Files: map[string]string{
+				"main.tf": "resource \"null_resource\" \"ssa_create\" {}",
+			},
+		},
+	}
+
+	updatedObj, created, err := templateStorage.Update(
+		ctx,
+		"acme.ssa-template",
+		testUpdatedObjectInfo{obj: desiredTemplate},
+		rest.ValidateAllObjectFunc,
+		rest.ValidateAllObjectUpdateFunc,
+		true,
+		nil,
+	)
+	if err != nil {
+		t.Fatalf("expected create-on-update to succeed for missing template: %v", err)
+	}
+	if !created {
+		t.Fatal("expected update created=true when forceAllowCreate creates a template")
+	}
+
+	createdTemplate, ok := updatedObj.(*aggregationv1alpha1.CoderTemplate)
+	if !ok {
+		t.Fatalf("expected *CoderTemplate from update, got %T", updatedObj)
+	}
+	if createdTemplate.Name != "acme.ssa-template" {
+		t.Fatalf("expected created template name acme.ssa-template, got %q", createdTemplate.Name)
+	}
+	if createdTemplate.Spec.Organization != "acme" {
+		t.Fatalf("expected created template organization acme, got %q", createdTemplate.Spec.Organization)
+	}
+	if !state.hasTemplate("acme", "ssa-template") {
+		t.Fatal("expected create-on-update template to be persisted in mock server state")
+	}
+}
+
+func TestTemplateStorageUpdateRetainsManagedFields(t *testing.T) {
+	t.Parallel()
+
+	server, _ := newMockCoderServer(t)
+	defer server.Close()
+
+	templateStorage := NewTemplateStorage(newTestClientProvider(t, server.URL))
+	ctx := namespacedContext("control-plane")
+
+	currentObj, err := templateStorage.Get(ctx, "acme.starter-template", nil)
+	if err != nil {
+		t.Fatalf("expected template get to succeed: %v", err)
+	}
+	currentTemplate, ok := currentObj.(*aggregationv1alpha1.CoderTemplate)
+	if !ok {
+		t.Fatalf("expected *CoderTemplate from get, got %T", currentObj)
+	}
+	if len(currentTemplate.ManagedFields) != 0 {
+		t.Fatalf("expected no managed fields before the first apply, got %v", currentTemplate.ManagedFields)
+	}
+
+	desiredTemplate := currentTemplate.DeepCopy()
+	desiredTemplate.Spec.DisplayName = "Applied Display Name"
+	desiredTemplate.ManagedFields = []metav1.ManagedFieldsEntry{
+		appliedManagedFieldsEntry("gitops", `{"f:spec":{"f:displayName":{}}}`),
+	}
+
+	updatedObj, _, err := templateStorage.Update(
+		ctx,
+		desiredTemplate.Name,
+		testUpdatedObjectInfo{obj: desiredTemplate},
+		nil,
+		rest.ValidateAllObjectUpdateFunc,
+		false,
+		nil,
+	)
+	if err != nil {
+		t.Fatalf("expected apply update to succeed: %v", err)
+	}
+	updatedTemplate, ok := updatedObj.(*aggregationv1alpha1.CoderTemplate)
+	if !ok {
+		t.Fatalf("expected *CoderTemplate from update, got %T", updatedObj)
+	}
+	if !reflect.DeepEqual(updatedTemplate.ManagedFields, desiredTemplate.ManagedFields) {
+		t.Fatalf("expected update to return managed fields %v, got %v", desiredTemplate.ManagedFields, updatedTemplate.ManagedFields)
+	}
+
+	refetchedObj, err := templateStorage.Get(ctx, "acme.starter-template", nil)
+	if err != nil {
+		t.Fatalf("expected template get to succeed: %v", err)
+	}
+	refetchedTemplate, ok := refetchedObj.(*aggregationv1alpha1.CoderTemplate)
+	if !ok {
+		t.Fatalf("expected *CoderTemplate from get, got %T", refetchedObj)
+	}
+	if !reflect.DeepEqual(refetchedTemplate.ManagedFields, desiredTemplate.ManagedFields) {
+		t.Fatalf("expected get to return managed fields %v, got %v", desiredTemplate.ManagedFields, refetchedTemplate.ManagedFields)
+	}
+
+	foreground := metav1.DeletePropagationForeground
+	if _, _, err := templateStorage.Delete(
+		ctx,
+		"acme.starter-template",
+		rest.ValidateAllObjectFunc,
+		&metav1.DeleteOptions{PropagationPolicy: &foreground},
+	); err != nil {
+		t.Fatalf("expected template delete to succeed: %v", err)
+	}
+	if fields := templateStorage.managedFields.get(refetchedTemplate); fields != nil {
+		t.Fatalf("expected delete to drop managed fields, got %v", fields)
+	}
+}
+
+func TestTemplateStorageDeleteWithWorkspaces(t *testing.T) {
+	t.Parallel()
+
+	server, state := newMockCoderServer(t)
+	defer server.Close()
+
+	templateStorage := NewTemplateStorage(newTestClientProvider(t, server.URL))
+	ctx := namespacedContext("control-plane")
+	state.addWorkspaceCopy("bob", "second-workspace")
+	background := metav1.DeletePropagationBackground
+	foreground := metav1.DeletePropagationForeground
+
+	for _, opts := range []*metav1.DeleteOptions{nil, {PropagationPolicy: &background}} {
+		_, deleted, err := templateStorage.Delete(ctx, "acme.starter-template", rest.ValidateAllObjectFunc, opts)
+		if !apierrors.IsConflict(err) {
+			t.Fatalf("expected Conflict when deleting a template with workspaces, got %v", err)
+		}
+		assertTopLevelStatusError(t, err)
+		for _, workspaceName := range []string{"acme.alice.dev-workspace", "acme.bob.second-workspace"} {
+			if !strings.Contains(err.Error(), workspaceName) {
+				t.Fatalf("expected error to name blocking workspace %q, got %v", workspaceName, err)
+			}
+		}
+		if deleted {
+			t.Fatal("expected blocked delete to report deleted=false")
+		}
+	}
+	if !state.hasTemplate("acme", "starter-template") {
+		t.Fatal("expected blocked delete to keep the template")
+	}
+	if containsTransition(state.buildTransitionsSnapshot(), codersdk.WorkspaceTransitionDelete) {
+		t.Fatal("expected blocked delete to leave workspaces alone")
+	}
+
+	_, deleted, err := templateStorage.Delete(
+		ctx,
+		"acme.starter-template",
+		rest.ValidateAllObjectFunc,
+		&metav1.DeleteOptions{PropagationPolicy: &foreground},
+	)
+	if err != nil {
+		t.Fatalf("expected foreground delete to succeed: %v", err)
+	}
+	if !deleted {
+		t.Fatal("expected foreground delete to report deleted=true")
+	}
+	if state.hasTemplate("acme", "starter-template") {
+		t.Fatal("expected foreground delete to remove the template")
+	}
+	deleteBuilds := 0
+	for _, transition := range state.buildTransitionsSnapshot() {
+		if transition == codersdk.WorkspaceTransitionDelete {
+			deleteBuilds++
+		}
+	}
+	if deleteBuilds != 2 {
+		t.Fatalf("expected a delete build for each workspace, got %d", deleteBuilds)
+	}
+}
+
+func TestTemplateStorageDeleteDryRunLeavesTemplateAndWorkspaces(t *testing.T) {
+	t.Parallel()
+
+	server, state := newMockCoderServer(t)
+	defer server.Close()
+
+	templateStorage := NewTemplateStorage(newTestClientProvider(t, server.URL))
+	state.addWorkspaceCopy("bob", "second-workspace")
+	foreground := metav1.DeletePropagationForeground
+
+	recorder := &recordingWarningRecorder{}
+	obj, deleted, err := templateStorage.Delete(
+		warning.WithWarningRecorder(namespacedContext("control-plane"), recorder),
+		"acme.starter-template",
+		rest.ValidateAllObjectFunc,
+		&metav1.DeleteOptions{PropagationPolicy: &foreground, DryRun: []string{metav1.DryRunAll}},
+	)
+	if err != nil {
+		t.Fatalf("expected dry-run foreground delete to succeed: %v", err)
+	}
+	if !deleted {
+		t.Fatal("expected dry-run delete to report deleted=true")
+	}
+	template, ok := obj.(*aggregationv1alpha1.CoderTemplate)
+	if !ok {
+		t.Fatalf("expected *CoderTemplate from dry-run delete, got %T", obj)
+	}
+	if template.Name != "acme.starter-template" {
+		t.Fatalf("expected dry-run delete to return the template, got %q", template.Name)
+	}
+	if !state.hasTemplate("acme", "starter-template") {
+		t.Fatal("expected dry-run delete to keep the template")
+	}
+	if containsTransition(state.buildTransitionsSnapshot(), codersdk.WorkspaceTransitionDelete) {
+		t.Fatal("expected dry-run delete to leave workspaces alone")
+	}
+
+	warnings := recorder.snapshot()
+	if len(warnings) != 1 {
+		t.Fatalf("expected one warning naming the cascaded workspaces, got %v", warnings)
+	}
+	for _, workspaceName := range []string{"acme.alice.dev-workspace", "acme.bob.second-workspace"} {
+		if !strings.Contains(warnings[0], workspaceName) {
+			t.Fatalf("expected warning to name workspace %q, got %q", workspaceName, warnings[0])
+		}
+	}
+}
+
+func TestTemplateStorageUpdateRejectsAppliedStatusFields(t *testing.T) {
+	t.Parallel()
+
+	server, state := newMockCoderServer(t)
+	defer server.Close()
+
+	templateStorage := NewTemplateStorage(newTestClientProvider(t, server.URL))
+	ctx := namespacedContext("control-plane")
+
+	currentObj, err := templateStorage.Get(ctx, "acme.starter-template", nil)
+	if err != nil {
+		t.Fatalf("expected template get to succeed: %v", err)
+	}
+	currentTemplate, ok := currentObj.(*aggregationv1alpha1.CoderTemplate)
+	if !ok {
+		t.Fatalf("expected *CoderTemplate from get, got %T", currentObj)
+	}
+
+	metaUpdateCountBefore := state.templateMetaUpdateCount()
+
+	desiredTemplate := currentTemplate.DeepCopy()
+	desiredTemplate.Spec.DisplayName = "Applied Display Name"
+	desiredTemplate.Status.Deprecated = true
+	desiredTemplate.ManagedFields = []metav1.ManagedFieldsEntry{
+		appliedManagedFieldsEntry("gitops", `{"f:spec":{"f:displayName":{}},"f:status":{"f:deprecated":{}}}`),
+	}
+
+	_, _, err = templateStorage.Update(
+		ctx,
+		desiredTemplate.Name,
+		testUpdatedObjectInfo{obj: desiredTemplate},
+		nil,
+		rest.ValidateAllObjectUpdateFunc,
+		false,
+		nil,
+	)
+	if !apierrors.IsBadRequest(err) {
+		t.Fatalf("expected BadRequest for applied status fields, got %v", err)
+	}
+	if state.templateMetaUpdateCount() != metaUpdateCountBefore {
+		t.Fatalf("expected rejected apply to avoid metadata updates, before=%d after=%d", metaUpdateCountBefore, state.templateMetaUpdateCount())
+	}
+}
+
+func TestTemplateStorageUpdateRejectsMissingResourceVersion(t *testing.T) {
+	t.Parallel()
+
+	server, _ := newMockCoderServer(t)
+	defer server.Close()
+
+	templateStorage := NewTemplateStorage(newTestClientProvider(t, server.URL))
+	ctx := namespacedContext("control-plane")
+
+	currentObj, err := templateStorage.Get(ctx, "acme.starter-template", nil)
+	if err != nil {
+		t.Fatalf("expected template get to succeed: %v", err)
+	}
+
+	currentTemplate, ok := currentObj.(*aggregationv1alpha1.CoderTemplate)
+	if !ok {
+		t.Fatalf("expected *CoderTemplate from get, got %T", currentObj)
+	}
+	if currentTemplate.ResourceVersion == "" {
+		t.Fatal("expected current template resourceVersion to be populated")
+	}
+
+	desiredTemplate := currentTemplate.DeepCopy()
+	desiredTemplate.Spec.Running = !currentTemplate.Spec.Running
+	desiredTemplate.ResourceVersion = ""
+
+	_, _, err = templateStorage.Update(
+		ctx,
+		desiredTemplate.Name,
+		testUpdatedObjectInfo{obj: desiredTemplate},
+		nil,
+		rest.ValidateAllObjectUpdateFunc,
+		false,
+		nil,
+	)
+	if !apierrors.IsBadRequest(err) {
+		t.Fatalf("expected BadRequest for missing resourceVersion, got %v", err)
+	}
+	if err == nil || !strings.Contains(err.Error(), "metadata.resourceVersion is required for update") {
+		t.Fatalf("expected missing resourceVersion error message, got %v", err)
+	}
+}
+
+func TestTemplateStorageUpdateRejectsStaleResourceVersion(t *testing.T) {
+	t.Parallel()
+
+	server, _ := newMockCoderServer(t)
+	defer server.Close()
+
+	templateStorage := NewTemplateStorage(newTestClientProvider(t, server.URL))
+	ctx := namespacedContext("control-plane")
+
+	currentObj, err := templateStorage.Get(ctx, "acme.starter-template", nil)
+	if err != nil {
+		t.Fatalf("expected template get to succeed: %v", err)
+	}
+
+	currentTemplate, ok := currentObj.(*aggregationv1alpha1.CoderTemplate)
+	if !ok {
+		t.Fatalf("expected *CoderTemplate from get, got %T", currentObj)
+	}
+
+	desiredTemplate := currentTemplate.DeepCopy()
+	desiredTemplate.Spec.Running = !currentTemplate.Spec.Running
+	desiredTemplate.ResourceVersion = currentTemplate.ResourceVersion + "-stale"
+
+	_, _, err = templateStorage.Update(
+		ctx,
+		desiredTemplate.Name,
+		testUpdatedObjectInfo{obj: desiredTemplate},
+		nil,
+		rest.ValidateAllObjectUpdateFunc,
+		false,
+		nil,
+	)
+	if !apierrors.IsConflict(err) {
+		t.Fatalf("expected Conflict for stale resourceVersion, got %v", err)
+	}
+	if err == nil || !strings.Contains(err.Error(), "resource version mismatch") {
+		t.Fatalf("expected stale resourceVersion error message, got %v", err)
+	}
+}
+
+func TestTemplateStorageUpdateRejectsMismatchedName(t *testing.T) {
+	t.Parallel()
+
+	server, _ := newMockCoderServer(t)
+	defer server.Close()
+
+	templateStorage := NewTemplateStorage(newTestClientProvider(t, server.URL))
+	ctx := namespacedContext("control-plane")
+
+	currentObj, err := templateStorage.Get(ctx, "acme.starter-template", nil)
+	if err != nil {
+		t.Fatalf("expected template get to succeed: %v", err)
+	}
+
+	currentTemplate, ok := currentObj.(*aggregationv1alpha1.CoderTemplate)
+	if !ok {
+		t.Fatalf("expected *CoderTemplate from get, got %T", currentObj)
+	}
+
+	desiredTemplate := currentTemplate.DeepCopy()
+	desiredTemplate.Spec.Running = !currentTemplate.Spec.Running
+	desiredTemplate.Name = "acme.other-template"
+
+	_, _, err = templateStorage.Update(
+		ctx,
+		currentTemplate.Name,
+		testUpdatedObjectInfo{obj: desiredTemplate},
+		nil,
+		rest.ValidateAllObjectUpdateFunc,
+		false,
+		nil,
+	)
+	if !apierrors.IsBadRequest(err) {
+		t.Fatalf("expected BadRequest for mismatched name, got %v", err)
+	}
+	if err == nil || !strings.Contains(err.Error(), "updated object metadata.name \"acme.other-template\" must match request name \"acme.starter-template\"") {
+		t.Fatalf("expected mismatched name error message, got %v", err)
+	}
+}
+
+func TestTemplateStorageUpdateRejectsMismatchedNamespace(t *testing.T) {
+	t.Parallel()
+
+	server, _ := newMockCoderServer(t)
+	defer server.Close()
+
+	templateStorage := NewTemplateStorage(newTestClientProvider(t, server.URL))
+	ctx := namespacedContext("control-plane")
+
+	currentObj, err := templateStorage.Get(ctx, "acme.starter-template", nil)
+	if err != nil {
+		t.Fatalf("expected template get to succeed: %v", err)
+	}
+
+	currentTemplate, ok := currentObj.(*aggregationv1alpha1.CoderTemplate)
+	if !ok {
+		t.Fatalf("expected *CoderTemplate from get, got %T", currentObj)
+	}
+
+	desiredTemplate := currentTemplate.DeepCopy()
+	desiredTemplate.Spec.Running = !currentTemplate.Spec.Running
+	desiredTemplate.Namespace = "other-namespace"
+
+	_, _, err = templateStorage.Update(
+		ctx,
+		desiredTemplate.Name,
+		testUpdatedObjectInfo{obj: desiredTemplate},
+		nil,
+		rest.ValidateAllObjectUpdateFunc,
+		false,
+		nil,
+	)
+	if !apierrors.IsBadRequest(err) {
+		t.Fatalf("expected BadRequest for mismatched namespace, got %v", err)
+	}
+	if err == nil || !strings.Contains(err.Error(), "metadata.namespace \"other-namespace\" does not match request namespace \"control-plane\"") {
+		t.Fatalf("expected mismatched namespace error message, got %v", err)
+	}
+}
+
+func TestTemplateVersionStorageGet(t *testing.T) {
+	t.Parallel()
+
+	server, state := newMockCoderServer(t)
+	defer server.Close()
+
+	templateVersionStorage := NewTemplateVersionStorage(newTestClientProvider(t, server.URL))
+	ctx := namespacedContext("control-plane")
+
+	activeVersionID, ok := state.templateActiveVersionID("acme", "starter-template")
+	if !ok {
+		t.Fatal("expected seeded starter-template active version")
+	}
+
+	obj, err := templateVersionStorage.Get(ctx, "acme.starter-template."+activeVersionID.String(), nil)
+	if err != nil {
+		t.Fatalf("expected template version get to succeed: %v", err)
+	}
+
+	version, ok := obj.(*aggregationv1alpha1.CoderTemplateVersion)
+	if !ok {
+		t.Fatalf("expected *CoderTemplateVersion, got %T", obj)
+	}
+	if version.Spec.TemplateName != "starter-template" {
+		t.Fatalf("expected template name starter-template, got %q", version.Spec.TemplateName)
+	}
+	if !version.Status.Active {
+		t.Fatal("expected seeded active version to report status.active=true")
+	}
+}
+
+func TestTemplateVersionPromoteUpdatesActiveVersion(t *testing.T) {
+	t.Parallel()
+
+	server, state := newMockCoderServer(t)
+	defer server.Close()
+
+	templateStorage := NewTemplateStorage(newTestClientProvider(t, server.URL))
+	defer templateStorage.Destroy()
+	promoteStorage := NewTemplateVersionPromoteStorage(templateStorage)
+	ctx := namespacedContext("control-plane")
+
+	templateID, ok := state.templateIDByName("acme", "starter-template")
+	if !ok {
+		t.Fatal("expected seeded starter-template")
+	}
+	newVersionID := state.addSucceededTemplateVersion(templateID)
+
+	name := "acme.starter-template." + newVersionID.String()
+	obj, err := promoteStorage.Create(ctx, name, &aggregationv1alpha1.CoderTemplateVersion{}, rest.ValidateAllObjectFunc, nil)
+	if err != nil {
+		t.Fatalf("expected template version promote to succeed: %v", err)
+	}
+
+	template, ok := obj.(*aggregationv1alpha1.CoderTemplate)
+	if !ok {
+		t.Fatalf("expected *CoderTemplate from promote, got %T", obj)
+	}
+	if template.Status.ActiveVersionID != newVersionID.String() {
+		t.Fatalf("expected returned active version %q, got %q", newVersionID.String(), template.Status.ActiveVersionID)
+	}
+	// The response matches Get so watchers do not see spec.files disappear.
+	if template.Spec.Files == nil {
+		t.Fatal("expected promote to return the template's spec.files")
+	}
+	if template.Status.ActiveVersionName == "" {
+		t.Fatal("expected promote to return status.activeVersionName")
+	}
+
+	activeVersionID, ok := state.templateActiveVersionID("acme", "starter-template")
+	if !ok {
+		t.Fatal("expected starter-template to exist after promote")
+	}
+	if activeVersionID != newVersionID {
+		t.Fatalf("expected backend active version %q, got %q", newVersionID.String(), activeVersionID.String())
+	}
+}
+
+func TestTemplateVersionPromoteRejectsVersionFromDifferentTemplate(t *testing.T) {
+	t.Parallel()
+
+	server, state := newMockCoderServer(t)
+	defer server.Close()
+
+	templateStorage := NewTemplateStorage(newTestClientProvider(t, server.URL))
+	defer templateStorage.Destroy()
+	promoteStorage := NewTemplateVersionPromoteStorage(templateStorage)
+	ctx := namespacedContext("control-plane")
+
+	originalActiveVersionID, ok := state.templateActiveVersionID("acme", "starter-template")
+	if !ok {
+		t.Fatal("expected seeded starter-template active version")
+	}
+	crossTemplateVersionID := state.addSucceededTemplateVersion(uuid.New())
+
+	name := "acme.starter-template." + crossTemplateVersionID.String()
+	_, err := promoteStorage.Create(ctx, name, &aggregationv1alpha1.CoderTemplateVersion{}, rest.ValidateAllObjectFunc, nil)
+	if !apierrors.IsBadRequest(err) {
+		t.Fatalf("expected BadRequest when promoting a version from a different template, got %v", err)
+	}
+
+	expectedMessage := fmt.Sprintf(
+		"template version %q does not belong to template %q",
+		crossTemplateVersionID.String(),
+		"starter-template",
+	)
+	if err == nil || !strings.Contains(err.Error(), expectedMessage) {
+		t.Fatalf("expected cross-template error message %q, got %v", expectedMessage, err)
+	}
+
+	activeVersionID, ok := state.templateActiveVersionID("acme", "starter-template")
+	if !ok {
+		t.Fatal("expected starter-template to exist after rejected promote")
+	}
+	if activeVersionID != originalActiveVersionID {
+		t.Fatalf("expected active version to remain %q, got %q", originalActiveVersionID.String(), activeVersionID.String())
+	}
+}
+
+func TestTemplateVersionPromoteRejectsMismatchedBodyName(t *testing.T) {
+	t.Parallel()
+
+	server, _ := newMockCoderServer(t)
+	defer server.Close()
+
+	templateStorage := NewTemplateStorage(newTestClientProvider(t, server.URL))
+	defer templateStorage.Destroy()
+	promoteStorage := NewTemplateVersionPromoteStorage(templateStorage)
+	ctx := namespacedContext("control-plane")
+
+	name := "acme.starter-template." + uuid.New().String()
+	body := &aggregationv1alpha1.CoderTemplateVersion{
+		ObjectMeta: metav1.ObjectMeta{Name: "acme.starter-template." + uuid.New().String()},
+	}
+	_, err := promoteStorage.Create(ctx, name, body, rest.ValidateAllObjectFunc, nil)
+	if !apierrors.IsBadRequest(err) {
+		t.Fatalf("expected BadRequest for mismatched body name, got %v", err)
+	}
+}
+
+func TestTemplateLintReturnsInactiveVersion(t *testing.T) {
+	t.Parallel()
+
+	server, state := newMockCoderServer(t)
+	defer server.Close()
+
+	templateStorage := NewTemplateStorage(newTestClientProvider(t, server.URL))
+	defer templateStorage.Destroy()
+	lintStorage := NewTemplateLintStorage(templateStorage)
+	ctx := namespacedContext("control-plane")
+
+	activeVersionBefore, ok := state.templateActiveVersionID("acme", "starter-template")
+	if !ok {
+		t.Fatal("expected seeded starter-template active version")
+	}
+	templateVersionCountBefore := state.templateVersionCount()
+
+	body := &aggregationv1alpha1.CoderTemplate{
+		Spec: aggregationv1alpha1.CoderTemplateSpec{
+			Files: map[string]string{"main.tf": "resource \"null_resource\" \"lint\" {}"},
+		},
+	}
+	obj, err := lintStorage.Create(ctx, "acme.starter-template", body, rest.ValidateAllObjectFunc, nil)
+	if err != nil {
+		t.Fatalf("expected template lint to succeed: %v", err)
+	}
+
+	version, ok := obj.(*aggregationv1alpha1.CoderTemplateVersion)
+	if !ok {
+		t.Fatalf("expected *CoderTemplateVersion from lint, got %T", obj)
+	}
+	if version.Status.JobStatus != string(codersdk.ProvisionerJobSucceeded) {
+		t.Fatalf("expected lint job status %q, got %q", codersdk.ProvisionerJobSucceeded, version.Status.JobStatus)
+	}
+	if version.Status.Active {
+		t.Fatal("expected linted version to be inactive")
+	}
+	if !strings.HasPrefix(version.Name, "acme.starter-template.") {
+		t.Fatalf("expected linted version name under acme.starter-template, got %q", version.Name)
+	}
+
+	if state.templateVersionCount() != templateVersionCountBefore+1 {
+		t.Fatalf("expected lint to create one template version, before=%d after=%d", templateVersionCountBefore, state.templateVersionCount())
+	}
+	activeVersionAfter, ok := state.templateActiveVersionID("acme", "starter-template")
+	if !ok {
+		t.Fatal("expected starter-template to exist after lint")
+	}
+	if activeVersionAfter != activeVersionBefore {
+		t.Fatalf("expected active version to remain %q, got %q", activeVersionBefore.String(), activeVersionAfter.String())
+	}
+
+	lintedVersionID := uuid.MustParse(version.Status.ID)
+	archived, ok := state.templateVersionArchived(lintedVersionID)
+	if !ok {
+		t.Fatalf("expected linted version %q to exist", lintedVersionID.String())
+	}
+	if !archived {
+		t.Fatal("expected linted version to be archived")
+	}
+
+	promoteStorage := NewTemplateVersionPromoteStorage(templateStorage)
+	if _, err := promoteStorage.Create(ctx, version.Name, nil, rest.ValidateAllObjectFunc, nil); err != nil {
+		t.Fatalf("expected linted version to be promotable: %v", err)
+	}
+	activeVersionAfter, ok = state.templateActiveVersionID("acme", "starter-template")
+	if !ok {
+		t.Fatal("expected starter-template to exist after promote")
+	}
+	if activeVersionAfter != lintedVersionID {
+		t.Fatalf("expected promoted active version %q, got %q", lintedVersionID.String(), activeVersionAfter.String())
+	}
+	if archived, _ := state.templateVersionArchived(lintedVersionID); archived {
+		t.Fatal("expected promoted version to be unarchived")
+	}
+}
+
+func TestTemplateLintDryRunCreatesNoVersion(t *testing.T) {
+	t.Parallel()
+
+	server, state := newMockCoderServer(t)
+	defer server.Close()
+
+	templateStorage := NewTemplateStorage(newTestClientProvider(t, server.URL))
+	defer templateStorage.Destroy()
+	lintStorage := NewTemplateLintStorage(templateStorage)
+	ctx := namespacedContext("control-plane")
+
+	templateVersionCountBefore := state.templateVersionCount()
+
+	body := &aggregationv1alpha1.CoderTemplate{
+		Spec: aggregationv1alpha1.CoderTemplateSpec{
+			Files: map[string]string{"main.tf": "resource \"null_resource\" \"lint\" {}"},
+		},
+	}
+	obj, err := lintStorage.Create(
+		ctx,
+		"acme.starter-template",
+		body,
+		rest.ValidateAllObjectFunc,
+		&metav1.CreateOptions{DryRun: []string{metav1.DryRunAll}},
+	)
+	if err != nil {
+		t.Fatalf("expected dry-run template lint to succeed: %v", err)
+	}
+
+	version, ok := obj.(*aggregationv1alpha1.CoderTemplateVersion)
+	if !ok {
+		t.Fatalf("expected *CoderTemplateVersion from lint, got %T", obj)
+	}
+	if version.Spec.TemplateName != "starter-template" {
+		t.Fatalf("expected dry-run lint for starter-template, got %q", version.Spec.TemplateName)
+	}
+	if version.Status.ID != "" {
+		t.Fatalf("expected dry-run lint to return no version ID, got %q", version.Status.ID)
+	}
+	if state.templateVersionCount() != templateVersionCountBefore {
+		t.Fatalf("expected dry-run lint to create no template version, before=%d after=%d", templateVersionCountBefore, state.templateVersionCount())
+	}
+}
+
+func TestTemplateLintReturnsJobErrorForFailedImport(t *testing.T) {
+	t.Parallel()
+
+	server, state := newMockCoderServer(t)
+	defer server.Close()
+
+	templateStorage := NewTemplateStorage(newTestClientProvider(t, server.URL))
+	defer templateStorage.Destroy()
+	lintStorage := NewTemplateLintStorage(templateStorage)
+	ctx := namespacedContext("control-plane")
+
+	activeVersionBefore, ok := state.templateActiveVersionID("acme", "starter-template")
+	if !ok {
+		t.Fatal("expected seeded starter-template active version")
+	}
+
+	jobError := "main.tf:1,1-9: Unsupported block type; Blocks of type \"resourc\" are not expected here."
+	state.setNextCreatedTemplateVersionFailed(jobError)
+
+	body := &aggregationv1alpha1.CoderTemplate{
+		Spec: aggregationv1alpha1.CoderTemplateSpec{
+			Files: map[string]string{"main.tf": "resourc \"null_resource\" \"lint\" {}"},
+		},
+	}
+	obj, err := lintStorage.Create(ctx, "acme.starter-template", body, rest.ValidateAllObjectFunc, nil)
+	if err != nil {
+		t.Fatalf("expected failed import to be returned as a result, got error: %v", err)
+	}
+
+	version, ok := obj.(*aggregationv1alpha1.CoderTemplateVersion)
+	if !ok {
+		t.Fatalf("expected *CoderTemplateVersion from lint, got %T", obj)
+	}
+	if version.Status.JobStatus != string(codersdk.ProvisionerJobFailed) {
+		t.Fatalf("expected lint job status %q, got %q", codersdk.ProvisionerJobFailed, version.Status.JobStatus)
+	}
+	if version.Status.JobError != jobError {
+		t.Fatalf("expected lint job error %q, got %q", jobError, version.Status.JobError)
+	}
+
+	activeVersionAfter, ok := state.templateActiveVersionID("acme", "starter-template")
+	if !ok {
+		t.Fatal("expected starter-template to exist after lint")
+	}
+	if activeVersionAfter != activeVersionBefore {
+		t.Fatalf("expected active version to remain %q, got %q", activeVersionBefore.String(), activeVersionAfter.String())
+	}
+}
+
+func TestTemplateLintReturnsMethodNotSupportedWhenCoderRejectsVersions(t *testing.T) {
+	t.Parallel()
+
+	server, state := newMockCoderServer(t)
+	defer server.Close()
+
+	templateStorage := NewTemplateStorage(newTestClientProvider(t, server.URL))
+	defer templateStorage.Destroy()
+	lintStorage := NewTemplateLintStorage(templateStorage)
+	ctx := namespacedContext("control-plane")
+
+	state.setTemplateVersionCreateStatusCode(http.StatusNotImplemented)
+
+	body := &aggregationv1alpha1.CoderTemplate{
+		Spec: aggregationv1alpha1.CoderTemplateSpec{
+			Files: map[string]string{"main.tf": "resource \"null_resource\" \"lint\" {}"},
+		},
+	}
+	_, err := lintStorage.Create(ctx, "acme.starter-template", body, rest.ValidateAllObjectFunc, nil)
+	if !apierrors.IsMethodNotSupported(err) {
+		t.Fatalf("expected MethodNotSupported when Coder cannot create template versions, got %v", err)
+	}
+}
+
+func TestTemplateLintRequiresTemplateSource(t *testing.T) {
+	t.Parallel()
+
+	server, _ := newMockCoderServer(t)
+	defer server.Close()
+
+	templateStorage := NewTemplateStorage(newTestClientProvider(t, server.URL))
+	defer templateStorage.Destroy()
+	lintStorage := NewTemplateLintStorage(templateStorage)
+	ctx := namespacedContext("control-plane")
+
+	_, err := lintStorage.Create(ctx, "acme.starter-template", &aggregationv1alpha1.CoderTemplate{}, rest.ValidateAllObjectFunc, nil)
+	if !apierrors.IsBadRequest(err) {
+		t.Fatalf("expected BadRequest for lint without spec.files or spec.gitSource, got %v", err)
+	}
+}
+
+func TestWorkspaceStorageCRUDWithCoderSDK(t *testing.T) {
+	t.Parallel()
+
+	server, state := newMockCoderServer(t)
+	defer server.Close()
+
+	workspaceStorage := NewWorkspaceStorage(newTestClientProvider(t, server.URL))
+	ctx := namespacedContext("control-plane")
+
+	listObj, err := workspaceStorage.List(ctx, nil)
+	if err != nil {
+		t.Fatalf("expected workspace list to succeed: %v", err)
+	}
+
+	list, ok := listObj.(*aggregationv1alpha1.CoderWorkspaceList)
+	if !ok {
+		t.Fatalf("expected *CoderWorkspaceList, got %T", listObj)
+	}
+	if len(list.Items) != 1 {
+		t.Fatalf("expected one workspace in list, got %d", len(list.Items))
+	}
+	if list.Items[0].Name != "acme.alice.dev-workspace" {
+		t.Fatalf("expected workspace name acme.alice.dev-workspace, got %q", list.Items[0].Name)
+	}
+
+	obj, err := workspaceStorage.Get(ctx, "acme.alice.dev-workspace", nil)
+	if err != nil {
+		t.Fatalf("expected workspace get to succeed: %v", err)
+	}
+
+	workspace, ok := obj.(*aggregationv1alpha1.CoderWorkspace)
+	if !ok {
+		t.Fatalf("expected *CoderWorkspace, got %T", obj)
+	}
+	if !workspace.Spec.Running {
+		t.Fatal("expected initial workspace to be running")
+	}
+
+	ttlMillis := int64(7200000)
+	autostartSchedule := "CRON_TZ=UTC 0 10 * * 1-5"
+	createObj := &aggregationv1alpha1.CoderWorkspace{
+		ObjectMeta: metav1.ObjectMeta{Name: "acme.alice.ops-workspace"},
+		Spec: aggregationv1alpha1.CoderWorkspaceSpec{
+			Organization:      "acme",
+			TemplateName:      "starter-template",
+			Running:           false,
+			TTLMillis:         &ttlMillis,
+			AutostartSchedule: &autostartSchedule,
+		},
+	}
+
+	createdObj, err := workspaceStorage.Create(ctx, createObj, rest.ValidateAllObjectFunc, nil)
+	if err != nil {
+		t.Fatalf("expected workspace create to succeed: %v", err)
+	}
+
+	createdWorkspace, ok := createdObj.(*aggregationv1alpha1.CoderWorkspace)
+	if !ok {
+		t.Fatalf("expected *CoderWorkspace from create, got %T", createdObj)
+	}
+	if createdWorkspace.Spec.Running {
+		t.Fatal("expected created workspace to be stopped when spec.running=false")
+	}
+	if !state.hasWorkspace("alice", "ops-workspace") {
+		t.Fatal("expected workspace to be persisted in mock server state")
+	}
+	if !containsTransition(state.buildTransitionsSnapshot(), codersdk.WorkspaceTransitionStop) {
+		t.Fatal("expected create to queue stop transition when running=false")
+	}
+
+	desiredWorkspace := createdWorkspace.DeepCopy()
+	desiredWorkspace.Spec.Running = true
+
+	updatedObj, created, err := workspaceStorage.Update(
+		ctx,
+		desiredWorkspace.Name,
+		testUpdatedObjectInfo{obj: desiredWorkspace},
+		nil,
+		rest.ValidateAllObjectUpdateFunc,
+		false,
+		nil,
+	)
+	if err != nil {
+		t.Fatalf("expected workspace update to succeed: %v", err)
+	}
+	if created {
+		t.Fatal("expected update created=false")
+	}
+
+	updatedWorkspace, ok := updatedObj.(*aggregationv1alpha1.CoderWorkspace)
+	if !ok {
+		t.Fatalf("expected *CoderWorkspace from update, got %T", updatedObj)
+	}
+	if !updatedWorkspace.Spec.Running {
+		t.Fatal("expected updated workspace to be running")
+	}
+	if !containsTransition(state.buildTransitionsSnapshot(), codersdk.WorkspaceTransitionStart) {
+		t.Fatal("expected update to queue start transition")
+	}
+
+	_, deleted, err := workspaceStorage.Delete(ctx, desiredWorkspace.Name, rest.ValidateAllObjectFunc, nil)
+	if err != nil {
+		t.Fatalf("expected workspace delete to succeed: %v", err)
+	}
+	if deleted {
+		t.Fatal("expected delete to report deleted=false for async delete transition")
+	}
+	if !containsTransition(state.buildTransitionsSnapshot(), codersdk.WorkspaceTransitionDelete) {
+		t.Fatal("expected delete to queue delete transition")
+	}
+}
+
+func TestWorkspaceDeleteWaitStaysBelowRequestTimeout(t *testing.T) {
+	t.Parallel()
+
+	if got := workspaceDeleteWait(&metav1.DeleteOptions{}); got != 0 {
+		t.Fatalf("expected no wait without gracePeriodSeconds, got %s", got)
+	}
+	for gracePeriodSeconds, want := range map[int64]time.Duration{
+		0:   0,
+		20:  20 * time.Second,
+		600: maxWorkspaceDeleteWait,
+	} {
+		got := workspaceDeleteWait(&metav1.DeleteOptions{GracePeriodSeconds: &gracePeriodSeconds})
+		if got != want {
+			t.Fatalf("expected wait %s for gracePeriodSeconds %d, got %s", want, gracePeriodSeconds, got)
+		}
+	}
+	if maxWorkspaceDeleteWait >= time.Minute {
+		t.Fatalf("expected delete wait cap below kube-apiserver's default 60s request timeout, got %s", maxWorkspaceDeleteWait)
+	}
+}
+
+func TestWorkspaceStorageDeleteWithGracePeriodWaitsForDeleteBuild(t *testing.T) {
+	t.Parallel()
+
+	server, state := newMockCoderServer(t)
+	defer server.Close()
+
+	workspaceStorage := NewWorkspaceStorage(newTestClientProvider(t, server.URL))
+	ctx := namespacedContext("control-plane")
+
+	state.setNextWorkspaceDeletePendingForPolls(2)
+
+	gracePeriodSeconds := int64(30)
+	_, deleted, err := workspaceStorage.Delete(
+		ctx,
+		"acme.alice.dev-workspace",
+		rest.ValidateAllObjectFunc,
+		&metav1.DeleteOptions{GracePeriodSeconds: &gracePeriodSeconds},
+	)
+	if err != nil {
+		t.Fatalf("expected workspace delete with grace period to succeed: %v", err)
+	}
+	if !deleted {
+		t.Fatal("expected delete to report deleted=true after the delete build completed")
+	}
+	if !containsTransition(state.buildTransitionsSnapshot(), codersdk.WorkspaceTransitionDelete) {
+		t.Fatal("expected delete to queue delete transition")
+	}
+
+	obj, err := workspaceStorage.Get(ctx, "acme.alice.dev-workspace", nil)
+	if err != nil {
+		t.Fatalf("expected workspace get to succeed: %v", err)
+	}
+	workspace, ok := obj.(*aggregationv1alpha1.CoderWorkspace)
+	if !ok {
+		t.Fatalf("expected *CoderWorkspace from get, got %T", obj)
+	}
+	if workspace.Status.LatestBuildStatus != string(codersdk.WorkspaceStatusDeleted) {
+		t.Fatalf("expected latest build status %q, got %q", codersdk.WorkspaceStatusDeleted, workspace.Status.LatestBuildStatus)
+	}
+}
+
+func TestWorkspaceStorageDeleteWithShortGracePeriodReturnsDeletingWorkspace(t *testing.T) {
+	t.Parallel()
+
+	server, state := newMockCoderServer(t)
+	defer server.Close()
+
+	workspaceStorage := NewWorkspaceStorage(newTestClientProvider(t, server.URL))
+	ctx := namespacedContext("control-plane")
+
+	state.setNextWorkspaceDeletePendingForPolls(100)
+
+	// kubectl delete --now sends a one-second grace period.
+	gracePeriodSeconds := int64(1)
+	obj, deleted, err := workspaceStorage.Delete(
+		ctx,
+		"acme.alice.dev-workspace",
+		rest.ValidateAllObjectFunc,
+		&metav1.DeleteOptions{GracePeriodSeconds: &gracePeriodSeconds},
+	)
+	if err != nil {
+		t.Fatalf("expected delete with an outlived grace period to succeed: %v", err)
+	}
+	if deleted {
+		t.Fatal("expected delete to report deleted=false while the delete build is running")
+	}
+	workspace, ok := obj.(*aggregationv1alpha1.CoderWorkspace)
+	if !ok {
+		t.Fatalf("expected *CoderWorkspace from delete, got %T", obj)
+	}
+	if workspace.DeletionTimestamp == nil {
+		t.Fatal("expected deleting workspace to carry a deletionTimestamp")
+	}
+	if !containsTransition(state.buildTransitionsSnapshot(), codersdk.WorkspaceTransitionDelete) {
+		t.Fatal("expected delete to queue delete transition")
+	}
+}
+
+type recordingWarningRecorder struct {
+	mu       sync.Mutex
+	warnings []string
+}
+
+func (r *recordingWarningRecorder) AddWarning(_, text string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.warnings = append(r.warnings, text)
+}
+
+func (r *recordingWarningRecorder) snapshot() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]string(nil), r.warnings...)
+}
+
+func TestWorkspaceStorageListRetriesTransientCoderErrors(t *testing.T) {
+	t.Parallel()
+
+	server, _ := newMockCoderServer(t)
+	defer server.Close()
+
+	var (
+		mu           sync.Mutex
+		listAttempts int
+	)
+	flaky := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet && r.URL.Path == "/api/v2/workspaces" {
+			mu.Lock()
+			listAttempts++
+			attempt := listAttempts
+			mu.Unlock()
+			if attempt <= 2 {
+				writeCoderError(w, http.StatusServiceUnavailable, "coderd is restarting")
+				return
+			}
+		}
+		server.Config.Handler.ServeHTTP(w, r)
+	}))
+	defer flaky.Close()
+
+	workspaceStorage := NewWorkspaceStorage(newTestClientProvider(t, flaky.URL))
+	defer workspaceStorage.Destroy()
+
+	listObj, err := workspaceStorage.List(namespacedContext("control-plane"), nil)
+	if err != nil {
+		t.Fatalf("expected workspace list to succeed after transient failures: %v", err)
+	}
+	list, ok := listObj.(*aggregationv1alpha1.CoderWorkspaceList)
+	if !ok {
+		t.Fatalf("expected *CoderWorkspaceList, got %T", listObj)
+	}
+	if len(list.Items) != 1 {
+		t.Fatalf("expected one workspace in list, got %d", len(list.Items))
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if listAttempts != 3 {
+		t.Fatalf("expected 3 list attempts, got %d", listAttempts)
+	}
+}
+
+func TestWorkspaceStorageDoesNotRetryBuildTransitions(t *testing.T) {
+	t.Parallel()
+
+	server, _ := newMockCoderServer(t)
+	defer server.Close()
+
+	var (
+		mu            sync.Mutex
+		buildAttempts int
+	)
+	flaky := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/builds") {
+			mu.Lock()
+			buildAttempts++
+			mu.Unlock()
+			writeCoderError(w, http.StatusServiceUnavailable, "coderd is restarting")
+			return
+		}
+		server.Config.Handler.ServeHTTP(w, r)
+	}))
+	defer flaky.Close()
+
+	workspaceStorage := NewWorkspaceStorage(newTestClientProvider(t, flaky.URL))
+	defer workspaceStorage.Destroy()
+
+	_, _, err := workspaceStorage.Delete(namespacedContext("control-plane"), "acme.alice.dev-workspace", nil, nil)
+	if err == nil {
+		t.Fatal("expected delete to fail when the build transition fails")
+	}
+	if !apierrors.IsServiceUnavailable(err) {
+		t.Fatalf("expected ServiceUnavailable, got %v", err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if buildAttempts != 1 {
+		t.Fatalf("expected build transition to be sent once, got %d attempts", buildAttempts)
+	}
+}
+
+func TestWorkspaceStorageGetWarnsWhenRunningDivergesFromRecentUpdate(t *testing.T) {
+	t.Parallel()
+
+	server, state := newMockCoderServer(t)
+	defer server.Close()
+
+	workspaceStorage := NewWorkspaceStorage(newTestClientProvider(t, server.URL))
+	ctx := namespacedContext("control-plane")
+	const workspaceName = "acme.alice.dev-workspace"
+
+	currentObj, err := workspaceStorage.Get(ctx, workspaceName, nil)
+	if err != nil {
+		t.Fatalf("expected workspace get to succeed: %v", err)
+	}
+	desiredWorkspace := currentObj.(*aggregationv1alpha1.CoderWorkspace).DeepCopy()
+	desiredWorkspace.Spec.Running = false
+
+	if _, _, err := workspaceStorage.Update(
+		ctx,
+		workspaceName,
+		testUpdatedObjectInfo{obj: desiredWorkspace},
+		nil,
+		rest.ValidateAllObjectUpdateFunc,
+		false,
+		nil,
+	); err != nil {
+		t.Fatalf("expected workspace update to succeed: %v", err)
+	}
+
+	alignedRecorder := &recordingWarningRecorder{}
+	if _, err := workspaceStorage.Get(warning.WithWarningRecorder(ctx, alignedRecorder), workspaceName, nil); err != nil {
+		t.Fatalf("expected workspace get to succeed: %v", err)
+	}
+	if warnings := alignedRecorder.snapshot(); len(warnings) != 0 {
+		t.Fatalf("expected no warnings while backend state matches spec, got %v", warnings)
+	}
+
+	// Simulate an out-of-band start from the Coder UI.
+	state.setWorkspaceLatestTransition("alice", "dev-workspace", codersdk.WorkspaceTransitionStart)
+
+	divergedRecorder := &recordingWarningRecorder{}
+	obj, err := workspaceStorage.Get(warning.WithWarningRecorder(ctx, divergedRecorder), workspaceName, nil)
+	if err != nil {
+		t.Fatalf("expected workspace get to succeed: %v", err)
+	}
+	if !obj.(*aggregationv1alpha1.CoderWorkspace).Spec.Running {
+		t.Fatal("expected Get to return backend running state")
+	}
+	warnings := divergedRecorder.snapshot()
+	if len(warnings) != 1 {
+		t.Fatalf("expected one divergence warning, got %v", warnings)
+	}
+	if !strings.Contains(warnings[0], "spec.running=false was set through this API") {
+		t.Fatalf("expected divergence warning to mention requested spec.running, got %q", warnings[0])
+	}
+}
+
+func TestWorkspaceStorageReportsHealthAndAgentStatus(t *testing.T) {
+	t.Parallel()
+
+	server, state := newMockCoderServer(t)
+	defer server.Close()
+
+	workspaceStorage := NewWorkspaceStorage(newTestClientProvider(t, server.URL))
+	ctx := namespacedContext("control-plane")
+	const workspaceName = "acme.alice.dev-workspace"
+
+	assertStatus := func(t *testing.T, health aggregationv1alpha1.CoderWorkspaceHealth, agentStatus string) {
+		t.Helper()
+
+		obj, err := workspaceStorage.Get(ctx, workspaceName, nil)
+		if err != nil {
+			t.Fatalf("expected workspace get to succeed: %v", err)
+		}
+		workspace := obj.(*aggregationv1alpha1.CoderWorkspace)
+		if workspace.Status.Health != health || workspace.Status.AgentStatus != agentStatus {
+			t.Fatalf(
+				"expected Get health=%q agentStatus=%q, got health=%q agentStatus=%q",
+				health, agentStatus, workspace.Status.Health, workspace.Status.AgentStatus,
+			)
+		}
+		if workspace.Status.LatestBuildStatus != string(codersdk.WorkspaceStatusRunning) {
+			t.Fatalf("expected Get latestBuildStatus %q, got %q", codersdk.WorkspaceStatusRunning, workspace.Status.LatestBuildStatus)
+		}
+
+		listObj, err := workspaceStorage.List(ctx, nil)
+		if err != nil {
+			t.Fatalf("expected workspace list to succeed: %v", err)
+		}
+		list := listObj.(*aggregationv1alpha1.CoderWorkspaceList)
+		if len(list.Items) != 1 {
+			t.Fatalf("expected one listed workspace, got %d", len(list.Items))
+		}
+		if list.Items[0].Status.Health != health || list.Items[0].Status.AgentStatus != agentStatus {
+			t.Fatalf(
+				"expected List health=%q agentStatus=%q, got health=%q agentStatus=%q",
+				health, agentStatus, list.Items[0].Status.Health, list.Items[0].Status.AgentStatus,
+			)
+		}
+	}
+
+	state.setWorkspaceAgents("alice", "dev-workspace",
+		codersdk.WorkspaceAgent{ID: uuid.New(), Name: "main", Status: codersdk.WorkspaceAgentConnected, Health: codersdk.WorkspaceAgentHealth{Healthy: true}},
+	)
+	assertStatus(t, aggregationv1alpha1.CoderWorkspaceHealthHealthy, string(codersdk.WorkspaceAgentConnected))
+
+	state.setWorkspaceAgents("alice", "dev-workspace",
+		codersdk.WorkspaceAgent{ID: uuid.New(), Name: "main", Status: codersdk.WorkspaceAgentConnected, Health: codersdk.WorkspaceAgentHealth{Healthy: true}},
+		codersdk.WorkspaceAgent{ID: uuid.New(), Name: "sidecar", Status: codersdk.WorkspaceAgentDisconnected, Health: codersdk.WorkspaceAgentHealth{Reason: "agent is not connected"}},
+	)
+	assertStatus(t, aggregationv1alpha1.CoderWorkspaceHealthUnhealthy, string(codersdk.WorkspaceAgentDisconnected))
+}
+
+func TestWorkspaceStorageReportsLastBuildTiming(t *testing.T) {
+	t.Parallel()
+
+	server, state := newMockCoderServer(t)
+	defer server.Close()
+
+	startedAt := time.Date(2025, time.March, 4, 10, 0, 0, 0, time.UTC)
+	completedAt := startedAt.Add(2*time.Minute + 5*time.Second)
+	state.setWorkspaceBuildTiming("alice", "dev-workspace", startedAt, completedAt, 12)
+
+	workspaceStorage := NewWorkspaceStorage(newTestClientProvider(t, server.URL))
+	ctx := namespacedContext("control-plane")
+
+	obj, err := workspaceStorage.Get(ctx, "acme.alice.dev-workspace", nil)
+	if err != nil {
+		t.Fatalf("expected workspace get to succeed: %v", err)
+	}
+	listObj, err := workspaceStorage.List(ctx, nil)
+	if err != nil {
+		t.Fatalf("expected workspace list to succeed: %v", err)
+	}
+	list := listObj.(*aggregationv1alpha1.CoderWorkspaceList)
+	if len(list.Items) != 1 {
+		t.Fatalf("expected one listed workspace, got %d", len(list.Items))
+	}
+
+	for source, status := range map[string]aggregationv1alpha1.CoderWorkspaceStatus{
+		"Get":  obj.(*aggregationv1alpha1.CoderWorkspace).Status,
+		"List": list.Items[0].Status,
+	} {
+		if status.LastBuildStartedAt == nil || !status.LastBuildStartedAt.Time.Equal(startedAt) {
+			t.Fatalf("expected %s lastBuildStartedAt %s, got %v", source, startedAt, status.LastBuildStartedAt)
+		}
+		if status.LastBuildCompletedAt == nil || !status.LastBuildCompletedAt.Time.Equal(completedAt) {
+			t.Fatalf("expected %s lastBuildCompletedAt %s, got %v", source, completedAt, status.LastBuildCompletedAt)
+		}
+		if status.LastBuildDurationSeconds == nil || *status.LastBuildDurationSeconds != 125 {
+			t.Fatalf("expected %s lastBuildDurationSeconds 125, got %v", source, status.LastBuildDurationSeconds)
+		}
+		if status.DailyCost != 12 {
+			t.Fatalf("expected %s dailyCost 12, got %d", source, status.DailyCost)
+		}
+	}
+}
+
+func TestWorkspaceStorageGetDoesNotWarnWithoutRecentUpdate(t *testing.T) {
+	t.Parallel()
+
+	server, _ := newMockCoderServer(t)
+	defer server.Close()
+
+	workspaceStorage := NewWorkspaceStorage(newTestClientProvider(t, server.URL))
+	recorder := &recordingWarningRecorder{}
+	ctx := warning.WithWarningRecorder(namespacedContext("control-plane"), recorder)
+
+	if _, err := workspaceStorage.Get(ctx, "acme.alice.dev-workspace", nil); err != nil {
+		t.Fatalf("expected workspace get to succeed: %v", err)
+	}
+	if warnings := recorder.snapshot(); len(warnings) != 0 {
+		t.Fatalf("expected no warnings without a recorded aggregated operation, got %v", warnings)
+	}
+}
+
+func TestWorkspaceStorageCreateRejectsTemplateVersionIDFromDifferentTemplate(t *testing.T) {
+	t.Parallel()
+
+	server, state := newMockCoderServer(t)
+	defer server.Close()
+
+	workspaceStorage := NewWorkspaceStorage(newTestClientProvider(t, server.URL))
+	ctx := namespacedContext("control-plane")
+
+	mismatchedTemplateVersionID := uuid.New()
+	state.setTemplateVersionTemplateID(mismatchedTemplateVersionID, uuid.New())
+
+	createObj := &aggregationv1alpha1.CoderWorkspace{
+		ObjectMeta: metav1.ObjectMeta{Name: "acme.alice.mismatch-template-version-workspace"},
+		Spec: aggregationv1alpha1.CoderWorkspaceSpec{
+			Organization:      "acme",
+			TemplateName:      "starter-template",
+			TemplateVersionID: mismatchedTemplateVersionID.String(),
+			Running:           true,
+		},
+	}
+
+	_, err := workspaceStorage.Create(ctx, createObj, rest.ValidateAllObjectFunc, nil)
+	if !apierrors.IsBadRequest(err) {
+		t.Fatalf("expected BadRequest when templateVersionID belongs to a different template, got %v", err)
+	}
+
+	expectedMessage := fmt.Sprintf(
+		"spec.templateVersionID %q does not belong to template %q",
+		mismatchedTemplateVersionID.String(),
+		"starter-template",
+	)
+	if err == nil || !strings.Contains(err.Error(), expectedMessage) {
+		t.Fatalf("expected mismatched templateVersionID error message %q, got %v", expectedMessage, err)
+	}
+	if state.hasWorkspace("alice", "mismatch-template-version-workspace") {
+		t.Fatal("expected workspace create to be rejected before persistence")
+	}
+	if transitions := state.buildTransitionsSnapshot(); len(transitions) != 0 {
+		t.Fatalf("expected no workspace build transitions on mismatched templateVersionID, got %v", transitions)
+	}
+}
+
+func TestWorkspaceStorageCreateAllowsMatchingTemplateVersionID(t *testing.T) {
+	t.Parallel()
+
+	server, state := newMockCoderServer(t)
+	defer server.Close()
+
+	workspaceStorage := NewWorkspaceStorage(newTestClientProvider(t, server.URL))
 	ctx := namespacedContext("control-plane")
 
-	currentObj, err := templateStorage.Get(ctx, "acme.starter-template", nil)
+	templateVersionID, ok := state.workspaceLatestBuildTemplateVersionID("alice", "dev-workspace")
+	if !ok {
+		t.Fatal("expected workspace template version ID in mock server state")
+	}
+	if templateVersionID == uuid.Nil {
+		t.Fatal("expected workspace template version ID to be non-nil")
+	}
+
+	createObj := &aggregationv1alpha1.CoderWorkspace{
+		ObjectMeta: metav1.ObjectMeta{Name: "acme.alice.matching-template-version-workspace"},
+		Spec: aggregationv1alpha1.CoderWorkspaceSpec{
+			Organization:      "acme",
+			TemplateName:      "starter-template",
+			TemplateVersionID: templateVersionID.String(),
+			Running:           true,
+		},
+	}
+
+	createdObj, err := workspaceStorage.Create(ctx, createObj, rest.ValidateAllObjectFunc, nil)
 	if err != nil {
-		t.Fatalf("expected template get to succeed: %v", err)
+		t.Fatalf("expected workspace create to succeed for matching templateVersionID: %v", err)
 	}
 
-	currentTemplate, ok := currentObj.(*aggregationv1alpha1.CoderTemplate)
+	createdWorkspace, ok := createdObj.(*aggregationv1alpha1.CoderWorkspace)
 	if !ok {
-		t.Fatalf("expected *CoderTemplate from get, got %T", currentObj)
+		t.Fatalf("expected *CoderWorkspace from create, got %T", createdObj)
 	}
-
-	desiredTemplate := currentTemplate.DeepCopy()
-	desiredTemplate.Spec.Running = !currentTemplate.Spec.Running
-	desiredTemplate.ResourceVersion = currentTemplate.ResourceVersion + "-stale"
-
-	_, _, err = templateStorage.Update(
-		ctx,
-		desiredTemplate.Name,
-		testUpdatedObjectInfo{obj: desiredTemplate},
-		nil,
-		rest.ValidateAllObjectUpdateFunc,
-		false,
-		nil,
-	)
-	if !apierrors.IsConflict(err) {
-		t.Fatalf("expected Conflict for stale resourceVersion, got %v", err)
+	if createdWorkspace.Spec.TemplateVersionID != templateVersionID.String() {
+		t.Fatalf(
+			"expected created spec.templateVersionID %q, got %q",
+			templateVersionID.String(),
+			createdWorkspace.Spec.TemplateVersionID,
+		)
 	}
-	if err == nil || !strings.Contains(err.Error(), "resource version mismatch") {
-		t.Fatalf("expected stale resourceVersion error message, got %v", err)
+	if !state.hasWorkspace("alice", "matching-template-version-workspace") {
+		t.Fatal("expected workspace to be persisted in mock server state")
+	}
+	if transitions := state.buildTransitionsSnapshot(); len(transitions) != 0 {
+		t.Fatalf("expected no workspace build transitions when spec.running=true, got %v", transitions)
 	}
 }
 
-func TestTemplateStorageUpdateRejectsMismatchedName(t *testing.T) {
+func TestWorkspaceStorageCreateUseActiveTemplateVersionPinsVersion(t *testing.T) {
 	t.Parallel()
 
-	server, _ := newMockCoderServer(t)
+	server, state := newMockCoderServer(t)
 	defer server.Close()
 
-	templateStorage := NewTemplateStorage(newTestClientProvider(t, server.URL))
+	workspaceStorage := NewWorkspaceStorage(newTestClientProvider(t, server.URL))
 	ctx := namespacedContext("control-plane")
 
-	currentObj, err := templateStorage.Get(ctx, "acme.starter-template", nil)
-	if err != nil {
-		t.Fatalf("expected template get to succeed: %v", err)
+	activeVersionID, ok := state.templateActiveVersionID("acme", "starter-template")
+	if !ok {
+		t.Fatal("expected starter-template active version in mock server state")
 	}
 
-	currentTemplate, ok := currentObj.(*aggregationv1alpha1.CoderTemplate)
+	createObj := &aggregationv1alpha1.CoderWorkspace{
+		ObjectMeta: metav1.ObjectMeta{Name: "acme.alice.pinned-workspace"},
+		Spec: aggregationv1alpha1.CoderWorkspaceSpec{
+			Organization:             "acme",
+			TemplateName:             "starter-template",
+			UseActiveTemplateVersion: true,
+			Running:                  true,
+		},
+	}
+
+	createdObj, err := workspaceStorage.Create(ctx, createObj, rest.ValidateAllObjectFunc, nil)
+	if err != nil {
+		t.Fatalf("expected workspace create to succeed: %v", err)
+	}
+	createdWorkspace, ok := createdObj.(*aggregationv1alpha1.CoderWorkspace)
 	if !ok {
-		t.Fatalf("expected *CoderTemplate from get, got %T", currentObj)
+		t.Fatalf("expected *CoderWorkspace from create, got %T", createdObj)
+	}
+	if createdWorkspace.Status.TemplateVersionID != activeVersionID.String() {
+		t.Fatalf("expected created status.templateVersionID %q, got %q", activeVersionID.String(), createdWorkspace.Status.TemplateVersionID)
 	}
 
-	desiredTemplate := currentTemplate.DeepCopy()
-	desiredTemplate.Spec.Running = !currentTemplate.Spec.Running
-	desiredTemplate.Name = "acme.other-template"
+	createRequests := state.workspaceCreateRequestsSnapshot()
+	if len(createRequests) != 1 {
+		t.Fatalf("expected one workspace create request, got %d", len(createRequests))
+	}
+	if createRequests[0].TemplateVersionID != activeVersionID || createRequests[0].TemplateID != uuid.Nil {
+		t.Fatalf(
+			"expected create request pinned to template version %q without a template ID, got version %q and template %q",
+			activeVersionID.String(),
+			createRequests[0].TemplateVersionID.String(),
+			createRequests[0].TemplateID.String(),
+		)
+	}
 
-	_, _, err = templateStorage.Update(
+	// Promoting a new version must not move the pinned workspace on rebuild.
+	templateID, ok := state.templateIDByName("acme", "starter-template")
+	if !ok {
+		t.Fatal("expected starter-template in mock server state")
+	}
+	templateStorage := NewTemplateStorage(newTestClientProvider(t, server.URL))
+	defer templateStorage.Destroy()
+	promotedVersionID := state.addSucceededTemplateVersion(templateID)
+	if _, err := NewTemplateVersionPromoteStorage(templateStorage).Create(
 		ctx,
-		currentTemplate.Name,
-		testUpdatedObjectInfo{obj: desiredTemplate},
+		"acme.starter-template."+promotedVersionID.String(),
+		&aggregationv1alpha1.CoderTemplateVersion{},
+		rest.ValidateAllObjectFunc,
+		nil,
+	); err != nil {
+		t.Fatalf("expected template version promotion to succeed: %v", err)
+	}
+
+	stoppedWorkspace := createdWorkspace.DeepCopy()
+	stoppedWorkspace.Spec.Running = false
+	if _, _, err := workspaceStorage.Update(
+		ctx,
+		stoppedWorkspace.Name,
+		testUpdatedObjectInfo{obj: stoppedWorkspace},
 		nil,
 		rest.ValidateAllObjectUpdateFunc,
 		false,
 		nil,
-	)
-	if !apierrors.IsBadRequest(err) {
-		t.Fatalf("expected BadRequest for mismatched name, got %v", err)
+	); err != nil {
+		t.Fatalf("expected workspace stop to succeed: %v", err)
 	}
-	if err == nil || !strings.Contains(err.Error(), "updated object metadata.name \"acme.other-template\" must match request name \"acme.starter-template\"") {
-		t.Fatalf("expected mismatched name error message, got %v", err)
+
+	gotObj, err := workspaceStorage.Get(ctx, createObj.Name, nil)
+	if err != nil {
+		t.Fatalf("expected workspace get to succeed: %v", err)
+	}
+	gotWorkspace, ok := gotObj.(*aggregationv1alpha1.CoderWorkspace)
+	if !ok {
+		t.Fatalf("expected *CoderWorkspace from get, got %T", gotObj)
+	}
+	if gotWorkspace.Status.TemplateVersionID != activeVersionID.String() {
+		t.Fatalf("expected status.templateVersionID to stay pinned to %q, got %q", activeVersionID.String(), gotWorkspace.Status.TemplateVersionID)
 	}
 }
 
-func TestTemplateStorageUpdateRejectsMismatchedNamespace(t *testing.T) {
+func TestWorkspaceStorageCreateRejectsUseActiveTemplateVersionWithTemplateVersionID(t *testing.T) {
 	t.Parallel()
 
-	server, _ := newMockCoderServer(t)
+	server, state := newMockCoderServer(t)
 	defer server.Close()
 
-	templateStorage := NewTemplateStorage(newTestClientProvider(t, server.URL))
+	workspaceStorage := NewWorkspaceStorage(newTestClientProvider(t, server.URL))
 	ctx := namespacedContext("control-plane")
 
-	currentObj, err := templateStorage.Get(ctx, "acme.starter-template", nil)
-	if err != nil {
-		t.Fatalf("expected template get to succeed: %v", err)
-	}
-
-	currentTemplate, ok := currentObj.(*aggregationv1alpha1.CoderTemplate)
+	activeVersionID, ok := state.templateActiveVersionID("acme", "starter-template")
 	if !ok {
-		t.Fatalf("expected *CoderTemplate from get, got %T", currentObj)
+		t.Fatal("expected starter-template active version in mock server state")
 	}
 
-	desiredTemplate := currentTemplate.DeepCopy()
-	desiredTemplate.Spec.Running = !currentTemplate.Spec.Running
-	desiredTemplate.Namespace = "other-namespace"
+	createObj := &aggregationv1alpha1.CoderWorkspace{
+		ObjectMeta: metav1.ObjectMeta{Name: "acme.alice.conflicting-pin-workspace"},
+		Spec: aggregationv1alpha1.CoderWorkspaceSpec{
+			Organization:             "acme",
+			TemplateName:             "starter-template",
+			TemplateVersionID:        activeVersionID.String(),
+			UseActiveTemplateVersion: true,
+		},
+	}
 
-	_, _, err = templateStorage.Update(
-		ctx,
-		desiredTemplate.Name,
-		testUpdatedObjectInfo{obj: desiredTemplate},
-		nil,
-		rest.ValidateAllObjectUpdateFunc,
-		false,
-		nil,
-	)
+	_, err := workspaceStorage.Create(ctx, createObj, rest.ValidateAllObjectFunc, nil)
 	if !apierrors.IsBadRequest(err) {
-		t.Fatalf("expected BadRequest for mismatched namespace, got %v", err)
+		t.Fatalf("expected BadRequest for useActiveTemplateVersion with templateVersionID, got %v", err)
 	}
-	if err == nil || !strings.Contains(err.Error(), "metadata.namespace \"other-namespace\" does not match request namespace \"control-plane\"") {
-		t.Fatalf("expected mismatched namespace error message, got %v", err)
+	if state.hasWorkspace("alice", "conflicting-pin-workspace") {
+		t.Fatal("expected workspace create to be rejected before persistence")
 	}
 }
 
-func TestWorkspaceStorageCRUDWithCoderSDK(t *testing.T) {
+func TestWorkspaceStorageCreateWithPreset(t *testing.T) {
 	t.Parallel()
 
 	server, state := newMockCoderServer(t)
@@ -1783,153 +4679,310 @@ func TestWorkspaceStorageCRUDWithCoderSDK(t *testing.T) {
 	workspaceStorage := NewWorkspaceStorage(newTestClientProvider(t, server.URL))
 	ctx := namespacedContext("control-plane")
 
-	listObj, err := workspaceStorage.List(ctx, nil)
-	if err != nil {
-		t.Fatalf("expected workspace list to succeed: %v", err)
+	activeVersionID, ok := state.templateActiveVersionID("acme", "starter-template")
+	if !ok {
+		t.Fatal("expected starter-template active version in mock server state")
+	}
+	templateID, ok := state.templateIDByName("acme", "starter-template")
+	if !ok {
+		t.Fatal("expected starter-template in mock server state")
+	}
+	presetID := state.addTemplateVersionPreset(activeVersionID, "large", map[string]string{"cpu": "8"})
+	otherVersionID := state.addSucceededTemplateVersion(templateID)
+	state.addTemplateVersionPreset(otherVersionID, "gpu", map[string]string{"gpu": "true"})
+
+	newWorkspace := func(name, presetName string) *aggregationv1alpha1.CoderWorkspace {
+		return &aggregationv1alpha1.CoderWorkspace{
+			ObjectMeta: metav1.ObjectMeta{Name: "acme.alice." + name},
+			Spec: aggregationv1alpha1.CoderWorkspaceSpec{
+				Organization: "acme",
+				TemplateName: "starter-template",
+				PresetName:   presetName,
+				Running:      true,
+			},
+		}
 	}
 
-	list, ok := listObj.(*aggregationv1alpha1.CoderWorkspaceList)
+	createdObj, err := workspaceStorage.Create(ctx, newWorkspace("preset-workspace", "large"), rest.ValidateAllObjectFunc, nil)
+	if err != nil {
+		t.Fatalf("expected workspace create with preset to succeed: %v", err)
+	}
+	created, ok := createdObj.(*aggregationv1alpha1.CoderWorkspace)
 	if !ok {
-		t.Fatalf("expected *CoderWorkspaceList, got %T", listObj)
+		t.Fatalf("expected *CoderWorkspace from create, got %T", createdObj)
 	}
-	if len(list.Items) != 1 {
-		t.Fatalf("expected one workspace in list, got %d", len(list.Items))
+	if created.Status.PresetName != "large" {
+		t.Fatalf("expected created status.presetName %q, got %q", "large", created.Status.PresetName)
 	}
-	if list.Items[0].Name != "acme.alice.dev-workspace" {
-		t.Fatalf("expected workspace name acme.alice.dev-workspace, got %q", list.Items[0].Name)
+
+	requests := state.workspaceCreateRequestsSnapshot()
+	if len(requests) != 1 {
+		t.Fatalf("expected one workspace create request, got %d", len(requests))
+	}
+	if requests[0].TemplateVersionPresetID != presetID {
+		t.Fatalf("expected preset ID %q in create request, got %q", presetID, requests[0].TemplateVersionPresetID)
+	}
+	if len(requests[0].RichParameterValues) != 0 {
+		t.Fatalf("expected Coder to apply preset parameters, got rich parameter values %+v", requests[0].RichParameterValues)
 	}
 
-	obj, err := workspaceStorage.Get(ctx, "acme.alice.dev-workspace", nil)
+	fetchedObj, err := workspaceStorage.Get(ctx, "acme.alice.preset-workspace", &metav1.GetOptions{})
 	if err != nil {
 		t.Fatalf("expected workspace get to succeed: %v", err)
 	}
+	fetched, ok := fetchedObj.(*aggregationv1alpha1.CoderWorkspace)
+	if !ok {
+		t.Fatalf("expected *CoderWorkspace from get, got %T", fetchedObj)
+	}
+	if fetched.Status.PresetName != "large" {
+		t.Fatalf("expected fetched status.presetName %q, got %q", "large", fetched.Status.PresetName)
+	}
 
-	workspace, ok := obj.(*aggregationv1alpha1.CoderWorkspace)
+	// The preset name is informational; failing to list presets must not
+	// fail the get.
+	state.setPresetListStatusCode(http.StatusInternalServerError)
+	fetchedObj, err = workspaceStorage.Get(ctx, "acme.alice.preset-workspace", &metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("expected workspace get to succeed when presets cannot be listed: %v", err)
+	}
+	fetched, ok = fetchedObj.(*aggregationv1alpha1.CoderWorkspace)
 	if !ok {
-		t.Fatalf("expected *CoderWorkspace, got %T", obj)
+		t.Fatalf("expected *CoderWorkspace from get, got %T", fetchedObj)
 	}
-	if !workspace.Spec.Running {
-		t.Fatal("expected initial workspace to be running")
+	if fetched.Status.PresetName != "" {
+		t.Fatalf("expected empty status.presetName when presets cannot be listed, got %q", fetched.Status.PresetName)
 	}
+	state.setPresetListStatusCode(0)
 
-	ttlMillis := int64(7200000)
-	autostartSchedule := "CRON_TZ=UTC 0 10 * * 1-5"
-	createObj := &aggregationv1alpha1.CoderWorkspace{
-		ObjectMeta: metav1.ObjectMeta{Name: "acme.alice.ops-workspace"},
-		Spec: aggregationv1alpha1.CoderWorkspaceSpec{
-			Organization:      "acme",
-			TemplateName:      "starter-template",
-			Running:           false,
-			TTLMillis:         &ttlMillis,
-			AutostartSchedule: &autostartSchedule,
-		},
+	for _, tc := range []struct {
+		name       string
+		presetName string
+	}{
+		{name: "unknown-preset-workspace", presetName: "missing"},
+		{name: "foreign-preset-workspace", presetName: "gpu"},
+	} {
+		_, err := workspaceStorage.Create(ctx, newWorkspace(tc.name, tc.presetName), rest.ValidateAllObjectFunc, nil)
+		if !apierrors.IsBadRequest(err) {
+			t.Fatalf("expected BadRequest for preset %q, got %v", tc.presetName, err)
+		}
+		if state.hasWorkspace("alice", tc.name) {
+			t.Fatalf("expected workspace %q to be rejected before persistence", tc.name)
+		}
 	}
+}
 
-	createdObj, err := workspaceStorage.Create(ctx, createObj, rest.ValidateAllObjectFunc, nil)
+func TestWorkspaceStorageCreateForOtherUser(t *testing.T) {
+	t.Parallel()
+
+	server, state := newMockCoderServer(t)
+	defer server.Close()
+
+	workspaceStorage := NewWorkspaceStorage(newTestClientProvider(t, server.URL))
+	ctx := namespacedContext("control-plane")
+
+	createdObj, err := workspaceStorage.Create(ctx, &aggregationv1alpha1.CoderWorkspace{
+		ObjectMeta: metav1.ObjectMeta{Name: "acme.bob.dev"},
+		Spec: aggregationv1alpha1.CoderWorkspaceSpec{
+			Organization: "acme",
+			TemplateName: "starter-template",
+			Running:      true,
+		},
+	}, rest.ValidateAllObjectFunc, nil)
 	if err != nil {
-		t.Fatalf("expected workspace create to succeed: %v", err)
+		t.Fatalf("expected workspace create for bob to succeed: %v", err)
 	}
-
 	createdWorkspace, ok := createdObj.(*aggregationv1alpha1.CoderWorkspace)
 	if !ok {
 		t.Fatalf("expected *CoderWorkspace from create, got %T", createdObj)
 	}
-	if createdWorkspace.Spec.Running {
-		t.Fatal("expected created workspace to be stopped when spec.running=false")
+	if createdWorkspace.Name != "acme.bob.dev" {
+		t.Fatalf("expected created workspace name acme.bob.dev, got %q", createdWorkspace.Name)
 	}
-	if !state.hasWorkspace("alice", "ops-workspace") {
-		t.Fatal("expected workspace to be persisted in mock server state")
+	if createdWorkspace.Status.OwnerName != "bob" {
+		t.Fatalf("expected created workspace owner bob, got %q", createdWorkspace.Status.OwnerName)
 	}
-	if !containsTransition(state.buildTransitionsSnapshot(), codersdk.WorkspaceTransitionStop) {
-		t.Fatal("expected create to queue stop transition when running=false")
+	if !state.hasWorkspace("bob", "dev") {
+		t.Fatal("expected workspace dev to be owned by bob in mock server state")
+	}
+	if state.hasWorkspace("alice", "dev") {
+		t.Fatal("expected no workspace dev owned by alice")
 	}
 
-	desiredWorkspace := createdWorkspace.DeepCopy()
-	desiredWorkspace.Spec.Running = true
+	_, err = workspaceStorage.Create(ctx, &aggregationv1alpha1.CoderWorkspace{
+		ObjectMeta: metav1.ObjectMeta{Name: "acme.carol.dev"},
+		Spec: aggregationv1alpha1.CoderWorkspaceSpec{
+			Organization: "acme",
+			TemplateName: "starter-template",
+			Running:      true,
+		},
+	}, rest.ValidateAllObjectFunc, nil)
+	if !apierrors.IsBadRequest(err) {
+		t.Fatalf("expected BadRequest for unknown user carol, got %v", err)
+	}
+	if !strings.Contains(err.Error(), `"carol"`) {
+		t.Fatalf("expected error to name user carol, got %v", err)
+	}
+	if state.hasWorkspace("carol", "dev") {
+		t.Fatal("expected no workspace to be created for unknown user carol")
+	}
+}
+
+func TestWorkspaceStorageCreateReportsCoderValidationErrorsAsCauses(t *testing.T) {
+	t.Parallel()
+
+	server, _ := newMockCoderServer(t)
+	defer server.Close()
+
+	workspaceStorage := NewWorkspaceStorage(newTestClientProvider(t, server.URL))
+	ctx := namespacedContext("control-plane")
+
+	invalidSchedule := "0 9 * * 1-5"
+	_, err := workspaceStorage.Create(ctx, &aggregationv1alpha1.CoderWorkspace{
+		ObjectMeta: metav1.ObjectMeta{Name: "acme.alice.invalid-schedule"},
+		Spec: aggregationv1alpha1.CoderWorkspaceSpec{
+			Organization:      "acme",
+			TemplateName:      "starter-template",
+			Running:           true,
+			AutostartSchedule: &invalidSchedule,
+		},
+	}, rest.ValidateAllObjectFunc, nil)
+	if !apierrors.IsInvalid(err) {
+		t.Fatalf("expected Invalid for a Coder validation error, got %v", err)
+	}
+	assertTopLevelStatusError(t, err)
+
+	statusErr := err.(*apierrors.StatusError)
+	details := statusErr.ErrStatus.Details
+	if details == nil || details.Name != "acme.alice.invalid-schedule" || details.Kind != "CoderWorkspace" {
+		t.Fatalf("expected status details for the workspace, got %#v", details)
+	}
+	want := []metav1.StatusCause{{
+		Type:    metav1.CauseTypeFieldValueInvalid,
+		Field:   "spec.autostartSchedule",
+		Message: "parse schedule: schedule must specify a CRON_TZ timezone",
+	}}
+	if !reflect.DeepEqual(details.Causes, want) {
+		t.Fatalf("expected causes %#v, got %#v", want, details.Causes)
+	}
+}
+
+func TestWorkspaceStorageCreateRejectsUnknownOrganization(t *testing.T) {
+	t.Parallel()
+
+	server, state := newMockCoderServer(t)
+	defer server.Close()
+
+	workspaceStorage := NewWorkspaceStorage(newTestClientProvider(t, server.URL))
+	ctx := namespacedContext("control-plane")
+
+	_, err := workspaceStorage.Create(ctx, &aggregationv1alpha1.CoderWorkspace{
+		ObjectMeta: metav1.ObjectMeta{Name: "ghost-org.alice.dev"},
+		Spec: aggregationv1alpha1.CoderWorkspaceSpec{
+			Organization: "ghost-org",
+			TemplateName: "starter-template",
+			Running:      true,
+		},
+	}, rest.ValidateAllObjectFunc, nil)
+	if !apierrors.IsBadRequest(err) {
+		t.Fatalf("expected BadRequest for unknown organization, got %v", err)
+	}
+	assertTopLevelStatusError(t, err)
+	if !strings.Contains(err.Error(), `organization "ghost-org"`) {
+		t.Fatalf("expected error to name organization ghost-org, got %v", err)
+	}
+	if requests := state.workspaceCreateRequestsSnapshot(); len(requests) != 0 {
+		t.Fatalf("expected no workspace create request, got %d", len(requests))
+	}
+}
+
+func TestWorkspaceStorageUpdateForceAllowCreateCreatesWhenMissing(t *testing.T) {
+	t.Parallel()
+
+	server, state := newMockCoderServer(t)
+	defer server.Close()
+
+	workspaceStorage := NewWorkspaceStorage(newTestClientProvider(t, server.URL))
+	ctx := namespacedContext("control-plane")
+
+	desiredWorkspace := &aggregationv1alpha1.CoderWorkspace{
+		Spec: aggregationv1alpha1.CoderWorkspaceSpec{
+			Organization: "acme",
+			TemplateName: "starter-template",
+			Running:      false,
+		},
+	}
 
 	updatedObj, created, err := workspaceStorage.Update(
 		ctx,
-		desiredWorkspace.Name,
+		"acme.alice.ssa-workspace",
 		testUpdatedObjectInfo{obj: desiredWorkspace},
-		nil,
+		rest.ValidateAllObjectFunc,
 		rest.ValidateAllObjectUpdateFunc,
-		false,
+		true,
 		nil,
 	)
 	if err != nil {
-		t.Fatalf("expected workspace update to succeed: %v", err)
+		t.Fatalf("expected create-on-update to succeed for missing workspace: %v", err)
 	}
-	if created {
-		t.Fatal("expected update created=false")
+	if !created {
+		t.Fatal("expected update created=true when forceAllowCreate creates a workspace")
 	}
 
-	updatedWorkspace, ok := updatedObj.(*aggregationv1alpha1.CoderWorkspace)
+	createdWorkspace, ok := updatedObj.(*aggregationv1alpha1.CoderWorkspace)
 	if !ok {
 		t.Fatalf("expected *CoderWorkspace from update, got %T", updatedObj)
 	}
-	if !updatedWorkspace.Spec.Running {
-		t.Fatal("expected updated workspace to be running")
-	}
-	if !containsTransition(state.buildTransitionsSnapshot(), codersdk.WorkspaceTransitionStart) {
-		t.Fatal("expected update to queue start transition")
-	}
-
-	_, deleted, err := workspaceStorage.Delete(ctx, desiredWorkspace.Name, rest.ValidateAllObjectFunc, nil)
-	if err != nil {
-		t.Fatalf("expected workspace delete to succeed: %v", err)
+	if createdWorkspace.Name != "acme.alice.ssa-workspace" {
+		t.Fatalf("expected created workspace name acme.alice.ssa-workspace, got %q", createdWorkspace.Name)
 	}
-	if deleted {
-		t.Fatal("expected delete to report deleted=false for async delete transition")
+	if !state.hasWorkspace("alice", "ssa-workspace") {
+		t.Fatal("expected create-on-update workspace to be persisted in mock server state")
 	}
-	if !containsTransition(state.buildTransitionsSnapshot(), codersdk.WorkspaceTransitionDelete) {
-		t.Fatal("expected delete to queue delete transition")
+	if !containsTransition(state.buildTransitionsSnapshot(), codersdk.WorkspaceTransitionStop) {
+		t.Fatal("expected create-on-update workspace with spec.running=false to queue stop transition")
 	}
 }
 
-func TestWorkspaceStorageCreateRejectsTemplateVersionIDFromDifferentTemplate(t *testing.T) {
+func TestWorkspaceStorageUpdateRejectsNonRunningSpecChanges(t *testing.T) {
 	t.Parallel()
 
-	server, state := newMockCoderServer(t)
+	server, _ := newMockCoderServer(t)
 	defer server.Close()
 
 	workspaceStorage := NewWorkspaceStorage(newTestClientProvider(t, server.URL))
 	ctx := namespacedContext("control-plane")
 
-	mismatchedTemplateVersionID := uuid.New()
-	state.setTemplateVersionTemplateID(mismatchedTemplateVersionID, uuid.New())
-
-	createObj := &aggregationv1alpha1.CoderWorkspace{
-		ObjectMeta: metav1.ObjectMeta{Name: "acme.alice.mismatch-template-version-workspace"},
-		Spec: aggregationv1alpha1.CoderWorkspaceSpec{
-			Organization:      "acme",
-			TemplateName:      "starter-template",
-			TemplateVersionID: mismatchedTemplateVersionID.String(),
-			Running:           true,
-		},
+	currentObj, err := workspaceStorage.Get(ctx, "acme.alice.dev-workspace", nil)
+	if err != nil {
+		t.Fatalf("expected workspace get to succeed: %v", err)
 	}
 
-	_, err := workspaceStorage.Create(ctx, createObj, rest.ValidateAllObjectFunc, nil)
-	if !apierrors.IsBadRequest(err) {
-		t.Fatalf("expected BadRequest when templateVersionID belongs to a different template, got %v", err)
+	currentWorkspace, ok := currentObj.(*aggregationv1alpha1.CoderWorkspace)
+	if !ok {
+		t.Fatalf("expected *CoderWorkspace from get, got %T", currentObj)
 	}
 
-	expectedMessage := fmt.Sprintf(
-		"spec.templateVersionID %q does not belong to template %q",
-		mismatchedTemplateVersionID.String(),
-		"starter-template",
+	desiredWorkspace := currentWorkspace.DeepCopy()
+	desiredWorkspace.Spec.TemplateName = "renamed-template"
+
+	_, _, err = workspaceStorage.Update(
+		ctx,
+		desiredWorkspace.Name,
+		testUpdatedObjectInfo{obj: desiredWorkspace},
+		nil,
+		rest.ValidateAllObjectUpdateFunc,
+		false,
+		nil,
 	)
-	if err == nil || !strings.Contains(err.Error(), expectedMessage) {
-		t.Fatalf("expected mismatched templateVersionID error message %q, got %v", expectedMessage, err)
-	}
-	if state.hasWorkspace("alice", "mismatch-template-version-workspace") {
-		t.Fatal("expected workspace create to be rejected before persistence")
+	if !apierrors.IsBadRequest(err) {
+		t.Fatalf("expected BadRequest when changing immutable workspace spec fields, got %v", err)
 	}
-	if transitions := state.buildTransitionsSnapshot(); len(transitions) != 0 {
-		t.Fatalf("expected no workspace build transitions on mismatched templateVersionID, got %v", transitions)
+	if err == nil || !strings.Contains(err.Error(), "spec.running") {
+		t.Fatalf("expected immutable-field error mentioning spec.running, got %v", err)
 	}
 }
 
-func TestWorkspaceStorageCreateAllowsMatchingTemplateVersionID(t *testing.T) {
+func TestWorkspaceStorageUpdateAllowsPinnedTemplateVersionIDWhenTogglingRunning(t *testing.T) {
 	t.Parallel()
 
 	server, state := newMockCoderServer(t)
@@ -1938,6 +4991,16 @@ func TestWorkspaceStorageCreateAllowsMatchingTemplateVersionID(t *testing.T) {
 	workspaceStorage := NewWorkspaceStorage(newTestClientProvider(t, server.URL))
 	ctx := namespacedContext("control-plane")
 
+	currentObj, err := workspaceStorage.Get(ctx, "acme.alice.dev-workspace", nil)
+	if err != nil {
+		t.Fatalf("expected workspace get to succeed: %v", err)
+	}
+
+	currentWorkspace, ok := currentObj.(*aggregationv1alpha1.CoderWorkspace)
+	if !ok {
+		t.Fatalf("expected *CoderWorkspace from get, got %T", currentObj)
+	}
+
 	templateVersionID, ok := state.workspaceLatestBuildTemplateVersionID("alice", "dev-workspace")
 	if !ok {
 		t.Fatal("expected workspace template version ID in mock server state")
@@ -1946,41 +5009,51 @@ func TestWorkspaceStorageCreateAllowsMatchingTemplateVersionID(t *testing.T) {
 		t.Fatal("expected workspace template version ID to be non-nil")
 	}
 
-	createObj := &aggregationv1alpha1.CoderWorkspace{
-		ObjectMeta: metav1.ObjectMeta{Name: "acme.alice.matching-template-version-workspace"},
-		Spec: aggregationv1alpha1.CoderWorkspaceSpec{
-			Organization:      "acme",
-			TemplateName:      "starter-template",
-			TemplateVersionID: templateVersionID.String(),
-			Running:           true,
-		},
-	}
+	desiredWorkspace := currentWorkspace.DeepCopy()
+	desiredWorkspace.Spec.TemplateVersionID = templateVersionID.String()
+	desiredWorkspace.Spec.Running = !currentWorkspace.Spec.Running
 
-	createdObj, err := workspaceStorage.Create(ctx, createObj, rest.ValidateAllObjectFunc, nil)
+	updatedObj, created, err := workspaceStorage.Update(
+		ctx,
+		desiredWorkspace.Name,
+		testUpdatedObjectInfo{obj: desiredWorkspace},
+		nil,
+		rest.ValidateAllObjectUpdateFunc,
+		false,
+		nil,
+	)
 	if err != nil {
-		t.Fatalf("expected workspace create to succeed for matching templateVersionID: %v", err)
+		t.Fatalf("expected workspace update to succeed when templateVersionID is unchanged: %v", err)
+	}
+	if created {
+		t.Fatal("expected update created=false")
 	}
 
-	createdWorkspace, ok := createdObj.(*aggregationv1alpha1.CoderWorkspace)
+	updatedWorkspace, ok := updatedObj.(*aggregationv1alpha1.CoderWorkspace)
 	if !ok {
-		t.Fatalf("expected *CoderWorkspace from create, got %T", createdObj)
+		t.Fatalf("expected *CoderWorkspace from update, got %T", updatedObj)
 	}
-	if createdWorkspace.Spec.TemplateVersionID != templateVersionID.String() {
+	if updatedWorkspace.Spec.Running != desiredWorkspace.Spec.Running {
+		t.Fatalf("expected updated running=%t, got %t", desiredWorkspace.Spec.Running, updatedWorkspace.Spec.Running)
+	}
+	if updatedWorkspace.Spec.TemplateVersionID != templateVersionID.String() {
 		t.Fatalf(
-			"expected created spec.templateVersionID %q, got %q",
+			"expected updated templateVersionID %q, got %q",
 			templateVersionID.String(),
-			createdWorkspace.Spec.TemplateVersionID,
+			updatedWorkspace.Spec.TemplateVersionID,
 		)
 	}
-	if !state.hasWorkspace("alice", "matching-template-version-workspace") {
-		t.Fatal("expected workspace to be persisted in mock server state")
+
+	expectedTransition := codersdk.WorkspaceTransitionStop
+	if desiredWorkspace.Spec.Running {
+		expectedTransition = codersdk.WorkspaceTransitionStart
 	}
-	if transitions := state.buildTransitionsSnapshot(); len(transitions) != 0 {
-		t.Fatalf("expected no workspace build transitions when spec.running=true, got %v", transitions)
+	if !containsTransition(state.buildTransitionsSnapshot(), expectedTransition) {
+		t.Fatalf("expected update to queue %q transition", expectedTransition)
 	}
 }
 
-func TestWorkspaceStorageUpdateForceAllowCreateCreatesWhenMissing(t *testing.T) {
+func TestWorkspaceStorageUpdateAllowsEmptyTemplateVersionIDWhenTogglingRunning(t *testing.T) {
 	t.Parallel()
 
 	server, state := newMockCoderServer(t)
@@ -1989,49 +5062,67 @@ func TestWorkspaceStorageUpdateForceAllowCreateCreatesWhenMissing(t *testing.T)
 	workspaceStorage := NewWorkspaceStorage(newTestClientProvider(t, server.URL))
 	ctx := namespacedContext("control-plane")
 
-	desiredWorkspace := &aggregationv1alpha1.CoderWorkspace{
-		Spec: aggregationv1alpha1.CoderWorkspaceSpec{
-			Organization: "acme",
-			TemplateName: "starter-template",
-			Running:      false,
-		},
+	currentObj, err := workspaceStorage.Get(ctx, "acme.alice.dev-workspace", nil)
+	if err != nil {
+		t.Fatalf("expected workspace get to succeed: %v", err)
+	}
+
+	currentWorkspace, ok := currentObj.(*aggregationv1alpha1.CoderWorkspace)
+	if !ok {
+		t.Fatalf("expected *CoderWorkspace from get, got %T", currentObj)
+	}
+	if currentWorkspace.Spec.TemplateVersionID == "" {
+		t.Fatal("expected current workspace spec.templateVersionID to be populated")
 	}
 
+	desiredWorkspace := currentWorkspace.DeepCopy()
+	desiredWorkspace.Spec.TemplateVersionID = ""
+	desiredWorkspace.Spec.Running = !currentWorkspace.Spec.Running
+
 	updatedObj, created, err := workspaceStorage.Update(
 		ctx,
-		"acme.alice.ssa-workspace",
+		desiredWorkspace.Name,
 		testUpdatedObjectInfo{obj: desiredWorkspace},
-		rest.ValidateAllObjectFunc,
+		nil,
 		rest.ValidateAllObjectUpdateFunc,
-		true,
+		false,
 		nil,
 	)
 	if err != nil {
-		t.Fatalf("expected create-on-update to succeed for missing workspace: %v", err)
+		t.Fatalf("expected workspace update to succeed when desired spec.templateVersionID is empty: %v", err)
 	}
-	if !created {
-		t.Fatal("expected update created=true when forceAllowCreate creates a workspace")
+	if created {
+		t.Fatal("expected update created=false")
 	}
 
-	createdWorkspace, ok := updatedObj.(*aggregationv1alpha1.CoderWorkspace)
+	updatedWorkspace, ok := updatedObj.(*aggregationv1alpha1.CoderWorkspace)
 	if !ok {
 		t.Fatalf("expected *CoderWorkspace from update, got %T", updatedObj)
 	}
-	if createdWorkspace.Name != "acme.alice.ssa-workspace" {
-		t.Fatalf("expected created workspace name acme.alice.ssa-workspace, got %q", createdWorkspace.Name)
+	if updatedWorkspace.Spec.Running != desiredWorkspace.Spec.Running {
+		t.Fatalf("expected updated running=%t, got %t", desiredWorkspace.Spec.Running, updatedWorkspace.Spec.Running)
 	}
-	if !state.hasWorkspace("alice", "ssa-workspace") {
-		t.Fatal("expected create-on-update workspace to be persisted in mock server state")
+	if updatedWorkspace.Spec.TemplateVersionID != currentWorkspace.Spec.TemplateVersionID {
+		t.Fatalf(
+			"expected updated templateVersionID %q, got %q",
+			currentWorkspace.Spec.TemplateVersionID,
+			updatedWorkspace.Spec.TemplateVersionID,
+		)
 	}
-	if !containsTransition(state.buildTransitionsSnapshot(), codersdk.WorkspaceTransitionStop) {
-		t.Fatal("expected create-on-update workspace with spec.running=false to queue stop transition")
+
+	expectedTransition := codersdk.WorkspaceTransitionStop
+	if desiredWorkspace.Spec.Running {
+		expectedTransition = codersdk.WorkspaceTransitionStart
+	}
+	if !containsTransition(state.buildTransitionsSnapshot(), expectedTransition) {
+		t.Fatalf("expected update to queue %q transition", expectedTransition)
 	}
 }
 
-func TestWorkspaceStorageUpdateRejectsNonRunningSpecChanges(t *testing.T) {
+func TestWorkspaceStorageUpdateAllowsNilOptionalFieldsWhenTogglingRunning(t *testing.T) {
 	t.Parallel()
 
-	server, _ := newMockCoderServer(t)
+	server, state := newMockCoderServer(t)
 	defer server.Close()
 
 	workspaceStorage := NewWorkspaceStorage(newTestClientProvider(t, server.URL))
@@ -2046,11 +5137,16 @@ func TestWorkspaceStorageUpdateRejectsNonRunningSpecChanges(t *testing.T) {
 	if !ok {
 		t.Fatalf("expected *CoderWorkspace from get, got %T", currentObj)
 	}
+	if currentWorkspace.Spec.TTLMillis == nil || currentWorkspace.Spec.AutostartSchedule == nil {
+		t.Fatal("expected current workspace optional fields to be populated")
+	}
 
 	desiredWorkspace := currentWorkspace.DeepCopy()
-	desiredWorkspace.Spec.TemplateName = "renamed-template"
+	desiredWorkspace.Spec.Running = !currentWorkspace.Spec.Running
+	desiredWorkspace.Spec.TTLMillis = nil
+	desiredWorkspace.Spec.AutostartSchedule = nil
 
-	_, _, err = workspaceStorage.Update(
+	updatedObj, created, err := workspaceStorage.Update(
 		ctx,
 		desiredWorkspace.Name,
 		testUpdatedObjectInfo{obj: desiredWorkspace},
@@ -2059,15 +5155,45 @@ func TestWorkspaceStorageUpdateRejectsNonRunningSpecChanges(t *testing.T) {
 		false,
 		nil,
 	)
-	if !apierrors.IsBadRequest(err) {
-		t.Fatalf("expected BadRequest when changing immutable workspace spec fields, got %v", err)
+	if err != nil {
+		t.Fatalf("expected workspace update to succeed when optional fields are nil: %v", err)
 	}
-	if err == nil || !strings.Contains(err.Error(), "spec.running") {
-		t.Fatalf("expected immutable-field error mentioning spec.running, got %v", err)
+	if created {
+		t.Fatal("expected update created=false")
+	}
+
+	updatedWorkspace, ok := updatedObj.(*aggregationv1alpha1.CoderWorkspace)
+	if !ok {
+		t.Fatalf("expected *CoderWorkspace from update, got %T", updatedObj)
+	}
+	if updatedWorkspace.Spec.Running != desiredWorkspace.Spec.Running {
+		t.Fatalf("expected updated running=%t, got %t", desiredWorkspace.Spec.Running, updatedWorkspace.Spec.Running)
+	}
+	if updatedWorkspace.Spec.TTLMillis == nil || *updatedWorkspace.Spec.TTLMillis != *currentWorkspace.Spec.TTLMillis {
+		t.Fatalf(
+			"expected returned spec.ttlMillis to remain %v, got %v",
+			*currentWorkspace.Spec.TTLMillis,
+			updatedWorkspace.Spec.TTLMillis,
+		)
+	}
+	if updatedWorkspace.Spec.AutostartSchedule == nil || *updatedWorkspace.Spec.AutostartSchedule != *currentWorkspace.Spec.AutostartSchedule {
+		t.Fatalf(
+			"expected returned spec.autostartSchedule to remain %q, got %v",
+			*currentWorkspace.Spec.AutostartSchedule,
+			updatedWorkspace.Spec.AutostartSchedule,
+		)
+	}
+
+	expectedTransition := codersdk.WorkspaceTransitionStop
+	if desiredWorkspace.Spec.Running {
+		expectedTransition = codersdk.WorkspaceTransitionStart
+	}
+	if !containsTransition(state.buildTransitionsSnapshot(), expectedTransition) {
+		t.Fatalf("expected update to queue %q transition", expectedTransition)
 	}
 }
 
-func TestWorkspaceStorageUpdateAllowsPinnedTemplateVersionIDWhenTogglingRunning(t *testing.T) {
+func TestWorkspaceStorageUpdateScheduleWithoutTogglingRunning(t *testing.T) {
 	t.Parallel()
 
 	server, state := newMockCoderServer(t)
@@ -2085,20 +5211,17 @@ func TestWorkspaceStorageUpdateAllowsPinnedTemplateVersionIDWhenTogglingRunning(
 	if !ok {
 		t.Fatalf("expected *CoderWorkspace from get, got %T", currentObj)
 	}
-
-	templateVersionID, ok := state.workspaceLatestBuildTemplateVersionID("alice", "dev-workspace")
-	if !ok {
-		t.Fatal("expected workspace template version ID in mock server state")
-	}
-	if templateVersionID == uuid.Nil {
-		t.Fatal("expected workspace template version ID to be non-nil")
+	if currentWorkspace.Spec.TTLMillis == nil {
+		t.Fatal("expected current workspace spec.ttlMillis to be populated")
 	}
 
+	differentTTLMillis := *currentWorkspace.Spec.TTLMillis + 60000
+	differentSchedule := "CRON_TZ=UTC 30 8 * * 1-5"
 	desiredWorkspace := currentWorkspace.DeepCopy()
-	desiredWorkspace.Spec.TemplateVersionID = templateVersionID.String()
-	desiredWorkspace.Spec.Running = !currentWorkspace.Spec.Running
+	desiredWorkspace.Spec.TTLMillis = &differentTTLMillis
+	desiredWorkspace.Spec.AutostartSchedule = &differentSchedule
 
-	updatedObj, created, err := workspaceStorage.Update(
+	updatedObj, _, err := workspaceStorage.Update(
 		ctx,
 		desiredWorkspace.Name,
 		testUpdatedObjectInfo{obj: desiredWorkspace},
@@ -2108,37 +5231,60 @@ func TestWorkspaceStorageUpdateAllowsPinnedTemplateVersionIDWhenTogglingRunning(
 		nil,
 	)
 	if err != nil {
-		t.Fatalf("expected workspace update to succeed when templateVersionID is unchanged: %v", err)
+		t.Fatalf("expected schedule update to succeed: %v", err)
 	}
-	if created {
-		t.Fatal("expected update created=false")
+	if updates := state.workspaceScheduleUpdatesSnapshot(); !reflect.DeepEqual(updates, []string{"ttl", "autostart"}) {
+		t.Fatalf("expected ttl and autostart update calls, got %v", updates)
+	}
+	if transitions := state.buildTransitionsSnapshot(); len(transitions) != 0 {
+		t.Fatalf("expected no workspace build transitions for a schedule update, got %v", transitions)
 	}
 
 	updatedWorkspace, ok := updatedObj.(*aggregationv1alpha1.CoderWorkspace)
 	if !ok {
 		t.Fatalf("expected *CoderWorkspace from update, got %T", updatedObj)
 	}
-	if updatedWorkspace.Spec.Running != desiredWorkspace.Spec.Running {
-		t.Fatalf("expected updated running=%t, got %t", desiredWorkspace.Spec.Running, updatedWorkspace.Spec.Running)
+	if updatedWorkspace.Spec.TTLMillis == nil || *updatedWorkspace.Spec.TTLMillis != differentTTLMillis {
+		t.Fatalf("expected spec.ttlMillis %d, got %v", differentTTLMillis, updatedWorkspace.Spec.TTLMillis)
 	}
-	if updatedWorkspace.Spec.TemplateVersionID != templateVersionID.String() {
-		t.Fatalf(
-			"expected updated templateVersionID %q, got %q",
-			templateVersionID.String(),
-			updatedWorkspace.Spec.TemplateVersionID,
-		)
+	if updatedWorkspace.Spec.AutostartSchedule == nil || *updatedWorkspace.Spec.AutostartSchedule != differentSchedule {
+		t.Fatalf("expected spec.autostartSchedule %q, got %v", differentSchedule, updatedWorkspace.Spec.AutostartSchedule)
+	}
+	if updatedWorkspace.Spec.Running != currentWorkspace.Spec.Running {
+		t.Fatalf("expected spec.running to stay %t", currentWorkspace.Spec.Running)
 	}
 
-	expectedTransition := codersdk.WorkspaceTransitionStop
-	if desiredWorkspace.Spec.Running {
-		expectedTransition = codersdk.WorkspaceTransitionStart
+	// Zero and empty values disable autostop and autostart.
+	disabled := updatedWorkspace.DeepCopy()
+	zeroTTL := int64(0)
+	emptySchedule := ""
+	disabled.Spec.TTLMillis = &zeroTTL
+	disabled.Spec.AutostartSchedule = &emptySchedule
+	disabledObj, _, err := workspaceStorage.Update(
+		ctx,
+		disabled.Name,
+		testUpdatedObjectInfo{obj: disabled},
+		nil,
+		rest.ValidateAllObjectUpdateFunc,
+		false,
+		nil,
+	)
+	if err != nil {
+		t.Fatalf("expected schedule disable update to succeed: %v", err)
 	}
-	if !containsTransition(state.buildTransitionsSnapshot(), expectedTransition) {
-		t.Fatalf("expected update to queue %q transition", expectedTransition)
+	disabledWorkspace, ok := disabledObj.(*aggregationv1alpha1.CoderWorkspace)
+	if !ok {
+		t.Fatalf("expected *CoderWorkspace from update, got %T", disabledObj)
+	}
+	if disabledWorkspace.Spec.TTLMillis != nil || disabledWorkspace.Spec.AutostartSchedule != nil {
+		t.Fatalf("expected schedule to be cleared, got ttl=%v autostart=%v", disabledWorkspace.Spec.TTLMillis, disabledWorkspace.Spec.AutostartSchedule)
+	}
+	if transitions := state.buildTransitionsSnapshot(); len(transitions) != 0 {
+		t.Fatalf("expected no workspace build transitions for a schedule update, got %v", transitions)
 	}
 }
 
-func TestWorkspaceStorageUpdateAllowsEmptyTemplateVersionIDWhenTogglingRunning(t *testing.T) {
+func TestWorkspaceStorageUpdateScheduleAndRunningTogether(t *testing.T) {
 	t.Parallel()
 
 	server, state := newMockCoderServer(t)
@@ -2156,15 +5302,13 @@ func TestWorkspaceStorageUpdateAllowsEmptyTemplateVersionIDWhenTogglingRunning(t
 	if !ok {
 		t.Fatalf("expected *CoderWorkspace from get, got %T", currentObj)
 	}
-	if currentWorkspace.Spec.TemplateVersionID == "" {
-		t.Fatal("expected current workspace spec.templateVersionID to be populated")
-	}
 
+	differentTTLMillis := *currentWorkspace.Spec.TTLMillis + 60000
 	desiredWorkspace := currentWorkspace.DeepCopy()
-	desiredWorkspace.Spec.TemplateVersionID = ""
 	desiredWorkspace.Spec.Running = !currentWorkspace.Spec.Running
+	desiredWorkspace.Spec.TTLMillis = &differentTTLMillis
 
-	updatedObj, created, err := workspaceStorage.Update(
+	updatedObj, _, err := workspaceStorage.Update(
 		ctx,
 		desiredWorkspace.Name,
 		testUpdatedObjectInfo{obj: desiredWorkspace},
@@ -2174,37 +5318,79 @@ func TestWorkspaceStorageUpdateAllowsEmptyTemplateVersionIDWhenTogglingRunning(t
 		nil,
 	)
 	if err != nil {
-		t.Fatalf("expected workspace update to succeed when desired spec.templateVersionID is empty: %v", err)
+		t.Fatalf("expected schedule and running update to succeed: %v", err)
 	}
-	if created {
-		t.Fatal("expected update created=false")
+	if updates := state.workspaceScheduleUpdatesSnapshot(); !reflect.DeepEqual(updates, []string{"ttl"}) {
+		t.Fatalf("expected one ttl update call, got %v", updates)
+	}
+	if transitions := state.buildTransitionsSnapshot(); len(transitions) != 1 {
+		t.Fatalf("expected one workspace build transition, got %v", transitions)
 	}
 
 	updatedWorkspace, ok := updatedObj.(*aggregationv1alpha1.CoderWorkspace)
 	if !ok {
 		t.Fatalf("expected *CoderWorkspace from update, got %T", updatedObj)
 	}
-	if updatedWorkspace.Spec.Running != desiredWorkspace.Spec.Running {
-		t.Fatalf("expected updated running=%t, got %t", desiredWorkspace.Spec.Running, updatedWorkspace.Spec.Running)
+	if updatedWorkspace.Spec.TTLMillis == nil || *updatedWorkspace.Spec.TTLMillis != differentTTLMillis {
+		t.Fatalf("expected spec.ttlMillis %d, got %v", differentTTLMillis, updatedWorkspace.Spec.TTLMillis)
 	}
-	if updatedWorkspace.Spec.TemplateVersionID != currentWorkspace.Spec.TemplateVersionID {
-		t.Fatalf(
-			"expected updated templateVersionID %q, got %q",
-			currentWorkspace.Spec.TemplateVersionID,
-			updatedWorkspace.Spec.TemplateVersionID,
-		)
+}
+
+func TestWorkspaceStorageCreateDryRunDoesNotMutateCoder(t *testing.T) {
+	t.Parallel()
+
+	server, state := newMockCoderServer(t)
+	defer server.Close()
+
+	workspaceStorage := NewWorkspaceStorage(newTestClientProvider(t, server.URL))
+	ctx := namespacedContext("control-plane")
+
+	createObj := &aggregationv1alpha1.CoderWorkspace{
+		ObjectMeta: metav1.ObjectMeta{Name: "acme.alice.dry-run-workspace"},
+		Spec: aggregationv1alpha1.CoderWorkspaceSpec{
+			Organization: "acme",
+			TemplateName: "starter-template",
+			Running:      true,
+		},
 	}
 
-	expectedTransition := codersdk.WorkspaceTransitionStop
-	if desiredWorkspace.Spec.Running {
-		expectedTransition = codersdk.WorkspaceTransitionStart
+	createdObj, err := workspaceStorage.Create(
+		ctx,
+		createObj,
+		rest.ValidateAllObjectFunc,
+		&metav1.CreateOptions{DryRun: []string{metav1.DryRunAll}},
+	)
+	if err != nil {
+		t.Fatalf("expected dry-run workspace create to succeed: %v", err)
 	}
-	if !containsTransition(state.buildTransitionsSnapshot(), expectedTransition) {
-		t.Fatalf("expected update to queue %q transition", expectedTransition)
+
+	createdWorkspace, ok := createdObj.(*aggregationv1alpha1.CoderWorkspace)
+	if !ok {
+		t.Fatalf("expected *CoderWorkspace from create, got %T", createdObj)
+	}
+	if createdWorkspace.Namespace != "control-plane" {
+		t.Fatalf("expected dry-run namespace %q, got %q", "control-plane", createdWorkspace.Namespace)
+	}
+	if state.hasWorkspace("alice", "dry-run-workspace") {
+		t.Fatal("expected dry-run create to leave the workspace absent in Coder")
+	}
+	if transitions := state.buildTransitionsSnapshot(); len(transitions) != 0 {
+		t.Fatalf("expected dry-run create to avoid workspace builds, got %v", transitions)
+	}
+
+	createObj.Name = "acme.alice.dev-workspace"
+	_, err = workspaceStorage.Create(
+		ctx,
+		createObj,
+		rest.ValidateAllObjectFunc,
+		&metav1.CreateOptions{DryRun: []string{metav1.DryRunAll}},
+	)
+	if !apierrors.IsAlreadyExists(err) {
+		t.Fatalf("expected dry-run create of an existing workspace to return AlreadyExists, got %v", err)
 	}
 }
 
-func TestWorkspaceStorageUpdateAllowsNilOptionalFieldsWhenTogglingRunning(t *testing.T) {
+func TestWorkspaceStorageUpdateDryRunDoesNotMutateCoder(t *testing.T) {
 	t.Parallel()
 
 	server, state := newMockCoderServer(t)
@@ -2222,29 +5408,29 @@ func TestWorkspaceStorageUpdateAllowsNilOptionalFieldsWhenTogglingRunning(t *tes
 	if !ok {
 		t.Fatalf("expected *CoderWorkspace from get, got %T", currentObj)
 	}
-	if currentWorkspace.Spec.TTLMillis == nil || currentWorkspace.Spec.AutostartSchedule == nil {
-		t.Fatal("expected current workspace optional fields to be populated")
-	}
 
+	differentTTLMillis := *currentWorkspace.Spec.TTLMillis + 60000
 	desiredWorkspace := currentWorkspace.DeepCopy()
 	desiredWorkspace.Spec.Running = !currentWorkspace.Spec.Running
-	desiredWorkspace.Spec.TTLMillis = nil
-	desiredWorkspace.Spec.AutostartSchedule = nil
+	desiredWorkspace.Spec.TTLMillis = &differentTTLMillis
 
-	updatedObj, created, err := workspaceStorage.Update(
+	updatedObj, _, err := workspaceStorage.Update(
 		ctx,
 		desiredWorkspace.Name,
 		testUpdatedObjectInfo{obj: desiredWorkspace},
 		nil,
 		rest.ValidateAllObjectUpdateFunc,
 		false,
-		nil,
+		&metav1.UpdateOptions{DryRun: []string{metav1.DryRunAll}},
 	)
 	if err != nil {
-		t.Fatalf("expected workspace update to succeed when optional fields are nil: %v", err)
+		t.Fatalf("expected dry-run workspace update to succeed: %v", err)
 	}
-	if created {
-		t.Fatal("expected update created=false")
+	if updates := state.workspaceScheduleUpdatesSnapshot(); len(updates) != 0 {
+		t.Fatalf("expected dry-run update to avoid schedule updates, got %v", updates)
+	}
+	if transitions := state.buildTransitionsSnapshot(); len(transitions) != 0 {
+		t.Fatalf("expected dry-run update to avoid workspace builds, got %v", transitions)
 	}
 
 	updatedWorkspace, ok := updatedObj.(*aggregationv1alpha1.CoderWorkspace)
@@ -2252,33 +5438,14 @@ func TestWorkspaceStorageUpdateAllowsNilOptionalFieldsWhenTogglingRunning(t *tes
 		t.Fatalf("expected *CoderWorkspace from update, got %T", updatedObj)
 	}
 	if updatedWorkspace.Spec.Running != desiredWorkspace.Spec.Running {
-		t.Fatalf("expected updated running=%t, got %t", desiredWorkspace.Spec.Running, updatedWorkspace.Spec.Running)
-	}
-	if updatedWorkspace.Spec.TTLMillis == nil || *updatedWorkspace.Spec.TTLMillis != *currentWorkspace.Spec.TTLMillis {
-		t.Fatalf(
-			"expected returned spec.ttlMillis to remain %v, got %v",
-			*currentWorkspace.Spec.TTLMillis,
-			updatedWorkspace.Spec.TTLMillis,
-		)
-	}
-	if updatedWorkspace.Spec.AutostartSchedule == nil || *updatedWorkspace.Spec.AutostartSchedule != *currentWorkspace.Spec.AutostartSchedule {
-		t.Fatalf(
-			"expected returned spec.autostartSchedule to remain %q, got %v",
-			*currentWorkspace.Spec.AutostartSchedule,
-			updatedWorkspace.Spec.AutostartSchedule,
-		)
-	}
-
-	expectedTransition := codersdk.WorkspaceTransitionStop
-	if desiredWorkspace.Spec.Running {
-		expectedTransition = codersdk.WorkspaceTransitionStart
+		t.Fatalf("expected dry-run spec.running %t, got %t", desiredWorkspace.Spec.Running, updatedWorkspace.Spec.Running)
 	}
-	if !containsTransition(state.buildTransitionsSnapshot(), expectedTransition) {
-		t.Fatalf("expected update to queue %q transition", expectedTransition)
+	if updatedWorkspace.Spec.TTLMillis == nil || *updatedWorkspace.Spec.TTLMillis != differentTTLMillis {
+		t.Fatalf("expected dry-run spec.ttlMillis %d, got %v", differentTTLMillis, updatedWorkspace.Spec.TTLMillis)
 	}
 }
 
-func TestWorkspaceStorageUpdateRejectsDifferentTTLMillis(t *testing.T) {
+func TestWorkspaceStorageUpdateRejectsDifferentTemplateVersionID(t *testing.T) {
 	t.Parallel()
 
 	server, state := newMockCoderServer(t)
@@ -2296,14 +5463,13 @@ func TestWorkspaceStorageUpdateRejectsDifferentTTLMillis(t *testing.T) {
 	if !ok {
 		t.Fatalf("expected *CoderWorkspace from get, got %T", currentObj)
 	}
-	if currentWorkspace.Spec.TTLMillis == nil {
-		t.Fatal("expected current workspace spec.ttlMillis to be populated")
-	}
 
-	differentTTLMillis := *currentWorkspace.Spec.TTLMillis + 60000
 	desiredWorkspace := currentWorkspace.DeepCopy()
 	desiredWorkspace.Spec.Running = !currentWorkspace.Spec.Running
-	desiredWorkspace.Spec.TTLMillis = &differentTTLMillis
+	desiredWorkspace.Spec.TemplateVersionID = uuid.New().String()
+	if desiredWorkspace.Spec.TemplateVersionID == currentWorkspace.Spec.TemplateVersionID {
+		t.Fatal("expected test fixture to use a different spec.templateVersionID")
+	}
 
 	_, _, err = workspaceStorage.Update(
 		ctx,
@@ -2315,7 +5481,7 @@ func TestWorkspaceStorageUpdateRejectsDifferentTTLMillis(t *testing.T) {
 		nil,
 	)
 	if !apierrors.IsBadRequest(err) {
-		t.Fatalf("expected BadRequest when changing spec.ttlMillis, got %v", err)
+		t.Fatalf("expected BadRequest when changing spec.templateVersionID, got %v", err)
 	}
 	if err == nil || !strings.Contains(err.Error(), "spec.running") {
 		t.Fatalf("expected immutable-field error mentioning spec.running, got %v", err)
@@ -2325,10 +5491,10 @@ func TestWorkspaceStorageUpdateRejectsDifferentTTLMillis(t *testing.T) {
 	}
 }
 
-func TestWorkspaceStorageUpdateRejectsDifferentTemplateVersionID(t *testing.T) {
+func TestWorkspaceStorageUpdateRetainsManagedFields(t *testing.T) {
 	t.Parallel()
 
-	server, state := newMockCoderServer(t)
+	server, _ := newMockCoderServer(t)
 	defer server.Close()
 
 	workspaceStorage := NewWorkspaceStorage(newTestClientProvider(t, server.URL))
@@ -2338,19 +5504,44 @@ func TestWorkspaceStorageUpdateRejectsDifferentTemplateVersionID(t *testing.T) {
 	if err != nil {
 		t.Fatalf("expected workspace get to succeed: %v", err)
 	}
-
 	currentWorkspace, ok := currentObj.(*aggregationv1alpha1.CoderWorkspace)
 	if !ok {
 		t.Fatalf("expected *CoderWorkspace from get, got %T", currentObj)
 	}
 
 	desiredWorkspace := currentWorkspace.DeepCopy()
-	desiredWorkspace.Spec.Running = !currentWorkspace.Spec.Running
-	desiredWorkspace.Spec.TemplateVersionID = uuid.New().String()
-	if desiredWorkspace.Spec.TemplateVersionID == currentWorkspace.Spec.TemplateVersionID {
-		t.Fatal("expected test fixture to use a different spec.templateVersionID")
+	desiredWorkspace.ManagedFields = []metav1.ManagedFieldsEntry{
+		appliedManagedFieldsEntry("gitops", `{"f:spec":{"f:running":{}}}`),
+	}
+
+	if _, _, err := workspaceStorage.Update(
+		ctx,
+		desiredWorkspace.Name,
+		testUpdatedObjectInfo{obj: desiredWorkspace},
+		nil,
+		rest.ValidateAllObjectUpdateFunc,
+		false,
+		nil,
+	); err != nil {
+		t.Fatalf("expected apply update to succeed: %v", err)
+	}
+
+	refetchedObj, err := workspaceStorage.Get(ctx, "acme.alice.dev-workspace", nil)
+	if err != nil {
+		t.Fatalf("expected workspace get to succeed: %v", err)
+	}
+	refetchedWorkspace, ok := refetchedObj.(*aggregationv1alpha1.CoderWorkspace)
+	if !ok {
+		t.Fatalf("expected *CoderWorkspace from get, got %T", refetchedObj)
+	}
+	if !reflect.DeepEqual(refetchedWorkspace.ManagedFields, desiredWorkspace.ManagedFields) {
+		t.Fatalf("expected get to return managed fields %v, got %v", desiredWorkspace.ManagedFields, refetchedWorkspace.ManagedFields)
 	}
 
+	desiredWorkspace = refetchedWorkspace.DeepCopy()
+	desiredWorkspace.ManagedFields = []metav1.ManagedFieldsEntry{
+		appliedManagedFieldsEntry("gitops", `{"f:status":{"f:ownerName":{}}}`),
+	}
 	_, _, err = workspaceStorage.Update(
 		ctx,
 		desiredWorkspace.Name,
@@ -2361,13 +5552,17 @@ func TestWorkspaceStorageUpdateRejectsDifferentTemplateVersionID(t *testing.T) {
 		nil,
 	)
 	if !apierrors.IsBadRequest(err) {
-		t.Fatalf("expected BadRequest when changing spec.templateVersionID, got %v", err)
+		t.Fatalf("expected BadRequest for applied status fields, got %v", err)
 	}
-	if err == nil || !strings.Contains(err.Error(), "spec.running") {
-		t.Fatalf("expected immutable-field error mentioning spec.running, got %v", err)
-	}
-	if transitions := state.buildTransitionsSnapshot(); len(transitions) != 0 {
-		t.Fatalf("expected no workspace build transitions on immutable-field error, got %v", transitions)
+}
+
+func appliedManagedFieldsEntry(manager, fields string) metav1.ManagedFieldsEntry {
+	return metav1.ManagedFieldsEntry{
+		Manager:    manager,
+		Operation:  metav1.ManagedFieldsOperationApply,
+		APIVersion: aggregationv1alpha1.SchemeGroupVersion.String(),
+		FieldsType: "FieldsV1",
+		FieldsV1:   &metav1.FieldsV1{Raw: []byte(fields)},
 	}
 }
 
@@ -2579,6 +5774,125 @@ func TestWorkspaceStorageListAllowsAllNamespacesRequest(t *testing.T) {
 	}
 }
 
+func TestWorkspaceStorageListResourceVersionZeroServesCachedList(t *testing.T) {
+	t.Parallel()
+
+	server, state := newMockCoderServer(t)
+	defer server.Close()
+
+	workspaceStorage := NewWorkspaceStorage(newTestClientProvider(t, server.URL))
+	now := time.Now()
+	workspaceStorage.listCache.now = func() time.Time { return now }
+	ctx := namespacedContext("control-plane")
+	cachedOpts := &metainternalversion.ListOptions{ResourceVersion: "0"}
+
+	listWorkspaces := func(t *testing.T, opts *metainternalversion.ListOptions) *aggregationv1alpha1.CoderWorkspaceList {
+		t.Helper()
+
+		listObj, err := workspaceStorage.List(ctx, opts)
+		if err != nil {
+			t.Fatalf("expected workspace list to succeed, got %v", err)
+		}
+		list, ok := listObj.(*aggregationv1alpha1.CoderWorkspaceList)
+		if !ok {
+			t.Fatalf("expected *CoderWorkspaceList, got %T", listObj)
+		}
+		if len(list.Items) == 0 {
+			t.Fatal("expected at least one workspace in list")
+		}
+		return list
+	}
+
+	first := listWorkspaces(t, cachedOpts)
+	// Callers own the returned list; mutating it must not poison the cache.
+	first.Items[0].Name = "mutated"
+	second := listWorkspaces(t, cachedOpts)
+	if second.Items[0].Name == "mutated" {
+		t.Fatal("expected cached list to be copied for each caller")
+	}
+	if queries := state.workspaceListQueriesSnapshot(); len(queries) != 1 {
+		t.Fatalf("expected repeated resourceVersion=0 lists within the TTL to query the backend once, got %d queries", len(queries))
+	}
+
+	listWorkspaces(t, &metainternalversion.ListOptions{})
+	if queries := state.workspaceListQueriesSnapshot(); len(queries) != 2 {
+		t.Fatalf("expected a list without resourceVersion=0 to query the backend, got %d queries", len(queries))
+	}
+
+	now = now.Add(listCacheTTL)
+	listWorkspaces(t, cachedOpts)
+	if queries := state.workspaceListQueriesSnapshot(); len(queries) != 3 {
+		t.Fatalf("expected a resourceVersion=0 list after the TTL to query the backend, got %d queries", len(queries))
+	}
+}
+
+func TestWorkspaceStorageListScopesBackendQueryToOwnerFieldSelector(t *testing.T) {
+	t.Parallel()
+
+	server, state := newMockCoderServer(t)
+	defer server.Close()
+	state.addWorkspaceCopy("bob", "bob-workspace")
+
+	workspaceStorage := NewWorkspaceStorage(newTestClientProvider(t, server.URL))
+	ctx := namespacedContext("control-plane")
+
+	listObj, err := workspaceStorage.List(ctx, &metainternalversion.ListOptions{
+		FieldSelector: fields.OneTermEqualSelector(aggregationv1alpha1.CoderWorkspaceOwnerNameField, "bob"),
+	})
+	if err != nil {
+		t.Fatalf("expected owner-scoped list to succeed, got %v", err)
+	}
+	list, ok := listObj.(*aggregationv1alpha1.CoderWorkspaceList)
+	if !ok {
+		t.Fatalf("expected *CoderWorkspaceList, got %T", listObj)
+	}
+	if len(list.Items) != 1 {
+		t.Fatalf("expected exactly one workspace for bob, got %d", len(list.Items))
+	}
+	if list.Items[0].Name != "acme.bob.bob-workspace" {
+		t.Fatalf("expected bob's workspace, got %q", list.Items[0].Name)
+	}
+	if list.Items[0].Status.OwnerName != "bob" {
+		t.Fatalf("expected owner bob, got %q", list.Items[0].Status.OwnerName)
+	}
+
+	queries := state.workspaceListQueriesSnapshot()
+	if len(queries) != 1 {
+		t.Fatalf("expected one backend workspace list query, got %v", queries)
+	}
+	if queries[0] != `owner:"bob"` {
+		t.Fatalf("expected owner-filtered backend query, got %q", queries[0])
+	}
+
+	// Unscoped lists still query every workspace.
+	listObj, err = workspaceStorage.List(ctx, nil)
+	if err != nil {
+		t.Fatalf("expected unscoped list to succeed, got %v", err)
+	}
+	if list = listObj.(*aggregationv1alpha1.CoderWorkspaceList); len(list.Items) != 2 {
+		t.Fatalf("expected two workspaces without a selector, got %d", len(list.Items))
+	}
+	if queries = state.workspaceListQueriesSnapshot(); queries[len(queries)-1] != "" {
+		t.Fatalf("expected unfiltered backend query, got %q", queries[len(queries)-1])
+	}
+}
+
+func TestWorkspaceStorageListRejectsUnsupportedFieldSelector(t *testing.T) {
+	t.Parallel()
+
+	server, _ := newMockCoderServer(t)
+	defer server.Close()
+
+	workspaceStorage := NewWorkspaceStorage(newTestClientProvider(t, server.URL))
+
+	_, err := workspaceStorage.List(namespacedContext("control-plane"), &metainternalversion.ListOptions{
+		FieldSelector: fields.OneTermEqualSelector("spec.templateName", "starter-template"),
+	})
+	if !apierrors.IsBadRequest(err) {
+		t.Fatalf("expected bad request for unsupported field selector, got %v", err)
+	}
+}
+
 func TestWorkspaceStorageListAggregatesAcrossNamespaces(t *testing.T) {
 	t.Parallel()
 
@@ -2722,17 +6036,34 @@ type mockCoderServerState struct {
 	templatesByID        map[uuid.UUID]codersdk.Template
 	templateIDsByOrg     map[string]map[string]uuid.UUID
 	templateVersionsByID map[uuid.UUID]codersdk.TemplateVersion
+	presetsByVersionID   map[uuid.UUID][]codersdk.Preset
 	filesByID            map[uuid.UUID][]byte
+	fileDownloads        int
 	workspacesByID       map[uuid.UUID]codersdk.Workspace
 	workspaceIDsByUser   map[string]map[string]uuid.UUID
+	usersByName          map[string]codersdk.User
+	groupsByName         map[string]codersdk.Group
+	templateUserRoles    map[uuid.UUID]map[uuid.UUID]codersdk.TemplateRole
+	templateGroupRoles   map[uuid.UUID]map[uuid.UUID]codersdk.TemplateRole
 
 	buildTransitions                  []codersdk.WorkspaceTransition
 	failBuildTransitions              map[codersdk.WorkspaceTransition]int
 	templateMetaPatchCall             int
 	failActiveVersionPromotion        bool
+	advancedSchedulingEntitled        bool
+	templateRBACEntitled              bool
+	templateACLPatchCall              int
+	workspaceScheduleUpdates          []string
+	workspaceListQueries              []string
+	workspaceCreateRequests           []codersdk.CreateWorkspaceRequest
 	templateVersionPollsBeforeSuccess map[uuid.UUID]int
 	nextTemplateVersionInitialStatus  codersdk.ProvisionerJobStatus
 	nextTemplateVersionPendingPolls   int
+	nextTemplateVersionJobError       string
+	templateVersionCreateStatusCode   int
+	presetListStatusCode              int
+	workspaceDeletePollsBeforeDone    map[uuid.UUID]int
+	nextWorkspaceDeletePendingPolls   int
 }
 
 func newMockCoderServer(t *testing.T) (*httptest.Server, *mockCoderServerState) {
@@ -2830,6 +6161,7 @@ func newMockCoderServer(t *testing.T) (*httptest.Server, *mockCoderServerState)
 		templateVersionsByID: map[uuid.UUID]codersdk.TemplateVersion{
 			templateVersion.ID: templateVersion,
 		},
+		presetsByVersionID: map[uuid.UUID][]codersdk.Preset{},
 		filesByID: map[uuid.UUID][]byte{
 			fileID: seededTemplateSourceZip,
 		},
@@ -2841,10 +6173,24 @@ func newMockCoderServer(t *testing.T) (*httptest.Server, *mockCoderServerState)
 				workspace.Name: workspace.ID,
 			},
 		},
+		usersByName: map[string]codersdk.User{
+			"alice": {ReducedUser: codersdk.ReducedUser{MinimalUser: codersdk.MinimalUser{ID: uuid.New(), Username: "alice"}}},
+			"bob":   {ReducedUser: codersdk.ReducedUser{MinimalUser: codersdk.MinimalUser{ID: uuid.New(), Username: "bob"}}},
+		},
+		groupsByName: map[string]codersdk.Group{
+			// Coder gives the Everyone group the organization's ID.
+			"Everyone": {ID: orgID, Name: "Everyone", OrganizationID: orgID},
+			"platform": {ID: uuid.New(), Name: "platform", OrganizationID: orgID},
+		},
+		templateUserRoles: map[uuid.UUID]map[uuid.UUID]codersdk.TemplateRole{},
+		templateGroupRoles: map[uuid.UUID]map[uuid.UUID]codersdk.TemplateRole{
+			template.ID: {orgID: codersdk.TemplateRoleUse},
+		},
 		buildTransitions:                  []codersdk.WorkspaceTransition{},
 		failBuildTransitions:              map[codersdk.WorkspaceTransition]int{},
 		templateVersionPollsBeforeSuccess: map[uuid.UUID]int{},
 		nextTemplateVersionInitialStatus:  codersdk.ProvisionerJobSucceeded,
+		workspaceDeletePollsBeforeDone:    map[uuid.UUID]int{},
 	}
 
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -2860,6 +6206,9 @@ func (s *mockCoderServerState) handleRequest(t *testing.T, w http.ResponseWriter
 	segments := splitPath(r.URL.Path)
 
 	switch {
+	case r.Method == http.MethodGet && hasSegments(segments, "api", "v2", "entitlements") && len(segments) == 3:
+		s.handleGetEntitlements(w)
+		return
 	case r.Method == http.MethodGet && hasSegments(segments, "api", "v2", "organizations") && len(segments) == 4:
 		s.handleGetOrganization(w, segments[3])
 		return
@@ -2884,12 +6233,31 @@ func (s *mockCoderServerState) handleRequest(t *testing.T, w http.ResponseWriter
 	case r.Method == http.MethodPatch && hasSegments(segments, "api", "v2", "templates") && len(segments) == 5 && segments[4] == "versions":
 		s.handleUpdateActiveTemplateVersion(w, r, segments[3])
 		return
+	case r.Method == http.MethodGet && hasSegments(segments, "api", "v2", "templates") && len(segments) == 5 && segments[4] == "acl":
+		s.handleGetTemplateACL(w, segments[3])
+		return
+	case r.Method == http.MethodPatch && hasSegments(segments, "api", "v2", "templates") && len(segments) == 5 && segments[4] == "acl":
+		s.handleUpdateTemplateACL(w, r, segments[3])
+		return
+	case r.Method == http.MethodGet && hasSegments(segments, "api", "v2", "users") && len(segments) == 4:
+		s.handleGetUser(w, segments[3])
+		return
+	case r.Method == http.MethodGet && hasSegments(segments, "api", "v2", "organizations") && len(segments) == 6 && segments[4] == "groups":
+		s.handleGetGroupByName(w, segments[3], segments[5])
+		return
 	case r.Method == http.MethodDelete && hasSegments(segments, "api", "v2", "templates") && len(segments) == 4:
 		s.handleDeleteTemplate(w, segments[3])
 		return
 	case r.Method == http.MethodGet && hasSegments(segments, "api", "v2", "templateversions") && len(segments) == 4:
 		s.handleGetTemplateVersion(w, segments[3])
 		return
+	case r.Method == http.MethodPost && hasSegments(segments, "api", "v2", "templateversions") && len(segments) == 5 &&
+		(segments[4] == "archive" || segments[4] == "unarchive"):
+		s.handleSetArchiveTemplateVersion(w, segments[3], segments[4] == "archive")
+		return
+	case r.Method == http.MethodGet && hasSegments(segments, "api", "v2", "templateversions") && len(segments) == 5 && segments[4] == "presets":
+		s.handleListTemplateVersionPresets(w, segments[3])
+		return
 	case r.Method == http.MethodPost && hasSegments(segments, "api", "v2", "files") && len(segments) == 3:
 		s.handleUploadFile(w, r)
 		return
@@ -2897,7 +6265,7 @@ func (s *mockCoderServerState) handleRequest(t *testing.T, w http.ResponseWriter
 		s.handleGetFile(w, r, segments[3])
 		return
 	case r.Method == http.MethodGet && hasSegments(segments, "api", "v2", "workspaces") && len(segments) == 3:
-		s.handleListWorkspaces(w)
+		s.handleListWorkspaces(w, r)
 		return
 	case r.Method == http.MethodGet && hasSegments(segments, "api", "v2", "users") && len(segments) == 6 && segments[4] == "workspace":
 		s.handleGetWorkspace(w, segments[3], segments[5])
@@ -2905,6 +6273,13 @@ func (s *mockCoderServerState) handleRequest(t *testing.T, w http.ResponseWriter
 	case r.Method == http.MethodPost && hasSegments(segments, "api", "v2", "users") && len(segments) == 5 && segments[4] == "workspaces":
 		s.handleCreateWorkspace(w, r, segments[3])
 		return
+	case r.Method == http.MethodGet && hasSegments(segments, "api", "v2", "workspaces") && len(segments) == 4:
+		s.handleGetWorkspaceByID(w, segments[3])
+		return
+	case r.Method == http.MethodPut && hasSegments(segments, "api", "v2", "workspaces") && len(segments) == 5 &&
+		(segments[4] == "ttl" || segments[4] == "autostart"):
+		s.handleUpdateWorkspaceSchedule(w, r, segments[3], segments[4])
+		return
 	case r.Method == http.MethodPost && hasSegments(segments, "api", "v2", "workspaces") && len(segments) == 5 && segments[4] == "builds":
 		s.handleCreateWorkspaceBuild(w, r, segments[3])
 		return
@@ -3015,8 +6390,29 @@ func (s *mockCoderServerState) handleCreateTemplate(w http.ResponseWriter, r *ht
 		Icon:             request.Icon,
 		ActiveVersionID:  request.VersionID,
 	}
+	if request.TimeTilDormantMillis != nil {
+		template.TimeTilDormantMillis = *request.TimeTilDormantMillis
+	}
+	if request.TimeTilDormantAutoDeleteMillis != nil {
+		template.TimeTilDormantAutoDeleteMillis = *request.TimeTilDormantAutoDeleteMillis
+	}
+	if request.DefaultTTLMillis != nil {
+		template.DefaultTTLMillis = *request.DefaultTTLMillis
+	}
+	if request.ActivityBumpMillis != nil {
+		template.ActivityBumpMillis = *request.ActivityBumpMillis
+	}
+	if request.AutostopRequirement != nil {
+		template.AutostopRequirement = *request.AutostopRequirement
+	}
 
 	s.templatesByID[template.ID] = template
+	// Coder attaches the initial version to the template it creates.
+	if templateVersion, ok := s.templateVersionsByID[request.VersionID]; ok && templateVersion.TemplateID == nil {
+		templateID := template.ID
+		templateVersion.TemplateID = &templateID
+		s.templateVersionsByID[templateVersion.ID] = templateVersion
+	}
 	orgTemplates, ok := s.templateIDsByOrg[s.organization.Name]
 	if !ok {
 		orgTemplates = map[string]uuid.UUID{}
@@ -3027,6 +6423,148 @@ func (s *mockCoderServerState) handleCreateTemplate(w http.ResponseWriter, r *ht
 	writeJSON(w, http.StatusCreated, template)
 }
 
+func (s *mockCoderServerState) handleGetEntitlements(w http.ResponseWriter) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	feature := codersdk.Feature{Entitlement: codersdk.EntitlementNotEntitled}
+	if s.advancedSchedulingEntitled {
+		feature = codersdk.Feature{Entitlement: codersdk.EntitlementEntitled, Enabled: true}
+	}
+
+	templateRBACFeature := codersdk.Feature{Entitlement: codersdk.EntitlementNotEntitled}
+	if s.templateRBACEntitled {
+		templateRBACFeature = codersdk.Feature{Entitlement: codersdk.EntitlementEntitled, Enabled: true}
+	}
+
+	writeJSON(w, http.StatusOK, codersdk.Entitlements{
+		Features: map[codersdk.FeatureName]codersdk.Feature{
+			codersdk.FeatureAdvancedTemplateScheduling: feature,
+			codersdk.FeatureTemplateRBAC:               templateRBACFeature,
+		},
+	})
+}
+
+func (s *mockCoderServerState) handleGetUser(w http.ResponseWriter, username string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	user, ok := s.usersByName[username]
+	if !ok {
+		writeCoderError(w, http.StatusNotFound, "user not found")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, user)
+}
+
+func (s *mockCoderServerState) handleGetGroupByName(w http.ResponseWriter, orgSegment, groupName string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if orgSegment != s.organization.Name && orgSegment != s.organization.ID.String() {
+		writeCoderError(w, http.StatusNotFound, "organization not found")
+		return
+	}
+
+	group, ok := s.groupsByName[groupName]
+	if !ok {
+		writeCoderError(w, http.StatusNotFound, "group not found")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, group)
+}
+
+func (s *mockCoderServerState) handleGetTemplateACL(w http.ResponseWriter, templateIDSegment string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.templateRBACEntitled {
+		writeCoderError(w, http.StatusForbidden, "template RBAC is an enterprise feature")
+		return
+	}
+
+	templateID, err := uuid.Parse(templateIDSegment)
+	if err != nil {
+		writeCoderError(w, http.StatusBadRequest, fmt.Sprintf("invalid template id %q", templateIDSegment))
+		return
+	}
+	if _, ok := s.templatesByID[templateID]; !ok {
+		writeCoderError(w, http.StatusNotFound, "template not found")
+		return
+	}
+
+	acl := codersdk.TemplateACL{Users: []codersdk.TemplateUser{}, Groups: []codersdk.TemplateGroup{}}
+	for _, user := range s.usersByName {
+		if role, ok := s.templateUserRoles[templateID][user.ID]; ok {
+			acl.Users = append(acl.Users, codersdk.TemplateUser{User: user, Role: role})
+		}
+	}
+	for _, group := range s.groupsByName {
+		if role, ok := s.templateGroupRoles[templateID][group.ID]; ok {
+			acl.Groups = append(acl.Groups, codersdk.TemplateGroup{Group: group, Role: role})
+		}
+	}
+
+	writeJSON(w, http.StatusOK, acl)
+}
+
+func (s *mockCoderServerState) handleUpdateTemplateACL(w http.ResponseWriter, r *http.Request, templateIDSegment string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.templateRBACEntitled {
+		writeCoderError(w, http.StatusForbidden, "template RBAC is an enterprise feature")
+		return
+	}
+
+	templateID, err := uuid.Parse(templateIDSegment)
+	if err != nil {
+		writeCoderError(w, http.StatusBadRequest, fmt.Sprintf("invalid template id %q", templateIDSegment))
+		return
+	}
+	if _, ok := s.templatesByID[templateID]; !ok {
+		writeCoderError(w, http.StatusNotFound, "template not found")
+		return
+	}
+
+	var request codersdk.UpdateTemplateACL
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		writeCoderError(w, http.StatusBadRequest, fmt.Sprintf("decode template acl request: %v", err))
+		return
+	}
+	s.templateACLPatchCall++
+
+	applyPerms := func(rolesByTemplate map[uuid.UUID]map[uuid.UUID]codersdk.TemplateRole, perms map[string]codersdk.TemplateRole) error {
+		if rolesByTemplate[templateID] == nil {
+			rolesByTemplate[templateID] = map[uuid.UUID]codersdk.TemplateRole{}
+		}
+		for rawID, role := range perms {
+			id, err := uuid.Parse(rawID)
+			if err != nil {
+				return fmt.Errorf("invalid id %q", rawID)
+			}
+			if role == codersdk.TemplateRoleDeleted {
+				delete(rolesByTemplate[templateID], id)
+				continue
+			}
+			rolesByTemplate[templateID][id] = role
+		}
+		return nil
+	}
+	if err := applyPerms(s.templateUserRoles, request.UserPerms); err != nil {
+		writeCoderError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := applyPerms(s.templateGroupRoles, request.GroupPerms); err != nil {
+		writeCoderError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, codersdk.Response{Message: "Successfully updated template ACL list."})
+}
+
 func (s *mockCoderServerState) handleUploadFile(w http.ResponseWriter, r *http.Request) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -3064,6 +6602,7 @@ func (s *mockCoderServerState) handleGetFile(w http.ResponseWriter, r *http.Requ
 		writeCoderError(w, http.StatusNotFound, "file not found")
 		return
 	}
+	s.fileDownloads++
 
 	w.Header().Set("Content-Type", codersdk.ContentTypeZip)
 	w.WriteHeader(http.StatusOK)
@@ -3092,6 +6631,10 @@ func (s *mockCoderServerState) handleCreateTemplateVersion(w http.ResponseWriter
 		writeCoderError(w, http.StatusNotFound, "file not found")
 		return
 	}
+	if s.templateVersionCreateStatusCode != 0 {
+		writeCoderError(w, s.templateVersionCreateStatusCode, "template version creation is not available")
+		return
+	}
 
 	now := time.Now().UTC()
 	initialStatus := s.nextTemplateVersionInitialStatus
@@ -3108,8 +6651,12 @@ func (s *mockCoderServerState) handleCreateTemplateVersion(w http.ResponseWriter
 		Job: codersdk.ProvisionerJob{
 			FileID: request.FileID,
 			Status: initialStatus,
+			Error:  s.nextTemplateVersionJobError,
 		},
 	}
+	if request.Name != "" {
+		templateVersion.Name = request.Name
+	}
 	if request.TemplateID != uuid.Nil {
 		if _, ok := s.templatesByID[request.TemplateID]; !ok {
 			writeCoderError(w, http.StatusNotFound, "template not found")
@@ -3129,6 +6676,7 @@ func (s *mockCoderServerState) handleCreateTemplateVersion(w http.ResponseWriter
 	// Reset one-shot template version behavior knobs after creation.
 	s.nextTemplateVersionInitialStatus = codersdk.ProvisionerJobSucceeded
 	s.nextTemplateVersionPendingPolls = 0
+	s.nextTemplateVersionJobError = ""
 
 	s.templateVersionsByID[templateVersion.ID] = templateVersion
 
@@ -3166,6 +6714,17 @@ func (s *mockCoderServerState) handleUpdateTemplateMeta(w http.ResponseWriter, r
 	if request.Icon != nil {
 		template.Icon = *request.Icon
 	}
+	template.TimeTilDormantMillis = request.TimeTilDormantMillis
+	template.TimeTilDormantAutoDeleteMillis = request.TimeTilDormantAutoDeleteMillis
+	template.DefaultTTLMillis = request.DefaultTTLMillis
+	template.ActivityBumpMillis = request.ActivityBumpMillis
+	if request.AutostopRequirement != nil {
+		template.AutostopRequirement = *request.AutostopRequirement
+	}
+	if request.DeprecationMessage != nil {
+		template.DeprecationMessage = *request.DeprecationMessage
+		template.Deprecated = *request.DeprecationMessage != ""
+	}
 	template.UpdatedAt = time.Now().UTC()
 
 	s.templatesByID[templateID] = template
@@ -3224,6 +6783,10 @@ func (s *mockCoderServerState) handleUpdateActiveTemplateVersion(w http.Response
 		)
 		return
 	}
+	if templateVersion.Archived {
+		writeCoderError(w, http.StatusBadRequest, "The provided template version is archived.")
+		return
+	}
 
 	if !s.failActiveVersionPromotion {
 		template.ActiveVersionID = request.ID
@@ -3249,6 +6812,12 @@ func (s *mockCoderServerState) handleDeleteTemplate(w http.ResponseWriter, templ
 		writeCoderError(w, http.StatusNotFound, "template not found")
 		return
 	}
+	for _, workspace := range s.workspacesByID {
+		if workspace.TemplateID == templateID && workspace.LatestBuild.Status != codersdk.WorkspaceStatusDeleted {
+			writeCoderError(w, http.StatusBadRequest, "All workspaces must be deleted before a template can be removed.")
+			return
+		}
+	}
 
 	delete(s.templatesByID, templateID)
 	orgTemplates := s.templateIDsByOrg[template.OrganizationName]
@@ -3257,6 +6826,79 @@ func (s *mockCoderServerState) handleDeleteTemplate(w http.ResponseWriter, templ
 	writeJSON(w, http.StatusOK, map[string]string{"message": "template deleted"})
 }
 
+func (s *mockCoderServerState) setPresetListStatusCode(statusCode int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.presetListStatusCode = statusCode
+}
+
+func (s *mockCoderServerState) handleListTemplateVersionPresets(w http.ResponseWriter, templateVersionIDSegment string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	templateVersionID, err := uuid.Parse(templateVersionIDSegment)
+	if err != nil {
+		writeCoderError(w, http.StatusBadRequest, fmt.Sprintf("invalid template version id %q", templateVersionIDSegment))
+		return
+	}
+	if _, ok := s.templateVersionsByID[templateVersionID]; !ok {
+		writeCoderError(w, http.StatusNotFound, "template version not found")
+		return
+	}
+	if s.presetListStatusCode != 0 {
+		writeCoderError(w, s.presetListStatusCode, "list presets failed")
+		return
+	}
+
+	presets := s.presetsByVersionID[templateVersionID]
+	if presets == nil {
+		presets = []codersdk.Preset{}
+	}
+	writeJSON(w, http.StatusOK, presets)
+}
+
+func (s *mockCoderServerState) handleSetArchiveTemplateVersion(w http.ResponseWriter, templateVersionIDSegment string, archive bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	templateVersionID, err := uuid.Parse(templateVersionIDSegment)
+	if err != nil {
+		writeCoderError(w, http.StatusBadRequest, fmt.Sprintf("invalid template version id %q", templateVersionIDSegment))
+		return
+	}
+
+	templateVersion, ok := s.templateVersionsByID[templateVersionID]
+	if !ok {
+		writeCoderError(w, http.StatusNotFound, "template version not found")
+		return
+	}
+	if templateVersion.Archived == archive {
+		writeCoderError(w, http.StatusBadRequest, fmt.Sprintf("template version archived is already %t", archive))
+		return
+	}
+	if archive && templateVersion.TemplateID != nil {
+		if template, ok := s.templatesByID[*templateVersion.TemplateID]; ok && template.ActiveVersionID == templateVersionID {
+			writeCoderError(w, http.StatusBadRequest, "cannot archive the active template version")
+			return
+		}
+	}
+
+	templateVersion.Archived = archive
+	templateVersion.UpdatedAt = time.Now().UTC()
+	s.templateVersionsByID[templateVersionID] = templateVersion
+
+	writeJSON(w, http.StatusOK, map[string]string{"message": "template version archive state updated"})
+}
+
+func (s *mockCoderServerState) templateVersionArchived(templateVersionID uuid.UUID) (bool, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	templateVersion, ok := s.templateVersionsByID[templateVersionID]
+	return templateVersion.Archived, ok
+}
+
 func (s *mockCoderServerState) handleGetTemplateVersion(w http.ResponseWriter, templateVersionIDSegment string) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -3293,12 +6935,29 @@ func (s *mockCoderServerState) handleGetTemplateVersion(w http.ResponseWriter, t
 	writeJSON(w, http.StatusOK, templateVersion)
 }
 
-func (s *mockCoderServerState) handleListWorkspaces(w http.ResponseWriter) {
+func (s *mockCoderServerState) handleListWorkspaces(w http.ResponseWriter, r *http.Request) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	query := r.URL.Query().Get("q")
+	s.workspaceListQueries = append(s.workspaceListQueries, query)
+
+	ownerFilter := ""
+	for _, term := range strings.Fields(query) {
+		if rawOwner, ok := strings.CutPrefix(term, "owner:"); ok {
+			owner, err := strconv.Unquote(rawOwner)
+			if err != nil {
+				owner = rawOwner
+			}
+			ownerFilter = owner
+		}
+	}
+
 	workspaces := make([]codersdk.Workspace, 0, len(s.workspacesByID))
 	for _, workspace := range s.workspacesByID {
+		if ownerFilter != "" && workspace.OwnerName != ownerFilter {
+			continue
+		}
 		workspaces = append(workspaces, workspace)
 	}
 	sort.Slice(workspaces, func(i, j int) bool {
@@ -3337,12 +6996,30 @@ func (s *mockCoderServerState) handleCreateWorkspace(w http.ResponseWriter, r *h
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	if _, ok := s.usersByName[user]; !ok {
+		writeCoderError(w, http.StatusNotFound, "user not found")
+		return
+	}
+
 	var request codersdk.CreateWorkspaceRequest
 	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
 		writeCoderError(w, http.StatusBadRequest, fmt.Sprintf("decode create workspace request: %v", err))
 		return
 	}
 
+	// Coder parses the autostart schedule and reports failures as a field-level
+	// validation error.
+	if request.AutostartSchedule != nil && !strings.HasPrefix(*request.AutostartSchedule, "CRON_TZ=") {
+		writeJSON(w, http.StatusBadRequest, codersdk.Response{
+			Message: "Invalid Autostart Schedule.",
+			Validations: []codersdk.ValidationError{{
+				Field:  "autostart_schedule",
+				Detail: "parse schedule: schedule must specify a CRON_TZ timezone",
+			}},
+		})
+		return
+	}
+
 	templateID := request.TemplateID
 	templateVersionID := request.TemplateVersionID
 	if templateID == uuid.Nil && templateVersionID == uuid.Nil {
@@ -3377,56 +7054,149 @@ func (s *mockCoderServerState) handleCreateWorkspace(w http.ResponseWriter, r *h
 			return
 		}
 
-		templateID = *templateVersion.TemplateID
+		templateID = *templateVersion.TemplateID
+	}
+
+	template, ok := s.templatesByID[templateID]
+	if !ok {
+		writeCoderError(w, http.StatusNotFound, "template not found")
+		return
+	}
+	if templateVersionID == uuid.Nil {
+		templateVersionID = template.ActiveVersionID
+	}
+
+	var presetID *uuid.UUID
+	if request.TemplateVersionPresetID != uuid.Nil {
+		found := false
+		for _, preset := range s.presetsByVersionID[templateVersionID] {
+			if preset.ID == request.TemplateVersionPresetID {
+				found = true
+				break
+			}
+		}
+		if !found {
+			writeCoderError(
+				w,
+				http.StatusBadRequest,
+				fmt.Sprintf("preset %q does not belong to template version %q", request.TemplateVersionPresetID, templateVersionID),
+			)
+			return
+		}
+		presetIDCopy := request.TemplateVersionPresetID
+		presetID = &presetIDCopy
+	}
+	s.workspaceCreateRequests = append(s.workspaceCreateRequests, request)
+
+	now := time.Now().UTC()
+	workspaceID := uuid.New()
+	build := codersdk.WorkspaceBuild{
+		ID:                      uuid.New(),
+		CreatedAt:               now,
+		UpdatedAt:               now,
+		WorkspaceID:             workspaceID,
+		WorkspaceName:           request.Name,
+		WorkspaceOwnerName:      user,
+		TemplateVersionID:       templateVersionID,
+		TemplateVersionPresetID: presetID,
+		Transition:              codersdk.WorkspaceTransitionStart,
+		Status:                  codersdk.WorkspaceStatusRunning,
+	}
+	workspace := codersdk.Workspace{
+		ID:                workspaceID,
+		CreatedAt:         now,
+		UpdatedAt:         now,
+		OwnerName:         user,
+		OrganizationID:    template.OrganizationID,
+		OrganizationName:  template.OrganizationName,
+		TemplateID:        template.ID,
+		TemplateName:      template.Name,
+		Name:              request.Name,
+		TTLMillis:         request.TTLMillis,
+		AutostartSchedule: request.AutostartSchedule,
+		LastUsedAt:        now,
+		LatestBuild:       build,
+	}
+
+	s.workspacesByID[workspace.ID] = workspace
+	userWorkspaces, ok := s.workspaceIDsByUser[user]
+	if !ok {
+		userWorkspaces = map[string]uuid.UUID{}
+		s.workspaceIDsByUser[user] = userWorkspaces
+	}
+	userWorkspaces[workspace.Name] = workspace.ID
+
+	writeJSON(w, http.StatusCreated, workspace)
+}
+
+func (s *mockCoderServerState) handleGetWorkspaceByID(w http.ResponseWriter, workspaceIDSegment string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	workspaceID, err := uuid.Parse(workspaceIDSegment)
+	if err != nil {
+		writeCoderError(w, http.StatusBadRequest, fmt.Sprintf("invalid workspace id %q", workspaceIDSegment))
+		return
+	}
+
+	workspace, ok := s.workspacesByID[workspaceID]
+	if !ok {
+		writeCoderError(w, http.StatusNotFound, "workspace not found")
+		return
+	}
+
+	if pollsRemaining, hasPendingPolls := s.workspaceDeletePollsBeforeDone[workspaceID]; hasPendingPolls {
+		pollsRemaining--
+		if pollsRemaining <= 0 {
+			workspace.LatestBuild.Status = codersdk.WorkspaceStatusDeleted
+			delete(s.workspaceDeletePollsBeforeDone, workspaceID)
+		} else {
+			s.workspaceDeletePollsBeforeDone[workspaceID] = pollsRemaining
+		}
+		s.workspacesByID[workspaceID] = workspace
+	}
+
+	writeJSON(w, http.StatusOK, workspace)
+}
+
+func (s *mockCoderServerState) handleUpdateWorkspaceSchedule(w http.ResponseWriter, r *http.Request, workspaceIDSegment, setting string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	workspaceID, err := uuid.Parse(workspaceIDSegment)
+	if err != nil {
+		writeCoderError(w, http.StatusBadRequest, fmt.Sprintf("invalid workspace id %q", workspaceIDSegment))
+		return
 	}
 
-	template, ok := s.templatesByID[templateID]
+	workspace, ok := s.workspacesByID[workspaceID]
 	if !ok {
-		writeCoderError(w, http.StatusNotFound, "template not found")
+		writeCoderError(w, http.StatusNotFound, "workspace not found")
 		return
 	}
-	if templateVersionID == uuid.Nil {
-		templateVersionID = template.ActiveVersionID
-	}
 
-	now := time.Now().UTC()
-	workspaceID := uuid.New()
-	build := codersdk.WorkspaceBuild{
-		ID:                 uuid.New(),
-		CreatedAt:          now,
-		UpdatedAt:          now,
-		WorkspaceID:        workspaceID,
-		WorkspaceName:      request.Name,
-		WorkspaceOwnerName: user,
-		TemplateVersionID:  templateVersionID,
-		Transition:         codersdk.WorkspaceTransitionStart,
-		Status:             codersdk.WorkspaceStatusRunning,
-	}
-	workspace := codersdk.Workspace{
-		ID:                workspaceID,
-		CreatedAt:         now,
-		UpdatedAt:         now,
-		OwnerName:         user,
-		OrganizationID:    template.OrganizationID,
-		OrganizationName:  template.OrganizationName,
-		TemplateID:        template.ID,
-		TemplateName:      template.Name,
-		Name:              request.Name,
-		TTLMillis:         request.TTLMillis,
-		AutostartSchedule: request.AutostartSchedule,
-		LastUsedAt:        now,
-		LatestBuild:       build,
+	switch setting {
+	case "ttl":
+		var request codersdk.UpdateWorkspaceTTLRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			writeCoderError(w, http.StatusBadRequest, fmt.Sprintf("decode update workspace ttl request: %v", err))
+			return
+		}
+		workspace.TTLMillis = request.TTLMillis
+	case "autostart":
+		var request codersdk.UpdateWorkspaceAutostartRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			writeCoderError(w, http.StatusBadRequest, fmt.Sprintf("decode update workspace autostart request: %v", err))
+			return
+		}
+		workspace.AutostartSchedule = request.Schedule
 	}
+	workspace.UpdatedAt = time.Now().UTC()
 
-	s.workspacesByID[workspace.ID] = workspace
-	userWorkspaces, ok := s.workspaceIDsByUser[user]
-	if !ok {
-		userWorkspaces = map[string]uuid.UUID{}
-		s.workspaceIDsByUser[user] = userWorkspaces
-	}
-	userWorkspaces[workspace.Name] = workspace.ID
+	s.workspacesByID[workspaceID] = workspace
+	s.workspaceScheduleUpdates = append(s.workspaceScheduleUpdates, setting)
 
-	writeJSON(w, http.StatusCreated, workspace)
+	w.WriteHeader(http.StatusNoContent)
 }
 
 func (s *mockCoderServerState) handleCreateWorkspaceBuild(w http.ResponseWriter, r *http.Request, workspaceIDSegment string) {
@@ -3468,6 +7238,11 @@ func (s *mockCoderServerState) handleCreateWorkspaceBuild(w http.ResponseWriter,
 		Transition:         request.Transition,
 		Status:             statusFromTransition(request.Transition),
 	}
+	if request.Transition == codersdk.WorkspaceTransitionDelete && s.nextWorkspaceDeletePendingPolls > 0 {
+		build.Status = codersdk.WorkspaceStatusDeleting
+		s.workspaceDeletePollsBeforeDone[workspace.ID] = s.nextWorkspaceDeletePendingPolls
+		s.nextWorkspaceDeletePendingPolls = 0
+	}
 
 	workspace.LatestBuild = build
 	workspace.UpdatedAt = now
@@ -3523,6 +7298,25 @@ func (s *mockCoderServerState) templateActiveVersionID(organization, templateNam
 	return template.ActiveVersionID, true
 }
 
+func (s *mockCoderServerState) fileDownloadCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.fileDownloads
+}
+
+func (s *mockCoderServerState) templateVersionName(templateVersionID uuid.UUID) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	templateVersion, ok := s.templateVersionsByID[templateVersionID]
+	if !ok {
+		return "", false
+	}
+
+	return templateVersion.Name, true
+}
+
 func (s *mockCoderServerState) templateActiveSourceZip(organization, templateName string) ([]byte, bool) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -3561,6 +7355,27 @@ func (s *mockCoderServerState) templateMetaUpdateCount() int {
 	return s.templateMetaPatchCall
 }
 
+func (s *mockCoderServerState) setAdvancedSchedulingEntitled(entitled bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.advancedSchedulingEntitled = entitled
+}
+
+func (s *mockCoderServerState) setTemplateRBACEntitled(entitled bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.templateRBACEntitled = entitled
+}
+
+func (s *mockCoderServerState) templateACLUpdateCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.templateACLPatchCall
+}
+
 func (s *mockCoderServerState) setFailActiveVersionPromotion(fail bool) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -3580,6 +7395,37 @@ func (s *mockCoderServerState) setNextCreatedTemplateVersionStatus(status coders
 	s.nextTemplateVersionPendingPolls = 0
 }
 
+func (s *mockCoderServerState) setNextCreatedTemplateVersionFailed(jobError string) {
+	if jobError == "" {
+		panic("assertion failed: template version job error must not be empty")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.nextTemplateVersionInitialStatus = codersdk.ProvisionerJobFailed
+	s.nextTemplateVersionPendingPolls = 0
+	s.nextTemplateVersionJobError = jobError
+}
+
+func (s *mockCoderServerState) setTemplateVersionCreateStatusCode(statusCode int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.templateVersionCreateStatusCode = statusCode
+}
+
+func (s *mockCoderServerState) setNextWorkspaceDeletePendingForPolls(polls int) {
+	if polls <= 0 {
+		panic(fmt.Sprintf("assertion failed: pending poll count must be > 0, got %d", polls))
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.nextWorkspaceDeletePendingPolls = polls
+}
+
 func (s *mockCoderServerState) setNextCreatedTemplateVersionPendingForPolls(polls int) {
 	if polls <= 0 {
 		panic(fmt.Sprintf("assertion failed: pending poll count must be > 0, got %d", polls))
@@ -3592,6 +7438,174 @@ func (s *mockCoderServerState) setNextCreatedTemplateVersionPendingForPolls(poll
 	s.nextTemplateVersionPendingPolls = polls
 }
 
+func (s *mockCoderServerState) templateIDByName(organization, templateName string) (uuid.UUID, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	orgTemplates, ok := s.templateIDsByOrg[organization]
+	if !ok {
+		return uuid.Nil, false
+	}
+
+	templateID, ok := orgTemplates[templateName]
+	return templateID, ok
+}
+
+// addTemplateWithActiveVersion seeds a template in the default organization
+// whose active version is named versionName.
+func (s *mockCoderServerState) addTemplateWithActiveVersion(name, versionName string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now().UTC()
+	templateID := uuid.New()
+	templateIDCopy := templateID
+	version := codersdk.TemplateVersion{
+		ID:             uuid.New(),
+		TemplateID:     &templateIDCopy,
+		OrganizationID: s.organization.ID,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+		Name:           versionName,
+		Job: codersdk.ProvisionerJob{
+			Status: codersdk.ProvisionerJobSucceeded,
+		},
+	}
+	s.templateVersionsByID[version.ID] = version
+	s.templatesByID[templateID] = codersdk.Template{
+		ID:               templateID,
+		CreatedAt:        now,
+		UpdatedAt:        now,
+		OrganizationID:   s.organization.ID,
+		OrganizationName: s.organization.Name,
+		Name:             name,
+		ActiveVersionID:  version.ID,
+	}
+	s.templateIDsByOrg[s.organization.Name][name] = templateID
+}
+
+// addOrganizationResources seeds a template and a workspace that belong to a
+// second Coder organization, as returned by the deployment-wide list endpoints.
+func (s *mockCoderServerState) addOrganizationResources(orgName string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now().UTC()
+	orgID := uuid.New()
+	template := codersdk.Template{
+		ID:               uuid.New(),
+		CreatedAt:        now,
+		UpdatedAt:        now,
+		OrganizationID:   orgID,
+		OrganizationName: orgName,
+		Name:             "other-template",
+		ActiveVersionID:  uuid.New(),
+	}
+	templateIDCopy := template.ID
+	s.templateVersionsByID[template.ActiveVersionID] = codersdk.TemplateVersion{
+		ID:             template.ActiveVersionID,
+		TemplateID:     &templateIDCopy,
+		OrganizationID: orgID,
+		Name:           "other-template-v1",
+		Job:            codersdk.ProvisionerJob{Status: codersdk.ProvisionerJobSucceeded},
+	}
+	s.templatesByID[template.ID] = template
+	s.templateIDsByOrg[orgName] = map[string]uuid.UUID{template.Name: template.ID}
+
+	workspace := codersdk.Workspace{
+		ID:               uuid.New(),
+		CreatedAt:        now,
+		UpdatedAt:        now,
+		OwnerName:        "alice",
+		OrganizationID:   orgID,
+		OrganizationName: orgName,
+		TemplateID:       template.ID,
+		TemplateName:     template.Name,
+		Name:             "other-workspace",
+	}
+	s.workspacesByID[workspace.ID] = workspace
+	s.workspaceIDsByUser["alice"][workspace.Name] = workspace.ID
+}
+
+// addDetachedTemplateVersion seeds a built template version that does not
+// belong to a template yet, as used by template creation from spec.versionID.
+func (s *mockCoderServerState) addDetachedTemplateVersion() uuid.UUID {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now().UTC()
+	version := codersdk.TemplateVersion{
+		ID:             uuid.New(),
+		OrganizationID: s.organization.ID,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+		Name:           "detached-version",
+		Job: codersdk.ProvisionerJob{
+			Status: codersdk.ProvisionerJobSucceeded,
+		},
+	}
+	s.templateVersionsByID[version.ID] = version
+
+	return version.ID
+}
+
+func (s *mockCoderServerState) addSucceededTemplateVersion(templateID uuid.UUID) uuid.UUID {
+	if templateID == uuid.Nil {
+		panic("assertion failed: template ID must not be nil")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Reuse the active version's source so the new version can be promoted
+	// and read back like any other.
+	var fileID uuid.UUID
+	if template, ok := s.templatesByID[templateID]; ok {
+		fileID = s.templateVersionsByID[template.ActiveVersionID].Job.FileID
+	}
+
+	now := time.Now().UTC()
+	templateIDCopy := templateID
+	version := codersdk.TemplateVersion{
+		ID:             uuid.New(),
+		TemplateID:     &templateIDCopy,
+		OrganizationID: s.organization.ID,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+		Name:           "promotable-version",
+		Job: codersdk.ProvisionerJob{
+			FileID: fileID,
+			Status: codersdk.ProvisionerJobSucceeded,
+		},
+	}
+	s.templateVersionsByID[version.ID] = version
+
+	return version.ID
+}
+
+// addTemplateVersionPreset seeds a preset with the given parameter values on a
+// template version.
+func (s *mockCoderServerState) addTemplateVersionPreset(
+	templateVersionID uuid.UUID,
+	name string,
+	parameters map[string]string,
+) uuid.UUID {
+	if templateVersionID == uuid.Nil {
+		panic("assertion failed: template version ID must not be nil")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	preset := codersdk.Preset{ID: uuid.New(), Name: name}
+	for parameterName, value := range parameters {
+		preset.Parameters = append(preset.Parameters, codersdk.PresetParameter{Name: parameterName, Value: value})
+	}
+	s.presetsByVersionID[templateVersionID] = append(s.presetsByVersionID[templateVersionID], preset)
+
+	return preset.ID
+}
+
 func (s *mockCoderServerState) setTemplateVersionTemplateID(templateVersionID, templateID uuid.UUID) {
 	if templateVersionID == uuid.Nil {
 		panic("assertion failed: template version ID must not be nil")
@@ -3619,6 +7633,50 @@ func (s *mockCoderServerState) setTemplateVersionTemplateID(templateVersionID, t
 	s.templateVersionsByID[templateVersionID] = version
 }
 
+func (s *mockCoderServerState) workspaceListQueriesSnapshot() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]string(nil), s.workspaceListQueries...)
+}
+
+func (s *mockCoderServerState) workspaceCreateRequestsSnapshot() []codersdk.CreateWorkspaceRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]codersdk.CreateWorkspaceRequest(nil), s.workspaceCreateRequests...)
+}
+
+// addWorkspaceCopy seeds another workspace for owner, cloned from the seeded
+// acme.alice.dev-workspace.
+func (s *mockCoderServerState) addWorkspaceCopy(owner, workspaceName string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var seeded codersdk.Workspace
+	for _, workspace := range s.workspacesByID {
+		seeded = workspace
+		break
+	}
+
+	workspace := seeded
+	workspace.ID = uuid.New()
+	workspace.OwnerName = owner
+	workspace.Name = workspaceName
+	workspace.LatestBuild.ID = uuid.New()
+	workspace.LatestBuild.WorkspaceID = workspace.ID
+	workspace.LatestBuild.WorkspaceName = workspaceName
+	workspace.LatestBuild.WorkspaceOwnerName = owner
+
+	s.workspacesByID[workspace.ID] = workspace
+	userWorkspaces, ok := s.workspaceIDsByUser[owner]
+	if !ok {
+		userWorkspaces = map[string]uuid.UUID{}
+		s.workspaceIDsByUser[owner] = userWorkspaces
+	}
+	userWorkspaces[workspaceName] = workspace.ID
+}
+
 func (s *mockCoderServerState) hasWorkspace(owner, workspaceName string) bool {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -3653,6 +7711,80 @@ func (s *mockCoderServerState) workspaceLatestBuildTemplateVersionID(owner, work
 	return workspace.LatestBuild.TemplateVersionID, true
 }
 
+func (s *mockCoderServerState) setWorkspaceLatestTransition(owner, workspaceName string, transition codersdk.WorkspaceTransition) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	workspaceID, ok := s.workspaceIDsByUser[owner][workspaceName]
+	if !ok {
+		panic(fmt.Sprintf("assertion failed: workspace %s/%s not found", owner, workspaceName))
+	}
+	workspace := s.workspacesByID[workspaceID]
+	workspace.LatestBuild.Transition = transition
+	workspace.LatestBuild.Status = statusFromTransition(transition)
+	workspace.LatestBuild.UpdatedAt = time.Now().UTC()
+	workspace.UpdatedAt = workspace.LatestBuild.UpdatedAt
+	s.workspacesByID[workspaceID] = workspace
+}
+
+// setWorkspaceAgents replaces the latest build's agents and derives workspace
+// health from them the way Coder does.
+func (s *mockCoderServerState) setWorkspaceAgents(owner, workspaceName string, agents ...codersdk.WorkspaceAgent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	workspaceID, ok := s.workspaceIDsByUser[owner][workspaceName]
+	if !ok {
+		panic(fmt.Sprintf("assertion failed: workspace %s/%s not found", owner, workspaceName))
+	}
+	workspace := s.workspacesByID[workspaceID]
+	failingAgents := []uuid.UUID{}
+	for _, agent := range agents {
+		if !agent.Health.Healthy {
+			failingAgents = append(failingAgents, agent.ID)
+		}
+	}
+	workspace.LatestBuild.Resources = []codersdk.WorkspaceResource{{
+		ID:     uuid.New(),
+		Name:   "dev",
+		Type:   "kubernetes_deployment",
+		Agents: agents,
+	}}
+	workspace.Health = codersdk.WorkspaceHealth{
+		Healthy:       len(failingAgents) == 0,
+		FailingAgents: failingAgents,
+	}
+	s.workspacesByID[workspaceID] = workspace
+}
+
+// setWorkspaceBuildTiming records when the latest build's provisioner job ran
+// and the build's daily cost.
+func (s *mockCoderServerState) setWorkspaceBuildTiming(
+	owner, workspaceName string,
+	startedAt, completedAt time.Time,
+	dailyCost int32,
+) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	workspaceID, ok := s.workspaceIDsByUser[owner][workspaceName]
+	if !ok {
+		panic(fmt.Sprintf("assertion failed: workspace %s/%s not found", owner, workspaceName))
+	}
+	workspace := s.workspacesByID[workspaceID]
+	workspace.LatestBuild.Job.StartedAt = &startedAt
+	workspace.LatestBuild.Job.CompletedAt = &completedAt
+	workspace.LatestBuild.DailyCost = dailyCost
+	s.workspacesByID[workspaceID] = workspace
+}
+
+func (s *mockCoderServerState) workspaceScheduleUpdatesSnapshot() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]string(nil), s.workspaceScheduleUpdates...)
+}
+
 func (s *mockCoderServerState) buildTransitionsSnapshot() []codersdk.WorkspaceTransition {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -5,8 +5,10 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net/http"
 	"os"
 	"reflect"
+	"slices"
 	"sort"
 	"strings"
 	"sync"
@@ -53,9 +55,12 @@ var (
 	_ rest.Watcher              = (*TemplateStorage)(nil)
 	_ rest.Creater              = (*TemplateStorage)(nil) //nolint:misspell // Kubernetes rest interface name is Creater.
 	_ rest.Updater              = (*TemplateStorage)(nil)
+	_ rest.Patcher              = (*TemplateStorage)(nil)
 	_ rest.GracefulDeleter      = (*TemplateStorage)(nil)
 	_ rest.Scoper               = (*TemplateStorage)(nil)
 	_ rest.SingularNameProvider = (*TemplateStorage)(nil)
+	_ rest.ShortNamesProvider   = (*TemplateStorage)(nil)
+	_ rest.CategoriesProvider   = (*TemplateStorage)(nil)
 
 	errTemplateVersionBuildWaitTimeoutExceeded = errors.New("template version build wait timeout exceeded")
 )
@@ -68,6 +73,14 @@ type TemplateStorage struct {
 	watchEvents    chan watch.Event
 	watchEventsWG  sync.WaitGroup
 	destroyOnce    sync.Once
+	managedFields  *managedFieldsStore
+	listCache      *listCache
+	filesCache     *templateFilesCache
+	listFanOutMode ListFanOutMode
+
+	// gitSourceFetcher resolves spec.gitSource. Nil rejects spec.gitSource so
+	// the server makes no outbound Git requests unless enabled.
+	gitSourceFetcher TemplateSourceFetcher
 }
 
 // NewTemplateStorage builds codersdk-backed storage for CoderTemplate resources.
@@ -81,6 +94,9 @@ func NewTemplateStorage(provider coder.ClientProvider) *TemplateStorage {
 		tableConvertor: rest.NewDefaultTableConvertor(aggregationv1alpha1.Resource("codertemplates")),
 		broadcaster:    watch.NewBroadcaster(watchBroadcasterQueueLen, watch.DropIfChannelFull),
 		watchEvents:    make(chan watch.Event, watchBroadcasterQueueLen),
+		managedFields:  newManagedFieldsStore(),
+		listCache:      newListCache(listCacheTTL),
+		filesCache:     newTemplateFilesCache(templateFilesCacheMaxEntries, templateFilesCacheMaxBytes),
 	}
 	storage.watchEventsWG.Add(1)
 	go storage.dispatchWatchEvents()
@@ -88,6 +104,27 @@ func NewTemplateStorage(provider coder.ClientProvider) *TemplateStorage {
 	return storage
 }
 
+// SetGitSourceFetcher enables spec.gitSource, resolving it with fetcher.
+// It must be called before the storage serves requests; nil disables it.
+func (s *TemplateStorage) SetGitSourceFetcher(fetcher TemplateSourceFetcher) {
+	if s == nil {
+		panic("assertion failed: template storage must not be nil")
+	}
+
+	s.gitSourceFetcher = fetcher
+}
+
+// SetListFanOutMode sets how an all-namespaces LIST handles a failing
+// namespace. It must be called before the storage serves requests; the zero
+// value is ListFanOutModeStrict.
+func (s *TemplateStorage) SetListFanOutMode(mode ListFanOutMode) {
+	if s == nil {
+		panic("assertion failed: template storage must not be nil")
+	}
+
+	s.listFanOutMode = mode
+}
+
 // New returns an empty CoderTemplate object.
 func (s *TemplateStorage) New() runtime.Object {
 	return &aggregationv1alpha1.CoderTemplate{}
@@ -122,6 +159,16 @@ func (s *TemplateStorage) GetSingularName() string {
 	return "codertemplate"
 }
 
+// ShortNames returns the kubectl short names of the CoderTemplate resource.
+func (s *TemplateStorage) ShortNames() []string {
+	return []string{"ctpl"}
+}
+
+// Categories returns the kubectl categories of the CoderTemplate resource.
+func (s *TemplateStorage) Categories() []string {
+	return []string{coderResourceCategory}
+}
+
 // NewList returns an empty CoderTemplateList object.
 func (s *TemplateStorage) NewList() runtime.Object {
 	return &aggregationv1alpha1.CoderTemplateList{}
@@ -164,19 +211,64 @@ func (s *TemplateStorage) Get(ctx context.Context, name string, _ *metav1.GetOpt
 		return nil, coder.MapCoderError(err, aggregationv1alpha1.Resource("codertemplates"), name)
 	}
 
+	return s.templateObject(ctx, sdk, namespace, name, template)
+}
+
+// templateObject converts template into the CoderTemplate that Get returns,
+// with its source files, active version name, and ACL.
+func (s *TemplateStorage) templateObject(
+	ctx context.Context,
+	sdk *codersdk.Client,
+	namespace string,
+	name string,
+	template codersdk.Template,
+) (*aggregationv1alpha1.CoderTemplate, error) {
+	if sdk == nil {
+		return nil, fmt.Errorf("assertion failed: codersdk client must not be nil")
+	}
+
 	obj := convert.TemplateToK8s(namespace, template)
+	obj.ManagedFields = s.managedFields.get(obj)
 
-	files, err := fetchTemplateSourceFiles(ctx, sdk, template.ActiveVersionID)
-	if err != nil {
-		return nil, fmt.Errorf("fetch template source files: %w", err)
+	// Source files only change with the template's updatedAt, so repeated
+	// gets of an unchanged template skip the source download.
+	filesKey := templateFilesCacheKey{
+		namespace:       namespace,
+		templateID:      template.ID,
+		updatedAt:       template.UpdatedAt.UnixNano(),
+		activeVersionID: template.ActiveVersionID,
+	}
+	files, ok := s.filesCache.get(filesKey)
+	if !ok {
+		var err error
+		files, err = fetchTemplateSourceFiles(ctx, sdk, template.ActiveVersionID)
+		if err != nil {
+			return nil, fmt.Errorf("fetch template source files: %w", err)
+		}
+		s.filesCache.put(filesKey, files)
 	}
 	obj.Spec.Files = files
 
+	activeVersion, err := sdk.TemplateVersion(ctx, template.ActiveVersionID)
+	if err != nil {
+		return nil, coder.MapCoderError(err, aggregationv1alpha1.Resource("codertemplates"), name)
+	}
+	obj.Status.ActiveVersionName = activeVersion.Name
+	obj.Status.SourceTreeHash = templateSourceTreeHashFromMessage(activeVersion.Message)
+
+	acl, err := fetchTemplateACL(ctx, sdk, template.ID)
+	if err != nil {
+		return nil, coder.MapCoderError(err, aggregationv1alpha1.Resource("codertemplates"), name)
+	}
+	obj.Spec.ACL = acl
+
 	return obj, nil
 }
 
-// List fetches CoderTemplate objects from codersdk.
-func (s *TemplateStorage) List(ctx context.Context, _ *metainternalversion.ListOptions) (runtime.Object, error) {
+// List fetches CoderTemplate objects from codersdk. Requests with
+// resourceVersion=0 are served from a short-lived cache of recent responses
+// when one exists.
+func (s *TemplateStorage) List(ctx context.Context, opts *metainternalversion.ListOptions) (runtime.Object, error) {
 	if s == nil {
 		return nil, fmt.Errorf("assertion failed: template storage must not be nil")
 	}
@@ -189,6 +281,25 @@ func (s *TemplateStorage) List(ctx context.Context, _ *metainternalversion.ListO
 		return nil, badNamespaceErr
 	}
 
+	cacheKey := listCacheKey(namespace, opts)
+	if listCacheRequested(opts) {
+		if list, ok := s.listCache.get(cacheKey); ok {
+			return list, nil
+		}
+	}
+
+	list, err := s.list(ctx, namespace)
+	if err != nil {
+		return nil, err
+	}
+	s.listCache.put(cacheKey, list)
+
+	return list, nil
+}
+
+// list fetches CoderTemplate objects for namespace from codersdk, fanning out
+// across eligible namespaces when namespace is empty.
+func (s *TemplateStorage) list(ctx context.Context, namespace string) (runtime.Object, error) {
 	if namespace == "" {
 		if lister, ok := s.provider.(coder.NamespaceLister); ok {
 			namespaces, err := lister.EligibleNamespaces(ctx)
@@ -196,28 +307,43 @@ func (s *TemplateStorage) List(ctx context.Context, _ *metainternalversion.ListO
 				return nil, err
 			}
 
-			list := &aggregationv1alpha1.CoderTemplateList{
-				TypeMeta: metav1.TypeMeta{
-					Kind:       "CoderTemplateList",
-					APIVersion: aggregationv1alpha1.SchemeGroupVersion.String(),
-				},
-				Items: make([]aggregationv1alpha1.CoderTemplate, 0),
-			}
-
-			for _, eligibleNamespace := range namespaces {
+			items, err := fanOutList(ctx, s.listFanOutMode, namespaces, func(ctx context.Context, eligibleNamespace string) ([]aggregationv1alpha1.CoderTemplate, error) {
 				sdk, err := s.clientForNamespace(ctx, eligibleNamespace)
 				if err != nil {
 					return nil, wrapClientError(err)
 				}
+				allowed, err := allowedOrganizations(ctx, s.provider, eligibleNamespace)
+				if err != nil {
+					return nil, err
+				}
 
 				templates, err := sdk.Templates(ctx, codersdk.TemplateFilter{})
 				if err != nil {
 					return nil, coder.MapCoderError(err, aggregationv1alpha1.Resource("codertemplates"), "<list>")
 				}
 
+				items := make([]aggregationv1alpha1.CoderTemplate, 0, len(templates))
 				for _, template := range templates {
-					list.Items = append(list.Items, *convert.TemplateToK8s(eligibleNamespace, template))
+					if !allowed.allows(template.OrganizationName) {
+						continue
+					}
+					items = append(items, *convert.TemplateToK8s(eligibleNamespace, template))
+				}
+				if err := populateActiveVersionNames(ctx, sdk, items); err != nil {
+					return nil, err
 				}
+				return items, nil
+			})
+			if err != nil {
+				return nil, err
+			}
+
+			list := &aggregationv1alpha1.CoderTemplateList{
+				TypeMeta: metav1.TypeMeta{
+					Kind:       "CoderTemplateList",
+					APIVersion: aggregationv1alpha1.SchemeGroupVersion.String(),
+				},
+				Items: items,
 			}
 
 			sort.Slice(list.Items, func(i, j int) bool {
@@ -240,6 +366,10 @@ func (s *TemplateStorage) List(ctx context.Context, _ *metainternalversion.ListO
 	if err != nil {
 		return nil, wrapClientError(err)
 	}
+	allowed, err := allowedOrganizations(ctx, s.provider, responseNamespace)
+	if err != nil {
+		return nil, err
+	}
 
 	templates, err := sdk.Templates(ctx, codersdk.TemplateFilter{})
 	if err != nil {
@@ -255,8 +385,18 @@ func (s *TemplateStorage) List(ctx context.Context, _ *metainternalversion.ListO
 	}
 
 	for _, template := range templates {
+		if !allowed.allows(template.OrganizationName) {
+			continue
+		}
 		list.Items = append(list.Items, *convert.TemplateToK8s(responseNamespace, template))
 	}
+	if err := populateActiveVersionNames(ctx, sdk, list.Items); err != nil {
+		return nil, err
+	}
+
+	sort.Slice(list.Items, func(i, j int) bool {
+		return list.Items[i].Name < list.Items[j].Name
+	})
 
 	return list, nil
 }
@@ -330,7 +470,7 @@ func (s *TemplateStorage) Create(
 	ctx context.Context,
 	obj runtime.Object,
 	createValidation rest.ValidateObjectFunc,
-	_ *metav1.CreateOptions,
+	opts *metav1.CreateOptions,
 ) (runtime.Object, error) {
 	if s == nil {
 		return nil, fmt.Errorf("assertion failed: template storage must not be nil")
@@ -382,16 +522,82 @@ func (s *TemplateStorage) Create(
 		)
 	}
 
+	if err := convert.ValidateTemplateCleanupThresholds(templateObj.Spec); err != nil {
+		return nil, apierrors.NewBadRequest(fmt.Sprintf("invalid template spec: %v", err))
+	}
+	if err := convert.ValidateTemplateSchedule(templateObj.Spec); err != nil {
+		return nil, apierrors.NewBadRequest(fmt.Sprintf("invalid template spec: %v", err))
+	}
+	if err := convert.ValidateTemplateACL(templateObj.Spec.ACL); err != nil {
+		return nil, apierrors.NewBadRequest(fmt.Sprintf("invalid template spec: %v", err))
+	}
+	if templateObj.Spec.Files != nil {
+		if err := validateTemplateFiles(templateObj.Spec.Files); err != nil {
+			return nil, err
+		}
+		// Upload and echo the normalized paths so "./main.tf" and "main.tf"
+		// refer to the same file and nested directories keep their layout.
+		normalizedFiles, err := normalizeFileKeys(templateObj.Spec.Files)
+		if err != nil {
+			return nil, newTemplateFilesBadRequest(err)
+		}
+		templateObj = templateObj.DeepCopy()
+		templateObj.Spec.Files = normalizedFiles
+	}
+	if templateObj.Spec.GitSource != nil && templateObj.Spec.Files != nil {
+		return nil, apierrors.NewBadRequest("invalid template spec: spec.files and spec.gitSource are mutually exclusive")
+	}
+
 	sdk, err := s.clientForNamespace(ctx, namespace)
 	if err != nil {
 		return nil, wrapClientError(err)
 	}
 
-	org, err := sdk.OrganizationByName(ctx, orgName)
+	org, err := organizationForCreate(ctx, sdk, orgName, aggregationv1alpha1.Resource("codertemplates"), templateObj.Name)
 	if err != nil {
-		return nil, coder.MapCoderError(err, aggregationv1alpha1.Resource("codertemplates"), templateObj.Name)
+		return nil, err
+	}
+
+	if convert.TemplateCleanupThresholdsSet(templateObj.Spec) {
+		if err := requireAdvancedTemplateScheduling(ctx, sdk, templateObj.Name); err != nil {
+			return nil, err
+		}
+	}
+
+	// Resolve ACL names before creating the template so unknown users or
+	// groups do not leave a half-configured template behind.
+	var desiredACL *resolvedTemplateACL
+	if templateObj.Spec.ACL != nil {
+		supported, err := templateACLSupported(ctx, sdk, templateObj.Name)
+		if err != nil {
+			return nil, err
+		}
+		if supported {
+			desiredACL, err = resolveTemplateACL(ctx, sdk, org.ID, templateObj.Name, templateObj.Spec.ACL)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	// Upload a Git source like spec.files; the tree hash recorded in the
+	// version message lets later updates skip unchanged trees.
+	var versionMessage string
+	if templateObj.Spec.GitSource != nil {
+		source, err := s.resolveTemplateGitSource(ctx, templateObj.Spec.GitSource)
+		if err != nil {
+			return nil, err
+		}
+		versionMessage = templateGitSourceVersionMessage(templateObj.Spec.GitSource, source.TreeHash)
+		templateObj = templateObj.DeepCopy()
+		templateObj.Spec.Files = source.Files
+	}
+
+	if opts != nil && isDryRun(opts.DryRun) {
+		return s.dryRunCreate(ctx, sdk, namespace, org.ID, templateName, templateObj)
 	}
 
+	var createdTemplate codersdk.Template
 	if templateObj.Spec.Files != nil {
 		zipBytes, err := buildSourceZip(templateObj.Spec.Files)
 		if err != nil {
@@ -404,6 +610,8 @@ func (s *TemplateStorage) Create(
 		}
 
 		templateVersion, err := sdk.CreateTemplateVersion(ctx, org.ID, codersdk.CreateTemplateVersionRequest{
+			Name:          templateObj.Spec.VersionName,
+			Message:       versionMessage,
 			StorageMethod: codersdk.ProvisionerStorageMethodFile,
 			FileID:        uploadResponse.ID,
 			Provisioner:   codersdk.ProvisionerTypeTerraform,
@@ -412,47 +620,104 @@ func (s *TemplateStorage) Create(
 			return nil, coder.MapCoderError(err, aggregationv1alpha1.Resource("codertemplates"), templateObj.Name)
 		}
 
-		createdTemplate, err := sdk.CreateTemplate(ctx, org.ID, codersdk.CreateTemplateRequest{
+		createdTemplate, err = sdk.CreateTemplate(ctx, org.ID, codersdk.CreateTemplateRequest{
 			Name:        templateName,
 			VersionID:   templateVersion.ID,
 			DisplayName: templateObj.Spec.DisplayName,
 			Description: templateObj.Spec.Description,
 			Icon:        templateObj.Spec.Icon,
+
+			TimeTilDormantMillis:           templateObj.Spec.DormancyThresholdMillis,
+			TimeTilDormantAutoDeleteMillis: templateObj.Spec.AutoDeleteThresholdMillis,
+
+			DefaultTTLMillis:    templateObj.Spec.DefaultTTLMillis,
+			ActivityBumpMillis:  templateObj.Spec.ActivityBumpMillis,
+			AutostopRequirement: convert.TemplateAutostopRequirementFromK8s(templateObj.Spec.AutostopRequirement),
 		})
 		if err != nil {
 			return nil, coder.MapCoderError(err, aggregationv1alpha1.Resource("codertemplates"), templateObj.Name)
 		}
+	} else {
+		request, err := convert.TemplateCreateRequestFromK8s(templateObj, templateName)
+		if err != nil {
+			return nil, apierrors.NewBadRequest(err.Error())
+		}
 
-		result := convert.TemplateToK8s(namespace, createdTemplate)
-		if result == nil {
-			return nil, fmt.Errorf("assertion failed: converted template must not be nil")
+		createdTemplate, err = sdk.CreateTemplate(ctx, org.ID, request)
+		if err != nil {
+			return nil, coder.MapCoderError(err, aggregationv1alpha1.Resource("codertemplates"), templateObj.Name)
 		}
+	}
 
-		s.enqueueWatchEvent(watch.Added, result.DeepCopy())
+	// Coder cannot deprecate a template on creation, so deprecate it right after.
+	if templateObj.Spec.DeprecationMessage != nil && *templateObj.Spec.DeprecationMessage != "" {
+		createdTemplate, err = sdk.UpdateTemplateMeta(ctx, createdTemplate.ID, convert.TemplateUpdateMetaRequestFromK8s(templateObj))
+		if err != nil {
+			return nil, coder.MapCoderError(err, aggregationv1alpha1.Resource("codertemplates"), templateObj.Name)
+		}
+	}
 
-		return result, nil
+	if desiredACL != nil {
+		if err := applyTemplateACL(ctx, sdk, createdTemplate.ID, templateObj.Name, desiredACL); err != nil {
+			return nil, err
+		}
 	}
 
-	request, err := convert.TemplateCreateRequestFromK8s(templateObj, templateName)
-	if err != nil {
-		return nil, apierrors.NewBadRequest(err.Error())
+	result := convert.TemplateToK8s(namespace, createdTemplate)
+	if result == nil {
+		return nil, fmt.Errorf("assertion failed: converted template must not be nil")
 	}
 
-	createdTemplate, err := sdk.CreateTemplate(ctx, org.ID, request)
+	activeVersion, err := sdk.TemplateVersion(ctx, createdTemplate.ActiveVersionID)
 	if err != nil {
 		return nil, coder.MapCoderError(err, aggregationv1alpha1.Resource("codertemplates"), templateObj.Name)
 	}
+	result.Status.ActiveVersionName = activeVersion.Name
+	result.Status.SourceTreeHash = templateSourceTreeHashFromMessage(activeVersion.Message)
 
-	result := convert.TemplateToK8s(namespace, createdTemplate)
-	if result == nil {
-		return nil, fmt.Errorf("assertion failed: converted template must not be nil")
+	acl, err := fetchTemplateACL(ctx, sdk, createdTemplate.ID)
+	if err != nil {
+		return nil, coder.MapCoderError(err, aggregationv1alpha1.Resource("codertemplates"), templateObj.Name)
 	}
+	result.Spec.ACL = acl
+	s.managedFields.record(result, templateObj.ManagedFields)
 
 	s.enqueueWatchEvent(watch.Added, result.DeepCopy())
 
 	return result, nil
 }
 
+// dryRunCreate validates a template create without uploading files or creating
+// the template, and returns the object the create would produce.
+func (s *TemplateStorage) dryRunCreate(
+	ctx context.Context,
+	sdk *codersdk.Client,
+	namespace string,
+	orgID uuid.UUID,
+	templateName string,
+	templateObj *aggregationv1alpha1.CoderTemplate,
+) (runtime.Object, error) {
+	if templateObj.Spec.Files != nil {
+		if _, err := buildSourceZip(templateObj.Spec.Files); err != nil {
+			return nil, apierrors.NewBadRequest(fmt.Sprintf("invalid template spec.files: %v", err))
+		}
+	} else if _, err := convert.TemplateCreateRequestFromK8s(templateObj, templateName); err != nil {
+		return nil, apierrors.NewBadRequest(err.Error())
+	}
+
+	_, err := sdk.TemplateByName(ctx, orgID, templateName)
+	switch {
+	case err == nil:
+		return nil, apierrors.NewAlreadyExists(aggregationv1alpha1.Resource("codertemplates"), templateObj.Name)
+	case coderStatusCode(err) != http.StatusNotFound:
+		return nil, coder.MapCoderError(err, aggregationv1alpha1.Resource("codertemplates"), templateObj.Name)
+	}
+
+	result := templateObj.DeepCopy()
+	result.Namespace = namespace
+	return result, nil
+}
+
 // Update applies a template metadata/source reconcile.
 func (s *TemplateStorage) Update(
 	ctx context.Context,
@@ -461,7 +726,7 @@ func (s *TemplateStorage) Update(
 	createValidation rest.ValidateObjectFunc,
 	updateValidation rest.ValidateObjectUpdateFunc,
 	forceAllowCreate bool,
-	_ *metav1.UpdateOptions,
+	opts *metav1.UpdateOptions,
 ) (runtime.Object, bool, error) {
 	if s == nil {
 		return nil, false, fmt.Errorf("assertion failed: template storage must not be nil")
@@ -523,7 +788,7 @@ func (s *TemplateStorage) Update(
 			)
 		}
 
-		createdObj, createErr := s.Create(ctx, createTemplate, createValidation, nil)
+		createdObj, createErr := s.Create(ctx, createTemplate, createValidation, createOptionsForDryRun(opts))
 		if createErr != nil {
 			return nil, false, createErr
 		}
@@ -585,6 +850,9 @@ func (s *TemplateStorage) Update(
 			return nil, false, err
 		}
 	}
+	if err := rejectAppliedStatus(updatedTemplate.ManagedFields); err != nil {
+		return nil, false, err
+	}
 	if updatedTemplate.Spec.Organization != currentTemplate.Spec.Organization {
 		return nil, false, apierrors.NewBadRequest(
 			fmt.Sprintf(
@@ -595,19 +863,59 @@ func (s *TemplateStorage) Update(
 		)
 	}
 
-	// spec.versionID is informational (populated from the backend active version).
-	// Reject explicit non-empty mutations to avoid silent drift when the active version remains unchanged.
-	// Allow empty desired values so GitOps clients can omit this informational field on updates.
-	if updatedTemplate.Spec.VersionID != "" && updatedTemplate.Spec.VersionID != currentTemplate.Spec.VersionID {
-		return nil, false, apierrors.NewBadRequest(
-			fmt.Sprintf(
-				"spec.versionID is read-only; to change the active version, update spec.files instead (current: %q, requested: %q)",
-				currentTemplate.Spec.VersionID,
-				updatedTemplate.Spec.VersionID,
-			),
-		)
+	// spec.versionID reports the backend active version. Changing it promotes an
+	// existing version of this template without uploading files; empty desired
+	// values are allowed so GitOps clients can omit the field on updates.
+	versionIDChanged := updatedTemplate.Spec.VersionID != "" && updatedTemplate.Spec.VersionID != currentTemplate.Spec.VersionID
+	var promotedVersionID uuid.UUID
+	var promotedVersion codersdk.TemplateVersion
+	if versionIDChanged {
+		promotedVersionID, err = uuid.Parse(updatedTemplate.Spec.VersionID)
+		if err != nil {
+			return nil, false, apierrors.NewBadRequest(
+				fmt.Sprintf("spec.versionID %q must be a template version UUID", updatedTemplate.Spec.VersionID),
+			)
+		}
 	}
 
+	// Omitted cleanup thresholds keep their current backend values.
+	if updatedTemplate.Spec.DormancyThresholdMillis == nil {
+		updatedTemplate.Spec.DormancyThresholdMillis = currentTemplate.Spec.DormancyThresholdMillis
+	}
+	if updatedTemplate.Spec.AutoDeleteThresholdMillis == nil {
+		updatedTemplate.Spec.AutoDeleteThresholdMillis = currentTemplate.Spec.AutoDeleteThresholdMillis
+	}
+	if err := convert.ValidateTemplateCleanupThresholds(updatedTemplate.Spec); err != nil {
+		return nil, false, apierrors.NewBadRequest(fmt.Sprintf("invalid template spec: %v", err))
+	}
+	if err := convert.ValidateTemplateACL(updatedTemplate.Spec.ACL); err != nil {
+		return nil, false, apierrors.NewBadRequest(fmt.Sprintf("invalid template spec: %v", err))
+	}
+	// Omitted schedule and deprecation fields keep their current backend values too.
+	if updatedTemplate.Spec.DeprecationMessage == nil {
+		updatedTemplate.Spec.DeprecationMessage = currentTemplate.Spec.DeprecationMessage
+	}
+	if updatedTemplate.Spec.DefaultTTLMillis == nil {
+		updatedTemplate.Spec.DefaultTTLMillis = currentTemplate.Spec.DefaultTTLMillis
+	}
+	if updatedTemplate.Spec.ActivityBumpMillis == nil {
+		updatedTemplate.Spec.ActivityBumpMillis = currentTemplate.Spec.ActivityBumpMillis
+	}
+	if updatedTemplate.Spec.AutostopRequirement == nil {
+		updatedTemplate.Spec.AutostopRequirement = currentTemplate.Spec.AutostopRequirement
+	}
+	if err := convert.ValidateTemplateSchedule(updatedTemplate.Spec); err != nil {
+		return nil, false, apierrors.NewBadRequest(fmt.Sprintf("invalid template spec: %v", err))
+	}
+	policyChanged := !equalStringPtrOrEmpty(updatedTemplate.Spec.DeprecationMessage, currentTemplate.Spec.DeprecationMessage) ||
+		!equalInt64PtrOrZero(updatedTemplate.Spec.DefaultTTLMillis, currentTemplate.Spec.DefaultTTLMillis) ||
+		!equalInt64PtrOrZero(updatedTemplate.Spec.ActivityBumpMillis, currentTemplate.Spec.ActivityBumpMillis) ||
+		!equalAutostopRequirement(updatedTemplate.Spec.AutostopRequirement, currentTemplate.Spec.AutostopRequirement)
+	// An omitted spec.acl leaves the current ACL unchanged.
+	aclChanged := updatedTemplate.Spec.ACL != nil && !convert.TemplateACLEqual(updatedTemplate.Spec.ACL, currentTemplate.Spec.ACL)
+	cleanupThresholdsChanged := !equalInt64PtrOrZero(updatedTemplate.Spec.DormancyThresholdMillis, currentTemplate.Spec.DormancyThresholdMillis) ||
+		!equalInt64PtrOrZero(updatedTemplate.Spec.AutoDeleteThresholdMillis, currentTemplate.Spec.AutoDeleteThresholdMillis)
+
 	templateID, err := uuid.Parse(currentTemplate.Status.ID)
 	if err != nil {
 		return nil, false, fmt.Errorf("parse current template status.id %q: %w", currentTemplate.Status.ID, err)
@@ -618,9 +926,60 @@ func (s *TemplateStorage) Update(
 		return nil, false, wrapClientError(err)
 	}
 
+	if cleanupThresholdsChanged && convert.TemplateCleanupThresholdsSet(updatedTemplate.Spec) {
+		if err := requireAdvancedTemplateScheduling(ctx, sdk, name); err != nil {
+			return nil, false, err
+		}
+	}
+
+	var desiredACL *resolvedTemplateACL
+	if aclChanged {
+		supported, err := templateACLSupported(ctx, sdk, name)
+		if err != nil {
+			return nil, false, err
+		}
+		if supported {
+			org, err := sdk.OrganizationByName(ctx, currentTemplate.Spec.Organization)
+			if err != nil {
+				return nil, false, coder.MapCoderError(err, aggregationv1alpha1.Resource("codertemplates"), name)
+			}
+			desiredACL, err = resolveTemplateACL(ctx, sdk, org.ID, name, updatedTemplate.Spec.ACL)
+			if err != nil {
+				return nil, false, err
+			}
+		}
+	}
+
 	// Pre-validate spec.files before any mutations to avoid partial updates.
-	var normalizedDesiredFiles map[string]string
-	if updatedTemplate.Spec.Files != nil {
+	// spec.gitSource takes precedence; spec.files then only echoes the
+	// imported contents returned by GET and is ignored.
+	var (
+		gitSource              *TemplateSource
+		gitSourceZip           []byte
+		normalizedDesiredFiles map[string]string
+	)
+	if updatedTemplate.Spec.GitSource != nil {
+		if versionIDChanged {
+			return nil, false, apierrors.NewBadRequest(
+				"invalid template spec: spec.versionID cannot change together with spec.gitSource",
+			)
+		}
+		source, err := s.resolveTemplateGitSource(ctx, updatedTemplate.Spec.GitSource)
+		if err != nil {
+			return nil, false, err
+		}
+		gitSource = &source
+		// An unchanged tree hash means an unchanged commit tree, so no upload is needed.
+		if source.TreeHash != currentTemplate.Status.SourceTreeHash {
+			gitSourceZip, err = buildSourceZip(source.Files)
+			if err != nil {
+				return nil, false, apierrors.NewBadRequest(fmt.Sprintf("invalid template spec.gitSource: %v", err))
+			}
+		}
+	} else if updatedTemplate.Spec.Files != nil {
+		if err := validateTemplateFiles(updatedTemplate.Spec.Files); err != nil {
+			return nil, false, err
+		}
 		var normalizeErr error
 		normalizedDesiredFiles, normalizeErr = normalizeFileKeys(updatedTemplate.Spec.Files)
 		if normalizeErr != nil {
@@ -632,9 +991,29 @@ func (s *TemplateStorage) Update(
 		}
 	}
 
+	if versionIDChanged {
+		promotedVersion, err = validateTemplateVersionPromotion(ctx, sdk, currentTemplate, templateID, promotedVersionID, normalizedDesiredFiles, name)
+		if err != nil {
+			return nil, false, err
+		}
+	}
+
+	// Every check above is read-only; a dry run stops before the first mutation.
+	if opts != nil && isDryRun(opts.DryRun) {
+		if gitSource != nil {
+			updatedTemplate.Spec.Files = gitSource.Files
+			updatedTemplate.Status.SourceTreeHash = gitSource.TreeHash
+		} else if updatedTemplate.Spec.Files != nil {
+			updatedTemplate.Spec.Files = normalizedDesiredFiles
+		}
+		return updatedTemplate, false, nil
+	}
+
 	metadataChanged := updatedTemplate.Spec.DisplayName != currentTemplate.Spec.DisplayName ||
 		updatedTemplate.Spec.Description != currentTemplate.Spec.Description ||
-		updatedTemplate.Spec.Icon != currentTemplate.Spec.Icon
+		updatedTemplate.Spec.Icon != currentTemplate.Spec.Icon ||
+		cleanupThresholdsChanged ||
+		policyChanged
 	if metadataChanged {
 		_, err := sdk.UpdateTemplateMeta(ctx, templateID, convert.TemplateUpdateMetaRequestFromK8s(updatedTemplate))
 		if err != nil {
@@ -642,7 +1021,28 @@ func (s *TemplateStorage) Update(
 		}
 	}
 
-	if updatedTemplate.Spec.Files != nil {
+	if versionIDChanged {
+		if _, err := promoteTemplateVersion(ctx, sdk, templateID, promotedVersion, name); err != nil {
+			return nil, false, err
+		}
+	} else if gitSource != nil {
+		if gitSourceZip != nil {
+			if err := uploadAndPromoteTemplateVersion(
+				ctx,
+				sdk,
+				currentTemplate.Spec.Organization,
+				templateID,
+				gitSourceZip,
+				codersdk.CreateTemplateVersionRequest{
+					Name:    updatedTemplate.Spec.VersionName,
+					Message: templateGitSourceVersionMessage(updatedTemplate.Spec.GitSource, gitSource.TreeHash),
+				},
+				name,
+			); err != nil {
+				return nil, false, err
+			}
+		}
+	} else if updatedTemplate.Spec.Files != nil {
 		if normalizedDesiredFiles == nil {
 			return nil, false, fmt.Errorf("assertion failed: normalized desired template files must not be nil when spec.files is provided")
 		}
@@ -672,54 +1072,23 @@ func (s *TemplateStorage) Update(
 				return nil, false, apierrors.NewBadRequest(fmt.Sprintf("invalid template spec.files: %v", err))
 			}
 
-			uploadResponse, err := sdk.Upload(ctx, codersdk.ContentTypeZip, bytes.NewReader(zipBytes))
-			if err != nil {
-				return nil, false, coder.MapCoderError(err, aggregationv1alpha1.Resource("codertemplates"), name)
-			}
-			if uploadResponse.ID == uuid.Nil {
-				return nil, false, fmt.Errorf("assertion failed: uploaded file ID must not be nil")
-			}
-
-			org, err := sdk.OrganizationByName(ctx, currentTemplate.Spec.Organization)
-			if err != nil {
-				return nil, false, coder.MapCoderError(err, aggregationv1alpha1.Resource("codertemplates"), name)
-			}
-
-			newVersion, err := sdk.CreateTemplateVersion(ctx, org.ID, codersdk.CreateTemplateVersionRequest{
-				TemplateID:    templateID,
-				StorageMethod: codersdk.ProvisionerStorageMethodFile,
-				FileID:        uploadResponse.ID,
-				Provisioner:   codersdk.ProvisionerTypeTerraform,
-			})
-			if err != nil {
-				return nil, false, coder.MapCoderError(err, aggregationv1alpha1.Resource("codertemplates"), name)
-			}
-			if newVersion.ID == uuid.Nil {
-				return nil, false, fmt.Errorf("assertion failed: new template version ID must not be nil")
-			}
-
-			if waitErr := waitForTemplateVersionBuild(ctx, sdk, newVersion.ID); waitErr != nil {
-				return nil, false, mapTemplateVersionBuildWaitError(waitErr, name)
-			}
-
-			if err := sdk.UpdateActiveTemplateVersion(ctx, templateID, codersdk.UpdateActiveTemplateVersion{ID: newVersion.ID}); err != nil {
-				return nil, false, coder.MapCoderError(err, aggregationv1alpha1.Resource("codertemplates"), name)
+			if err := uploadAndPromoteTemplateVersion(
+				ctx,
+				sdk,
+				currentTemplate.Spec.Organization,
+				templateID,
+				zipBytes,
+				codersdk.CreateTemplateVersionRequest{Name: updatedTemplate.Spec.VersionName},
+				name,
+			); err != nil {
+				return nil, false, err
 			}
+		}
+	}
 
-			// Post-condition: verify promotion succeeded. The vendored SDK silently
-			// swallows transport errors in UpdateActiveTemplateVersion, so we must
-			// confirm the active version actually changed.
-			verifyTemplate, err := sdk.Template(ctx, templateID)
-			if err != nil {
-				return nil, false, coder.MapCoderError(err, aggregationv1alpha1.Resource("codertemplates"), name)
-			}
-			if verifyTemplate.ActiveVersionID != newVersion.ID {
-				return nil, false, fmt.Errorf(
-					"assertion failed: active version promotion did not take effect: expected %q, got %q",
-					newVersion.ID.String(),
-					verifyTemplate.ActiveVersionID.String(),
-				)
-			}
+	if desiredACL != nil {
+		if err := applyTemplateACL(ctx, sdk, templateID, name, desiredACL); err != nil {
+			return nil, false, err
 		}
 	}
 
@@ -735,18 +1104,183 @@ func (s *TemplateStorage) Update(
 	if result == nil {
 		return nil, false, fmt.Errorf("assertion failed: refreshed template must not be nil")
 	}
+	s.managedFields.record(result, updatedTemplate.ManagedFields)
 
 	s.enqueueWatchEvent(watch.Modified, result.DeepCopy())
 
 	return result, false, nil
 }
 
-// Delete deletes a CoderTemplate through codersdk.
+// uploadAndPromoteTemplateVersion uploads zipBytes as a new version of the
+// template, waits for its build, and makes it the active version. Name and
+// Message are taken from request; the remaining fields are filled in here.
+func uploadAndPromoteTemplateVersion(
+	ctx context.Context,
+	sdk *codersdk.Client,
+	organization string,
+	templateID uuid.UUID,
+	zipBytes []byte,
+	request codersdk.CreateTemplateVersionRequest,
+	name string,
+) error {
+	newVersion, err := uploadTemplateVersion(ctx, sdk, organization, templateID, zipBytes, request)
+	if err != nil {
+		return coder.MapCoderError(err, aggregationv1alpha1.Resource("codertemplates"), name)
+	}
+
+	if waitErr := waitForTemplateVersionBuild(ctx, sdk, newVersion.ID); waitErr != nil {
+		return mapTemplateVersionBuildWaitError(waitErr, name)
+	}
+
+	if _, err := promoteTemplateVersion(ctx, sdk, templateID, newVersion, name); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// uploadTemplateVersion uploads zipBytes and creates an inactive version of
+// the template from it, without waiting for the version to build. Coder API
+// errors are returned unmapped so callers can inspect the status code.
+func uploadTemplateVersion(
+	ctx context.Context,
+	sdk *codersdk.Client,
+	organization string,
+	templateID uuid.UUID,
+	zipBytes []byte,
+	request codersdk.CreateTemplateVersionRequest,
+) (codersdk.TemplateVersion, error) {
+	uploadResponse, err := sdk.Upload(ctx, codersdk.ContentTypeZip, bytes.NewReader(zipBytes))
+	if err != nil {
+		return codersdk.TemplateVersion{}, err
+	}
+	if uploadResponse.ID == uuid.Nil {
+		return codersdk.TemplateVersion{}, fmt.Errorf("assertion failed: uploaded file ID must not be nil")
+	}
+
+	org, err := sdk.OrganizationByName(ctx, organization)
+	if err != nil {
+		return codersdk.TemplateVersion{}, err
+	}
+
+	request.TemplateID = templateID
+	request.StorageMethod = codersdk.ProvisionerStorageMethodFile
+	request.FileID = uploadResponse.ID
+	request.Provisioner = codersdk.ProvisionerTypeTerraform
+	newVersion, err := sdk.CreateTemplateVersion(ctx, org.ID, request)
+	if err != nil {
+		return codersdk.TemplateVersion{}, err
+	}
+	if newVersion.ID == uuid.Nil {
+		return codersdk.TemplateVersion{}, fmt.Errorf("assertion failed: new template version ID must not be nil")
+	}
+
+	return newVersion, nil
+}
+
+// validateTemplateVersionPromotion checks that versionID is an existing version
+// of the template and that spec.files, when set, still matches the current
+// active version, so a version promotion never discards a files change. It
+// returns the version.
+func validateTemplateVersionPromotion(
+	ctx context.Context,
+	sdk *codersdk.Client,
+	currentTemplate *aggregationv1alpha1.CoderTemplate,
+	templateID uuid.UUID,
+	versionID uuid.UUID,
+	normalizedDesiredFiles map[string]string,
+	name string,
+) (codersdk.TemplateVersion, error) {
+	if currentTemplate == nil {
+		return codersdk.TemplateVersion{}, fmt.Errorf("assertion failed: current template must not be nil")
+	}
+
+	version, err := sdk.TemplateVersion(ctx, versionID)
+	if err != nil {
+		if coderStatusCode(err) == http.StatusNotFound {
+			return codersdk.TemplateVersion{}, apierrors.NewBadRequest(
+				fmt.Sprintf("spec.versionID %q is not a version of template %q", versionID.String(), name),
+			)
+		}
+		return codersdk.TemplateVersion{}, coder.MapCoderError(err, aggregationv1alpha1.Resource("codertemplates"), name)
+	}
+	if version.TemplateID == nil || *version.TemplateID != templateID {
+		return codersdk.TemplateVersion{}, apierrors.NewBadRequest(
+			fmt.Sprintf("spec.versionID %q is not a version of template %q", versionID.String(), name),
+		)
+	}
+
+	if normalizedDesiredFiles == nil {
+		return version, nil
+	}
+	currentActiveVersionID, err := uuid.Parse(currentTemplate.Status.ActiveVersionID)
+	if err != nil {
+		return codersdk.TemplateVersion{}, fmt.Errorf(
+			"parse current template status.activeVersionID %q: %w",
+			currentTemplate.Status.ActiveVersionID,
+			err,
+		)
+	}
+	currentFiles, err := fetchTemplateSourceFiles(ctx, sdk, currentActiveVersionID)
+	if err != nil {
+		return codersdk.TemplateVersion{}, fmt.Errorf("fetch current template source files: %w", err)
+	}
+	if !reflect.DeepEqual(normalizedDesiredFiles, currentFiles) {
+		return codersdk.TemplateVersion{}, apierrors.NewBadRequest(
+			"spec.versionID and spec.files cannot both change in one update; promote the version or upload new files",
+		)
+	}
+
+	return version, nil
+}
+
+// promoteTemplateVersion makes version the template's active version and
+// returns the template read back after the promotion. An archived version,
+// such as one built by lint, is unarchived first because Coder refuses to
+// promote it.
+func promoteTemplateVersion(
+	ctx context.Context,
+	sdk *codersdk.Client,
+	templateID uuid.UUID,
+	version codersdk.TemplateVersion,
+	name string,
+) (codersdk.Template, error) {
+	versionID := version.ID
+	if version.Archived {
+		if err := sdk.SetArchiveTemplateVersion(ctx, versionID, false); err != nil {
+			return codersdk.Template{}, coder.MapCoderError(err, aggregationv1alpha1.Resource("codertemplates"), name)
+		}
+	}
+	if err := sdk.UpdateActiveTemplateVersion(ctx, templateID, codersdk.UpdateActiveTemplateVersion{ID: versionID}); err != nil {
+		return codersdk.Template{}, coder.MapCoderError(err, aggregationv1alpha1.Resource("codertemplates"), name)
+	}
+
+	// Post-condition: verify promotion succeeded. The vendored SDK silently
+	// swallows transport errors in UpdateActiveTemplateVersion, so we must
+	// confirm the active version actually changed.
+	promotedTemplate, err := sdk.Template(ctx, templateID)
+	if err != nil {
+		return codersdk.Template{}, coder.MapCoderError(err, aggregationv1alpha1.Resource("codertemplates"), name)
+	}
+	if promotedTemplate.ActiveVersionID != versionID {
+		return codersdk.Template{}, fmt.Errorf(
+			"assertion failed: active version promotion did not take effect: expected %q, got %q",
+			versionID.String(),
+			promotedTemplate.ActiveVersionID.String(),
+		)
+	}
+
+	return promotedTemplate, nil
+}
+
+// Delete deletes a CoderTemplate through codersdk. A template that workspaces
+// still use is rejected unless the request asks for foreground propagation, in
+// which case those workspaces are deleted first.
 func (s *TemplateStorage) Delete(
 	ctx context.Context,
 	name string,
 	deleteValidation rest.ValidateObjectFunc,
-	_ *metav1.DeleteOptions,
+	opts *metav1.DeleteOptions,
 ) (runtime.Object, bool, error) {
 	if s == nil {
 		return nil, false, fmt.Errorf("assertion failed: template storage must not be nil")
@@ -792,14 +1326,25 @@ func (s *TemplateStorage) Delete(
 		}
 	}
 
-	if err := sdk.DeleteTemplate(ctx, template.ID); err != nil {
-		return nil, false, coder.MapCoderError(err, aggregationv1alpha1.Resource("codertemplates"), name)
+	dryRun := opts != nil && isDryRun(opts.DryRun)
+	if err := removeTemplateWorkspaces(ctx, sdk, template, name, templateDeleteCascades(opts), dryRun); err != nil {
+		return nil, false, err
 	}
 
 	templateObj := convert.TemplateToK8s(namespace, template)
 	if templateObj == nil {
 		return nil, false, fmt.Errorf("assertion failed: converted template must not be nil")
 	}
+	if dryRun {
+		return templateObj, true, nil
+	}
+
+	if err := sdk.DeleteTemplate(ctx, template.ID); err != nil {
+		return nil, false, coder.MapCoderError(err, aggregationv1alpha1.Resource("codertemplates"), name)
+	}
+
+	s.managedFields.forget(namespace, name)
+	s.filesCache.forget(namespace, template.ID)
 
 	// Emit a Deleted event with the last-known template state.
 	s.enqueueWatchEvent(watch.Deleted, templateObj.DeepCopy())
@@ -819,6 +1364,9 @@ func (s *TemplateStorage) enqueueWatchEvent(action watch.EventType, obj runtime.
 	if s == nil {
 		panic("assertion failed: template storage must not be nil")
 	}
+
+	// Writes through this API server must not be hidden by cached lists.
+	s.listCache.reset()
 	if s.watchEvents == nil {
 		panic("assertion failed: template watch event queue must not be nil")
 	}
@@ -1208,5 +1756,74 @@ func (s *TemplateStorage) clientForNamespace(ctx context.Context, namespace stri
 		return nil, fmt.Errorf("assertion failed: template client provider returned nil codersdk client")
 	}
 
-	return sdk, nil
+	return coder.WithRequestPolicy(sdk)
+}
+
+// requireAdvancedTemplateScheduling rejects template cleanup thresholds when the
+// backing Coder deployment is not entitled to advanced template scheduling.
+func requireAdvancedTemplateScheduling(ctx context.Context, sdk *codersdk.Client, name string) error {
+	if sdk == nil {
+		return fmt.Errorf("assertion failed: codersdk client must not be nil")
+	}
+
+	entitlements, err := sdk.Entitlements(ctx)
+	if err != nil {
+		return coder.MapCoderError(err, aggregationv1alpha1.Resource("codertemplates"), name)
+	}
+
+	feature, ok := entitlements.Features[codersdk.FeatureAdvancedTemplateScheduling]
+	if !ok || !feature.Enabled || feature.Entitlement == codersdk.EntitlementNotEntitled {
+		return apierrors.NewForbidden(
+			aggregationv1alpha1.Resource("codertemplates"),
+			name,
+			fmt.Errorf(
+				"spec.dormancyThresholdMillis and spec.autoDeleteThresholdMillis require the Coder %q entitlement",
+				codersdk.FeatureAdvancedTemplateScheduling,
+			),
+		)
+	}
+
+	return nil
+}
+
+func equalInt64PtrOrZero(a, b *int64) bool {
+	var aValue, bValue int64
+	if a != nil {
+		aValue = *a
+	}
+	if b != nil {
+		bValue = *b
+	}
+
+	return aValue == bValue
+}
+
+func equalStringPtrOrEmpty(a, b *string) bool {
+	var aValue, bValue string
+	if a != nil {
+		aValue = *a
+	}
+	if b != nil {
+		bValue = *b
+	}
+
+	return aValue == bValue
+}
+
+// equalAutostopRequirement treats requirements without days as equal: none of
+// them requires a stop.
+func equalAutostopRequirement(a, b *aggregationv1alpha1.CoderTemplateAutostopRequirement) bool {
+	var aDays, bDays []string
+	var aWeeks, bWeeks int64
+	if a != nil {
+		aDays, aWeeks = a.DaysOfWeek, a.Weeks
+	}
+	if b != nil {
+		bDays, bWeeks = b.DaysOfWeek, b.Weeks
+	}
+	if len(aDays) == 0 && len(bDays) == 0 {
+		return true
+	}
+
+	return slices.Equal(aDays, bDays) && aWeeks == bWeeks
 }
@@ -0,0 +1,170 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/google/uuid"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apiserver/pkg/warning"
+
+	aggregationv1alpha1 "github.com/coder/coder-k8s/api/aggregation/v1alpha1"
+	"github.com/coder/coder-k8s/internal/aggregated/coder"
+	"github.com/coder/coder-k8s/internal/aggregated/convert"
+	"github.com/coder/coder/v2/codersdk"
+)
+
+// resolvedTemplateACL is a CoderTemplate ACL keyed by Coder user and group IDs.
+type resolvedTemplateACL struct {
+	userRoles  map[string]codersdk.TemplateRole
+	groupRoles map[string]codersdk.TemplateRole
+}
+
+// fetchTemplateACL returns the template's ACL, or nil when the Coder deployment
+// does not serve template ACLs (AGPL builds or missing template_rbac
+// entitlement).
+func fetchTemplateACL(ctx context.Context, sdk *codersdk.Client, templateID uuid.UUID) (*aggregationv1alpha1.CoderTemplateACL, error) {
+	if sdk == nil {
+		return nil, fmt.Errorf("assertion failed: codersdk client must not be nil")
+	}
+
+	acl, err := sdk.TemplateACL(ctx, templateID)
+	if err != nil {
+		if coderStatusCode(err) == http.StatusNotFound || coderStatusCode(err) == http.StatusForbidden {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("fetch template %q acl: %w", templateID, err)
+	}
+
+	return convert.TemplateACLToK8s(acl), nil
+}
+
+// templateACLSupported reports whether the Coder deployment is entitled to
+// template ACLs. When it is not, a client warning explains that spec.acl was
+// skipped; the rest of the request still applies.
+func templateACLSupported(ctx context.Context, sdk *codersdk.Client, name string) (bool, error) {
+	if sdk == nil {
+		return false, fmt.Errorf("assertion failed: codersdk client must not be nil")
+	}
+
+	entitlements, err := sdk.Entitlements(ctx)
+	if err != nil {
+		return false, coder.MapCoderError(err, aggregationv1alpha1.Resource("codertemplates"), name)
+	}
+
+	feature, ok := entitlements.Features[codersdk.FeatureTemplateRBAC]
+	if !ok || !feature.Enabled || feature.Entitlement == codersdk.EntitlementNotEntitled {
+		warning.AddWarning(ctx, "", fmt.Sprintf(
+			"codertemplate %q: spec.acl was not applied because the Coder deployment is not entitled to %q",
+			name,
+			codersdk.FeatureTemplateRBAC,
+		))
+		return false, nil
+	}
+
+	return true, nil
+}
+
+// resolveTemplateACL looks up the Coder IDs of every user and group in acl so
+// unknown names are rejected before the template is modified.
+func resolveTemplateACL(
+	ctx context.Context,
+	sdk *codersdk.Client,
+	orgID uuid.UUID,
+	name string,
+	acl *aggregationv1alpha1.CoderTemplateACL,
+) (*resolvedTemplateACL, error) {
+	if sdk == nil {
+		return nil, fmt.Errorf("assertion failed: codersdk client must not be nil")
+	}
+	if acl == nil {
+		return nil, fmt.Errorf("assertion failed: template ACL must not be nil")
+	}
+
+	resolved := &resolvedTemplateACL{
+		userRoles:  make(map[string]codersdk.TemplateRole, len(acl.Users)),
+		groupRoles: make(map[string]codersdk.TemplateRole, len(acl.Groups)),
+	}
+	for _, entry := range acl.Users {
+		user, err := sdk.User(ctx, entry.Name)
+		if err != nil {
+			if coderStatusCode(err) == http.StatusNotFound {
+				return nil, apierrors.NewBadRequest(fmt.Sprintf("spec.acl.users: Coder user %q not found", entry.Name))
+			}
+			return nil, coder.MapCoderError(err, aggregationv1alpha1.Resource("codertemplates"), name)
+		}
+		resolved.userRoles[user.ID.String()] = codersdk.TemplateRole(entry.Role)
+	}
+	for _, entry := range acl.Groups {
+		group, err := sdk.GroupByOrgAndName(ctx, orgID, entry.Name)
+		if err != nil {
+			if coderStatusCode(err) == http.StatusNotFound {
+				return nil, apierrors.NewBadRequest(fmt.Sprintf("spec.acl.groups: Coder group %q not found", entry.Name))
+			}
+			return nil, coder.MapCoderError(err, aggregationv1alpha1.Resource("codertemplates"), name)
+		}
+		resolved.groupRoles[group.ID.String()] = codersdk.TemplateRole(entry.Role)
+	}
+
+	return resolved, nil
+}
+
+// applyTemplateACL makes the template's ACL match desired, removing users and
+// groups that are no longer listed.
+func applyTemplateACL(
+	ctx context.Context,
+	sdk *codersdk.Client,
+	templateID uuid.UUID,
+	name string,
+	desired *resolvedTemplateACL,
+) error {
+	if sdk == nil {
+		return fmt.Errorf("assertion failed: codersdk client must not be nil")
+	}
+	if desired == nil {
+		return fmt.Errorf("assertion failed: resolved template ACL must not be nil")
+	}
+
+	current, err := sdk.TemplateACL(ctx, templateID)
+	if err != nil {
+		return coder.MapCoderError(err, aggregationv1alpha1.Resource("codertemplates"), name)
+	}
+
+	request := codersdk.UpdateTemplateACL{
+		UserPerms:  map[string]codersdk.TemplateRole{},
+		GroupPerms: map[string]codersdk.TemplateRole{},
+	}
+	for _, user := range current.Users {
+		if _, ok := desired.userRoles[user.ID.String()]; !ok {
+			request.UserPerms[user.ID.String()] = codersdk.TemplateRoleDeleted
+		}
+	}
+	for _, group := range current.Groups {
+		if _, ok := desired.groupRoles[group.ID.String()]; !ok {
+			request.GroupPerms[group.ID.String()] = codersdk.TemplateRoleDeleted
+		}
+	}
+	for userID, role := range desired.userRoles {
+		request.UserPerms[userID] = role
+	}
+	for groupID, role := range desired.groupRoles {
+		request.GroupPerms[groupID] = role
+	}
+
+	if err := sdk.UpdateTemplateACL(ctx, templateID, request); err != nil {
+		return coder.MapCoderError(err, aggregationv1alpha1.Resource("codertemplates"), name)
+	}
+
+	return nil
+}
+
+func coderStatusCode(err error) int {
+	var coderErr *codersdk.Error
+	if !errors.As(err, &coderErr) {
+		return 0
+	}
+
+	return coderErr.StatusCode()
+}
@@ -0,0 +1,167 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/coder/coder/v2/codersdk"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apiserver/pkg/warning"
+
+	aggregationv1alpha1 "github.com/coder/coder-k8s/api/aggregation/v1alpha1"
+	"github.com/coder/coder-k8s/internal/aggregated/coder"
+)
+
+const (
+	templateWorkspaceDeletePollInterval = time.Second
+	// maxListedTemplateWorkspaces bounds how many blocking workspaces an error
+	// message names.
+	maxListedTemplateWorkspaces = 10
+)
+
+// templateDeleteCascades reports whether a template DELETE should delete the
+// template's workspaces first. Only foreground propagation
+// (kubectl delete --cascade=foreground) cascades; kubectl sends background
+// propagation by default, which keeps the safe reject.
+func templateDeleteCascades(opts *metav1.DeleteOptions) bool {
+	return opts != nil &&
+		opts.PropagationPolicy != nil &&
+		*opts.PropagationPolicy == metav1.DeletePropagationForeground
+}
+
+// removeTemplateWorkspaces makes sure no workspace still uses template. Without
+// cascade it rejects the delete and names the blocking workspaces. With cascade
+// it starts a delete build for each workspace and waits until Coder no longer
+// reports any of them. A dry run only warns which workspaces a cascade would
+// delete.
+func removeTemplateWorkspaces(
+	ctx context.Context,
+	sdk *codersdk.Client,
+	template codersdk.Template,
+	name string,
+	cascade bool,
+	dryRun bool,
+) error {
+	if sdk == nil {
+		return fmt.Errorf("assertion failed: codersdk client must not be nil")
+	}
+
+	workspaces, err := templateWorkspaces(ctx, sdk, template)
+	if err != nil {
+		return coder.MapCoderError(err, aggregationv1alpha1.Resource("codertemplates"), name)
+	}
+	if len(workspaces) == 0 {
+		return nil
+	}
+	if !cascade {
+		return apierrors.NewConflict(
+			aggregationv1alpha1.Resource("codertemplates"),
+			name,
+			fmt.Errorf(
+				"template is used by %d workspace(s): %s; delete them first, or delete with propagationPolicy=Foreground to delete them with the template",
+				len(workspaces),
+				templateWorkspaceNames(workspaces),
+			),
+		)
+	}
+	if dryRun {
+		warning.AddWarning(ctx, "", fmt.Sprintf(
+			"deleting template %q would delete %d workspace(s): %s",
+			name,
+			len(workspaces),
+			templateWorkspaceNames(workspaces),
+		))
+		return nil
+	}
+
+	for _, workspace := range workspaces {
+		if workspace.LatestBuild.Transition == codersdk.WorkspaceTransitionDelete {
+			continue
+		}
+		if _, err := sdk.CreateWorkspaceBuild(ctx, workspace.ID, codersdk.CreateWorkspaceBuildRequest{
+			Transition: codersdk.WorkspaceTransitionDelete,
+		}); err != nil {
+			return coder.MapCoderError(
+				err,
+				aggregationv1alpha1.Resource("coderworkspaces"),
+				coder.BuildWorkspaceName(workspace.OrganizationName, workspace.OwnerName, workspace.Name),
+			)
+		}
+	}
+
+	// Like a workspace DELETE, stop waiting before kube-apiserver's request
+	// timeout so the caller gets the retry hint below.
+	waitCtx, cancel := context.WithTimeout(ctx, maxWorkspaceDeleteWait)
+	defer cancel()
+	for {
+		remaining, err := templateWorkspaces(waitCtx, sdk, template)
+		if err != nil && waitCtx.Err() == nil {
+			return coder.MapCoderError(err, aggregationv1alpha1.Resource("codertemplates"), name)
+		}
+		if err == nil && len(remaining) == 0 {
+			return nil
+		}
+		if err == nil {
+			workspaces = remaining
+		}
+
+		select {
+		case <-waitCtx.Done():
+			return apierrors.NewTimeoutError(
+				fmt.Sprintf(
+					"timed out waiting for workspaces of template %q to be deleted: %s; retry the delete once they are gone",
+					name,
+					templateWorkspaceNames(workspaces),
+				),
+				0,
+			)
+		case <-time.After(templateWorkspaceDeletePollInterval):
+		}
+	}
+}
+
+// templateWorkspaces lists the workspaces that still use template. Workspaces
+// whose delete build already finished are skipped.
+func templateWorkspaces(ctx context.Context, sdk *codersdk.Client, template codersdk.Template) ([]codersdk.Workspace, error) {
+	response, err := sdk.Workspaces(ctx, codersdk.WorkspaceFilter{Template: template.Name})
+	if err != nil {
+		return nil, err
+	}
+
+	workspaces := make([]codersdk.Workspace, 0, len(response.Workspaces))
+	for _, workspace := range response.Workspaces {
+		// The template filter matches by name across organizations.
+		if workspace.TemplateID != template.ID {
+			continue
+		}
+		if workspace.LatestBuild.Transition == codersdk.WorkspaceTransitionDelete &&
+			workspace.LatestBuild.Status == codersdk.WorkspaceStatusDeleted {
+			continue
+		}
+		workspaces = append(workspaces, workspace)
+	}
+
+	return workspaces, nil
+}
+
+func templateWorkspaceNames(workspaces []codersdk.Workspace) string {
+	names := make([]string, 0, len(workspaces))
+	for _, workspace := range workspaces {
+		names = append(names, coder.BuildWorkspaceName(workspace.OrganizationName, workspace.OwnerName, workspace.Name))
+	}
+	sort.Strings(names)
+
+	if len(names) > maxListedTemplateWorkspaces {
+		return fmt.Sprintf(
+			"%s and %d more",
+			strings.Join(names[:maxListedTemplateWorkspaces], ", "),
+			len(names)-maxListedTemplateWorkspaces,
+		)
+	}
+
+	return strings.Join(names, ", ")
+}
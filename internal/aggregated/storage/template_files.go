@@ -7,13 +7,16 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"os"
 	"path"
 	"sort"
+	"strconv"
 	"strings"
 	"unicode/utf8"
 
 	"github.com/coder/coder/v2/codersdk"
 	"github.com/google/uuid"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 )
 
 const (
@@ -21,8 +24,86 @@ const (
 	maxTemplateSourceTotalUncompressedBytes = 40 << 20 // 40 MiB total extracted
 	maxTemplateSourceFiles                  = 2000
 	maxTemplateSourceFileBytes              = 2 << 20 // 2 MiB per file
+
+	templateFilesMaxCountEnv = "CODER_K8S_TEMPLATE_FILES_MAX_COUNT"
+	templateFilesMaxBytesEnv = "CODER_K8S_TEMPLATE_FILES_MAX_BYTES"
 )
 
+// templateFilesLimits bounds the spec.files a single request may carry. The
+// configured values can only tighten the hard source archive limits above.
+type templateFilesLimits struct {
+	maxCount int64
+	maxBytes int64
+}
+
+func loadTemplateFilesLimitsFromEnv() (templateFilesLimits, error) {
+	maxCount, err := parsePositiveIntEnvOrDefault(templateFilesMaxCountEnv, maxTemplateSourceFiles, maxTemplateSourceFiles)
+	if err != nil {
+		return templateFilesLimits{}, err
+	}
+	maxBytes, err := parsePositiveIntEnvOrDefault(templateFilesMaxBytesEnv, maxTemplateSourceTotalUncompressedBytes, maxTemplateSourceTotalUncompressedBytes)
+	if err != nil {
+		return templateFilesLimits{}, err
+	}
+
+	return templateFilesLimits{maxCount: maxCount, maxBytes: maxBytes}, nil
+}
+
+func parsePositiveIntEnvOrDefault(envName string, defaultValue, maxValue int64) (int64, error) {
+	if envName == "" {
+		return 0, fmt.Errorf("assertion failed: environment variable name must not be empty")
+	}
+
+	rawValue := strings.TrimSpace(os.Getenv(envName))
+	if rawValue == "" {
+		return defaultValue, nil
+	}
+
+	parsedValue, err := strconv.ParseInt(rawValue, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("parse %s=%q: %w", envName, rawValue, err)
+	}
+	if parsedValue <= 0 || parsedValue > maxValue {
+		return 0, fmt.Errorf("assertion failed: %s must be in (0, %d], got %d", envName, maxValue, parsedValue)
+	}
+
+	return parsedValue, nil
+}
+
+// validateTemplateFiles rejects spec.files that exceed the configured limits or
+// use unsafe paths, before any archive is built in memory.
+func validateTemplateFiles(files map[string]string) error {
+	if files == nil {
+		return fmt.Errorf("assertion failed: files map must not be nil")
+	}
+
+	limits, err := loadTemplateFilesLimitsFromEnv()
+	if err != nil {
+		return err
+	}
+
+	if int64(len(files)) > limits.maxCount {
+		return newTemplateFilesBadRequest(fmt.Errorf("file count exceeds limit: %d > %d", len(files), limits.maxCount))
+	}
+
+	totalBytes := int64(0)
+	for requestedPath, content := range files {
+		if _, err := validateTemplateSourcePath(requestedPath); err != nil {
+			return newTemplateFilesBadRequest(fmt.Errorf("validate template source path %q: %w", requestedPath, err))
+		}
+		totalBytes += int64(len(content))
+	}
+	if totalBytes > limits.maxBytes {
+		return newTemplateFilesBadRequest(fmt.Errorf("total file size exceeds limit: %d > %d bytes", totalBytes, limits.maxBytes))
+	}
+
+	return nil
+}
+
+func newTemplateFilesBadRequest(err error) error {
+	return apierrors.NewBadRequest(fmt.Sprintf("invalid template spec.files: %v", err))
+}
+
 // fetchRawTemplateSourceZip downloads the raw source archive for a template version.
 func fetchRawTemplateSourceZip(ctx context.Context, sdk *codersdk.Client, versionID uuid.UUID) ([]byte, error) {
 	if ctx == nil {
@@ -166,7 +247,9 @@ func fetchTemplateSourceFiles(ctx context.Context, sdk *codersdk.Client, version
 	return files, nil
 }
 
-// normalizeFileKeys validates and normalizes source file paths.
+// normalizeFileKeys validates and normalizes source file paths. Paths that
+// normalize to the same file, such as "./main.tf" and "main.tf", are merged
+// when their contents match and rejected otherwise.
 func normalizeFileKeys(files map[string]string) (map[string]string, error) {
 	if files == nil {
 		return nil, fmt.Errorf("assertion failed: files map must not be nil")
@@ -178,7 +261,10 @@ func normalizeFileKeys(files map[string]string) (map[string]string, error) {
 		if err != nil {
 			return nil, fmt.Errorf("validate template source path %q: %w", requestedPath, err)
 		}
-		if _, exists := normalizedFiles[normalizedPath]; exists {
+		if existing, exists := normalizedFiles[normalizedPath]; exists {
+			if existing == content {
+				continue
+			}
 			return nil, fmt.Errorf("duplicate normalized template source path %q", normalizedPath)
 		}
 		normalizedFiles[normalizedPath] = content
@@ -442,7 +528,20 @@ func buildSourceZip(files map[string]string) ([]byte, error) {
 	var buffer bytes.Buffer
 	zipWriter := zip.NewWriter(&buffer)
 
+	// Write explicit entries for nested directories so extractors that do not
+	// create parents on demand still reproduce the layout.
+	createdDirs := make(map[string]struct{})
 	for _, sourcePath := range paths {
+		for _, dir := range templateSourceParentDirs(sourcePath) {
+			if _, exists := createdDirs[dir]; exists {
+				continue
+			}
+			createdDirs[dir] = struct{}{}
+			if _, err := zipWriter.Create(dir + "/"); err != nil {
+				return nil, fmt.Errorf("create zip directory entry %q: %w", dir, err)
+			}
+		}
+
 		fileWriter, err := zipWriter.Create(sourcePath)
 		if err != nil {
 			return nil, fmt.Errorf("create zip entry %q: %w", sourcePath, err)
@@ -464,6 +563,19 @@ func buildSourceZip(files map[string]string) ([]byte, error) {
 	return result, nil
 }
 
+// templateSourceParentDirs returns the parent directories of a normalized
+// source path, outermost first: "modules/foo/main.tf" yields "modules" and
+// "modules/foo".
+func templateSourceParentDirs(sourcePath string) []string {
+	var dirs []string
+	for i, r := range sourcePath {
+		if r == '/' {
+			dirs = append(dirs, sourcePath[:i])
+		}
+	}
+	return dirs
+}
+
 func validateTemplateSourcePath(templatePath string) (string, error) {
 	if templatePath == "" {
 		return "", fmt.Errorf("path must not be empty")
@@ -472,6 +584,13 @@ func validateTemplateSourcePath(templatePath string) (string, error) {
 	if strings.ContainsRune(templatePath, '\\') {
 		return "", fmt.Errorf("path must not contain backslashes")
 	}
+	// Reject parent directory components before cleaning so "a/../b" is not
+	// silently rewritten to "b".
+	for _, component := range strings.Split(templatePath, "/") {
+		if component == ".." {
+			return "", fmt.Errorf("path must not contain parent directory components")
+		}
+	}
 
 	cleanedPath := path.Clean(templatePath)
 	if cleanedPath == "." || cleanedPath == "/" {
@@ -480,14 +599,6 @@ func validateTemplateSourcePath(templatePath string) (string, error) {
 	if strings.HasPrefix(cleanedPath, "/") {
 		return "", fmt.Errorf("path must be relative")
 	}
-	if cleanedPath == ".." || strings.HasPrefix(cleanedPath, "../") {
-		return "", fmt.Errorf("path must not escape root")
-	}
-	for _, component := range strings.Split(cleanedPath, "/") {
-		if component == ".." {
-			return "", fmt.Errorf("path must not contain parent directory components")
-		}
-	}
 
 	return cleanedPath, nil
 }
@@ -0,0 +1,137 @@
+package storage
+
+import (
+	"container/list"
+	"maps"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// templateFilesCacheMaxEntries and templateFilesCacheMaxBytes bound the
+	// template source cache. A single template may hold up to
+	// maxTemplateSourceTotalUncompressedBytes, so the byte limit matters more.
+	templateFilesCacheMaxEntries = 256
+	templateFilesCacheMaxBytes   = 64 << 20 // 64 MiB
+)
+
+// templateFilesCacheKey identifies a template's source at one backend state.
+// Coder bumps a template's updated_at whenever it changes, and the
+// CoderTemplate resourceVersion is derived from it, so a new updatedAt or
+// active version never serves stale files.
+type templateFilesCacheKey struct {
+	namespace       string
+	templateID      uuid.UUID
+	updatedAt       int64 // UnixNano; time.Time equality also compares locations.
+	activeVersionID uuid.UUID
+}
+
+type templateFilesCacheEntry struct {
+	key   templateFilesCacheKey
+	files map[string]string
+	bytes int
+}
+
+// templateFilesCache is a least-recently-used cache of the spec.files that Get
+// extracts from each template's active version, so polling clients do not
+// re-download the source archive from Coder while the template is unchanged.
+type templateFilesCache struct {
+	mu         sync.Mutex
+	maxEntries int
+	maxBytes   int
+	totalBytes int
+	// order holds *templateFilesCacheEntry values, most recently used first.
+	order *list.List
+	// byTemplate indexes order by namespace and template ID; a template has at
+	// most one entry, for its latest observed updatedAt.
+	byTemplate map[templateFilesCacheKey]*list.Element
+}
+
+func newTemplateFilesCache(maxEntries, maxBytes int) *templateFilesCache {
+	return &templateFilesCache{
+		maxEntries: maxEntries,
+		maxBytes:   maxBytes,
+		order:      list.New(),
+		byTemplate: make(map[templateFilesCacheKey]*list.Element),
+	}
+}
+
+// get returns a copy of the cached files for key.
+func (c *templateFilesCache) get(key templateFilesCacheKey) (map[string]string, bool) {
+	if c == nil {
+		return nil, false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.byTemplate[templateOnlyKey(key)]
+	if !ok {
+		return nil, false
+	}
+	entry := element.Value.(*templateFilesCacheEntry)
+	if entry.key != key {
+		// The template changed in Coder; drop the stale source.
+		c.removeLocked(element)
+		return nil, false
+	}
+	c.order.MoveToFront(element)
+
+	return maps.Clone(entry.files), true
+}
+
+// put stores a copy of files for key, replacing any older entry for the same
+// template and evicting least recently used entries beyond the limits.
+func (c *templateFilesCache) put(key templateFilesCacheKey, files map[string]string) {
+	if c == nil || files == nil {
+		return
+	}
+
+	size := 0
+	for name, contents := range files {
+		size += len(name) + len(contents)
+	}
+	if size > c.maxBytes {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, ok := c.byTemplate[templateOnlyKey(key)]; ok {
+		c.removeLocked(element)
+	}
+	entry := &templateFilesCacheEntry{key: key, files: maps.Clone(files), bytes: size}
+	c.byTemplate[templateOnlyKey(key)] = c.order.PushFront(entry)
+	c.totalBytes += size
+
+	for c.order.Len() > c.maxEntries || c.totalBytes > c.maxBytes {
+		c.removeLocked(c.order.Back())
+	}
+}
+
+// forget drops the cached files for a template, for example after it is
+// deleted.
+func (c *templateFilesCache) forget(namespace string, templateID uuid.UUID) {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, ok := c.byTemplate[templateFilesCacheKey{namespace: namespace, templateID: templateID}]; ok {
+		c.removeLocked(element)
+	}
+}
+
+func (c *templateFilesCache) removeLocked(element *list.Element) {
+	entry := c.order.Remove(element).(*templateFilesCacheEntry)
+	delete(c.byTemplate, templateOnlyKey(entry.key))
+	c.totalBytes -= entry.bytes
+}
+
+func templateOnlyKey(key templateFilesCacheKey) templateFilesCacheKey {
+	return templateFilesCacheKey{namespace: key.namespace, templateID: key.templateID}
+}
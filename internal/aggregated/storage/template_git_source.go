@@ -0,0 +1,405 @@
+package storage
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"os/exec"
+	"path"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+
+	aggregationv1alpha1 "github.com/coder/coder-k8s/api/aggregation/v1alpha1"
+)
+
+const (
+	maxTemplateGitRefLength = 256
+
+	// templateSourceTreeHashPrefix marks the template version message line
+	// that records which Git tree a version was imported from. Coder keeps no
+	// other metadata for a version, so the message is what lets later updates
+	// skip re-uploading an unchanged tree.
+	templateSourceTreeHashPrefix = "coder-k8s-source-tree: "
+
+	// templateGitRemote names the remote a template source is fetched from.
+	templateGitRemote = "origin"
+
+	// maxTemplateGitArchiveBytes bounds the tar stream read from git archive:
+	// the total file limit plus room for a header block per file.
+	maxTemplateGitArchiveBytes = maxTemplateSourceTotalUncompressedBytes + (maxTemplateSourceFiles+1)*2*512
+)
+
+// TemplateSource is a template source tree resolved from spec.gitSource.
+type TemplateSource struct {
+	// TreeHash identifies the fetched tree, so an unchanged tree can be
+	// detected without comparing file contents.
+	TreeHash string
+	// Files maps slash-delimited relative paths to UTF-8 file contents.
+	Files map[string]string
+}
+
+// TemplateSourceFetcher resolves a CoderTemplate spec.gitSource into template
+// files. Implementations must not modify source.
+type TemplateSourceFetcher interface {
+	Fetch(ctx context.Context, source aggregationv1alpha1.CoderTemplateGitSource) (TemplateSource, error)
+}
+
+// GitTemplateSourceFetcher fetches template sources with the git binary.
+type GitTemplateSourceFetcher struct {
+	gitPath string
+}
+
+var _ TemplateSourceFetcher = (*GitTemplateSourceFetcher)(nil)
+
+// NewGitTemplateSourceFetcher returns a fetcher that runs the git binary found
+// on PATH.
+func NewGitTemplateSourceFetcher() (*GitTemplateSourceFetcher, error) {
+	gitPath, err := exec.LookPath("git")
+	if err != nil {
+		return nil, fmt.Errorf("template git sources require a git binary on PATH: %w", err)
+	}
+
+	return &GitTemplateSourceFetcher{gitPath: gitPath}, nil
+}
+
+// Fetch shallow-fetches source.Ref into a temporary bare repository and reads
+// the source.Subdir tree from it.
+func (f *GitTemplateSourceFetcher) Fetch(
+	ctx context.Context,
+	source aggregationv1alpha1.CoderTemplateGitSource,
+) (TemplateSource, error) {
+	if f == nil || f.gitPath == "" {
+		return TemplateSource{}, fmt.Errorf("assertion failed: git template source fetcher must be constructed with NewGitTemplateSourceFetcher")
+	}
+	if ctx == nil {
+		return TemplateSource{}, fmt.Errorf("assertion failed: context must not be nil")
+	}
+
+	repoDir, err := os.MkdirTemp("", "coder-k8s-template-source-")
+	if err != nil {
+		return TemplateSource{}, fmt.Errorf("create git work directory: %w", err)
+	}
+	defer func() {
+		_ = os.RemoveAll(repoDir)
+	}()
+
+	ref := source.Ref
+	if ref == "" {
+		ref = "HEAD"
+	}
+	treeish := "FETCH_HEAD^{tree}"
+	if subdir := strings.Trim(source.Subdir, "/"); subdir != "" {
+		treeish = "FETCH_HEAD:" + subdir
+	}
+
+	if _, err := f.run(ctx, repoDir, "init", "--quiet", "--bare"); err != nil {
+		return TemplateSource{}, err
+	}
+	// A blob filter needs a named remote. Servers that support it skip files
+	// too large to import; the remote is removed afterwards so later commands
+	// cannot lazily fetch those blobs.
+	if _, err := f.run(ctx, repoDir, "remote", "add", "--", templateGitRemote, source.URL); err != nil {
+		return TemplateSource{}, err
+	}
+	if _, err := f.run(
+		ctx,
+		repoDir,
+		"fetch", "--quiet", "--depth=1", "--no-tags",
+		fmt.Sprintf("--filter=blob:limit=%d", maxTemplateSourceFileBytes+1),
+		"--", templateGitRemote, ref,
+	); err != nil {
+		return TemplateSource{}, err
+	}
+	if _, err := f.run(ctx, repoDir, "remote", "remove", templateGitRemote); err != nil {
+		return TemplateSource{}, err
+	}
+	treeHash, err := f.run(ctx, repoDir, "rev-parse", "--verify", "--quiet", treeish)
+	if err != nil {
+		return TemplateSource{}, fmt.Errorf("resolve %q in ref %q: %w", source.Subdir, source.Ref, err)
+	}
+	treeHash = strings.TrimSpace(treeHash)
+	objectType, err := f.run(ctx, repoDir, "cat-file", "-t", treeHash)
+	if err != nil {
+		return TemplateSource{}, err
+	}
+	if strings.TrimSpace(objectType) != "tree" {
+		return TemplateSource{}, fmt.Errorf("%q in ref %q is not a directory", source.Subdir, source.Ref)
+	}
+
+	files, err := f.archive(ctx, repoDir, treeHash)
+	if err != nil {
+		return TemplateSource{}, err
+	}
+
+	return TemplateSource{TreeHash: treeHash, Files: files}, nil
+}
+
+// archive streams `git archive` of treeHash into the template files map and
+// stops git as soon as the archive exceeds maxTemplateGitArchiveBytes or a
+// template source limit.
+func (f *GitTemplateSourceFetcher) archive(ctx context.Context, dir, treeHash string) (map[string]string, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	cmd := f.command(ctx, dir, "archive", "--format=tar", treeHash)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("git archive: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("git archive: %w", err)
+	}
+
+	limited := &io.LimitedReader{R: stdout, N: maxTemplateGitArchiveBytes + 1}
+	files, readErr := readTemplateSourceTar(limited)
+	if readErr == nil {
+		// Drain the end-of-archive padding so git exits cleanly.
+		_, readErr = io.Copy(io.Discard, limited)
+	}
+	if limited.N == 0 {
+		readErr = fmt.Errorf("git archive exceeds max size: > %d bytes", maxTemplateGitArchiveBytes)
+	}
+	if readErr != nil {
+		// Stop git instead of reading the rest of an oversized archive.
+		cancel()
+	}
+	waitErr := cmd.Wait()
+	if message := strings.TrimSpace(stderr.String()); strings.Contains(message, "invalid object") {
+		// Blobs over the fetch filter limit are missing from the repository.
+		return nil, fmt.Errorf("template source contains a file that exceeds max file size %d: %s", maxTemplateSourceFileBytes, message)
+	}
+	if readErr != nil {
+		return nil, readErr
+	}
+	if waitErr != nil {
+		return nil, gitCommandError("archive", waitErr, &stderr)
+	}
+
+	return files, nil
+}
+
+func (f *GitTemplateSourceFetcher) run(ctx context.Context, dir string, args ...string) (string, error) {
+	cmd := f.command(ctx, dir, args...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", gitCommandError(args[0], err, &stderr)
+	}
+
+	return stdout.String(), nil
+}
+
+func (f *GitTemplateSourceFetcher) command(ctx context.Context, dir string, args ...string) *exec.Cmd {
+	// Only the transports accepted by validateTemplateGitSource are allowed,
+	// so a request can never read repositories from the server filesystem.
+	gitArgs := append([]string{
+		"-c", "protocol.allow=never",
+		"-c", "protocol.https.allow=always",
+		"-c", "protocol.http.allow=always",
+		"-c", "protocol.ssh.allow=always",
+		"-c", "protocol.git.allow=always",
+		"--git-dir", dir,
+	}, args...)
+
+	cmd := exec.CommandContext(ctx, f.gitPath, gitArgs...) //nolint:gosec // arguments are validated by validateTemplateGitSource.
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0", "GIT_CONFIG_NOSYSTEM=1")
+	return cmd
+}
+
+func gitCommandError(subcommand string, err error, stderr *bytes.Buffer) error {
+	if message := strings.TrimSpace(stderr.String()); message != "" {
+		return fmt.Errorf("git %s: %w: %s", subcommand, err, message)
+	}
+	return fmt.Errorf("git %s: %w", subcommand, err)
+}
+
+// readTemplateSourceTar reads a git archive into a template files map with the
+// same limits applied to uploaded template source.
+func readTemplateSourceTar(r io.Reader) (map[string]string, error) {
+	files := make(map[string]string)
+	var totalBytes int64
+	reader := tar.NewReader(r)
+	for {
+		header, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			return files, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read git archive: %w", err)
+		}
+
+		switch header.Typeflag {
+		case tar.TypeDir, tar.TypeXGlobalHeader:
+			continue
+		case tar.TypeReg:
+		default:
+			return nil, fmt.Errorf("template source %q is not a regular file", header.Name)
+		}
+
+		if len(files) >= maxTemplateSourceFiles {
+			return nil, fmt.Errorf("template source contains too many files: > %d", maxTemplateSourceFiles)
+		}
+		if header.Size > maxTemplateSourceFileBytes {
+			return nil, fmt.Errorf("template source file %q exceeds max file size: %d > %d", header.Name, header.Size, maxTemplateSourceFileBytes)
+		}
+		totalBytes += header.Size
+		if totalBytes > maxTemplateSourceTotalUncompressedBytes {
+			return nil, fmt.Errorf("template source exceeds max total size: > %d", maxTemplateSourceTotalUncompressedBytes)
+		}
+
+		contents, err := io.ReadAll(io.LimitReader(reader, maxTemplateSourceFileBytes+1))
+		if err != nil {
+			return nil, fmt.Errorf("read template source file %q: %w", header.Name, err)
+		}
+		if !utf8.Valid(contents) {
+			return nil, fmt.Errorf("template source file %q is not valid UTF-8", header.Name)
+		}
+		files[header.Name] = string(contents)
+	}
+}
+
+// validateTemplateGitSource rejects repository URLs, refs, and subdirectories
+// that could be misread as git options or reach outside the repository.
+func validateTemplateGitSource(source *aggregationv1alpha1.CoderTemplateGitSource) error {
+	if source == nil {
+		return fmt.Errorf("assertion failed: git source must not be nil")
+	}
+
+	if source.URL == "" {
+		return fmt.Errorf("spec.gitSource.url must not be empty")
+	}
+	parsedURL, err := url.Parse(source.URL)
+	if err != nil {
+		return fmt.Errorf("spec.gitSource.url %q is invalid: %v", source.URL, err)
+	}
+	switch parsedURL.Scheme {
+	case "https", "http", "ssh", "git":
+	default:
+		return fmt.Errorf("spec.gitSource.url %q must use the https, http, ssh, or git scheme", source.URL)
+	}
+	if parsedURL.Host == "" {
+		return fmt.Errorf("spec.gitSource.url %q must include a host", source.URL)
+	}
+
+	if err := validateTemplateGitRef(source.Ref); err != nil {
+		return fmt.Errorf("spec.gitSource.ref %q is invalid: %v", source.Ref, err)
+	}
+
+	if subdir := strings.Trim(source.Subdir, "/"); subdir != "" {
+		if strings.HasPrefix(source.Subdir, "/") || path.Clean(subdir) != subdir || strings.HasPrefix(subdir, "..") {
+			return fmt.Errorf("spec.gitSource.subdir %q must be a clean relative path inside the repository", source.Subdir)
+		}
+		if strings.ContainsFunc(subdir, func(r rune) bool { return unicode.IsControl(r) || r == ':' }) {
+			return fmt.Errorf("spec.gitSource.subdir %q must not contain control characters or ':'", source.Subdir)
+		}
+	}
+
+	return nil
+}
+
+// validateTemplateGitRef applies the subset of git check-ref-format rules that
+// matter for a fetch refspec, and also accepts commit SHAs.
+func validateTemplateGitRef(ref string) error {
+	if ref == "" {
+		return nil
+	}
+	if len(ref) > maxTemplateGitRefLength {
+		return fmt.Errorf("must be at most %d characters", maxTemplateGitRefLength)
+	}
+	if strings.HasPrefix(ref, "-") {
+		return fmt.Errorf("must not start with '-'")
+	}
+	if strings.HasPrefix(ref, "/") || strings.HasSuffix(ref, "/") || strings.HasSuffix(ref, ".") || strings.HasSuffix(ref, ".lock") {
+		return fmt.Errorf("must not start or end with '/' or end with '.' or '.lock'")
+	}
+	for _, invalid := range []string{"..", "//", "@{", "/."} {
+		if strings.Contains(ref, invalid) {
+			return fmt.Errorf("must not contain %q", invalid)
+		}
+	}
+	if strings.ContainsFunc(ref, func(r rune) bool {
+		return unicode.IsSpace(r) || unicode.IsControl(r) || strings.ContainsRune("~^:?*[\\", r)
+	}) {
+		return fmt.Errorf("must not contain whitespace, control characters, or any of ~^:?*[\\")
+	}
+
+	return nil
+}
+
+// templateGitSourceVersionMessage builds the template version message for a
+// version imported from a Git tree.
+func templateGitSourceVersionMessage(source *aggregationv1alpha1.CoderTemplateGitSource, treeHash string) string {
+	origin := source.URL
+	if source.Ref != "" {
+		origin += "@" + source.Ref
+	}
+	if subdir := strings.Trim(source.Subdir, "/"); subdir != "" {
+		origin += " (" + subdir + ")"
+	}
+
+	return fmt.Sprintf("Imported from %s\n\n%s%s", origin, templateSourceTreeHashPrefix, treeHash)
+}
+
+// templateSourceTreeHashFromMessage returns the Git tree hash recorded by
+// templateGitSourceVersionMessage, or "" when the version was not imported
+// from Git.
+func templateSourceTreeHashFromMessage(message string) string {
+	for _, line := range strings.Split(message, "\n") {
+		if treeHash, ok := strings.CutPrefix(strings.TrimSpace(line), templateSourceTreeHashPrefix); ok {
+			return strings.TrimSpace(treeHash)
+		}
+	}
+
+	return ""
+}
+
+// resolveTemplateGitSource validates spec.gitSource and fetches its files.
+// Every failure is returned as a Kubernetes API status error.
+func (s *TemplateStorage) resolveTemplateGitSource(
+	ctx context.Context,
+	source *aggregationv1alpha1.CoderTemplateGitSource,
+) (TemplateSource, error) {
+	if source == nil {
+		return TemplateSource{}, fmt.Errorf("assertion failed: git source must not be nil")
+	}
+	if s.gitSourceFetcher == nil {
+		return TemplateSource{}, apierrors.NewBadRequest(
+			"spec.gitSource is disabled; start the aggregated API server with --enable-template-git-sources",
+		)
+	}
+	if err := validateTemplateGitSource(source); err != nil {
+		return TemplateSource{}, apierrors.NewBadRequest(fmt.Sprintf("invalid template spec: %v", err))
+	}
+
+	resolved, err := s.gitSourceFetcher.Fetch(ctx, *source)
+	if err != nil {
+		return TemplateSource{}, apierrors.NewBadRequest(fmt.Sprintf("fetch spec.gitSource %q: %v", source.URL, err))
+	}
+	if resolved.TreeHash == "" {
+		return TemplateSource{}, fmt.Errorf("assertion failed: fetched template source tree hash must not be empty")
+	}
+	if resolved.Files == nil {
+		resolved.Files = map[string]string{}
+	}
+	if err := validateTemplateFiles(resolved.Files); err != nil {
+		return TemplateSource{}, err
+	}
+	files, err := normalizeFileKeys(resolved.Files)
+	if err != nil {
+		return TemplateSource{}, newTemplateFilesBadRequest(err)
+	}
+	resolved.Files = files
+
+	return resolved, nil
+}
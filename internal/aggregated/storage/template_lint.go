@@ -0,0 +1,205 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apiserver/pkg/registry/rest"
+
+	aggregationv1alpha1 "github.com/coder/coder-k8s/api/aggregation/v1alpha1"
+	"github.com/coder/coder-k8s/internal/aggregated/coder"
+	"github.com/coder/coder-k8s/internal/aggregated/convert"
+	"github.com/coder/coder/v2/codersdk"
+)
+
+var (
+	_ rest.Storage                  = (*TemplateLintStorage)(nil)
+	_ rest.NamedCreater             = (*TemplateLintStorage)(nil) //nolint:misspell // Kubernetes rest interface name is Creater.
+	_ rest.GroupVersionKindProvider = (*TemplateLintStorage)(nil)
+)
+
+// TemplateLintStorage implements the codertemplates/lint subresource, which
+// builds template source as a new, inactive version of an existing template so
+// it can be checked before it is promoted.
+type TemplateLintStorage struct {
+	templates *TemplateStorage
+}
+
+// NewTemplateLintStorage builds the lint subresource storage. Git sources are
+// fetched with the fetcher configured on templates.
+func NewTemplateLintStorage(templates *TemplateStorage) *TemplateLintStorage {
+	if templates == nil {
+		panic("assertion failed: template storage must not be nil")
+	}
+
+	return &TemplateLintStorage{templates: templates}
+}
+
+// New returns an empty CoderTemplate object, which is the lint request body.
+func (s *TemplateLintStorage) New() runtime.Object {
+	return &aggregationv1alpha1.CoderTemplate{}
+}
+
+// Destroy cleans up storage resources.
+func (s *TemplateLintStorage) Destroy() {}
+
+// GroupVersionKind reports that lint responds with a CoderTemplateVersion.
+func (s *TemplateLintStorage) GroupVersionKind(_ schema.GroupVersion) schema.GroupVersionKind {
+	return aggregationv1alpha1.SchemeGroupVersion.WithKind("CoderTemplateVersion")
+}
+
+// Create uploads the request's spec.files or spec.gitSource as a new version
+// of the named template, waits for Coder's import job, and returns the version.
+// The version is never promoted and is archived once the import finishes, so
+// linting does not grow the template's version list. A failed import is not an
+// error: the returned status.jobStatus is "failed" and status.jobError carries
+// Coder's diagnostics. A dry run checks the request and the template but
+// uploads nothing. Other spec fields in the request body are ignored.
+func (s *TemplateLintStorage) Create(
+	ctx context.Context,
+	name string,
+	obj runtime.Object,
+	createValidation rest.ValidateObjectFunc,
+	options *metav1.CreateOptions,
+) (runtime.Object, error) {
+	if s == nil {
+		return nil, fmt.Errorf("assertion failed: template lint storage must not be nil")
+	}
+	if s.templates == nil {
+		return nil, fmt.Errorf("assertion failed: template storage must not be nil")
+	}
+	if ctx == nil {
+		return nil, fmt.Errorf("assertion failed: context must not be nil")
+	}
+	if name == "" {
+		return nil, fmt.Errorf("assertion failed: template name must not be empty")
+	}
+
+	request, ok := obj.(*aggregationv1alpha1.CoderTemplate)
+	if !ok || request == nil {
+		return nil, apierrors.NewBadRequest(fmt.Sprintf("expected *CoderTemplate, got %T", obj))
+	}
+	if request.Name != "" && request.Name != name {
+		return nil, apierrors.NewBadRequest(
+			fmt.Sprintf("metadata.name %q must match linted template %q", request.Name, name),
+		)
+	}
+	if createValidation != nil {
+		if err := createValidation(ctx, obj); err != nil {
+			return nil, err
+		}
+	}
+
+	namespace, badNamespaceErr := requiredNamespaceFromRequestContext(ctx)
+	if badNamespaceErr != nil {
+		return nil, badNamespaceErr
+	}
+
+	orgName, templateName, err := coder.ParseTemplateName(name)
+	if err != nil {
+		return nil, apierrors.NewBadRequest(fmt.Sprintf("invalid template name %q: %v", name, err))
+	}
+
+	switch {
+	case request.Spec.Files != nil && request.Spec.GitSource != nil:
+		return nil, apierrors.NewBadRequest("invalid lint request: spec.files and spec.gitSource are mutually exclusive")
+	case request.Spec.Files == nil && request.Spec.GitSource == nil:
+		return nil, apierrors.NewBadRequest("invalid lint request: spec.files or spec.gitSource is required")
+	}
+
+	files := request.Spec.Files
+	var versionMessage string
+	if request.Spec.GitSource != nil {
+		source, err := s.templates.resolveTemplateGitSource(ctx, request.Spec.GitSource)
+		if err != nil {
+			return nil, err
+		}
+		files = source.Files
+		versionMessage = templateGitSourceVersionMessage(request.Spec.GitSource, source.TreeHash)
+	} else {
+		if err := validateTemplateFiles(files); err != nil {
+			return nil, err
+		}
+		files, err = normalizeFileKeys(files)
+		if err != nil {
+			return nil, newTemplateFilesBadRequest(err)
+		}
+	}
+
+	zipBytes, err := buildSourceZip(files)
+	if err != nil {
+		return nil, apierrors.NewBadRequest(fmt.Sprintf("invalid template spec.files: %v", err))
+	}
+
+	sdk, err := s.templates.clientForNamespace(ctx, namespace)
+	if err != nil {
+		return nil, wrapClientError(err)
+	}
+
+	org, err := sdk.OrganizationByName(ctx, orgName)
+	if err != nil {
+		return nil, coder.MapCoderError(err, aggregationv1alpha1.Resource("codertemplates"), name)
+	}
+	template, err := sdk.TemplateByName(ctx, org.ID, templateName)
+	if err != nil {
+		return nil, coder.MapCoderError(err, aggregationv1alpha1.Resource("codertemplates"), name)
+	}
+
+	if options != nil && isDryRun(options.DryRun) {
+		return &aggregationv1alpha1.CoderTemplateVersion{
+			TypeMeta: metav1.TypeMeta{
+				Kind:       "CoderTemplateVersion",
+				APIVersion: aggregationv1alpha1.SchemeGroupVersion.String(),
+			},
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace},
+			Spec: aggregationv1alpha1.CoderTemplateVersionSpec{
+				Organization: template.OrganizationName,
+				TemplateName: template.Name,
+				Message:      versionMessage,
+			},
+			Status: aggregationv1alpha1.CoderTemplateVersionStatus{TemplateID: template.ID.String()},
+		}, nil
+	}
+
+	version, err := uploadTemplateVersion(
+		ctx,
+		sdk,
+		orgName,
+		template.ID,
+		zipBytes,
+		codersdk.CreateTemplateVersionRequest{Message: versionMessage},
+	)
+	if err != nil {
+		// Coder deployments without provisioner support for template imports
+		// reject the version outright; report that as an unsupported verb
+		// rather than an internal error.
+		if code := coderStatusCode(err); code == http.StatusNotImplemented || code == http.StatusMethodNotAllowed {
+			return nil, apierrors.NewMethodNotSupported(aggregationv1alpha1.Resource("codertemplates/lint"), "create")
+		}
+		return nil, coder.MapCoderError(err, aggregationv1alpha1.Resource("codertemplates"), name)
+	}
+
+	if waitErr := waitForTemplateVersionBuild(ctx, sdk, version.ID); waitErr != nil {
+		var terminalErr *templateVersionBuildTerminalError
+		if !errors.As(waitErr, &terminalErr) {
+			return nil, mapTemplateVersionBuildWaitError(waitErr, name)
+		}
+	}
+
+	if err := sdk.SetArchiveTemplateVersion(ctx, version.ID, true); err != nil {
+		return nil, coder.MapCoderError(err, aggregationv1alpha1.Resource("codertemplates"), name)
+	}
+
+	version, err = sdk.TemplateVersion(ctx, version.ID)
+	if err != nil {
+		return nil, coder.MapCoderError(err, aggregationv1alpha1.Resource("codertemplates"), name)
+	}
+
+	return convert.TemplateVersionToK8s(namespace, template, version), nil
+}
@@ -0,0 +1,85 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/google/uuid"
+
+	aggregationv1alpha1 "github.com/coder/coder-k8s/api/aggregation/v1alpha1"
+	"github.com/coder/coder-k8s/internal/aggregated/coder"
+	"github.com/coder/coder/v2/codersdk"
+)
+
+// templateListVersionConcurrency bounds how many active template versions one
+// LIST fetches at once.
+const templateListVersionConcurrency = 8
+
+// populateActiveVersionNames sets status.activeVersionName on every item. Each
+// distinct active version is fetched once, at most
+// templateListVersionConcurrency at a time. A version that disappeared since
+// the templates were listed leaves the name empty instead of failing the LIST.
+func populateActiveVersionNames(
+	ctx context.Context,
+	sdk *codersdk.Client,
+	items []aggregationv1alpha1.CoderTemplate,
+) error {
+	if ctx == nil {
+		return fmt.Errorf("assertion failed: context must not be nil")
+	}
+	if sdk == nil {
+		return fmt.Errorf("assertion failed: codersdk client must not be nil")
+	}
+
+	versionIDs := make([]uuid.UUID, 0, len(items))
+	seen := make(map[uuid.UUID]struct{}, len(items))
+	for i := range items {
+		versionID, err := uuid.Parse(items[i].Status.ActiveVersionID)
+		if err != nil || versionID == uuid.Nil {
+			continue
+		}
+		if _, ok := seen[versionID]; ok {
+			continue
+		}
+		seen[versionID] = struct{}{}
+		versionIDs = append(versionIDs, versionID)
+	}
+
+	names := make([]string, len(versionIDs))
+	errs := make([]error, len(versionIDs))
+	slots := make(chan struct{}, templateListVersionConcurrency)
+	var wg sync.WaitGroup
+	for i, versionID := range versionIDs {
+		wg.Add(1)
+		slots <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-slots }()
+
+			version, err := sdk.TemplateVersion(ctx, versionID)
+			if err != nil {
+				if coderStatusCode(err) != http.StatusNotFound {
+					errs[i] = err
+				}
+				return
+			}
+			names[i] = version.Name
+		}()
+	}
+	wg.Wait()
+
+	namesByID := make(map[string]string, len(versionIDs))
+	for i, versionID := range versionIDs {
+		if errs[i] != nil {
+			return coder.MapCoderError(errs[i], aggregationv1alpha1.Resource("codertemplates"), "<list>")
+		}
+		namesByID[versionID.String()] = names[i]
+	}
+	for i := range items {
+		items[i].Status.ActiveVersionName = namesByID[items[i].Status.ActiveVersionID]
+	}
+
+	return nil
+}
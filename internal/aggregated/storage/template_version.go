@@ -0,0 +1,281 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/apiserver/pkg/registry/rest"
+
+	aggregationv1alpha1 "github.com/coder/coder-k8s/api/aggregation/v1alpha1"
+	"github.com/coder/coder-k8s/internal/aggregated/coder"
+	"github.com/coder/coder-k8s/internal/aggregated/convert"
+	"github.com/coder/coder/v2/codersdk"
+)
+
+var (
+	_ rest.Storage              = (*TemplateVersionStorage)(nil)
+	_ rest.Getter               = (*TemplateVersionStorage)(nil)
+	_ rest.Scoper               = (*TemplateVersionStorage)(nil)
+	_ rest.SingularNameProvider = (*TemplateVersionStorage)(nil)
+	_ rest.ShortNamesProvider   = (*TemplateVersionStorage)(nil)
+
+	_ rest.Storage                  = (*TemplateVersionPromoteStorage)(nil)
+	_ rest.NamedCreater             = (*TemplateVersionPromoteStorage)(nil) //nolint:misspell // Kubernetes rest interface name is Creater.
+	_ rest.GroupVersionKindProvider = (*TemplateVersionPromoteStorage)(nil)
+)
+
+// TemplateVersionStorage provides read-only codersdk-backed CoderTemplateVersion objects.
+type TemplateVersionStorage struct {
+	provider       coder.ClientProvider
+	tableConvertor rest.TableConvertor
+}
+
+// NewTemplateVersionStorage builds codersdk-backed storage for CoderTemplateVersion resources.
+func NewTemplateVersionStorage(provider coder.ClientProvider) *TemplateVersionStorage {
+	if provider == nil {
+		panic("assertion failed: template version client provider must not be nil")
+	}
+
+	return &TemplateVersionStorage{
+		provider:       provider,
+		tableConvertor: rest.NewDefaultTableConvertor(aggregationv1alpha1.Resource("codertemplateversions")),
+	}
+}
+
+// New returns an empty CoderTemplateVersion object.
+func (s *TemplateVersionStorage) New() runtime.Object {
+	return &aggregationv1alpha1.CoderTemplateVersion{}
+}
+
+// Destroy cleans up storage resources.
+func (s *TemplateVersionStorage) Destroy() {}
+
+// NamespaceScoped returns true because CoderTemplateVersion is namespaced.
+func (s *TemplateVersionStorage) NamespaceScoped() bool {
+	return true
+}
+
+// GetSingularName returns the singular name of the CoderTemplateVersion resource.
+func (s *TemplateVersionStorage) GetSingularName() string {
+	return "codertemplateversion"
+}
+
+// ShortNames returns the kubectl short names of the CoderTemplateVersion
+// resource. Template versions cannot be listed, so they stay out of the coder
+// category.
+func (s *TemplateVersionStorage) ShortNames() []string {
+	return []string{"ctplv"}
+}
+
+// Get fetches a CoderTemplateVersion by organization, template name, and version ID.
+func (s *TemplateVersionStorage) Get(ctx context.Context, name string, _ *metav1.GetOptions) (runtime.Object, error) {
+	if s == nil {
+		return nil, fmt.Errorf("assertion failed: template version storage must not be nil")
+	}
+	if ctx == nil {
+		return nil, fmt.Errorf("assertion failed: context must not be nil")
+	}
+	if name == "" {
+		return nil, fmt.Errorf("assertion failed: template version name must not be empty")
+	}
+
+	namespace, badNamespaceErr := requiredNamespaceFromRequestContext(ctx)
+	if badNamespaceErr != nil {
+		return nil, badNamespaceErr
+	}
+
+	sdk, err := clientForProviderNamespace(ctx, s.provider, namespace)
+	if err != nil {
+		return nil, wrapClientError(err)
+	}
+
+	template, version, err := resolveTemplateVersion(ctx, sdk, name)
+	if err != nil {
+		return nil, err
+	}
+
+	return convert.TemplateVersionToK8s(namespace, template, version), nil
+}
+
+// ConvertToTable converts a template version object into kubectl table output.
+func (s *TemplateVersionStorage) ConvertToTable(ctx context.Context, object, tableOptions runtime.Object) (*metav1.Table, error) {
+	if s == nil {
+		return nil, fmt.Errorf("assertion failed: template version storage must not be nil")
+	}
+	if s.tableConvertor == nil {
+		return nil, fmt.Errorf("assertion failed: template version table convertor must not be nil")
+	}
+
+	return s.tableConvertor.ConvertToTable(ctx, object, tableOptions)
+}
+
+// TemplateVersionPromoteStorage implements the codertemplateversions/promote
+// subresource, which sets a template version as its template's active version.
+type TemplateVersionPromoteStorage struct {
+	templates *TemplateStorage
+}
+
+// NewTemplateVersionPromoteStorage builds the promote subresource storage.
+// Promotions are published as CoderTemplate watch events through templates.
+func NewTemplateVersionPromoteStorage(templates *TemplateStorage) *TemplateVersionPromoteStorage {
+	if templates == nil {
+		panic("assertion failed: template storage must not be nil")
+	}
+
+	return &TemplateVersionPromoteStorage{templates: templates}
+}
+
+// New returns an empty CoderTemplateVersion object, which is the promote request body.
+func (s *TemplateVersionPromoteStorage) New() runtime.Object {
+	return &aggregationv1alpha1.CoderTemplateVersion{}
+}
+
+// Destroy cleans up storage resources.
+func (s *TemplateVersionPromoteStorage) Destroy() {}
+
+// GroupVersionKind reports that promote responds with a CoderTemplate.
+func (s *TemplateVersionPromoteStorage) GroupVersionKind(_ schema.GroupVersion) schema.GroupVersionKind {
+	return aggregationv1alpha1.SchemeGroupVersion.WithKind("CoderTemplate")
+}
+
+// Create promotes the named template version to be its template's active
+// version and returns the updated CoderTemplate. The request body may be empty
+// apart from metadata; when metadata.name is set it must match the URL name.
+func (s *TemplateVersionPromoteStorage) Create(
+	ctx context.Context,
+	name string,
+	obj runtime.Object,
+	createValidation rest.ValidateObjectFunc,
+	_ *metav1.CreateOptions,
+) (runtime.Object, error) {
+	if s == nil {
+		return nil, fmt.Errorf("assertion failed: template version promote storage must not be nil")
+	}
+	if s.templates == nil {
+		return nil, fmt.Errorf("assertion failed: template storage must not be nil")
+	}
+	if ctx == nil {
+		return nil, fmt.Errorf("assertion failed: context must not be nil")
+	}
+	if name == "" {
+		return nil, fmt.Errorf("assertion failed: template version name must not be empty")
+	}
+
+	if obj != nil {
+		request, ok := obj.(*aggregationv1alpha1.CoderTemplateVersion)
+		if !ok {
+			return nil, fmt.Errorf("assertion failed: expected *CoderTemplateVersion, got %T", obj)
+		}
+		if request.Name != "" && request.Name != name {
+			return nil, apierrors.NewBadRequest(
+				fmt.Sprintf("metadata.name %q must match promoted template version %q", request.Name, name),
+			)
+		}
+	}
+	if createValidation != nil {
+		if err := createValidation(ctx, obj); err != nil {
+			return nil, err
+		}
+	}
+
+	namespace, badNamespaceErr := requiredNamespaceFromRequestContext(ctx)
+	if badNamespaceErr != nil {
+		return nil, badNamespaceErr
+	}
+
+	sdk, err := s.templates.clientForNamespace(ctx, namespace)
+	if err != nil {
+		return nil, wrapClientError(err)
+	}
+
+	template, version, err := resolveTemplateVersion(ctx, sdk, name)
+	if err != nil {
+		return nil, err
+	}
+
+	templateName := coder.BuildTemplateName(template.OrganizationName, template.Name)
+	promotedTemplate, err := promoteTemplateVersion(ctx, sdk, template.ID, version, templateName)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := s.templates.templateObject(ctx, sdk, namespace, templateName, promotedTemplate)
+	if err != nil {
+		return nil, err
+	}
+	s.templates.enqueueWatchEvent(watch.Modified, result.DeepCopy())
+
+	return result, nil
+}
+
+// resolveTemplateVersion looks up the template and version named by
+// "<org>.<template-name>.<version-id>" and rejects versions that belong to a
+// different template.
+func resolveTemplateVersion(
+	ctx context.Context,
+	sdk *codersdk.Client,
+	name string,
+) (codersdk.Template, codersdk.TemplateVersion, error) {
+	if sdk == nil {
+		return codersdk.Template{}, codersdk.TemplateVersion{}, fmt.Errorf("assertion failed: codersdk client must not be nil")
+	}
+
+	orgName, templateName, versionIDSegment, err := coder.ParseTemplateVersionName(name)
+	if err != nil {
+		return codersdk.Template{}, codersdk.TemplateVersion{}, apierrors.NewBadRequest(
+			fmt.Sprintf("invalid template version name %q: %v", name, err),
+		)
+	}
+
+	versionID, err := uuid.Parse(versionIDSegment)
+	if err != nil {
+		return codersdk.Template{}, codersdk.TemplateVersion{}, apierrors.NewBadRequest(
+			fmt.Sprintf("invalid template version name %q: invalid version ID %q: %v", name, versionIDSegment, err),
+		)
+	}
+
+	org, err := sdk.OrganizationByName(ctx, orgName)
+	if err != nil {
+		return codersdk.Template{}, codersdk.TemplateVersion{}, coder.MapCoderError(err, aggregationv1alpha1.Resource("codertemplateversions"), name)
+	}
+
+	template, err := sdk.TemplateByName(ctx, org.ID, templateName)
+	if err != nil {
+		return codersdk.Template{}, codersdk.TemplateVersion{}, coder.MapCoderError(err, aggregationv1alpha1.Resource("codertemplateversions"), name)
+	}
+
+	version, err := sdk.TemplateVersion(ctx, versionID)
+	if err != nil {
+		return codersdk.Template{}, codersdk.TemplateVersion{}, coder.MapCoderError(err, aggregationv1alpha1.Resource("codertemplateversions"), name)
+	}
+
+	if version.TemplateID == nil || *version.TemplateID != template.ID {
+		return codersdk.Template{}, codersdk.TemplateVersion{}, apierrors.NewBadRequest(
+			fmt.Sprintf("template version %q does not belong to template %q", versionID.String(), templateName),
+		)
+	}
+
+	return template, version, nil
+}
+
+func clientForProviderNamespace(ctx context.Context, provider coder.ClientProvider, namespace string) (*codersdk.Client, error) {
+	if provider == nil {
+		return nil, fmt.Errorf("assertion failed: client provider must not be nil")
+	}
+
+	sdk, err := provider.ClientForNamespace(ctx, namespace)
+	if err != nil {
+		return nil, fmt.Errorf("resolve codersdk client for namespace %q: %w", namespace, err)
+	}
+	if sdk == nil {
+		return nil, fmt.Errorf("assertion failed: client provider returned nil codersdk client")
+	}
+
+	return coder.WithRequestPolicy(sdk)
+}
@@ -6,7 +6,6 @@ import (
 	"testing"
 	"time"
 
-	"github.com/google/uuid"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metainternalversion "k8s.io/apimachinery/pkg/apis/meta/internalversion"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -207,7 +206,7 @@ func TestWorkspaceStorageWatch_AddedModified(t *testing.T) {
 func TestWatchRespectsFieldSelectorMetadataName(t *testing.T) {
 	t.Parallel()
 
-	server, _ := newMockCoderServer(t)
+	server, state := newMockCoderServer(t)
 	defer server.Close()
 
 	templateStorage := NewTemplateStorage(newTestClientProvider(t, server.URL))
@@ -230,7 +229,7 @@ func TestWatchRespectsFieldSelectorMetadataName(t *testing.T) {
 			ObjectMeta: metav1.ObjectMeta{Name: "acme.non-target-template"},
 			Spec: aggregationv1alpha1.CoderTemplateSpec{
 				Organization: "acme",
-				VersionID:    uuid.NewString(),
+				VersionID:    state.addDetachedTemplateVersion().String(),
 				DisplayName:  "Non Target",
 			},
 		},
@@ -248,7 +247,7 @@ func TestWatchRespectsFieldSelectorMetadataName(t *testing.T) {
 			ObjectMeta: metav1.ObjectMeta{Name: targetName},
 			Spec: aggregationv1alpha1.CoderTemplateSpec{
 				Organization: "acme",
-				VersionID:    uuid.NewString(),
+				VersionID:    state.addDetachedTemplateVersion().String(),
 				DisplayName:  "Target",
 			},
 		},
@@ -3,6 +3,7 @@ package storage
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"sort"
 	"sync"
 	"time"
@@ -11,10 +12,12 @@ import (
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metainternalversion "k8s.io/apimachinery/pkg/apis/meta/internalversion"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/watch"
 	genericapirequest "k8s.io/apiserver/pkg/endpoints/request"
 	"k8s.io/apiserver/pkg/registry/rest"
+	"k8s.io/apiserver/pkg/warning"
 
 	aggregationv1alpha1 "github.com/coder/coder-k8s/api/aggregation/v1alpha1"
 	"github.com/coder/coder-k8s/internal/aggregated/coder"
@@ -29,11 +32,29 @@ var (
 	_ rest.Watcher              = (*WorkspaceStorage)(nil)
 	_ rest.Creater              = (*WorkspaceStorage)(nil) //nolint:misspell // Kubernetes rest interface name is Creater.
 	_ rest.Updater              = (*WorkspaceStorage)(nil)
+	_ rest.Patcher              = (*WorkspaceStorage)(nil)
 	_ rest.GracefulDeleter      = (*WorkspaceStorage)(nil)
 	_ rest.Scoper               = (*WorkspaceStorage)(nil)
 	_ rest.SingularNameProvider = (*WorkspaceStorage)(nil)
+	_ rest.ShortNamesProvider   = (*WorkspaceStorage)(nil)
+	_ rest.CategoriesProvider   = (*WorkspaceStorage)(nil)
 )
 
+// workspaceRunningIntentTTL bounds how long a spec.running value set through
+// this API is remembered for out-of-band divergence warnings on Get.
+const workspaceRunningIntentTTL = 15 * time.Minute
+
+// coderResourceCategory groups the aggregated resources with the coder.com
+// CRDs, so `kubectl get coder` lists them together.
+const coderResourceCategory = "coder"
+
+// workspaceRunningIntent records the spec.running value most recently
+// requested through this API server for a workspace.
+type workspaceRunningIntent struct {
+	running    bool
+	recordedAt time.Time
+}
+
 // WorkspaceStorage provides codersdk-backed CoderWorkspace objects.
 type WorkspaceStorage struct {
 	provider       coder.ClientProvider
@@ -42,6 +63,13 @@ type WorkspaceStorage struct {
 	watchEvents    chan watch.Event
 	watchEventsWG  sync.WaitGroup
 	destroyOnce    sync.Once
+	managedFields  *managedFieldsStore
+	listCache      *listCache
+	listFanOutMode ListFanOutMode
+
+	runningIntentsMu sync.Mutex
+	runningIntents   map[string]workspaceRunningIntent
+	now              func() time.Time
 }
 
 // NewWorkspaceStorage builds codersdk-backed storage for CoderWorkspace resources.
@@ -55,6 +83,10 @@ func NewWorkspaceStorage(provider coder.ClientProvider) *WorkspaceStorage {
 		tableConvertor: rest.NewDefaultTableConvertor(aggregationv1alpha1.Resource("coderworkspaces")),
 		broadcaster:    watch.NewBroadcaster(watchBroadcasterQueueLen, watch.DropIfChannelFull),
 		watchEvents:    make(chan watch.Event, watchBroadcasterQueueLen),
+		managedFields:  newManagedFieldsStore(),
+		listCache:      newListCache(listCacheTTL),
+		runningIntents: make(map[string]workspaceRunningIntent),
+		now:            time.Now,
 	}
 	storage.watchEventsWG.Add(1)
 	go storage.dispatchWatchEvents()
@@ -62,6 +94,17 @@ func NewWorkspaceStorage(provider coder.ClientProvider) *WorkspaceStorage {
 	return storage
 }
 
+// SetListFanOutMode sets how an all-namespaces LIST handles a failing
+// namespace. It must be called before the storage serves requests; the zero
+// value is ListFanOutModeStrict.
+func (s *WorkspaceStorage) SetListFanOutMode(mode ListFanOutMode) {
+	if s == nil {
+		panic("assertion failed: workspace storage must not be nil")
+	}
+
+	s.listFanOutMode = mode
+}
+
 // New returns an empty CoderWorkspace object.
 func (s *WorkspaceStorage) New() runtime.Object {
 	return &aggregationv1alpha1.CoderWorkspace{}
@@ -96,6 +139,16 @@ func (s *WorkspaceStorage) GetSingularName() string {
 	return "coderworkspace"
 }
 
+// ShortNames returns the kubectl short names of the CoderWorkspace resource.
+func (s *WorkspaceStorage) ShortNames() []string {
+	return []string{"cws"}
+}
+
+// Categories returns the kubectl categories of the CoderWorkspace resource.
+func (s *WorkspaceStorage) Categories() []string {
+	return []string{coderResourceCategory}
+}
+
 // NewList returns an empty CoderWorkspaceList object.
 func (s *WorkspaceStorage) NewList() runtime.Object {
 	return &aggregationv1alpha1.CoderWorkspaceList{}
@@ -136,11 +189,20 @@ func (s *WorkspaceStorage) Get(ctx context.Context, name string, _ *metav1.GetOp
 		return nil, apierrors.NewNotFound(aggregationv1alpha1.Resource("coderworkspaces"), name)
 	}
 
-	return convert.WorkspaceToK8s(namespace, workspace), nil
+	result := convert.WorkspaceToK8s(namespace, workspace)
+	presetName := workspacePresetName(ctx, sdk, workspace.LatestBuild)
+	result.Spec.PresetName = presetName
+	result.Status.PresetName = presetName
+	result.ManagedFields = s.managedFields.get(result)
+	s.warnOnRunningDivergence(ctx, namespace, name, result.Spec.Running)
+
+	return result, nil
 }
 
-// List fetches CoderWorkspace objects from codersdk.
-func (s *WorkspaceStorage) List(ctx context.Context, _ *metainternalversion.ListOptions) (runtime.Object, error) {
+// List fetches CoderWorkspace objects from codersdk. Requests with
+// resourceVersion=0 are served from a short-lived cache of recent responses
+// when one exists.
+func (s *WorkspaceStorage) List(ctx context.Context, opts *metainternalversion.ListOptions) (runtime.Object, error) {
 	if s == nil {
 		return nil, fmt.Errorf("assertion failed: workspace storage must not be nil")
 	}
@@ -153,6 +215,30 @@ func (s *WorkspaceStorage) List(ctx context.Context, _ *metainternalversion.List
 		return nil, badNamespaceErr
 	}
 
+	cacheKey := listCacheKey(namespace, opts)
+	if listCacheRequested(opts) {
+		if list, ok := s.listCache.get(cacheKey); ok {
+			return list, nil
+		}
+	}
+
+	list, err := s.list(ctx, namespace, opts)
+	if err != nil {
+		return nil, err
+	}
+	s.listCache.put(cacheKey, list)
+
+	return list, nil
+}
+
+// list fetches CoderWorkspace objects for namespace from codersdk, fanning out
+// across eligible namespaces when namespace is empty.
+func (s *WorkspaceStorage) list(ctx context.Context, namespace string, opts *metainternalversion.ListOptions) (runtime.Object, error) {
+	fieldSelector, filter, err := workspaceListFilter(opts)
+	if err != nil {
+		return nil, err
+	}
+
 	if namespace == "" {
 		if lister, ok := s.provider.(coder.NamespaceLister); ok {
 			namespaces, err := lister.EligibleNamespaces(ctx)
@@ -160,28 +246,43 @@ func (s *WorkspaceStorage) List(ctx context.Context, _ *metainternalversion.List
 				return nil, err
 			}
 
-			list := &aggregationv1alpha1.CoderWorkspaceList{
-				TypeMeta: metav1.TypeMeta{
-					Kind:       "CoderWorkspaceList",
-					APIVersion: aggregationv1alpha1.SchemeGroupVersion.String(),
-				},
-				Items: make([]aggregationv1alpha1.CoderWorkspace, 0),
-			}
-
-			for _, eligibleNamespace := range namespaces {
+			items, err := fanOutList(ctx, s.listFanOutMode, namespaces, func(ctx context.Context, eligibleNamespace string) ([]aggregationv1alpha1.CoderWorkspace, error) {
 				sdk, err := s.clientForNamespace(ctx, eligibleNamespace)
 				if err != nil {
 					return nil, wrapClientError(err)
 				}
+				allowed, err := allowedOrganizations(ctx, s.provider, eligibleNamespace)
+				if err != nil {
+					return nil, err
+				}
 
-				workspacesResponse, err := sdk.Workspaces(ctx, codersdk.WorkspaceFilter{})
+				workspacesResponse, err := sdk.Workspaces(ctx, filter)
 				if err != nil {
 					return nil, coder.MapCoderError(err, aggregationv1alpha1.Resource("coderworkspaces"), "<list>")
 				}
 
+				items := make([]aggregationv1alpha1.CoderWorkspace, 0, len(workspacesResponse.Workspaces))
 				for _, workspace := range workspacesResponse.Workspaces {
-					list.Items = append(list.Items, *convert.WorkspaceToK8s(eligibleNamespace, workspace))
+					if !allowed.allows(workspace.OrganizationName) {
+						continue
+					}
+					item := convert.WorkspaceToK8s(eligibleNamespace, workspace)
+					if workspaceMatchesFieldSelector(item, fieldSelector) {
+						items = append(items, *item)
+					}
 				}
+				return items, nil
+			})
+			if err != nil {
+				return nil, err
+			}
+
+			list := &aggregationv1alpha1.CoderWorkspaceList{
+				TypeMeta: metav1.TypeMeta{
+					Kind:       "CoderWorkspaceList",
+					APIVersion: aggregationv1alpha1.SchemeGroupVersion.String(),
+				},
+				Items: items,
 			}
 
 			sort.Slice(list.Items, func(i, j int) bool {
@@ -204,8 +305,12 @@ func (s *WorkspaceStorage) List(ctx context.Context, _ *metainternalversion.List
 	if err != nil {
 		return nil, wrapClientError(err)
 	}
+	allowed, err := allowedOrganizations(ctx, s.provider, responseNamespace)
+	if err != nil {
+		return nil, err
+	}
 
-	workspacesResponse, err := sdk.Workspaces(ctx, codersdk.WorkspaceFilter{})
+	workspacesResponse, err := sdk.Workspaces(ctx, filter)
 	if err != nil {
 		return nil, coder.MapCoderError(err, aggregationv1alpha1.Resource("coderworkspaces"), "<list>")
 	}
@@ -219,12 +324,58 @@ func (s *WorkspaceStorage) List(ctx context.Context, _ *metainternalversion.List
 	}
 
 	for _, workspace := range workspacesResponse.Workspaces {
-		list.Items = append(list.Items, *convert.WorkspaceToK8s(responseNamespace, workspace))
+		if !allowed.allows(workspace.OrganizationName) {
+			continue
+		}
+		item := convert.WorkspaceToK8s(responseNamespace, workspace)
+		if workspaceMatchesFieldSelector(item, fieldSelector) {
+			list.Items = append(list.Items, *item)
+		}
 	}
 
 	return list, nil
 }
 
+// workspaceListFilter returns the field selector to apply to listed workspaces
+// and the codersdk filter to query with. A selector that pins
+// status.ownerName to one value scopes the backend query to that owner.
+func workspaceListFilter(opts *metainternalversion.ListOptions) (fields.Selector, codersdk.WorkspaceFilter, error) {
+	if opts == nil || opts.FieldSelector == nil || opts.FieldSelector.Empty() {
+		return nil, codersdk.WorkspaceFilter{}, nil
+	}
+
+	for _, requirement := range opts.FieldSelector.Requirements() {
+		switch requirement.Field {
+		case "metadata.name", "metadata.namespace", aggregationv1alpha1.CoderWorkspaceOwnerNameField:
+		default:
+			return nil, codersdk.WorkspaceFilter{}, apierrors.NewBadRequest(fmt.Sprintf(
+				"field selector %q is not supported; supported fields: metadata.name, metadata.namespace, %s",
+				requirement.Field,
+				aggregationv1alpha1.CoderWorkspaceOwnerNameField,
+			))
+		}
+	}
+
+	filter := codersdk.WorkspaceFilter{}
+	if owner, ok := opts.FieldSelector.RequiresExactMatch(aggregationv1alpha1.CoderWorkspaceOwnerNameField); ok && owner != "" {
+		filter.Owner = owner
+	}
+
+	return opts.FieldSelector, filter, nil
+}
+
+func workspaceMatchesFieldSelector(workspace *aggregationv1alpha1.CoderWorkspace, selector fields.Selector) bool {
+	if selector == nil {
+		return true
+	}
+
+	return selector.Matches(fields.Set{
+		"metadata.name":      workspace.Name,
+		"metadata.namespace": workspace.Namespace,
+		aggregationv1alpha1.CoderWorkspaceOwnerNameField: workspace.Status.OwnerName,
+	})
+}
+
 // Watch watches CoderWorkspace objects backed by codersdk.
 func (s *WorkspaceStorage) Watch(ctx context.Context, opts *metainternalversion.ListOptions) (watch.Interface, error) {
 	if s == nil {
@@ -294,7 +445,7 @@ func (s *WorkspaceStorage) Create(
 	ctx context.Context,
 	obj runtime.Object,
 	createValidation rest.ValidateObjectFunc,
-	_ *metav1.CreateOptions,
+	opts *metav1.CreateOptions,
 ) (runtime.Object, error) {
 	if s == nil {
 		return nil, fmt.Errorf("assertion failed: workspace storage must not be nil")
@@ -348,14 +499,28 @@ func (s *WorkspaceStorage) Create(
 	if workspaceObj.Spec.TemplateName == "" {
 		return nil, apierrors.NewBadRequest("spec.templateName must not be empty")
 	}
+	if workspaceObj.Spec.UseActiveTemplateVersion && workspaceObj.Spec.TemplateVersionID != "" {
+		return nil, apierrors.NewBadRequest("spec.useActiveTemplateVersion cannot be combined with spec.templateVersionID")
+	}
 
 	sdk, err := s.clientForNamespace(ctx, namespace)
 	if err != nil {
 		return nil, wrapClientError(err)
 	}
 
-	org, err := sdk.OrganizationByName(ctx, orgName)
+	org, err := organizationForCreate(ctx, sdk, orgName, aggregationv1alpha1.Resource("coderworkspaces"), workspaceObj.Name)
 	if err != nil {
+		return nil, err
+	}
+
+	// The workspace is created on behalf of the user named in metadata.name,
+	// who need not be the user behind the session token.
+	if _, err := sdk.User(ctx, userName); err != nil {
+		if coderStatusCode(err) == http.StatusNotFound {
+			return nil, apierrors.NewBadRequest(
+				fmt.Sprintf("Coder user %q parsed from metadata.name not found", userName),
+			)
+		}
 		return nil, coder.MapCoderError(err, aggregationv1alpha1.Resource("coderworkspaces"), workspaceObj.Name)
 	}
 
@@ -368,6 +533,7 @@ func (s *WorkspaceStorage) Create(
 		)
 	}
 
+	templateVersionID := template.ActiveVersionID
 	if workspaceObj.Spec.TemplateVersionID != "" {
 		parsedTemplateVersionID, parseErr := uuid.Parse(workspaceObj.Spec.TemplateVersionID)
 		if parseErr != nil {
@@ -398,6 +564,7 @@ func (s *WorkspaceStorage) Create(
 				),
 			)
 		}
+		templateVersionID = parsedTemplateVersionID
 	}
 
 	request, err := convert.WorkspaceCreateRequestFromK8s(workspaceObj, workspaceName, template.ID)
@@ -405,6 +572,40 @@ func (s *WorkspaceStorage) Create(
 		return nil, apierrors.NewBadRequest(fmt.Sprintf("invalid workspace spec: %v", err))
 	}
 
+	// Pin the active version resolved above instead of letting Coder pick
+	// whichever version is active when it handles the request.
+	if workspaceObj.Spec.UseActiveTemplateVersion {
+		request.TemplateID = uuid.Nil
+		request.TemplateVersionID = templateVersionID
+	}
+
+	if workspaceObj.Spec.PresetName != "" {
+		preset, presetErr := resolveWorkspacePreset(ctx, sdk, templateVersionID, workspaceObj.Spec.PresetName, workspaceObj.Name)
+		if presetErr != nil {
+			return nil, presetErr
+		}
+		// Coder applies the preset's parameter values itself; sending them
+		// again as rich parameters would apply them twice.
+		request.TemplateVersionPresetID = preset.ID
+	}
+
+	if opts != nil && isDryRun(opts.DryRun) {
+		_, err := sdk.WorkspaceByOwnerAndName(ctx, userName, workspaceName, codersdk.WorkspaceOptions{})
+		switch {
+		case err == nil:
+			return nil, apierrors.NewAlreadyExists(aggregationv1alpha1.Resource("coderworkspaces"), workspaceObj.Name)
+		case coderStatusCode(err) != http.StatusNotFound:
+			return nil, coder.MapCoderError(err, aggregationv1alpha1.Resource("coderworkspaces"), workspaceObj.Name)
+		}
+
+		result := workspaceObj.DeepCopy()
+		result.Namespace = namespace
+		if workspaceObj.Spec.UseActiveTemplateVersion {
+			result.Status.TemplateVersionID = templateVersionID.String()
+		}
+		return result, nil
+	}
+
 	createdWorkspace, err := sdk.CreateUserWorkspace(ctx, userName, request)
 	if err != nil {
 		return nil, coder.MapCoderError(err, aggregationv1alpha1.Resource("coderworkspaces"), workspaceObj.Name)
@@ -429,6 +630,10 @@ func (s *WorkspaceStorage) Create(
 	if result == nil {
 		return nil, fmt.Errorf("assertion failed: converted workspace must not be nil")
 	}
+	result.Spec.PresetName = workspaceObj.Spec.PresetName
+	result.Status.PresetName = workspaceObj.Spec.PresetName
+	s.recordRunningIntent(namespace, workspaceObj.Name, workspaceObj.Spec.Running)
+	s.managedFields.record(result, workspaceObj.ManagedFields)
 
 	s.enqueueWatchEvent(watch.Added, result.DeepCopy())
 
@@ -443,7 +648,7 @@ func (s *WorkspaceStorage) Update(
 	createValidation rest.ValidateObjectFunc,
 	updateValidation rest.ValidateObjectUpdateFunc,
 	forceAllowCreate bool,
-	_ *metav1.UpdateOptions,
+	opts *metav1.UpdateOptions,
 ) (runtime.Object, bool, error) {
 	if s == nil {
 		return nil, false, fmt.Errorf("assertion failed: workspace storage must not be nil")
@@ -521,7 +726,7 @@ func (s *WorkspaceStorage) Update(
 			)
 		}
 
-		createdObj, createErr := s.Create(ctx, createWorkspace, createValidation, nil)
+		createdObj, createErr := s.Create(ctx, createWorkspace, createValidation, createOptionsForDryRun(opts))
 		if createErr != nil {
 			return nil, false, createErr
 		}
@@ -533,6 +738,7 @@ func (s *WorkspaceStorage) Update(
 	}
 
 	currentK8sObj := convert.WorkspaceToK8s(namespace, currentWorkspace)
+	currentK8sObj.ManagedFields = s.managedFields.get(currentK8sObj)
 	desiredObjRuntime, err := objInfo.UpdatedObject(ctx, currentK8sObj.DeepCopy())
 	if err != nil {
 		return nil, false, err
@@ -583,21 +789,90 @@ func (s *WorkspaceStorage) Update(
 			return nil, false, err
 		}
 	}
+	if err := rejectAppliedStatus(desiredObj.ManagedFields); err != nil {
+		return nil, false, err
+	}
 
-	// Workspace updates via codersdk are currently limited to workspace build
-	// transitions, which map only to spec.running toggles in this API.
+	// Workspace updates via codersdk are limited to schedule updates and
+	// workspace build transitions, which map to spec.running toggles.
 	if desiredObj.Spec.Organization != currentK8sObj.Spec.Organization ||
 		desiredObj.Spec.TemplateName != currentK8sObj.Spec.TemplateName ||
-		(desiredObj.Spec.TemplateVersionID != "" && desiredObj.Spec.TemplateVersionID != currentK8sObj.Spec.TemplateVersionID) ||
-		(desiredObj.Spec.TTLMillis != nil && !equalInt64Ptr(desiredObj.Spec.TTLMillis, currentK8sObj.Spec.TTLMillis)) ||
-		(desiredObj.Spec.AutostartSchedule != nil && !equalStringPtr(desiredObj.Spec.AutostartSchedule, currentK8sObj.Spec.AutostartSchedule)) {
+		(desiredObj.Spec.TemplateVersionID != "" && desiredObj.Spec.TemplateVersionID != currentK8sObj.Spec.TemplateVersionID) {
 		return nil, false, apierrors.NewBadRequest(
-			"workspace update only supports changing spec.running; other spec fields are immutable",
+			"workspace update only supports changing spec.running, spec.ttlMillis, and spec.autostartSchedule; other spec fields are immutable",
 		)
 	}
+	if desiredObj.Spec.TTLMillis != nil && *desiredObj.Spec.TTLMillis < 0 {
+		return nil, false, apierrors.NewBadRequest(
+			fmt.Sprintf("spec.ttlMillis must not be negative, got %d", *desiredObj.Spec.TTLMillis),
+		)
+	}
+
+	// Omitted schedule fields keep their current values; zero or empty values
+	// disable autostop or autostart. Schedule updates never start a build.
+	ttlChanged := desiredObj.Spec.TTLMillis != nil && !equalInt64PtrOrZero(desiredObj.Spec.TTLMillis, currentK8sObj.Spec.TTLMillis)
+	autostartChanged := desiredObj.Spec.AutostartSchedule != nil &&
+		!equalStringPtrOrEmpty(desiredObj.Spec.AutostartSchedule, currentK8sObj.Spec.AutostartSchedule)
+
+	if opts != nil && isDryRun(opts.DryRun) {
+		result := currentK8sObj.DeepCopy()
+		result.ManagedFields = copyManagedFields(desiredObj.ManagedFields)
+		result.Spec.Running = desiredObj.Spec.Running
+		if ttlChanged {
+			result.Spec.TTLMillis = nil
+			if *desiredObj.Spec.TTLMillis > 0 {
+				result.Spec.TTLMillis = desiredObj.Spec.TTLMillis
+			}
+		}
+		if autostartChanged {
+			result.Spec.AutostartSchedule = nil
+			if *desiredObj.Spec.AutostartSchedule != "" {
+				result.Spec.AutostartSchedule = desiredObj.Spec.AutostartSchedule
+			}
+		}
+		return result, false, nil
+	}
+
+	if ttlChanged {
+		var ttlMillis *int64
+		if *desiredObj.Spec.TTLMillis > 0 {
+			ttlMillis = desiredObj.Spec.TTLMillis
+		}
+		if err := sdk.UpdateWorkspaceTTL(ctx, currentWorkspace.ID, codersdk.UpdateWorkspaceTTLRequest{TTLMillis: ttlMillis}); err != nil {
+			return nil, false, coder.MapCoderError(err, aggregationv1alpha1.Resource("coderworkspaces"), name)
+		}
+	}
+	if autostartChanged {
+		var schedule *string
+		if *desiredObj.Spec.AutostartSchedule != "" {
+			schedule = desiredObj.Spec.AutostartSchedule
+		}
+		if err := sdk.UpdateWorkspaceAutostart(ctx, currentWorkspace.ID, codersdk.UpdateWorkspaceAutostartRequest{Schedule: schedule}); err != nil {
+			return nil, false, coder.MapCoderError(err, aggregationv1alpha1.Resource("coderworkspaces"), name)
+		}
+	}
+	if ttlChanged || autostartChanged {
+		currentWorkspace, err = sdk.Workspace(ctx, currentWorkspace.ID)
+		if err != nil {
+			return nil, false, coder.MapCoderError(err, aggregationv1alpha1.Resource("coderworkspaces"), name)
+		}
+	}
 
 	if desiredObj.Spec.Running == currentK8sObj.Spec.Running {
-		return currentK8sObj, false, nil
+		s.recordRunningIntent(namespace, name, desiredObj.Spec.Running)
+		if !ttlChanged && !autostartChanged {
+			s.managedFields.record(currentK8sObj, desiredObj.ManagedFields)
+			return currentK8sObj, false, nil
+		}
+
+		result := convert.WorkspaceToK8s(namespace, currentWorkspace)
+		if result == nil {
+			return nil, false, fmt.Errorf("assertion failed: converted workspace must not be nil")
+		}
+		s.managedFields.record(result, desiredObj.ManagedFields)
+		s.enqueueWatchEvent(watch.Modified, result.DeepCopy())
+
+		return result, false, nil
 	}
 
 	transition := codersdk.WorkspaceTransitionStop
@@ -621,18 +896,24 @@ func (s *WorkspaceStorage) Update(
 	if result == nil {
 		return nil, false, fmt.Errorf("assertion failed: converted workspace must not be nil")
 	}
+	s.recordRunningIntent(namespace, name, desiredObj.Spec.Running)
+	s.managedFields.record(result, desiredObj.ManagedFields)
 
 	s.enqueueWatchEvent(watch.Modified, result.DeepCopy())
 
 	return result, false, nil
 }
 
-// Delete requests workspace deletion through a codersdk build transition.
+// Delete requests workspace deletion through a codersdk build transition. By
+// default it returns as soon as the delete build is queued. With a positive
+// gracePeriodSeconds it waits up to that long, capped at
+// maxWorkspaceDeleteWait, for the build to finish and then reports
+// deleted=true.
 func (s *WorkspaceStorage) Delete(
 	ctx context.Context,
 	name string,
 	deleteValidation rest.ValidateObjectFunc,
-	_ *metav1.DeleteOptions,
+	opts *metav1.DeleteOptions,
 ) (runtime.Object, bool, error) {
 	if s == nil {
 		return nil, false, fmt.Errorf("assertion failed: workspace storage must not be nil")
@@ -694,16 +975,93 @@ func (s *WorkspaceStorage) Delete(
 	if workspaceObj == nil {
 		return nil, false, fmt.Errorf("assertion failed: converted workspace must not be nil")
 	}
+	s.forgetRunningIntent(namespace, name)
+	s.managedFields.forget(namespace, name)
 
 	// Workspace deletion is asynchronous in Coder. Emit a Modified event
 	// to signal that deletion was requested, rather than a Deleted event.
 	s.enqueueWatchEvent(watch.Modified, workspaceObj.DeepCopy())
 
+	if wait := workspaceDeleteWait(opts); wait > 0 {
+		latestWorkspace, deleted, err := waitForWorkspaceDelete(ctx, sdk, workspace, deleteBuild.ID, name, wait)
+		if err != nil {
+			return nil, false, err
+		}
+		if latestWorkspace.ID != uuid.Nil {
+			workspaceObj = convert.WorkspaceToK8s(namespace, latestWorkspace)
+			if workspaceObj == nil {
+				return nil, false, fmt.Errorf("assertion failed: converted workspace must not be nil")
+			}
+		}
+		if !deleted {
+			// The delete build outlived the wait but is queued in Coder.
+			// Answer like a graceful delete still in progress: the object
+			// with a deletion timestamp, reported as not yet deleted.
+			deletionTimestamp := metav1.Now()
+			workspaceObj.DeletionTimestamp = &deletionTimestamp
+			return workspaceObj, false, nil
+		}
+		s.enqueueWatchEvent(watch.Deleted, workspaceObj.DeepCopy())
+
+		return &metav1.Status{Status: metav1.StatusSuccess}, true, nil
+	}
+
 	// Deletion is asynchronous in Coder: we only enqueue a delete build transition here.
 	// Report deleted=false so Kubernetes callers know the resource is not gone yet.
 	return &metav1.Status{Status: metav1.StatusSuccess}, false, nil
 }
 
+func workspaceRunningIntentKey(namespace, name string) string {
+	return namespace + "/" + name
+}
+
+func (s *WorkspaceStorage) recordRunningIntent(namespace, name string, running bool) {
+	s.runningIntentsMu.Lock()
+	defer s.runningIntentsMu.Unlock()
+
+	if s.runningIntents == nil {
+		s.runningIntents = make(map[string]workspaceRunningIntent)
+	}
+	now := s.now()
+	for key, intent := range s.runningIntents {
+		if now.Sub(intent.recordedAt) > workspaceRunningIntentTTL {
+			delete(s.runningIntents, key)
+		}
+	}
+	s.runningIntents[workspaceRunningIntentKey(namespace, name)] = workspaceRunningIntent{
+		running:    running,
+		recordedAt: now,
+	}
+}
+
+func (s *WorkspaceStorage) forgetRunningIntent(namespace, name string) {
+	s.runningIntentsMu.Lock()
+	defer s.runningIntentsMu.Unlock()
+
+	delete(s.runningIntents, workspaceRunningIntentKey(namespace, name))
+}
+
+// warnOnRunningDivergence adds a client warning when Coder reports a run state
+// that differs from the spec.running value recently set through this API,
+// which usually means the workspace was started or stopped out-of-band.
+func (s *WorkspaceStorage) warnOnRunningDivergence(ctx context.Context, namespace, name string, running bool) {
+	s.runningIntentsMu.Lock()
+	intent, ok := s.runningIntents[workspaceRunningIntentKey(namespace, name)]
+	s.runningIntentsMu.Unlock()
+
+	if !ok || s.now().Sub(intent.recordedAt) > workspaceRunningIntentTTL || intent.running == running {
+		return
+	}
+
+	warning.AddWarning(ctx, "", fmt.Sprintf(
+		"coderworkspace %q reports spec.running=%t but spec.running=%t was set through this API at %s; the workspace may have been changed outside Kubernetes",
+		name,
+		running,
+		intent.running,
+		intent.recordedAt.UTC().Format(time.RFC3339),
+	))
+}
+
 func (s *WorkspaceStorage) dispatchWatchEvents() {
 	defer s.watchEventsWG.Done()
 
@@ -716,6 +1074,9 @@ func (s *WorkspaceStorage) enqueueWatchEvent(action watch.EventType, obj runtime
 	if s == nil {
 		panic("assertion failed: workspace storage must not be nil")
 	}
+
+	// Writes through this API server must not be hidden by cached lists.
+	s.listCache.reset()
 	if s.watchEvents == nil {
 		panic("assertion failed: workspace watch event queue must not be nil")
 	}
@@ -767,7 +1128,22 @@ func (s *WorkspaceStorage) clientForNamespace(ctx context.Context, namespace str
 		return nil, fmt.Errorf("assertion failed: workspace client provider returned nil codersdk client")
 	}
 
-	return sdk, nil
+	return coder.WithRequestPolicy(sdk)
+}
+
+// isDryRun reports whether a request's dryRun option asks for validation only.
+func isDryRun(dryRun []string) bool {
+	return len(dryRun) > 0
+}
+
+// createOptionsForDryRun carries an update's dryRun option into the create it
+// falls back to.
+func createOptionsForDryRun(opts *metav1.UpdateOptions) *metav1.CreateOptions {
+	if opts == nil {
+		return nil
+	}
+
+	return &metav1.CreateOptions{DryRun: opts.DryRun}
 }
 
 func namespaceFromRequestContext(ctx context.Context) (string, error) {
@@ -822,25 +1198,3 @@ func namespaceForListConversion(
 
 	return resolvedNamespace, nil
 }
-
-func equalInt64Ptr(a, b *int64) bool {
-	if a == nil && b == nil {
-		return true
-	}
-	if a == nil || b == nil {
-		return false
-	}
-
-	return *a == *b
-}
-
-func equalStringPtr(a, b *string) bool {
-	if a == nil && b == nil {
-		return true
-	}
-	if a == nil || b == nil {
-		return false
-	}
-
-	return *a == *b
-}
@@ -0,0 +1,114 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/coder/coder/v2/codersdk"
+	"github.com/google/uuid"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	aggregationv1alpha1 "github.com/coder/coder-k8s/api/aggregation/v1alpha1"
+	"github.com/coder/coder-k8s/internal/aggregated/coder"
+)
+
+const (
+	workspaceDeletePollInterval = time.Second
+	// maxWorkspaceDeleteWait caps how long a workspace DELETE blocks on its
+	// delete build, whatever gracePeriodSeconds asks for. DELETE is a
+	// short-running request, so kube-apiserver cuts the proxied call off at
+	// its request timeout (60 seconds by default); the wait must end first so
+	// the caller gets this server's answer rather than a generic timeout.
+	maxWorkspaceDeleteWait = 50 * time.Second
+)
+
+// workspaceDeleteWait reports how long a workspace DELETE should wait for its
+// delete build to finish. Without gracePeriodSeconds, or with a value of zero
+// or less, it returns zero and the delete stays asynchronous. kubectl only
+// sends gracePeriodSeconds when --grace-period is set.
+//
+// A workspace has no pods to terminate gracefully, so the aggregated API
+// reuses gracePeriodSeconds as "how long to wait for the delete build" rather
+// than inventing a non-standard DeleteOptions field that kubectl cannot set.
+// Clients that need longer should delete asynchronously and watch the
+// workspace until it is gone.
+func workspaceDeleteWait(opts *metav1.DeleteOptions) time.Duration {
+	if opts == nil || opts.GracePeriodSeconds == nil || *opts.GracePeriodSeconds <= 0 {
+		return 0
+	}
+
+	wait := time.Duration(*opts.GracePeriodSeconds) * time.Second
+	if wait > maxWorkspaceDeleteWait {
+		return maxWorkspaceDeleteWait
+	}
+
+	return wait
+}
+
+// waitForWorkspaceDelete polls the workspace until deleteBuildID completes or
+// wait runs out. It returns the latest workspace snapshot and whether the
+// delete finished. A workspace that Coder no longer serves counts as deleted,
+// and the zero workspace is returned for it. Running out of time is not an
+// error: the delete build is queued and continues in Coder, so the caller
+// reports the workspace as still being deleted.
+func waitForWorkspaceDelete(
+	ctx context.Context,
+	sdk *codersdk.Client,
+	workspace codersdk.Workspace,
+	deleteBuildID uuid.UUID,
+	name string,
+	wait time.Duration,
+) (codersdk.Workspace, bool, error) {
+	if sdk == nil {
+		return codersdk.Workspace{}, false, fmt.Errorf("assertion failed: codersdk client must not be nil")
+	}
+	if wait <= 0 {
+		return codersdk.Workspace{}, false, fmt.Errorf("assertion failed: workspace delete wait must be positive, got %s", wait)
+	}
+
+	waitCtx, cancel := context.WithTimeout(ctx, wait)
+	defer cancel()
+
+	for {
+		current, err := sdk.Workspace(waitCtx, workspace.ID)
+		switch {
+		case err != nil && waitCtx.Err() == nil:
+			// Coder answers 410 Gone for deleted workspaces.
+			if code := coderStatusCode(err); code == http.StatusNotFound || code == http.StatusGone {
+				return codersdk.Workspace{}, true, nil
+			}
+			return codersdk.Workspace{}, false, coder.MapCoderError(err, aggregationv1alpha1.Resource("coderworkspaces"), name)
+		case err == nil && current.LatestBuild.ID == deleteBuildID:
+			workspace = current
+			switch current.LatestBuild.Status {
+			case codersdk.WorkspaceStatusDeleted:
+				return current, true, nil
+			case codersdk.WorkspaceStatusFailed, codersdk.WorkspaceStatusCanceled:
+				return codersdk.Workspace{}, false, apierrors.NewInternalError(fmt.Errorf(
+					"delete build for workspace %q ended with status %q: %s",
+					name,
+					current.LatestBuild.Status,
+					current.LatestBuild.Job.Error,
+				))
+			}
+		case err == nil:
+			return codersdk.Workspace{}, false, apierrors.NewConflict(
+				aggregationv1alpha1.Resource("coderworkspaces"),
+				name,
+				fmt.Errorf("delete build was superseded by build %q", current.LatestBuild.ID.String()),
+			)
+		}
+
+		select {
+		case <-waitCtx.Done():
+			if err := ctx.Err(); err != nil {
+				return codersdk.Workspace{}, false, err
+			}
+			return workspace, false, nil
+		case <-time.After(workspaceDeletePollInterval):
+		}
+	}
+}
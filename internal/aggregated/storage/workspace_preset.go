@@ -0,0 +1,72 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/google/uuid"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+
+	aggregationv1alpha1 "github.com/coder/coder-k8s/api/aggregation/v1alpha1"
+	"github.com/coder/coder-k8s/internal/aggregated/coder"
+	"github.com/coder/coder/v2/codersdk"
+)
+
+// resolveWorkspacePreset looks up the preset named presetName among the presets
+// of templateVersionID. A preset that the version does not define is rejected
+// before the workspace is created.
+func resolveWorkspacePreset(
+	ctx context.Context,
+	sdk *codersdk.Client,
+	templateVersionID uuid.UUID,
+	presetName string,
+	name string,
+) (codersdk.Preset, error) {
+	if sdk == nil {
+		return codersdk.Preset{}, fmt.Errorf("assertion failed: codersdk client must not be nil")
+	}
+	if templateVersionID == uuid.Nil {
+		return codersdk.Preset{}, fmt.Errorf("assertion failed: template version ID must not be nil")
+	}
+
+	presets, err := sdk.TemplateVersionPresets(ctx, templateVersionID)
+	if err != nil && coderStatusCode(err) != http.StatusNotFound {
+		return codersdk.Preset{}, coder.MapCoderError(err, aggregationv1alpha1.Resource("coderworkspaces"), name)
+	}
+	for _, preset := range presets {
+		if preset.Name == presetName {
+			return preset, nil
+		}
+	}
+
+	return codersdk.Preset{}, apierrors.NewBadRequest(fmt.Sprintf(
+		"spec.presetName %q is not a preset of template version %q",
+		presetName,
+		templateVersionID,
+	))
+}
+
+// workspacePresetName returns the name of the preset build used. The name is
+// informational, so it is left empty when the build used none, the preset no
+// longer exists, or the presets cannot be listed.
+func workspacePresetName(ctx context.Context, sdk *codersdk.Client, build codersdk.WorkspaceBuild) string {
+	if sdk == nil {
+		panic("assertion failed: codersdk client must not be nil")
+	}
+	if build.TemplateVersionPresetID == nil || *build.TemplateVersionPresetID == uuid.Nil {
+		return ""
+	}
+
+	presets, err := sdk.TemplateVersionPresets(ctx, build.TemplateVersionID)
+	if err != nil {
+		return ""
+	}
+	for _, preset := range presets {
+		if preset.ID == *build.TemplateVersionPresetID {
+			return preset.Name
+		}
+	}
+
+	return ""
+}
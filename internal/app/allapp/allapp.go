@@ -23,8 +23,9 @@ const (
 )
 
 var (
-	newManager             = controllerapp.NewManager
-	setupControllers       = controllerapp.SetupControllers
+	newManager             = controllerapp.NewManagerWithOptions
+	setupControllers       = controllerapp.SetupControllersWithOptions
+	setupWebhooks          = controllerapp.SetupWebhooksWithOptions
 	setupProbes            = controllerapp.SetupProbes
 	runAggregatedAPIServer = func(ctx context.Context, opts apiserverapp.Options) error {
 		return apiserverapp.RunWithOptions(ctx, opts)
@@ -52,6 +53,12 @@ func (nonLeaderRunnable) NeedLeaderElection() bool {
 
 // Run starts all app modes together using a shared controller-runtime manager/cache.
 func Run(ctx context.Context, coderRequestTimeout time.Duration) error {
+	return RunWithOptions(ctx, coderRequestTimeout, controllerapp.Options{})
+}
+
+// RunWithOptions starts all app modes together, configuring the controllers
+// with controllerOpts.
+func RunWithOptions(ctx context.Context, coderRequestTimeout time.Duration, controllerOpts controllerapp.Options) error {
 	if ctx == nil {
 		return fmt.Errorf("assertion failed: context must not be nil")
 	}
@@ -74,7 +81,7 @@ func Run(ctx context.Context, coderRequestTimeout time.Duration) error {
 		return fmt.Errorf("assertion failed: config is nil after successful construction")
 	}
 
-	mgr, err := newManager(cfg, scheme)
+	mgr, err := newManager(cfg, scheme, controllerOpts)
 	if err != nil {
 		return err
 	}
@@ -82,7 +89,10 @@ func Run(ctx context.Context, coderRequestTimeout time.Duration) error {
 		return fmt.Errorf("assertion failed: manager is nil after successful construction")
 	}
 
-	if err := setupControllers(mgr); err != nil {
+	if err := setupControllers(mgr, controllerOpts); err != nil {
+		return err
+	}
+	if err := setupWebhooks(mgr, controllerOpts); err != nil {
 		return err
 	}
 	if err := setupProbes(mgr); err != nil {
@@ -117,8 +127,17 @@ func Run(ctx context.Context, coderRequestTimeout time.Duration) error {
 				return fmt.Errorf("assertion failed: control plane client provider is nil after successful construction")
 			}
 
+			transport, err := coder.NewTransport(coder.TransportConfig{})
+			if err != nil {
+				return fmt.Errorf("build coder client transport: %w", err)
+			}
+			cachingProvider, err := coder.NewCachingClientProvider(provider, transport)
+			if err != nil {
+				return fmt.Errorf("build caching client provider: %w", err)
+			}
+
 			return runAggregatedAPIServer(runnableCtx, apiserverapp.Options{
-				ClientProvider:      provider,
+				ClientProvider:      cachingProvider,
 				CoderRequestTimeout: requestTimeout,
 			})
 		},
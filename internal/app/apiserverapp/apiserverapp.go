@@ -7,6 +7,7 @@ import (
 	"log"
 	"net"
 	"net/url"
+	"os"
 	"strings"
 	"time"
 
@@ -25,9 +26,12 @@ import (
 	genericapiserver "k8s.io/apiserver/pkg/server"
 	genericoptions "k8s.io/apiserver/pkg/server/options"
 	apiservercompatibility "k8s.io/apiserver/pkg/util/compatibility"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	openapicommon "k8s.io/kube-openapi/pkg/common"
 	openapiutil "k8s.io/kube-openapi/pkg/util"
 	"k8s.io/kube-openapi/pkg/validation/spec"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	aggregationv1alpha1 "github.com/coder/coder-k8s/api/aggregation/v1alpha1"
 	"github.com/coder/coder-k8s/internal/aggregated/coder"
@@ -40,6 +44,10 @@ const (
 	serverName               = "coder-k8s-aggregated-apiserver"
 	// defaultRequestTimeout keeps API request lifetimes aligned with template build wait limits.
 	defaultRequestTimeout = storage.MaxTemplateVersionBuildWaitTimeout
+
+	// ListFanOutModeEnv names the environment variable read at startup when
+	// Options.ListFanOutMode is empty: "strict" (default) or "best-effort".
+	ListFanOutModeEnv = "CODER_K8S_LIST_FANOUT_MODE"
 )
 
 // Options configures aggregated-apiserver bootstrap behavior.
@@ -52,13 +60,42 @@ type Options struct {
 	CoderURL string
 	// CoderSessionToken is the admin session token.
 	CoderSessionToken string
+	// CoderSessionTokenFile reads the admin session token from this file, for
+	// example a mounted Secret, instead of CoderSessionToken. The file is
+	// re-read every CoderSessionTokenReloadInterval and after Coder rejects
+	// the token, so rotating it does not require a restart.
+	CoderSessionTokenFile string
+	// CoderSessionTokenReloadInterval is how often CoderSessionTokenFile is
+	// re-read. Default: coder.DefaultSessionTokenReloadInterval.
+	CoderSessionTokenReloadInterval time.Duration
 	// CoderNamespace restricts the provider to serve only this namespace.
 	// When non-empty, requests to other namespaces are rejected.
 	CoderNamespace string
+	// CoderOrganizations limits LIST to these Coder organization names.
+	// When empty, every organization is listed.
+	CoderOrganizations []string
+	// CoderNamespaceSelector serves every namespace matching this label
+	// selector, reading each namespace's Coder URL and admin token from the
+	// CoderAdminTokenSecret Secret in that namespace. It cannot be combined with
+	// the static Coder* options; organizations are limited per namespace by
+	// the coder.com/aggregated-organizations annotation instead.
+	CoderNamespaceSelector string
+	// CoderAdminTokenSecret names the per-namespace admin token Secret used with
+	// CoderNamespaceSelector. Default: coder.DefaultAdminTokenSecretName.
+	CoderAdminTokenSecret string
 	// CoderRequestTimeout for SDK calls. Default 30s.
 	CoderRequestTimeout time.Duration
+	// EnableTemplateGitSources lets CoderTemplate spec.gitSource fetch template
+	// source from Git repositories with the git binary on PATH. Disabled by
+	// default so the server makes no outbound Git requests.
+	EnableTemplateGitSources bool
+	// ListFanOutMode selects how an all-namespaces LIST handles a namespace
+	// whose Coder backend fails. Empty reads ListFanOutModeEnv once at
+	// startup, which defaults to storage.ListFanOutModeStrict.
+	ListFanOutMode storage.ListFanOutMode
 	// ClientProvider overrides the default static provider.
-	// When set, CoderURL/CoderSessionToken/CoderNamespace flags are ignored.
+	// When set, the CoderURL/CoderSessionToken/CoderNamespace and
+	// CoderNamespaceSelector flags are ignored.
 	ClientProvider coder.ClientProvider
 }
 
@@ -87,18 +124,29 @@ func buildClientProvider(opts Options, requestTimeout time.Duration) (coder.Clie
 		return nil, fmt.Errorf("assertion failed: request timeout must be positive")
 	}
 
+	if strings.TrimSpace(opts.CoderNamespaceSelector) != "" {
+		return buildNamespaceLabelClientProvider(opts, requestTimeout)
+	}
+
 	coderURL := strings.TrimSpace(opts.CoderURL)
 	sessionToken := strings.TrimSpace(opts.CoderSessionToken)
+	sessionTokenFile := strings.TrimSpace(opts.CoderSessionTokenFile)
+	if sessionToken != "" && sessionTokenFile != "" {
+		return nil, fmt.Errorf("--coder-session-token cannot be combined with --coder-session-token-file")
+	}
+	if opts.CoderSessionTokenReloadInterval < 0 {
+		return nil, fmt.Errorf("assertion failed: coder session token reload interval must not be negative")
+	}
 	missing := make([]string, 0, 2)
 	if coderURL == "" {
 		missing = append(missing, "coder URL")
 	}
-	if sessionToken == "" {
+	if sessionToken == "" && sessionTokenFile == "" {
 		missing = append(missing, "coder session token")
 	}
 	if len(missing) > 0 {
 		message := fmt.Sprintf(
-			"coder client provider is not configured: missing %s; configure --coder-url and --coder-session-token",
+			"coder client provider is not configured: missing %s; configure --coder-url and --coder-session-token (or --coder-session-token-file)",
 			strings.Join(missing, " and "),
 		)
 		if len(missing) == 2 {
@@ -121,6 +169,30 @@ func buildClientProvider(opts Options, requestTimeout time.Duration) (coder.Clie
 		return nil, fmt.Errorf("assertion failed: parsed coder URL must not be nil")
 	}
 
+	organizations := coder.ParseOrganizationList(strings.Join(opts.CoderOrganizations, ","))
+	if sessionTokenFile != "" {
+		transport, err := coder.NewTransport(coder.TransportConfig{})
+		if err != nil {
+			return nil, fmt.Errorf("build coder client transport: %w", err)
+		}
+		provider, err := coder.NewTokenFileClientProvider(
+			coder.Config{
+				CoderURL:       parsedCoderURL,
+				RequestTimeout: requestTimeout,
+				Transport:      transport,
+			},
+			sessionTokenFile,
+			coderNamespace,
+			opts.CoderSessionTokenReloadInterval,
+		)
+		if err != nil {
+			return nil, err
+		}
+		provider.Organizations = organizations
+
+		return provider, nil
+	}
+
 	provider, err := coder.NewStaticClientProvider(
 		coder.Config{
 			CoderURL:       parsedCoderURL,
@@ -135,10 +207,74 @@ func buildClientProvider(opts Options, requestTimeout time.Duration) (coder.Clie
 	if provider == nil {
 		return nil, fmt.Errorf("assertion failed: coder client provider is nil after successful construction")
 	}
+	provider.Organizations = organizations
 
 	return provider, nil
 }
 
+// buildNamespaceLabelClientProvider serves the namespaces selected by
+// --coder-namespace-selector, reading namespaces and admin token secrets from
+// the cluster the server runs in.
+func buildNamespaceLabelClientProvider(opts Options, requestTimeout time.Duration) (coder.ClientProvider, error) {
+	conflicting := make([]string, 0, 5)
+	if strings.TrimSpace(opts.CoderURL) != "" {
+		conflicting = append(conflicting, "--coder-url")
+	}
+	if strings.TrimSpace(opts.CoderSessionToken) != "" {
+		conflicting = append(conflicting, "--coder-session-token")
+	}
+	if strings.TrimSpace(opts.CoderSessionTokenFile) != "" {
+		conflicting = append(conflicting, "--coder-session-token-file")
+	}
+	if strings.TrimSpace(opts.CoderNamespace) != "" {
+		conflicting = append(conflicting, "--coder-namespace")
+	}
+	if len(opts.CoderOrganizations) > 0 {
+		conflicting = append(conflicting, "--coder-organizations")
+	}
+	if len(conflicting) > 0 {
+		return nil, fmt.Errorf(
+			"--coder-namespace-selector cannot be combined with %s",
+			strings.Join(conflicting, ", "),
+		)
+	}
+
+	kubeReader, err := newInClusterKubeReader()
+	if err != nil {
+		return nil, fmt.Errorf("build kubernetes client: %w", err)
+	}
+	if kubeReader == nil {
+		return nil, fmt.Errorf("assertion failed: kubernetes client is nil after successful construction")
+	}
+
+	provider, err := coder.NewNamespaceLabelClientProvider(
+		kubeReader,
+		kubeReader,
+		opts.CoderNamespaceSelector,
+		opts.CoderAdminTokenSecret,
+		requestTimeout,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	transport, err := coder.NewTransport(coder.TransportConfig{})
+	if err != nil {
+		return nil, fmt.Errorf("build coder client transport: %w", err)
+	}
+
+	return coder.NewCachingClientProvider(provider, transport)
+}
+
+func newInClusterKubeReader() (client.Reader, error) {
+	cfg, err := ctrl.GetConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	return client.New(cfg, client.Options{Scheme: clientgoscheme.Scheme})
+}
+
 // NewScheme builds the runtime scheme used by the aggregated API server.
 func NewScheme() *runtime.Scheme {
 	scheme := runtime.NewScheme()
@@ -163,6 +299,8 @@ func NewScheme() *runtime.Scheme {
 		&aggregationv1alpha1.CoderWorkspaceList{},
 		&aggregationv1alpha1.CoderTemplate{},
 		&aggregationv1alpha1.CoderTemplateList{},
+		&aggregationv1alpha1.CoderTemplateVersion{},
+		&aggregationv1alpha1.CoderTemplateVersionList{},
 	)
 
 	return scheme
@@ -223,6 +361,8 @@ func NewAPIGroupInfo(
 	scheme *runtime.Scheme,
 	codecs serializer.CodecFactory,
 	provider coder.ClientProvider,
+	templateSourceFetcher storage.TemplateSourceFetcher,
+	listFanOutMode storage.ListFanOutMode,
 ) (*genericapiserver.APIGroupInfo, error) {
 	if scheme == nil {
 		return nil, fmt.Errorf("assertion failed: scheme must not be nil")
@@ -238,9 +378,17 @@ func NewAPIGroupInfo(
 		parameterCodec,
 		codecs,
 	)
+	templateStorage := storage.NewTemplateStorage(provider)
+	templateStorage.SetGitSourceFetcher(templateSourceFetcher)
+	templateStorage.SetListFanOutMode(listFanOutMode)
+	workspaceStorage := storage.NewWorkspaceStorage(provider)
+	workspaceStorage.SetListFanOutMode(listFanOutMode)
 	apiGroupInfo.VersionedResourcesStorageMap[aggregationv1alpha1.SchemeGroupVersion.Version] = map[string]rest.Storage{
-		"coderworkspaces": storage.NewWorkspaceStorage(provider),
-		"codertemplates":  storage.NewTemplateStorage(provider),
+		"coderworkspaces":               workspaceStorage,
+		"codertemplates":                templateStorage,
+		"codertemplates/lint":           storage.NewTemplateLintStorage(templateStorage),
+		"codertemplateversions":         storage.NewTemplateVersionStorage(provider),
+		"codertemplateversions/promote": storage.NewTemplateVersionPromoteStorage(templateStorage),
 	}
 	return &apiGroupInfo, nil
 }
@@ -343,7 +491,25 @@ func RunWithOptions(ctx context.Context, opts Options) error {
 		return err
 	}
 
-	apiGroupInfo, err := NewAPIGroupInfo(scheme, codecs, provider)
+	// A nil interface, not a typed nil pointer, keeps spec.gitSource disabled.
+	var templateSourceFetcher storage.TemplateSourceFetcher
+	if opts.EnableTemplateGitSources {
+		gitFetcher, err := storage.NewGitTemplateSourceFetcher()
+		if err != nil {
+			return fmt.Errorf("enable template git sources: %w", err)
+		}
+		templateSourceFetcher = gitFetcher
+	}
+
+	listFanOutMode := opts.ListFanOutMode
+	if listFanOutMode == "" {
+		listFanOutMode, err = storage.ParseListFanOutMode(os.Getenv(ListFanOutModeEnv))
+		if err != nil {
+			return fmt.Errorf("parse %s: %w", ListFanOutModeEnv, err)
+		}
+	}
+
+	apiGroupInfo, err := NewAPIGroupInfo(scheme, codecs, provider, templateSourceFetcher, listFanOutMode)
 	if err != nil {
 		return fmt.Errorf("build API group info: %w", err)
 	}
@@ -359,6 +525,8 @@ func getOpenAPIDefinitions(_ openapicommon.ReferenceCallback) map[string]openapi
 	workspaceListDefinitionName := openapiutil.GetCanonicalTypeName(&aggregationv1alpha1.CoderWorkspaceList{})
 	templateDefinitionName := openapiutil.GetCanonicalTypeName(&aggregationv1alpha1.CoderTemplate{})
 	templateListDefinitionName := openapiutil.GetCanonicalTypeName(&aggregationv1alpha1.CoderTemplateList{})
+	templateVersionDefinitionName := openapiutil.GetCanonicalTypeName(&aggregationv1alpha1.CoderTemplateVersion{})
+	templateVersionListDefinitionName := openapiutil.GetCanonicalTypeName(&aggregationv1alpha1.CoderTemplateVersionList{})
 
 	groupVersionKindExtension := func(kind string) spec.VendorExtensible {
 		return spec.VendorExtensible{
@@ -376,6 +544,7 @@ func getOpenAPIDefinitions(_ openapicommon.ReferenceCallback) map[string]openapi
 
 	boolSchema := spec.Schema{SchemaProps: spec.SchemaProps{Type: []string{"boolean"}}}
 	dateTimeSchema := spec.Schema{SchemaProps: spec.SchemaProps{Type: []string{"string"}, Format: "date-time"}}
+	int32Schema := spec.Schema{SchemaProps: spec.SchemaProps{Type: []string{"integer"}, Format: "int32"}}
 	int64Schema := spec.Schema{SchemaProps: spec.SchemaProps{Type: []string{"integer"}, Format: "int64"}}
 	stringSchema := spec.Schema{SchemaProps: spec.SchemaProps{Type: []string{"string"}}}
 	objectMetaSchema := spec.Schema{SchemaProps: spec.SchemaProps{Type: []string{"object"}}}
@@ -395,6 +564,52 @@ func getOpenAPIDefinitions(_ openapicommon.ReferenceCallback) map[string]openapi
 		},
 	}
 
+	gitSourceSchema := spec.Schema{
+		SchemaProps: spec.SchemaProps{
+			Type:     []string{"object"},
+			Required: []string{"url"},
+			Properties: map[string]spec.Schema{
+				"url":    stringSchema,
+				"ref":    stringSchema,
+				"subdir": stringSchema,
+			},
+		},
+	}
+
+	autostopRequirementSchema := spec.Schema{
+		SchemaProps: spec.SchemaProps{
+			Type: []string{"object"},
+			Properties: map[string]spec.Schema{
+				"daysOfWeek": {SchemaProps: spec.SchemaProps{Type: []string{"array"}, Items: &spec.SchemaOrArray{Schema: &stringSchema}}},
+				"weeks":      int64Schema,
+			},
+		},
+	}
+	aclEntriesSchema := spec.Schema{
+		SchemaProps: spec.SchemaProps{
+			Type: []string{"array"},
+			Items: &spec.SchemaOrArray{Schema: &spec.Schema{
+				SchemaProps: spec.SchemaProps{
+					Type:     []string{"object"},
+					Required: []string{"name", "role"},
+					Properties: map[string]spec.Schema{
+						"name": stringSchema,
+						"role": {SchemaProps: spec.SchemaProps{Type: []string{"string"}, Enum: []interface{}{"admin", "use"}}},
+					},
+				},
+			}},
+		},
+	}
+	aclSchema := spec.Schema{
+		SchemaProps: spec.SchemaProps{
+			Type: []string{"object"},
+			Properties: map[string]spec.Schema{
+				"users":  aclEntriesSchema,
+				"groups": aclEntriesSchema,
+			},
+		},
+	}
+
 	workspaceSchema := spec.Schema{
 		VendorExtensible: groupVersionKindExtension("CoderWorkspace"),
 		SchemaProps: spec.SchemaProps{
@@ -407,12 +622,71 @@ func getOpenAPIDefinitions(_ openapicommon.ReferenceCallback) map[string]openapi
 					SchemaProps: spec.SchemaProps{
 						Type: []string{"object"},
 						Properties: map[string]spec.Schema{
-							"organization":      stringSchema,
-							"templateName":      stringSchema,
-							"templateVersionID": stringSchema,
-							"running":           boolSchema,
-							"ttlMillis":         int64Schema,
-							"autostartSchedule": stringSchema,
+							"organization":             stringSchema,
+							"templateName":             stringSchema,
+							"templateVersionID":        stringSchema,
+							"useActiveTemplateVersion": boolSchema,
+							"running":                  boolSchema,
+							"ttlMillis":                int64Schema,
+							"autostartSchedule":        stringSchema,
+						},
+					},
+				},
+				"status": {
+					SchemaProps: spec.SchemaProps{
+						Type: []string{"object"},
+						Properties: map[string]spec.Schema{
+							"id":                       stringSchema,
+							"ownerName":                stringSchema,
+							"organizationName":         stringSchema,
+							"templateName":             stringSchema,
+							"templateVersionID":        stringSchema,
+							"latestBuildID":            stringSchema,
+							"latestBuildStatus":        stringSchema,
+							"lastBuildStartedAt":       dateTimeSchema,
+							"lastBuildCompletedAt":     dateTimeSchema,
+							"lastBuildDurationSeconds": int64Schema,
+							"dailyCost":                int32Schema,
+							"health":                   stringSchema,
+							"agentStatus":              stringSchema,
+							"presetName":               stringSchema,
+							"autoShutdown":             dateTimeSchema,
+							"lastUsedAt":               dateTimeSchema,
+						},
+					},
+				},
+			},
+		},
+	}
+
+	templateSchema := spec.Schema{
+		VendorExtensible: groupVersionKindExtension("CoderTemplate"),
+		SchemaProps: spec.SchemaProps{
+			Type: []string{"object"},
+			Properties: map[string]spec.Schema{
+				"apiVersion": stringSchema,
+				"kind":       stringSchema,
+				"metadata":   objectMetaSchema,
+				"spec": {
+					SchemaProps: spec.SchemaProps{
+						Type: []string{"object"},
+						Properties: map[string]spec.Schema{
+							"organization":              stringSchema,
+							"versionID":                 stringSchema,
+							"displayName":               stringSchema,
+							"description":               stringSchema,
+							"icon":                      stringSchema,
+							"files":                     filesSchema,
+							"gitSource":                 gitSourceSchema,
+							"versionName":               stringSchema,
+							"dormancyThresholdMillis":   int64Schema,
+							"autoDeleteThresholdMillis": int64Schema,
+							"deprecationMessage":        stringSchema,
+							"defaultTTLMillis":          int64Schema,
+							"activityBumpMillis":        int64Schema,
+							"autostopRequirement":       autostopRequirementSchema,
+							"acl":                       aclSchema,
+							"running":                   boolSchema,
 						},
 					},
 				},
@@ -421,13 +695,13 @@ func getOpenAPIDefinitions(_ openapicommon.ReferenceCallback) map[string]openapi
 						Type: []string{"object"},
 						Properties: map[string]spec.Schema{
 							"id":                stringSchema,
-							"ownerName":         stringSchema,
 							"organizationName":  stringSchema,
-							"templateName":      stringSchema,
-							"latestBuildID":     stringSchema,
-							"latestBuildStatus": stringSchema,
+							"activeVersionID":   stringSchema,
+							"activeVersionName": stringSchema,
+							"sourceTreeHash":    stringSchema,
+							"deprecated":        boolSchema,
+							"updatedAt":         dateTimeSchema,
 							"autoShutdown":      dateTimeSchema,
-							"lastUsedAt":        dateTimeSchema,
 						},
 					},
 				},
@@ -435,8 +709,8 @@ func getOpenAPIDefinitions(_ openapicommon.ReferenceCallback) map[string]openapi
 		},
 	}
 
-	templateSchema := spec.Schema{
-		VendorExtensible: groupVersionKindExtension("CoderTemplate"),
+	templateVersionSchema := spec.Schema{
+		VendorExtensible: groupVersionKindExtension("CoderTemplateVersion"),
 		SchemaProps: spec.SchemaProps{
 			Type: []string{"object"},
 			Properties: map[string]spec.Schema{
@@ -448,12 +722,8 @@ func getOpenAPIDefinitions(_ openapicommon.ReferenceCallback) map[string]openapi
 						Type: []string{"object"},
 						Properties: map[string]spec.Schema{
 							"organization": stringSchema,
-							"versionID":    stringSchema,
-							"displayName":  stringSchema,
-							"description":  stringSchema,
-							"icon":         stringSchema,
-							"files":        filesSchema,
-							"running":      boolSchema,
+							"templateName": stringSchema,
+							"message":      stringSchema,
 						},
 					},
 				},
@@ -461,12 +731,12 @@ func getOpenAPIDefinitions(_ openapicommon.ReferenceCallback) map[string]openapi
 					SchemaProps: spec.SchemaProps{
 						Type: []string{"object"},
 						Properties: map[string]spec.Schema{
-							"id":               stringSchema,
-							"organizationName": stringSchema,
-							"activeVersionID":  stringSchema,
-							"deprecated":       boolSchema,
-							"updatedAt":        dateTimeSchema,
-							"autoShutdown":     dateTimeSchema,
+							"id":          stringSchema,
+							"versionName": stringSchema,
+							"templateID":  stringSchema,
+							"active":      boolSchema,
+							"jobStatus":   stringSchema,
+							"jobError":    stringSchema,
 						},
 					},
 				},
@@ -510,6 +780,24 @@ func getOpenAPIDefinitions(_ openapicommon.ReferenceCallback) map[string]openapi
 		},
 	}
 
+	templateVersionListSchema := spec.Schema{
+		VendorExtensible: groupVersionKindExtension("CoderTemplateVersionList"),
+		SchemaProps: spec.SchemaProps{
+			Type: []string{"object"},
+			Properties: map[string]spec.Schema{
+				"apiVersion": stringSchema,
+				"kind":       stringSchema,
+				"metadata":   listMetaSchema,
+				"items": {
+					SchemaProps: spec.SchemaProps{
+						Type:  []string{"array"},
+						Items: &spec.SchemaOrArray{Schema: &templateVersionSchema},
+					},
+				},
+			},
+		},
+	}
+
 	return map[string]openapicommon.OpenAPIDefinition{
 		workspaceDefinitionName: {
 			Schema: workspaceSchema,
@@ -523,5 +811,11 @@ func getOpenAPIDefinitions(_ openapicommon.ReferenceCallback) map[string]openapi
 		templateListDefinitionName: {
 			Schema: templateListSchema,
 		},
+		templateVersionDefinitionName: {
+			Schema: templateVersionSchema,
+		},
+		templateVersionListDefinitionName: {
+			Schema: templateVersionListSchema,
+		},
 	}
 }
@@ -2,10 +2,15 @@ package apiserverapp
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"net"
+	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
 	"time"
@@ -22,6 +27,7 @@ import (
 
 	aggregationv1alpha1 "github.com/coder/coder-k8s/api/aggregation/v1alpha1"
 	coderhelper "github.com/coder/coder-k8s/internal/aggregated/coder"
+	"github.com/coder/coder-k8s/internal/aggregated/storage"
 )
 
 func TestNewSchemeRegistersAggregationKinds(t *testing.T) {
@@ -241,7 +247,7 @@ func TestInstallAPIGroupRegistersDiscovery(t *testing.T) {
 		t.Fatalf("build static client provider: %v", err)
 	}
 
-	apiGroupInfo, err := NewAPIGroupInfo(scheme, codecs, provider)
+	apiGroupInfo, err := NewAPIGroupInfo(scheme, codecs, provider, nil, storage.ListFanOutModeStrict)
 	if err != nil {
 		t.Fatalf("build API group info: %v", err)
 	}
@@ -279,6 +285,42 @@ func TestInstallAPIGroupRegistersDiscovery(t *testing.T) {
 	if !found {
 		t.Fatalf("expected discovery registration for group %s", aggregationv1alpha1.SchemeGroupVersion.Group)
 	}
+
+	recorder := httptest.NewRecorder()
+	server.Handler.ServeHTTP(recorder, httptest.NewRequest("GET", "/apis/"+aggregationv1alpha1.SchemeGroupVersion.String(), nil))
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected resource discovery status 200, got %d: %s", recorder.Code, recorder.Body.String())
+	}
+	resourceList := &metav1.APIResourceList{}
+	if err := json.Unmarshal(recorder.Body.Bytes(), resourceList); err != nil {
+		t.Fatalf("decode resource discovery: %v", err)
+	}
+
+	wantShortNames := map[string]string{
+		"coderworkspaces":       "cws",
+		"codertemplates":        "ctpl",
+		"codertemplateversions": "ctplv",
+	}
+	for _, resource := range resourceList.APIResources {
+		wantShortName, ok := wantShortNames[resource.Name]
+		if !ok {
+			continue
+		}
+		delete(wantShortNames, resource.Name)
+		if !slices.Equal(resource.ShortNames, []string{wantShortName}) {
+			t.Fatalf("expected %s short names [%s], got %v", resource.Name, wantShortName, resource.ShortNames)
+		}
+		wantCategories := []string{"coder"}
+		if resource.Name == "codertemplateversions" {
+			wantCategories = nil
+		}
+		if !slices.Equal(resource.Categories, wantCategories) {
+			t.Fatalf("expected %s categories %v, got %v", resource.Name, wantCategories, resource.Categories)
+		}
+	}
+	if len(wantShortNames) > 0 {
+		t.Fatalf("expected discovery to list resources %v", wantShortNames)
+	}
 }
 
 func TestNewRecommendedConfigSetsExtendedRequestTimeout(t *testing.T) {
@@ -379,6 +421,22 @@ func TestBuildClientProviderRejectsPartialCoderConfig(t *testing.T) {
 	}
 }
 
+func TestBuildClientProviderRejectsNamespaceSelectorWithStaticCoderConfig(t *testing.T) {
+	t.Parallel()
+
+	_, err := buildClientProvider(Options{
+		CoderNamespaceSelector: "coder.com/control-plane=true",
+		CoderURL:               "https://coder.example.com",
+		CoderNamespace:         "control-plane",
+	}, 30*time.Second)
+	if err == nil {
+		t.Fatal("expected namespace selector combined with static coder config to fail")
+	}
+	if !strings.Contains(err.Error(), "cannot be combined with --coder-url, --coder-namespace") {
+		t.Fatalf("expected conflicting flags error, got %v", err)
+	}
+}
+
 func TestRunWithOptionsRejectsPartialCoderConfig(t *testing.T) {
 	t.Parallel()
 
@@ -550,3 +608,49 @@ func TestBuildClientProviderReturnsStaticProviderWithCoderConfig(t *testing.T) {
 		t.Fatalf("expected BadRequest for namespace outside provider scope, got %v", err)
 	}
 }
+
+func TestBuildClientProviderReturnsTokenFileProviderWithSessionTokenFile(t *testing.T) {
+	t.Parallel()
+
+	tokenPath := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(tokenPath, []byte("file-session-token\n"), 0o600); err != nil {
+		t.Fatalf("write token file: %v", err)
+	}
+
+	provider, err := buildClientProvider(Options{
+		CoderURL:              "https://coder.example.com",
+		CoderSessionTokenFile: tokenPath,
+		CoderNamespace:        "control-plane",
+	}, 30*time.Second)
+	if err != nil {
+		t.Fatalf("build client provider: %v", err)
+	}
+	if _, ok := provider.(*coderhelper.TokenFileClientProvider); !ok {
+		t.Fatalf("expected *coder.TokenFileClientProvider, got %T", provider)
+	}
+
+	sdkClient, err := provider.ClientForNamespace(context.Background(), "control-plane")
+	if err != nil {
+		t.Fatalf("resolve client for namespace: %v", err)
+	}
+	if got, want := sdkClient.SessionToken(), "file-session-token"; got != want {
+		t.Fatalf("expected session token %q, got %q", want, got)
+	}
+}
+
+func TestBuildClientProviderRejectsSessionTokenWithSessionTokenFile(t *testing.T) {
+	t.Parallel()
+
+	_, err := buildClientProvider(Options{
+		CoderURL:              "https://coder.example.com",
+		CoderSessionToken:     "test-session-token",
+		CoderSessionTokenFile: "/var/run/secrets/coder/token",
+		CoderNamespace:        "control-plane",
+	}, 30*time.Second)
+	if err == nil {
+		t.Fatal("expected --coder-session-token combined with --coder-session-token-file to fail")
+	}
+	if !strings.Contains(err.Error(), "cannot be combined with --coder-session-token-file") {
+		t.Fatalf("expected conflicting flags error, got %v", err)
+	}
+}
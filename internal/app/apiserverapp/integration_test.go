@@ -21,6 +21,7 @@ import (
 
 	aggregationv1alpha1 "github.com/coder/coder-k8s/api/aggregation/v1alpha1"
 	"github.com/coder/coder-k8s/internal/aggregated/coder"
+	"github.com/coder/coder-k8s/internal/aggregated/storage"
 	"github.com/coder/coder/v2/codersdk"
 )
 
@@ -93,7 +94,7 @@ func TestIntegrationAggregatedAPIServerBootstrapAndList(t *testing.T) {
 	}
 	defer server.Destroy()
 
-	apiGroupInfo, err := NewAPIGroupInfo(scheme, codecs, provider)
+	apiGroupInfo, err := NewAPIGroupInfo(scheme, codecs, provider, nil, storage.ListFanOutModeStrict)
 	if err != nil {
 		t.Fatalf("build API group info: %v", err)
 	}
@@ -9,12 +9,19 @@ import (
 	"strings"
 	"time"
 
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/rest"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
+	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
 
+	coderv1alpha1 "github.com/coder/coder-k8s/api/v1alpha1"
 	"github.com/coder/coder-k8s/internal/app/sharedscheme"
 	"github.com/coder/coder-k8s/internal/coderbootstrap"
 	"github.com/coder/coder-k8s/internal/controller"
@@ -24,6 +31,22 @@ const (
 	// HealthProbeBindAddress exposes /healthz and /readyz checks for kube probes.
 	HealthProbeBindAddress = ":8081"
 
+	// MetricsBindAddress serves Prometheus metrics, including the
+	// coder_k8s_controlplane_* reconcile and Coder API metrics.
+	MetricsBindAddress = ":8080"
+
+	// WebhookCertDirEnv names the environment variable that overrides
+	// DefaultWebhookCertDir, the directory containing tls.crt and tls.key for
+	// the conversion webhook Service.
+	WebhookCertDirEnv = "CODER_K8S_WEBHOOK_CERT_DIR"
+
+	// DefaultWebhookCertDir is where the conversion webhook reads its serving
+	// certificate when WebhookCertDirEnv is unset.
+	DefaultWebhookCertDir = "/tmp/k8s-webhook-server/serving-certs"
+
+	// WebhookPort is the port the conversion webhook server listens on.
+	WebhookPort = 9443
+
 	// leaderElectionID is the stable identity used for leader-election lease objects.
 	leaderElectionID = "coder-k8s-controller.coder.com"
 
@@ -38,6 +61,67 @@ const (
 
 var setupLog = ctrl.Log.WithName("setup")
 
+// Options configures the controller application mode.
+type Options struct {
+	// DefaultCoderImage is used when a CoderControlPlane, CoderProvisioner, or
+	// CoderWorkspaceProxy does not set an image. Default: ghcr.io/coder/coder:latest.
+	DefaultCoderImage string
+	// DefaultResources applies to the CoderControlPlane coder container when
+	// neither spec.resources nor spec.resourceProfile is set. Nil leaves it
+	// without requests or limits.
+	DefaultResources *corev1.ResourceRequirements
+	// EnvPolicy limits the env var names CoderControlPlane spec.extraEnv,
+	// spec.config, spec.envFrom, and spec.extraArgs flags may set. The zero
+	// value allows every name.
+	EnvPolicy controller.EnvPolicy
+	// ResyncPeriod sets how often the manager's informers resync, re-running
+	// every reconcile to correct drift. Zero keeps the controller-runtime
+	// default of about ten hours. Per-feature requeue intervals are unaffected.
+	ResyncPeriod time.Duration
+	// DisableDriftRequeue stops the periodic CoderControlPlane requeues that
+	// check the managed HTTPRoute and cross-namespace workspace RBAC for drift,
+	// relying on watches instead.
+	DisableDriftRequeue bool
+	// WatchNamespaces limits the CoderControlPlane, CoderProvisioner, and
+	// CoderWorkspaceProxy objects the manager caches and reconciles to these
+	// namespaces. Empty watches all namespaces. Objects the control planes
+	// manage, such as cross-namespace workspace RBAC, are still watched
+	// cluster-wide.
+	WatchNamespaces []string
+	// LicenseDuplicateUploadMaxAttempts bounds re-uploads of a license that
+	// coderd rejects as a duplicate but does not report installed. Zero keeps
+	// the controller default of 5.
+	LicenseDuplicateUploadMaxAttempts int
+	// LicenseDuplicateUploadBackoff is the first delay between those
+	// re-uploads; it doubles per attempt. Zero keeps the default of 10s.
+	LicenseDuplicateUploadBackoff time.Duration
+	// EnableConversionWebhook makes the manager serve the CoderControlPlane
+	// conversion webhook on WebhookPort. Set it when the CRD is installed
+	// with the Webhook conversion strategy; the default None strategy needs
+	// no webhook or serving certificate.
+	EnableConversionWebhook bool
+}
+
+// ParseNamespaceList splits a comma-separated --watch-namespaces value into
+// namespace names, dropping blanks and duplicates.
+func ParseNamespaceList(value string) []string {
+	var namespaces []string
+	seen := make(map[string]struct{})
+	for _, name := range strings.Split(value, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if _, ok := seen[name]; ok {
+			continue
+		}
+		seen[name] = struct{}{}
+		namespaces = append(namespaces, name)
+	}
+
+	return namespaces
+}
+
 // NewScheme builds the runtime scheme used by the controller application.
 func NewScheme() *runtime.Scheme {
 	return sharedscheme.New()
@@ -45,21 +129,57 @@ func NewScheme() *runtime.Scheme {
 
 // NewManager builds a controller-runtime manager for the controller application mode.
 func NewManager(cfg *rest.Config, scheme *runtime.Scheme) (manager.Manager, error) {
+	return NewManagerWithOptions(cfg, scheme, Options{})
+}
+
+// NewManagerWithOptions builds a controller-runtime manager for the controller
+// application mode using opts.
+func NewManagerWithOptions(cfg *rest.Config, scheme *runtime.Scheme, opts Options) (manager.Manager, error) {
 	if cfg == nil {
 		return nil, fmt.Errorf("assertion failed: config must not be nil")
 	}
 	if scheme == nil {
 		return nil, fmt.Errorf("assertion failed: scheme must not be nil")
 	}
+	if opts.ResyncPeriod < 0 {
+		return nil, fmt.Errorf("assertion failed: resync period must not be negative: %s", opts.ResyncPeriod)
+	}
 
-	mgr, err := ctrl.NewManager(cfg, ctrl.Options{
+	options := ctrl.Options{
 		Scheme:                        scheme,
 		HealthProbeBindAddress:        HealthProbeBindAddress,
+		Metrics:                       metricsserver.Options{BindAddress: MetricsBindAddress},
 		LeaderElection:                true,
 		LeaderElectionID:              leaderElectionID,
 		LeaderElectionNamespace:       detectLeaderElectionNamespace(),
 		LeaderElectionReleaseOnCancel: true,
-	})
+	}
+	if opts.ResyncPeriod > 0 {
+		resyncPeriod := opts.ResyncPeriod
+		options.Cache.SyncPeriod = &resyncPeriod
+	}
+	// Workspace RBAC lives in arbitrary namespaces, so Roles and RoleBindings
+	// are watched cluster-wide; only the ones the operator creates are cached.
+	options.Cache.ByObject = map[client.Object]cache.ByObject{
+		&rbacv1.Role{}:        {Label: controller.ManagedRBACCacheSelector()},
+		&rbacv1.RoleBinding{}: {Label: controller.ManagedRBACCacheSelector()},
+	}
+	if len(opts.WatchNamespaces) > 0 {
+		namespaces := make(map[string]cache.Config, len(opts.WatchNamespaces))
+		for _, namespace := range opts.WatchNamespaces {
+			namespaces[namespace] = cache.Config{}
+		}
+		// Provisioners and workspace proxies read the control plane in their
+		// own namespace, so they are limited to the same namespaces.
+		options.Cache.ByObject[&coderv1alpha1.CoderControlPlane{}] = cache.ByObject{Namespaces: namespaces}
+		options.Cache.ByObject[&coderv1alpha1.CoderProvisioner{}] = cache.ByObject{Namespaces: namespaces}
+		options.Cache.ByObject[&coderv1alpha1.CoderWorkspaceProxy{}] = cache.ByObject{Namespaces: namespaces}
+	}
+	if opts.EnableConversionWebhook {
+		options.WebhookServer = webhook.NewServer(webhook.Options{Port: WebhookPort, CertDir: webhookCertDir()})
+	}
+
+	mgr, err := ctrl.NewManager(cfg, options)
 	if err != nil {
 		return nil, fmt.Errorf("unable to start manager: %w", err)
 	}
@@ -72,6 +192,12 @@ func NewManager(cfg *rest.Config, scheme *runtime.Scheme) (manager.Manager, erro
 
 // SetupControllers registers all controller reconcilers on the manager.
 func SetupControllers(mgr manager.Manager) error {
+	return SetupControllersWithOptions(mgr, Options{})
+}
+
+// SetupControllersWithOptions registers all controller reconcilers on the
+// manager using opts.
+func SetupControllersWithOptions(mgr manager.Manager, opts Options) error {
 	if mgr == nil {
 		return fmt.Errorf("assertion failed: manager must not be nil")
 	}
@@ -86,6 +212,11 @@ func SetupControllers(mgr manager.Manager) error {
 		return fmt.Errorf("assertion failed: manager scheme is nil")
 	}
 
+	resourceProfiles, err := controller.LoadResourceProfilesFromEnv()
+	if err != nil {
+		return fmt.Errorf("load resource profiles: %w", err)
+	}
+
 	reconciler := &controller.CoderControlPlaneReconciler{
 		Client:                    client,
 		APIReader:                 mgr.GetAPIReader(),
@@ -93,6 +224,17 @@ func SetupControllers(mgr manager.Manager) error {
 		OperatorAccessProvisioner: coderbootstrap.NewPostgresOperatorAccessProvisioner(),
 		LicenseUploader:           controller.NewSDKLicenseUploader(),
 		EntitlementsInspector:     controller.NewSDKEntitlementsInspector(),
+		BuildInfoInspector:        controller.NewSDKBuildInfoInspector(),
+		ResourceProfiles:          resourceProfiles,
+		DefaultResources:          opts.DefaultResources,
+		EnvPolicy:                 opts.EnvPolicy,
+		Recorder:                  mgr.GetEventRecorder("codercontrolplane"),
+		DefaultImage:              opts.DefaultCoderImage,
+		DisableDriftRequeue:       opts.DisableDriftRequeue,
+		WatchNamespaces:           opts.WatchNamespaces,
+
+		LicenseDuplicateUploadMaxAttempts: opts.LicenseDuplicateUploadMaxAttempts,
+		LicenseDuplicateUploadBackoff:     opts.LicenseDuplicateUploadBackoff,
 	}
 	if err := reconciler.SetupWithManager(mgr); err != nil {
 		return fmt.Errorf("unable to create controller: %w", err)
@@ -102,6 +244,8 @@ func SetupControllers(mgr manager.Manager) error {
 		Client:          client,
 		Scheme:          managerScheme,
 		BootstrapClient: coderbootstrap.NewSDKClient(),
+		DefaultImage:    opts.DefaultCoderImage,
+		WatchNamespaces: opts.WatchNamespaces,
 	}
 	if err := coderWorkspaceProxyReconciler.SetupWithManager(mgr); err != nil {
 		return fmt.Errorf("unable to create coder workspace proxy controller: %w", err)
@@ -111,6 +255,8 @@ func SetupControllers(mgr manager.Manager) error {
 		Client:          client,
 		Scheme:          managerScheme,
 		BootstrapClient: coderbootstrap.NewSDKClient(),
+		DefaultImage:    opts.DefaultCoderImage,
+		WatchNamespaces: opts.WatchNamespaces,
 	}
 	if err := provisionerReconciler.SetupWithManager(mgr); err != nil {
 		return fmt.Errorf("unable to create provisioner controller: %w", err)
@@ -119,6 +265,29 @@ func SetupControllers(mgr manager.Manager) error {
 	return nil
 }
 
+// SetupWebhooks registers the CoderControlPlane conversion webhook, which the
+// CRD's Webhook conversion strategy calls to convert between served versions.
+func SetupWebhooks(mgr manager.Manager) error {
+	return SetupWebhooksWithOptions(mgr, Options{EnableConversionWebhook: true})
+}
+
+// SetupWebhooksWithOptions registers the CoderControlPlane conversion webhook
+// when opts.EnableConversionWebhook is set.
+func SetupWebhooksWithOptions(mgr manager.Manager, opts Options) error {
+	if mgr == nil {
+		return fmt.Errorf("assertion failed: manager must not be nil")
+	}
+	if !opts.EnableConversionWebhook {
+		return nil
+	}
+
+	if err := ctrl.NewWebhookManagedBy(mgr, &coderv1alpha1.CoderControlPlane{}).Complete(); err != nil {
+		return fmt.Errorf("unable to create codercontrolplane conversion webhook: %w", err)
+	}
+
+	return nil
+}
+
 // SetupProbes configures health and readiness checks on the manager.
 func SetupProbes(mgr manager.Manager) error {
 	if mgr == nil {
@@ -144,9 +313,15 @@ func SetupProbes(mgr manager.Manager) error {
 
 // +kubebuilder:rbac:groups=coordination.k8s.io,resources=leases,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+// +kubebuilder:rbac:groups=events.k8s.io,resources=events,verbs=create;patch
 
 // Run starts the controller-runtime manager for the controller application mode.
 func Run(ctx context.Context) error {
+	return RunWithOptions(ctx, Options{})
+}
+
+// RunWithOptions starts the controller application mode using opts.
+func RunWithOptions(ctx context.Context, opts Options) error {
 	if ctx == nil {
 		return fmt.Errorf("assertion failed: context must not be nil")
 	}
@@ -156,12 +331,15 @@ func Run(ctx context.Context) error {
 		return fmt.Errorf("assertion failed: scheme is nil after successful construction")
 	}
 
-	mgr, err := NewManager(ctrl.GetConfigOrDie(), scheme)
+	mgr, err := NewManagerWithOptions(ctrl.GetConfigOrDie(), scheme, opts)
 	if err != nil {
 		return err
 	}
 
-	if err := SetupControllers(mgr); err != nil {
+	if err := SetupControllersWithOptions(mgr, opts); err != nil {
+		return err
+	}
+	if err := SetupWebhooksWithOptions(mgr, opts); err != nil {
 		return err
 	}
 	if err := SetupProbes(mgr); err != nil {
@@ -175,6 +353,14 @@ func Run(ctx context.Context) error {
 	return nil
 }
 
+func webhookCertDir() string {
+	if certDir := strings.TrimSpace(os.Getenv(WebhookCertDirEnv)); certDir != "" {
+		return certDir
+	}
+
+	return DefaultWebhookCertDir
+}
+
 // detectLeaderElectionNamespace returns the namespace to use for leader-election
 // lease objects. Resolution order:
 //  1. POD_NAMESPACE env var (allows explicit override for any environment).
@@ -9,6 +9,7 @@ import (
 
 	aggregationv1alpha1 "github.com/coder/coder-k8s/api/aggregation/v1alpha1"
 	coderv1alpha1 "github.com/coder/coder-k8s/api/v1alpha1"
+	coderv1alpha2 "github.com/coder/coder-k8s/api/v1alpha2"
 )
 
 // New builds a runtime scheme with core Kubernetes, coder.com, and aggregation.coder.com APIs.
@@ -16,6 +17,7 @@ func New() *runtime.Scheme {
 	scheme := runtime.NewScheme()
 	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
 	utilruntime.Must(coderv1alpha1.AddToScheme(scheme))
+	utilruntime.Must(coderv1alpha2.AddToScheme(scheme))
 	utilruntime.Must(aggregationv1alpha1.AddToScheme(scheme))
 	utilruntime.Must(gatewayv1.Install(scheme))
 	return scheme
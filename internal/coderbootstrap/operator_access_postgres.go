@@ -11,9 +11,13 @@ import (
 	"time"
 
 	"github.com/google/uuid"
-	_ "github.com/lib/pq" // register PostgreSQL driver for database/sql
+	"github.com/lib/pq" // also registers the PostgreSQL driver for database/sql
 )
 
+// DefaultOperatorRoles are the site-wide roles granted to the operator user
+// when EnsureOperatorTokenRequest.OperatorRoles is empty.
+var DefaultOperatorRoles = []string{"owner"}
+
 const (
 	operatorTokenIDLength     = 10
 	operatorTokenSecretLength = 22
@@ -26,9 +30,12 @@ type EnsureOperatorTokenRequest struct {
 	PostgresURL      string
 	OperatorUsername string
 	OperatorEmail    string
-	TokenName        string
-	TokenLifetime    time.Duration
-	ExistingToken    string
+	// OperatorRoles are the site-wide roles granted to the operator user.
+	// DefaultOperatorRoles applies when empty.
+	OperatorRoles []string
+	TokenName     string
+	TokenLifetime time.Duration
+	ExistingToken string
 }
 
 // RevokeOperatorTokenRequest defines the input required to revoke the managed
@@ -132,12 +139,16 @@ func (p *PostgresOperatorAccessProvisioner) EnsureOperatorToken(ctx context.Cont
 		return "", fmt.Errorf("assertion failed: provisioner clock returned zero time")
 	}
 
-	userID, err := ensureOperatorUser(ctx, tx, now, req)
+	userID, managed, err := ensureOperatorUser(ctx, tx, now, req)
 	if err != nil {
 		return "", err
 	}
-	if err := ensureOperatorMemberships(ctx, tx, now, userID); err != nil {
-		return "", err
+	// An existing human account keeps its site and organization roles; only
+	// the system user the operator manages is granted roles.
+	if managed {
+		if err := ensureOperatorMemberships(ctx, tx, now, userID); err != nil {
+			return "", err
+		}
 	}
 
 	token, err := ensureOperatorToken(ctx, tx, now, userID, req)
@@ -227,10 +238,22 @@ func (r EnsureOperatorTokenRequest) validate() error {
 	if r.TokenLifetime <= 0 {
 		return fmt.Errorf("operator access token lifetime must be positive")
 	}
+	for _, role := range r.OperatorRoles {
+		if strings.TrimSpace(role) == "" {
+			return fmt.Errorf("operator access roles must not contain empty entries")
+		}
+	}
 
 	return nil
 }
 
+func (r EnsureOperatorTokenRequest) roles() []string {
+	if len(r.OperatorRoles) == 0 {
+		return DefaultOperatorRoles
+	}
+	return r.OperatorRoles
+}
+
 func (r RevokeOperatorTokenRequest) validate() error {
 	if strings.TrimSpace(r.PostgresURL) == "" {
 		return fmt.Errorf("operator access postgres URL is required")
@@ -245,13 +268,17 @@ func (r RevokeOperatorTokenRequest) validate() error {
 	return nil
 }
 
-func ensureOperatorUser(ctx context.Context, tx *sql.Tx, now time.Time, req EnsureOperatorTokenRequest) (uuid.UUID, error) {
+// ensureOperatorUser returns the operator user's ID, creating a system user
+// when none exists. It reports whether the user is a system user, whose
+// attributes and roles the operator enforces; an existing human account is
+// used as is.
+func ensureOperatorUser(ctx context.Context, tx *sql.Tx, now time.Time, req EnsureOperatorTokenRequest) (uuid.UUID, bool, error) {
 	if tx == nil {
-		return uuid.Nil, fmt.Errorf("assertion failed: transaction must not be nil")
+		return uuid.Nil, false, fmt.Errorf("assertion failed: transaction must not be nil")
 	}
 
 	const lookupOperatorUserQuery = `
-SELECT id
+SELECT id, is_system
 FROM users
 WHERE deleted = false
   AND lower(username) = lower($1)
@@ -259,7 +286,8 @@ LIMIT 1
 `
 
 	var userID uuid.UUID
-	err := tx.QueryRowContext(ctx, lookupOperatorUserQuery, req.OperatorUsername).Scan(&userID)
+	isSystem := true
+	err := tx.QueryRowContext(ctx, lookupOperatorUserQuery, req.OperatorUsername).Scan(&userID, &isSystem)
 	switch {
 	case err == nil:
 		// Existing user found.
@@ -287,7 +315,7 @@ VALUES (
 	$5,
 	$6,
 	$6,
-	ARRAY['owner'],
+	$7::text[],
 	'none'::login_type,
 	'active'::user_status,
 	true
@@ -302,15 +330,20 @@ VALUES (
 			req.OperatorUsername,
 			[]byte("none"),
 			now,
+			pq.Array(req.roles()),
 		); execErr != nil {
-			return uuid.Nil, fmt.Errorf("insert operator user %q: %w", req.OperatorUsername, execErr)
+			return uuid.Nil, false, fmt.Errorf("insert operator user %q: %w", req.OperatorUsername, execErr)
 		}
 	default:
-		return uuid.Nil, fmt.Errorf("query operator user %q: %w", req.OperatorUsername, err)
+		return uuid.Nil, false, fmt.Errorf("query operator user %q: %w", req.OperatorUsername, err)
 	}
 
 	if userID == uuid.Nil {
-		return uuid.Nil, fmt.Errorf("assertion failed: operator user ID must not be nil")
+		return uuid.Nil, false, fmt.Errorf("assertion failed: operator user ID must not be nil")
+	}
+
+	if !isSystem {
+		return userID, false, nil
 	}
 
 	const enforceOperatorUserQuery = `
@@ -320,7 +353,7 @@ SET
 	name = $3,
 	hashed_password = $4,
 	updated_at = $5,
-	rbac_roles = ARRAY['owner'],
+	rbac_roles = $6::text[],
 	login_type = 'none'::login_type,
 	status = 'active'::user_status,
 	is_system = true
@@ -334,20 +367,21 @@ WHERE id = $1
 		req.OperatorUsername,
 		[]byte("none"),
 		now,
+		pq.Array(req.roles()),
 	)
 	if err != nil {
-		return uuid.Nil, fmt.Errorf("enforce operator user %q attributes: %w", req.OperatorUsername, err)
+		return uuid.Nil, false, fmt.Errorf("enforce operator user %q attributes: %w", req.OperatorUsername, err)
 	}
 
 	rowsAffected, err := result.RowsAffected()
 	if err != nil {
-		return uuid.Nil, fmt.Errorf("get rows affected while enforcing operator user %q: %w", req.OperatorUsername, err)
+		return uuid.Nil, false, fmt.Errorf("get rows affected while enforcing operator user %q: %w", req.OperatorUsername, err)
 	}
 	if rowsAffected != 1 {
-		return uuid.Nil, fmt.Errorf("assertion failed: expected one operator user row updated, got %d", rowsAffected)
+		return uuid.Nil, false, fmt.Errorf("assertion failed: expected one operator user row updated, got %d", rowsAffected)
 	}
 
-	return userID, nil
+	return userID, true, nil
 }
 
 func ensureOperatorMemberships(ctx context.Context, tx *sql.Tx, now time.Time, userID uuid.UUID) error {
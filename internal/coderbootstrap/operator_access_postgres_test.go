@@ -25,6 +25,14 @@ func TestEnsureOperatorTokenRequestValidate(t *testing.T) {
 	if err := req.validate(); err != nil {
 		t.Fatalf("expected validate to pass for complete request, got %v", err)
 	}
+	if roles := req.roles(); len(roles) != 1 || roles[0] != "owner" {
+		t.Fatalf("expected default operator roles [owner], got %v", roles)
+	}
+
+	req.OperatorRoles = []string{"template-admin", " "}
+	if err := req.validate(); err == nil {
+		t.Fatal("expected validate to fail for an empty operator role")
+	}
 }
 
 func TestRevokeOperatorTokenRequestValidate(t *testing.T) {
@@ -0,0 +1,221 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"strconv"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	coderv1alpha1 "github.com/coder/coder-k8s/api/v1alpha1"
+)
+
+const (
+	backupCronJobSuffix    = "-backup"
+	defaultBackupSchedule  = "0 3 * * *"
+	defaultBackupImage     = "postgres:17"
+	defaultBackupRetention = int32(7)
+	backupVolumeName       = "backup"
+	backupMountPath        = "/backup"
+
+	backupRetentionEnvVar = "BACKUP_RETENTION"
+	backupS3URLEnvVar     = "BACKUP_S3_URL"
+
+	backupConfiguredReasonInvalidConfig = "InvalidConfig"
+)
+
+// backupPVCScript dumps the database into the mounted PVC and removes all but
+// the newest $BACKUP_RETENTION dumps.
+const backupPVCScript = `set -eu
+file="/backup/coder-$(date -u +%Y%m%dT%H%M%SZ).dump"
+pg_dump --format=custom --file="$file.partial" "$CODER_PG_CONNECTION_URL"
+mv "$file.partial" "$file"
+ls -1t /backup/coder-*.dump | tail -n +$((BACKUP_RETENTION + 1)) | xargs -r rm -f --
+`
+
+// backupS3Script uploads a dump to $BACKUP_S3_URL and removes all but the
+// newest $BACKUP_RETENTION dumps under that prefix.
+const backupS3Script = `set -eu
+prefix="${BACKUP_S3_URL%/}"
+name="coder-$(date -u +%Y%m%dT%H%M%SZ).dump"
+pg_dump --format=custom --file="/tmp/$name" "$CODER_PG_CONNECTION_URL"
+aws s3 cp "/tmp/$name" "$prefix/$name"
+rm -f "/tmp/$name"
+aws s3 ls "$prefix/" | awk '{print $4}' | grep '^coder-.*\.dump$' | sort -r | tail -n +$((BACKUP_RETENTION + 1)) |
+  while read -r old; do aws s3 rm "$prefix/$old"; done
+`
+
+func backupEnabled(coderControlPlane *coderv1alpha1.CoderControlPlane) bool {
+	return coderControlPlane != nil && coderControlPlane.Spec.Backup != nil && coderControlPlane.Spec.Backup.Enabled
+}
+
+func backupCronJobName(coderControlPlane *coderv1alpha1.CoderControlPlane) string {
+	return coderControlPlane.Name + backupCronJobSuffix
+}
+
+func backupLabels(name string) map[string]string {
+	return map[string]string{
+		"app.kubernetes.io/name":       "coder-backup",
+		"app.kubernetes.io/instance":   name,
+		"app.kubernetes.io/managed-by": "coder-k8s",
+	}
+}
+
+// reconcileBackupCronJob converges the pg_dump CronJob, or removes it when
+// backups are disabled. It returns a non-empty message instead of an error
+// when spec.backup cannot be applied, so a bad backup configuration does not
+// block the rest of the control plane; the existing CronJob is left as is.
+func (r *CoderControlPlaneReconciler) reconcileBackupCronJob(ctx context.Context, coderControlPlane *coderv1alpha1.CoderControlPlane) (string, error) {
+	if coderControlPlane == nil {
+		return "", fmt.Errorf("assertion failed: coder control plane must not be nil")
+	}
+
+	if !backupEnabled(coderControlPlane) {
+		return "", r.cleanupOwnedBackupCronJob(ctx, coderControlPlane)
+	}
+
+	backup := coderControlPlane.Spec.Backup
+	if backup.Destination == nil {
+		return "spec.backup.destination is required when backup is enabled", nil
+	}
+	pgEnvVar, err := postgresURLEnvVar(coderControlPlane)
+	if err != nil {
+		return fmt.Sprintf("resolve backup database: %v", err), nil
+	}
+
+	schedule := backup.Schedule
+	if schedule == "" {
+		schedule = defaultBackupSchedule
+	}
+	image := backup.Image
+	if image == "" {
+		image = defaultBackupImage
+	}
+	retention := defaultBackupRetention
+	if backup.Retention != nil {
+		retention = *backup.Retention
+	}
+
+	container := corev1.Container{
+		Name:    "backup",
+		Image:   image,
+		Command: []string{"/bin/sh", "-c"},
+		Env: []corev1.EnvVar{
+			pgEnvVar,
+			{Name: backupRetentionEnvVar, Value: strconv.Itoa(int(retention))},
+		},
+	}
+	var volumes []corev1.Volume
+	switch {
+	case backup.Destination.PersistentVolumeClaim != nil:
+		container.Args = []string{backupPVCScript}
+		container.VolumeMounts = []corev1.VolumeMount{{Name: backupVolumeName, MountPath: backupMountPath}}
+		volumes = []corev1.Volume{{
+			Name: backupVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+					ClaimName: backup.Destination.PersistentVolumeClaim.ClaimName,
+				},
+			},
+		}}
+	case backup.Destination.S3 != nil:
+		s3 := backup.Destination.S3
+		container.Args = []string{backupS3Script}
+		container.Env = append(container.Env, corev1.EnvVar{Name: backupS3URLEnvVar, Value: s3.URL})
+		container.Env = append(container.Env, s3.Env...)
+		container.EnvFrom = append([]corev1.EnvFromSource(nil), s3.EnvFrom...)
+	default:
+		return "spec.backup.destination must set persistentVolumeClaim or s3", nil
+	}
+
+	cronJob := &batchv1.CronJob{ObjectMeta: metav1.ObjectMeta{Name: backupCronJobName(coderControlPlane), Namespace: coderControlPlane.Namespace}}
+	_, err = controllerutil.CreateOrUpdate(ctx, r.Client, cronJob, func() error {
+		labels := backupLabels(coderControlPlane.Name)
+		cronJob.Labels = childLabels(coderControlPlane, labels)
+		applyCommonAnnotations(cronJob, coderControlPlane)
+
+		if err := controllerutil.SetControllerReference(coderControlPlane, cronJob, r.Scheme); err != nil {
+			return fmt.Errorf("set controller reference: %w", err)
+		}
+
+		cronJob.Spec.Schedule = schedule
+		cronJob.Spec.ConcurrencyPolicy = batchv1.ForbidConcurrent
+		cronJob.Spec.JobTemplate = batchv1.JobTemplateSpec{
+			ObjectMeta: metav1.ObjectMeta{Labels: maps.Clone(labels)},
+			Spec: batchv1.JobSpec{
+				Template: corev1.PodTemplateSpec{
+					ObjectMeta: metav1.ObjectMeta{Labels: maps.Clone(labels)},
+					Spec: corev1.PodSpec{
+						RestartPolicy:    corev1.RestartPolicyOnFailure,
+						ImagePullSecrets: coderControlPlane.Spec.ImagePullSecrets,
+						Containers:       []corev1.Container{container},
+						Volumes:          volumes,
+					},
+				},
+			},
+		}
+
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("reconcile backup cronjob: %w", err)
+	}
+
+	return "", nil
+}
+
+// setBackupConfiguredCondition sets BackupConfigured=False while spec.backup
+// cannot be applied and removes the condition otherwise.
+func setBackupConfiguredCondition(
+	nextStatus *coderv1alpha1.CoderControlPlaneStatus,
+	generation int64,
+	problem string,
+) error {
+	if nextStatus == nil {
+		return fmt.Errorf("assertion failed: next status must not be nil")
+	}
+
+	if problem == "" {
+		meta.RemoveStatusCondition(&nextStatus.Conditions, coderv1alpha1.CoderControlPlaneConditionBackupConfigured)
+		return nil
+	}
+
+	return setControlPlaneCondition(
+		nextStatus,
+		generation,
+		coderv1alpha1.CoderControlPlaneConditionBackupConfigured,
+		metav1.ConditionFalse,
+		backupConfiguredReasonInvalidConfig,
+		problem,
+	)
+}
+
+func (r *CoderControlPlaneReconciler) cleanupOwnedBackupCronJob(ctx context.Context, coderControlPlane *coderv1alpha1.CoderControlPlane) error {
+	cronJob := &batchv1.CronJob{}
+	namespacedName := types.NamespacedName{Name: backupCronJobName(coderControlPlane), Namespace: coderControlPlane.Namespace}
+	err := r.Get(ctx, namespacedName, cronJob)
+	switch {
+	case err == nil:
+	case apierrors.IsNotFound(err):
+		return nil
+	default:
+		return fmt.Errorf("get backup cronjob %s: %w", namespacedName, err)
+	}
+
+	if !isOwnedByCoderControlPlane(cronJob, coderControlPlane) {
+		return nil
+	}
+
+	if err := r.Delete(ctx, cronJob); err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("delete backup cronjob %s: %w", namespacedName, err)
+	}
+
+	return nil
+}
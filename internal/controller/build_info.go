@@ -0,0 +1,185 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/coder/coder/v2/codersdk"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	coderv1alpha1 "github.com/coder/coder-k8s/api/v1alpha1"
+)
+
+const (
+	versionDetectedReasonDetected    = "Detected"
+	versionDetectedReasonUnreachable = "CoderAPIUnreachable"
+
+	externalURLReachableReasonReachable   = "BuildInfoReported"
+	externalURLReachableReasonUnreachable = "CoderAPIUnreachable"
+)
+
+// BuildInfoInspector reads coderd build information. An empty sessionToken
+// queries build info without authentication.
+type BuildInfoInspector interface {
+	BuildInfo(ctx context.Context, coderURL, sessionToken string) (codersdk.BuildInfoResponse, error)
+}
+
+// NewSDKBuildInfoInspector returns a BuildInfoInspector backed by codersdk.
+func NewSDKBuildInfoInspector() BuildInfoInspector {
+	return &sdkBuildInfoInspector{}
+}
+
+type sdkBuildInfoInspector struct{}
+
+func (i *sdkBuildInfoInspector) BuildInfo(ctx context.Context, coderURL, sessionToken string) (codersdk.BuildInfoResponse, error) {
+	sdkClient, err := newUnauthenticatedSDKClient(coderURL)
+	if err != nil {
+		return codersdk.BuildInfoResponse{}, err
+	}
+	if sessionToken != "" {
+		sdkClient.SetSessionToken(sessionToken)
+	}
+
+	buildInfo, err := sdkClient.BuildInfo(ctx)
+	if err != nil {
+		return codersdk.BuildInfoResponse{}, fmt.Errorf("query coder build info: %w", err)
+	}
+
+	return buildInfo, nil
+}
+
+// reconcileCoderVersion records the running Coder version in
+// status.coderVersion once the control plane is ready. A failed query keeps
+// the last known version, sets VersionDetected to False, and requeues.
+func (r *CoderControlPlaneReconciler) reconcileCoderVersion(
+	ctx context.Context,
+	coderControlPlane *coderv1alpha1.CoderControlPlane,
+	nextStatus *coderv1alpha1.CoderControlPlaneStatus,
+) (ctrl.Result, error) {
+	if coderControlPlane == nil {
+		return ctrl.Result{}, fmt.Errorf("assertion failed: coder control plane must not be nil")
+	}
+	if nextStatus == nil {
+		return ctrl.Result{}, fmt.Errorf("assertion failed: next status must not be nil")
+	}
+
+	if r.BuildInfoInspector == nil ||
+		nextStatus.Phase != coderv1alpha1.CoderControlPlanePhaseReady ||
+		!nextStatus.OperatorAccessReady ||
+		nextStatus.OperatorTokenSecretRef == nil {
+		return ctrl.Result{}, nil
+	}
+	controlPlaneURL := controlPlaneSDKURL(coderControlPlane)
+	if strings.TrimSpace(controlPlaneURL) == "" {
+		return ctrl.Result{}, fmt.Errorf("assertion failed: control plane SDK URL must not be empty when querying build info")
+	}
+
+	operatorTokenSecretName := strings.TrimSpace(nextStatus.OperatorTokenSecretRef.Name)
+	if operatorTokenSecretName == "" {
+		return ctrl.Result{}, fmt.Errorf("assertion failed: operator token secret name must not be empty when querying build info")
+	}
+	operatorTokenSecretKey := strings.TrimSpace(nextStatus.OperatorTokenSecretRef.Key)
+	if operatorTokenSecretKey == "" {
+		operatorTokenSecretKey = coderv1alpha1.DefaultTokenSecretKey
+	}
+
+	operatorToken, err := r.readSecretValue(ctx, coderControlPlane.Namespace, operatorTokenSecretName, operatorTokenSecretKey)
+	if err != nil {
+		return ctrl.Result{RequeueAfter: operatorAccessRetryInterval}, nil
+	}
+
+	started := time.Now()
+	buildInfo, err := r.BuildInfoInspector.BuildInfo(ctx, controlPlaneURL, operatorToken)
+	observeCoderAPICall(coderControlPlane, coderAPIOperationBuildInfo, started, err)
+	version := strings.TrimSpace(buildInfo.Version)
+	if err == nil && version == "" {
+		err = fmt.Errorf("build info reported an empty version")
+	}
+	if err != nil {
+		if err := setControlPlaneCondition(
+			nextStatus,
+			coderControlPlane.Generation,
+			coderv1alpha1.CoderControlPlaneConditionVersionDetected,
+			metav1.ConditionFalse,
+			versionDetectedReasonUnreachable,
+			fmt.Sprintf("Failed to query build info: %v.", err),
+		); err != nil {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{RequeueAfter: operatorAccessRetryInterval}, nil
+	}
+
+	nextStatus.CoderVersion = version
+
+	return ctrl.Result{}, setControlPlaneCondition(
+		nextStatus,
+		coderControlPlane.Generation,
+		coderv1alpha1.CoderControlPlaneConditionVersionDetected,
+		metav1.ConditionTrue,
+		versionDetectedReasonDetected,
+		fmt.Sprintf("Coder %s is running.", version),
+	)
+}
+
+// reconcileExternalControlPlaneReadiness moves an externally managed control
+// plane from Pending to Ready once coderd answers a build info query at
+// spec.externalURL. The query needs no session token. A failed query sets
+// ExternalURLReachable to False and requeues.
+func (r *CoderControlPlaneReconciler) reconcileExternalControlPlaneReadiness(
+	ctx context.Context,
+	coderControlPlane *coderv1alpha1.CoderControlPlane,
+	nextStatus *coderv1alpha1.CoderControlPlaneStatus,
+) (ctrl.Result, error) {
+	if coderControlPlane == nil {
+		return ctrl.Result{}, fmt.Errorf("assertion failed: coder control plane must not be nil")
+	}
+	if nextStatus == nil {
+		return ctrl.Result{}, fmt.Errorf("assertion failed: next status must not be nil")
+	}
+
+	if controlPlaneDeploymentManaged(coderControlPlane) {
+		meta.RemoveStatusCondition(&nextStatus.Conditions, coderv1alpha1.CoderControlPlaneConditionExternalURLReachable)
+		return ctrl.Result{}, nil
+	}
+	externalURL := strings.TrimSpace(coderControlPlane.Spec.ExternalURL)
+	if externalURL == "" {
+		return ctrl.Result{}, fmt.Errorf("assertion failed: external URL must not be empty when the deployment is unmanaged")
+	}
+
+	var err error
+	if r.BuildInfoInspector == nil {
+		err = fmt.Errorf("no build info inspector is configured")
+	} else {
+		started := time.Now()
+		_, err = r.BuildInfoInspector.BuildInfo(ctx, externalURL, "")
+		observeCoderAPICall(coderControlPlane, coderAPIOperationBuildInfo, started, err)
+	}
+	if err != nil {
+		if err := setControlPlaneCondition(
+			nextStatus,
+			coderControlPlane.Generation,
+			coderv1alpha1.CoderControlPlaneConditionExternalURLReachable,
+			metav1.ConditionFalse,
+			externalURLReachableReasonUnreachable,
+			fmt.Sprintf("Failed to query build info at %s: %v.", externalURL, err),
+		); err != nil {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{RequeueAfter: operatorAccessRetryInterval}, nil
+	}
+
+	nextStatus.Phase = coderv1alpha1.CoderControlPlanePhaseReady
+
+	return ctrl.Result{}, setControlPlaneCondition(
+		nextStatus,
+		coderControlPlane.Generation,
+		coderv1alpha1.CoderControlPlaneConditionExternalURLReachable,
+		metav1.ConditionTrue,
+		externalURLReachableReasonReachable,
+		fmt.Sprintf("Coder answered at %s.", externalURL),
+	)
+}
@@ -0,0 +1,337 @@
+package controller
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"maps"
+	"net/url"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	coderv1alpha1 "github.com/coder/coder-k8s/api/v1alpha1"
+)
+
+const (
+	builtinPostgresSuffix      = "-postgres"
+	builtinPostgresPort        = int32(5432)
+	builtinPostgresUser        = "coder"
+	builtinPostgresDatabase    = "coder"
+	defaultBuiltinPostgresImg  = "postgres:17"
+	defaultBuiltinPostgresSize = "10Gi"
+	// builtinPostgresPasswordBytes is the number of random bytes hex-encoded
+	// into the generated password.
+	builtinPostgresPasswordBytes = 24
+
+	// #nosec G101 -- these are Secret data keys, not credentials.
+	builtinPostgresPasswordKey = "password"
+	builtinPostgresURLKey      = "url"
+)
+
+func builtinPostgresEnabled(coderControlPlane *coderv1alpha1.CoderControlPlane) bool {
+	return coderControlPlane != nil &&
+		coderControlPlane.Spec.Database != nil &&
+		coderControlPlane.Spec.Database.Builtin != nil &&
+		coderControlPlane.Spec.Database.Builtin.Enabled
+}
+
+// builtinPostgresName names the StatefulSet, Service, and credentials Secret
+// of the operator-managed PostgreSQL instance.
+func builtinPostgresName(coderControlPlane *coderv1alpha1.CoderControlPlane) string {
+	return coderControlPlane.Name + builtinPostgresSuffix
+}
+
+func builtinPostgresLabels(name string) map[string]string {
+	return map[string]string{
+		"app.kubernetes.io/name":       "coder-postgres",
+		"app.kubernetes.io/instance":   name,
+		"app.kubernetes.io/managed-by": "coder-k8s",
+	}
+}
+
+// builtinPostgresEnv returns the CODER_PG_CONNECTION_URL env var that points
+// coderd at the operator-managed PostgreSQL Secret.
+func builtinPostgresEnv(coderControlPlane *coderv1alpha1.CoderControlPlane) corev1.EnvVar {
+	return corev1.EnvVar{
+		Name: postgresConnectionURLEnvVar,
+		ValueFrom: &corev1.EnvVarSource{
+			SecretKeyRef: &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: builtinPostgresName(coderControlPlane)},
+				Key:                  builtinPostgresURLKey,
+			},
+		},
+	}
+}
+
+// reconcileBuiltinPostgres converges the operator-managed PostgreSQL instance,
+// or removes its StatefulSet and Service when it is disabled. The credentials
+// Secret and data volume are kept so re-enabling reuses the existing data.
+// Neither has an owner reference, so both also survive deleting the control
+// plane; recreating it adopts the existing Secret and its password. It reports
+// true, changing nothing, when a Secret the operator did not create for this
+// control plane holds the credentials Secret name.
+func (r *CoderControlPlaneReconciler) reconcileBuiltinPostgres(ctx context.Context, coderControlPlane *coderv1alpha1.CoderControlPlane) (bool, error) {
+	if coderControlPlane == nil {
+		return false, fmt.Errorf("assertion failed: coder control plane must not be nil")
+	}
+
+	if !builtinPostgresEnabled(coderControlPlane) {
+		return false, r.cleanupBuiltinPostgres(ctx, coderControlPlane)
+	}
+
+	conflict, err := r.reconcileBuiltinPostgresSecret(ctx, coderControlPlane)
+	if err != nil || conflict {
+		return conflict, err
+	}
+	if err := r.reconcileBuiltinPostgresService(ctx, coderControlPlane); err != nil {
+		return false, err
+	}
+
+	return false, r.reconcileBuiltinPostgresStatefulSet(ctx, coderControlPlane)
+}
+
+// builtinPostgresSecretManaged reports whether secret was created by the
+// operator for this control plane. The Secret carries no owner reference, so
+// its labels are what survive deleting and recreating the control plane.
+func builtinPostgresSecretManaged(secret *corev1.Secret, coderControlPlane *coderv1alpha1.CoderControlPlane) bool {
+	for key, value := range builtinPostgresLabels(coderControlPlane.Name) {
+		if secret.Labels[key] != value {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (r *CoderControlPlaneReconciler) reconcileBuiltinPostgresSecret(ctx context.Context, coderControlPlane *coderv1alpha1.CoderControlPlane) (bool, error) {
+	name := builtinPostgresName(coderControlPlane)
+	secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: coderControlPlane.Namespace}}
+	err := r.Get(ctx, client.ObjectKeyFromObject(secret), secret)
+	switch {
+	case err == nil:
+		if !builtinPostgresSecretManaged(secret, coderControlPlane) {
+			return true, nil
+		}
+	case apierrors.IsNotFound(err):
+	default:
+		return false, fmt.Errorf("get builtin postgres secret %s/%s: %w", secret.Namespace, name, err)
+	}
+
+	_, err = controllerutil.CreateOrUpdate(ctx, r.Client, secret, func() error {
+		secret.Labels = childLabels(coderControlPlane, builtinPostgresLabels(coderControlPlane.Name))
+		applyCommonAnnotations(secret, coderControlPlane)
+		secret.Type = corev1.SecretTypeOpaque
+		if secret.Data == nil {
+			secret.Data = make(map[string][]byte)
+		}
+
+		// Keep an existing password: the data volume was initialized with it.
+		password := string(secret.Data[builtinPostgresPasswordKey])
+		if password == "" {
+			randomBytes := make([]byte, builtinPostgresPasswordBytes)
+			if _, err := rand.Read(randomBytes); err != nil {
+				return fmt.Errorf("generate postgres password: %w", err)
+			}
+			password = hex.EncodeToString(randomBytes)
+			secret.Data[builtinPostgresPasswordKey] = []byte(password)
+		}
+
+		connectionURL := url.URL{
+			Scheme:   "postgres",
+			User:     url.UserPassword(builtinPostgresUser, password),
+			Host:     fmt.Sprintf("%s.%s.svc.cluster.local:%d", name, coderControlPlane.Namespace, builtinPostgresPort),
+			Path:     "/" + builtinPostgresDatabase,
+			RawQuery: "sslmode=disable",
+		}
+		secret.Data[builtinPostgresURLKey] = []byte(connectionURL.String())
+
+		// The data volume outlives the control plane, so the password it was
+		// initialized with must too: drop a controller reference rather than
+		// let garbage collection delete the Secret.
+		if isOwnedByCoderControlPlane(secret, coderControlPlane) {
+			if err := controllerutil.RemoveControllerReference(coderControlPlane, secret, r.Scheme); err != nil {
+				return fmt.Errorf("remove controller reference: %w", err)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return false, fmt.Errorf("reconcile builtin postgres secret %q: %w", name, err)
+	}
+
+	return false, nil
+}
+
+// reconcileBuiltinPostgresService converges the headless Service that governs
+// the StatefulSet and that coderd connects through.
+func (r *CoderControlPlaneReconciler) reconcileBuiltinPostgresService(ctx context.Context, coderControlPlane *coderv1alpha1.CoderControlPlane) error {
+	service := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: builtinPostgresName(coderControlPlane), Namespace: coderControlPlane.Namespace}}
+	err := r.Get(ctx, client.ObjectKeyFromObject(service), service)
+	switch {
+	case err == nil:
+		// spec.clusterIP is immutable, so a Service created with a cluster IP
+		// is replaced to become headless.
+		if service.Spec.ClusterIP != corev1.ClusterIPNone && isOwnedByCoderControlPlane(service, coderControlPlane) {
+			if err := r.Delete(ctx, service); err != nil && !apierrors.IsNotFound(err) {
+				return fmt.Errorf("delete builtin postgres service with a cluster IP: %w", err)
+			}
+			service = &corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: service.Name, Namespace: service.Namespace}}
+		}
+	case apierrors.IsNotFound(err):
+	default:
+		return fmt.Errorf("get builtin postgres service: %w", err)
+	}
+
+	_, err = controllerutil.CreateOrUpdate(ctx, r.Client, service, func() error {
+		labels := builtinPostgresLabels(coderControlPlane.Name)
+		service.Labels = childLabels(coderControlPlane, labels)
+		applyCommonAnnotations(service, coderControlPlane)
+
+		if err := controllerutil.SetControllerReference(coderControlPlane, service, r.Scheme); err != nil {
+			return fmt.Errorf("set controller reference: %w", err)
+		}
+
+		service.Spec.Type = corev1.ServiceTypeClusterIP
+		service.Spec.ClusterIP = corev1.ClusterIPNone
+		service.Spec.Selector = maps.Clone(labels)
+		service.Spec.Ports = []corev1.ServicePort{{
+			Name:       "postgres",
+			Port:       builtinPostgresPort,
+			TargetPort: intstr.FromString("postgres"),
+			Protocol:   corev1.ProtocolTCP,
+		}}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("reconcile builtin postgres service: %w", err)
+	}
+
+	return nil
+}
+
+func (r *CoderControlPlaneReconciler) reconcileBuiltinPostgresStatefulSet(ctx context.Context, coderControlPlane *coderv1alpha1.CoderControlPlane) error {
+	builtin := coderControlPlane.Spec.Database.Builtin
+	name := builtinPostgresName(coderControlPlane)
+
+	image := builtin.Image
+	if image == "" {
+		image = defaultBuiltinPostgresImg
+	}
+	storageSize := resource.MustParse(defaultBuiltinPostgresSize)
+	if builtin.StorageSize != nil {
+		storageSize = builtin.StorageSize.DeepCopy()
+	}
+
+	statefulSet := &appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: coderControlPlane.Namespace}}
+	_, err := controllerutil.CreateOrUpdate(ctx, r.Client, statefulSet, func() error {
+		labels := builtinPostgresLabels(coderControlPlane.Name)
+		statefulSet.Labels = childLabels(coderControlPlane, labels)
+		applyCommonAnnotations(statefulSet, coderControlPlane)
+
+		if err := controllerutil.SetControllerReference(coderControlPlane, statefulSet, r.Scheme); err != nil {
+			return fmt.Errorf("set controller reference: %w", err)
+		}
+
+		secretEnv := func(envName, key string) corev1.EnvVar {
+			return corev1.EnvVar{
+				Name: envName,
+				ValueFrom: &corev1.EnvVarSource{
+					SecretKeyRef: &corev1.SecretKeySelector{
+						LocalObjectReference: corev1.LocalObjectReference{Name: name},
+						Key:                  key,
+					},
+				},
+			}
+		}
+
+		replicas := int32(1)
+		statefulSet.Spec.Replicas = &replicas
+		statefulSet.Spec.ServiceName = name
+		statefulSet.Spec.Selector = &metav1.LabelSelector{MatchLabels: maps.Clone(labels)}
+		statefulSet.Spec.Template = corev1.PodTemplateSpec{
+			ObjectMeta: metav1.ObjectMeta{Labels: maps.Clone(labels)},
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{{
+					Name:  "postgres",
+					Image: image,
+					Env: []corev1.EnvVar{
+						{Name: "POSTGRES_USER", Value: builtinPostgresUser},
+						{Name: "POSTGRES_DB", Value: builtinPostgresDatabase},
+						secretEnv("POSTGRES_PASSWORD", builtinPostgresPasswordKey),
+						{Name: "PGDATA", Value: "/var/lib/postgresql/data/pgdata"},
+					},
+					Ports: []corev1.ContainerPort{{
+						Name:          "postgres",
+						ContainerPort: builtinPostgresPort,
+						Protocol:      corev1.ProtocolTCP,
+					}},
+					ReadinessProbe: &corev1.Probe{
+						ProbeHandler: corev1.ProbeHandler{
+							Exec: &corev1.ExecAction{Command: []string{"pg_isready", "-U", builtinPostgresUser, "-d", builtinPostgresDatabase}},
+						},
+						PeriodSeconds: 10,
+					},
+					VolumeMounts: []corev1.VolumeMount{{
+						Name:      "data",
+						MountPath: "/var/lib/postgresql/data",
+					}},
+				}},
+			},
+		}
+		// volumeClaimTemplates are immutable, so only set them on create.
+		if statefulSet.CreationTimestamp.IsZero() {
+			statefulSet.Spec.VolumeClaimTemplates = []corev1.PersistentVolumeClaim{{
+				ObjectMeta: metav1.ObjectMeta{Name: "data", Labels: maps.Clone(labels)},
+				Spec: corev1.PersistentVolumeClaimSpec{
+					AccessModes:      []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+					StorageClassName: builtin.StorageClassName,
+					Resources: corev1.VolumeResourceRequirements{
+						Requests: corev1.ResourceList{corev1.ResourceStorage: storageSize},
+					},
+				},
+			}}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("reconcile builtin postgres statefulset: %w", err)
+	}
+
+	return nil
+}
+
+// cleanupBuiltinPostgres deletes the owned PostgreSQL StatefulSet and Service.
+func (r *CoderControlPlaneReconciler) cleanupBuiltinPostgres(ctx context.Context, coderControlPlane *coderv1alpha1.CoderControlPlane) error {
+	namespacedName := types.NamespacedName{Name: builtinPostgresName(coderControlPlane), Namespace: coderControlPlane.Namespace}
+	for _, object := range []client.Object{&appsv1.StatefulSet{}, &corev1.Service{}} {
+		err := r.Get(ctx, namespacedName, object)
+		switch {
+		case err == nil:
+		case apierrors.IsNotFound(err):
+			continue
+		default:
+			return fmt.Errorf("get builtin postgres %T %s: %w", object, namespacedName, err)
+		}
+
+		if !isOwnedByCoderControlPlane(object, coderControlPlane) {
+			continue
+		}
+		if err := r.Delete(ctx, object); err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("delete builtin postgres %T %s: %w", object, namespacedName, err)
+		}
+	}
+
+	return nil
+}
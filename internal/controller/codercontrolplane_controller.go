@@ -9,13 +9,19 @@ import (
 	"fmt"
 	"hash/fnv"
 	"maps"
+	"math"
 	"net/http"
 	"net/url"
+	"path"
+	"slices"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/coder/coder/v2/codersdk"
 	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	networkingv1 "k8s.io/api/networking/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
@@ -23,15 +29,19 @@ import (
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/tools/events"
 	"k8s.io/client-go/util/retry"
 	ctrl "sigs.k8s.io/controller-runtime"
+	ctrlbuilder "sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
 
@@ -44,17 +54,42 @@ const (
 	defaultControlPlanePort   = int32(80)
 	controlPlaneTargetPort    = int32(8080)
 	controlPlaneTLSTargetPort = int32(8443)
+	// controlPlaneHTTPSServicePort is the Service port named https whenever
+	// built-in TLS is enabled.
+	controlPlaneHTTPSServicePort = int32(443)
+
+	// serviceAppProtocolAuto derives each service port's appProtocol from the
+	// listener it forwards to.
+	serviceAppProtocolAuto = "auto"
+
+	// tlsClientAuthNone disables client certificate requests.
+	tlsClientAuthNone = "none"
+	// tlsClientCASecretKey is the key of spec.tls.clientCASecretName that holds
+	// the client CA bundle.
+	tlsClientCASecretKey = "ca.crt"
 
 	postgresConnectionURLEnvVar = "CODER_PG_CONNECTION_URL"
 
 	defaultOperatorAccessUsername = "coder-k8s-operator"
-	defaultOperatorAccessEmail    = "coder-k8s-operator@coder-k8s.invalid"
+	operatorAccessEmailDomain     = "coder-k8s.invalid"
 	// #nosec G101 -- this is a static token label used as a database identifier.
 	defaultOperatorAccessTokenName     = "coder-k8s-operator"
 	defaultOperatorAccessTokenLifetime = 365 * 24 * time.Hour
 
 	operatorAccessRetryInterval = 30 * time.Second
 	operatorTokenSecretSuffix   = "-operator-token"
+	meshServiceSuffix           = "-mesh"
+	configMapSuffix             = "-config"
+
+	// Requeues while the Postgres URL Secret is missing start short and grow
+	// with the time spent waiting, up to the maximum.
+	postgresSecretRetryMinInterval = 5 * time.Second
+	postgresSecretRetryMaxInterval = 5 * time.Minute
+
+	// Requeues while the Coder API is unreachable for entitlements checks back
+	// off the same way, since the control plane is often still starting.
+	entitlementsRetryMinInterval = 5 * time.Second
+	entitlementsRetryMaxInterval = 5 * time.Minute
 
 	workspaceRBACFinalizer          = "coder.com/workspace-rbac-cleanup"
 	workspaceRBACOwnerUIDAnnotation = "coder.com/workspace-rbac-owner-uid"
@@ -65,6 +100,46 @@ const (
 	licenseSecretNameFieldIndex    = ".spec.licenseSecretRef.name"
 	envFromConfigMapNameFieldIndex = ".spec.envFrom.configMapRef.name"
 	envFromSecretNameFieldIndex    = ".spec.envFrom.secretRef.name" // #nosec G101 -- this is a field index key, not a credential.
+	mountedSecretNameFieldIndex    = ".spec.mountedSecretNames"     // #nosec G101 -- this is a field index key, not a credential.
+
+	// tlsChecksumAnnotation records a checksum of mounted TLS and CA cert Secret
+	// contents on the pod template so certificate rotation triggers a rollout.
+	tlsChecksumAnnotation = "coder.com/tls-checksum"
+	// configChecksumAnnotation records a checksum of spec.config on the pod
+	// template so changes to the managed ConfigMap trigger a rollout.
+	configChecksumAnnotation = "coder.com/config-checksum"
+
+	// operatorTokenNameAnnotation and operatorTokenCreatedAtAnnotation record
+	// the Coder API token stored in the operator token Secret and when the
+	// operator last wrote a new token value, for auditing.
+	operatorTokenNameAnnotation      = "coder.com/operator-token-name"
+	operatorTokenCreatedAtAnnotation = "coder.com/operator-token-created-at"
+	operatorTokenSecretComponent     = "operator-token"
+
+	// projectedCertsVolumeName and projectedCertsMountPath locate the
+	// consolidated CA bundle used when spec.certs.projected is set.
+	projectedCertsVolumeName = "ca-certs"
+	projectedCertsMountPath  = "/etc/ssl/coder-ca"
+
+	// projectedTokenVolumePrefix, projectedTokenMountDir, and
+	// projectedTokenDefaultPath name and place the volumes for
+	// spec.projectedTokens.
+	projectedTokenVolumePrefix = "token-"
+	projectedTokenMountDir     = "/var/run/secrets/tokens"
+	projectedTokenDefaultPath  = "token"
+	// projectedTokenDefaultExpirationSeconds matches the API server default,
+	// set explicitly so the pod template does not drift from it.
+	projectedTokenDefaultExpirationSeconds = int64(3600)
+
+	// hardenedUserID is the non-root user and group the coder container runs as
+	// when spec.hardened is set; it matches the coder user in the Coder image.
+	hardenedUserID = int64(1000)
+
+	configMapConflictReasonNotOwned = "ConfigMapNotOwned"
+	// builtinPostgresSecretConflictReasonNotOwned reports that the builtin
+	// PostgreSQL credentials Secret name is taken by a Secret the operator did
+	// not create.
+	builtinPostgresSecretConflictReasonNotOwned = "SecretNotOwned"
 
 	licenseConditionReasonApplied       = "Applied"
 	licenseConditionReasonPending       = "Pending"
@@ -72,27 +147,79 @@ const (
 	licenseConditionReasonForbidden     = "Forbidden"
 	licenseConditionReasonNotSupported  = "NotSupported"
 	licenseConditionReasonError         = "Error"
+	licenseConditionReasonStuck         = "Stuck"
+
+	operatorAccessConditionReasonProvisioned            = "Provisioned"
+	operatorAccessConditionReasonDisabled               = "Disabled"
+	operatorAccessConditionReasonRevokePending          = "RevokePending"
+	operatorAccessConditionReasonPostgresURLMissing     = "PostgresURLMissing"
+	operatorAccessConditionReasonPostgresSecretNotFound = "PostgresSecretNotFound"
+	operatorAccessConditionReasonPostgresURLInvalid     = "PostgresURLInvalid"
+	operatorAccessConditionReasonProvisionError         = "ProvisionError"
+
+	managedEnvOverriddenReasonExtraEnv   = "ExtraEnvOverridesManagedEnv"
+	managedArgsOverriddenReasonExtraArgs = "ExtraArgsOverridesManagedArgs"
+
+	gatewayControllerMissingReasonRouteNotAccepted = "RouteNotAccepted"
+
+	imagePinnedReasonMutableTag = "MutableTag"
+
+	servicePortPlaintextReasonHTTPSPortWithoutTLS = "HTTPSPortWithoutTLS"
+
+	serviceTargetPortValidReasonExposed    = "TargetPortExposed"
+	serviceTargetPortValidReasonNotExposed = "TargetPortNotExposed"
+
+	entitlementsUnknownReasonUnreachable     = "CoderAPIUnreachable"
+	entitlementsUnknownReasonRequestRejected = "RequestRejected"
+
+	eventReasonOperatorTokenProvisioned = "OperatorTokenProvisioned"
+	eventReasonOperatorTokenRotated     = "OperatorTokenRotated"
+	eventReasonLicenseApplied           = "LicenseApplied"
+	eventReasonLicenseNotSupported      = "LicenseNotSupported"
+	eventReasonEntitlementsChanged      = "EntitlementsChanged"
+	eventReasonGatewayCRDMissing        = "GatewayCRDMissing"
+	eventActionReconcile                = "Reconcile"
 
 	workspaceRBACDriftRequeueInterval = 2 * time.Minute
 	gatewayExposureRequeueInterval    = 2 * time.Minute
 	licenseUploadRequestTimeout       = 30 * time.Second
 	entitlementsStatusRefreshInterval = 2 * time.Minute
+
+	// defaultLicenseDuplicateUploadMaxAttempts and
+	// defaultLicenseDuplicateUploadBackoff bound re-uploads of a license that
+	// coderd rejects as a duplicate while not reporting it installed.
+	defaultLicenseDuplicateUploadMaxAttempts = 5
+	defaultLicenseDuplicateUploadBackoff     = 10 * time.Second
+	licenseDuplicateUploadMaxBackoff         = 5 * time.Minute
+
+	// gatewayRouteMaxUnacceptedReconciles bounds how many reconciles may observe
+	// an un-accepted HTTPRoute before the controller backs off and reports
+	// GatewayControllerMissing.
+	gatewayRouteMaxUnacceptedReconciles     = 5
+	gatewayControllerMissingRequeueInterval = 30 * time.Minute
 )
 
 var (
 	errSecretValueMissing = errors.New("secret value missing")
 	errSecretValueEmpty   = errors.New("secret value empty")
+
+	errPostgresURLNotConfigured = fmt.Errorf("%s is not configured", postgresConnectionURLEnvVar)
 )
 
 // LicenseUploader uploads and inspects Coder Enterprise licenses in a coderd instance.
 type LicenseUploader interface {
 	AddLicense(ctx context.Context, coderURL, sessionToken, licenseJWT string) error
 	HasAnyLicense(ctx context.Context, coderURL, sessionToken string) (bool, error)
+	// LicenseUUIDs returns the UUIDs of the licenses installed in coderd.
+	LicenseUUIDs(ctx context.Context, coderURL, sessionToken string) ([]string, error)
 }
 
 // EntitlementsInspector inspects coderd entitlements.
 type EntitlementsInspector interface {
 	Entitlements(ctx context.Context, coderURL, sessionToken string) (codersdk.Entitlements, error)
+	// ConnectedProvisionerDaemons returns how many external provisioner daemons
+	// are connected to coderd.
+	ConnectedProvisionerDaemons(ctx context.Context, coderURL, sessionToken string) (int, error)
 }
 
 // NewSDKEntitlementsInspector returns an EntitlementsInspector backed by codersdk.
@@ -119,6 +246,37 @@ func (i *sdkEntitlementsInspector) Entitlements(ctx context.Context, coderURL, s
 	return entitlements, nil
 }
 
+func (i *sdkEntitlementsInspector) ConnectedProvisionerDaemons(ctx context.Context, coderURL, sessionToken string) (int, error) {
+	sdkClient, err := newSDKLicenseClient(coderURL, sessionToken)
+	if err != nil {
+		return 0, err
+	}
+
+	daemons, err := sdkClient.ProvisionerDaemons(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("query coder provisioner daemons: %w", err)
+	}
+
+	return countConnectedExternalProvisionerDaemons(daemons), nil
+}
+
+// countConnectedExternalProvisionerDaemons counts daemons that are not built
+// into coderd and not reported offline.
+func countConnectedExternalProvisionerDaemons(daemons []codersdk.ProvisionerDaemon) int {
+	count := 0
+	for _, daemon := range daemons {
+		if daemon.KeyName != nil && *daemon.KeyName == codersdk.ProvisionerKeyNameBuiltIn {
+			continue
+		}
+		if daemon.Status != nil && *daemon.Status == codersdk.ProvisionerDaemonOffline {
+			continue
+		}
+		count++
+	}
+
+	return count
+}
+
 // NewSDKLicenseUploader returns a LicenseUploader backed by codersdk.
 func NewSDKLicenseUploader() LicenseUploader {
 	return &sdkLicenseUploader{}
@@ -157,21 +315,52 @@ func (u *sdkLicenseUploader) HasAnyLicense(ctx context.Context, coderURL, sessio
 	return len(licenses) > 0, nil
 }
 
-func newSDKLicenseClient(coderURL, sessionToken string) (*codersdk.Client, error) {
-	if strings.TrimSpace(coderURL) == "" {
-		return nil, fmt.Errorf("assertion failed: coder URL must not be empty")
+func (u *sdkLicenseUploader) LicenseUUIDs(ctx context.Context, coderURL, sessionToken string) ([]string, error) {
+	sdkClient, err := newSDKLicenseClient(coderURL, sessionToken)
+	if err != nil {
+		return nil, err
+	}
+
+	licenses, err := sdkClient.Licenses(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list coder licenses: %w", err)
 	}
+
+	uuids := make([]string, 0, len(licenses))
+	for _, license := range licenses {
+		uuids = append(uuids, license.UUID.String())
+	}
+
+	return uuids, nil
+}
+
+func newSDKLicenseClient(coderURL, sessionToken string) (*codersdk.Client, error) {
 	if sessionToken == "" {
 		return nil, fmt.Errorf("assertion failed: session token must not be empty")
 	}
 
+	sdkClient, err := newUnauthenticatedSDKClient(coderURL)
+	if err != nil {
+		return nil, err
+	}
+	sdkClient.SetSessionToken(sessionToken)
+
+	return sdkClient, nil
+}
+
+// newUnauthenticatedSDKClient returns a codersdk client without a session
+// token, for endpoints such as build info that do not require one.
+func newUnauthenticatedSDKClient(coderURL string) (*codersdk.Client, error) {
+	if strings.TrimSpace(coderURL) == "" {
+		return nil, fmt.Errorf("assertion failed: coder URL must not be empty")
+	}
+
 	parsedURL, err := url.Parse(coderURL)
 	if err != nil {
 		return nil, fmt.Errorf("parse coder URL: %w", err)
 	}
 
 	sdkClient := codersdk.New(parsedURL)
-	sdkClient.SetSessionToken(sessionToken)
 	if sdkClient.HTTPClient == nil {
 		sdkClient.HTTPClient = &http.Client{}
 	}
@@ -196,21 +385,103 @@ type CoderControlPlaneReconciler struct {
 	OperatorAccessProvisioner coderbootstrap.OperatorAccessProvisioner
 	LicenseUploader           LicenseUploader
 	EntitlementsInspector     EntitlementsInspector
+	BuildInfoInspector        BuildInfoInspector
+
+	// ResourceProfiles maps spec.resourceProfile names to container resources.
+	// When nil, DefaultResourceProfiles is used.
+	ResourceProfiles ResourceProfiles
+
+	// DefaultResources applies to the coder container when neither
+	// spec.resources nor spec.resourceProfile is set. When nil, the container
+	// runs without requests or limits.
+	DefaultResources *corev1.ResourceRequirements
+
+	// EnvPolicy limits the environment variable names spec.extraEnv,
+	// spec.config, spec.envFrom, and spec.extraArgs flags may set. The zero
+	// value allows every name.
+	EnvPolicy EnvPolicy
+
+	// Recorder emits Kubernetes Events for meaningful state transitions. When
+	// nil, no Events are recorded.
+	Recorder events.EventRecorder
+
+	// DefaultImage is used when spec.image is empty. When empty,
+	// ghcr.io/coder/coder:latest is used.
+	DefaultImage string
+
+	// DisableDriftRequeue stops the periodic requeues that re-check the managed
+	// HTTPRoute and cross-namespace workspace RBAC for drift. Those objects are
+	// then only corrected when a watch event arrives for them or for the control
+	// plane. Requeues that wait for the Gateway API CRDs to be installed are kept.
+	DisableDriftRequeue bool
+
+	// WatchNamespaces limits the control planes the reconciler acts on to
+	// these namespaces. Control planes in other namespaces are ignored, so no
+	// workloads or operator tokens are provisioned for them. When empty, every
+	// namespace is reconciled.
+	WatchNamespaces []string
+
+	// LicenseDuplicateUploadMaxAttempts bounds how many times a license that
+	// coderd rejects as a duplicate, yet does not report as installed, is
+	// uploaded again. After that the LicenseApplied condition reports Stuck and
+	// uploads stop until the license Secret changes. Default: 5.
+	LicenseDuplicateUploadMaxAttempts int
+	// LicenseDuplicateUploadBackoff is the delay before the first such
+	// re-upload. It doubles with each attempt, up to five minutes.
+	// Default: 10s.
+	LicenseDuplicateUploadBackoff time.Duration
+
+	gatewayRouteMu         sync.Mutex
+	gatewayRouteUnaccepted map[types.NamespacedName]gatewayRouteUnacceptedCount
+	gatewayCRDMissing      map[types.NamespacedName]struct{}
+
+	licenseDuplicateMu      sync.Mutex
+	licenseDuplicateUploads map[types.NamespacedName]licenseDuplicateUploadCount
+}
+
+// licenseDuplicateUploadCount tracks consecutive duplicate errors for a license
+// Secret value that coderd still reported as missing before the upload.
+type licenseDuplicateUploadCount struct {
+	hash        string
+	count       int
+	lastAttempt time.Time
+}
+
+// gatewayRouteUnacceptedCount tracks consecutive reconciles that observed a
+// managed HTTPRoute generation without an Accepted parent.
+type gatewayRouteUnacceptedCount struct {
+	generation int64
+	count      int
+}
+
+// gatewayExposureResult reports how the managed Gateway API exposure converged.
+type gatewayExposureResult struct {
+	requeueAfter      time.Duration
+	controllerMissing bool
+	// crdMissing is true while the Gateway API CRDs are not installed; the
+	// requeue then waits for them rather than checking for drift.
+	crdMissing bool
+	// crdMissingChanged is true on the first reconcile that finds the Gateway
+	// API CRDs missing after they were present or not yet observed.
+	crdMissingChanged bool
 }
 
 // +kubebuilder:rbac:groups=coder.com,resources=codercontrolplanes,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=coder.com,resources=codercontrolplanes/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=coder.com,resources=codercontrolplanes/finalizers,verbs=update
 // +kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch;create;update;patch;delete
-// +kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups="",resources=services,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups="",resources=serviceaccounts,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups="",resources=pods;persistentvolumeclaims,verbs=deletecollection
 // +kubebuilder:rbac:groups=apps,resources=deployments,verbs=deletecollection
+// +kubebuilder:rbac:groups=apps,resources=statefulsets,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;create;delete
+// +kubebuilder:rbac:groups=batch,resources=cronjobs,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=roles;rolebindings,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=networking.k8s.io,resources=ingresses,verbs=get;list;watch;create;update;patch;delete
-// +kubebuilder:rbac:groups=gateway.networking.k8s.io,resources=httproutes,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=gateway.networking.k8s.io,resources=httproutes;backendtlspolicies,verbs=get;list;watch;create;update;patch;delete
 
 // Reconcile converges the desired CoderControlPlane spec into Deployment and Service resources.
 func (r *CoderControlPlaneReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
@@ -221,9 +492,16 @@ func (r *CoderControlPlaneReconciler) Reconcile(ctx context.Context, req ctrl.Re
 		return ctrl.Result{}, fmt.Errorf("assertion failed: reconciler scheme must not be nil")
 	}
 
+	if !watchesNamespace(r.WatchNamespaces, req.Namespace) {
+		return ctrl.Result{}, nil
+	}
+
 	coderControlPlane := &coderv1alpha1.CoderControlPlane{}
 	if err := r.Get(ctx, req.NamespacedName, coderControlPlane); err != nil {
 		if apierrors.IsNotFound(err) {
+			r.forgetGatewayRouteUnaccepted(req.NamespacedName)
+			r.forgetLicenseDuplicateUploads(req.NamespacedName)
+			forgetControlPlaneMetrics(req.NamespacedName)
 			return ctrl.Result{}, nil
 		}
 		return ctrl.Result{}, fmt.Errorf("get codercontrolplane %s: %w", req.NamespacedName, err)
@@ -234,6 +512,19 @@ func (r *CoderControlPlaneReconciler) Reconcile(ctx context.Context, req ctrl.Re
 			coderControlPlane.Namespace, coderControlPlane.Name, req.Namespace, req.Name)
 	}
 
+	started := time.Now()
+	result, err := r.reconcileControlPlane(ctx, req, coderControlPlane)
+	observeControlPlaneReconcile(req.NamespacedName, started, err)
+
+	return result, err
+}
+
+// reconcileControlPlane converges a fetched CoderControlPlane.
+func (r *CoderControlPlaneReconciler) reconcileControlPlane(
+	ctx context.Context,
+	req ctrl.Request,
+	coderControlPlane *coderv1alpha1.CoderControlPlane,
+) (ctrl.Result, error) {
 	if !coderControlPlane.DeletionTimestamp.IsZero() {
 		return r.finalizeWorkspaceRBAC(ctx, coderControlPlane)
 	}
@@ -242,28 +533,133 @@ func (r *CoderControlPlaneReconciler) Reconcile(ctx context.Context, req ctrl.Re
 		return ctrl.Result{}, err
 	}
 
+	disallowedEnv, missingEnvFromSources, err := r.disallowedEnvVars(ctx, coderControlPlane)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if len(disallowedEnv) > 0 {
+		return ctrl.Result{}, r.rejectDisallowedEnv(ctx, coderControlPlane, disallowedEnv)
+	}
+
 	if err := r.reconcileServiceAccount(ctx, coderControlPlane); err != nil {
 		return ctrl.Result{}, err
 	}
 	if err := r.reconcileWorkspaceRBAC(ctx, coderControlPlane); err != nil {
 		return ctrl.Result{}, err
 	}
-
-	deployment, err := r.reconcileDeployment(ctx, coderControlPlane)
+	builtinPostgresConflict, err := r.reconcileBuiltinPostgres(ctx, coderControlPlane)
 	if err != nil {
 		return ctrl.Result{}, err
 	}
-	service, err := r.reconcileService(ctx, coderControlPlane)
+	if builtinPostgresConflict {
+		return ctrl.Result{}, r.rejectBuiltinPostgresSecretConflict(ctx, coderControlPlane)
+	}
+	configMapConflict, err := r.reconcileConfigMap(ctx, coderControlPlane)
 	if err != nil {
 		return ctrl.Result{}, err
 	}
-	gatewayExposureNeedsRequeue, err := r.reconcileExposure(ctx, coderControlPlane)
+	if configMapConflict {
+		return ctrl.Result{}, r.rejectConfigMapConflict(ctx, coderControlPlane)
+	}
+
+	var (
+		migration             migrationState
+		deployment            *appsv1.Deployment
+		overriddenManagedEnv  []string
+		overriddenManagedArgs []string
+		image                 string
+	)
+	if controlPlaneDeploymentManaged(coderControlPlane) {
+		migration, err = r.observeMigration(ctx, coderControlPlane)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		deployment, overriddenManagedEnv, err = r.reconcileDeployment(ctx, coderControlPlane, migration.pending())
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		_, overriddenManagedArgs = controlPlaneArgs(coderControlPlane)
+		image = coderImageOrDefault(coderControlPlane.Spec.Image, r.DefaultImage)
+		if err := r.reconcileMigrationJob(ctx, coderControlPlane, migration, deployment.Spec.Template); err != nil {
+			return ctrl.Result{}, err
+		}
+	} else if err := r.cleanupOwnedDeployment(ctx, coderControlPlane); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	var (
+		service         *corev1.Service
+		gatewayExposure gatewayExposureResult
+		targetPortErr   error
+	)
+	if controlPlaneDeploymentManaged(coderControlPlane) {
+		targetPortErr = validateServiceTargetPort(coderControlPlane)
+		if targetPortErr != nil {
+			// Leave the Service as it is rather than route traffic to a port
+			// coderd does not listen on; ServiceTargetPortValid reports why.
+			service = &corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: coderControlPlane.Name, Namespace: coderControlPlane.Namespace}}
+		} else {
+			service, err = r.reconcileService(ctx, coderControlPlane)
+			if err != nil {
+				return ctrl.Result{}, err
+			}
+		}
+		if err := r.reconcileMeshService(ctx, coderControlPlane); err != nil {
+			return ctrl.Result{}, err
+		}
+		gatewayExposure, err = r.reconcileExposure(ctx, coderControlPlane)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+	} else if err := r.cleanupUnmanagedNetworking(ctx, coderControlPlane); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	backupProblem, err := r.reconcileBackupCronJob(ctx, coderControlPlane)
 	if err != nil {
 		return ctrl.Result{}, err
 	}
 
 	originalStatus := *coderControlPlane.Status.DeepCopy()
 	nextStatus := r.desiredStatus(coderControlPlane, deployment, service)
+	meta.RemoveStatusCondition(&nextStatus.Conditions, coderv1alpha1.CoderControlPlaneConditionExtraEnvRejected)
+	meta.RemoveStatusCondition(&nextStatus.Conditions, coderv1alpha1.CoderControlPlaneConditionConfigMapConflict)
+	meta.RemoveStatusCondition(&nextStatus.Conditions, coderv1alpha1.CoderControlPlaneConditionBuiltinPostgresSecretConflict)
+	if err := setManagedEnvOverriddenCondition(&nextStatus, coderControlPlane.Generation, overriddenManagedEnv); err != nil {
+		return ctrl.Result{}, err
+	}
+	if err := setManagedArgsOverriddenCondition(&nextStatus, coderControlPlane.Generation, overriddenManagedArgs); err != nil {
+		return ctrl.Result{}, err
+	}
+	if err := setGatewayControllerMissingCondition(&nextStatus, coderControlPlane.Generation, gatewayExposure.controllerMissing); err != nil {
+		return ctrl.Result{}, err
+	}
+	if err := setImagePinnedCondition(&nextStatus, coderControlPlane.Generation, image); err != nil {
+		return ctrl.Result{}, err
+	}
+	if err := setServicePortPlaintextCondition(&nextStatus, coderControlPlane); err != nil {
+		return ctrl.Result{}, err
+	}
+	if err := setServiceTargetPortValidCondition(&nextStatus, coderControlPlane, targetPortErr); err != nil {
+		return ctrl.Result{}, err
+	}
+	if err := setDeploymentConditions(&nextStatus, coderControlPlane.Generation, deployment); err != nil {
+		return ctrl.Result{}, err
+	}
+	if err := setBackupConfiguredCondition(&nextStatus, coderControlPlane.Generation, backupProblem); err != nil {
+		return ctrl.Result{}, err
+	}
+	if err := setEnvFromSourceMissingCondition(&nextStatus, coderControlPlane.Generation, missingEnvFromSources); err != nil {
+		return ctrl.Result{}, err
+	}
+	if err := setMigrationStatus(&nextStatus, coderControlPlane.Generation, migration); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	externalResult, err := r.reconcileExternalControlPlaneReadiness(ctx, coderControlPlane, &nextStatus)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
 
 	operatorResult, err := r.reconcileOperatorAccess(ctx, coderControlPlane, &nextStatus)
 	if err != nil {
@@ -280,16 +676,23 @@ func (r *CoderControlPlaneReconciler) Reconcile(ctx context.Context, req ctrl.Re
 		return ctrl.Result{}, err
 	}
 
+	versionResult, err := r.reconcileCoderVersion(ctx, coderControlPlane, &nextStatus)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	gateOnRequiredLicense(coderControlPlane, &nextStatus)
+
 	if err := r.reconcileStatus(ctx, coderControlPlane, originalStatus, nextStatus); err != nil {
 		return ctrl.Result{}, err
 	}
+	r.recordTransitionEvents(coderControlPlane, originalStatus, nextStatus, gatewayExposure)
 
-	result := mergeResults(operatorResult, licenseResult, entitlementsResult)
-	if requiresWorkspaceRBACDriftRequeue(coderControlPlane) {
+	result := mergeResults(externalResult, operatorResult, licenseResult, entitlementsResult, versionResult)
+	if requiresWorkspaceRBACDriftRequeue(coderControlPlane) && !r.DisableDriftRequeue {
 		result = mergeResults(result, ctrl.Result{RequeueAfter: workspaceRBACDriftRequeueInterval})
 	}
-	if gatewayExposureNeedsRequeue {
-		result = mergeResults(result, ctrl.Result{RequeueAfter: gatewayExposureRequeueInterval})
+	if gatewayExposure.requeueAfter > 0 && (!r.DisableDriftRequeue || gatewayExposure.crdMissing) {
+		result = mergeResults(result, ctrl.Result{RequeueAfter: gatewayExposure.requeueAfter})
 	}
 
 	return result, nil
@@ -383,6 +786,12 @@ func boolOrDefault(explicit *bool, defaultValue bool) bool {
 	return *explicit
 }
 
+// controlPlaneDeploymentManaged reports whether the operator owns the control
+// plane Deployment rather than an externally managed coderd.
+func controlPlaneDeploymentManaged(cp *coderv1alpha1.CoderControlPlane) bool {
+	return boolOrDefault(cp.Spec.ManageDeployment, true)
+}
+
 func workspacePermsEnabled(explicit *bool) bool {
 	return boolOrDefault(explicit, true)
 }
@@ -398,127 +807,475 @@ func controlPlaneTLSEnabled(cp *coderv1alpha1.CoderControlPlane) bool {
 	return len(cp.Spec.TLS.SecretNames) > 0
 }
 
-func httpRouteBackendServicePort(coderControlPlane *coderv1alpha1.CoderControlPlane) (int32, error) {
+// controlPlaneTLSHeaderEnv returns the HTTP-to-HTTPS redirect and HSTS env
+// vars for spec.tls. Both settings require built-in TLS.
+func controlPlaneTLSHeaderEnv(coderControlPlane *coderv1alpha1.CoderControlPlane) ([]corev1.EnvVar, error) {
 	if coderControlPlane == nil {
-		return 0, fmt.Errorf("assertion failed: coder control plane must not be nil")
+		return nil, fmt.Errorf("assertion failed: coder control plane must not be nil")
 	}
 
-	servicePort := coderControlPlane.Spec.Service.Port
-	if servicePort == 0 {
-		servicePort = defaultControlPlanePort
+	tls := coderControlPlane.Spec.TLS
+	if tls.RedirectHTTP == nil && tls.HSTS == nil {
+		return nil, nil
 	}
-
-	if controlPlaneTLSEnabled(coderControlPlane) && servicePort == 443 {
-		return defaultControlPlanePort, nil
+	if !controlPlaneTLSEnabled(coderControlPlane) {
+		return nil, fmt.Errorf("spec.tls.redirectHTTP and spec.tls.hsts require spec.tls.secretNames to be set")
 	}
 
-	return servicePort, nil
-}
-
-func requiresWorkspaceRBACDriftRequeue(cp *coderv1alpha1.CoderControlPlane) bool {
-	if cp == nil || !workspacePermsEnabled(cp.Spec.RBAC.WorkspacePerms) {
-		return false
+	var env []corev1.EnvVar
+	if tls.RedirectHTTP != nil {
+		env = append(env, corev1.EnvVar{
+			Name:  "CODER_TLS_REDIRECT_HTTP_TO_HTTPS",
+			Value: strconv.FormatBool(*tls.RedirectHTTP),
+		})
 	}
+	if tls.HSTS != nil {
+		if tls.HSTS.MaxAge <= 0 {
+			return nil, fmt.Errorf("spec.tls.hsts.maxAge must be positive")
+		}
+		env = append(env, corev1.EnvVar{
+			Name:  "CODER_STRICT_TRANSPORT_SECURITY",
+			Value: strconv.FormatInt(tls.HSTS.MaxAge, 10),
+		})
 
-	for _, namespace := range cp.Spec.RBAC.WorkspaceNamespaces {
-		namespace = strings.TrimSpace(namespace)
-		if namespace == "" || namespace == cp.Namespace {
-			continue
+		var options []string
+		if tls.HSTS.IncludeSubdomains {
+			options = append(options, "includeSubDomains")
+		}
+		if tls.HSTS.Preload {
+			options = append(options, "preload")
+		}
+		if len(options) > 0 {
+			env = append(env, corev1.EnvVar{
+				Name:  "CODER_STRICT_TRANSPORT_SECURITY_OPTIONS",
+				Value: strings.Join(options, ","),
+			})
 		}
-		return true
 	}
 
-	return false
+	return env, nil
 }
 
-func workspaceRBACLabels(cp *coderv1alpha1.CoderControlPlane) map[string]string {
-	labels := maps.Clone(controlPlaneLabels(cp.Name))
-	labels["coder.com/control-plane"] = cp.Name
-	labels["coder.com/control-plane-namespace"] = cp.Namespace
-	return labels
-}
+// controlPlaneTLSClientAuth returns the client certificate verification env
+// vars for spec.tls, together with the volume and mount that expose the client
+// CA bundle. It returns no volume when no client CA Secret is configured.
+func controlPlaneTLSClientAuth(
+	coderControlPlane *coderv1alpha1.CoderControlPlane,
+) ([]corev1.EnvVar, *corev1.Volume, *corev1.VolumeMount, error) {
+	if coderControlPlane == nil {
+		return nil, nil, nil, fmt.Errorf("assertion failed: coder control plane must not be nil")
+	}
 
-func workspaceRBACAnnotations(ownerUID string) map[string]string {
-	return map[string]string{workspaceRBACOwnerUIDAnnotation: ownerUID}
-}
+	tls := coderControlPlane.Spec.TLS
+	clientAuth := strings.TrimSpace(tls.ClientAuth)
+	clientCASecretName := strings.TrimSpace(tls.ClientCASecretName)
+	if clientAuth == "" && clientCASecretName == "" {
+		return nil, nil, nil, nil
+	}
+	if !controlPlaneTLSEnabled(coderControlPlane) {
+		return nil, nil, nil, fmt.Errorf("spec.tls.clientAuth and spec.tls.clientCASecretName require spec.tls.secretNames to be set")
+	}
+	if clientAuth != "" && clientAuth != tlsClientAuthNone && clientCASecretName == "" {
+		return nil, nil, nil, fmt.Errorf("spec.tls.clientAuth %q requires spec.tls.clientCASecretName to be set", clientAuth)
+	}
 
-func hasWorkspaceRBACIdentityLabels(object metav1.Object, coderControlPlane *coderv1alpha1.CoderControlPlane) bool {
-	if object == nil || coderControlPlane == nil {
-		return false
+	var env []corev1.EnvVar
+	if clientAuth != "" {
+		env = append(env, corev1.EnvVar{Name: "CODER_TLS_CLIENT_AUTH", Value: clientAuth})
+	}
+	if clientCASecretName == "" {
+		return env, nil, nil, nil
 	}
 
-	labels := object.GetLabels()
-	if labels == nil {
-		return false
+	volumeName := volumeNameForSecret("tls-client-ca", clientCASecretName)
+	mountPath := fmt.Sprintf("/etc/ssl/certs/coder-client-ca/%s", clientCASecretName)
+	env = append(env, corev1.EnvVar{
+		Name:  "CODER_TLS_CLIENT_CA_FILE",
+		Value: fmt.Sprintf("%s/%s", mountPath, tlsClientCASecretKey),
+	})
+	volume := &corev1.Volume{
+		Name: volumeName,
+		VolumeSource: corev1.VolumeSource{
+			Secret: &corev1.SecretVolumeSource{SecretName: clientCASecretName},
+		},
+	}
+	volumeMount := &corev1.VolumeMount{
+		Name:      volumeName,
+		MountPath: mountPath,
+		ReadOnly:  true,
 	}
 
-	return labels["coder.com/control-plane"] == coderControlPlane.Name &&
-		labels["coder.com/control-plane-namespace"] == coderControlPlane.Namespace
+	return env, volume, volumeMount, nil
 }
 
-func hasWorkspaceRBACOwnerUID(object metav1.Object, coderControlPlane *coderv1alpha1.CoderControlPlane) bool {
-	if object == nil || coderControlPlane == nil {
-		return false
+// controlPlaneSecurityEnv returns the CODER_SECURE_AUTH_COOKIE and proxy trust
+// env vars for spec.security. Secure cookies default on when the control plane
+// is reached over TLS, either built-in, at the Ingress, or via a trusted proxy
+// that forwards X-Forwarded-Proto. Otherwise CODER_SECURE_AUTH_COOKIE is only
+// set when spec.security.secureAuthCookie is.
+func controlPlaneSecurityEnv(coderControlPlane *coderv1alpha1.CoderControlPlane) ([]corev1.EnvVar, error) {
+	if coderControlPlane == nil {
+		return nil, fmt.Errorf("assertion failed: coder control plane must not be nil")
 	}
 
-	ownerUID := strings.TrimSpace(string(coderControlPlane.UID))
-	if ownerUID == "" {
-		return false
+	security := coderControlPlane.Spec.Security
+	if security == nil {
+		security = &coderv1alpha1.SecuritySpec{}
 	}
 
-	annotations := object.GetAnnotations()
-	if annotations == nil {
-		return false
+	trustedHeaders := trimmedNonEmpty(security.ProxyTrustedHeaders)
+	trustedOrigins := trimmedNonEmpty(security.ProxyTrustedOrigins)
+	if len(trustedHeaders) > 0 && len(trustedOrigins) == 0 {
+		return nil, fmt.Errorf("spec.security.proxyTrustedOrigins must be set when spec.security.proxyTrustedHeaders is set")
 	}
-
-	return strings.TrimSpace(annotations[workspaceRBACOwnerUIDAnnotation]) == ownerUID
-}
-
-func isManagedWorkspaceRole(
-	role *rbacv1.Role,
-	coderControlPlane *coderv1alpha1.CoderControlPlane,
-	expectedRoleName string,
-) bool {
-	if role == nil || coderControlPlane == nil {
-		return false
+	if len(trustedOrigins) > 0 && len(trustedHeaders) == 0 {
+		return nil, fmt.Errorf("spec.security.proxyTrustedHeaders must be set when spec.security.proxyTrustedOrigins is set")
 	}
-	if isOwnedByCoderControlPlane(role, coderControlPlane) {
-		return true
+
+	secureByDefault := controlPlaneTLSEnabled(coderControlPlane) || controlPlaneIngressTLSEnabled(coderControlPlane)
+	if slices.ContainsFunc(trustedHeaders, func(header string) bool {
+		return strings.EqualFold(header, "X-Forwarded-Proto")
+	}) {
+		secureByDefault = true
 	}
-	if !hasWorkspaceRBACIdentityLabels(role, coderControlPlane) {
-		return false
+
+	// Coder already defaults to insecure cookies, so only set the variable
+	// when it changes something. This keeps Deployments without TLS or an
+	// explicit setting unchanged and lets spec.envFrom provide the value.
+	var env []corev1.EnvVar
+	if security.SecureAuthCookie != nil || secureByDefault {
+		env = append(env, corev1.EnvVar{
+			Name:  "CODER_SECURE_AUTH_COOKIE",
+			Value: strconv.FormatBool(boolOrDefault(security.SecureAuthCookie, secureByDefault)),
+		})
 	}
-	if hasWorkspaceRBACOwnerUID(role, coderControlPlane) {
-		return true
+	if len(trustedHeaders) > 0 {
+		env = append(env,
+			corev1.EnvVar{Name: "CODER_PROXY_TRUSTED_HEADERS", Value: strings.Join(trustedHeaders, ",")},
+			corev1.EnvVar{Name: "CODER_PROXY_TRUSTED_ORIGINS", Value: strings.Join(trustedOrigins, ",")},
+		)
 	}
 
-	return role.Namespace != coderControlPlane.Namespace && role.Name == expectedRoleName
+	return env, nil
 }
 
-func isManagedWorkspaceRoleBinding(
-	roleBinding *rbacv1.RoleBinding,
-	coderControlPlane *coderv1alpha1.CoderControlPlane,
-	expectedRoleName string,
-	expectedRoleBindingName string,
-	expectedServiceAccountName string,
-) bool {
-	if roleBinding == nil || coderControlPlane == nil {
+func controlPlaneIngressTLSEnabled(cp *coderv1alpha1.CoderControlPlane) bool {
+	if cp == nil || cp.Spec.Expose == nil || cp.Spec.Expose.Ingress == nil || cp.Spec.Expose.Ingress.TLS == nil {
 		return false
 	}
-	if isOwnedByCoderControlPlane(roleBinding, coderControlPlane) {
-		return true
-	}
-	if !hasWorkspaceRBACIdentityLabels(roleBinding, coderControlPlane) {
-		return false
+	tls := cp.Spec.Expose.Ingress.TLS
+	return strings.TrimSpace(tls.SecretName) != "" || strings.TrimSpace(tls.WildcardSecretName) != ""
+}
+
+// controlPlaneExposedURL returns the public URL of the Ingress or Gateway host
+// in spec.expose, or an empty string when exposure is not configured. Ingress
+// hosts use https only when Ingress TLS is configured. Gateway listeners are
+// not visible from the HTTPRoute, so Gateway hosts are assumed to terminate
+// TLS.
+func controlPlaneExposedURL(cp *coderv1alpha1.CoderControlPlane) string {
+	if cp == nil || cp.Spec.Expose == nil {
+		return ""
 	}
-	if hasWorkspaceRBACOwnerUID(roleBinding, coderControlPlane) {
-		return true
+
+	switch {
+	case cp.Spec.Expose.Ingress != nil:
+		host := strings.TrimSpace(cp.Spec.Expose.Ingress.Host)
+		if host == "" {
+			return ""
+		}
+		if controlPlaneIngressTLSEnabled(cp) {
+			return "https://" + host
+		}
+		return "http://" + host
+	case cp.Spec.Expose.Gateway != nil:
+		host := strings.TrimSpace(cp.Spec.Expose.Gateway.Host)
+		if host == "" {
+			return ""
+		}
+		return "https://" + host
+	default:
+		return ""
 	}
-	if roleBinding.Namespace == coderControlPlane.Namespace {
-		return false
+}
+
+func trimmedNonEmpty(values []string) []string {
+	trimmed := make([]string, 0, len(values))
+	for _, value := range values {
+		if value = strings.TrimSpace(value); value != "" {
+			trimmed = append(trimmed, value)
+		}
 	}
-	if roleBinding.Name != expectedRoleBindingName {
-		return false
+	return trimmed
+}
+
+// tlsSecretEntry is a parsed spec.tls.secretNames entry.
+type tlsSecretEntry struct {
+	secretName string
+	port       int32
+}
+
+// parseTLSSecretEntries parses spec.tls.secretNames entries of the form
+// "<secret-name>" or "<secret-name>:<port>". Entries without a port use
+// controlPlaneTLSTargetPort. Duplicate secret names are mounted once, while
+// every distinct port still produces a listener.
+func parseTLSSecretEntries(secretNames []string) ([]tlsSecretEntry, error) {
+	entries := make([]tlsSecretEntry, 0, len(secretNames))
+	for _, rawEntry := range secretNames {
+		rawEntry = strings.TrimSpace(rawEntry)
+		secretName, rawPort, hasPort := strings.Cut(rawEntry, ":")
+		secretName = strings.TrimSpace(secretName)
+		if secretName == "" {
+			return nil, fmt.Errorf("assertion failed: tls secret name must not be empty")
+		}
+
+		port := controlPlaneTLSTargetPort
+		if hasPort {
+			parsedPort, err := strconv.ParseInt(strings.TrimSpace(rawPort), 10, 32)
+			if err != nil || parsedPort < 1 || parsedPort > 65535 {
+				return nil, fmt.Errorf("invalid tls secret entry %q: port must be between 1 and 65535", rawEntry)
+			}
+			port = int32(parsedPort)
+		}
+		if port == controlPlaneTargetPort {
+			return nil, fmt.Errorf("invalid tls secret entry %q: port %d is reserved for HTTP", rawEntry, controlPlaneTargetPort)
+		}
+
+		entries = append(entries, tlsSecretEntry{secretName: secretName, port: port})
+	}
+
+	return entries, nil
+}
+
+// controlPlaneTLSListenerPorts returns the sorted, distinct Service ports that
+// entries with a port add in front of the controlPlaneTLSTargetPort listener.
+func controlPlaneTLSListenerPorts(entries []tlsSecretEntry) []int32 {
+	ports := make([]int32, 0, len(entries))
+	for _, entry := range entries {
+		if entry.port == controlPlaneTLSTargetPort || slices.Contains(ports, entry.port) {
+			continue
+		}
+		ports = append(ports, entry.port)
+	}
+	slices.Sort(ports)
+	return ports
+}
+
+// appendExtraContainerPorts appends spec.extraPorts to the managed container
+// ports, rejecting entries whose name or port a managed port already uses.
+func appendExtraContainerPorts(ports, extraPorts []corev1.ContainerPort) ([]corev1.ContainerPort, error) {
+	managedCount := len(ports)
+	for _, extraPort := range extraPorts {
+		for _, existing := range ports[:managedCount] {
+			if extraPort.Name != "" && extraPort.Name == existing.Name {
+				return nil, fmt.Errorf("extra port name %q conflicts with a managed container port", extraPort.Name)
+			}
+			if extraPort.ContainerPort == existing.ContainerPort {
+				return nil, fmt.Errorf("extra port %d conflicts with managed container port %q", extraPort.ContainerPort, existing.Name)
+			}
+		}
+		ports = append(ports, *extraPort.DeepCopy())
+	}
+
+	return ports, nil
+}
+
+// validateServiceTargetPort checks that spec.service.targetPort, when set,
+// names or numbers a port the coder container exposes.
+func validateServiceTargetPort(coderControlPlane *coderv1alpha1.CoderControlPlane) error {
+	if coderControlPlane.Spec.Service.TargetPort == nil {
+		return nil
+	}
+	targetPort := *coderControlPlane.Spec.Service.TargetPort
+
+	ports := []corev1.ContainerPort{{Name: "http", ContainerPort: controlPlaneTargetPort}}
+	if controlPlaneTLSEnabled(coderControlPlane) {
+		ports = append(ports, corev1.ContainerPort{Name: "https", ContainerPort: controlPlaneTLSTargetPort})
+	}
+	ports = append(ports, coderControlPlane.Spec.ExtraPorts...)
+
+	for _, port := range ports {
+		if targetPort.Type == intstr.String && port.Name != "" && port.Name == targetPort.StrVal {
+			return nil
+		}
+		if targetPort.Type == intstr.Int && port.ContainerPort == targetPort.IntVal {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("spec.service.targetPort %q does not match a port exposed by the coder container", targetPort.String())
+}
+
+func tlsListenerPortName(port int32) string {
+	return fmt.Sprintf("https-%d", port)
+}
+
+// controlPlaneServiceAppProtocol returns the appProtocol for a service port
+// that forwards to targetPort: "https" for the coder TLS listener and "http"
+// for the plain listener. Other targets, such as spec.extraPorts, get "".
+func controlPlaneServiceAppProtocol(targetPort intstr.IntOrString) string {
+	if targetPort.Type == intstr.String {
+		switch targetPort.StrVal {
+		case "http":
+			return "http"
+		case "https":
+			return "https"
+		}
+		return ""
+	}
+
+	switch targetPort.IntVal {
+	case controlPlaneTargetPort:
+		return "http"
+	case controlPlaneTLSTargetPort:
+		return "https"
+	}
+	return ""
+}
+
+func httpRouteBackendServicePort(coderControlPlane *coderv1alpha1.CoderControlPlane) (int32, error) {
+	if coderControlPlane == nil {
+		return 0, fmt.Errorf("assertion failed: coder control plane must not be nil")
+	}
+
+	servicePort := coderControlPlane.Spec.Service.Port
+	if servicePort == 0 {
+		servicePort = defaultControlPlanePort
+	}
+
+	if controlPlaneTLSEnabled(coderControlPlane) && servicePort == 443 {
+		return defaultControlPlanePort, nil
+	}
+
+	return servicePort, nil
+}
+
+func requiresWorkspaceRBACDriftRequeue(cp *coderv1alpha1.CoderControlPlane) bool {
+	if cp == nil || !workspacePermsEnabled(cp.Spec.RBAC.WorkspacePerms) {
+		return false
+	}
+
+	for _, namespace := range cp.Spec.RBAC.WorkspaceNamespaces {
+		namespace = strings.TrimSpace(namespace)
+		if namespace == "" || namespace == cp.Namespace {
+			continue
+		}
+		return true
+	}
+
+	return false
+}
+
+func workspaceRBACLabels(cp *coderv1alpha1.CoderControlPlane) map[string]string {
+	labels := maps.Clone(controlPlaneLabels(cp.Name))
+	labels["coder.com/control-plane"] = cp.Name
+	labels["coder.com/control-plane-namespace"] = cp.Namespace
+	return labels
+}
+
+// ManagedRBACCacheSelector selects the Roles and RoleBindings the controllers
+// create: workspace RBAC, which also carries the identity labels
+// reconcileRequestsForWorkspaceRBAC maps through, and provisioner RBAC. The
+// manager restricts its Role and RoleBinding cache to it so the cluster-wide
+// watches do not hold every Role in the cluster.
+func ManagedRBACCacheSelector() labels.Selector {
+	return labels.SelectorFromSet(labels.Set{"app.kubernetes.io/managed-by": "coder-k8s"})
+}
+
+// reconcileRequestsForWorkspaceRBAC maps a workspace Role or RoleBinding to its
+// control plane through the identity labels. Cross-namespace objects cannot
+// carry an owner reference, so Owns does not see their changes.
+func reconcileRequestsForWorkspaceRBAC(_ context.Context, obj client.Object) []reconcile.Request {
+	labels := obj.GetLabels()
+	name := labels["coder.com/control-plane"]
+	namespace := labels["coder.com/control-plane-namespace"]
+	if name == "" || namespace == "" {
+		return nil
+	}
+
+	return []reconcile.Request{{NamespacedName: types.NamespacedName{Name: name, Namespace: namespace}}}
+}
+
+func workspaceRBACAnnotations(ownerUID string) map[string]string {
+	return map[string]string{workspaceRBACOwnerUIDAnnotation: ownerUID}
+}
+
+func hasWorkspaceRBACIdentityLabels(object metav1.Object, coderControlPlane *coderv1alpha1.CoderControlPlane) bool {
+	if object == nil || coderControlPlane == nil {
+		return false
+	}
+
+	labels := object.GetLabels()
+	if labels == nil {
+		return false
+	}
+
+	return labels["coder.com/control-plane"] == coderControlPlane.Name &&
+		labels["coder.com/control-plane-namespace"] == coderControlPlane.Namespace
+}
+
+func hasWorkspaceRBACOwnerUID(object metav1.Object, coderControlPlane *coderv1alpha1.CoderControlPlane) bool {
+	if object == nil || coderControlPlane == nil {
+		return false
+	}
+
+	ownerUID := strings.TrimSpace(string(coderControlPlane.UID))
+	if ownerUID == "" {
+		return false
+	}
+
+	annotations := object.GetAnnotations()
+	if annotations == nil {
+		return false
+	}
+
+	return strings.TrimSpace(annotations[workspaceRBACOwnerUIDAnnotation]) == ownerUID
+}
+
+func isManagedWorkspaceRole(
+	role *rbacv1.Role,
+	coderControlPlane *coderv1alpha1.CoderControlPlane,
+	expectedRoleName string,
+) bool {
+	if role == nil || coderControlPlane == nil {
+		return false
+	}
+	if isOwnedByCoderControlPlane(role, coderControlPlane) {
+		return true
+	}
+	if !hasWorkspaceRBACIdentityLabels(role, coderControlPlane) {
+		return false
+	}
+	if hasWorkspaceRBACOwnerUID(role, coderControlPlane) {
+		return true
+	}
+
+	return role.Namespace != coderControlPlane.Namespace && role.Name == expectedRoleName
+}
+
+func isManagedWorkspaceRoleBinding(
+	roleBinding *rbacv1.RoleBinding,
+	coderControlPlane *coderv1alpha1.CoderControlPlane,
+	expectedRoleName string,
+	expectedRoleBindingName string,
+	expectedServiceAccountName string,
+) bool {
+	if roleBinding == nil || coderControlPlane == nil {
+		return false
+	}
+	if isOwnedByCoderControlPlane(roleBinding, coderControlPlane) {
+		return true
+	}
+	if !hasWorkspaceRBACIdentityLabels(roleBinding, coderControlPlane) {
+		return false
+	}
+	if hasWorkspaceRBACOwnerUID(roleBinding, coderControlPlane) {
+		return true
+	}
+	if roleBinding.Namespace == coderControlPlane.Namespace {
+		return false
+	}
+	if roleBinding.Name != expectedRoleBindingName {
+		return false
 	}
 	if roleBinding.RoleRef.APIGroup != rbacv1.GroupName || roleBinding.RoleRef.Kind != "Role" || roleBinding.RoleRef.Name != expectedRoleName {
 		return false
@@ -571,8 +1328,10 @@ func (r *CoderControlPlaneReconciler) finalizeWorkspaceRBAC(
 		return ctrl.Result{}, nil
 	}
 
+	// Keep the finalizer until every managed cross-namespace Role and
+	// RoleBinding is gone; a returned error requeues the deletion.
 	if err := r.cleanupManagedWorkspaceRBAC(ctx, coderControlPlane, nil, nil); err != nil {
-		return ctrl.Result{}, err
+		return ctrl.Result{}, fmt.Errorf("clean up workspace RBAC before removing finalizer: %w", err)
 	}
 
 	original := coderControlPlane.DeepCopy()
@@ -601,8 +1360,8 @@ func (r *CoderControlPlaneReconciler) reconcileServiceAccount(ctx context.Contex
 	_, err := controllerutil.CreateOrUpdate(ctx, r.Client, serviceAccount, func() error {
 		labels := maps.Clone(controlPlaneLabels(coderControlPlane.Name))
 		maps.Copy(labels, coderControlPlane.Spec.ServiceAccount.Labels)
-		serviceAccount.Labels = labels
-		serviceAccount.Annotations = maps.Clone(coderControlPlane.Spec.ServiceAccount.Annotations)
+		serviceAccount.Labels = childLabels(coderControlPlane, labels)
+		serviceAccount.Annotations = childAnnotations(coderControlPlane, coderControlPlane.Spec.ServiceAccount.Annotations)
 
 		if err := controllerutil.SetControllerReference(coderControlPlane, serviceAccount, r.Scheme); err != nil {
 			return fmt.Errorf("set controller reference: %w", err)
@@ -722,8 +1481,8 @@ func (r *CoderControlPlaneReconciler) reconcileWorkspaceRBAC(ctx context.Context
 
 		role := &rbacv1.Role{ObjectMeta: metav1.ObjectMeta{Name: roleName, Namespace: namespace}}
 		_, err := controllerutil.CreateOrUpdate(ctx, r.Client, role, func() error {
-			role.Labels = maps.Clone(labels)
-			role.Annotations = maps.Clone(annotations)
+			role.Labels = childLabels(coderControlPlane, labels)
+			role.Annotations = childAnnotations(coderControlPlane, annotations)
 			role.Rules = append([]rbacv1.PolicyRule(nil), rules...)
 
 			if namespace == coderControlPlane.Namespace {
@@ -742,8 +1501,8 @@ func (r *CoderControlPlaneReconciler) reconcileWorkspaceRBAC(ctx context.Context
 
 		roleBinding := &rbacv1.RoleBinding{ObjectMeta: metav1.ObjectMeta{Name: roleBindingName, Namespace: namespace}}
 		_, err = controllerutil.CreateOrUpdate(ctx, r.Client, roleBinding, func() error {
-			roleBinding.Labels = maps.Clone(labels)
-			roleBinding.Annotations = maps.Clone(annotations)
+			roleBinding.Labels = childLabels(coderControlPlane, labels)
+			roleBinding.Annotations = childAnnotations(coderControlPlane, annotations)
 			roleBinding.RoleRef = rbacv1.RoleRef{
 				APIGroup: rbacv1.GroupName,
 				Kind:     "Role",
@@ -805,6 +1564,10 @@ func (r *CoderControlPlaneReconciler) cleanupManagedWorkspaceRBAC(
 
 	labels := workspaceRBACLabels(coderControlPlane)
 
+	// Keep going after a failed delete so one unavailable namespace does not
+	// block cleanup elsewhere; the joined error makes the caller requeue.
+	var cleanupErrs []error
+
 	roles := &rbacv1.RoleList{}
 	if err := r.List(ctx, roles, client.MatchingLabels(labels)); err != nil {
 		return fmt.Errorf("list managed workspace roles: %w", err)
@@ -820,7 +1583,7 @@ func (r *CoderControlPlaneReconciler) cleanupManagedWorkspaceRBAC(
 			continue
 		}
 		if err := r.Delete(ctx, role); err != nil && !apierrors.IsNotFound(err) {
-			return fmt.Errorf("delete managed workspace role %s/%s: %w", role.Namespace, role.Name, err)
+			cleanupErrs = append(cleanupErrs, fmt.Errorf("delete managed workspace role %s/%s: %w", role.Namespace, role.Name, err))
 		}
 	}
 
@@ -839,26 +1602,46 @@ func (r *CoderControlPlaneReconciler) cleanupManagedWorkspaceRBAC(
 			continue
 		}
 		if err := r.Delete(ctx, roleBinding); err != nil && !apierrors.IsNotFound(err) {
-			return fmt.Errorf("delete managed workspace role binding %s/%s: %w", roleBinding.Namespace, roleBinding.Name, err)
+			cleanupErrs = append(cleanupErrs, fmt.Errorf("delete managed workspace role binding %s/%s: %w", roleBinding.Namespace, roleBinding.Name, err))
 		}
 	}
 
-	return nil
+	return errors.Join(cleanupErrs...)
 }
 
 func probeEnabled(explicit *bool, defaultEnabled bool) bool {
 	return boolOrDefault(explicit, defaultEnabled)
 }
 
-func buildProbe(spec coderv1alpha1.ProbeSpec, path, portName string) *corev1.Probe {
+// buildProbe returns a probe for spec. Probes use an HTTP GET unless spec.Type
+// selects a TCP or exec handler. The default path and port name apply unless
+// spec overrides them.
+func buildProbe(spec coderv1alpha1.ProbeSpec, defaultPath, defaultPortName string) *corev1.Probe {
+	port := intstr.FromString(defaultPortName)
+	if spec.Port != nil {
+		port = *spec.Port
+	}
+
+	var handler corev1.ProbeHandler
+	switch spec.Type {
+	case "tcpSocket":
+		handler.TCPSocket = &corev1.TCPSocketAction{Port: port}
+	case "exec":
+		handler.Exec = &corev1.ExecAction{Command: slices.Clone(spec.Command)}
+	default:
+		path := defaultPath
+		if healthPath := strings.TrimSpace(spec.HealthPath); healthPath != "" {
+			path = healthPath
+		}
+		handler.HTTPGet = &corev1.HTTPGetAction{
+			Path:   path,
+			Port:   port,
+			Scheme: corev1.URISchemeHTTP,
+		}
+	}
+
 	probe := &corev1.Probe{
-		ProbeHandler: corev1.ProbeHandler{
-			HTTPGet: &corev1.HTTPGetAction{
-				Path:   path,
-				Port:   intstr.FromString(portName),
-				Scheme: corev1.URISchemeHTTP,
-			},
-		},
+		ProbeHandler:        handler,
 		InitialDelaySeconds: spec.InitialDelaySeconds,
 		PeriodSeconds:       10,
 		TimeoutSeconds:      1,
@@ -881,9 +1664,15 @@ func buildProbe(spec coderv1alpha1.ProbeSpec, path, portName string) *corev1.Pro
 	return probe
 }
 
-func (r *CoderControlPlaneReconciler) reconcileDeployment(ctx context.Context, coderControlPlane *coderv1alpha1.CoderControlPlane) (*appsv1.Deployment, error) {
+// reconcileDeployment converges the control plane Deployment and returns the
+// operator-managed env var names that spec.extraEnv overrides.
+func (r *CoderControlPlaneReconciler) reconcileDeployment(
+	ctx context.Context,
+	coderControlPlane *coderv1alpha1.CoderControlPlane,
+	migrationPending bool,
+) (*appsv1.Deployment, []string, error) {
 	if coderControlPlane == nil {
-		return nil, fmt.Errorf("assertion failed: coder control plane must not be nil")
+		return nil, nil, fmt.Errorf("assertion failed: coder control plane must not be nil")
 	}
 
 	deployment := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: coderControlPlane.Name, Namespace: coderControlPlane.Namespace}}
@@ -894,13 +1683,33 @@ func (r *CoderControlPlaneReconciler) reconcileDeployment(ctx context.Context, c
 		var err error
 		accessURLConfiguredViaEnvFrom, err = r.envFromDefinesEnvVar(ctx, coderControlPlane.Namespace, coderControlPlane.Spec.EnvFrom, "CODER_ACCESS_URL")
 		if err != nil {
-			return nil, err
+			return nil, nil, err
+		}
+		if _, ok := coderControlPlane.Spec.Config["CODER_ACCESS_URL"]; ok {
+			accessURLConfiguredViaEnvFrom = true
 		}
 	}
 
-	_, err := controllerutil.CreateOrUpdate(ctx, r.Client, deployment, func() error {
+	containerResources, err := r.resolveContainerResources(coderControlPlane)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tlsChecksum, err := r.mountedSecretsChecksum(ctx, coderControlPlane)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	policyEnv, envFromExpanded, err := r.policyEnvFromEnv(ctx, coderControlPlane)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var overriddenManagedEnv []string
+	_, err = controllerutil.CreateOrUpdate(ctx, r.Client, deployment, func() error {
 		labels := controlPlaneLabels(coderControlPlane.Name)
-		deployment.Labels = maps.Clone(labels)
+		deployment.Labels = childLabels(coderControlPlane, labels)
+		applyCommonAnnotations(deployment, coderControlPlane)
 
 		if err := controllerutil.SetControllerReference(coderControlPlane, deployment, r.Scheme); err != nil {
 			return fmt.Errorf("set controller reference: %w", err)
@@ -910,19 +1719,19 @@ func (r *CoderControlPlaneReconciler) reconcileDeployment(ctx context.Context, c
 		if coderControlPlane.Spec.Replicas != nil {
 			replicas = *coderControlPlane.Spec.Replicas
 		}
-
-		image := coderControlPlane.Spec.Image
-		if image == "" {
-			image = defaultCoderImage
+		if migrationPending {
+			// Keep coderd from racing the migration Job against the database.
+			replicas = 0
 		}
 
+		image := coderImageOrDefault(coderControlPlane.Spec.Image, r.DefaultImage)
+
 		serviceAccountName := resolveServiceAccountName(coderControlPlane)
 		if strings.TrimSpace(serviceAccountName) == "" {
 			return fmt.Errorf("assertion failed: service account name must not be empty")
 		}
 
-		args := []string{"--http-address=0.0.0.0:8080"}
-		args = append(args, coderControlPlane.Spec.ExtraArgs...)
+		args, _ := controlPlaneArgs(coderControlPlane)
 
 		env := []corev1.EnvVar{
 			{
@@ -931,10 +1740,13 @@ func (r *CoderControlPlaneReconciler) reconcileDeployment(ctx context.Context, c
 					FieldRef: &corev1.ObjectFieldSelector{FieldPath: "status.podIP"},
 				},
 			},
-			{
-				Name:  "CODER_DERP_SERVER_RELAY_URL",
-				Value: "http://$(KUBE_POD_IP):8080",
-			},
+		}
+		env = append(env, controlPlaneDERPEnv(coderControlPlane)...)
+		if provisioner := coderControlPlane.Spec.Provisioner; provisioner != nil && provisioner.Daemons != nil {
+			env = append(env, corev1.EnvVar{
+				Name:  "CODER_PROVISIONER_DAEMONS",
+				Value: strconv.FormatInt(int64(*provisioner.Daemons), 10),
+			})
 		}
 
 		tlsEnabled := controlPlaneTLSEnabled(coderControlPlane)
@@ -965,6 +1777,21 @@ func (r *CoderControlPlaneReconciler) reconcileDeployment(ctx context.Context, c
 			}
 		}
 
+		securityEnv, err := controlPlaneSecurityEnv(coderControlPlane)
+		if err != nil {
+			return err
+		}
+		env = append(env, securityEnv...)
+
+		tlsHeaderEnv, err := controlPlaneTLSHeaderEnv(coderControlPlane)
+		if err != nil {
+			return err
+		}
+		env = append(env, tlsHeaderEnv...)
+		if builtinPostgresEnabled(coderControlPlane) {
+			env = append(env, builtinPostgresEnv(coderControlPlane))
+		}
+
 		ports := []corev1.ContainerPort{{
 			Name:          "http",
 			ContainerPort: controlPlaneTargetPort,
@@ -977,13 +1804,14 @@ func (r *CoderControlPlaneReconciler) reconcileDeployment(ctx context.Context, c
 			tlsCertFiles := make([]string, 0, len(coderControlPlane.Spec.TLS.SecretNames))
 			tlsKeyFiles := make([]string, 0, len(coderControlPlane.Spec.TLS.SecretNames))
 
-			tlsSecretSeen := make(map[string]struct{}, len(coderControlPlane.Spec.TLS.SecretNames))
+			tlsEntries, err := parseTLSSecretEntries(coderControlPlane.Spec.TLS.SecretNames)
+			if err != nil {
+				return err
+			}
 
-			for _, secretName := range coderControlPlane.Spec.TLS.SecretNames {
-				secretName = strings.TrimSpace(secretName)
-				if secretName == "" {
-					return fmt.Errorf("assertion failed: tls secret name must not be empty")
-				}
+			tlsSecretSeen := make(map[string]struct{}, len(tlsEntries))
+			for _, entry := range tlsEntries {
+				secretName := entry.secretName
 				if _, seen := tlsSecretSeen[secretName]; seen {
 					continue
 				}
@@ -1007,9 +1835,11 @@ func (r *CoderControlPlaneReconciler) reconcileDeployment(ctx context.Context, c
 				tlsKeyFiles = append(tlsKeyFiles, fmt.Sprintf("%s/tls.key", mountPath))
 			}
 
+			// Coder serves TLS on a single address, so entries with a port are
+			// exposed as extra Service ports in front of the same listener.
 			env = append(env,
 				corev1.EnvVar{Name: "CODER_TLS_ENABLE", Value: "true"},
-				corev1.EnvVar{Name: "CODER_TLS_ADDRESS", Value: "0.0.0.0:8443"},
+				corev1.EnvVar{Name: "CODER_TLS_ADDRESS", Value: fmt.Sprintf("0.0.0.0:%d", controlPlaneTLSTargetPort)},
 				corev1.EnvVar{Name: "CODER_TLS_CERT_FILE", Value: strings.Join(tlsCertFiles, ",")},
 				corev1.EnvVar{Name: "CODER_TLS_KEY_FILE", Value: strings.Join(tlsKeyFiles, ",")},
 			)
@@ -1021,6 +1851,16 @@ func (r *CoderControlPlaneReconciler) reconcileDeployment(ctx context.Context, c
 			})
 		}
 
+		tlsClientAuthEnv, clientCAVolume, clientCAVolumeMount, err := controlPlaneTLSClientAuth(coderControlPlane)
+		if err != nil {
+			return err
+		}
+		env = append(env, tlsClientAuthEnv...)
+		if clientCAVolume != nil {
+			volumes = append(volumes, *clientCAVolume)
+			volumeMounts = append(volumeMounts, *clientCAVolumeMount)
+		}
+
 		certSecretNameCounts := make(map[string]int, len(coderControlPlane.Spec.Certs.Secrets))
 		for i := range coderControlPlane.Spec.Certs.Secrets {
 			secretName := strings.TrimSpace(coderControlPlane.Spec.Certs.Secrets[i].Name)
@@ -1030,6 +1870,9 @@ func (r *CoderControlPlaneReconciler) reconcileDeployment(ctx context.Context, c
 			certSecretNameCounts[secretName]++
 		}
 
+		projectedCerts := coderControlPlane.Spec.Certs.Projected
+		var projectedCertSources []corev1.VolumeProjection
+		projectedCertSourceIndex := make(map[string]int, len(certSecretNameCounts))
 		certVolumeNameBySecret := make(map[string]string, len(certSecretNameCounts))
 		certMountFileCount := make(map[string]int, len(coderControlPlane.Spec.Certs.Secrets))
 		certSelectorSeen := make(map[string]struct{}, len(coderControlPlane.Spec.Certs.Secrets))
@@ -1050,6 +1893,29 @@ func (r *CoderControlPlaneReconciler) reconcileDeployment(ctx context.Context, c
 			}
 			certSelectorSeen[selectorKey] = struct{}{}
 
+			if projectedCerts {
+				fileName := projectedCertFileName(secret.Name, secret.Key)
+				fileCount := certMountFileCount[fileName]
+				certMountFileCount[fileName] = fileCount + 1
+				if fileCount > 0 {
+					fileName = fmt.Sprintf("%s-%d.crt", strings.TrimSuffix(fileName, ".crt"), fileCount+1)
+				}
+
+				sourceIndex, sourceExists := projectedCertSourceIndex[secret.Name]
+				if !sourceExists {
+					sourceIndex = len(projectedCertSources)
+					projectedCertSourceIndex[secret.Name] = sourceIndex
+					projectedCertSources = append(projectedCertSources, corev1.VolumeProjection{
+						Secret: &corev1.SecretProjection{
+							LocalObjectReference: corev1.LocalObjectReference{Name: secret.Name},
+						},
+					})
+				}
+				projection := projectedCertSources[sourceIndex].Secret
+				projection.Items = append(projection.Items, corev1.KeyToPath{Key: secret.Key, Path: fileName})
+				continue
+			}
+
 			volumeName, volumeExists := certVolumeNameBySecret[secret.Name]
 			if !volumeExists {
 				volumeName = volumeNameForSecret("ca-cert", secret.Name)
@@ -1086,24 +1952,93 @@ func (r *CoderControlPlaneReconciler) reconcileDeployment(ctx context.Context, c
 			})
 		}
 
-		env = append(env, coderControlPlane.Spec.ExtraEnv...)
+		if len(projectedCertSources) > 0 {
+			volumes = append(volumes, corev1.Volume{
+				Name: projectedCertsVolumeName,
+				VolumeSource: corev1.VolumeSource{
+					Projected: &corev1.ProjectedVolumeSource{Sources: projectedCertSources},
+				},
+			})
+			volumeMounts = append(volumeMounts, corev1.VolumeMount{
+				Name:      projectedCertsVolumeName,
+				MountPath: projectedCertsMountPath,
+				ReadOnly:  true,
+			})
+			// Go reads SSL_CERT_DIR instead of the default directories, so keep
+			// the image's system bundle alongside the consolidated CAs.
+			env = append(env, corev1.EnvVar{
+				Name:  "SSL_CERT_DIR",
+				Value: "/etc/ssl/certs:" + projectedCertsMountPath,
+			})
+		}
+
+		ports, err = appendExtraContainerPorts(ports, coderControlPlane.Spec.ExtraPorts)
+		if err != nil {
+			return err
+		}
+
+		securityContext := coderControlPlane.Spec.SecurityContext
+		if coderControlPlane.Spec.Hardened {
+			securityContext = hardenedSecurityContext(securityContext)
+			if securityContext.ReadOnlyRootFilesystem != nil && *securityContext.ReadOnlyRootFilesystem {
+				for _, writable := range []struct{ name, path string }{
+					{name: "hardened-tmp", path: "/tmp"},
+					{name: "hardened-home", path: "/home/coder"},
+				} {
+					// A user mount at the same path already provides it, and
+					// two mounts at one path make the pod invalid.
+					if slices.ContainsFunc(coderControlPlane.Spec.VolumeMounts, func(mount corev1.VolumeMount) bool {
+						return path.Clean(mount.MountPath) == writable.path
+					}) {
+						continue
+					}
+					volumes = append(volumes, corev1.Volume{
+						Name:         writable.name,
+						VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+					})
+					volumeMounts = append(volumeMounts, corev1.VolumeMount{Name: writable.name, MountPath: writable.path})
+				}
+			}
+		}
+
+		for _, token := range coderControlPlane.Spec.ProjectedTokens {
+			tokenVolume, tokenVolumeMount := projectedTokenVolume(token)
+			volumes = append(volumes, tokenVolume)
+			volumeMounts = append(volumeMounts, tokenVolumeMount)
+		}
+
+		env, overriddenManagedEnv = overlayExtraEnv(env, coderControlPlane.Spec.ExtraEnv)
+		envFrom := controlPlaneEnvFrom(coderControlPlane, !envFromExpanded)
+		if envFromExpanded {
+			// env takes precedence over envFrom, so expanded keys only fill
+			// names the managed env and spec.extraEnv leave unset.
+			for _, envVar := range policyEnv {
+				if !slices.ContainsFunc(env, func(existing corev1.EnvVar) bool { return existing.Name == envVar.Name }) {
+					env = append(env, envVar)
+				}
+			}
+		}
 		volumes = append(volumes, coderControlPlane.Spec.Volumes...)
 		volumeMounts = append(volumeMounts, coderControlPlane.Spec.VolumeMounts...)
 
 		container := corev1.Container{
-			Name:         "coder",
-			Image:        image,
-			Args:         args,
-			Env:          env,
-			EnvFrom:      coderControlPlane.Spec.EnvFrom,
-			Ports:        ports,
-			VolumeMounts: volumeMounts,
+			Name:            "coder",
+			Image:           image,
+			ImagePullPolicy: imagePullPolicyOrDefault(coderControlPlane.Spec.ImagePullPolicy, image),
+			Args:            args,
+			Env:             env,
+			EnvFrom:         envFrom,
+			Ports:           ports,
+			VolumeMounts:    volumeMounts,
 		}
-		if coderControlPlane.Spec.SecurityContext != nil {
-			container.SecurityContext = coderControlPlane.Spec.SecurityContext
+		if securityContext != nil {
+			container.SecurityContext = securityContext
 		}
-		if coderControlPlane.Spec.Resources != nil {
-			container.Resources = *coderControlPlane.Spec.Resources
+		if coderControlPlane.Spec.Lifecycle != nil {
+			container.Lifecycle = coderControlPlane.Spec.Lifecycle.DeepCopy()
+		}
+		if containerResources != nil {
+			container.Resources = *containerResources
 		}
 		if probeEnabled(coderControlPlane.Spec.ReadinessProbe.Enabled, true) {
 			container.ReadinessProbe = buildProbe(coderControlPlane.Spec.ReadinessProbe, "/healthz", "http")
@@ -1123,8 +2058,17 @@ func (r *CoderControlPlaneReconciler) reconcileDeployment(ctx context.Context, c
 				[]corev1.TopologySpreadConstraint(nil),
 				coderControlPlane.Spec.TopologySpreadConstraints...,
 			),
+			HostAliases: append([]corev1.HostAlias(nil), coderControlPlane.Spec.HostAliases...),
+			DNSPolicy:   coderControlPlane.Spec.DNSPolicy,
 		}
-		if coderControlPlane.Spec.PodSecurityContext != nil {
+		if coderControlPlane.Spec.DNSConfig != nil {
+			podSpec.DNSConfig = coderControlPlane.Spec.DNSConfig.DeepCopy()
+		}
+		if coderControlPlane.Spec.TerminationGracePeriodSeconds != nil {
+			gracePeriod := *coderControlPlane.Spec.TerminationGracePeriodSeconds
+			podSpec.TerminationGracePeriodSeconds = &gracePeriod
+		}
+		if coderControlPlane.Spec.PodSecurityContext != nil {
 			podSpec.SecurityContext = coderControlPlane.Spec.PodSecurityContext
 		}
 		if coderControlPlane.Spec.Affinity != nil {
@@ -1137,16 +2081,483 @@ func (r *CoderControlPlaneReconciler) reconcileDeployment(ctx context.Context, c
 			ObjectMeta: metav1.ObjectMeta{Labels: maps.Clone(labels)},
 			Spec:       podSpec,
 		}
+		podAnnotations := map[string]string{}
+		if tlsChecksum != "" {
+			podAnnotations[tlsChecksumAnnotation] = tlsChecksum
+		}
+		if len(coderControlPlane.Spec.Config) > 0 {
+			podAnnotations[configChecksumAnnotation] = configChecksum(coderControlPlane.Spec.Config)
+		}
+		// Pod template changes roll the Deployment, so copying a new
+		// restartedAt value restarts Coder without a spec change.
+		if restartedAt := strings.TrimSpace(coderControlPlane.Annotations[coderv1alpha1.RestartedAtAnnotation]); restartedAt != "" {
+			podAnnotations[coderv1alpha1.RestartedAtAnnotation] = restartedAt
+		}
+		if len(podAnnotations) > 0 {
+			deployment.Spec.Template.Annotations = podAnnotations
+		}
 
 		return nil
 	})
 	if err != nil {
-		return nil, fmt.Errorf("reconcile control plane deployment: %w", err)
+		return nil, nil, fmt.Errorf("reconcile control plane deployment: %w", err)
 	}
 
 	// Avoid an immediate cached read-after-write here; cache propagation lag can
 	// transiently return NotFound for just-created objects and produce noisy reconcile errors.
-	return deployment, nil
+	return deployment, overriddenManagedEnv, nil
+}
+
+// hardenedSecurityContext returns the restricted security context applied by
+// spec.hardened, with every field set in override taking precedence.
+func hardenedSecurityContext(override *corev1.SecurityContext) *corev1.SecurityContext {
+	securityContext := &corev1.SecurityContext{}
+	if override != nil {
+		securityContext = override.DeepCopy()
+	}
+	runAsNonRoot, readOnlyRootFilesystem, allowPrivilegeEscalation := true, true, false
+	runAsUser, runAsGroup := hardenedUserID, hardenedUserID
+	if securityContext.RunAsNonRoot == nil {
+		securityContext.RunAsNonRoot = &runAsNonRoot
+	}
+	if securityContext.RunAsUser == nil {
+		securityContext.RunAsUser = &runAsUser
+	}
+	if securityContext.RunAsGroup == nil {
+		securityContext.RunAsGroup = &runAsGroup
+	}
+	if securityContext.AllowPrivilegeEscalation == nil {
+		securityContext.AllowPrivilegeEscalation = &allowPrivilegeEscalation
+	}
+	if securityContext.ReadOnlyRootFilesystem == nil {
+		securityContext.ReadOnlyRootFilesystem = &readOnlyRootFilesystem
+	}
+	if securityContext.Capabilities == nil {
+		securityContext.Capabilities = &corev1.Capabilities{Drop: []corev1.Capability{"ALL"}}
+	}
+	if securityContext.SeccompProfile == nil {
+		securityContext.SeccompProfile = &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeRuntimeDefault}
+	}
+
+	return securityContext
+}
+
+// mountedSecretNames returns the distinct Secret names mounted from
+// spec.tls.secretNames, spec.tls.clientCASecretName, and spec.certs.secrets.
+func mountedSecretNames(coderControlPlane *coderv1alpha1.CoderControlPlane) []string {
+	if coderControlPlane == nil {
+		return nil
+	}
+
+	names := make([]string, 0, len(coderControlPlane.Spec.TLS.SecretNames)+len(coderControlPlane.Spec.Certs.Secrets))
+	for _, entry := range coderControlPlane.Spec.TLS.SecretNames {
+		secretName, _, _ := strings.Cut(strings.TrimSpace(entry), ":")
+		if secretName = strings.TrimSpace(secretName); secretName != "" && !slices.Contains(names, secretName) {
+			names = append(names, secretName)
+		}
+	}
+	if secretName := strings.TrimSpace(coderControlPlane.Spec.TLS.ClientCASecretName); secretName != "" && !slices.Contains(names, secretName) {
+		names = append(names, secretName)
+	}
+	for i := range coderControlPlane.Spec.Certs.Secrets {
+		secretName := strings.TrimSpace(coderControlPlane.Spec.Certs.Secrets[i].Name)
+		if secretName != "" && !slices.Contains(names, secretName) {
+			names = append(names, secretName)
+		}
+	}
+	slices.Sort(names)
+
+	return names
+}
+
+// mountedSecretsChecksum hashes the contents of every mounted TLS and CA cert
+// Secret. Missing Secrets contribute a marker so their creation also changes the
+// checksum. It returns an empty string when no Secrets are mounted.
+func (r *CoderControlPlaneReconciler) mountedSecretsChecksum(
+	ctx context.Context,
+	coderControlPlane *coderv1alpha1.CoderControlPlane,
+) (string, error) {
+	secretNames := mountedSecretNames(coderControlPlane)
+	if len(secretNames) == 0 {
+		return "", nil
+	}
+
+	var reader client.Reader = r.Client
+	if r.APIReader != nil {
+		reader = r.APIReader
+	}
+	if reader == nil {
+		return "", fmt.Errorf("assertion failed: reader must not be nil")
+	}
+
+	hasher := sha256.New()
+	for _, secretName := range secretNames {
+		_, _ = hasher.Write([]byte(secretName))
+		_, _ = hasher.Write([]byte{0})
+
+		secret := &corev1.Secret{}
+		err := reader.Get(ctx, types.NamespacedName{Name: secretName, Namespace: coderControlPlane.Namespace}, secret)
+		switch {
+		case apierrors.IsNotFound(err):
+			_, _ = hasher.Write([]byte("<missing>"))
+			_, _ = hasher.Write([]byte{0})
+			continue
+		case err != nil:
+			return "", fmt.Errorf("get mounted secret %s/%s: %w", coderControlPlane.Namespace, secretName, err)
+		}
+
+		for _, key := range slices.Sorted(maps.Keys(secret.Data)) {
+			_, _ = hasher.Write([]byte(key))
+			_, _ = hasher.Write([]byte{0})
+			_, _ = hasher.Write(secret.Data[key])
+			_, _ = hasher.Write([]byte{0})
+		}
+	}
+
+	return hex.EncodeToString(hasher.Sum(nil)), nil
+}
+
+// overlayExtraEnv applies user-provided env vars on top of operator-managed ones.
+// Entries are deduplicated by name with the last definition winning. An ExtraEnv
+// entry that shares a name with a managed entry replaces it in place so that $(VAR)
+// references between managed entries keep resolving in order; all other ExtraEnv
+// entries are appended. It returns the sorted names of overridden managed entries.
+func overlayExtraEnv(managed, extra []corev1.EnvVar) ([]corev1.EnvVar, []string) {
+	merged := make([]corev1.EnvVar, 0, len(managed)+len(extra))
+	mergedIndex := make(map[string]int, len(managed)+len(extra))
+	for i := range managed {
+		if index, ok := mergedIndex[managed[i].Name]; ok {
+			merged[index] = managed[i]
+			continue
+		}
+		mergedIndex[managed[i].Name] = len(merged)
+		merged = append(merged, managed[i])
+	}
+	managedCount := len(merged)
+
+	overridden := make(map[string]struct{})
+	for i := range extra {
+		index, ok := mergedIndex[extra[i].Name]
+		if !ok {
+			mergedIndex[extra[i].Name] = len(merged)
+			merged = append(merged, extra[i])
+			continue
+		}
+		merged[index] = extra[i]
+		if index < managedCount {
+			overridden[extra[i].Name] = struct{}{}
+		}
+	}
+
+	overriddenNames := make([]string, 0, len(overridden))
+	for name := range overridden {
+		overriddenNames = append(overriddenNames, name)
+	}
+	slices.Sort(overriddenNames)
+
+	return merged, overriddenNames
+}
+
+func setManagedEnvOverriddenCondition(
+	nextStatus *coderv1alpha1.CoderControlPlaneStatus,
+	generation int64,
+	overriddenNames []string,
+) error {
+	if nextStatus == nil {
+		return fmt.Errorf("assertion failed: next status must not be nil")
+	}
+
+	if len(overriddenNames) == 0 {
+		meta.RemoveStatusCondition(&nextStatus.Conditions, coderv1alpha1.CoderControlPlaneConditionManagedEnvOverridden)
+		return nil
+	}
+
+	return setControlPlaneCondition(
+		nextStatus,
+		generation,
+		coderv1alpha1.CoderControlPlaneConditionManagedEnvOverridden,
+		metav1.ConditionTrue,
+		managedEnvOverriddenReasonExtraEnv,
+		fmt.Sprintf("spec.extraEnv overrides operator-managed env vars: %s", strings.Join(overriddenNames, ", ")),
+	)
+}
+
+// controlPlaneArgs returns the coder server arguments, the operator-managed
+// flags overlaid with spec.extraArgs, and the sorted names of managed flags
+// that spec.extraArgs overrides.
+func controlPlaneArgs(coderControlPlane *coderv1alpha1.CoderControlPlane) ([]string, []string) {
+	return overlayExtraArgs([]string{"--http-address=0.0.0.0:8080"}, coderControlPlane.Spec.ExtraArgs)
+}
+
+// commandLineArg is a flag with its value tokens, or a lone positional token
+// when name is empty.
+type commandLineArg struct {
+	name   string
+	tokens []string
+}
+
+// groupCommandLineArgs splits args into flags keyed by their dash-trimmed name.
+// A flag without an inline `=value` takes the following token as its value
+// unless that token is itself a flag.
+func groupCommandLineArgs(args []string) []commandLineArg {
+	grouped := make([]commandLineArg, 0, len(args))
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		name := strings.TrimLeft(arg, "-")
+		if name == arg || name == "" {
+			grouped = append(grouped, commandLineArg{tokens: []string{arg}})
+			continue
+		}
+
+		tokens := []string{arg}
+		if flagName, _, hasValue := strings.Cut(name, "="); hasValue {
+			name = flagName
+		} else if i+1 < len(args) && !strings.HasPrefix(args[i+1], "-") {
+			i++
+			tokens = append(tokens, args[i])
+		}
+		grouped = append(grouped, commandLineArg{name: name, tokens: tokens})
+	}
+
+	return grouped
+}
+
+// scalarServerFlags names coder server flags that take a single value, so a
+// later definition in spec.extraArgs replaces an earlier one. Flags that may be
+// repeated, such as --oidc-group-mapping or --external-auth-*, are not listed
+// and pass through as given.
+var scalarServerFlags = map[string]struct{}{
+	"access-url":          {},
+	"cache-dir":           {},
+	"http-address":        {},
+	"oidc-client-id":      {},
+	"oidc-client-secret":  {},
+	"oidc-issuer-url":     {},
+	"postgres-url":        {},
+	"pprof-address":       {},
+	"pprof-enable":        {},
+	"prometheus-address":  {},
+	"prometheus-enable":   {},
+	"tls-address":         {},
+	"tls-enable":          {},
+	"verbose":             {},
+	"wildcard-access-url": {},
+}
+
+// overlayExtraArgs applies user-provided flags on top of operator-managed ones,
+// mirroring overlayExtraEnv. An extra flag that shares a name with a managed
+// flag replaces it in place. Extra flags listed in scalarServerFlags are
+// deduplicated by name with the last definition winning in the position of the
+// first; all other extra arguments are appended in order. It returns the
+// sorted names of overridden managed flags.
+func overlayExtraArgs(managed, extra []string) ([]string, []string) {
+	merged := make([]commandLineArg, 0, len(managed)+len(extra))
+	mergedIndex := make(map[string]int, len(managed)+len(extra))
+	add := func(arg commandLineArg, scalar bool) (int, bool) {
+		if arg.name == "" {
+			merged = append(merged, arg)
+			return 0, false
+		}
+		if index, ok := mergedIndex[arg.name]; ok {
+			merged[index] = arg
+			return index, true
+		}
+		if scalar {
+			mergedIndex[arg.name] = len(merged)
+		}
+		merged = append(merged, arg)
+		return 0, false
+	}
+
+	for _, arg := range groupCommandLineArgs(managed) {
+		add(arg, true)
+	}
+	managedCount := len(merged)
+
+	overridden := make(map[string]struct{})
+	for _, arg := range groupCommandLineArgs(extra) {
+		_, scalar := scalarServerFlags[arg.name]
+		if index, replaced := add(arg, scalar); replaced && index < managedCount {
+			overridden["--"+arg.name] = struct{}{}
+		}
+	}
+
+	args := make([]string, 0, len(managed)+len(extra))
+	for _, arg := range merged {
+		args = append(args, arg.tokens...)
+	}
+
+	return args, slices.Sorted(maps.Keys(overridden))
+}
+
+func setManagedArgsOverriddenCondition(
+	nextStatus *coderv1alpha1.CoderControlPlaneStatus,
+	generation int64,
+	overriddenNames []string,
+) error {
+	if nextStatus == nil {
+		return fmt.Errorf("assertion failed: next status must not be nil")
+	}
+
+	if len(overriddenNames) == 0 {
+		meta.RemoveStatusCondition(&nextStatus.Conditions, coderv1alpha1.CoderControlPlaneConditionManagedArgsOverridden)
+		return nil
+	}
+
+	return setControlPlaneCondition(
+		nextStatus,
+		generation,
+		coderv1alpha1.CoderControlPlaneConditionManagedArgsOverridden,
+		metav1.ConditionTrue,
+		managedArgsOverriddenReasonExtraArgs,
+		fmt.Sprintf("spec.extraArgs overrides operator-managed flags: %s", strings.Join(overriddenNames, ", ")),
+	)
+}
+
+// setImagePinnedCondition sets ImagePinned=False while image uses a mutable
+// tag and removes the condition otherwise, including when the operator does
+// not manage the Deployment and image is empty.
+func setImagePinnedCondition(
+	nextStatus *coderv1alpha1.CoderControlPlaneStatus,
+	generation int64,
+	image string,
+) error {
+	if nextStatus == nil {
+		return fmt.Errorf("assertion failed: next status must not be nil")
+	}
+
+	if image == "" || !imageTagMutable(image) {
+		meta.RemoveStatusCondition(&nextStatus.Conditions, coderv1alpha1.CoderControlPlaneConditionImagePinned)
+		return nil
+	}
+
+	return setControlPlaneCondition(
+		nextStatus,
+		generation,
+		coderv1alpha1.CoderControlPlaneConditionImagePinned,
+		metav1.ConditionFalse,
+		imagePinnedReasonMutableTag,
+		fmt.Sprintf(
+			"image %q uses the latest tag or no tag, so pods can run different Coder versions after a restart; pin a version tag or digest in spec.image",
+			image,
+		),
+	)
+}
+
+// projectedTokenVolume returns the projected ServiceAccount token volume and
+// read-only mount for one spec.projectedTokens entry.
+func projectedTokenVolume(token coderv1alpha1.ProjectedTokenSpec) (corev1.Volume, corev1.VolumeMount) {
+	volumeName := projectedTokenVolumePrefix + token.Name
+	mountPath := token.MountPath
+	if mountPath == "" {
+		mountPath = path.Join(projectedTokenMountDir, token.Name)
+	}
+	tokenPath := token.Path
+	if tokenPath == "" {
+		tokenPath = projectedTokenDefaultPath
+	}
+	expirationSeconds := projectedTokenDefaultExpirationSeconds
+	if token.ExpirationSeconds != nil {
+		expirationSeconds = *token.ExpirationSeconds
+	}
+
+	volume := corev1.Volume{
+		Name: volumeName,
+		VolumeSource: corev1.VolumeSource{
+			Projected: &corev1.ProjectedVolumeSource{
+				Sources: []corev1.VolumeProjection{{
+					ServiceAccountToken: &corev1.ServiceAccountTokenProjection{
+						Audience:          token.Audience,
+						ExpirationSeconds: &expirationSeconds,
+						Path:              tokenPath,
+					},
+				}},
+			},
+		},
+	}
+	volumeMount := corev1.VolumeMount{
+		Name:      volumeName,
+		MountPath: mountPath,
+		ReadOnly:  true,
+	}
+
+	return volume, volumeMount
+}
+
+// setServicePortPlaintextCondition sets ServicePortPlaintext=True while the
+// primary service port is 443 but forwards to the plain HTTP listener because
+// TLS is disabled, and removes the condition otherwise. Clients expect TLS on
+// 443, so this is almost always a mistake.
+func setServicePortPlaintextCondition(
+	nextStatus *coderv1alpha1.CoderControlPlaneStatus,
+	coderControlPlane *coderv1alpha1.CoderControlPlane,
+) error {
+	if nextStatus == nil {
+		return fmt.Errorf("assertion failed: next status must not be nil")
+	}
+	if coderControlPlane == nil {
+		return fmt.Errorf("assertion failed: coder control plane must not be nil")
+	}
+
+	targetPort := intstr.FromInt(int(controlPlaneTargetPort))
+	if override := coderControlPlane.Spec.Service.TargetPort; override != nil {
+		targetPort = *override
+	}
+	if coderControlPlane.Spec.Service.Port != controlPlaneHTTPSServicePort ||
+		controlPlaneTLSEnabled(coderControlPlane) ||
+		controlPlaneServiceAppProtocol(targetPort) != "http" {
+		meta.RemoveStatusCondition(&nextStatus.Conditions, coderv1alpha1.CoderControlPlaneConditionServicePortPlaintext)
+		return nil
+	}
+
+	return setControlPlaneCondition(
+		nextStatus,
+		coderControlPlane.Generation,
+		coderv1alpha1.CoderControlPlaneConditionServicePortPlaintext,
+		metav1.ConditionTrue,
+		servicePortPlaintextReasonHTTPSPortWithoutTLS,
+		"spec.service.port is 443 but TLS is disabled, so the service serves plain HTTP on the HTTPS port; set spec.tls.secretNames to enable TLS or use a different spec.service.port",
+	)
+}
+
+// setServiceTargetPortValidCondition reports whether spec.service.targetPort
+// matches a port the coder container exposes. The condition is removed while
+// targetPort is unset or the operator does not manage the Deployment.
+func setServiceTargetPortValidCondition(
+	nextStatus *coderv1alpha1.CoderControlPlaneStatus,
+	coderControlPlane *coderv1alpha1.CoderControlPlane,
+	targetPortErr error,
+) error {
+	if nextStatus == nil {
+		return fmt.Errorf("assertion failed: next status must not be nil")
+	}
+	if coderControlPlane == nil {
+		return fmt.Errorf("assertion failed: coder control plane must not be nil")
+	}
+
+	if coderControlPlane.Spec.Service.TargetPort == nil || !controlPlaneDeploymentManaged(coderControlPlane) {
+		meta.RemoveStatusCondition(&nextStatus.Conditions, coderv1alpha1.CoderControlPlaneConditionServiceTargetPortValid)
+		return nil
+	}
+	if targetPortErr != nil {
+		return setControlPlaneCondition(
+			nextStatus,
+			coderControlPlane.Generation,
+			coderv1alpha1.CoderControlPlaneConditionServiceTargetPortValid,
+			metav1.ConditionFalse,
+			serviceTargetPortValidReasonNotExposed,
+			targetPortErr.Error()+"; the Service is left unchanged until it does",
+		)
+	}
+
+	return setControlPlaneCondition(
+		nextStatus,
+		coderControlPlane.Generation,
+		coderv1alpha1.CoderControlPlaneConditionServiceTargetPortValid,
+		metav1.ConditionTrue,
+		serviceTargetPortValidReasonExposed,
+		"spec.service.targetPort matches a port exposed by the coder container",
+	)
 }
 
 func (r *CoderControlPlaneReconciler) reconcileService(ctx context.Context, coderControlPlane *coderv1alpha1.CoderControlPlane) (*corev1.Service, error) {
@@ -1154,8 +2565,8 @@ func (r *CoderControlPlaneReconciler) reconcileService(ctx context.Context, code
 
 	_, err := controllerutil.CreateOrUpdate(ctx, r.Client, service, func() error {
 		labels := controlPlaneLabels(coderControlPlane.Name)
-		service.Labels = maps.Clone(labels)
-		service.Annotations = maps.Clone(coderControlPlane.Spec.Service.Annotations)
+		service.Labels = childLabels(coderControlPlane, labels)
+		service.Annotations = childAnnotations(coderControlPlane, coderControlPlane.Spec.Service.Annotations)
 
 		if err := controllerutil.SetControllerReference(coderControlPlane, service, r.Scheme); err != nil {
 			return fmt.Errorf("set controller reference: %w", err)
@@ -1181,6 +2592,9 @@ func (r *CoderControlPlaneReconciler) reconcileService(ctx context.Context, code
 			primaryServicePort.Name = "https"
 			primaryServicePort.TargetPort = intstr.FromInt(int(controlPlaneTLSTargetPort))
 		}
+		if targetPort := coderControlPlane.Spec.Service.TargetPort; targetPort != nil {
+			primaryServicePort.TargetPort = *targetPort
+		}
 
 		servicePorts := []corev1.ServicePort{primaryServicePort}
 		if tlsEnabled && servicePort == 443 {
@@ -1199,60 +2613,455 @@ func (r *CoderControlPlaneReconciler) reconcileService(ctx context.Context, code
 				TargetPort: intstr.FromInt(int(controlPlaneTLSTargetPort)),
 			})
 		}
+		if tlsEnabled {
+			tlsEntries, err := parseTLSSecretEntries(coderControlPlane.Spec.TLS.SecretNames)
+			if err != nil {
+				return err
+			}
+			for _, port := range controlPlaneTLSListenerPorts(tlsEntries) {
+				if slices.ContainsFunc(servicePorts, func(existing corev1.ServicePort) bool { return existing.Port == port }) {
+					return fmt.Errorf("tls listener port %d conflicts with an existing service port", port)
+				}
+				servicePorts = append(servicePorts, corev1.ServicePort{
+					Name:       tlsListenerPortName(port),
+					Port:       port,
+					Protocol:   corev1.ProtocolTCP,
+					TargetPort: intstr.FromInt(int(controlPlaneTLSTargetPort)),
+				})
+			}
+		}
+		if mode := coderControlPlane.Spec.Service.AppProtocol; mode != "" {
+			for i := range servicePorts {
+				appProtocol := controlPlaneServiceAppProtocol(servicePorts[i].TargetPort)
+				if i == 0 && mode != serviceAppProtocolAuto {
+					appProtocol = mode
+				}
+				if appProtocol != "" {
+					servicePorts[i].AppProtocol = &appProtocol
+				}
+			}
+		}
+		for _, extraPort := range coderControlPlane.Spec.ExtraPorts {
+			if extraPort.Name == "" {
+				continue
+			}
+			if slices.ContainsFunc(servicePorts, func(existing corev1.ServicePort) bool { return existing.Port == extraPort.ContainerPort }) {
+				return fmt.Errorf("extra port %q conflicts with an existing service port %d", extraPort.Name, extraPort.ContainerPort)
+			}
+			protocol := extraPort.Protocol
+			if protocol == "" {
+				protocol = corev1.ProtocolTCP
+			}
+			servicePorts = append(servicePorts, corev1.ServicePort{
+				Name:       extraPort.Name,
+				Port:       extraPort.ContainerPort,
+				Protocol:   protocol,
+				TargetPort: intstr.FromString(extraPort.Name),
+			})
+		}
+
+		service.Spec.Type = serviceType
+		service.Spec.Selector = maps.Clone(labels)
+		service.Spec.Ports = servicePorts
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("reconcile control plane service: %w", err)
+	}
+
+	// Avoid an immediate cached read-after-write here; cache propagation lag can
+	// transiently return NotFound for just-created objects and produce noisy reconcile errors.
+	return service, nil
+}
+
+func meshServiceName(coderControlPlane *coderv1alpha1.CoderControlPlane) string {
+	return coderControlPlane.Name + meshServiceSuffix
+}
+
+func meshServiceEnabled(coderControlPlane *coderv1alpha1.CoderControlPlane) bool {
+	if coderControlPlane.Spec.HighAvailability == nil || !coderControlPlane.Spec.HighAvailability.Enabled {
+		return false
+	}
+
+	return coderControlPlane.Spec.Replicas != nil && *coderControlPlane.Spec.Replicas > 1
+}
+
+// controlPlaneDERPEnv returns the DERP relay settings for the coder
+// container. The relay URL defaults to the pod IP unless spec.derp.relayURL
+// overrides it; an override may reference $(KUBE_POD_IP) so each replica
+// still advertises its own address.
+func controlPlaneDERPEnv(coderControlPlane *coderv1alpha1.CoderControlPlane) []corev1.EnvVar {
+	relayURL := "http://$(KUBE_POD_IP):8080"
+	derp := coderControlPlane.Spec.DERP
+	if derp != nil && strings.TrimSpace(derp.RelayURL) != "" {
+		relayURL = strings.TrimSpace(derp.RelayURL)
+	}
+
+	return []corev1.EnvVar{{
+		Name:  "CODER_DERP_SERVER_RELAY_URL",
+		Value: relayURL,
+	}}
+}
+
+// reconcileMeshService manages the headless Service used for DERP mesh peer
+// discovery. It only exists while high availability is enabled with more than
+// one replica, and is removed otherwise.
+func (r *CoderControlPlaneReconciler) reconcileMeshService(ctx context.Context, coderControlPlane *coderv1alpha1.CoderControlPlane) error {
+	if coderControlPlane == nil {
+		return fmt.Errorf("assertion failed: coder control plane must not be nil")
+	}
+
+	if !meshServiceEnabled(coderControlPlane) {
+		return r.cleanupOwnedMeshService(ctx, coderControlPlane)
+	}
+
+	service := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: meshServiceName(coderControlPlane), Namespace: coderControlPlane.Namespace}}
+	_, err := controllerutil.CreateOrUpdate(ctx, r.Client, service, func() error {
+		labels := controlPlaneLabels(coderControlPlane.Name)
+		service.Labels = childLabels(coderControlPlane, labels)
+		applyCommonAnnotations(service, coderControlPlane)
+
+		if err := controllerutil.SetControllerReference(coderControlPlane, service, r.Scheme); err != nil {
+			return fmt.Errorf("set controller reference: %w", err)
+		}
+
+		service.Spec.Type = corev1.ServiceTypeClusterIP
+		service.Spec.ClusterIP = corev1.ClusterIPNone
+		// Peers must be discoverable while they are still starting so the mesh can form.
+		service.Spec.PublishNotReadyAddresses = true
+		service.Spec.Selector = maps.Clone(labels)
+		service.Spec.Ports = []corev1.ServicePort{{
+			Name:       "http",
+			Port:       controlPlaneTargetPort,
+			Protocol:   corev1.ProtocolTCP,
+			TargetPort: intstr.FromInt(int(controlPlaneTargetPort)),
+		}}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("reconcile control plane mesh service: %w", err)
+	}
+
+	return nil
+}
+
+// cleanupOwnedDeployment deletes the control plane Deployment this control
+// plane created before spec.manageDeployment was set to false.
+func (r *CoderControlPlaneReconciler) cleanupOwnedDeployment(ctx context.Context, coderControlPlane *coderv1alpha1.CoderControlPlane) error {
+	if coderControlPlane == nil {
+		return fmt.Errorf("assertion failed: coder control plane must not be nil")
+	}
+
+	deployment := &appsv1.Deployment{}
+	namespacedName := types.NamespacedName{Name: coderControlPlane.Name, Namespace: coderControlPlane.Namespace}
+	err := r.Get(ctx, namespacedName, deployment)
+	switch {
+	case err == nil:
+	case apierrors.IsNotFound(err):
+		return nil
+	default:
+		return fmt.Errorf("get control plane deployment %s: %w", namespacedName, err)
+	}
+
+	if !isOwnedByCoderControlPlane(deployment, coderControlPlane) {
+		return nil
+	}
+
+	if err := r.Delete(ctx, deployment); err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("delete control plane deployment %s: %w", namespacedName, err)
+	}
+
+	return nil
+}
+
+// reconcileConfigMap writes spec.config to the managed ConfigMap, or deletes
+// that ConfigMap when spec.config is empty or the Deployment is not managed.
+// It reports a conflict, and leaves the ConfigMap alone, when a ConfigMap with
+// the managed name exists but this control plane does not own it.
+func (r *CoderControlPlaneReconciler) reconcileConfigMap(ctx context.Context, coderControlPlane *coderv1alpha1.CoderControlPlane) (bool, error) {
+	if coderControlPlane == nil {
+		return false, fmt.Errorf("assertion failed: coder control plane must not be nil")
+	}
+
+	if len(coderControlPlane.Spec.Config) == 0 || !controlPlaneDeploymentManaged(coderControlPlane) {
+		return false, r.cleanupOwnedConfigMap(ctx, coderControlPlane)
+	}
+
+	configMap := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: controlPlaneConfigMapName(coderControlPlane), Namespace: coderControlPlane.Namespace}}
+	err := r.Get(ctx, client.ObjectKeyFromObject(configMap), configMap)
+	switch {
+	case err == nil:
+		if !isOwnedByCoderControlPlane(configMap, coderControlPlane) {
+			return true, nil
+		}
+	case apierrors.IsNotFound(err):
+	default:
+		return false, fmt.Errorf("get control plane config map %s/%s: %w", configMap.Namespace, configMap.Name, err)
+	}
+
+	_, err = controllerutil.CreateOrUpdate(ctx, r.Client, configMap, func() error {
+		configMap.Labels = childLabels(coderControlPlane, controlPlaneLabels(coderControlPlane.Name))
+		applyCommonAnnotations(configMap, coderControlPlane)
+
+		if err := controllerutil.SetControllerReference(coderControlPlane, configMap, r.Scheme); err != nil {
+			return fmt.Errorf("set controller reference: %w", err)
+		}
+
+		configMap.Data = maps.Clone(coderControlPlane.Spec.Config)
+		configMap.BinaryData = nil
+		return nil
+	})
+	if err != nil {
+		return false, fmt.Errorf("reconcile control plane config map: %w", err)
+	}
+
+	return false, nil
+}
+
+// rejectWithCondition sets conditionType to True with reason and message and
+// writes only the status, so Reconcile can stop before touching any managed
+// resource while the spec or cluster state blocks it.
+func (r *CoderControlPlaneReconciler) rejectWithCondition(
+	ctx context.Context,
+	coderControlPlane *coderv1alpha1.CoderControlPlane,
+	conditionType string,
+	reason string,
+	message string,
+) error {
+	if coderControlPlane == nil {
+		return fmt.Errorf("assertion failed: coder control plane must not be nil")
+	}
+
+	originalStatus := *coderControlPlane.Status.DeepCopy()
+	nextStatus := *coderControlPlane.Status.DeepCopy()
+	nextStatus.ObservedGeneration = coderControlPlane.Generation
+	if err := setControlPlaneCondition(
+		&nextStatus,
+		coderControlPlane.Generation,
+		conditionType,
+		metav1.ConditionTrue,
+		reason,
+		message,
+	); err != nil {
+		return err
+	}
+
+	return r.reconcileStatus(ctx, coderControlPlane, originalStatus, nextStatus)
+}
+
+// rejectConfigMapConflict records the ConfigMapConflict condition while a
+// ConfigMap this control plane does not own holds the managed config name,
+// leaving every managed resource as it is.
+func (r *CoderControlPlaneReconciler) rejectConfigMapConflict(ctx context.Context, coderControlPlane *coderv1alpha1.CoderControlPlane) error {
+	if coderControlPlane == nil {
+		return fmt.Errorf("assertion failed: coder control plane must not be nil")
+	}
+
+	return r.rejectWithCondition(
+		ctx,
+		coderControlPlane,
+		coderv1alpha1.CoderControlPlaneConditionConfigMapConflict,
+		configMapConflictReasonNotOwned,
+		fmt.Sprintf("ConfigMap %q already exists and is not owned by this control plane; rename or delete it so spec.config can be applied.", controlPlaneConfigMapName(coderControlPlane)),
+	)
+}
+
+// rejectBuiltinPostgresSecretConflict records the
+// BuiltinPostgresSecretConflict condition while a Secret the operator did not
+// create holds the builtin PostgreSQL credentials Secret name, leaving every
+// managed resource as it is.
+func (r *CoderControlPlaneReconciler) rejectBuiltinPostgresSecretConflict(ctx context.Context, coderControlPlane *coderv1alpha1.CoderControlPlane) error {
+	if coderControlPlane == nil {
+		return fmt.Errorf("assertion failed: coder control plane must not be nil")
+	}
+
+	return r.rejectWithCondition(
+		ctx,
+		coderControlPlane,
+		coderv1alpha1.CoderControlPlaneConditionBuiltinPostgresSecretConflict,
+		builtinPostgresSecretConflictReasonNotOwned,
+		fmt.Sprintf("Secret %q already exists and was not created by the operator for this control plane; rename or delete it so spec.database.builtin can be applied.", builtinPostgresName(coderControlPlane)),
+	)
+}
+
+func (r *CoderControlPlaneReconciler) cleanupOwnedConfigMap(ctx context.Context, coderControlPlane *coderv1alpha1.CoderControlPlane) error {
+	if coderControlPlane == nil {
+		return fmt.Errorf("assertion failed: coder control plane must not be nil")
+	}
+
+	configMap := &corev1.ConfigMap{}
+	namespacedName := types.NamespacedName{Name: controlPlaneConfigMapName(coderControlPlane), Namespace: coderControlPlane.Namespace}
+	err := r.Get(ctx, namespacedName, configMap)
+	switch {
+	case err == nil:
+	case apierrors.IsNotFound(err):
+		return nil
+	default:
+		return fmt.Errorf("get control plane config map %s: %w", namespacedName, err)
+	}
+
+	if !isOwnedByCoderControlPlane(configMap, coderControlPlane) {
+		return nil
+	}
+
+	if err := r.Delete(ctx, configMap); err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("delete control plane config map %s: %w", namespacedName, err)
+	}
+
+	return nil
+}
+
+func controlPlaneConfigMapName(coderControlPlane *coderv1alpha1.CoderControlPlane) string {
+	return coderControlPlane.Name + configMapSuffix
+}
+
+// controlPlaneEnvFrom returns the coder container's envFrom sources: the
+// managed ConfigMap, when spec.config is set, followed by spec.envFrom so that
+// user sources win for the same name. includeSpecEnvFrom is false when
+// policyEnvFromEnv expanded spec.envFrom into env vars instead.
+func controlPlaneEnvFrom(coderControlPlane *coderv1alpha1.CoderControlPlane, includeSpecEnvFrom bool) []corev1.EnvFromSource {
+	var specEnvFrom []corev1.EnvFromSource
+	if includeSpecEnvFrom {
+		specEnvFrom = coderControlPlane.Spec.EnvFrom
+	}
+	if len(coderControlPlane.Spec.Config) == 0 {
+		return specEnvFrom
+	}
+
+	envFrom := make([]corev1.EnvFromSource, 0, len(specEnvFrom)+1)
+	envFrom = append(envFrom, corev1.EnvFromSource{
+		ConfigMapRef: &corev1.ConfigMapEnvSource{
+			LocalObjectReference: corev1.LocalObjectReference{Name: controlPlaneConfigMapName(coderControlPlane)},
+		},
+	})
+
+	return append(envFrom, specEnvFrom...)
+}
+
+// configChecksum hashes spec.config in key order.
+func configChecksum(config map[string]string) string {
+	hasher := sha256.New()
+	for _, key := range slices.Sorted(maps.Keys(config)) {
+		_, _ = hasher.Write([]byte(key))
+		_, _ = hasher.Write([]byte{0})
+		_, _ = hasher.Write([]byte(config[key]))
+		_, _ = hasher.Write([]byte{0})
+	}
+
+	return hex.EncodeToString(hasher.Sum(nil))
+}
+
+// cleanupUnmanagedNetworking deletes the Service, mesh Service, and exposure
+// resources this control plane created before spec.manageDeployment was set
+// to false. Their selectors match operator-managed pod labels, which an
+// externally managed coderd does not carry.
+func (r *CoderControlPlaneReconciler) cleanupUnmanagedNetworking(ctx context.Context, coderControlPlane *coderv1alpha1.CoderControlPlane) error {
+	if coderControlPlane == nil {
+		return fmt.Errorf("assertion failed: coder control plane must not be nil")
+	}
+
+	r.forgetGatewayRouteUnaccepted(types.NamespacedName{Name: coderControlPlane.Name, Namespace: coderControlPlane.Namespace})
+	if err := r.cleanupOwnedIngress(ctx, coderControlPlane); err != nil {
+		return fmt.Errorf("cleanup managed ingress: %w", err)
+	}
+	if err := r.cleanupOwnedHTTPRoute(ctx, coderControlPlane); err != nil {
+		return fmt.Errorf("cleanup managed httproute: %w", err)
+	}
+	if err := r.cleanupOwnedMeshService(ctx, coderControlPlane); err != nil {
+		return err
+	}
+
+	service := &corev1.Service{}
+	namespacedName := types.NamespacedName{Name: coderControlPlane.Name, Namespace: coderControlPlane.Namespace}
+	err := r.Get(ctx, namespacedName, service)
+	switch {
+	case err == nil:
+	case apierrors.IsNotFound(err):
+		return nil
+	default:
+		return fmt.Errorf("get control plane service %s: %w", namespacedName, err)
+	}
+
+	if !isOwnedByCoderControlPlane(service, coderControlPlane) {
+		return nil
+	}
+
+	if err := r.Delete(ctx, service); err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("delete control plane service %s: %w", namespacedName, err)
+	}
+
+	return nil
+}
+
+func (r *CoderControlPlaneReconciler) cleanupOwnedMeshService(ctx context.Context, coderControlPlane *coderv1alpha1.CoderControlPlane) error {
+	if coderControlPlane == nil {
+		return fmt.Errorf("assertion failed: coder control plane must not be nil")
+	}
 
-		service.Spec.Type = serviceType
-		service.Spec.Selector = maps.Clone(labels)
-		service.Spec.Ports = servicePorts
+	service := &corev1.Service{}
+	namespacedName := types.NamespacedName{Name: meshServiceName(coderControlPlane), Namespace: coderControlPlane.Namespace}
+	err := r.Get(ctx, namespacedName, service)
+	switch {
+	case err == nil:
+	case apierrors.IsNotFound(err):
 		return nil
-	})
-	if err != nil {
-		return nil, fmt.Errorf("reconcile control plane service: %w", err)
+	default:
+		return fmt.Errorf("get control plane mesh service %s: %w", namespacedName, err)
 	}
 
-	// Avoid an immediate cached read-after-write here; cache propagation lag can
-	// transiently return NotFound for just-created objects and produce noisy reconcile errors.
-	return service, nil
+	if !isOwnedByCoderControlPlane(service, coderControlPlane) {
+		return nil
+	}
+
+	if err := r.Delete(ctx, service); err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("delete control plane mesh service %s: %w", namespacedName, err)
+	}
+
+	return nil
 }
 
-func (r *CoderControlPlaneReconciler) reconcileExposure(ctx context.Context, coderControlPlane *coderv1alpha1.CoderControlPlane) (bool, error) {
+func (r *CoderControlPlaneReconciler) reconcileExposure(ctx context.Context, coderControlPlane *coderv1alpha1.CoderControlPlane) (gatewayExposureResult, error) {
 	if coderControlPlane == nil {
-		return false, fmt.Errorf("assertion failed: coder control plane must not be nil")
+		return gatewayExposureResult{}, fmt.Errorf("assertion failed: coder control plane must not be nil")
 	}
 
 	exposeSpec := coderControlPlane.Spec.Expose
+	if exposeSpec == nil || exposeSpec.Gateway == nil {
+		r.forgetGatewayRouteUnaccepted(types.NamespacedName{Name: coderControlPlane.Name, Namespace: coderControlPlane.Namespace})
+	}
 	if exposeSpec == nil || (exposeSpec.Ingress == nil && exposeSpec.Gateway == nil) {
 		if err := r.cleanupOwnedIngress(ctx, coderControlPlane); err != nil {
-			return false, fmt.Errorf("cleanup managed ingress: %w", err)
+			return gatewayExposureResult{}, fmt.Errorf("cleanup managed ingress: %w", err)
 		}
 		if err := r.cleanupOwnedHTTPRoute(ctx, coderControlPlane); err != nil {
-			return false, fmt.Errorf("cleanup managed httproute: %w", err)
+			return gatewayExposureResult{}, fmt.Errorf("cleanup managed httproute: %w", err)
 		}
-		return false, nil
+		return gatewayExposureResult{}, nil
 	}
 
 	if exposeSpec.Ingress != nil && exposeSpec.Gateway != nil {
-		return false, fmt.Errorf("assertion failed: only one of ingress or gateway exposure may be configured")
+		return gatewayExposureResult{}, fmt.Errorf("assertion failed: only one of ingress or gateway exposure may be configured")
 	}
 
 	if exposeSpec.Ingress != nil {
 		if err := r.reconcileIngress(ctx, coderControlPlane); err != nil {
-			return false, err
+			return gatewayExposureResult{}, err
 		}
 		if err := r.cleanupOwnedHTTPRoute(ctx, coderControlPlane); err != nil {
-			return false, fmt.Errorf("cleanup managed httproute: %w", err)
+			return gatewayExposureResult{}, fmt.Errorf("cleanup managed httproute: %w", err)
 		}
-		return false, nil
+		return gatewayExposureResult{}, nil
 	}
 
-	httpRouteReconciled, err := r.reconcileHTTPRoute(ctx, coderControlPlane)
+	gatewayExposure, err := r.reconcileHTTPRoute(ctx, coderControlPlane)
 	if err != nil {
-		return false, err
+		return gatewayExposureResult{}, err
 	}
 	if err := r.cleanupOwnedIngress(ctx, coderControlPlane); err != nil {
-		return false, fmt.Errorf("cleanup managed ingress: %w", err)
+		return gatewayExposureResult{}, fmt.Errorf("cleanup managed ingress: %w", err)
 	}
 
-	return httpRouteReconciled, nil
+	return gatewayExposure, nil
 }
 
 func (r *CoderControlPlaneReconciler) reconcileIngress(ctx context.Context, coderControlPlane *coderv1alpha1.CoderControlPlane) error {
@@ -1270,6 +3079,14 @@ func (r *CoderControlPlaneReconciler) reconcileIngress(ctx context.Context, code
 	}
 
 	wildcardHost := strings.TrimSpace(ingressExpose.WildcardHost)
+	additionalHosts := make([]string, 0, len(ingressExpose.AdditionalHosts))
+	for _, host := range ingressExpose.AdditionalHosts {
+		host = strings.TrimSpace(host)
+		if host == "" || host == primaryHost || host == wildcardHost || slices.Contains(additionalHosts, host) {
+			continue
+		}
+		additionalHosts = append(additionalHosts, host)
+	}
 	backendServicePort, backendPortErr := httpRouteBackendServicePort(coderControlPlane)
 	if backendPortErr != nil {
 		return backendPortErr
@@ -1278,46 +3095,33 @@ func (r *CoderControlPlaneReconciler) reconcileIngress(ctx context.Context, code
 	ingress := &networkingv1.Ingress{ObjectMeta: metav1.ObjectMeta{Name: coderControlPlane.Name, Namespace: coderControlPlane.Namespace}}
 	_, err := controllerutil.CreateOrUpdate(ctx, r.Client, ingress, func() error {
 		labels := controlPlaneLabels(coderControlPlane.Name)
-		ingress.Labels = maps.Clone(labels)
-		ingress.Annotations = maps.Clone(ingressExpose.Annotations)
+		ingress.Labels = childLabels(coderControlPlane, labels)
+		ingress.Annotations = childAnnotations(coderControlPlane, ingressExpose.Annotations)
 
-		pathTypePrefix := networkingv1.PathTypePrefix
-		rules := []networkingv1.IngressRule{
-			{
-				Host: primaryHost,
-				IngressRuleValue: networkingv1.IngressRuleValue{
-					HTTP: &networkingv1.HTTPIngressRuleValue{
-						Paths: []networkingv1.HTTPIngressPath{
-							{
-								Path:     "/",
-								PathType: &pathTypePrefix,
-								Backend: networkingv1.IngressBackend{
-									Service: &networkingv1.IngressServiceBackend{
-										Name: coderControlPlane.Name,
-										Port: networkingv1.ServiceBackendPort{Number: backendServicePort},
-									},
-								},
-							},
-						},
-					},
-				},
+		backend := networkingv1.IngressBackend{
+			Service: &networkingv1.IngressServiceBackend{
+				Name: coderControlPlane.Name,
+				Port: networkingv1.ServiceBackendPort{Number: backendServicePort},
 			},
 		}
+		hosts := []string{primaryHost}
 		if wildcardHost != "" {
+			hosts = append(hosts, wildcardHost)
+		}
+		hosts = append(hosts, additionalHosts...)
+
+		pathTypePrefix := networkingv1.PathTypePrefix
+		rules := make([]networkingv1.IngressRule, 0, len(hosts))
+		for _, host := range hosts {
 			rules = append(rules, networkingv1.IngressRule{
-				Host: wildcardHost,
+				Host: host,
 				IngressRuleValue: networkingv1.IngressRuleValue{
 					HTTP: &networkingv1.HTTPIngressRuleValue{
 						Paths: []networkingv1.HTTPIngressPath{
 							{
 								Path:     "/",
 								PathType: &pathTypePrefix,
-								Backend: networkingv1.IngressBackend{
-									Service: &networkingv1.IngressServiceBackend{
-										Name: coderControlPlane.Name,
-										Port: networkingv1.ServiceBackendPort{Number: backendServicePort},
-									},
-								},
+								Backend:  backend,
 							},
 						},
 					},
@@ -1345,6 +3149,14 @@ func (r *CoderControlPlaneReconciler) reconcileIngress(ctx context.Context, code
 					Hosts:      []string{wildcardHost},
 				})
 			}
+
+			additionalHostsSecretName := strings.TrimSpace(ingressExpose.TLS.AdditionalHostsSecretName)
+			if additionalHostsSecretName != "" && len(additionalHosts) > 0 {
+				tls = append(tls, networkingv1.IngressTLS{
+					SecretName: additionalHostsSecretName,
+					Hosts:      slices.Clone(additionalHosts),
+				})
+			}
 		}
 
 		ingress.Spec = networkingv1.IngressSpec{
@@ -1352,6 +3164,9 @@ func (r *CoderControlPlaneReconciler) reconcileIngress(ctx context.Context, code
 			Rules:            rules,
 			TLS:              tls,
 		}
+		if ingressExpose.DefaultBackend {
+			ingress.Spec.DefaultBackend = backend.DeepCopy()
+		}
 
 		if err := controllerutil.SetControllerReference(coderControlPlane, ingress, r.Scheme); err != nil {
 			return fmt.Errorf("set controller reference: %w", err)
@@ -1366,28 +3181,33 @@ func (r *CoderControlPlaneReconciler) reconcileIngress(ctx context.Context, code
 	return nil
 }
 
-func (r *CoderControlPlaneReconciler) reconcileHTTPRoute(ctx context.Context, coderControlPlane *coderv1alpha1.CoderControlPlane) (bool, error) {
+func (r *CoderControlPlaneReconciler) reconcileHTTPRoute(ctx context.Context, coderControlPlane *coderv1alpha1.CoderControlPlane) (gatewayExposureResult, error) {
 	if coderControlPlane == nil {
-		return false, fmt.Errorf("assertion failed: coder control plane must not be nil")
+		return gatewayExposureResult{}, fmt.Errorf("assertion failed: coder control plane must not be nil")
 	}
 	if coderControlPlane.Spec.Expose == nil || coderControlPlane.Spec.Expose.Gateway == nil {
-		return false, fmt.Errorf("assertion failed: gateway exposure spec must not be nil")
+		return gatewayExposureResult{}, fmt.Errorf("assertion failed: gateway exposure spec must not be nil")
 	}
 
 	gatewayExpose := coderControlPlane.Spec.Expose.Gateway
 	primaryHost := strings.TrimSpace(gatewayExpose.Host)
 	if primaryHost == "" {
-		return false, fmt.Errorf("assertion failed: gateway host must not be empty")
+		return gatewayExposureResult{}, fmt.Errorf("assertion failed: gateway host must not be empty")
 	}
 
 	if len(gatewayExpose.ParentRefs) == 0 {
-		return false, fmt.Errorf("assertion failed: gateway parentRefs must not be empty")
+		return gatewayExposureResult{}, fmt.Errorf("assertion failed: gateway parentRefs must not be empty")
+	}
+	backendTLS := gatewayExpose.BackendTLS
+	if backendTLS != nil && !controlPlaneTLSEnabled(coderControlPlane) {
+		return gatewayExposureResult{}, fmt.Errorf("spec.expose.gateway.backendTLS requires spec.tls.secretNames")
 	}
 
 	httpRoute := &gatewayv1.HTTPRoute{ObjectMeta: metav1.ObjectMeta{Name: coderControlPlane.Name, Namespace: coderControlPlane.Namespace}}
 	_, err := controllerutil.CreateOrUpdate(ctx, r.Client, httpRoute, func() error {
 		labels := controlPlaneLabels(coderControlPlane.Name)
-		httpRoute.Labels = maps.Clone(labels)
+		httpRoute.Labels = childLabels(coderControlPlane, labels)
+		applyCommonAnnotations(httpRoute, coderControlPlane)
 
 		parentRefs := make([]gatewayv1.ParentReference, 0, len(gatewayExpose.ParentRefs))
 		for i := range gatewayExpose.ParentRefs {
@@ -1428,6 +3248,9 @@ func (r *CoderControlPlaneReconciler) reconcileHTTPRoute(ctx context.Context, co
 		if err != nil {
 			return err
 		}
+		if backendTLS != nil {
+			servicePort = controlPlaneHTTPSServicePort
+		}
 		backendPort := gatewayv1.PortNumber(servicePort)
 		serviceKind := gatewayv1.Kind("Service")
 		serviceGroup := gatewayv1.Group("")
@@ -1474,12 +3297,142 @@ func (r *CoderControlPlaneReconciler) reconcileHTTPRoute(ctx context.Context, co
 			ctrl.LoggerFrom(ctx).WithName("controller").WithName("codercontrolplane").Info(
 				"Gateway API CRDs not available, retrying HTTPRoute reconciliation",
 			)
-			return true, nil
+			r.forgetGatewayRouteUnaccepted(types.NamespacedName{Name: coderControlPlane.Name, Namespace: coderControlPlane.Namespace})
+			return gatewayExposureResult{
+				requeueAfter:      gatewayExposureRequeueInterval,
+				crdMissing:        true,
+				crdMissingChanged: r.setGatewayCRDMissing(coderControlPlane, true),
+			}, nil
+		}
+		return gatewayExposureResult{}, fmt.Errorf("reconcile control plane httproute: %w", err)
+	}
+	r.setGatewayCRDMissing(coderControlPlane, false)
+
+	if backendTLS != nil {
+		if err := r.reconcileBackendTLSPolicy(ctx, coderControlPlane); err != nil {
+			return gatewayExposureResult{}, err
+		}
+	} else if err := r.cleanupOwnedBackendTLSPolicy(ctx, coderControlPlane); err != nil {
+		return gatewayExposureResult{}, fmt.Errorf("cleanup managed backendtlspolicy: %w", err)
+	}
+
+	if httpRouteAccepted(httpRoute) {
+		r.forgetGatewayRouteUnaccepted(types.NamespacedName{Name: coderControlPlane.Name, Namespace: coderControlPlane.Namespace})
+		return gatewayExposureResult{requeueAfter: gatewayExposureRequeueInterval}, nil
+	}
+
+	unacceptedReconciles := r.observeGatewayRouteUnaccepted(coderControlPlane, httpRoute.Generation)
+	if unacceptedReconciles <= gatewayRouteMaxUnacceptedReconciles {
+		return gatewayExposureResult{requeueAfter: gatewayExposureRequeueInterval}, nil
+	}
+
+	ctrl.LoggerFrom(ctx).WithName("controller").WithName("codercontrolplane").Info(
+		"HTTPRoute has not been accepted by any Gateway controller, backing off",
+		"httproute", httpRoute.Name,
+		"unacceptedReconciles", unacceptedReconciles,
+	)
+	return gatewayExposureResult{
+		requeueAfter:      gatewayControllerMissingRequeueInterval,
+		controllerMissing: true,
+	}, nil
+}
+
+// httpRouteAccepted reports whether any parent Gateway accepted the route.
+func httpRouteAccepted(httpRoute *gatewayv1.HTTPRoute) bool {
+	if httpRoute == nil {
+		return false
+	}
+	for _, parent := range httpRoute.Status.Parents {
+		if meta.IsStatusConditionTrue(parent.Conditions, string(gatewayv1.RouteConditionAccepted)) {
+			return true
 		}
-		return false, fmt.Errorf("reconcile control plane httproute: %w", err)
 	}
 
-	return true, nil
+	return false
+}
+
+// observeGatewayRouteUnaccepted records one more reconcile that found the
+// managed HTTPRoute un-accepted and returns the consecutive count. The count
+// restarts whenever the route's generation changes.
+func (r *CoderControlPlaneReconciler) observeGatewayRouteUnaccepted(
+	coderControlPlane *coderv1alpha1.CoderControlPlane,
+	generation int64,
+) int {
+	key := types.NamespacedName{Name: coderControlPlane.Name, Namespace: coderControlPlane.Namespace}
+
+	r.gatewayRouteMu.Lock()
+	defer r.gatewayRouteMu.Unlock()
+
+	if r.gatewayRouteUnaccepted == nil {
+		r.gatewayRouteUnaccepted = map[types.NamespacedName]gatewayRouteUnacceptedCount{}
+	}
+	tracked := r.gatewayRouteUnaccepted[key]
+	if tracked.generation != generation {
+		tracked = gatewayRouteUnacceptedCount{generation: generation}
+	}
+	tracked.count++
+	r.gatewayRouteUnaccepted[key] = tracked
+
+	return tracked.count
+}
+
+func (r *CoderControlPlaneReconciler) forgetGatewayRouteUnaccepted(key types.NamespacedName) {
+	r.gatewayRouteMu.Lock()
+	defer r.gatewayRouteMu.Unlock()
+
+	delete(r.gatewayRouteUnaccepted, key)
+	delete(r.gatewayCRDMissing, key)
+}
+
+// setGatewayCRDMissing records whether the Gateway API CRDs were missing for a
+// control plane and reports whether they just became missing.
+func (r *CoderControlPlaneReconciler) setGatewayCRDMissing(
+	coderControlPlane *coderv1alpha1.CoderControlPlane,
+	missing bool,
+) bool {
+	key := types.NamespacedName{Name: coderControlPlane.Name, Namespace: coderControlPlane.Namespace}
+
+	r.gatewayRouteMu.Lock()
+	defer r.gatewayRouteMu.Unlock()
+
+	_, wasMissing := r.gatewayCRDMissing[key]
+	if !missing {
+		delete(r.gatewayCRDMissing, key)
+		return false
+	}
+	if r.gatewayCRDMissing == nil {
+		r.gatewayCRDMissing = map[types.NamespacedName]struct{}{}
+	}
+	r.gatewayCRDMissing[key] = struct{}{}
+
+	return !wasMissing
+}
+
+func setGatewayControllerMissingCondition(
+	nextStatus *coderv1alpha1.CoderControlPlaneStatus,
+	generation int64,
+	controllerMissing bool,
+) error {
+	if nextStatus == nil {
+		return fmt.Errorf("assertion failed: next status must not be nil")
+	}
+
+	if !controllerMissing {
+		meta.RemoveStatusCondition(&nextStatus.Conditions, coderv1alpha1.CoderControlPlaneConditionGatewayControllerMissing)
+		return nil
+	}
+
+	return setControlPlaneCondition(
+		nextStatus,
+		generation,
+		coderv1alpha1.CoderControlPlaneConditionGatewayControllerMissing,
+		metav1.ConditionTrue,
+		gatewayControllerMissingReasonRouteNotAccepted,
+		fmt.Sprintf(
+			"HTTPRoute has not been accepted by any parent Gateway after %d reconciles; check that a Gateway API controller is installed for the referenced Gateway",
+			gatewayRouteMaxUnacceptedReconciles,
+		),
+	)
 }
 
 func (r *CoderControlPlaneReconciler) cleanupOwnedIngress(ctx context.Context, coderControlPlane *coderv1alpha1.CoderControlPlane) error {
@@ -1509,10 +3462,112 @@ func (r *CoderControlPlaneReconciler) cleanupOwnedIngress(ctx context.Context, c
 	return nil
 }
 
+// reconcileBackendTLSPolicy makes the Gateway connect to the Coder Service
+// https port over TLS. Clusters whose Gateway API install lacks the
+// BackendTLSPolicy CRD keep the HTTPRoute and retry on the next gateway
+// requeue.
+func (r *CoderControlPlaneReconciler) reconcileBackendTLSPolicy(ctx context.Context, coderControlPlane *coderv1alpha1.CoderControlPlane) error {
+	if coderControlPlane == nil {
+		return fmt.Errorf("assertion failed: coder control plane must not be nil")
+	}
+	if coderControlPlane.Spec.Expose == nil || coderControlPlane.Spec.Expose.Gateway == nil || coderControlPlane.Spec.Expose.Gateway.BackendTLS == nil {
+		return fmt.Errorf("assertion failed: gateway backend TLS spec must not be nil")
+	}
+
+	backendTLS := coderControlPlane.Spec.Expose.Gateway.BackendTLS
+	hostname := strings.TrimSpace(backendTLS.Hostname)
+	if hostname == "" {
+		hostname = fmt.Sprintf("%s.%s.svc.cluster.local", coderControlPlane.Name, coderControlPlane.Namespace)
+	}
+
+	policy := &gatewayv1.BackendTLSPolicy{ObjectMeta: metav1.ObjectMeta{Name: coderControlPlane.Name, Namespace: coderControlPlane.Namespace}}
+	_, err := controllerutil.CreateOrUpdate(ctx, r.Client, policy, func() error {
+		policy.Labels = childLabels(coderControlPlane, controlPlaneLabels(coderControlPlane.Name))
+		applyCommonAnnotations(policy, coderControlPlane)
+
+		validation := gatewayv1.BackendTLSPolicyValidation{Hostname: gatewayv1.PreciseHostname(hostname)}
+		for _, name := range backendTLS.CACertificateRefs {
+			name = strings.TrimSpace(name)
+			if name == "" {
+				continue
+			}
+			validation.CACertificateRefs = append(validation.CACertificateRefs, gatewayv1.LocalObjectReference{
+				Group: gatewayv1.Group(""),
+				Kind:  gatewayv1.Kind("ConfigMap"),
+				Name:  gatewayv1.ObjectName(name),
+			})
+		}
+		if len(validation.CACertificateRefs) == 0 {
+			wellKnown := gatewayv1.WellKnownCACertificatesSystem
+			validation.WellKnownCACertificates = &wellKnown
+		}
+
+		sectionName := gatewayv1.SectionName("https")
+		policy.Spec = gatewayv1.BackendTLSPolicySpec{
+			TargetRefs: []gatewayv1.LocalPolicyTargetReferenceWithSectionName{{
+				LocalPolicyTargetReference: gatewayv1.LocalPolicyTargetReference{
+					Group: gatewayv1.Group(""),
+					Kind:  gatewayv1.Kind("Service"),
+					Name:  gatewayv1.ObjectName(coderControlPlane.Name),
+				},
+				SectionName: &sectionName,
+			}},
+			Validation: validation,
+		}
+
+		if err := controllerutil.SetControllerReference(coderControlPlane, policy, r.Scheme); err != nil {
+			return fmt.Errorf("set controller reference: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		if meta.IsNoMatchError(err) {
+			ctrl.LoggerFrom(ctx).WithName("controller").WithName("codercontrolplane").Info(
+				"BackendTLSPolicy CRD not available, retrying BackendTLSPolicy reconciliation",
+			)
+			return nil
+		}
+		return fmt.Errorf("reconcile control plane backendtlspolicy: %w", err)
+	}
+
+	return nil
+}
+
+func (r *CoderControlPlaneReconciler) cleanupOwnedBackendTLSPolicy(ctx context.Context, coderControlPlane *coderv1alpha1.CoderControlPlane) error {
+	if coderControlPlane == nil {
+		return fmt.Errorf("assertion failed: coder control plane must not be nil")
+	}
+
+	policy := &gatewayv1.BackendTLSPolicy{}
+	namespacedName := types.NamespacedName{Name: coderControlPlane.Name, Namespace: coderControlPlane.Namespace}
+	err := r.Get(ctx, namespacedName, policy)
+	switch {
+	case err == nil:
+	case apierrors.IsNotFound(err), meta.IsNoMatchError(err):
+		return nil
+	default:
+		return fmt.Errorf("get control plane backendtlspolicy %s: %w", namespacedName, err)
+	}
+
+	if !isOwnedByCoderControlPlane(policy, coderControlPlane) {
+		return nil
+	}
+
+	if err := r.Delete(ctx, policy); err != nil && !apierrors.IsNotFound(err) && !meta.IsNoMatchError(err) {
+		return fmt.Errorf("delete control plane backendtlspolicy %s: %w", namespacedName, err)
+	}
+
+	return nil
+}
+
 func (r *CoderControlPlaneReconciler) cleanupOwnedHTTPRoute(ctx context.Context, coderControlPlane *coderv1alpha1.CoderControlPlane) error {
 	if coderControlPlane == nil {
 		return fmt.Errorf("assertion failed: coder control plane must not be nil")
 	}
+	if err := r.cleanupOwnedBackendTLSPolicy(ctx, coderControlPlane); err != nil {
+		return fmt.Errorf("cleanup managed backendtlspolicy: %w", err)
+	}
 
 	httpRoute := &gatewayv1.HTTPRoute{}
 	namespacedName := types.NamespacedName{Name: coderControlPlane.Name, Namespace: coderControlPlane.Namespace}
@@ -1558,6 +3613,17 @@ func (r *CoderControlPlaneReconciler) desiredStatus(
 	service *corev1.Service,
 ) coderv1alpha1.CoderControlPlaneStatus {
 	nextStatus := coderControlPlane.Status
+	nextStatus.ObservedGeneration = coderControlPlane.Generation
+
+	if deployment == nil {
+		// An externally managed coderd stays Pending until
+		// reconcileExternalControlPlaneReadiness reaches it at ExternalURL.
+		nextStatus.ReadyReplicas = 0
+		nextStatus.URL = strings.TrimSpace(coderControlPlane.Spec.ExternalURL)
+		nextStatus.PublicURL = ""
+		nextStatus.Phase = coderv1alpha1.CoderControlPlanePhasePending
+		return nextStatus
+	}
 
 	servicePort := coderControlPlane.Spec.Service.Port
 	if servicePort == 0 {
@@ -1565,7 +3631,10 @@ func (r *CoderControlPlaneReconciler) desiredStatus(
 	}
 
 	phase := coderv1alpha1.CoderControlPlanePhasePending
-	if deployment.Status.ReadyReplicas > 0 {
+	switch {
+	case controlPlaneSuspended(coderControlPlane):
+		phase = coderv1alpha1.CoderControlPlanePhaseSuspended
+	case deployment.Status.ReadyReplicas > 0:
 		phase = coderv1alpha1.CoderControlPlanePhaseReady
 	}
 
@@ -1576,18 +3645,84 @@ func (r *CoderControlPlaneReconciler) desiredStatus(
 		statusPort = 443
 	}
 
-	nextStatus.ObservedGeneration = coderControlPlane.Generation
 	nextStatus.ReadyReplicas = deployment.Status.ReadyReplicas
 	nextStatus.URL = fmt.Sprintf("%s://%s.%s.svc.cluster.local:%d", scheme, service.Name, service.Namespace, statusPort)
+	nextStatus.PublicURL = controlPlaneExposedURL(coderControlPlane)
 	nextStatus.Phase = phase
 
 	return nextStatus
 }
 
+// gateOnRequiredLicense holds a Ready control plane in the Pending phase until
+// the LicenseApplied condition is True when spec.requireLicense is set. It runs
+// after the license and entitlement steps, which only act on a Ready phase.
+func gateOnRequiredLicense(
+	coderControlPlane *coderv1alpha1.CoderControlPlane,
+	nextStatus *coderv1alpha1.CoderControlPlaneStatus,
+) {
+	if !coderControlPlane.Spec.RequireLicense || nextStatus.Phase != coderv1alpha1.CoderControlPlanePhaseReady {
+		return
+	}
+	if meta.IsStatusConditionTrue(nextStatus.Conditions, coderv1alpha1.CoderControlPlaneConditionLicenseApplied) {
+		return
+	}
+
+	nextStatus.Phase = coderv1alpha1.CoderControlPlanePhasePending
+}
+
+// controlPlaneSuspended reports whether spec.replicas is explicitly 0. An
+// unset value defaults to one replica.
+func controlPlaneSuspended(coderControlPlane *coderv1alpha1.CoderControlPlane) bool {
+	return coderControlPlane.Spec.Replicas != nil && *coderControlPlane.Spec.Replicas == 0
+}
+
+// coderImageOrDefault returns image, falling back to the operator-wide default
+// and then to defaultCoderImage.
+func coderImageOrDefault(image, operatorDefault string) string {
+	if image = strings.TrimSpace(image); image != "" {
+		return image
+	}
+	if operatorDefault = strings.TrimSpace(operatorDefault); operatorDefault != "" {
+		return operatorDefault
+	}
+
+	return defaultCoderImage
+}
+
+// imagePullPolicyOrDefault returns policy, or the policy Kubernetes would
+// default to for image: Always when the image is untagged or tagged latest,
+// IfNotPresent otherwise. Setting the default explicitly keeps it in sync when
+// spec.image changes between a pinned tag and latest.
+func imagePullPolicyOrDefault(policy corev1.PullPolicy, image string) corev1.PullPolicy {
+	if policy != "" {
+		return policy
+	}
+	if imageTagMutable(image) {
+		return corev1.PullAlways
+	}
+
+	return corev1.PullIfNotPresent
+}
+
+// imageTagMutable reports whether image is untagged or tagged latest. Images
+// pinned by digest are never mutable.
+func imageTagMutable(image string) bool {
+	if strings.Contains(image, "@") {
+		return false
+	}
+
+	name := image[strings.LastIndex(image, "/")+1:]
+	tagIndex := strings.LastIndex(name, ":")
+	return tagIndex < 0 || name[tagIndex+1:] == "latest"
+}
+
 func controlPlaneSDKURL(coderControlPlane *coderv1alpha1.CoderControlPlane) string {
 	if coderControlPlane == nil {
 		return ""
 	}
+	if !controlPlaneDeploymentManaged(coderControlPlane) {
+		return strings.TrimSpace(coderControlPlane.Spec.ExternalURL)
+	}
 
 	// Always use HTTP for in-cluster SDK calls. TLS certs are typically provisioned
 	// for external hostnames and may fail verification against *.svc.cluster.local.
@@ -1623,16 +3758,38 @@ func (r *CoderControlPlaneReconciler) reconcileOperatorAccess(
 				Name: pendingSecretName,
 				Key:  coderv1alpha1.DefaultTokenSecretKey,
 			}
+			if err := setControlPlaneCondition(
+				nextStatus,
+				coderControlPlane.Generation,
+				coderv1alpha1.CoderControlPlaneConditionOperatorAccessReady,
+				metav1.ConditionFalse,
+				operatorAccessConditionReasonRevokePending,
+				fmt.Sprintf("Operator access is disabled; revoking the operator token: %v.", cleanupErr),
+			); err != nil {
+				return ctrl.Result{}, err
+			}
 			//nolint:nilerr // disabling operator access should retry cleanup without surfacing a terminal reconcile error.
 			return ctrl.Result{RequeueAfter: operatorAccessRetryInterval}, nil
 		}
 		nextStatus.OperatorTokenSecretRef = nil
+		nextStatus.OperatorUsername = ""
+		if err := setControlPlaneCondition(
+			nextStatus,
+			coderControlPlane.Generation,
+			coderv1alpha1.CoderControlPlaneConditionOperatorAccessReady,
+			metav1.ConditionFalse,
+			operatorAccessConditionReasonDisabled,
+			"Operator access is disabled by spec.operatorAccess.disabled.",
+		); err != nil {
+			return ctrl.Result{}, err
+		}
 		return ctrl.Result{}, nil
 	}
 
 	if r.OperatorAccessProvisioner == nil {
 		nextStatus.OperatorTokenSecretRef = nil
 		nextStatus.OperatorAccessReady = false
+		meta.RemoveStatusCondition(&nextStatus.Conditions, coderv1alpha1.CoderControlPlaneConditionOperatorAccessReady)
 		return ctrl.Result{}, nil
 	}
 
@@ -1656,25 +3813,105 @@ func (r *CoderControlPlaneReconciler) reconcileOperatorAccess(
 		return ctrl.Result{}, fmt.Errorf("read operator token secret %q: %w", operatorTokenSecretName, err)
 	}
 
+	// A new rotate annotation value discards the existing token so the
+	// provisioner mints a replacement and deletes the previous API key.
+	rotationRequest := strings.TrimSpace(coderControlPlane.Annotations[coderv1alpha1.RotateOperatorTokenAnnotation])
+	rotate := rotationRequest != "" && rotationRequest != nextStatus.OperatorTokenRotationRequest
+	if rotate {
+		existingToken = ""
+	}
+
 	postgresURL, resolveErr := r.resolvePostgresURLFromExtraEnv(ctx, coderControlPlane)
 	if resolveErr != nil {
 		nextStatus.OperatorTokenSecretRef = nil
 		nextStatus.OperatorAccessReady = false
+		if postgresSecretMissing(resolveErr) {
+			// The Postgres URL Secret is often created alongside the control
+			// plane, so wait for it with backoff instead of bootstrapping.
+			requeueAfter := postgresSecretRetryDelay(nextStatus, time.Now())
+			if err := setControlPlaneCondition(
+				nextStatus,
+				coderControlPlane.Generation,
+				coderv1alpha1.CoderControlPlaneConditionOperatorAccessReady,
+				metav1.ConditionFalse,
+				operatorAccessConditionReasonPostgresSecretNotFound,
+				fmt.Sprintf("Waiting for the %s Secret: %v.", postgresConnectionURLEnvVar, resolveErr),
+			); err != nil {
+				return ctrl.Result{}, err
+			}
+			return ctrl.Result{RequeueAfter: requeueAfter}, nil
+		}
+		reason := operatorAccessConditionReasonPostgresURLInvalid
+		if errors.Is(resolveErr, errPostgresURLNotConfigured) {
+			reason = operatorAccessConditionReasonPostgresURLMissing
+		}
+		if err := setControlPlaneCondition(
+			nextStatus,
+			coderControlPlane.Generation,
+			coderv1alpha1.CoderControlPlaneConditionOperatorAccessReady,
+			metav1.ConditionFalse,
+			reason,
+			fmt.Sprintf("Resolve %s: %v.", postgresConnectionURLEnvVar, resolveErr),
+		); err != nil {
+			return ctrl.Result{}, err
+		}
 		//nolint:nilerr // missing bootstrap inputs should requeue without surfacing a terminal reconcile error.
 		return ctrl.Result{RequeueAfter: operatorAccessRetryInterval}, nil
 	}
 
+	// A username change moves the token to another Coder user, so revoke the
+	// previous user's token before minting one for the new user.
+	operatorUsername := operatorAccessUsername(coderControlPlane)
+	if previousUsername := nextStatus.OperatorUsername; previousUsername != "" && previousUsername != operatorUsername {
+		revokeStarted := time.Now()
+		revokeErr := r.OperatorAccessProvisioner.RevokeOperatorToken(ctx, coderbootstrap.RevokeOperatorTokenRequest{
+			PostgresURL:      postgresURL,
+			OperatorUsername: previousUsername,
+			TokenName:        operatorTokenName,
+		})
+		observeCoderAPICall(coderControlPlane, coderAPIOperationRevokeOperatorToken, revokeStarted, revokeErr)
+		if revokeErr != nil {
+			nextStatus.OperatorAccessReady = false
+			if err := setControlPlaneCondition(
+				nextStatus,
+				coderControlPlane.Generation,
+				coderv1alpha1.CoderControlPlaneConditionOperatorAccessReady,
+				metav1.ConditionFalse,
+				operatorAccessConditionReasonRevokePending,
+				fmt.Sprintf("Revoke the operator token of previous user %q: %v.", previousUsername, revokeErr),
+			); err != nil {
+				return ctrl.Result{}, err
+			}
+			return ctrl.Result{RequeueAfter: operatorAccessRetryInterval}, nil
+		}
+		nextStatus.OperatorUsername = ""
+		existingToken = ""
+	}
+
+	provisionStarted := time.Now()
 	token, provisionErr := r.OperatorAccessProvisioner.EnsureOperatorToken(ctx, coderbootstrap.EnsureOperatorTokenRequest{
 		PostgresURL:      postgresURL,
-		OperatorUsername: defaultOperatorAccessUsername,
-		OperatorEmail:    defaultOperatorAccessEmail,
+		OperatorUsername: operatorUsername,
+		OperatorEmail:    fmt.Sprintf("%s@%s", operatorUsername, operatorAccessEmailDomain),
+		OperatorRoles:    coderControlPlane.Spec.OperatorAccess.Roles,
 		TokenName:        operatorTokenName,
 		TokenLifetime:    defaultOperatorAccessTokenLifetime,
 		ExistingToken:    existingToken,
 	})
+	observeCoderAPICall(coderControlPlane, coderAPIOperationEnsureOperatorToken, provisionStarted, provisionErr)
 	if provisionErr != nil {
 		nextStatus.OperatorTokenSecretRef = nil
 		nextStatus.OperatorAccessReady = false
+		if err := setControlPlaneCondition(
+			nextStatus,
+			coderControlPlane.Generation,
+			coderv1alpha1.CoderControlPlaneConditionOperatorAccessReady,
+			metav1.ConditionFalse,
+			operatorAccessConditionReasonProvisionError,
+			fmt.Sprintf("Provision operator token: %v.", provisionErr),
+		); err != nil {
+			return ctrl.Result{}, err
+		}
 		//nolint:nilerr // transient provisioning errors should requeue without surfacing a terminal reconcile error.
 		return ctrl.Result{RequeueAfter: operatorAccessRetryInterval}, nil
 	}
@@ -1687,6 +3924,7 @@ func (r *CoderControlPlaneReconciler) reconcileOperatorAccess(
 		coderControlPlane,
 		operatorTokenSecretName,
 		coderv1alpha1.DefaultTokenSecretKey,
+		operatorTokenName,
 		token,
 	); err != nil {
 		return ctrl.Result{}, err
@@ -1696,7 +3934,23 @@ func (r *CoderControlPlaneReconciler) reconcileOperatorAccess(
 		Name: operatorTokenSecretName,
 		Key:  coderv1alpha1.DefaultTokenSecretKey,
 	}
-	nextStatus.OperatorAccessReady = true
+	nextStatus.OperatorAccessReady = true
+	nextStatus.OperatorUsername = operatorUsername
+	if err := setControlPlaneCondition(
+		nextStatus,
+		coderControlPlane.Generation,
+		coderv1alpha1.CoderControlPlaneConditionOperatorAccessReady,
+		metav1.ConditionTrue,
+		operatorAccessConditionReasonProvisioned,
+		"Operator API access is bootstrapped.",
+	); err != nil {
+		return ctrl.Result{}, err
+	}
+	if rotate {
+		now := metav1.Now()
+		nextStatus.OperatorTokenRotatedAt = &now
+		nextStatus.OperatorTokenRotationRequest = rotationRequest
+	}
 
 	return ctrl.Result{}, nil
 }
@@ -1713,7 +3967,9 @@ func (r *CoderControlPlaneReconciler) reconcileLicense(
 		return ctrl.Result{}, fmt.Errorf("assertion failed: next status must not be nil")
 	}
 
-	if coderControlPlane.Spec.LicenseSecretRef == nil {
+	nextStatus.Licenses = pruneAppliedLicenses(nextStatus.Licenses, coderControlPlane.Spec.Licenses)
+
+	if coderControlPlane.Spec.LicenseSecretRef == nil && len(coderControlPlane.Spec.Licenses) == 0 {
 		if err := setControlPlaneCondition(
 			nextStatus,
 			coderControlPlane.Generation,
@@ -1728,17 +3984,21 @@ func (r *CoderControlPlaneReconciler) reconcileLicense(
 	}
 
 	if r.LicenseUploader == nil {
-		return ctrl.Result{}, fmt.Errorf("assertion failed: license uploader must not be nil when licenseSecretRef is configured")
+		return ctrl.Result{}, fmt.Errorf("assertion failed: license uploader must not be nil when licenses are configured")
 	}
 
 	if nextStatus.Phase != coderv1alpha1.CoderControlPlanePhaseReady {
+		message := "Waiting for control plane readiness before applying license."
+		if nextStatus.Phase == coderv1alpha1.CoderControlPlanePhaseSuspended {
+			message = "Control plane is scaled to zero replicas; the license is applied after it scales up."
+		}
 		if err := setControlPlaneCondition(
 			nextStatus,
 			coderControlPlane.Generation,
 			coderv1alpha1.CoderControlPlaneConditionLicenseApplied,
 			metav1.ConditionFalse,
 			licenseConditionReasonPending,
-			"Waiting for control plane readiness before applying license.",
+			message,
 		); err != nil {
 			return ctrl.Result{}, err
 		}
@@ -1761,7 +4021,7 @@ func (r *CoderControlPlaneReconciler) reconcileLicense(
 
 	controlPlaneURL := controlPlaneSDKURL(coderControlPlane)
 	if strings.TrimSpace(controlPlaneURL) == "" {
-		return ctrl.Result{}, fmt.Errorf("assertion failed: control plane SDK URL must not be empty when licenseSecretRef is configured")
+		return ctrl.Result{}, fmt.Errorf("assertion failed: control plane SDK URL must not be empty when licenses are configured")
 	}
 
 	operatorTokenSecretName := strings.TrimSpace(nextStatus.OperatorTokenSecretRef.Name)
@@ -1802,6 +4062,28 @@ func (r *CoderControlPlaneReconciler) reconcileLicense(
 		return ctrl.Result{RequeueAfter: operatorAccessRetryInterval}, nil
 	}
 
+	if coderControlPlane.Spec.LicenseSecretRef != nil {
+		result, err := r.applyLicenseSecretRef(ctx, coderControlPlane, nextStatus, controlPlaneURL, operatorToken)
+		if err != nil || len(coderControlPlane.Spec.Licenses) == 0 {
+			return result, err
+		}
+		if !meta.IsStatusConditionTrue(nextStatus.Conditions, coderv1alpha1.CoderControlPlaneConditionLicenseApplied) {
+			return result, nil
+		}
+	}
+
+	return r.applyStackedLicenses(ctx, coderControlPlane, nextStatus, controlPlaneURL, operatorToken)
+}
+
+// applyLicenseSecretRef uploads the license referenced by spec.licenseSecretRef
+// and tracks it in status.licenseLastApplied and status.licenseLastAppliedHash.
+func (r *CoderControlPlaneReconciler) applyLicenseSecretRef(
+	ctx context.Context,
+	coderControlPlane *coderv1alpha1.CoderControlPlane,
+	nextStatus *coderv1alpha1.CoderControlPlaneStatus,
+	controlPlaneURL string,
+	operatorToken string,
+) (ctrl.Result, error) {
 	licenseSecretName := strings.TrimSpace(coderControlPlane.Spec.LicenseSecretRef.Name)
 	if licenseSecretName == "" {
 		return ctrl.Result{}, fmt.Errorf("assertion failed: license secret name must not be empty when licenseSecretRef is configured")
@@ -1860,125 +4142,64 @@ func (r *CoderControlPlaneReconciler) reconcileLicense(
 		return ctrl.Result{}, err
 	}
 
-	if nextStatus.LicenseLastApplied != nil && nextStatus.LicenseLastAppliedHash == licenseHash {
-		hasAnyLicense, hasLicenseErr := r.LicenseUploader.HasAnyLicense(ctx, controlPlaneURL, operatorToken)
-		if hasLicenseErr != nil {
-			var sdkErr *codersdk.Error
-			if errors.As(hasLicenseErr, &sdkErr) {
-				switch sdkErr.StatusCode() {
-				case http.StatusNotFound:
-					if err := setControlPlaneCondition(
-						nextStatus,
-						coderControlPlane.Generation,
-						coderv1alpha1.CoderControlPlaneConditionLicenseApplied,
-						metav1.ConditionFalse,
-						licenseConditionReasonNotSupported,
-						"Control plane does not expose the Enterprise licenses API.",
-					); err != nil {
-						return ctrl.Result{}, err
-					}
-					return ctrl.Result{}, nil
-				case http.StatusUnauthorized, http.StatusForbidden:
-					if err := setControlPlaneCondition(
-						nextStatus,
-						coderControlPlane.Generation,
-						coderv1alpha1.CoderControlPlaneConditionLicenseApplied,
-						metav1.ConditionFalse,
-						licenseConditionReasonForbidden,
-						"Operator token is not authorized to query configured licenses.",
-					); err != nil {
-						return ctrl.Result{}, err
-					}
-					return ctrl.Result{RequeueAfter: operatorAccessRetryInterval}, nil
-				}
-			}
-			if err := setControlPlaneCondition(
-				nextStatus,
-				coderControlPlane.Generation,
-				coderv1alpha1.CoderControlPlaneConditionLicenseApplied,
-				metav1.ConditionFalse,
-				licenseConditionReasonError,
-				"Failed to query existing licenses; retrying.",
-			); err != nil {
-				return ctrl.Result{}, err
-			}
-			return ctrl.Result{RequeueAfter: operatorAccessRetryInterval}, nil
-		}
-		if hasAnyLicense {
-			if err := setControlPlaneCondition(
-				nextStatus,
-				coderControlPlane.Generation,
-				coderv1alpha1.CoderControlPlaneConditionLicenseApplied,
-				metav1.ConditionTrue,
-				licenseConditionReasonApplied,
-				"Configured license is already applied.",
-			); err != nil {
-				return ctrl.Result{}, err
-			}
-			return ctrl.Result{}, nil
-		}
+	// An unchanged value is still checked against coderd so licenses deleted
+	// out of band are re-uploaded.
+	unchanged := nextStatus.LicenseLastApplied != nil && nextStatus.LicenseLastAppliedHash == licenseHash
+	licenseKey := types.NamespacedName{Name: coderControlPlane.Name, Namespace: coderControlPlane.Namespace}
+	if !unchanged {
+		r.forgetLicenseDuplicateUploads(licenseKey)
 	}
-
-	if err := r.LicenseUploader.AddLicense(ctx, controlPlaneURL, operatorToken, licenseJWT); err != nil {
-		if isDuplicateLicenseUploadError(err) {
-			now := metav1.Now()
-			nextStatus.LicenseLastApplied = &now
-			nextStatus.LicenseLastAppliedHash = licenseHash
-			if err := setControlPlaneCondition(
-				nextStatus,
-				coderControlPlane.Generation,
-				coderv1alpha1.CoderControlPlaneConditionLicenseApplied,
-				metav1.ConditionTrue,
-				licenseConditionReasonApplied,
-				"Configured license already exists in coderd.",
-			); err != nil {
-				return ctrl.Result{}, err
-			}
-			return ctrl.Result{}, nil
-		}
-
-		var sdkErr *codersdk.Error
-		if errors.As(err, &sdkErr) {
-			switch sdkErr.StatusCode() {
-			case http.StatusNotFound:
-				if err := setControlPlaneCondition(
-					nextStatus,
-					coderControlPlane.Generation,
-					coderv1alpha1.CoderControlPlaneConditionLicenseApplied,
-					metav1.ConditionFalse,
-					licenseConditionReasonNotSupported,
-					"Control plane does not expose the Enterprise licenses API.",
-				); err != nil {
-					return ctrl.Result{}, err
-				}
-				return ctrl.Result{}, nil
-			case http.StatusUnauthorized, http.StatusForbidden:
-				if err := setControlPlaneCondition(
-					nextStatus,
-					coderControlPlane.Generation,
-					coderv1alpha1.CoderControlPlaneConditionLicenseApplied,
-					metav1.ConditionFalse,
-					licenseConditionReasonForbidden,
-					"Operator token is not authorized to upload the configured license.",
-				); err != nil {
-					return ctrl.Result{}, err
-				}
-				return ctrl.Result{RequeueAfter: operatorAccessRetryInterval}, nil
-			}
-		}
+	if result, held, err := r.holdLicenseDuplicateUpload(coderControlPlane, nextStatus, licenseKey, licenseHash); held || err != nil {
+		return result, err
+	}
+	pendingJWTs, err := r.pendingLicenseJWTs(ctx, coderControlPlane, controlPlaneURL, operatorToken, splitLicenseJWTs(licenseJWT), unchanged)
+	if err != nil {
+		return r.setLicenseSDKErrorCondition(coderControlPlane, nextStatus, err, "query configured licenses")
+	}
+	if unchanged && len(pendingJWTs) == 0 {
+		r.forgetLicenseDuplicateUploads(licenseKey)
 		if err := setControlPlaneCondition(
 			nextStatus,
 			coderControlPlane.Generation,
 			coderv1alpha1.CoderControlPlaneConditionLicenseApplied,
-			metav1.ConditionFalse,
-			licenseConditionReasonError,
-			"Failed to upload configured license; retrying.",
+			metav1.ConditionTrue,
+			licenseConditionReasonApplied,
+			"Configured license is already applied.",
 		); err != nil {
 			return ctrl.Result{}, err
 		}
-		return ctrl.Result{RequeueAfter: operatorAccessRetryInterval}, nil
+		return ctrl.Result{}, nil
+	}
+
+	uploaded := 0
+	duplicates := 0
+	for _, pendingJWT := range pendingJWTs {
+		started := time.Now()
+		err := r.LicenseUploader.AddLicense(ctx, controlPlaneURL, operatorToken, pendingJWT)
+		observeCoderAPICall(coderControlPlane, coderAPIOperationAddLicense, started, err)
+		switch {
+		case err == nil:
+			uploaded++
+		case isDuplicateLicenseUploadError(err):
+			duplicates++
+		default:
+			return r.setLicenseSDKErrorCondition(coderControlPlane, nextStatus, err, "upload the configured license")
+		}
+	}
+
+	// A duplicate error for a newly configured value is the rollback case and
+	// converges below. For a value that was already applied, coderd reported
+	// the license missing and then rejected it as present, so uploading again
+	// would loop; back off instead.
+	if unchanged && duplicates > 0 && uploaded == 0 {
+		return r.recordLicenseDuplicateUpload(coderControlPlane, nextStatus, licenseKey, licenseHash)
 	}
+	r.forgetLicenseDuplicateUploads(licenseKey)
 
+	message := "Configured license uploaded successfully."
+	if uploaded == 0 {
+		message = "Configured license already exists in coderd."
+	}
 	now := metav1.Now()
 	nextStatus.LicenseLastApplied = &now
 	nextStatus.LicenseLastAppliedHash = licenseHash
@@ -1988,7 +4209,7 @@ func (r *CoderControlPlaneReconciler) reconcileLicense(
 		coderv1alpha1.CoderControlPlaneConditionLicenseApplied,
 		metav1.ConditionTrue,
 		licenseConditionReasonApplied,
-		"Configured license uploaded successfully.",
+		message,
 	); err != nil {
 		return ctrl.Result{}, err
 	}
@@ -1996,6 +4217,117 @@ func (r *CoderControlPlaneReconciler) reconcileLicense(
 	return ctrl.Result{}, nil
 }
 
+// holdLicenseDuplicateUpload skips the license upload while a previous
+// duplicate error for licenseHash is backing off or has exhausted its attempts.
+// It reports whether the upload was held.
+func (r *CoderControlPlaneReconciler) holdLicenseDuplicateUpload(
+	coderControlPlane *coderv1alpha1.CoderControlPlane,
+	nextStatus *coderv1alpha1.CoderControlPlaneStatus,
+	key types.NamespacedName,
+	licenseHash string,
+) (ctrl.Result, bool, error) {
+	r.licenseDuplicateMu.Lock()
+	tracked, ok := r.licenseDuplicateUploads[key]
+	r.licenseDuplicateMu.Unlock()
+	if !ok || tracked.hash != licenseHash {
+		return ctrl.Result{}, false, nil
+	}
+
+	if tracked.count >= r.licenseDuplicateUploadMaxAttempts() {
+		// The license Secret watch reconciles again once the value changes.
+		err := setControlPlaneCondition(
+			nextStatus,
+			coderControlPlane.Generation,
+			coderv1alpha1.CoderControlPlaneConditionLicenseApplied,
+			metav1.ConditionFalse,
+			licenseConditionReasonStuck,
+			fmt.Sprintf(
+				"coderd rejected the configured license as a duplicate %d times but does not report it installed; update the license Secret to retry.",
+				tracked.count,
+			),
+		)
+		return ctrl.Result{}, true, err
+	}
+
+	remaining := r.licenseDuplicateUploadDelay(tracked.count) - time.Since(tracked.lastAttempt)
+	if remaining <= 0 {
+		return ctrl.Result{}, false, nil
+	}
+	return ctrl.Result{RequeueAfter: remaining}, true, nil
+}
+
+// recordLicenseDuplicateUpload counts a duplicate error for a license value
+// that was already applied and schedules the next upload attempt.
+func (r *CoderControlPlaneReconciler) recordLicenseDuplicateUpload(
+	coderControlPlane *coderv1alpha1.CoderControlPlane,
+	nextStatus *coderv1alpha1.CoderControlPlaneStatus,
+	key types.NamespacedName,
+	licenseHash string,
+) (ctrl.Result, error) {
+	r.licenseDuplicateMu.Lock()
+	if r.licenseDuplicateUploads == nil {
+		r.licenseDuplicateUploads = map[types.NamespacedName]licenseDuplicateUploadCount{}
+	}
+	tracked := r.licenseDuplicateUploads[key]
+	if tracked.hash != licenseHash {
+		tracked = licenseDuplicateUploadCount{hash: licenseHash}
+	}
+	tracked.count++
+	tracked.lastAttempt = time.Now()
+	r.licenseDuplicateUploads[key] = tracked
+	r.licenseDuplicateMu.Unlock()
+
+	if tracked.count >= r.licenseDuplicateUploadMaxAttempts() {
+		result, _, err := r.holdLicenseDuplicateUpload(coderControlPlane, nextStatus, key, licenseHash)
+		return result, err
+	}
+
+	delay := r.licenseDuplicateUploadDelay(tracked.count)
+	if err := setControlPlaneCondition(
+		nextStatus,
+		coderControlPlane.Generation,
+		coderv1alpha1.CoderControlPlaneConditionLicenseApplied,
+		metav1.ConditionFalse,
+		licenseConditionReasonError,
+		fmt.Sprintf(
+			"coderd rejected the configured license as a duplicate but does not report it installed; retrying in %s (attempt %d of %d).",
+			delay,
+			tracked.count,
+			r.licenseDuplicateUploadMaxAttempts(),
+		),
+	); err != nil {
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{RequeueAfter: delay}, nil
+}
+
+func (r *CoderControlPlaneReconciler) forgetLicenseDuplicateUploads(key types.NamespacedName) {
+	r.licenseDuplicateMu.Lock()
+	defer r.licenseDuplicateMu.Unlock()
+
+	delete(r.licenseDuplicateUploads, key)
+}
+
+func (r *CoderControlPlaneReconciler) licenseDuplicateUploadMaxAttempts() int {
+	if r.LicenseDuplicateUploadMaxAttempts > 0 {
+		return r.LicenseDuplicateUploadMaxAttempts
+	}
+	return defaultLicenseDuplicateUploadMaxAttempts
+}
+
+// licenseDuplicateUploadDelay returns the backoff after the given number of
+// duplicate errors, doubling from LicenseDuplicateUploadBackoff.
+func (r *CoderControlPlaneReconciler) licenseDuplicateUploadDelay(attempts int) time.Duration {
+	delay := r.LicenseDuplicateUploadBackoff
+	if delay <= 0 {
+		delay = defaultLicenseDuplicateUploadBackoff
+	}
+	for i := 1; i < attempts && delay < licenseDuplicateUploadMaxBackoff; i++ {
+		delay *= 2
+	}
+	return min(delay, licenseDuplicateUploadMaxBackoff)
+}
+
 func (r *CoderControlPlaneReconciler) reconcileEntitlements(
 	ctx context.Context,
 	coderControlPlane *coderv1alpha1.CoderControlPlane,
@@ -2046,17 +4378,35 @@ func (r *CoderControlPlaneReconciler) reconcileEntitlements(
 		return ctrl.Result{RequeueAfter: operatorAccessRetryInterval}, nil
 	}
 
+	started := time.Now()
 	entitlements, err := r.EntitlementsInspector.Entitlements(ctx, controlPlaneURL, operatorToken)
+	observeCoderAPICall(coderControlPlane, coderAPIOperationEntitlements, started, err)
 	if err != nil {
+		// Keep the last known tier and entitlements; a failed check says
+		// nothing about the license.
+		reason := entitlementsUnknownReasonUnreachable
+		requeueAfter := entitlementsRetryDelay(nextStatus, time.Now())
 		var sdkErr *codersdk.Error
 		if errors.As(err, &sdkErr) {
 			switch sdkErr.StatusCode() {
 			case http.StatusNotFound, http.StatusUnauthorized, http.StatusForbidden:
-				return ctrl.Result{RequeueAfter: operatorAccessRetryInterval}, nil
+				reason = entitlementsUnknownReasonRequestRejected
+				requeueAfter = operatorAccessRetryInterval
 			}
 		}
-		return ctrl.Result{RequeueAfter: operatorAccessRetryInterval}, nil
+		if err := setControlPlaneCondition(
+			nextStatus,
+			coderControlPlane.Generation,
+			coderv1alpha1.CoderControlPlaneConditionEntitlementsUnknown,
+			metav1.ConditionTrue,
+			reason,
+			fmt.Sprintf("Failed to query entitlements: %v.", err),
+		); err != nil {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{RequeueAfter: requeueAfter}, nil
 	}
+	meta.RemoveStatusCondition(&nextStatus.Conditions, coderv1alpha1.CoderControlPlaneConditionEntitlementsUnknown)
 	if entitlements.Features == nil {
 		return ctrl.Result{}, fmt.Errorf("assertion failed: entitlements features must not be nil")
 	}
@@ -2067,6 +4417,11 @@ func (r *CoderControlPlaneReconciler) reconcileEntitlements(
 	nextStatus.LicenseTier = licenseTierFromEntitlements(entitlements)
 	nextStatus.ExternalProvisionerDaemonsEntitlement = externalProvisionerDaemonsEntitlement(entitlements)
 
+	daemonsRetry, err := r.reconcileConnectedProvisionerDaemons(ctx, coderControlPlane, controlPlaneURL, operatorToken, nextStatus)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
 	shouldRefreshEntitlementsTimestamp := nextStatus.EntitlementsLastChecked == nil
 	if !shouldRefreshEntitlementsTimestamp {
 		elapsedSinceLastCheck := time.Since(nextStatus.EntitlementsLastChecked.Time)
@@ -2088,10 +4443,62 @@ func (r *CoderControlPlaneReconciler) reconcileEntitlements(
 			requeueAfter = entitlementsStatusRefreshInterval - elapsedSinceLastCheck
 		}
 	}
+	if daemonsRetry && requeueAfter > operatorAccessRetryInterval {
+		requeueAfter = operatorAccessRetryInterval
+	}
 
 	return ctrl.Result{RequeueAfter: requeueAfter}, nil
 }
 
+// reconcileConnectedProvisionerDaemons refreshes
+// status.connectedProvisionerDaemons when external provisioner daemons are
+// entitled. Like the licenses API, a control plane that does not serve the
+// provisioner daemons endpoint leaves the count at zero without retrying. It
+// reports whether a transient failure should be retried sooner.
+func (r *CoderControlPlaneReconciler) reconcileConnectedProvisionerDaemons(
+	ctx context.Context,
+	coderControlPlane *coderv1alpha1.CoderControlPlane,
+	controlPlaneURL string,
+	operatorToken string,
+	nextStatus *coderv1alpha1.CoderControlPlaneStatus,
+) (bool, error) {
+	if nextStatus == nil {
+		return false, fmt.Errorf("assertion failed: next status must not be nil")
+	}
+
+	switch nextStatus.ExternalProvisionerDaemonsEntitlement {
+	case string(codersdk.EntitlementEntitled), string(codersdk.EntitlementGracePeriod):
+	default:
+		nextStatus.ConnectedProvisionerDaemons = 0
+		return false, nil
+	}
+
+	started := time.Now()
+	count, err := r.EntitlementsInspector.ConnectedProvisionerDaemons(ctx, controlPlaneURL, operatorToken)
+	observeCoderAPICall(coderControlPlane, coderAPIOperationProvisionerDaemons, started, err)
+	if err != nil {
+		var sdkErr *codersdk.Error
+		if errors.As(err, &sdkErr) {
+			switch sdkErr.StatusCode() {
+			case http.StatusNotFound, http.StatusUnauthorized, http.StatusForbidden:
+				nextStatus.ConnectedProvisionerDaemons = 0
+				return false, nil
+			}
+		}
+		// Keep the last observed count until the endpoint answers again.
+		return true, nil
+	}
+	if count < 0 {
+		return false, fmt.Errorf("assertion failed: connected provisioner daemon count must not be negative")
+	}
+	if count > math.MaxInt32 {
+		count = math.MaxInt32
+	}
+	nextStatus.ConnectedProvisionerDaemons = int32(count)
+
+	return false, nil
+}
+
 func externalProvisionerDaemonsEntitlement(entitlements codersdk.Entitlements) string {
 	feature, ok := entitlements.Features[codersdk.FeatureExternalProvisionerDaemons]
 	if !ok {
@@ -2184,17 +4591,58 @@ func (r *CoderControlPlaneReconciler) cleanupDisabledOperatorAccess(
 	if r.OperatorAccessProvisioner == nil {
 		return fmt.Errorf("assertion failed: operator access provisioner must not be nil while disabling managed credentials")
 	}
-	if err := r.OperatorAccessProvisioner.RevokeOperatorToken(ctx, coderbootstrap.RevokeOperatorTokenRequest{
+	revokeUsername := strings.TrimSpace(coderControlPlane.Status.OperatorUsername)
+	if revokeUsername == "" {
+		revokeUsername = operatorAccessUsername(coderControlPlane)
+	}
+	revokeStarted := time.Now()
+	err = r.OperatorAccessProvisioner.RevokeOperatorToken(ctx, coderbootstrap.RevokeOperatorTokenRequest{
 		PostgresURL:      postgresURL,
-		OperatorUsername: defaultOperatorAccessUsername,
+		OperatorUsername: revokeUsername,
 		TokenName:        operatorTokenName,
-	}); err != nil {
+	})
+	observeCoderAPICall(coderControlPlane, coderAPIOperationRevokeOperatorToken, revokeStarted, err)
+	if err != nil {
 		return fmt.Errorf("revoke operator token while disabling operator access: %w", err)
 	}
 
 	return nil
 }
 
+// postgresSecretMissing reports whether err means the Secret referenced by the
+// Postgres URL secretKeyRef, or its key, does not exist yet.
+func postgresSecretMissing(err error) bool {
+	return apierrors.IsNotFound(err) || errors.Is(err, errSecretValueMissing) || errors.Is(err, errSecretValueEmpty)
+}
+
+// postgresSecretRetryDelay returns how long to wait before checking for the
+// Postgres URL Secret again. The delay matches the time already spent waiting,
+// so requeues back off roughly exponentially between the minimum and maximum.
+func postgresSecretRetryDelay(nextStatus *coderv1alpha1.CoderControlPlaneStatus, now time.Time) time.Duration {
+	condition := meta.FindStatusCondition(nextStatus.Conditions, coderv1alpha1.CoderControlPlaneConditionOperatorAccessReady)
+	if condition == nil ||
+		condition.Status != metav1.ConditionFalse ||
+		condition.Reason != operatorAccessConditionReasonPostgresSecretNotFound {
+		return postgresSecretRetryMinInterval
+	}
+
+	return min(max(now.Sub(condition.LastTransitionTime.Time), postgresSecretRetryMinInterval), postgresSecretRetryMaxInterval)
+}
+
+// entitlementsRetryDelay returns how long to wait before querying entitlements
+// again after the Coder API was unreachable. Like postgresSecretRetryDelay, the
+// delay matches the time the condition has been set.
+func entitlementsRetryDelay(nextStatus *coderv1alpha1.CoderControlPlaneStatus, now time.Time) time.Duration {
+	condition := meta.FindStatusCondition(nextStatus.Conditions, coderv1alpha1.CoderControlPlaneConditionEntitlementsUnknown)
+	if condition == nil ||
+		condition.Status != metav1.ConditionTrue ||
+		condition.Reason != entitlementsUnknownReasonUnreachable {
+		return entitlementsRetryMinInterval
+	}
+
+	return min(max(now.Sub(condition.LastTransitionTime.Time), entitlementsRetryMinInterval), entitlementsRetryMaxInterval)
+}
+
 func isManagedOperatorTokenSecret(secret *corev1.Secret, coderControlPlane *coderv1alpha1.CoderControlPlane) bool {
 	return isOwnedByCoderControlPlane(secret, coderControlPlane)
 }
@@ -2203,38 +4651,58 @@ func (r *CoderControlPlaneReconciler) resolvePostgresURLFromExtraEnv(
 	ctx context.Context,
 	coderControlPlane *coderv1alpha1.CoderControlPlane,
 ) (string, error) {
+	pgEnvVar, err := postgresURLEnvVar(coderControlPlane)
+	if err != nil {
+		return "", err
+	}
+
+	if value := strings.TrimSpace(pgEnvVar.Value); value != "" {
+		return value, nil
+	}
+
+	secretRef := pgEnvVar.ValueFrom.SecretKeyRef
+	return r.readSecretValue(ctx, coderControlPlane.Namespace, secretRef.Name, secretRef.Key)
+}
+
+// postgresURLEnvVar returns the CODER_PG_CONNECTION_URL env var coderd uses:
+// the spec.extraEnv entry when set, otherwise the operator-managed PostgreSQL
+// Secret. The result has either a literal value or a secretKeyRef.
+func postgresURLEnvVar(coderControlPlane *coderv1alpha1.CoderControlPlane) (corev1.EnvVar, error) {
 	if coderControlPlane == nil {
-		return "", fmt.Errorf("assertion failed: coder control plane must not be nil")
+		return corev1.EnvVar{}, fmt.Errorf("assertion failed: coder control plane must not be nil")
 	}
 
 	pgEnvVar, err := findEnvVar(coderControlPlane.Spec.ExtraEnv, postgresConnectionURLEnvVar)
 	if err != nil {
-		return "", err
+		return corev1.EnvVar{}, err
 	}
 	if pgEnvVar == nil {
-		return "", fmt.Errorf("%s is not configured", postgresConnectionURLEnvVar)
+		if builtinPostgresEnabled(coderControlPlane) {
+			return builtinPostgresEnv(coderControlPlane), nil
+		}
+		return corev1.EnvVar{}, errPostgresURLNotConfigured
 	}
 
-	if value := strings.TrimSpace(pgEnvVar.Value); value != "" {
-		return value, nil
+	if strings.TrimSpace(pgEnvVar.Value) != "" {
+		return *pgEnvVar, nil
 	}
 
 	if pgEnvVar.ValueFrom == nil {
-		return "", fmt.Errorf("%s must define either value or valueFrom.secretKeyRef", postgresConnectionURLEnvVar)
+		return corev1.EnvVar{}, fmt.Errorf("%s must define either value or valueFrom.secretKeyRef", postgresConnectionURLEnvVar)
 	}
 	if pgEnvVar.ValueFrom.SecretKeyRef == nil {
-		return "", fmt.Errorf("%s valueFrom must be a secretKeyRef", postgresConnectionURLEnvVar)
+		return corev1.EnvVar{}, fmt.Errorf("%s valueFrom must be a secretKeyRef", postgresConnectionURLEnvVar)
 	}
 
 	secretRef := pgEnvVar.ValueFrom.SecretKeyRef
 	if strings.TrimSpace(secretRef.Name) == "" {
-		return "", fmt.Errorf("%s secretKeyRef name must not be empty", postgresConnectionURLEnvVar)
+		return corev1.EnvVar{}, fmt.Errorf("%s secretKeyRef name must not be empty", postgresConnectionURLEnvVar)
 	}
 	if strings.TrimSpace(secretRef.Key) == "" {
-		return "", fmt.Errorf("%s secretKeyRef key must not be empty", postgresConnectionURLEnvVar)
+		return corev1.EnvVar{}, fmt.Errorf("%s secretKeyRef key must not be empty", postgresConnectionURLEnvVar)
 	}
 
-	return r.readSecretValue(ctx, coderControlPlane.Namespace, secretRef.Name, secretRef.Key)
+	return *pgEnvVar, nil
 }
 
 func (r *CoderControlPlaneReconciler) envFromDefinesEnvVar(
@@ -2344,6 +4812,14 @@ func findEnvVar(envVars []corev1.EnvVar, name string) (*corev1.EnvVar, error) {
 	return found, nil
 }
 
+// operatorAccessUsername returns the Coder user that owns the operator token.
+func operatorAccessUsername(coderControlPlane *coderv1alpha1.CoderControlPlane) string {
+	if username := strings.TrimSpace(coderControlPlane.Spec.OperatorAccess.Username); username != "" {
+		return username
+	}
+	return defaultOperatorAccessUsername
+}
+
 func operatorAccessDatabaseTokenName(coderControlPlane *coderv1alpha1.CoderControlPlane) string {
 	if coderControlPlane == nil {
 		return ""
@@ -2383,6 +4859,26 @@ func operatorAccessTokenSecretName(coderControlPlane *coderv1alpha1.CoderControl
 	return fmt.Sprintf("%s-%s%s", coderControlPlane.Name[:available], hashSuffix, operatorTokenSecretSuffix)
 }
 
+// projectedCertFileName returns the `{name}-{key}.crt` file name of a CA
+// certificate inside the consolidated projected volume. Characters outside
+// the portable file name set are replaced so every source maps to a flat path.
+func projectedCertFileName(secretName, key string) string {
+	fileName := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_', r == '.':
+			return r
+		default:
+			return '-'
+		}
+	}, fmt.Sprintf("%s-%s", secretName, strings.TrimSuffix(key, ".crt")))
+	fileName = strings.TrimLeft(fileName, ".")
+	if fileName == "" {
+		fileName = "ca"
+	}
+
+	return fileName + ".crt"
+}
+
 func volumeNameForSecret(prefix, secretName string) string {
 	normalizedSecretName := strings.TrimSpace(strings.ToLower(secretName))
 	sanitizedSecretName := sanitizeDNSLabel(normalizedSecretName)
@@ -2447,6 +4943,7 @@ func (r *CoderControlPlaneReconciler) ensureOperatorTokenSecret(
 	coderControlPlane *coderv1alpha1.CoderControlPlane,
 	name string,
 	key string,
+	tokenName string,
 	token string,
 ) error {
 	if coderControlPlane == nil {
@@ -2458,13 +4955,28 @@ func (r *CoderControlPlaneReconciler) ensureOperatorTokenSecret(
 	if strings.TrimSpace(key) == "" {
 		return fmt.Errorf("assertion failed: secret key must not be empty")
 	}
+	if strings.TrimSpace(tokenName) == "" {
+		return fmt.Errorf("assertion failed: token name must not be empty")
+	}
 	if token == "" {
 		return fmt.Errorf("assertion failed: secret token must not be empty")
 	}
 
 	secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: coderControlPlane.Namespace}}
 	_, err := controllerutil.CreateOrUpdate(ctx, r.Client, secret, func() error {
-		secret.Labels = maps.Clone(controlPlaneLabels(coderControlPlane.Name))
+		labels := controlPlaneLabels(coderControlPlane.Name)
+		labels["app.kubernetes.io/component"] = operatorTokenSecretComponent
+		secret.Labels = childLabels(coderControlPlane, labels)
+		applyCommonAnnotations(secret, coderControlPlane)
+		if secret.Annotations == nil {
+			secret.Annotations = make(map[string]string, 2)
+		}
+		secret.Annotations[operatorTokenNameAnnotation] = tokenName
+		// Only a new token value moves the creation time, so validating an
+		// existing token on every reconcile leaves the Secret unchanged.
+		if string(secret.Data[key]) != token || secret.Annotations[operatorTokenCreatedAtAnnotation] == "" {
+			secret.Annotations[operatorTokenCreatedAtAnnotation] = time.Now().UTC().Format(time.RFC3339)
+		}
 		secret.Type = corev1.SecretTypeOpaque
 		if secret.Data == nil {
 			secret.Data = make(map[string][]byte)
@@ -2562,16 +5074,24 @@ func mergeResults(results ...ctrl.Result) ctrl.Result {
 
 func indexByLicenseSecretName(obj client.Object) []string {
 	coderControlPlane, ok := obj.(*coderv1alpha1.CoderControlPlane)
-	if !ok || coderControlPlane.Spec.LicenseSecretRef == nil {
+	if !ok {
 		return nil
 	}
 
-	licenseSecretName := strings.TrimSpace(coderControlPlane.Spec.LicenseSecretRef.Name)
-	if licenseSecretName == "" {
+	licenseSecretNames := make([]string, 0, len(coderControlPlane.Spec.Licenses)+1)
+	if coderControlPlane.Spec.LicenseSecretRef != nil {
+		licenseSecretNames = append(licenseSecretNames, strings.TrimSpace(coderControlPlane.Spec.LicenseSecretRef.Name))
+	}
+	for _, selector := range coderControlPlane.Spec.Licenses {
+		licenseSecretNames = append(licenseSecretNames, strings.TrimSpace(selector.Name))
+	}
+	licenseSecretNames = slices.DeleteFunc(licenseSecretNames, func(name string) bool { return name == "" })
+	if len(licenseSecretNames) == 0 {
 		return nil
 	}
+	slices.Sort(licenseSecretNames)
 
-	return []string{licenseSecretName}
+	return slices.Compact(licenseSecretNames)
 }
 
 func indexByEnvFromConfigMapName(obj client.Object) []string {
@@ -2601,6 +5121,15 @@ func indexByEnvFromConfigMapName(obj client.Object) []string {
 	return indexedNames
 }
 
+func indexByMountedSecretName(obj client.Object) []string {
+	coderControlPlane, ok := obj.(*coderv1alpha1.CoderControlPlane)
+	if !ok {
+		return nil
+	}
+
+	return mountedSecretNames(coderControlPlane)
+}
+
 func indexByEnvFromSecretName(obj client.Object) []string {
 	coderControlPlane, ok := obj.(*coderv1alpha1.CoderControlPlane)
 	if !ok {
@@ -2637,7 +5166,16 @@ func (r *CoderControlPlaneReconciler) reconcileRequestsForEnvFromConfigMap(
 		return nil
 	}
 
-	return r.reconcileRequestsForIndexedControlPlanes(ctx, configMap.Namespace, envFromConfigMapNameFieldIndex, configMap.Name)
+	requests := r.reconcileRequestsForIndexedControlPlanes(ctx, configMap.Namespace, envFromConfigMapNameFieldIndex, configMap.Name)
+	// A control plane blocked by a foreign ConfigMap holding its managed config
+	// name retries once that ConfigMap changes or is deleted.
+	if name, ok := strings.CutSuffix(configMap.Name, configMapSuffix); ok && name != "" {
+		requests = mergeReconcileRequests(requests, []reconcile.Request{{
+			NamespacedName: types.NamespacedName{Name: name, Namespace: configMap.Namespace},
+		}})
+	}
+
+	return requests
 }
 
 func (r *CoderControlPlaneReconciler) reconcileRequestsForEnvFromSecret(
@@ -2734,8 +5272,14 @@ func (r *CoderControlPlaneReconciler) reconcileRequestsForLicenseSecret(
 		secret.Name,
 	)
 	envFromSecretRequests := r.reconcileRequestsForEnvFromSecret(ctx, secret)
+	mountedSecretRequests := r.reconcileRequestsForIndexedControlPlanes(
+		ctx,
+		secret.Namespace,
+		mountedSecretNameFieldIndex,
+		secret.Name,
+	)
 
-	return mergeReconcileRequests(licenseSecretRequests, envFromSecretRequests)
+	return mergeReconcileRequests(licenseSecretRequests, envFromSecretRequests, mountedSecretRequests)
 }
 
 func isDuplicateLicenseUploadError(err error) bool {
@@ -2778,18 +5322,36 @@ func mergeControlPlaneStatusDelta(
 	if baseStatus.URL != nextStatus.URL {
 		mergedStatus.URL = nextStatus.URL
 	}
+	if baseStatus.PublicURL != nextStatus.PublicURL {
+		mergedStatus.PublicURL = nextStatus.PublicURL
+	}
 	if !equality.Semantic.DeepEqual(baseStatus.OperatorTokenSecretRef, nextStatus.OperatorTokenSecretRef) {
 		mergedStatus.OperatorTokenSecretRef = cloneSecretKeySelector(nextStatus.OperatorTokenSecretRef)
 	}
 	if baseStatus.OperatorAccessReady != nextStatus.OperatorAccessReady {
 		mergedStatus.OperatorAccessReady = nextStatus.OperatorAccessReady
 	}
+	if baseStatus.MigrationJobName != nextStatus.MigrationJobName {
+		mergedStatus.MigrationJobName = nextStatus.MigrationJobName
+	}
+	if !equality.Semantic.DeepEqual(baseStatus.OperatorTokenRotatedAt, nextStatus.OperatorTokenRotatedAt) {
+		mergedStatus.OperatorTokenRotatedAt = cloneMetav1Time(nextStatus.OperatorTokenRotatedAt)
+	}
+	if baseStatus.OperatorTokenRotationRequest != nextStatus.OperatorTokenRotationRequest {
+		mergedStatus.OperatorTokenRotationRequest = nextStatus.OperatorTokenRotationRequest
+	}
 	if !equality.Semantic.DeepEqual(baseStatus.LicenseLastApplied, nextStatus.LicenseLastApplied) {
 		mergedStatus.LicenseLastApplied = cloneMetav1Time(nextStatus.LicenseLastApplied)
 	}
 	if baseStatus.LicenseLastAppliedHash != nextStatus.LicenseLastAppliedHash {
 		mergedStatus.LicenseLastAppliedHash = nextStatus.LicenseLastAppliedHash
 	}
+	if !equality.Semantic.DeepEqual(baseStatus.Licenses, nextStatus.Licenses) {
+		mergedStatus.Licenses = nil
+		for _, entry := range nextStatus.Licenses {
+			mergedStatus.Licenses = append(mergedStatus.Licenses, *entry.DeepCopy())
+		}
+	}
 	if baseStatus.LicenseTier != nextStatus.LicenseTier {
 		mergedStatus.LicenseTier = nextStatus.LicenseTier
 	}
@@ -2799,6 +5361,9 @@ func mergeControlPlaneStatusDelta(
 	if baseStatus.ExternalProvisionerDaemonsEntitlement != nextStatus.ExternalProvisionerDaemonsEntitlement {
 		mergedStatus.ExternalProvisionerDaemonsEntitlement = nextStatus.ExternalProvisionerDaemonsEntitlement
 	}
+	if baseStatus.ConnectedProvisionerDaemons != nextStatus.ConnectedProvisionerDaemons {
+		mergedStatus.ConnectedProvisionerDaemons = nextStatus.ConnectedProvisionerDaemons
+	}
 	if baseStatus.Phase != nextStatus.Phase {
 		mergedStatus.Phase = nextStatus.Phase
 	}
@@ -2826,6 +5391,85 @@ func cloneMetav1Time(timestamp *metav1.Time) *metav1.Time {
 	return timestamp.DeepCopy()
 }
 
+// recordTransitionEvents emits Events for state changes between the persisted
+// and reconciled status. Events are only emitted on transitions so periodic
+// requeues do not repeat them.
+func (r *CoderControlPlaneReconciler) recordTransitionEvents(
+	coderControlPlane *coderv1alpha1.CoderControlPlane,
+	originalStatus coderv1alpha1.CoderControlPlaneStatus,
+	nextStatus coderv1alpha1.CoderControlPlaneStatus,
+	gatewayExposure gatewayExposureResult,
+) {
+	if r.Recorder == nil || coderControlPlane == nil {
+		return
+	}
+
+	if nextStatus.OperatorAccessReady && !originalStatus.OperatorAccessReady {
+		r.Recorder.Eventf(coderControlPlane, nil, corev1.EventTypeNormal, eventReasonOperatorTokenProvisioned, eventActionReconcile,
+			"Provisioned operator API token")
+	}
+	if nextStatus.OperatorTokenRotationRequest != "" && nextStatus.OperatorTokenSecretRef != nil &&
+		nextStatus.OperatorTokenRotationRequest != originalStatus.OperatorTokenRotationRequest {
+		r.Recorder.Eventf(coderControlPlane, nil, corev1.EventTypeNormal, eventReasonOperatorTokenRotated, eventActionReconcile,
+			"Rotated operator API token in Secret %q", nextStatus.OperatorTokenSecretRef.Name)
+	}
+
+	if nextStatus.LicenseLastAppliedHash != "" && nextStatus.LicenseLastAppliedHash != originalStatus.LicenseLastAppliedHash {
+		r.Recorder.Eventf(coderControlPlane, nil, corev1.EventTypeNormal, eventReasonLicenseApplied, eventActionReconcile,
+			"Applied license from Secret %q", licenseSecretName(coderControlPlane))
+	}
+	for _, entry := range nextStatus.Licenses {
+		if findAppliedLicense(originalStatus.Licenses, entry.SecretName, entry.Key, entry.Hash) >= 0 {
+			continue
+		}
+		r.Recorder.Eventf(coderControlPlane, nil, corev1.EventTypeNormal, eventReasonLicenseApplied, eventActionReconcile,
+			"Applied license from Secret %q", entry.SecretName)
+	}
+
+	if licenseConditionReason(nextStatus) == licenseConditionReasonNotSupported &&
+		licenseConditionReason(originalStatus) != licenseConditionReasonNotSupported {
+		r.Recorder.Eventf(coderControlPlane, nil, corev1.EventTypeWarning, eventReasonLicenseNotSupported, eventActionReconcile,
+			"Coder deployment does not support licenses; use an Enterprise or Premium image")
+	}
+
+	if entitlementsKnown(originalStatus) && entitlementsKnown(nextStatus) &&
+		(originalStatus.LicenseTier != nextStatus.LicenseTier ||
+			originalStatus.ExternalProvisionerDaemonsEntitlement != nextStatus.ExternalProvisionerDaemonsEntitlement) {
+		r.Recorder.Eventf(coderControlPlane, nil, corev1.EventTypeNormal, eventReasonEntitlementsChanged, eventActionReconcile,
+			"Entitlements changed: license tier %s -> %s, external provisioner daemons %s -> %s",
+			originalStatus.LicenseTier, nextStatus.LicenseTier,
+			originalStatus.ExternalProvisionerDaemonsEntitlement, nextStatus.ExternalProvisionerDaemonsEntitlement)
+	}
+
+	if gatewayExposure.crdMissingChanged {
+		r.Recorder.Eventf(coderControlPlane, nil, corev1.EventTypeWarning, eventReasonGatewayCRDMissing, eventActionReconcile,
+			"Gateway API CRDs are not installed; HTTPRoute reconciliation will be retried")
+	}
+}
+
+func licenseSecretName(coderControlPlane *coderv1alpha1.CoderControlPlane) string {
+	if coderControlPlane == nil || coderControlPlane.Spec.LicenseSecretRef == nil {
+		return ""
+	}
+
+	return coderControlPlane.Spec.LicenseSecretRef.Name
+}
+
+func licenseConditionReason(status coderv1alpha1.CoderControlPlaneStatus) string {
+	condition := meta.FindStatusCondition(status.Conditions, coderv1alpha1.CoderControlPlaneConditionLicenseApplied)
+	if condition == nil {
+		return ""
+	}
+
+	return condition.Reason
+}
+
+func entitlementsKnown(status coderv1alpha1.CoderControlPlaneStatus) bool {
+	return status.LicenseTier != "" &&
+		status.LicenseTier != coderv1alpha1.CoderControlPlaneLicenseTierUnknown &&
+		status.ExternalProvisionerDaemonsEntitlement != coderv1alpha1.CoderControlPlaneEntitlementUnknown
+}
+
 func (r *CoderControlPlaneReconciler) reconcileStatus(
 	ctx context.Context,
 	coderControlPlane *coderv1alpha1.CoderControlPlane,
@@ -2925,16 +5569,33 @@ func (r *CoderControlPlaneReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	); err != nil {
 		return fmt.Errorf("index coder control planes by envFrom Secret name: %w", err)
 	}
+	if err := mgr.GetFieldIndexer().IndexField(
+		context.Background(),
+		&coderv1alpha1.CoderControlPlane{},
+		mountedSecretNameFieldIndex,
+		indexByMountedSecretName,
+	); err != nil {
+		return fmt.Errorf("index coder control planes by mounted Secret name: %w", err)
+	}
 
 	builder := ctrl.NewControllerManagedBy(mgr).
-		For(&coderv1alpha1.CoderControlPlane{}).
+		For(
+			&coderv1alpha1.CoderControlPlane{},
+			ctrlbuilder.WithPredicates(predicate.NewPredicateFuncs(func(obj client.Object) bool {
+				return watchesNamespace(r.WatchNamespaces, obj.GetNamespace())
+			})),
+		).
 		Owns(&appsv1.Deployment{}).
+		Owns(&batchv1.Job{}).
+		Owns(&batchv1.CronJob{}).
+		Owns(&appsv1.StatefulSet{}).
 		Owns(&corev1.Service{}).
 		Owns(&networkingv1.Ingress{}).
 		Owns(&corev1.ServiceAccount{}).
 		Owns(&rbacv1.Role{}).
 		Owns(&rbacv1.RoleBinding{}).
 		Owns(&corev1.Secret{}).
+		Owns(&corev1.ConfigMap{}).
 		Watches(
 			&corev1.Secret{},
 			handler.EnqueueRequestsFromMapFunc(r.reconcileRequestsForLicenseSecret),
@@ -2942,6 +5603,14 @@ func (r *CoderControlPlaneReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		Watches(
 			&corev1.ConfigMap{},
 			handler.EnqueueRequestsFromMapFunc(r.reconcileRequestsForEnvFromConfigMap),
+		).
+		Watches(
+			&rbacv1.Role{},
+			handler.EnqueueRequestsFromMapFunc(reconcileRequestsForWorkspaceRBAC),
+		).
+		Watches(
+			&rbacv1.RoleBinding{},
+			handler.EnqueueRequestsFromMapFunc(reconcileRequestsForWorkspaceRBAC),
 		)
 
 	// Gateway API is optional; only watch HTTPRoutes when the CRD is installed.
@@ -2957,6 +5626,12 @@ func (r *CoderControlPlaneReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		Complete(r)
 }
 
+// watchesNamespace reports whether objects in namespace are reconciled given
+// the configured watchNamespaces; an empty list watches every namespace.
+func watchesNamespace(watchNamespaces []string, namespace string) bool {
+	return len(watchNamespaces) == 0 || slices.Contains(watchNamespaces, namespace)
+}
+
 func controlPlaneLabels(name string) map[string]string {
 	return map[string]string{
 		"app.kubernetes.io/name":       "coder-control-plane",
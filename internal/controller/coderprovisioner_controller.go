@@ -27,9 +27,11 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
+	ctrlbuilder "sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	coderv1alpha1 "github.com/coder/coder-k8s/api/v1alpha1"
@@ -63,6 +65,15 @@ type CoderProvisionerReconciler struct {
 	client.Client
 	Scheme          *runtime.Scheme
 	BootstrapClient coderbootstrap.Client
+
+	// DefaultImage is used when neither the provisioner nor its control plane
+	// sets an image. When empty, ghcr.io/coder/coder:latest is used.
+	DefaultImage string
+
+	// WatchNamespaces limits the provisioners the reconciler acts on to these
+	// namespaces, matching the control planes it can read. Provisioners in
+	// other namespaces are ignored. When empty, every namespace is reconciled.
+	WatchNamespaces []string
 }
 
 // +kubebuilder:rbac:groups=coder.com,resources=coderprovisioners,verbs=get;list;watch;create;update;patch;delete
@@ -84,6 +95,9 @@ func (r *CoderProvisionerReconciler) Reconcile(ctx context.Context, req ctrl.Req
 	if r.BootstrapClient == nil {
 		return ctrl.Result{}, fmt.Errorf("assertion failed: reconciler bootstrap client must not be nil")
 	}
+	if !watchesNamespace(r.WatchNamespaces, req.Namespace) {
+		return ctrl.Result{}, nil
+	}
 
 	provisioner := &coderv1alpha1.CoderProvisioner{}
 	defer func() {
@@ -209,6 +223,11 @@ func (r *CoderProvisionerReconciler) Reconcile(ctx context.Context, req ctrl.Req
 		if statusSnapshot == nil {
 			return ctrl.Result{}, fmt.Errorf("assertion failed: status snapshot must not be nil")
 		}
+		if provisionerNotEntitled(provisioner) {
+			if err := r.removeDeploymentForLostEntitlement(ctx, provisioner); err != nil {
+				return ctrl.Result{}, err
+			}
+		}
 		if !equality.Semantic.DeepEqual(*statusSnapshot, provisioner.Status) {
 			_ = r.Status().Update(ctx, provisioner)
 		}
@@ -597,9 +616,7 @@ func (r *CoderProvisionerReconciler) Reconcile(ctx context.Context, req ctrl.Req
 	if image == "" {
 		image = controlPlane.Spec.Image
 	}
-	if image == "" {
-		image = defaultCoderImage
-	}
+	image = coderImageOrDefault(image, r.DefaultImage)
 
 	secretRef := &coderv1alpha1.SecretKeySelector{Name: keySecretName, Key: keySecretKey}
 	deployment, err := r.reconcileDeployment(ctx, provisioner, image, controlPlane.Status.URL, secretRef, serviceAccountName, secretChecksum)
@@ -892,6 +909,49 @@ func (r *CoderProvisionerReconciler) reconcileExternalProvisionerEntitlement(
 	return ctrl.Result{}, nil
 }
 
+// provisionerNotEntitled reports whether the last entitlement check found the
+// Coder deployment is not entitled to external provisioner daemons.
+func provisionerNotEntitled(provisioner *coderv1alpha1.CoderProvisioner) bool {
+	condition := meta.FindStatusCondition(provisioner.Status.Conditions, coderv1alpha1.CoderProvisionerConditionExternalProvisionersEntitled)
+	return condition != nil && condition.Status == metav1.ConditionFalse && condition.Reason == "NotEntitled"
+}
+
+// removeDeploymentForLostEntitlement deletes the provisioner Deployment once
+// the Coder deployment is no longer entitled to external provisioners, since
+// coderd rejects the daemons. The provisioner key and RBAC are kept so the
+// Deployment is recreated when the entitlement returns.
+func (r *CoderProvisionerReconciler) removeDeploymentForLostEntitlement(
+	ctx context.Context,
+	provisioner *coderv1alpha1.CoderProvisioner,
+) error {
+	if provisioner == nil {
+		return fmt.Errorf("assertion failed: coder provisioner must not be nil")
+	}
+
+	deployment := &appsv1.Deployment{}
+	namespacedName := types.NamespacedName{Name: provisionerResourceName(provisioner.Name), Namespace: provisioner.Namespace}
+	if err := r.Get(ctx, namespacedName, deployment); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("get provisioner deployment %s: %w", namespacedName, err)
+	}
+	if !metav1.IsControlledBy(deployment, provisioner) {
+		return nil
+	}
+
+	if err := r.Delete(ctx, deployment); err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("delete provisioner deployment %s: %w", namespacedName, err)
+	}
+	ctrl.LoggerFrom(ctx).Info("deleted provisioner deployment after external provisioner entitlement was lost",
+		"deployment", namespacedName)
+
+	provisioner.Status.ReadyReplicas = 0
+	provisioner.Status.Phase = coderv1alpha1.CoderProvisionerPhasePending
+
+	return nil
+}
+
 func (r *CoderProvisionerReconciler) ensureProvisionerKeySecret(
 	ctx context.Context,
 	provisioner *coderv1alpha1.CoderProvisioner,
@@ -1250,7 +1310,12 @@ func (r *CoderProvisionerReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	}
 
 	return ctrl.NewControllerManagedBy(mgr).
-		For(&coderv1alpha1.CoderProvisioner{}).
+		For(
+			&coderv1alpha1.CoderProvisioner{},
+			ctrlbuilder.WithPredicates(predicate.NewPredicateFuncs(func(obj client.Object) bool {
+				return watchesNamespace(r.WatchNamespaces, obj.GetNamespace())
+			})),
+		).
 		Owns(&appsv1.Deployment{}).
 		Owns(&corev1.Secret{}).
 		Owns(&corev1.ServiceAccount{}).
@@ -385,6 +385,51 @@ func TestCoderProvisionerReconciler_EntitlementFallbackForbidden(t *testing.T) {
 	require.Equal(t, "Forbidden", condition.Reason)
 }
 
+func TestCoderProvisionerReconciler_EntitlementLostDeletesDeployment(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	namespace := createTestNamespace(ctx, t, "coderprov-ent-lost")
+	controlPlane := createTestControlPlane(ctx, t, namespace, "controlplane-ent-lost", "https://coder.example.com")
+
+	bootstrapClient := &fakeBootstrapClient{
+		provisionerKeyResponses: []coderbootstrap.EnsureProvisionerKeyResponse{{
+			OrganizationID: uuid.New(),
+			KeyID:          uuid.New(),
+			KeyName:        "provisioner-ent-lost",
+			Key:            "provisioner-key-material",
+		}},
+	}
+	reconciler := &controller.CoderProvisionerReconciler{Client: k8sClient, Scheme: scheme, BootstrapClient: bootstrapClient}
+	provisioner := createTestProvisioner(ctx, t, namespace, "provisioner-ent-lost", controlPlane.Name)
+	namespacedName := types.NamespacedName{Name: provisioner.Name, Namespace: provisioner.Namespace}
+	deploymentName := types.NamespacedName{Name: expectedProvisionerResourceName(provisioner.Name), Namespace: provisioner.Namespace}
+
+	reconcileProvisioner(ctx, t, reconciler, namespacedName)
+	reconcileProvisioner(ctx, t, reconciler, namespacedName)
+	require.NoError(t, k8sClient.Get(ctx, deploymentName, &appsv1.Deployment{}))
+
+	require.NoError(t, k8sClient.Get(ctx, types.NamespacedName{Name: controlPlane.Name, Namespace: namespace}, controlPlane))
+	now := metav1.Now()
+	controlPlane.Status.EntitlementsLastChecked = &now
+	controlPlane.Status.ExternalProvisionerDaemonsEntitlement = string(codersdk.EntitlementNotEntitled)
+	require.NoError(t, k8sClient.Status().Update(ctx, controlPlane))
+
+	result, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: namespacedName})
+	require.NoError(t, err)
+	require.Greater(t, result.RequeueAfter, time.Duration(0))
+
+	err = k8sClient.Get(ctx, deploymentName, &appsv1.Deployment{})
+	require.True(t, apierrors.IsNotFound(err), "expected provisioner deployment to be deleted, got %v", err)
+
+	reconciled := &coderv1alpha1.CoderProvisioner{}
+	require.NoError(t, k8sClient.Get(ctx, namespacedName, reconciled))
+	require.Equal(t, coderv1alpha1.CoderProvisionerPhasePending, reconciled.Status.Phase)
+	require.Equal(t, int32(0), reconciled.Status.ReadyReplicas)
+	condition := findCondition(t, reconciled.Status.Conditions, coderv1alpha1.CoderProvisionerConditionExternalProvisionersEntitled)
+	require.Equal(t, "NotEntitled", condition.Reason)
+}
+
 func TestCoderProvisionerReconciler_BasicCreate(t *testing.T) {
 	t.Parallel()
 
@@ -735,6 +780,41 @@ func TestCoderProvisionerReconciler_DeletionControlPlaneGone(t *testing.T) {
 	}, 5*time.Second, 100*time.Millisecond)
 }
 
+func TestCoderProvisionerReconciler_IgnoresProvisionersOutsideWatchNamespaces(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	namespace := createTestNamespace(ctx, t, "coderprov-unwatched")
+	controlPlane := createTestControlPlane(ctx, t, namespace, "controlplane-unwatched", "https://coder.example.com")
+
+	bootstrapClient := &fakeBootstrapClient{}
+	reconciler := &controller.CoderProvisionerReconciler{
+		Client:          k8sClient,
+		Scheme:          scheme,
+		BootstrapClient: bootstrapClient,
+		WatchNamespaces: []string{"coder-watched"},
+	}
+	provisioner := createTestProvisioner(ctx, t, namespace, "provisioner-unwatched", controlPlane.Name)
+	namespacedName := types.NamespacedName{Name: provisioner.Name, Namespace: provisioner.Namespace}
+
+	for range 2 {
+		result, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: namespacedName})
+		require.NoError(t, err)
+		require.Equal(t, ctrl.Result{}, result)
+	}
+	require.Equal(t, 0, bootstrapClient.entitlementsCalls)
+	require.Equal(t, 0, bootstrapClient.provisionerKeyCalls)
+
+	ignored := &coderv1alpha1.CoderProvisioner{}
+	require.NoError(t, k8sClient.Get(ctx, namespacedName, ignored))
+	require.Empty(t, ignored.Finalizers)
+	require.Empty(t, ignored.Status.Conditions)
+
+	deployment := &appsv1.Deployment{}
+	err := k8sClient.Get(ctx, types.NamespacedName{Name: expectedProvisionerResourceName(provisioner.Name), Namespace: provisioner.Namespace}, deployment)
+	require.True(t, apierrors.IsNotFound(err))
+}
+
 func TestCoderProvisionerReconciler_NotFound(t *testing.T) {
 	t.Parallel()
 
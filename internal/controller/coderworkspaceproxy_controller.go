@@ -16,8 +16,10 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	ctrl "sigs.k8s.io/controller-runtime"
+	ctrlbuilder "sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 
 	coderv1alpha1 "github.com/coder/coder-k8s/api/v1alpha1"
 	"github.com/coder/coder-k8s/internal/coderbootstrap"
@@ -34,6 +36,15 @@ type CoderWorkspaceProxyReconciler struct {
 	client.Client
 	Scheme          *runtime.Scheme
 	BootstrapClient coderbootstrap.Client
+
+	// DefaultImage is used when spec.image is empty. When empty,
+	// ghcr.io/coder/coder:latest is used.
+	DefaultImage string
+
+	// WatchNamespaces limits the workspace proxies the reconciler acts on to
+	// these namespaces. Proxies in other namespaces are ignored. When empty,
+	// every namespace is reconciled.
+	WatchNamespaces []string
 }
 
 // +kubebuilder:rbac:groups=coder.com,resources=coderworkspaceproxies,verbs=get;list;watch;create;update;patch;delete
@@ -51,6 +62,9 @@ func (r *CoderWorkspaceProxyReconciler) Reconcile(ctx context.Context, req ctrl.
 	if r.Scheme == nil {
 		return ctrl.Result{}, fmt.Errorf("assertion failed: reconciler scheme must not be nil")
 	}
+	if !watchesNamespace(r.WatchNamespaces, req.Namespace) {
+		return ctrl.Result{}, nil
+	}
 
 	workspaceProxy := &coderv1alpha1.CoderWorkspaceProxy{}
 	if err := r.Get(ctx, req.NamespacedName, workspaceProxy); err != nil {
@@ -184,10 +198,7 @@ func (r *CoderWorkspaceProxyReconciler) reconcileDeployment(
 			replicas = *workspaceProxy.Spec.Replicas
 		}
 
-		image := workspaceProxy.Spec.Image
-		if image == "" {
-			image = defaultCoderImage
-		}
+		image := coderImageOrDefault(workspaceProxy.Spec.Image, r.DefaultImage)
 
 		args := []string{"wsproxy", "server", "--http-address=0.0.0.0:3001"}
 		if workspaceProxy.Spec.DerpOnly {
@@ -254,14 +265,31 @@ func (r *CoderWorkspaceProxyReconciler) reconcileService(ctx context.Context, wo
 			servicePort = defaultWorkspaceProxyPort
 		}
 
-		service.Spec.Type = serviceType
-		service.Spec.Selector = maps.Clone(labels)
-		service.Spec.Ports = []corev1.ServicePort{{
+		targetPort := intstr.FromInt(int(workspaceProxyTargetPort))
+		if override := workspaceProxy.Spec.Service.TargetPort; override != nil {
+			if *override != intstr.FromString("http") && *override != targetPort {
+				return fmt.Errorf("spec.service.targetPort %q does not match a port exposed by the workspace proxy container", override.String())
+			}
+			targetPort = *override
+		}
+
+		primaryServicePort := corev1.ServicePort{
 			Name:       "http",
 			Port:       servicePort,
 			Protocol:   corev1.ProtocolTCP,
-			TargetPort: intstr.FromInt(int(workspaceProxyTargetPort)),
-		}}
+			TargetPort: targetPort,
+		}
+		// The workspace proxy container only serves plain HTTP.
+		if appProtocol := workspaceProxy.Spec.Service.AppProtocol; appProtocol != "" {
+			if appProtocol == serviceAppProtocolAuto {
+				appProtocol = "http"
+			}
+			primaryServicePort.AppProtocol = &appProtocol
+		}
+
+		service.Spec.Type = serviceType
+		service.Spec.Selector = maps.Clone(labels)
+		service.Spec.Ports = []corev1.ServicePort{primaryServicePort}
 		return nil
 	})
 	if err != nil {
@@ -367,7 +395,12 @@ func (r *CoderWorkspaceProxyReconciler) SetupWithManager(mgr ctrl.Manager) error
 	}
 
 	return ctrl.NewControllerManagedBy(mgr).
-		For(&coderv1alpha1.CoderWorkspaceProxy{}).
+		For(
+			&coderv1alpha1.CoderWorkspaceProxy{},
+			ctrlbuilder.WithPredicates(predicate.NewPredicateFuncs(func(obj client.Object) bool {
+				return watchesNamespace(r.WatchNamespaces, obj.GetNamespace())
+			})),
+		).
 		Owns(&appsv1.Deployment{}).
 		Owns(&corev1.Service{}).
 		Owns(&corev1.Secret{}).
@@ -0,0 +1,68 @@
+package controller
+
+import (
+	"maps"
+	"sort"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	coderv1alpha1 "github.com/coder/coder-k8s/api/v1alpha1"
+)
+
+// commonAnnotationKeysAnnotation records which spec.commonAnnotations keys were
+// applied to a child object whose annotations other writers also set.
+const commonAnnotationKeysAnnotation = "coder.com/common-annotation-keys"
+
+// childLabels returns the metadata labels for a child object: the control
+// plane's spec.commonLabels overlaid by managed, so the labels the operator
+// relies on always win. Selectors keep using the managed labels alone.
+func childLabels(coderControlPlane *coderv1alpha1.CoderControlPlane, managed map[string]string) map[string]string {
+	labels := make(map[string]string, len(coderControlPlane.Spec.CommonLabels)+len(managed))
+	maps.Copy(labels, coderControlPlane.Spec.CommonLabels)
+	maps.Copy(labels, managed)
+	return labels
+}
+
+// childAnnotations returns spec.commonAnnotations overlaid by the annotations
+// the operator sets on a child object itself. It is used for children whose
+// annotations the operator replaces on every reconcile.
+func childAnnotations(coderControlPlane *coderv1alpha1.CoderControlPlane, own map[string]string) map[string]string {
+	if len(coderControlPlane.Spec.CommonAnnotations) == 0 {
+		return maps.Clone(own)
+	}
+
+	annotations := maps.Clone(coderControlPlane.Spec.CommonAnnotations)
+	maps.Copy(annotations, own)
+	return annotations
+}
+
+// applyCommonAnnotations adds spec.commonAnnotations to a child object whose
+// annotations are shared with other writers, such as the Deployment revision
+// annotation. Keys applied by an earlier reconcile and since removed from the
+// spec are dropped; every other annotation is left alone.
+func applyCommonAnnotations(obj metav1.Object, coderControlPlane *coderv1alpha1.CoderControlPlane) {
+	annotations := obj.GetAnnotations()
+	for _, key := range strings.Split(annotations[commonAnnotationKeysAnnotation], ",") {
+		if _, ok := coderControlPlane.Spec.CommonAnnotations[key]; key != "" && !ok {
+			delete(annotations, key)
+		}
+	}
+	delete(annotations, commonAnnotationKeysAnnotation)
+
+	common := coderControlPlane.Spec.CommonAnnotations
+	if len(common) > 0 {
+		if annotations == nil {
+			annotations = make(map[string]string, len(common)+1)
+		}
+		keys := make([]string, 0, len(common))
+		for key, value := range common {
+			annotations[key] = value
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		annotations[commonAnnotationKeysAnnotation] = strings.Join(keys, ",")
+	}
+
+	obj.SetAnnotations(annotations)
+}
@@ -0,0 +1,99 @@
+package controller
+
+import (
+	"fmt"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	coderv1alpha1 "github.com/coder/coder-k8s/api/v1alpha1"
+)
+
+const (
+	deploymentConditionReasonPending = "DeploymentStatusPending"
+	deploymentConditionReasonStale   = "DeploymentStatusStale"
+)
+
+func findDeploymentCondition(deployment *appsv1.Deployment, conditionType appsv1.DeploymentConditionType) *appsv1.DeploymentCondition {
+	for i := range deployment.Status.Conditions {
+		if deployment.Status.Conditions[i].Type == conditionType {
+			return &deployment.Status.Conditions[i]
+		}
+	}
+
+	return nil
+}
+
+// deploymentReplicaSummary describes rollout progress in terms of the
+// Deployment's updated and unavailable replica counts.
+func deploymentReplicaSummary(deployment *appsv1.Deployment) string {
+	desired := int32(1)
+	if deployment.Spec.Replicas != nil {
+		desired = *deployment.Spec.Replicas
+	}
+
+	return fmt.Sprintf("%d/%d replicas updated, %d unavailable.",
+		deployment.Status.UpdatedReplicas, desired, deployment.Status.UnavailableReplicas)
+}
+
+// setDeploymentConditions mirrors the control plane Deployment's Available and
+// Progressing conditions into status so a stuck rollout, such as an image pull
+// failure or a crash loop, is visible on the CoderControlPlane. Both conditions
+// are removed when the operator does not manage the Deployment.
+func setDeploymentConditions(
+	nextStatus *coderv1alpha1.CoderControlPlaneStatus,
+	generation int64,
+	deployment *appsv1.Deployment,
+) error {
+	if nextStatus == nil {
+		return fmt.Errorf("assertion failed: next status must not be nil")
+	}
+
+	if deployment == nil {
+		meta.RemoveStatusCondition(&nextStatus.Conditions, coderv1alpha1.CoderControlPlaneConditionDeploymentAvailable)
+		meta.RemoveStatusCondition(&nextStatus.Conditions, coderv1alpha1.CoderControlPlaneConditionDeploymentProgressing)
+		return nil
+	}
+
+	summary := deploymentReplicaSummary(deployment)
+	stale := deployment.Status.ObservedGeneration < deployment.Generation
+	for _, mapping := range []struct {
+		conditionType  string
+		deploymentType appsv1.DeploymentConditionType
+	}{
+		{coderv1alpha1.CoderControlPlaneConditionDeploymentAvailable, appsv1.DeploymentAvailable},
+		{coderv1alpha1.CoderControlPlaneConditionDeploymentProgressing, appsv1.DeploymentProgressing},
+	} {
+		status := metav1.ConditionUnknown
+		reason := deploymentConditionReasonPending
+		message := "Waiting for the Deployment controller to report status."
+
+		deploymentCondition := findDeploymentCondition(deployment, mapping.deploymentType)
+		switch {
+		case stale:
+			reason = deploymentConditionReasonStale
+			message = "Waiting for the Deployment controller to observe the latest spec."
+		case deploymentCondition != nil:
+			status = metav1.ConditionStatus(deploymentCondition.Status)
+			if strings.TrimSpace(deploymentCondition.Reason) != "" {
+				reason = deploymentCondition.Reason
+			}
+			message = deploymentCondition.Message
+		}
+
+		if err := setControlPlaneCondition(
+			nextStatus,
+			generation,
+			mapping.conditionType,
+			status,
+			reason,
+			strings.TrimSpace(message+" "+summary),
+		); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
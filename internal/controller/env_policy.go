@@ -0,0 +1,333 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"path"
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	coderv1alpha1 "github.com/coder/coder-k8s/api/v1alpha1"
+)
+
+const (
+	extraEnvRejectedReasonDisallowedEnvVar = "DisallowedEnvVar"
+
+	envFromSourceMissingReasonNotFound = "SourceNotFound"
+)
+
+// EnvPolicy limits the environment variable names a CoderControlPlane may set
+// through spec.extraEnv, spec.config, and spec.envFrom, and the equivalent
+// CODER_* names of --flags in spec.extraArgs. Patterns use path.Match syntax,
+// for example CODER_OIDC_*. The zero value allows every name.
+type EnvPolicy struct {
+	// Allowed, when not empty, lists the only names that may be set.
+	Allowed []string
+	// Denied lists names that may never be set. It takes precedence over
+	// Allowed.
+	Denied []string
+}
+
+// ParseEnvNamePatterns splits a comma-separated list of environment variable
+// name patterns and checks that each is a valid path.Match pattern. An empty
+// value yields no patterns.
+func ParseEnvNamePatterns(raw string) ([]string, error) {
+	var patterns []string
+	for _, pattern := range strings.Split(raw, ",") {
+		pattern = strings.TrimSpace(pattern)
+		if pattern == "" {
+			continue
+		}
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("parse env name pattern %q: %w", pattern, err)
+		}
+		patterns = append(patterns, pattern)
+	}
+
+	return patterns, nil
+}
+
+// Permits reports whether the policy lets a control plane set name.
+func (p EnvPolicy) Permits(name string) bool {
+	if matchesEnvNamePattern(p.Denied, name) {
+		return false
+	}
+
+	return len(p.Allowed) == 0 || matchesEnvNamePattern(p.Allowed, name)
+}
+
+func (p EnvPolicy) empty() bool {
+	return len(p.Allowed) == 0 && len(p.Denied) == 0
+}
+
+func matchesEnvNamePattern(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		// Patterns are validated when the policy is parsed.
+		if matched, _ := path.Match(pattern, name); matched {
+			return true
+		}
+	}
+
+	return false
+}
+
+// disallowedEnvVars returns the sorted environment variable names that
+// spec.extraEnv, spec.config, spec.envFrom, and spec.extraArgs set but the
+// operator's EnvPolicy does not permit. Names from envFrom are read from the
+// referenced ConfigMap and Secret keys. A --flag in extraArgs is checked as
+// the CODER_* variable Coder reads for it and reported as "--flag (CODER_*)".
+// It also returns the non-optional envFrom sources that do not exist: with a
+// policy they are expanded into env vars, so the pod no longer fails to start
+// without them.
+func (r *CoderControlPlaneReconciler) disallowedEnvVars(
+	ctx context.Context,
+	coderControlPlane *coderv1alpha1.CoderControlPlane,
+) ([]string, []string, error) {
+	if coderControlPlane == nil {
+		return nil, nil, fmt.Errorf("assertion failed: coder control plane must not be nil")
+	}
+	if r.EnvPolicy.empty() {
+		return nil, nil, nil
+	}
+
+	var reader client.Reader = r.Client
+	if r.APIReader != nil {
+		reader = r.APIReader
+	}
+	if reader == nil {
+		return nil, nil, fmt.Errorf("assertion failed: reader must not be nil")
+	}
+
+	names := make([]string, 0, len(coderControlPlane.Spec.ExtraEnv)+len(coderControlPlane.Spec.Config))
+	for _, envVar := range coderControlPlane.Spec.ExtraEnv {
+		names = append(names, envVar.Name)
+	}
+	for name := range coderControlPlane.Spec.Config {
+		names = append(names, name)
+	}
+
+	var missingSources []string
+	for i, envFromSource := range coderControlPlane.Spec.EnvFrom {
+		keys, found, err := envFromSourceKeys(ctx, reader, coderControlPlane.Namespace, i, envFromSource)
+		if err != nil {
+			return nil, nil, err
+		}
+		if !found && !envFromSourceOptional(envFromSource) {
+			missingSources = append(missingSources, envFromSourceDescription(envFromSource))
+		}
+		for _, key := range keys {
+			names = append(names, envFromSource.Prefix+key)
+		}
+	}
+
+	var disallowed []string
+	for _, name := range names {
+		if !r.EnvPolicy.Permits(name) {
+			disallowed = append(disallowed, name)
+		}
+	}
+	for _, arg := range groupCommandLineArgs(coderControlPlane.Spec.ExtraArgs) {
+		if arg.name == "" || !strings.HasPrefix(arg.tokens[0], "--") {
+			continue
+		}
+		if name := coderFlagEnvVarName(arg.name); !r.EnvPolicy.Permits(name) {
+			disallowed = append(disallowed, fmt.Sprintf("--%s (%s)", arg.name, name))
+		}
+	}
+	slices.Sort(disallowed)
+
+	return slices.Compact(disallowed), missingSources, nil
+}
+
+// coderFlagEnvVarName returns the environment variable Coder reads for a
+// server flag: --oidc-client-id is CODER_OIDC_CLIENT_ID.
+func coderFlagEnvVarName(flagName string) string {
+	return "CODER_" + strings.ToUpper(strings.ReplaceAll(flagName, "-", "_"))
+}
+
+func envFromSourceOptional(envFromSource corev1.EnvFromSource) bool {
+	switch {
+	case envFromSource.ConfigMapRef != nil:
+		return boolOrDefault(envFromSource.ConfigMapRef.Optional, false)
+	case envFromSource.SecretRef != nil:
+		return boolOrDefault(envFromSource.SecretRef.Optional, false)
+	default:
+		return true
+	}
+}
+
+func envFromSourceDescription(envFromSource corev1.EnvFromSource) string {
+	if envFromSource.ConfigMapRef != nil {
+		return "configmap/" + strings.TrimSpace(envFromSource.ConfigMapRef.Name)
+	}
+	return "secret/" + strings.TrimSpace(envFromSource.SecretRef.Name)
+}
+
+// envFromSourceKeys returns the sorted keys of the ConfigMap or Secret that
+// spec.envFrom[index] references, and whether the source exists. A missing
+// source has no keys.
+func envFromSourceKeys(
+	ctx context.Context,
+	reader client.Reader,
+	namespace string,
+	index int,
+	envFromSource corev1.EnvFromSource,
+) ([]string, bool, error) {
+	var keys []string
+	switch {
+	case envFromSource.ConfigMapRef != nil:
+		configMapName := strings.TrimSpace(envFromSource.ConfigMapRef.Name)
+		configMap := &corev1.ConfigMap{}
+		if err := reader.Get(ctx, types.NamespacedName{Namespace: namespace, Name: configMapName}, configMap); err != nil {
+			if apierrors.IsNotFound(err) {
+				return nil, false, nil
+			}
+			return nil, false, fmt.Errorf("get envFrom[%d] configmap %s/%s: %w", index, namespace, configMapName, err)
+		}
+		for key := range configMap.Data {
+			keys = append(keys, key)
+		}
+		for key := range configMap.BinaryData {
+			keys = append(keys, key)
+		}
+	case envFromSource.SecretRef != nil:
+		secretName := strings.TrimSpace(envFromSource.SecretRef.Name)
+		secret := &corev1.Secret{}
+		if err := reader.Get(ctx, types.NamespacedName{Namespace: namespace, Name: secretName}, secret); err != nil {
+			if apierrors.IsNotFound(err) {
+				return nil, false, nil
+			}
+			return nil, false, fmt.Errorf("get envFrom[%d] secret %s/%s: %w", index, namespace, secretName, err)
+		}
+		for key := range secret.Data {
+			keys = append(keys, key)
+		}
+	}
+	slices.Sort(keys)
+
+	return keys, true, nil
+}
+
+// policyEnvFromEnv expands spec.envFrom into one env var per source key when
+// the operator has an EnvPolicy, and reports whether it did. The Deployment
+// then only references keys disallowedEnvVars checked, so a key added to a
+// source later cannot reach coderd before the policy sees it. Later sources
+// win for the same name, as they would with envFrom; names that are not
+// valid env var names are skipped, as the kubelet skips them.
+func (r *CoderControlPlaneReconciler) policyEnvFromEnv(
+	ctx context.Context,
+	coderControlPlane *coderv1alpha1.CoderControlPlane,
+) ([]corev1.EnvVar, bool, error) {
+	if coderControlPlane == nil {
+		return nil, false, fmt.Errorf("assertion failed: coder control plane must not be nil")
+	}
+	if r.EnvPolicy.empty() {
+		return nil, false, nil
+	}
+
+	var reader client.Reader = r.Client
+	if r.APIReader != nil {
+		reader = r.APIReader
+	}
+	if reader == nil {
+		return nil, false, fmt.Errorf("assertion failed: reader must not be nil")
+	}
+
+	envByName := map[string]corev1.EnvVar{}
+	for i, envFromSource := range coderControlPlane.Spec.EnvFrom {
+		keys, _, err := envFromSourceKeys(ctx, reader, coderControlPlane.Namespace, i, envFromSource)
+		if err != nil {
+			return nil, false, err
+		}
+		for _, key := range keys {
+			name := envFromSource.Prefix + key
+			if len(validation.IsEnvVarName(name)) > 0 {
+				continue
+			}
+
+			envVar := corev1.EnvVar{Name: name, ValueFrom: &corev1.EnvVarSource{}}
+			switch {
+			case envFromSource.ConfigMapRef != nil:
+				envVar.ValueFrom.ConfigMapKeyRef = &corev1.ConfigMapKeySelector{
+					LocalObjectReference: envFromSource.ConfigMapRef.LocalObjectReference,
+					Key:                  key,
+					Optional:             envFromSource.ConfigMapRef.Optional,
+				}
+			case envFromSource.SecretRef != nil:
+				envVar.ValueFrom.SecretKeyRef = &corev1.SecretKeySelector{
+					LocalObjectReference: envFromSource.SecretRef.LocalObjectReference,
+					Key:                  key,
+					Optional:             envFromSource.SecretRef.Optional,
+				}
+			}
+			envByName[name] = envVar
+		}
+	}
+
+	names := slices.Sorted(maps.Keys(envByName))
+	env := make([]corev1.EnvVar, 0, len(names))
+	for _, name := range names {
+		env = append(env, envByName[name])
+	}
+
+	return env, true, nil
+}
+
+// rejectDisallowedEnv records the ExtraEnvRejected condition for names the
+// env policy does not permit, leaving every managed resource as it is.
+func (r *CoderControlPlaneReconciler) rejectDisallowedEnv(
+	ctx context.Context,
+	coderControlPlane *coderv1alpha1.CoderControlPlane,
+	disallowed []string,
+) error {
+	if coderControlPlane == nil {
+		return fmt.Errorf("assertion failed: coder control plane must not be nil")
+	}
+	if len(disallowed) == 0 {
+		return fmt.Errorf("assertion failed: disallowed env var names must not be empty")
+	}
+
+	return r.rejectWithCondition(
+		ctx,
+		coderControlPlane,
+		coderv1alpha1.CoderControlPlaneConditionExtraEnvRejected,
+		extraEnvRejectedReasonDisallowedEnvVar,
+		fmt.Sprintf("spec.extraEnv, spec.config, spec.envFrom, or spec.extraArgs sets env vars the operator env policy does not allow: %s", strings.Join(disallowed, ", ")),
+	)
+}
+
+// setEnvFromSourceMissingCondition sets EnvFromSourceMissing=True while
+// non-optional spec.envFrom sources do not exist and removes the condition
+// otherwise.
+func setEnvFromSourceMissingCondition(
+	nextStatus *coderv1alpha1.CoderControlPlaneStatus,
+	generation int64,
+	missingSources []string,
+) error {
+	if nextStatus == nil {
+		return fmt.Errorf("assertion failed: next status must not be nil")
+	}
+
+	if len(missingSources) == 0 {
+		meta.RemoveStatusCondition(&nextStatus.Conditions, coderv1alpha1.CoderControlPlaneConditionEnvFromSourceMissing)
+		return nil
+	}
+
+	return setControlPlaneCondition(
+		nextStatus,
+		generation,
+		coderv1alpha1.CoderControlPlaneConditionEnvFromSourceMissing,
+		metav1.ConditionTrue,
+		envFromSourceMissingReasonNotFound,
+		fmt.Sprintf("spec.envFrom references sources that do not exist, so coderd runs without their env vars: %s", strings.Join(missingSources, ", ")),
+	)
+}
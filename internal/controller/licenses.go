@@ -0,0 +1,356 @@
+package controller
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/coder/coder/v2/codersdk"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	coderv1alpha1 "github.com/coder/coder-k8s/api/v1alpha1"
+)
+
+// stackedLicenseKey returns the Secret name and key a spec.licenses entry reads from.
+func stackedLicenseKey(selector coderv1alpha1.SecretKeySelector) (string, string) {
+	key := strings.TrimSpace(selector.Key)
+	if key == "" {
+		key = coderv1alpha1.DefaultLicenseSecretKey
+	}
+
+	return strings.TrimSpace(selector.Name), key
+}
+
+// pruneAppliedLicenses drops tracked licenses whose Secret key is no longer
+// listed in spec.licenses.
+func pruneAppliedLicenses(
+	applied []coderv1alpha1.AppliedLicenseStatus,
+	desired []coderv1alpha1.SecretKeySelector,
+) []coderv1alpha1.AppliedLicenseStatus {
+	if len(applied) == 0 {
+		return nil
+	}
+
+	desiredKeys := make(map[[2]string]struct{}, len(desired))
+	for _, selector := range desired {
+		name, key := stackedLicenseKey(selector)
+		desiredKeys[[2]string{name, key}] = struct{}{}
+	}
+
+	pruned := make([]coderv1alpha1.AppliedLicenseStatus, 0, len(applied))
+	for _, entry := range applied {
+		if _, ok := desiredKeys[[2]string{entry.SecretName, entry.Key}]; ok {
+			pruned = append(pruned, entry)
+		}
+	}
+	if len(pruned) == 0 {
+		return nil
+	}
+
+	return pruned
+}
+
+func findAppliedLicense(
+	applied []coderv1alpha1.AppliedLicenseStatus,
+	secretName string,
+	key string,
+	hash string,
+) int {
+	for i := range applied {
+		if applied[i].SecretName == secretName && applied[i].Key == key && applied[i].Hash == hash {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// pruneReplacedLicenses drops tracked licenses read from secretName and key
+// whose hash is no longer part of the Secret value.
+func pruneReplacedLicenses(
+	applied []coderv1alpha1.AppliedLicenseStatus,
+	secretName string,
+	key string,
+	currentHashes map[string]struct{},
+) []coderv1alpha1.AppliedLicenseStatus {
+	pruned := applied[:0]
+	for _, entry := range applied {
+		if entry.SecretName == secretName && entry.Key == key {
+			if _, ok := currentHashes[entry.Hash]; !ok {
+				continue
+			}
+		}
+		pruned = append(pruned, entry)
+	}
+	if len(pruned) == 0 {
+		return nil
+	}
+
+	return pruned
+}
+
+// splitLicenseJWTs splits a license Secret value into its JWTs. A value may hold
+// several licenses separated by newlines or other whitespace; repeated licenses
+// are dropped.
+func splitLicenseJWTs(value string) []string {
+	var licenseJWTs []string
+	for _, field := range strings.Fields(value) {
+		if !slices.Contains(licenseJWTs, field) {
+			licenseJWTs = append(licenseJWTs, field)
+		}
+	}
+
+	return licenseJWTs
+}
+
+// pendingLicenseJWTs returns the license JWTs coderd does not have installed.
+// JWTs with a `jti` claim are matched against the installed license UUIDs.
+// JWTs without one cannot be matched individually: when checkUnidentified is
+// set they count as installed if coderd has any license, otherwise they are
+// always returned.
+func (r *CoderControlPlaneReconciler) pendingLicenseJWTs(
+	ctx context.Context,
+	coderControlPlane *coderv1alpha1.CoderControlPlane,
+	controlPlaneURL string,
+	operatorToken string,
+	licenseJWTs []string,
+	checkUnidentified bool,
+) ([]string, error) {
+	var installedUUIDs map[string]struct{}
+	var hasAnyLicense *bool
+	pending := make([]string, 0, len(licenseJWTs))
+	for _, licenseJWT := range licenseJWTs {
+		licenseUUID := licenseJWTID(licenseJWT)
+		switch {
+		case licenseUUID != "":
+			if installedUUIDs == nil {
+				started := time.Now()
+				uuids, err := r.LicenseUploader.LicenseUUIDs(ctx, controlPlaneURL, operatorToken)
+				observeCoderAPICall(coderControlPlane, coderAPIOperationListLicenses, started, err)
+				if err != nil {
+					return nil, err
+				}
+				installedUUIDs = make(map[string]struct{}, len(uuids))
+				for _, installedUUID := range uuids {
+					installedUUIDs[installedUUID] = struct{}{}
+				}
+			}
+			if _, ok := installedUUIDs[licenseUUID]; ok {
+				continue
+			}
+		case checkUnidentified:
+			if hasAnyLicense == nil {
+				started := time.Now()
+				hasAny, err := r.LicenseUploader.HasAnyLicense(ctx, controlPlaneURL, operatorToken)
+				observeCoderAPICall(coderControlPlane, coderAPIOperationHasAnyLicense, started, err)
+				if err != nil {
+					return nil, err
+				}
+				hasAnyLicense = &hasAny
+			}
+			if *hasAnyLicense {
+				continue
+			}
+		}
+		pending = append(pending, licenseJWT)
+	}
+
+	return pending, nil
+}
+
+// licenseJWTID returns the `jti` claim of a license JWT without verifying its
+// signature. coderd stores this value as the license UUID, so it identifies the
+// license in the backend. It returns an empty string when the claim is absent.
+func licenseJWTID(licenseJWT string) string {
+	parts := strings.Split(licenseJWT, ".")
+	if len(parts) != 3 {
+		return ""
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return ""
+	}
+
+	var claims struct {
+		ID string `json:"jti"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return ""
+	}
+
+	return strings.TrimSpace(claims.ID)
+}
+
+// applyStackedLicenses uploads every license in spec.licenses that has not
+// been applied yet, has changed, or is no longer installed in coderd.
+func (r *CoderControlPlaneReconciler) applyStackedLicenses(
+	ctx context.Context,
+	coderControlPlane *coderv1alpha1.CoderControlPlane,
+	nextStatus *coderv1alpha1.CoderControlPlaneStatus,
+	controlPlaneURL string,
+	operatorToken string,
+) (ctrl.Result, error) {
+	if coderControlPlane == nil {
+		return ctrl.Result{}, fmt.Errorf("assertion failed: coder control plane must not be nil")
+	}
+	if nextStatus == nil {
+		return ctrl.Result{}, fmt.Errorf("assertion failed: next status must not be nil")
+	}
+
+	var installedUUIDs map[string]struct{}
+	for i, selector := range coderControlPlane.Spec.Licenses {
+		secretName, secretKey := stackedLicenseKey(selector)
+		if secretName == "" {
+			return ctrl.Result{}, fmt.Errorf("assertion failed: spec.licenses[%d] name must not be empty", i)
+		}
+
+		licenseJWT, err := r.readSecretValue(ctx, coderControlPlane.Namespace, secretName, secretKey)
+		switch {
+		case err == nil:
+		case apierrors.IsNotFound(err), errors.Is(err, errSecretValueMissing), errors.Is(err, errSecretValueEmpty):
+			return r.setLicenseRetryCondition(coderControlPlane, nextStatus, licenseConditionReasonSecretMissing,
+				fmt.Sprintf("License Secret %q is missing or incomplete; retrying upload.", secretName))
+		default:
+			return r.setLicenseRetryCondition(coderControlPlane, nextStatus, licenseConditionReasonError,
+				fmt.Sprintf("Failed to read license Secret %q; retrying upload.", secretName))
+		}
+
+		licenseJWTs := splitLicenseJWTs(licenseJWT)
+		if len(licenseJWTs) == 0 {
+			return r.setLicenseRetryCondition(coderControlPlane, nextStatus, licenseConditionReasonSecretMissing,
+				fmt.Sprintf("License Secret %q value is empty after trimming whitespace.", secretName))
+		}
+
+		// Each license in the value is tracked by its own hash. Tracked
+		// licenses are skipped unless coderd no longer has them installed.
+		currentHashes := make(map[string]struct{}, len(licenseJWTs))
+		for _, licenseJWT := range licenseJWTs {
+			licenseHash, err := hashLicenseJWT(licenseJWT)
+			if err != nil {
+				return ctrl.Result{}, err
+			}
+			currentHashes[licenseHash] = struct{}{}
+			licenseUUID := licenseJWTID(licenseJWT)
+
+			index := findAppliedLicense(nextStatus.Licenses, secretName, secretKey, licenseHash)
+			if index >= 0 {
+				if licenseUUID == "" {
+					continue
+				}
+				if installedUUIDs == nil {
+					started := time.Now()
+					uuids, err := r.LicenseUploader.LicenseUUIDs(ctx, controlPlaneURL, operatorToken)
+					observeCoderAPICall(coderControlPlane, coderAPIOperationListLicenses, started, err)
+					if err != nil {
+						return r.setLicenseSDKErrorCondition(coderControlPlane, nextStatus, err, "query configured licenses")
+					}
+					installedUUIDs = make(map[string]struct{}, len(uuids))
+					for _, installedUUID := range uuids {
+						installedUUIDs[installedUUID] = struct{}{}
+					}
+				}
+				if _, ok := installedUUIDs[licenseUUID]; ok {
+					continue
+				}
+			}
+
+			started := time.Now()
+			uploadErr := r.LicenseUploader.AddLicense(ctx, controlPlaneURL, operatorToken, licenseJWT)
+			observeCoderAPICall(coderControlPlane, coderAPIOperationAddLicense, started, uploadErr)
+			if uploadErr != nil && !isDuplicateLicenseUploadError(uploadErr) {
+				return r.setLicenseSDKErrorCondition(coderControlPlane, nextStatus, uploadErr,
+					fmt.Sprintf("upload the license from Secret %q", secretName))
+			}
+
+			now := metav1.Now()
+			entry := coderv1alpha1.AppliedLicenseStatus{
+				SecretName:  secretName,
+				Key:         secretKey,
+				Hash:        licenseHash,
+				UUID:        licenseUUID,
+				LastApplied: &now,
+			}
+			if index >= 0 {
+				nextStatus.Licenses[index] = entry
+			} else {
+				nextStatus.Licenses = append(nextStatus.Licenses, entry)
+			}
+		}
+		nextStatus.Licenses = pruneReplacedLicenses(nextStatus.Licenses, secretName, secretKey, currentHashes)
+	}
+
+	if err := setControlPlaneCondition(
+		nextStatus,
+		coderControlPlane.Generation,
+		coderv1alpha1.CoderControlPlaneConditionLicenseApplied,
+		metav1.ConditionTrue,
+		licenseConditionReasonApplied,
+		"Configured licenses are applied.",
+	); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	return ctrl.Result{}, nil
+}
+
+func (r *CoderControlPlaneReconciler) setLicenseRetryCondition(
+	coderControlPlane *coderv1alpha1.CoderControlPlane,
+	nextStatus *coderv1alpha1.CoderControlPlaneStatus,
+	reason string,
+	message string,
+) (ctrl.Result, error) {
+	if err := setControlPlaneCondition(
+		nextStatus,
+		coderControlPlane.Generation,
+		coderv1alpha1.CoderControlPlaneConditionLicenseApplied,
+		metav1.ConditionFalse,
+		reason,
+		message,
+	); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	return ctrl.Result{RequeueAfter: operatorAccessRetryInterval}, nil
+}
+
+// setLicenseSDKErrorCondition maps a coderd licenses API error to the
+// LicenseApplied condition. Unsupported deployments are not retried.
+func (r *CoderControlPlaneReconciler) setLicenseSDKErrorCondition(
+	coderControlPlane *coderv1alpha1.CoderControlPlane,
+	nextStatus *coderv1alpha1.CoderControlPlaneStatus,
+	err error,
+	operation string,
+) (ctrl.Result, error) {
+	var sdkErr *codersdk.Error
+	if errors.As(err, &sdkErr) {
+		switch sdkErr.StatusCode() {
+		case http.StatusNotFound:
+			if err := setControlPlaneCondition(
+				nextStatus,
+				coderControlPlane.Generation,
+				coderv1alpha1.CoderControlPlaneConditionLicenseApplied,
+				metav1.ConditionFalse,
+				licenseConditionReasonNotSupported,
+				"Control plane does not expose the Enterprise licenses API.",
+			); err != nil {
+				return ctrl.Result{}, err
+			}
+			return ctrl.Result{}, nil
+		case http.StatusUnauthorized, http.StatusForbidden:
+			return r.setLicenseRetryCondition(coderControlPlane, nextStatus, licenseConditionReasonForbidden,
+				fmt.Sprintf("Operator token is not authorized to %s.", operation))
+		}
+	}
+
+	return r.setLicenseRetryCondition(coderControlPlane, nextStatus, licenseConditionReasonError,
+		fmt.Sprintf("Failed to %s; retrying.", operation))
+}
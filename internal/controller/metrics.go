@@ -0,0 +1,102 @@
+package controller
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	coderv1alpha1 "github.com/coder/coder-k8s/api/v1alpha1"
+)
+
+const (
+	coderAPIOperationEntitlements        = "entitlements"
+	coderAPIOperationProvisionerDaemons  = "provisioner_daemons"
+	coderAPIOperationEnsureOperatorToken = "ensure_operator_token"
+	coderAPIOperationRevokeOperatorToken = "revoke_operator_token"
+	coderAPIOperationAddLicense          = "add_license"
+	coderAPIOperationHasAnyLicense       = "has_any_license"
+	coderAPIOperationListLicenses        = "list_licenses"
+	coderAPIOperationBuildInfo           = "build_info"
+
+	coderAPIResultSuccess = "success"
+	coderAPIResultError   = "error"
+
+	reconcileErrorReasonUnknown = "Unknown"
+)
+
+// The control plane metrics are labeled by CoderControlPlane namespace and
+// name. Every other label takes values from a fixed set, and a control plane's
+// series are dropped when it is deleted, so cardinality is bounded by the number
+// of CoderControlPlanes.
+var (
+	controlPlaneReconcileDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "coder_k8s_controlplane_reconcile_duration_seconds",
+		Help:    "Duration of CoderControlPlane reconciles.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"namespace", "name"})
+
+	controlPlaneReconcileErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "coder_k8s_controlplane_reconcile_errors_total",
+		Help: "CoderControlPlane reconciles that returned an error, by Kubernetes status reason.",
+	}, []string{"namespace", "name", "reason"})
+
+	coderAPIRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "coder_k8s_controlplane_coder_api_request_duration_seconds",
+		Help:    "Duration of Coder API calls made while reconciling a CoderControlPlane.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"namespace", "name", "operation", "result"})
+)
+
+func init() {
+	ctrlmetrics.Registry.MustRegister(
+		controlPlaneReconcileDuration,
+		controlPlaneReconcileErrors,
+		coderAPIRequestDuration,
+	)
+}
+
+// observeControlPlaneReconcile records the duration of one reconcile and, when
+// it failed, the Kubernetes status reason of its error.
+func observeControlPlaneReconcile(key types.NamespacedName, started time.Time, err error) {
+	controlPlaneReconcileDuration.WithLabelValues(key.Namespace, key.Name).Observe(time.Since(started).Seconds())
+	if err == nil {
+		return
+	}
+
+	reason := string(apierrors.ReasonForError(err))
+	if reason == "" {
+		reason = reconcileErrorReasonUnknown
+	}
+	controlPlaneReconcileErrors.WithLabelValues(key.Namespace, key.Name, reason).Inc()
+}
+
+// observeCoderAPICall records the duration and outcome of a Coder API call made
+// for coderControlPlane.
+func observeCoderAPICall(coderControlPlane *coderv1alpha1.CoderControlPlane, operation string, started time.Time, err error) {
+	if coderControlPlane == nil {
+		return
+	}
+
+	result := coderAPIResultSuccess
+	if err != nil {
+		result = coderAPIResultError
+	}
+	coderAPIRequestDuration.
+		WithLabelValues(coderControlPlane.Namespace, coderControlPlane.Name, operation, result).
+		Observe(time.Since(started).Seconds())
+}
+
+// forgetControlPlaneMetrics drops every series recorded for a deleted control
+// plane.
+func forgetControlPlaneMetrics(key types.NamespacedName) {
+	labels := prometheus.Labels{
+		"namespace": key.Namespace,
+		"name":      key.Name,
+	}
+	controlPlaneReconcileDuration.DeletePartialMatch(labels)
+	controlPlaneReconcileErrors.DeletePartialMatch(labels)
+	coderAPIRequestDuration.DeletePartialMatch(labels)
+}
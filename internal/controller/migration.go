@@ -0,0 +1,293 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"maps"
+	"strings"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	coderv1alpha1 "github.com/coder/coder-k8s/api/v1alpha1"
+)
+
+const (
+	migrationJobNameInfix = "-migrate-"
+	// Job names are copied into the job-name pod label, so they must fit a
+	// label value.
+	migrationJobNameMaxLength     = 63
+	defaultMigrationJobTTLSeconds = int32(3600)
+	// migrationSpecHashAnnotation records a hash of the migration Job's pod
+	// template, so a failed Job is replaced once the spec that produced it
+	// changes.
+	migrationSpecHashAnnotation = "coder.com/migration-spec-hash"
+
+	migrationConditionReasonRunning   = "Running"
+	migrationConditionReasonSucceeded = "Succeeded"
+	migrationConditionReasonFailed    = "Failed"
+)
+
+// migrationState describes the migration Job for the current control plane
+// image.
+type migrationState struct {
+	enabled   bool
+	jobName   string
+	jobExists bool
+	complete  bool
+	failed    bool
+	// specHash is the existing Job's migrationSpecHashAnnotation.
+	specHash string
+}
+
+// pending reports whether the Deployment must stay scaled down.
+func (s migrationState) pending() bool {
+	return s.enabled && !s.complete
+}
+
+func migrationEnabled(coderControlPlane *coderv1alpha1.CoderControlPlane) bool {
+	return coderControlPlane != nil && coderControlPlane.Spec.Migration != nil && coderControlPlane.Spec.Migration.Enabled
+}
+
+// migrationArgs returns spec.migration.args. There is no default: Coder has no
+// documented migrate-only subcommand, so the arguments must name a command the
+// image provides.
+func migrationArgs(coderControlPlane *coderv1alpha1.CoderControlPlane) []string {
+	if coderControlPlane.Spec.Migration == nil {
+		return nil
+	}
+
+	return append([]string(nil), coderControlPlane.Spec.Migration.Args...)
+}
+
+// migrationSpecHash hashes a migration Job pod template.
+func migrationSpecHash(template corev1.PodTemplateSpec) (string, error) {
+	encoded, err := json.Marshal(template)
+	if err != nil {
+		return "", fmt.Errorf("marshal migration job pod template: %w", err)
+	}
+
+	hasher := fnv.New32a()
+	_, _ = hasher.Write(encoded)
+	return fmt.Sprintf("%08x", hasher.Sum32()), nil
+}
+
+func migrationJobLabels(name string) map[string]string {
+	return map[string]string{
+		"app.kubernetes.io/name":       "coder-migration",
+		"app.kubernetes.io/instance":   name,
+		"app.kubernetes.io/managed-by": "coder-k8s",
+	}
+}
+
+// migrationJobName returns a Job name that changes whenever the image or the
+// migration arguments change, so each image is migrated exactly once.
+func migrationJobName(coderControlPlane *coderv1alpha1.CoderControlPlane, image string) (string, error) {
+	if coderControlPlane == nil {
+		return "", fmt.Errorf("assertion failed: coder control plane must not be nil")
+	}
+
+	hasher := fnv.New32a()
+	_, _ = hasher.Write([]byte(image))
+	for _, arg := range migrationArgs(coderControlPlane) {
+		_, _ = hasher.Write([]byte{0})
+		_, _ = hasher.Write([]byte(arg))
+	}
+	suffix := fmt.Sprintf("%s%08x", migrationJobNameInfix, hasher.Sum32())
+
+	prefix := coderControlPlane.Name
+	if available := migrationJobNameMaxLength - len(suffix); len(prefix) > available {
+		prefix = prefix[:available]
+	}
+	prefix = strings.Trim(prefix, "-.")
+	if prefix == "" {
+		return "", fmt.Errorf("assertion failed: migration job name prefix must not be empty")
+	}
+
+	return prefix + suffix, nil
+}
+
+func jobConditionTrue(job *batchv1.Job, conditionType batchv1.JobConditionType) bool {
+	for _, condition := range job.Status.Conditions {
+		if condition.Type == conditionType && condition.Status == corev1.ConditionTrue {
+			return true
+		}
+	}
+
+	return false
+}
+
+// observeMigration reports the state of the migration Job for the current
+// image. A finished Job removed by its TTL still counts as complete when status
+// already recorded its success.
+func (r *CoderControlPlaneReconciler) observeMigration(
+	ctx context.Context,
+	coderControlPlane *coderv1alpha1.CoderControlPlane,
+) (migrationState, error) {
+	if !migrationEnabled(coderControlPlane) {
+		return migrationState{}, nil
+	}
+
+	jobName, err := migrationJobName(coderControlPlane, coderImageOrDefault(coderControlPlane.Spec.Image, r.DefaultImage))
+	if err != nil {
+		return migrationState{}, err
+	}
+	state := migrationState{enabled: true, jobName: jobName}
+
+	job := &batchv1.Job{}
+	err = r.Get(ctx, types.NamespacedName{Name: jobName, Namespace: coderControlPlane.Namespace}, job)
+	switch {
+	case err == nil:
+		if !isOwnedByCoderControlPlane(job, coderControlPlane) {
+			return migrationState{}, fmt.Errorf("migration job %s/%s exists and is not owned by codercontrolplane %s",
+				coderControlPlane.Namespace, jobName, coderControlPlane.Name)
+		}
+		state.jobExists = true
+		state.complete = jobConditionTrue(job, batchv1.JobComplete)
+		state.failed = jobConditionTrue(job, batchv1.JobFailed)
+		state.specHash = job.Annotations[migrationSpecHashAnnotation]
+	case apierrors.IsNotFound(err):
+		state.complete = coderControlPlane.Status.MigrationJobName == jobName &&
+			meta.IsStatusConditionTrue(coderControlPlane.Status.Conditions, coderv1alpha1.CoderControlPlaneConditionMigrationComplete)
+	default:
+		return migrationState{}, fmt.Errorf("get migration job %s/%s: %w", coderControlPlane.Namespace, jobName, err)
+	}
+
+	return state, nil
+}
+
+// reconcileMigrationJob creates the migration Job from the control plane pod
+// template when the current image has not been migrated yet. The Job name
+// already changes with the image and args; a failed Job whose pod template no
+// longer matches the desired one is deleted and created again, so fixing the
+// env or volumes retries the migration without a manual delete.
+func (r *CoderControlPlaneReconciler) reconcileMigrationJob(
+	ctx context.Context,
+	coderControlPlane *coderv1alpha1.CoderControlPlane,
+	state migrationState,
+	podTemplate corev1.PodTemplateSpec,
+) error {
+	if coderControlPlane == nil {
+		return fmt.Errorf("assertion failed: coder control plane must not be nil")
+	}
+	if !state.pending() || (state.jobExists && !state.failed) {
+		return nil
+	}
+	if len(podTemplate.Spec.Containers) == 0 {
+		return fmt.Errorf("assertion failed: control plane pod template must have a container")
+	}
+	args := migrationArgs(coderControlPlane)
+	if len(args) == 0 {
+		return fmt.Errorf("spec.migration.args is required when spec.migration.enabled is true")
+	}
+
+	podSpec := *podTemplate.Spec.DeepCopy()
+	podSpec.RestartPolicy = corev1.RestartPolicyNever
+	container := &podSpec.Containers[0]
+	container.Args = args
+	container.Ports = nil
+	container.ReadinessProbe = nil
+	container.LivenessProbe = nil
+	container.StartupProbe = nil
+	container.Lifecycle = nil
+
+	ttlSeconds := defaultMigrationJobTTLSeconds
+	if coderControlPlane.Spec.Migration.TTLSecondsAfterFinished != nil {
+		ttlSeconds = *coderControlPlane.Spec.Migration.TTLSecondsAfterFinished
+	}
+
+	labels := migrationJobLabels(coderControlPlane.Name)
+	template := corev1.PodTemplateSpec{
+		ObjectMeta: metav1.ObjectMeta{Labels: maps.Clone(labels)},
+		Spec:       podSpec,
+	}
+	specHash, err := migrationSpecHash(template)
+	if err != nil {
+		return err
+	}
+	if state.jobExists {
+		if state.specHash == specHash {
+			return nil
+		}
+		stale := &batchv1.Job{ObjectMeta: metav1.ObjectMeta{Name: state.jobName, Namespace: coderControlPlane.Namespace}}
+		if err := r.Delete(ctx, stale, client.PropagationPolicy(metav1.DeletePropagationBackground)); err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("delete failed migration job %s/%s: %w", stale.Namespace, stale.Name, err)
+		}
+	}
+
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      state.jobName,
+			Namespace: coderControlPlane.Namespace,
+			Labels:    childLabels(coderControlPlane, labels),
+			Annotations: childAnnotations(coderControlPlane, map[string]string{
+				migrationSpecHashAnnotation: specHash,
+			}),
+		},
+		Spec: batchv1.JobSpec{
+			TTLSecondsAfterFinished: &ttlSeconds,
+			Template:                template,
+		},
+	}
+	if err := controllerutil.SetControllerReference(coderControlPlane, job, r.Scheme); err != nil {
+		return fmt.Errorf("set migration job controller reference: %w", err)
+	}
+	if err := r.Create(ctx, job); err != nil && !apierrors.IsAlreadyExists(err) {
+		return fmt.Errorf("create migration job %s/%s: %w", job.Namespace, job.Name, err)
+	}
+
+	return nil
+}
+
+// setMigrationStatus records the migration Job and the MigrationComplete
+// condition. While the migration is pending the control plane stays Pending
+// unless it is suspended.
+func setMigrationStatus(
+	nextStatus *coderv1alpha1.CoderControlPlaneStatus,
+	generation int64,
+	state migrationState,
+) error {
+	if nextStatus == nil {
+		return fmt.Errorf("assertion failed: next status must not be nil")
+	}
+
+	if !state.enabled {
+		nextStatus.MigrationJobName = ""
+		meta.RemoveStatusCondition(&nextStatus.Conditions, coderv1alpha1.CoderControlPlaneConditionMigrationComplete)
+		return nil
+	}
+
+	nextStatus.MigrationJobName = state.jobName
+	status := metav1.ConditionFalse
+	reason := migrationConditionReasonRunning
+	message := fmt.Sprintf("Waiting for migration Job %q to complete.", state.jobName)
+	switch {
+	case state.complete:
+		status = metav1.ConditionTrue
+		reason = migrationConditionReasonSucceeded
+		message = fmt.Sprintf("Migration Job %q completed.", state.jobName)
+	case state.failed:
+		reason = migrationConditionReasonFailed
+		message = fmt.Sprintf("Migration Job %q failed; change the migration spec or delete the Job to retry.", state.jobName)
+	}
+	if state.pending() && nextStatus.Phase != coderv1alpha1.CoderControlPlanePhaseSuspended {
+		nextStatus.Phase = coderv1alpha1.CoderControlPlanePhasePending
+	}
+
+	return setControlPlaneCondition(
+		nextStatus,
+		generation,
+		coderv1alpha1.CoderControlPlaneConditionMigrationComplete,
+		status,
+		reason,
+		message,
+	)
+}
@@ -0,0 +1,247 @@
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	coderv1alpha1 "github.com/coder/coder-k8s/api/v1alpha1"
+)
+
+// ResourceProfilesEnv names the operator environment variable that overrides or
+// extends the built-in resource profiles. Its value is a JSON object mapping a
+// profile name to a Kubernetes ResourceRequirements object, so it can be sourced
+// from a ConfigMap key with valueFrom.configMapKeyRef.
+const ResourceProfilesEnv = "CODER_K8S_RESOURCE_PROFILES"
+
+// ResourceProfiles maps profile names to container resource requirements.
+type ResourceProfiles map[string]corev1.ResourceRequirements
+
+// DefaultResourceProfiles returns the built-in small, medium, and large profiles.
+func DefaultResourceProfiles() ResourceProfiles {
+	return ResourceProfiles{
+		"small":  resourceProfile("250m", "512Mi", "1", "1Gi"),
+		"medium": resourceProfile("1", "2Gi", "2", "4Gi"),
+		"large":  resourceProfile("2", "4Gi", "4", "8Gi"),
+	}
+}
+
+func resourceProfile(cpuRequest, memoryRequest, cpuLimit, memoryLimit string) corev1.ResourceRequirements {
+	return corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse(cpuRequest),
+			corev1.ResourceMemory: resource.MustParse(memoryRequest),
+		},
+		Limits: corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse(cpuLimit),
+			corev1.ResourceMemory: resource.MustParse(memoryLimit),
+		},
+	}
+}
+
+// LoadResourceProfilesFromEnv returns the built-in profiles merged with any
+// profiles configured through ResourceProfilesEnv. Configured profiles replace
+// built-in profiles with the same name.
+func LoadResourceProfilesFromEnv() (ResourceProfiles, error) {
+	return ParseResourceProfiles(os.Getenv(ResourceProfilesEnv))
+}
+
+// ParseResourceProfiles merges a JSON profile map over the built-in profiles.
+// An empty value yields the built-in profiles unchanged.
+func ParseResourceProfiles(raw string) (ResourceProfiles, error) {
+	profiles := DefaultResourceProfiles()
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return profiles, nil
+	}
+
+	configured := map[string]corev1.ResourceRequirements{}
+	if err := json.Unmarshal([]byte(raw), &configured); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", ResourceProfilesEnv, err)
+	}
+	for name, requirements := range configured {
+		if strings.TrimSpace(name) == "" {
+			return nil, fmt.Errorf("parse %s: profile name must not be empty", ResourceProfilesEnv)
+		}
+		if err := validateContainerResources(&requirements); err != nil {
+			return nil, fmt.Errorf("parse %s: profile %q: %w", ResourceProfilesEnv, name, err)
+		}
+		profiles[name] = requirements
+	}
+
+	return profiles, nil
+}
+
+// ParseDefaultResources parses the operator's --default-resources value, a
+// JSON Kubernetes ResourceRequirements object. An empty value yields nil.
+func ParseDefaultResources(raw string) (*corev1.ResourceRequirements, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil, nil
+	}
+
+	requirements := &corev1.ResourceRequirements{}
+	if err := json.Unmarshal([]byte(raw), requirements); err != nil {
+		return nil, fmt.Errorf("parse default resources: %w", err)
+	}
+	if err := validateContainerResources(requirements); err != nil {
+		return nil, fmt.Errorf("parse default resources: %w", err)
+	}
+
+	return requirements, nil
+}
+
+const (
+	// defaultBuiltinProvisionerDaemons matches coderd's CODER_PROVISIONER_DAEMONS default.
+	defaultBuiltinProvisionerDaemons = int32(3)
+	// defaultProvisionerResourceFactorPercent is the share of base resources
+	// added per built-in provisioner daemon beyond the default count.
+	defaultProvisionerResourceFactorPercent = int32(25)
+	// defaultScaledResourceProfile is the base profile scaled for extra
+	// built-in provisioner daemons when no profile is selected.
+	defaultScaledResourceProfile = "small"
+)
+
+// builtinProvisionerExtraDaemons returns how many built-in provisioner daemons
+// are configured beyond coderd's default count.
+func builtinProvisionerExtraDaemons(coderControlPlane *coderv1alpha1.CoderControlPlane) int32 {
+	provisioner := coderControlPlane.Spec.Provisioner
+	if provisioner == nil || provisioner.Daemons == nil {
+		return 0
+	}
+
+	return max(*provisioner.Daemons-defaultBuiltinProvisionerDaemons, 0)
+}
+
+// isExtendedResourceName reports whether name is an extended resource such as
+// nvidia.com/gpu. Extended resources are whole devices, so they are never
+// overcommitted or scaled.
+func isExtendedResourceName(name corev1.ResourceName) bool {
+	return strings.Contains(string(name), "/") && !strings.HasPrefix(string(name), "kubernetes.io/")
+}
+
+// validateContainerResources rejects requirements the API server would refuse
+// on the pod template: extended resources must set a whole-number limit, and a
+// request, when set, must equal that limit.
+func validateContainerResources(requirements *corev1.ResourceRequirements) error {
+	if requirements == nil {
+		return nil
+	}
+
+	names := slices.Sorted(maps.Keys(requirements.Limits))
+	for name := range requirements.Requests {
+		if _, ok := requirements.Limits[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	for _, name := range names {
+		if !isExtendedResourceName(name) {
+			continue
+		}
+		limit, ok := requirements.Limits[name]
+		if !ok {
+			return fmt.Errorf("extended resource %q must set a limit", name)
+		}
+		if limit.MilliValue()%1000 != 0 {
+			return fmt.Errorf("extended resource %q limit %s must be a whole number", name, limit.String())
+		}
+		if request, ok := requirements.Requests[name]; ok && request.Cmp(limit) != 0 {
+			return fmt.Errorf("extended resource %q request %s must equal its limit %s", name, request.String(), limit.String())
+		}
+	}
+
+	return nil
+}
+
+// scaleResourceRequirements grows every request and limit by percent of its
+// value for each extra daemon. Extended resources keep their configured count.
+func scaleResourceRequirements(requirements *corev1.ResourceRequirements, extraDaemons, percent int32) {
+	scale := func(list corev1.ResourceList) {
+		for name, quantity := range list {
+			if isExtendedResourceName(name) {
+				continue
+			}
+			scaled := quantity.MilliValue() * int64(100+extraDaemons*percent) / 100
+			list[name] = *resource.NewMilliQuantity(scaled, quantity.Format)
+		}
+	}
+	scale(requirements.Requests)
+	scale(requirements.Limits)
+}
+
+// resolveContainerResources returns the resources for the control plane
+// container. Explicit spec.resources always wins over spec.resourceProfile,
+// which wins over the operator's DefaultResources. Profile and default
+// resources grow with built-in provisioner daemons beyond the default.
+func (r *CoderControlPlaneReconciler) resolveContainerResources(
+	coderControlPlane *coderv1alpha1.CoderControlPlane,
+) (*corev1.ResourceRequirements, error) {
+	if coderControlPlane == nil {
+		return nil, fmt.Errorf("assertion failed: coder control plane must not be nil")
+	}
+	if coderControlPlane.Spec.Resources != nil {
+		if err := validateContainerResources(coderControlPlane.Spec.Resources); err != nil {
+			return nil, fmt.Errorf("invalid spec.resources for codercontrolplane %s/%s: %w",
+				coderControlPlane.Namespace, coderControlPlane.Name, err)
+		}
+		return coderControlPlane.Spec.Resources.DeepCopy(), nil
+	}
+
+	extraDaemons := builtinProvisionerExtraDaemons(coderControlPlane)
+	profileName := strings.TrimSpace(coderControlPlane.Spec.ResourceProfile)
+	if profileName == "" && r.DefaultResources != nil {
+		resolved := r.DefaultResources.DeepCopy()
+		if extraDaemons > 0 {
+			scaleResourceRequirements(resolved, extraDaemons, provisionerResourceFactorPercent(coderControlPlane))
+		}
+		return resolved, nil
+	}
+	if profileName == "" {
+		if extraDaemons == 0 {
+			return nil, nil
+		}
+		profileName = defaultScaledResourceProfile
+	}
+
+	profiles := r.ResourceProfiles
+	if profiles == nil {
+		profiles = DefaultResourceProfiles()
+	}
+	requirements, ok := profiles[profileName]
+	if !ok {
+		return nil, fmt.Errorf(
+			"unknown resource profile %q for codercontrolplane %s/%s; available profiles: %s",
+			profileName,
+			coderControlPlane.Namespace,
+			coderControlPlane.Name,
+			strings.Join(slices.Sorted(maps.Keys(profiles)), ", "),
+		)
+	}
+
+	if err := validateContainerResources(&requirements); err != nil {
+		return nil, fmt.Errorf("invalid resource profile %q: %w", profileName, err)
+	}
+	resolved := requirements.DeepCopy()
+	if extraDaemons > 0 {
+		scaleResourceRequirements(resolved, extraDaemons, provisionerResourceFactorPercent(coderControlPlane))
+	}
+
+	return resolved, nil
+}
+
+// provisionerResourceFactorPercent returns spec.provisioner.resourceFactorPercent
+// or its default. Callers only use it while extra daemons are configured, so
+// spec.provisioner is set.
+func provisionerResourceFactorPercent(coderControlPlane *coderv1alpha1.CoderControlPlane) int32 {
+	if factor := coderControlPlane.Spec.Provisioner.ResourceFactorPercent; factor != nil {
+		return *factor
+	}
+
+	return defaultProvisionerResourceFactorPercent
+}
@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"errors"
+	"slices"
 	"strings"
 	"testing"
 	"time"
@@ -83,7 +84,7 @@ func TestRunDefaultsToAllMode(t *testing.T) {
 
 	expectedErr := errors.New("sentinel all error")
 	called := false
-	runAllApp = func(ctx context.Context, timeout time.Duration) error {
+	runAllApp = func(ctx context.Context, timeout time.Duration, _ controllerapp.Options) error {
 		called = true
 		if ctx == nil {
 			t.Fatal("expected non-nil context")
@@ -114,7 +115,7 @@ func TestRunDispatchesAllMode(t *testing.T) {
 
 	expectedErr := errors.New("sentinel all error")
 	called := false
-	runAllApp = func(ctx context.Context, timeout time.Duration) error {
+	runAllApp = func(ctx context.Context, timeout time.Duration, _ controllerapp.Options) error {
 		called = true
 		if ctx == nil {
 			t.Fatal("expected non-nil context")
@@ -134,6 +135,290 @@ func TestRunDispatchesAllMode(t *testing.T) {
 	}
 }
 
+func TestRunPassesDefaultCoderImageToController(t *testing.T) {
+	t.Helper()
+	installMockSignalHandler(t)
+
+	previous := runControllerApp
+	t.Cleanup(func() {
+		runControllerApp = previous
+	})
+
+	expectedErr := errors.New("sentinel controller error")
+	called := false
+	runControllerApp = func(ctx context.Context, opts controllerapp.Options) error {
+		called = true
+		if ctx == nil {
+			t.Fatal("expected non-nil context")
+		}
+		if got, want := opts.DefaultCoderImage, "registry.internal/coder/coder:v2.20.0"; got != want {
+			t.Fatalf("expected default coder image %q, got %q", want, got)
+		}
+		return expectedErr
+	}
+
+	err := run([]string{"--app=controller", "-default-coder-image=registry.internal/coder/coder:v2.20.0"})
+	if !called {
+		t.Fatal("expected controller runner to be called")
+	}
+	if !errors.Is(err, expectedErr) {
+		t.Fatalf("expected sentinel, got %v", err)
+	}
+}
+
+func TestRunPassesResyncPeriodToController(t *testing.T) {
+	t.Helper()
+	installMockSignalHandler(t)
+
+	previous := runControllerApp
+	t.Cleanup(func() {
+		runControllerApp = previous
+	})
+
+	expectedErr := errors.New("sentinel controller error")
+	called := false
+	runControllerApp = func(_ context.Context, opts controllerapp.Options) error {
+		called = true
+		if got, want := opts.ResyncPeriod, 30*time.Minute; got != want {
+			t.Fatalf("expected resync period %v, got %v", want, got)
+		}
+		return expectedErr
+	}
+
+	err := run([]string{"--app=controller", "-resync-period=30m"})
+	if !called {
+		t.Fatal("expected controller runner to be called")
+	}
+	if !errors.Is(err, expectedErr) {
+		t.Fatalf("expected sentinel, got %v", err)
+	}
+}
+
+func TestRunPassesDisableDriftRequeueToController(t *testing.T) {
+	t.Helper()
+	installMockSignalHandler(t)
+
+	previous := runControllerApp
+	t.Cleanup(func() {
+		runControllerApp = previous
+	})
+
+	expectedErr := errors.New("sentinel controller error")
+	called := false
+	runControllerApp = func(_ context.Context, opts controllerapp.Options) error {
+		called = true
+		if !opts.DisableDriftRequeue {
+			t.Fatal("expected drift requeue to be disabled")
+		}
+		return expectedErr
+	}
+
+	err := run([]string{"--app=controller", "-disable-drift-requeue"})
+	if !called {
+		t.Fatal("expected controller runner to be called")
+	}
+	if !errors.Is(err, expectedErr) {
+		t.Fatalf("expected sentinel, got %v", err)
+	}
+}
+
+func TestRunPassesLicenseDuplicateUploadLimitsToController(t *testing.T) {
+	t.Helper()
+	installMockSignalHandler(t)
+
+	previous := runControllerApp
+	t.Cleanup(func() {
+		runControllerApp = previous
+	})
+
+	expectedErr := errors.New("sentinel controller error")
+	called := false
+	runControllerApp = func(_ context.Context, opts controllerapp.Options) error {
+		called = true
+		if got, want := opts.LicenseDuplicateUploadMaxAttempts, 3; got != want {
+			t.Fatalf("expected license duplicate upload max attempts %d, got %d", want, got)
+		}
+		if got, want := opts.LicenseDuplicateUploadBackoff, 30*time.Second; got != want {
+			t.Fatalf("expected license duplicate upload backoff %v, got %v", want, got)
+		}
+		return expectedErr
+	}
+
+	err := run([]string{
+		"--app=controller",
+		"--license-duplicate-upload-max-attempts=3",
+		"--license-duplicate-upload-backoff=30s",
+	})
+	if !called {
+		t.Fatal("expected controller runner to be called")
+	}
+	if !errors.Is(err, expectedErr) {
+		t.Fatalf("expected sentinel, got %v", err)
+	}
+
+	called = false
+	err = run([]string{"--app=controller", "--license-duplicate-upload-max-attempts=-1"})
+	if err == nil || !strings.Contains(err.Error(), "must not be negative") {
+		t.Fatalf("expected negative max attempts to be rejected, got %v", err)
+	}
+	if called {
+		t.Fatal("expected controller runner not to be called for invalid flags")
+	}
+}
+
+func TestRunPassesDefaultResourcesToController(t *testing.T) {
+	t.Helper()
+	installMockSignalHandler(t)
+
+	previous := runControllerApp
+	t.Cleanup(func() {
+		runControllerApp = previous
+	})
+
+	expectedErr := errors.New("sentinel controller error")
+	called := false
+	runControllerApp = func(_ context.Context, opts controllerapp.Options) error {
+		called = true
+		if opts.DefaultResources == nil {
+			t.Fatal("expected default resources to be passed to the controller")
+		}
+		if got := opts.DefaultResources.Requests.Cpu().String(); got != "500m" {
+			t.Fatalf("expected default cpu request 500m, got %q", got)
+		}
+		return expectedErr
+	}
+
+	err := run([]string{"--app=controller", `--default-resources={"requests":{"cpu":"500m"}}`})
+	if !called {
+		t.Fatal("expected controller runner to be called")
+	}
+	if !errors.Is(err, expectedErr) {
+		t.Fatalf("expected sentinel, got %v", err)
+	}
+
+	called = false
+	err = run([]string{"--app=controller", "--default-resources={"})
+	if err == nil || !strings.Contains(err.Error(), "invalid --default-resources") {
+		t.Fatalf("expected malformed default resources to be rejected, got %v", err)
+	}
+	if called {
+		t.Fatal("expected controller runner not to be called for invalid flags")
+	}
+}
+
+func TestRunServesConversionWebhookOnlyWhenEnabled(t *testing.T) {
+	t.Helper()
+	installMockSignalHandler(t)
+
+	previous := runControllerApp
+	t.Cleanup(func() {
+		runControllerApp = previous
+	})
+
+	expectedErr := errors.New("sentinel controller error")
+	var got []bool
+	runControllerApp = func(_ context.Context, opts controllerapp.Options) error {
+		got = append(got, opts.EnableConversionWebhook)
+		return expectedErr
+	}
+
+	for _, args := range [][]string{
+		{"--app=controller"},
+		{"--app=controller", "--enable-conversion-webhook"},
+	} {
+		if err := run(args); !errors.Is(err, expectedErr) {
+			t.Fatalf("expected sentinel for %v, got %v", args, err)
+		}
+	}
+	if !slices.Equal(got, []bool{false, true}) {
+		t.Fatalf("expected conversion webhook disabled by default and enabled by flag, got EnableConversionWebhook=%v", got)
+	}
+}
+
+func TestRunPassesEnvPolicyToController(t *testing.T) {
+	t.Helper()
+	installMockSignalHandler(t)
+
+	previous := runControllerApp
+	t.Cleanup(func() {
+		runControllerApp = previous
+	})
+
+	expectedErr := errors.New("sentinel controller error")
+	called := false
+	runControllerApp = func(_ context.Context, opts controllerapp.Options) error {
+		called = true
+		if !slices.Equal(opts.EnvPolicy.Allowed, []string{"CODER_*"}) {
+			t.Fatalf("expected allowed env patterns [CODER_*], got %v", opts.EnvPolicy.Allowed)
+		}
+		if !slices.Equal(opts.EnvPolicy.Denied, []string{"CODER_OIDC_*", "CODER_PG_CONNECTION_URL"}) {
+			t.Fatalf("expected denied env patterns [CODER_OIDC_* CODER_PG_CONNECTION_URL], got %v", opts.EnvPolicy.Denied)
+		}
+		return expectedErr
+	}
+
+	err := run([]string{
+		"--app=controller",
+		"--extra-env-allow=CODER_*",
+		"--extra-env-deny=CODER_OIDC_*, CODER_PG_CONNECTION_URL",
+	})
+	if !called {
+		t.Fatal("expected controller runner to be called")
+	}
+	if !errors.Is(err, expectedErr) {
+		t.Fatalf("expected sentinel, got %v", err)
+	}
+
+	called = false
+	err = run([]string{"--app=controller", "--extra-env-deny=CODER_[OIDC"})
+	if err == nil || !strings.Contains(err.Error(), "invalid --extra-env-deny") {
+		t.Fatalf("expected malformed env pattern to be rejected, got %v", err)
+	}
+	if called {
+		t.Fatal("expected controller runner not to be called for invalid flags")
+	}
+}
+
+func TestRunPassesWatchNamespacesToController(t *testing.T) {
+	t.Helper()
+	installMockSignalHandler(t)
+
+	previous := runControllerApp
+	t.Cleanup(func() {
+		runControllerApp = previous
+	})
+
+	expectedErr := errors.New("sentinel controller error")
+	called := false
+	runControllerApp = func(_ context.Context, opts controllerapp.Options) error {
+		called = true
+		if got, want := opts.WatchNamespaces, []string{"coder", "team-a"}; !slices.Equal(got, want) {
+			t.Fatalf("expected watch namespaces %v, got %v", want, got)
+		}
+		return expectedErr
+	}
+
+	err := run([]string{"--app=controller", "-watch-namespaces=coder, team-a,,coder"})
+	if !called {
+		t.Fatal("expected controller runner to be called")
+	}
+	if !errors.Is(err, expectedErr) {
+		t.Fatalf("expected sentinel, got %v", err)
+	}
+}
+
+func TestRunRejectsNegativeResyncPeriod(t *testing.T) {
+	t.Helper()
+
+	err := run([]string{"--app=controller", "-resync-period=-1m"})
+	if err == nil {
+		t.Fatal("expected an error for a negative --resync-period")
+	}
+	if !strings.Contains(err.Error(), "--resync-period") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
 func TestRunRejectsUnknownMode(t *testing.T) {
 	t.Helper()
 
@@ -168,12 +453,30 @@ func TestRunDispatchesAggregatedAPIServerMode(t *testing.T) {
 		if got, want := opts.CoderSessionToken, "test-token"; got != want {
 			t.Fatalf("expected coder session token %q, got %q", want, got)
 		}
+		if got := opts.CoderSessionTokenFile; got != "" {
+			t.Fatalf("expected empty coder session token file, got %q", got)
+		}
+		if got, want := opts.CoderSessionTokenReloadInterval, time.Minute; got != want {
+			t.Fatalf("expected coder session token reload interval %v, got %v", want, got)
+		}
 		if got, want := opts.CoderNamespace, "control-plane"; got != want {
 			t.Fatalf("expected coder namespace %q, got %q", want, got)
 		}
+		if got, want := strings.Join(opts.CoderOrganizations, ","), "acme,globex"; got != want {
+			t.Fatalf("expected coder organizations %q, got %q", want, got)
+		}
 		if got, want := opts.CoderRequestTimeout, 45*time.Second; got != want {
 			t.Fatalf("expected coder request timeout %v, got %v", want, got)
 		}
+		if got := opts.CoderNamespaceSelector; got != "" {
+			t.Fatalf("expected empty coder namespace selector, got %q", got)
+		}
+		if got, want := opts.CoderAdminTokenSecret, "coder-admin-token"; got != want {
+			t.Fatalf("expected coder admin token secret %q, got %q", want, got)
+		}
+		if opts.EnableTemplateGitSources {
+			t.Fatal("expected template git sources to be disabled by default")
+		}
 		return expectedErr
 	}
 
@@ -182,6 +485,7 @@ func TestRunDispatchesAggregatedAPIServerMode(t *testing.T) {
 		"--coder-url=https://coder.example.com",
 		"--coder-session-token=test-token",
 		"--coder-namespace=control-plane",
+		"--coder-organizations=acme, globex",
 		"--coder-request-timeout=45s",
 	})
 	if !called {